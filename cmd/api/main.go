@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net"
 	"os"
 
 	"remote-config-system/internal/cache"
 	"remote-config-system/internal/db"
+	"remote-config-system/internal/grpcapi"
 	"remote-config-system/internal/handlers"
 	"remote-config-system/internal/middleware"
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/notify"
+	"remote-config-system/internal/server"
 	"remote-config-system/internal/services"
 	"remote-config-system/internal/sse"
 
@@ -21,11 +27,8 @@ func main() {
 		log.Println("No .env file found, using environment variables")
 	}
 
-	// Set default port
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	// HTTP server port and timeouts
+	serverConfig := server.NewConfig()
 
 	// Initialize database connection
 	dbConfig := db.NewConfig()
@@ -47,7 +50,7 @@ func main() {
 
 	// Initialize Redis cache
 	cacheConfig := cache.NewConfig()
-	log.Printf("Connecting to Redis with config: %+v", cacheConfig)
+	log.Printf("Connecting to Redis at %s", cacheConfig.Address())
 	redisClient, err := cache.NewRedisClient(cacheConfig)
 	if err != nil {
 		log.Printf("Failed to connect to Redis: %v", err)
@@ -62,22 +65,46 @@ func main() {
 	repos := db.NewRepositories(database)
 
 	// Initialize SSE service
-	sseService := sse.NewSSEService()
+	sseService := sse.NewSSEServiceWithConfig(sse.NewConfig())
 	log.Println("SSE service initialized")
 
 	// Initialize services
-	configService := services.NewConfigService(repos, redisClient, sseService)
+	configService := services.NewConfigService(services.NewConfig(), repos, redisClient, sseService)
+
+	// Initialize encryption of secret configuration values
+	encryptor, err := services.NewConfigEncryptorFromEnv()
+	if err != nil {
+		log.Fatal("Failed to initialize config encryption:", err)
+	}
+	if encryptor == nil {
+		log.Println("CONFIG_ENCRYPTION_KEY not set, configurations with secret keys cannot be stored")
+	}
+	configService.SetEncryptor(encryptor)
+
+	// Announce configuration changes over Slack and email
+	notifyConfig := notify.NewConfig()
+	configService.SetNotifier(notify.NewDispatcher(notifyConfig))
+	if notifyConfig.SMTPHost == "" {
+		log.Println("SMTP_HOST not set, email notifications are disabled")
+	}
 
-	// Warm cache on startup if Redis is available
+	// Warm cache on startup if Redis is available; progress is reported at
+	// /admin/cache/warm/status
 	if redisClient != nil {
-		go func() {
-			log.Println("Starting background cache warming...")
-			if err := configService.WarmCache(); err != nil {
-				log.Printf("Cache warming failed: %v", err)
-			}
-		}()
+		configService.StartCacheWarming()
+	}
+
+	// Prune configuration history according to the retention policies
+	go configService.RunHistoryPruner(context.Background())
+
+	// Store the API key usage and organization reads counted in Redis
+	if redisClient != nil {
+		go configService.RunUsageFlusher(context.Background())
 	}
 
+	// Flag environments that are no longer changed or read
+	go configService.RunStaleCheck(context.Background())
+
 	// Initialize handlers
 	configHandler := handlers.NewConfigHandler(configService)
 	managementHandler := handlers.NewManagementHandler(configService)
@@ -86,18 +113,44 @@ func main() {
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(configService)
 
+	// Enable JWT bearer authentication if an identity provider is configured
+	if jwtConfig := middleware.NewJWTConfig(); jwtConfig.Enabled() {
+		authMiddleware.SetJWTValidator(middleware.NewJWTValidator(jwtConfig))
+		log.Printf("JWT authentication enabled with JWKS from %s", jwtConfig.JWKSURL)
+	}
+
+	// Enable role-based access control for the admin API
+	adminAuthConfig := middleware.NewAdminAuthConfig()
+	authMiddleware.SetAdminAuth(adminAuthConfig)
+	if adminAuthConfig.Enabled {
+		log.Println("Admin API role-based access control enabled")
+		if adminAuthConfig.BootstrapToken == "" {
+			log.Println("ADMIN_BOOTSTRAP_TOKEN is not set; only existing admin tokens can reach the admin API")
+		}
+	} else {
+		log.Println("WARNING: admin API is unauthenticated because ADMIN_AUTH_ENABLED=false")
+	}
+
 	// Initialize Gin router
 	r := gin.Default()
 
 	// Add global middleware
-	r.Use(middleware.CORS())
-	r.Use(middleware.RequestLogger())
+	r.Use(middleware.CORS(middleware.NewCORSConfig()))
+	r.Use(middleware.RequestID())
+	r.Use(middleware.RequestLogger(middleware.NewAccessLogConfig()))
 	r.Use(middleware.ErrorHandler())
 	r.Use(middleware.RateLimiter())
+	r.Use(middleware.BodyLimit(middleware.NewBodyLimitConfig()))
+	r.Use(middleware.ConfigSchemaVersion())
 
 	// Health check endpoint
 	r.GET("/health", configHandler.HealthCheck)
 
+	// API documentation, generated from the routes registered below
+	openAPIHandler := handlers.NewOpenAPIHandler(r.Routes)
+	r.GET("/openapi.json", openAPIHandler.GetSpec)
+	r.GET("/docs", openAPIHandler.SwaggerUI)
+
 	// Serve static files in development mode
 	if os.Getenv("GIN_MODE") == "debug" {
 		r.Static("/static", "./web/static")
@@ -112,100 +165,261 @@ func main() {
 		})
 	}
 
+	// Configuration reads, streams included, count towards the monthly read quota of
+	// their organization
+	readQuota := middleware.ReadQuota(configService)
+
 	// Public configuration endpoints (no authentication required)
 	publicAPI := r.Group("/config")
 	{
-		publicAPI.GET("/:org/:app/:env", configHandler.GetConfig)
+		publicAPI.GET("/:org/:app/:env", readQuota, configHandler.GetConfig)
 	}
 
+	// Streams, WebSockets and long polls outlive the server's write timeout
+	streaming := middleware.Streaming()
+
 	// Public SSE endpoints (no authentication required)
 	eventsAPI := r.Group("/events")
 	{
-		eventsAPI.GET("/:org/:app/:env", sseHandler.StreamConfigUpdates)
+		eventsAPI.GET("/:org/:app/:env", streaming, readQuota, sseHandler.StreamConfigUpdates)
+		eventsAPI.GET("/:org/:app/:env/history", sseHandler.GetEventHistory)
 	}
 
+	// Public WebSocket endpoint, for clients behind proxies that break SSE
+	r.GET("/ws/:org/:app/:env", streaming, readQuota, sseHandler.StreamConfigUpdatesWebSocket)
+
 	// API endpoints with authentication
 	apiV1 := r.Group("/api")
-	apiV1.Use(authMiddleware.APIKeyAuth())
+	apiV1.Use(authMiddleware.APIKeyAuth(), middleware.UsageTracker(redisClient))
 	{
 		// Configuration endpoints for applications
-		apiV1.GET("/config/:env", configHandler.GetConfigByAPIKey)
+		apiV1.GET("/config", readQuota, configHandler.GetDefaultConfigByAPIKey)
+		apiV1.GET("/config/:env", readQuota, configHandler.GetConfigByAPIKey)
+		apiV1.GET("/config/:env/poll", streaming, readQuota, sseHandler.PollConfigWithAPIKey)
+		apiV1.GET("/config/:env/:version", readQuota, configHandler.GetConfigVersionByAPIKey)
+		apiV1.GET("/flags/:env", readQuota, configHandler.GetFlagsByAPIKey)
+		apiV1.POST("/config/batch", readQuota, configHandler.GetConfigBatchByAPIKey)
 
 		// SSE endpoints for applications
-		apiV1.GET("/events/:env", sseHandler.StreamConfigUpdatesWithAPIKey)
+		apiV1.GET("/events/:env", streaming, readQuota, sseHandler.StreamConfigUpdatesWithAPIKey)
+		apiV1.GET("/events/:env/history", sseHandler.GetEventHistoryWithAPIKey)
 	}
 
-	// Admin endpoints with optional authentication
+	// Admin endpoints: reads require the viewer role, changes the editor role
+	// and credential management the admin role
+	requireEditor := authMiddleware.RequireRole(models.RoleEditor)
+	requireAdmin := authMiddleware.RequireRole(models.RoleAdmin)
+
+	// Config writes can be retried safely with an Idempotency-Key header
+	idempotent := middleware.Idempotency(redisClient, middleware.NewIdempotencyConfig())
+
 	adminAPI := r.Group("/admin")
-	adminAPI.Use(authMiddleware.OptionalAPIKeyAuth())
+	adminAPI.Use(authMiddleware.OptionalAPIKeyAuth(), authMiddleware.RequireRole(models.RoleViewer))
 	{
+		// Admin token management
+		adminAPI.GET("/tokens", requireAdmin, managementHandler.ListAdminTokens)
+		adminAPI.POST("/tokens", requireAdmin, managementHandler.CreateAdminToken)
+		adminAPI.DELETE("/tokens/:id", requireAdmin, managementHandler.DeleteAdminToken)
+
 		// Cache management
 		adminAPI.GET("/cache/stats", managementHandler.GetCacheStats)
-		adminAPI.POST("/cache/warm", managementHandler.WarmCache)
-		adminAPI.DELETE("/cache", managementHandler.ClearCache)
+		adminAPI.POST("/cache/stats/reset", requireEditor, managementHandler.ResetCacheStats)
+		adminAPI.POST("/cache/warm", requireEditor, managementHandler.WarmCache)
+		adminAPI.GET("/cache/warm/status", managementHandler.GetCacheWarmStatus)
+		adminAPI.DELETE("/cache", requireEditor, managementHandler.ClearCache)
+
+		// Database monitoring
+		adminAPI.GET("/db/stats", managementHandler.GetDatabaseStats)
+
+		// Stale configuration detection
+		adminAPI.GET("/stats/stale", managementHandler.GetStaleConfigs)
+
+		// Encryption management
+		adminAPI.POST("/encryption/reencrypt", requireAdmin, managementHandler.ReencryptSecrets)
 
 		// SSE management
 		adminAPI.GET("/sse/stats", sseHandler.GetSSEStats)
+		adminAPI.DELETE("/sse/clients/:id", requireAdmin, sseHandler.DisconnectSSEClient)
+
+		// Validation
+		adminAPI.POST("/validate/manifest", configHandler.ValidateManifest)
+
+		// Search
+		adminAPI.GET("/search", configHandler.SearchConfigs)
 
 		// Organization management
 		adminAPI.GET("/orgs", managementHandler.ListOrganizations)
-		adminAPI.POST("/orgs", managementHandler.CreateOrganization)
+		adminAPI.POST("/orgs", requireEditor, managementHandler.CreateOrganization)
 
 		orgs := adminAPI.Group("/orgs/:org")
 		{
 			orgs.GET("", managementHandler.GetOrganization)
-			orgs.PUT("", managementHandler.UpdateOrganization)
-			orgs.DELETE("", managementHandler.DeleteOrganization)
+			orgs.PUT("", requireEditor, managementHandler.UpdateOrganization)
+			orgs.DELETE("", requireEditor, managementHandler.DeleteOrganization)
+			orgs.GET("/tree", managementHandler.GetOrganizationTree)
+			orgs.GET("/quota", managementHandler.GetOrganizationQuota)
 
 			// Application management
 			orgs.GET("/apps", managementHandler.ListApplications)
-			orgs.POST("/apps", managementHandler.CreateApplication)
+			orgs.POST("/apps", requireEditor, managementHandler.CreateApplication)
+
+			// Atomic update of the environments of every application picked by a label selector
+			orgs.POST("/config/batch-update", requireEditor, idempotent, configHandler.UpdateConfigBatch)
+
+			// Configuration templates shared by the organization's environments
+			orgs.GET("/templates", configHandler.ListTemplates)
+			orgs.GET("/templates/:template", configHandler.GetTemplate)
+			orgs.PUT("/templates/:template", requireEditor, configHandler.SetTemplate)
+			orgs.DELETE("/templates/:template", requireEditor, configHandler.DeleteTemplate)
 
 			apps := orgs.Group("/apps/:app")
 			{
 				apps.GET("", managementHandler.GetApplication)
-				apps.PUT("", managementHandler.UpdateApplication)
-				apps.DELETE("", managementHandler.DeleteApplication)
+				apps.PUT("", requireEditor, managementHandler.UpdateApplication)
+				apps.DELETE("", requireEditor, managementHandler.DeleteApplication)
+
+				// Requests made with the application's API key
+				apps.GET("/usage", managementHandler.GetApplicationUsage)
+				apps.POST("/api-key/rotate", requireAdmin, managementHandler.RotateAPIKey)
+
+				// Default configuration inherited by every environment
+				apps.GET("/defaults", configHandler.GetAppDefaults)
+				apps.PUT("/defaults", requireEditor, configHandler.UpdateAppDefaults)
+				apps.DELETE("/defaults", requireEditor, configHandler.DeleteAppDefaults)
+
+				// Atomic update of several environments, by slug or by label selector
+				apps.POST("/config/batch-update", requireEditor, idempotent, configHandler.UpdateConfigBatch)
 
 				// Environment management
 				apps.GET("/envs", managementHandler.ListEnvironments)
-				apps.POST("/envs", managementHandler.CreateEnvironment)
+				apps.POST("/envs", requireEditor, managementHandler.CreateEnvironment)
+				apps.POST("/envs/bulk", requireEditor, managementHandler.BulkCreateEnvironments)
 
 				envs := apps.Group("/envs/:env")
 				{
 					envs.GET("", managementHandler.GetEnvironment)
-					envs.PUT("", managementHandler.UpdateEnvironment)
-					envs.DELETE("", managementHandler.DeleteEnvironment)
+					envs.PUT("", requireEditor, managementHandler.UpdateEnvironment)
+					envs.DELETE("", requireEditor, managementHandler.DeleteEnvironment)
+
+					// Environment labels, for selecting environments across applications
+					envs.GET("/labels", managementHandler.ListEnvironmentLabels)
+					envs.PUT("/labels/:label", requireEditor, managementHandler.SetEnvironmentLabel)
+					envs.DELETE("/labels/:label", requireEditor, managementHandler.DeleteEnvironmentLabel)
+
+					// Environment locks; a locked environment rejects configuration changes
+					envs.POST("/lock", requireAdmin, managementHandler.LockEnvironment)
+					envs.DELETE("/lock", requireAdmin, managementHandler.UnlockEnvironment)
 
 					// Configuration management
-					envs.PUT("/config", configHandler.UpdateConfig)
+					envs.PUT("/config", requireEditor, idempotent, configHandler.UpdateConfig)
+					envs.GET("/config/download", configHandler.DownloadConfig)
+					envs.POST("/config/cas", requireEditor, configHandler.CompareAndSwapConfig)
+					envs.POST("/config/diff-draft", configHandler.DiffDraftConfig)
+					envs.POST("/config/lint", configHandler.LintConfig)
+					envs.POST("/config/encrypt-secrets", requireAdmin, configHandler.EncryptSecrets)
 					envs.GET("/history", configHandler.GetConfigHistory)
 					envs.GET("/history/:version", configHandler.GetConfigVersion)
+					envs.GET("/history/key/:keyPath", configHandler.GetKeyHistory)
+					envs.POST("/history/prune", requireAdmin, configHandler.PruneHistory)
 					envs.GET("/changes", configHandler.GetConfigChanges)
-					envs.POST("/rollback", configHandler.RollbackConfig)
+					envs.GET("/changes/export", streaming, configHandler.ExportConfigChanges)
+					envs.POST("/rollback", requireEditor, idempotent, configHandler.RollbackConfig)
+					envs.POST("/promote", requireEditor, idempotent, configHandler.PromoteConfig)
+					envs.POST("/config/from-template", requireEditor, idempotent, configHandler.CreateConfigFromTemplate)
+
+					// Version tags
+					envs.GET("/tags", configHandler.ListTags)
+					envs.PUT("/tags/:tag", requireEditor, configHandler.SetTag)
+					envs.DELETE("/tags/:tag", requireEditor, configHandler.DeleteTag)
+
+					// Approval workflow
+					envs.GET("/config/pending", configHandler.ListPendingChanges)
+					envs.POST("/config/approve", requireAdmin, configHandler.ApprovePendingChange)
+					envs.POST("/config/reject", requireAdmin, configHandler.RejectPendingChange)
+
+					// Gradual rollouts
+					envs.GET("/rollout", configHandler.GetRollout)
+					envs.PUT("/rollout", requireEditor, configHandler.UpdateRollout)
+					envs.POST("/rollout/promote", requireEditor, configHandler.PromoteRollout)
+					envs.DELETE("/rollout", requireEditor, configHandler.AbortRollout)
+
+					// Schema management
+					envs.GET("/schema", configHandler.GetConfigSchema)
+					envs.PUT("/schema", requireEditor, configHandler.UpdateConfigSchema)
+					envs.GET("/form-schema", configHandler.GetFormSchema)
+
+					// Validation rule management
+					envs.GET("/rules", configHandler.GetConfigRules)
+					envs.PUT("/rules", requireEditor, configHandler.UpdateConfigRules)
+					envs.DELETE("/rules", requireEditor, configHandler.DeleteConfigRules)
+
+					// Change notifications; the Slack webhook URL is a credential, so reading
+					// the settings needs the editor role too
+					envs.GET("/notifications", requireEditor, configHandler.GetNotificationSettings)
+					envs.PUT("/notifications", requireEditor, configHandler.UpdateNotificationSettings)
+					envs.DELETE("/notifications", requireEditor, configHandler.DeleteNotificationSettings)
 				}
 			}
 		}
 	}
 
-	log.Printf("Starting server on port %s", port)
+	for _, route := range openAPIHandler.Undocumented() {
+		log.Printf("WARNING: %s is not described in the OpenAPI document", route)
+	}
+
+	log.Printf("Starting server on port %s", serverConfig.Port)
+	log.Printf("Server timeouts: read=%s write=%s idle=%s (streaming routes have no read or write timeout)",
+		serverConfig.ReadTimeout, serverConfig.WriteTimeout, serverConfig.IdleTimeout)
 	log.Println("Available endpoints:")
 	log.Println("  GET  /                                               - Redirect to dashboard")
 	log.Println("  GET  /dashboard                                      - Admin dashboard")
 	log.Println("  GET  /health                                         - Health check")
 	log.Println("  GET  /demo/sse                                       - SSE demo page")
-	log.Println("  GET  /config/:org/:app/:env                          - Get config (public)")
-	log.Println("  GET  /events/:org/:app/:env                          - SSE stream (public)")
+	log.Println("  GET  /openapi.json                                   - OpenAPI document")
+	log.Println("  GET  /docs                                           - Swagger UI")
+	log.Println("  GET  /config/:org/:app/:env                          - Get config (public, ?raw=true for overrides only)")
+	log.Println("  GET  /events/:org/:app/:env                          - SSE stream (public, ?replay=true for recent updates)")
+	log.Println("  GET  /events/:org/:app/:env/history                  - Recent configuration updates (public)")
+	log.Println("  GET  /ws/:org/:app/:env                              - WebSocket stream (public)")
+	log.Println("  GET  /api/config                                     - Get default environment config (API key required)")
 	log.Println("  GET  /api/config/:env                                - Get config (API key required)")
-	log.Println("  GET  /api/events/:env                                - SSE stream (API key required)")
+	log.Println("  GET  /api/config/:env/poll                           - Wait for a config change (API key required, ?since_version=)")
+	log.Println("  GET  /api/config/:env/:version                       - Get specific config version (API key required)")
+	log.Println("  POST /api/config/batch                               - Get configs for several environments (API key required)")
+	log.Println("  GET  /api/flags/:env                                 - Get boolean feature flags (API key required)")
+	log.Println("  GET  /api/events/:env                                - SSE stream (API key required, ?replay=true for recent updates)")
+	log.Println("  GET  /api/events/:env/history                        - Recent configuration updates (API key required)")
+	log.Println("")
+	log.Println("Admin Tokens (admin role):")
+	log.Println("  GET    /admin/tokens                                 - List admin tokens")
+	log.Println("  POST   /admin/tokens                                 - Create admin token")
+	log.Println("  DELETE /admin/tokens/:id                             - Revoke admin token")
 	log.Println("")
 	log.Println("Cache Management:")
 	log.Println("  GET    /admin/cache/stats                            - Get cache statistics")
-	log.Println("  POST   /admin/cache/warm                             - Warm cache with configurations")
+	log.Println("  POST   /admin/cache/stats/reset                      - Reset cache statistics without clearing the cache")
+	log.Println("  POST   /admin/cache/warm                             - Warm cache with configurations (?org=&app= to limit)")
+	log.Println("  GET    /admin/cache/warm/status                      - Get the progress of startup cache warming")
 	log.Println("  DELETE /admin/cache                                  - Clear all cache")
 	log.Println("")
+	log.Println("Database Monitoring:")
+	log.Println("  GET    /admin/db/stats                               - Get database connection pool statistics")
+	log.Println("")
+	log.Println("Stale Configuration Detection:")
+	log.Println("  GET    /admin/stats/stale                            - List environments no longer changed or read (?refresh=true to recheck)")
+	log.Println("")
+	log.Println("Encryption Management:")
+	log.Println("  POST   /admin/encryption/reencrypt                   - Re-encrypt secret values with the primary key")
+	log.Println("")
 	log.Println("SSE Management:")
 	log.Println("  GET    /admin/sse/stats                              - Get SSE statistics and connected clients")
+	log.Println("  DELETE /admin/sse/clients/:id                        - Disconnect an SSE client")
+	log.Println("")
+	log.Println("Validation:")
+	log.Println("  POST   /admin/validate/manifest                      - Validate configs for many environments")
+	log.Println("")
+	log.Println("Search:")
+	log.Println("  GET    /admin/search?key=&value=                     - Find environments referencing a key or value")
 	log.Println("")
 	log.Println("Management API:")
 	log.Println("  GET    /admin/orgs                                   - List organizations")
@@ -213,25 +427,93 @@ func main() {
 	log.Println("  GET    /admin/orgs/:org                              - Get organization")
 	log.Println("  PUT    /admin/orgs/:org                              - Update organization")
 	log.Println("  DELETE /admin/orgs/:org                              - Delete organization")
+	log.Println("  GET    /admin/orgs/:org/tree                         - Get organization tree of applications and environments")
+	log.Println("  GET    /admin/orgs/:org/quota                        - Get organization reads of the month and read quota")
 	log.Println("  GET    /admin/orgs/:org/apps                         - List applications")
 	log.Println("  POST   /admin/orgs/:org/apps                         - Create application")
+	log.Println("  POST   /admin/orgs/:org/config/batch-update?selector= - Update the environments a label selector picks atomically")
+	log.Println("  GET    /admin/orgs/:org/templates                    - List config templates")
+	log.Println("  GET    /admin/orgs/:org/templates/:template          - Get config template")
+	log.Println("  PUT    /admin/orgs/:org/templates/:template          - Create or update config template")
+	log.Println("  DELETE /admin/orgs/:org/templates/:template          - Delete config template")
 	log.Println("  GET    /admin/orgs/:org/apps/:app                    - Get application")
 	log.Println("  PUT    /admin/orgs/:org/apps/:app                    - Update application")
 	log.Println("  DELETE /admin/orgs/:org/apps/:app                    - Delete application")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/usage              - Get API key usage by environment and day")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/api-key/rotate     - Replace the API key (returned only once)")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/defaults           - Get application default config")
+	log.Println("  PUT    /admin/orgs/:org/apps/:app/defaults           - Set application default config")
+	log.Println("  DELETE /admin/orgs/:org/apps/:app/defaults           - Remove application default config")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/config/batch-update - Update several environments atomically (by slug or ?selector=)")
 	log.Println("  GET    /admin/orgs/:org/apps/:app/envs               - List environments")
 	log.Println("  POST   /admin/orgs/:org/apps/:app/envs               - Create environment")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/bulk          - Create several environments")
 	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env          - Get environment")
 	log.Println("  PUT    /admin/orgs/:org/apps/:app/envs/:env          - Update environment")
 	log.Println("  DELETE /admin/orgs/:org/apps/:app/envs/:env          - Delete environment")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/labels   - List environment labels")
+	log.Println("  PUT    /admin/orgs/:org/apps/:app/envs/:env/labels/:label - Set an environment label")
+	log.Println("  DELETE /admin/orgs/:org/apps/:app/envs/:env/labels/:label - Remove an environment label")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/:env/lock     - Lock environment against config changes (admin role)")
+	log.Println("  DELETE /admin/orgs/:org/apps/:app/envs/:env/lock     - Unlock environment (admin role)")
 	log.Println("")
 	log.Println("Configuration API:")
 	log.Println("  PUT    /admin/orgs/:org/apps/:app/envs/:env/config           - Update config")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/config/download  - Download config as a file")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/:env/config/diff-draft - Preview draft config diff")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/:env/config/lint - Check a config for common mistakes")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/:env/config/cas       - Set a key if it has the expected value")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/:env/config/encrypt-secrets - Encrypt stored secret values")
 	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/history          - Get config history")
 	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/history/:version - Get specific config version")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/history/key/:keyPath - Get the values a key took across versions")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/:env/history/prune    - Prune old config versions")
 	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/changes          - Get config changes")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/changes/export   - Export config changes as CSV")
 	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/:env/rollback         - Rollback config")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/:env/promote          - Promote another environment's config")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/:env/config/from-template - Create config from a template")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/tags             - List version tags")
+	log.Println("  PUT    /admin/orgs/:org/apps/:app/envs/:env/tags/:tag        - Create or move a version tag")
+	log.Println("  DELETE /admin/orgs/:org/apps/:app/envs/:env/tags/:tag        - Delete a version tag")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/config/pending   - List proposed config changes")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/:env/config/approve   - Approve and activate a proposed change")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/:env/config/reject    - Reject a proposed change")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/rollout          - Get the rollout in progress")
+	log.Println("  PUT    /admin/orgs/:org/apps/:app/envs/:env/rollout          - Change the rollout percentage")
+	log.Println("  POST   /admin/orgs/:org/apps/:app/envs/:env/rollout/promote  - Promote the rollout to every client")
+	log.Println("  DELETE /admin/orgs/:org/apps/:app/envs/:env/rollout          - Abort the rollout")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/schema           - Get config JSON Schema")
+	log.Println("  PUT    /admin/orgs/:org/apps/:app/envs/:env/schema           - Set config JSON Schema")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/form-schema      - Get schema pre-filled with current values")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/rules            - Get config validation rules")
+	log.Println("  PUT    /admin/orgs/:org/apps/:app/envs/:env/rules            - Set config validation rules")
+	log.Println("  DELETE /admin/orgs/:org/apps/:app/envs/:env/rules            - Remove config validation rules")
+	log.Println("  GET    /admin/orgs/:org/apps/:app/envs/:env/notifications    - Get change notification settings")
+	log.Println("  PUT    /admin/orgs/:org/apps/:app/envs/:env/notifications    - Set change notification settings (Slack, email)")
+	log.Println("  DELETE /admin/orgs/:org/apps/:app/envs/:env/notifications    - Remove change notification settings")
+
+	// Start the gRPC API on its own port
+	grpcConfig := grpcapi.NewConfig()
+	if grpcConfig.Enabled {
+		listener, err := net.Listen("tcp", ":"+grpcConfig.Port)
+		if err != nil {
+			log.Fatal("Failed to listen for gRPC:", err)
+		}
+
+		grpcServer := grpcapi.NewGRPCServer(configService, sseService)
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatal("Failed to start gRPC server:", err)
+			}
+		}()
+
+		log.Printf("gRPC API listening on port %s", grpcConfig.Port)
+		log.Println("  remoteconfig.v1.ConfigService/GetConfig   - Get current config (API key required)")
+		log.Println("  remoteconfig.v1.ConfigService/WatchConfig - Stream config updates (API key required)")
+	}
 
-	if err := r.Run(":" + port); err != nil {
+	if err := server.New(serverConfig, r).ListenAndServe(); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
 }
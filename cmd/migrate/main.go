@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"remote-config-system/internal/db"
+
+	"github.com/joho/godotenv"
+)
+
+const usage = `Usage: migrate [-dir migrations] <command>
+
+Commands:
+  up            Apply all pending migrations
+  down [steps]  Roll back the last applied migrations (default 1)
+  status        List migrations and whether they are applied
+`
+
+func main() {
+	migrationsDir := flag.String("dir", "migrations", "directory containing the migration files")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	database, err := db.Connect(db.NewConfig())
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer database.Close()
+
+	migrationRunner := db.NewMigrationRunner(database, *migrationsDir)
+
+	switch command := flag.Arg(0); command {
+	case "up":
+		if err := migrationRunner.RunMigrations(); err != nil {
+			log.Fatal("Failed to run database migrations:", err)
+		}
+		log.Println("Database migrations completed successfully")
+
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			steps, err = strconv.Atoi(flag.Arg(1))
+			if err != nil || steps < 1 {
+				log.Fatalf("Invalid number of steps: %s", flag.Arg(1))
+			}
+		}
+		if err := migrationRunner.RollbackMigration(steps); err != nil {
+			log.Fatal("Failed to roll back database migrations:", err)
+		}
+		log.Println("Database rollback completed successfully")
+
+	case "status":
+		if err := migrationRunner.CreateMigrationsTable(); err != nil {
+			log.Fatal(err)
+		}
+		status, err := migrationRunner.GetMigrationStatus()
+		if err != nil {
+			log.Fatal("Failed to get migration status:", err)
+		}
+		for _, migration := range status {
+			state := "pending"
+			if migration["applied"].(bool) {
+				state = "applied"
+			}
+			down := ""
+			if !migration["down"].(bool) {
+				down = " (no down script)"
+			}
+			fmt.Printf("%-8s %s%s\n", state, migration["filename"], down)
+		}
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", command)
+		flag.Usage()
+		os.Exit(2)
+	}
+}
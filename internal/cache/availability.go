@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AvailabilityStats describes whether Redis is reachable and how often it was not
+type AvailabilityStats struct {
+	Available bool `json:"available"`
+	// UnavailableEvents counts the times Redis became unreachable
+	UnavailableEvents int64 `json:"unavailable_events"`
+	// Recoveries counts the times Redis became reachable again
+	Recoveries int64 `json:"recoveries"`
+	// UnavailableSince is set while Redis is unreachable
+	UnavailableSince *time.Time `json:"unavailable_since,omitempty"`
+}
+
+// availability tracks whether Redis is reachable. It is updated by a hook on every
+// command and by the monitor that retries the connection while Redis is down.
+type availability struct {
+	down              int32 // 1 while Redis is unreachable
+	downSince         int64 // Unix nanoseconds
+	unavailableEvents int64
+	recoveries        int64
+}
+
+// IsAvailable reports whether Redis is reachable. Callers skip the cache while it is
+// not, instead of waiting on every request for a connection that will fail.
+func (r *RedisClient) IsAvailable() bool {
+	return atomic.LoadInt32(&r.availability.down) == 0
+}
+
+// GetAvailability returns the availability of Redis
+func (r *RedisClient) GetAvailability() *AvailabilityStats {
+	stats := &AvailabilityStats{
+		Available:         r.IsAvailable(),
+		UnavailableEvents: atomic.LoadInt64(&r.availability.unavailableEvents),
+		Recoveries:        atomic.LoadInt64(&r.availability.recoveries),
+	}
+	if !stats.Available {
+		since := time.Unix(0, atomic.LoadInt64(&r.availability.downSince))
+		stats.UnavailableSince = &since
+	}
+	return stats
+}
+
+// markUnavailable records that Redis could not be reached
+func (r *RedisClient) markUnavailable(err error) {
+	if !atomic.CompareAndSwapInt32(&r.availability.down, 0, 1) {
+		return
+	}
+	atomic.StoreInt64(&r.availability.downSince, time.Now().UnixNano())
+	atomic.AddInt64(&r.availability.unavailableEvents, 1)
+	log.Printf("Redis became unavailable, serving from the database until it recovers: %v", err)
+}
+
+// markAvailable records that Redis can be reached again. Invalidations attempted while
+// it was down were lost, so cached configurations and flags are dropped.
+func (r *RedisClient) markAvailable() {
+	if r.IsAvailable() {
+		return
+	}
+
+	// Drop stale entries before callers start reading them again
+	for _, pattern := range []string{"config:*", "flags:*"} {
+		if err := r.InvalidatePattern(pattern); err != nil {
+			log.Printf("Failed to drop cache entries after Redis recovered: %v", err)
+			return
+		}
+	}
+
+	if atomic.CompareAndSwapInt32(&r.availability.down, 1, 0) {
+		atomic.AddInt64(&r.availability.recoveries, 1)
+		downtime := time.Since(time.Unix(0, atomic.LoadInt64(&r.availability.downSince)))
+		log.Printf("Redis is available again after %s", downtime.Round(time.Second))
+	}
+}
+
+// monitor pings Redis at the given interval, so that an outage is noticed even without
+// traffic and a recovery is noticed while callers skip the cache
+func (r *RedisClient) monitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopMonitor:
+			return
+		case <-ticker.C:
+			// Failures are recorded by the hook
+			if err := r.Health(); err == nil {
+				r.markAvailable()
+			}
+		}
+	}
+}
+
+// isUnavailableError reports whether an error means Redis could not be reached, as
+// opposed to an error reply or a missing key
+func isUnavailableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	// Replies from the server, including redis.Nil, mean it is reachable
+	var replyErr redis.Error
+	return !errors.As(err, &replyErr)
+}
+
+// availabilityHook marks Redis unavailable when a command fails to reach it
+type availabilityHook struct {
+	client *RedisClient
+}
+
+func (h availabilityHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if isUnavailableError(err) {
+			h.client.markUnavailable(err)
+		}
+		return conn, err
+	}
+}
+
+func (h availabilityHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		if isUnavailableError(err) {
+			h.client.markUnavailable(err)
+		}
+		return err
+	}
+}
+
+func (h availabilityHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		if isUnavailableError(err) {
+			h.client.markUnavailable(err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsUnavailableError_Unit(t *testing.T) {
+	assert.False(t, isUnavailableError(nil))
+	assert.False(t, isUnavailableError(redis.Nil))
+	assert.True(t, isUnavailableError(errors.New("dial tcp 127.0.0.1:6379: connect: connection refused")))
+}
+
+func TestRedisClient_Availability_Unit(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	host, port, _ := strings.Cut(mr.Addr(), ":")
+	client, err := NewRedisClient(&Config{Host: host, Port: port, TTL: time.Minute})
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.True(t, client.IsAvailable())
+	require.NoError(t, client.SetConfig(GenerateConfigKey("org", "app", "prod"), map[string]int{"version": 1}))
+
+	// A cache miss is not an outage
+	data, err := client.GetConfig("config:missing")
+	require.NoError(t, err)
+	assert.Nil(t, data)
+	assert.True(t, client.IsAvailable())
+
+	t.Run("outage is detected", func(t *testing.T) {
+		mr.Close()
+
+		_, err := client.GetConfig(GenerateConfigKey("org", "app", "prod"))
+		require.Error(t, err)
+
+		assert.False(t, client.IsAvailable())
+		availability := client.GetAvailability()
+		assert.Equal(t, int64(1), availability.UnavailableEvents)
+		assert.NotNil(t, availability.UnavailableSince)
+
+		// Further failures belong to the same outage
+		_, _ = client.GetConfig(GenerateConfigKey("org", "app", "prod"))
+		assert.Equal(t, int64(1), client.GetAvailability().UnavailableEvents)
+
+		// Without Redis there is nothing to recover to
+		client.markAvailable()
+		assert.False(t, client.IsAvailable())
+	})
+
+	t.Run("recovery drops entries that may be stale", func(t *testing.T) {
+		require.NoError(t, mr.Restart())
+
+		// The client may hold back new connections briefly after failed dials
+		require.Eventually(t, func() bool { return client.Health() == nil }, 5*time.Second, 50*time.Millisecond)
+		require.True(t, mr.Exists(GenerateConfigKey("org", "app", "prod")))
+		client.markAvailable()
+
+		assert.True(t, client.IsAvailable())
+		availability := client.GetAvailability()
+		assert.Equal(t, int64(1), availability.Recoveries)
+		assert.Nil(t, availability.UnavailableSince)
+		assert.False(t, mr.Exists(GenerateConfigKey("org", "app", "prod")))
+	})
+}
+
+func TestRedisClient_Monitor_Unit(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	host, port, _ := strings.Cut(mr.Addr(), ":")
+	client, err := NewRedisClient(&Config{Host: host, Port: port, TTL: time.Minute, HealthInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	defer client.Close()
+
+	// The monitor notices the outage without any traffic, and the recovery
+	mr.Close()
+	assert.Eventually(t, func() bool { return !client.IsAvailable() }, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, mr.Restart())
+	assert.Eventually(t, client.IsAvailable, 5*time.Second, 10*time.Millisecond)
+}
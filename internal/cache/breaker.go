@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBreakerOpen is returned by cache reads skipped while the circuit breaker is open
+var ErrBreakerOpen = errors.New("cache circuit breaker is open")
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerDisabled = "disabled"
+)
+
+// BreakerStats describes the circuit breaker of cache reads
+type BreakerStats struct {
+	State string `json:"state"`
+	// ConsecutiveFailures counts the failed reads since the last successful one
+	ConsecutiveFailures int64 `json:"consecutive_failures"`
+	// Trips counts the times the breaker opened
+	Trips int64 `json:"trips"`
+	// OpenUntil is set while the breaker is open
+	OpenUntil *time.Time `json:"open_until,omitempty"`
+}
+
+// breaker stops reading from Redis for a cooldown after a number of consecutive failed
+// reads, so that a sick Redis that still answers, e.g. with errors or corrupt entries,
+// does not add its latency to every request. Unreachable Redis is handled by availability.
+// Once the cooldown is over reads are tried again; the next failure opens it at once.
+type breaker struct {
+	threshold int64 // Consecutive failures that open the breaker; 0 disables it
+	cooldown  time.Duration
+	failures  int64
+	openUntil int64 // Unix nanoseconds
+	trips     int64
+}
+
+// allow reports whether a read may go to Redis
+func (b *breaker) allow() bool {
+	return b.threshold <= 0 || time.Now().UnixNano() >= atomic.LoadInt64(&b.openUntil)
+}
+
+// success records a read that Redis answered, a miss included
+func (b *breaker) success() {
+	atomic.StoreInt64(&b.failures, 0)
+}
+
+// failure records a failed read and opens the breaker when there were too many in a row
+func (b *breaker) failure(err error) {
+	if b.threshold <= 0 {
+		return
+	}
+	failures := atomic.AddInt64(&b.failures, 1)
+	if failures < b.threshold {
+		return
+	}
+
+	atomic.StoreInt64(&b.openUntil, time.Now().Add(b.cooldown).UnixNano())
+	atomic.AddInt64(&b.trips, 1)
+	log.Printf("Cache circuit breaker opened after %d failed reads, reading from the database for %s: %v", failures, b.cooldown, err)
+}
+
+// GetBreaker returns the state of the circuit breaker of cache reads
+func (r *RedisClient) GetBreaker() *BreakerStats {
+	stats := &BreakerStats{
+		State:               BreakerClosed,
+		ConsecutiveFailures: atomic.LoadInt64(&r.breaker.failures),
+		Trips:               atomic.LoadInt64(&r.breaker.trips),
+	}
+	if r.breaker.threshold <= 0 {
+		stats.State = BreakerDisabled
+	} else if !r.breaker.allow() {
+		stats.State = BreakerOpen
+		openUntil := time.Unix(0, atomic.LoadInt64(&r.breaker.openUntil))
+		stats.OpenUntil = &openUntil
+	}
+	return stats
+}
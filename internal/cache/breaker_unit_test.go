@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisClient_Breaker_Unit(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	host, port, _ := strings.Cut(mr.Addr(), ":")
+	client, err := NewRedisClient(&Config{Host: host, Port: port, TTL: time.Minute, BreakerThreshold: 3, BreakerCooldown: 100 * time.Millisecond})
+	require.NoError(t, err)
+	defer client.Close()
+
+	key := GenerateConfigKey("org", "app", "prod")
+	require.NoError(t, client.SetConfig(key, map[string]int{"version": 1}))
+	assert.Equal(t, BreakerClosed, client.GetBreaker().State)
+
+	t.Run("misses are not failures", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			data, err := client.GetConfig("config:missing")
+			require.NoError(t, err)
+			assert.Nil(t, data)
+		}
+		assert.Equal(t, int64(0), client.GetBreaker().ConsecutiveFailures)
+		assert.Equal(t, int64(0), client.GetStats().ReadErrors)
+	})
+
+	t.Run("failed reads open the breaker", func(t *testing.T) {
+		// Error replies mean Redis is reachable, but sick
+		mr.SetError("LOADING Redis is loading the dataset in memory")
+		for i := 0; i < 3; i++ {
+			_, err := client.GetConfig(key)
+			require.Error(t, err)
+			assert.False(t, errors.Is(err, ErrBreakerOpen))
+		}
+		assert.True(t, client.IsAvailable())
+
+		breaker := client.GetBreaker()
+		assert.Equal(t, BreakerOpen, breaker.State)
+		assert.Equal(t, int64(1), breaker.Trips)
+		assert.NotNil(t, breaker.OpenUntil)
+
+		// Reads skip Redis during the cooldown
+		_, err := client.GetConfig(key)
+		assert.ErrorIs(t, err, ErrBreakerOpen)
+		stats := client.GetStats()
+		assert.Equal(t, int64(3), stats.ReadErrors)
+		assert.Equal(t, int64(1), stats.Bypassed)
+		assert.Equal(t, int64(5), stats.Misses, "failures are not misses")
+	})
+
+	t.Run("the next failure after the cooldown opens it again", func(t *testing.T) {
+		time.Sleep(150 * time.Millisecond)
+		assert.Equal(t, BreakerClosed, client.GetBreaker().State)
+
+		_, err := client.GetConfig(key)
+		require.Error(t, err)
+		assert.Equal(t, BreakerOpen, client.GetBreaker().State)
+		assert.Equal(t, int64(2), client.GetBreaker().Trips)
+	})
+
+	t.Run("a successful read closes it", func(t *testing.T) {
+		mr.SetError("")
+		time.Sleep(150 * time.Millisecond)
+
+		data, err := client.GetConfig(key)
+		require.NoError(t, err)
+		assert.NotNil(t, data)
+
+		breaker := client.GetBreaker()
+		assert.Equal(t, BreakerClosed, breaker.State)
+		assert.Equal(t, int64(0), breaker.ConsecutiveFailures)
+		assert.Nil(t, breaker.OpenUntil)
+	})
+}
+
+func TestRedisClient_BreakerDisabled_Unit(t *testing.T) {
+	cache, cleanup := setupMockRedis(t)
+	defer cleanup()
+
+	for i := 0; i < 10; i++ {
+		cache.breaker.failure(errors.New("read failed"))
+	}
+	assert.Equal(t, BreakerDisabled, cache.GetBreaker().State)
+	assert.True(t, cache.breaker.allow())
+}
+
+func TestConfig_Validate_Breaker_Unit(t *testing.T) {
+	config := &Config{Host: "localhost", Port: "6379", BreakerThreshold: -1}
+	assert.Error(t, config.Validate())
+
+	t.Setenv("CACHE_BREAKER_THRESHOLD", "10")
+	t.Setenv("CACHE_BREAKER_COOLDOWN", "60")
+	config = NewConfig()
+	assert.Equal(t, 10, config.BreakerThreshold)
+	assert.Equal(t, time.Minute, config.BreakerCooldown)
+}
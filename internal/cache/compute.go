@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// computeKeyPrefix namespaces cached results of computed endpoints
+const computeKeyPrefix = "compute:"
+
+// HashContent returns a hex-encoded SHA-256 hash of the given content
+func HashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateComputeKey generates a cache key for a computed result. The parts should
+// include every input of the computation, with configuration versions identified by
+// their content hash so that a changed version never resolves to a stale entry.
+func GenerateComputeKey(kind string, parts ...string) string {
+	return fmt.Sprintf("%s%s:%s", computeKeyPrefix, kind, HashContent([]byte(strings.Join(parts, "\x00"))))
+}
+
+// GetOrCompute returns the result cached under key, or runs compute and caches its
+// result for the configured compute TTL. Cache failures are logged and fall back to
+// computing the result directly; a nil client disables caching entirely, and so does
+// Redis being unavailable.
+func GetOrCompute[T any](r *RedisClient, key string, compute func() (T, error)) (T, error) {
+	if r == nil || r.computeTTL <= 0 || !r.IsAvailable() {
+		return compute()
+	}
+
+	if cached, err := r.getComputed(key); err != nil {
+		log.Printf("Failed to read computed result from cache: %v", err)
+	} else if cached != nil {
+		var result T
+		if err := json.Unmarshal(cached, &result); err == nil {
+			return result, nil
+		}
+		log.Printf("Discarding undecodable computed result for key %s", key)
+	}
+
+	result, err := compute()
+	if err != nil {
+		return result, err
+	}
+
+	if err := r.setComputed(key, result); err != nil {
+		log.Printf("Failed to cache computed result: %v", err)
+	}
+
+	return result, nil
+}
+
+// getComputed retrieves a raw computed result, returning nil on a cache miss
+func (r *RedisClient) getComputed(key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	val, err := r.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			atomic.AddInt64(&r.stats.Computed.Misses, 1)
+			return nil, nil
+		}
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return nil, err
+	}
+
+	atomic.AddInt64(&r.stats.Computed.Hits, 1)
+	return val, nil
+}
+
+// setComputed stores a computed result with the compute TTL
+func (r *RedisClient) setComputed(key string, result interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return fmt.Errorf("failed to marshal computed result: %w", err)
+	}
+
+	if err := r.client.Set(ctx, key, data, r.computeTTL).Err(); err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return fmt.Errorf("failed to set computed result in cache: %w", err)
+	}
+
+	atomic.AddInt64(&r.stats.Computed.Sets, 1)
+	return nil
+}
+
+// InvalidateComputed removes all cached computed results
+func (r *RedisClient) InvalidateComputed() error {
+	return r.InvalidatePattern(computeKeyPrefix + "*")
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type computedResult struct {
+	Value string `json:"value"`
+}
+
+func TestGetOrCompute_Unit(t *testing.T) {
+	cache, cleanup := setupMockRedis(t)
+	defer cleanup()
+	cache.computeTTL = 30 * time.Second
+
+	key := GenerateComputeKey("diff", "v1-hash", "v2-hash")
+	calls := 0
+	compute := func() (*computedResult, error) {
+		calls++
+		return &computedResult{Value: "expensive"}, nil
+	}
+
+	first, err := GetOrCompute(cache, key, compute)
+	require.NoError(t, err)
+	assert.Equal(t, "expensive", first.Value)
+
+	second, err := GetOrCompute(cache, key, compute)
+	require.NoError(t, err)
+	assert.Equal(t, "expensive", second.Value)
+	assert.Equal(t, 1, calls, "second call should be served from cache")
+
+	// A different version hash must not reuse the cached result
+	_, err = GetOrCompute(cache, GenerateComputeKey("diff", "v1-hash", "v3-hash"), compute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// Computed results are counted apart from configuration reads
+	stats := cache.GetStats()
+	assert.Equal(t, UseStats{Hits: 1, Misses: 2, Sets: 2}, stats.Computed)
+	assert.Zero(t, stats.Hits)
+	assert.Zero(t, stats.Misses)
+	assert.Zero(t, stats.Sets)
+}
+
+func TestGetOrCompute_ErrorsNotCached_Unit(t *testing.T) {
+	cache, cleanup := setupMockRedis(t)
+	defer cleanup()
+	cache.computeTTL = 30 * time.Second
+
+	key := GenerateComputeKey("diff", "a", "b")
+	_, err := GetOrCompute(cache, key, func() (*computedResult, error) {
+		return nil, errors.New("boom")
+	})
+	assert.Error(t, err)
+
+	result, err := GetOrCompute(cache, key, func() (*computedResult, error) {
+		return &computedResult{Value: "ok"}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.Value)
+}
+
+func TestGetOrCompute_NilClient_Unit(t *testing.T) {
+	calls := 0
+	for i := 0; i < 2; i++ {
+		_, err := GetOrCompute(nil, "compute:test", func() (int, error) {
+			calls++
+			return 42, nil
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestInvalidateComputed_Unit(t *testing.T) {
+	cache, cleanup := setupMockRedis(t)
+	defer cleanup()
+	cache.computeTTL = 30 * time.Second
+
+	key := GenerateComputeKey("blame", "x")
+	calls := 0
+	compute := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	_, err := GetOrCompute(cache, key, compute)
+	require.NoError(t, err)
+	require.NoError(t, cache.InvalidateComputed())
+
+	_, err = GetOrCompute(cache, key, compute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// idempotencyKeyPrefix namespaces stored results of idempotent requests
+const idempotencyKeyPrefix = "idempotency:"
+
+// IdempotencyRecord is the stored outcome of a request made with an idempotency key.
+// A record without a status code belongs to a request that is still in progress.
+type IdempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"` // Hash of the request the key was first used with
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// InProgress reports whether the original request has not completed yet
+func (rec *IdempotencyRecord) InProgress() bool {
+	return rec.StatusCode == 0
+}
+
+// GenerateIdempotencyKey generates a cache key for a client-supplied idempotency key.
+// The principal (who made the request) and the scope (e.g. the request path) keep the
+// same key apart across callers and resources. Both are hashed, never stored.
+func GenerateIdempotencyKey(principal, scope, key string) string {
+	return idempotencyKeyPrefix + HashContent([]byte(strings.Join([]string{principal, scope, key}, "\x00")))
+}
+
+// ReserveIdempotencyKey claims an idempotency key for a new request. It returns nil if the
+// key was free and is now reserved, or the existing record if the key was already used.
+func (r *RedisClient) ReserveIdempotencyKey(key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	data, err := json.Marshal(&IdempotencyRecord{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	reserved, err := r.client.SetNX(ctx, key, data, ttl).Result()
+	if err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if reserved {
+		atomic.AddInt64(&r.stats.Idempotency.Misses, 1)
+		return nil, nil
+	}
+
+	existing, err := r.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			// The reservation expired in between; treat the key as free
+			return r.ReserveIdempotencyKey(key, fingerprint, ttl)
+		}
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return nil, fmt.Errorf("failed to get idempotency record: %w", err)
+	}
+
+	var record IdempotencyRecord
+	if err := json.Unmarshal(existing, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal idempotency record: %w", err)
+	}
+
+	atomic.AddInt64(&r.stats.Idempotency.Hits, 1)
+	return &record, nil
+}
+
+// CompleteIdempotencyKey stores the outcome of the request that reserved a key
+func (r *RedisClient) CompleteIdempotencyKey(key string, record *IdempotencyRecord, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal idempotency record: %w", err)
+	}
+
+	if err := r.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return fmt.Errorf("failed to store idempotency record: %w", err)
+	}
+
+	atomic.AddInt64(&r.stats.Idempotency.Sets, 1)
+	return nil
+}
+
+// ReleaseIdempotencyKey frees a reserved key, so that a failed request can be retried
+func (r *RedisClient) ReleaseIdempotencyKey(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := r.client.Del(ctx, key).Err(); err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+
+	return nil
+}
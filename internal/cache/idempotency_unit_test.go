@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKey_Unit(t *testing.T) {
+	cache, cleanup := setupMockRedis(t)
+	defer cleanup()
+
+	key := GenerateIdempotencyKey("admin:token", "/admin/orgs/acme/apps/web/envs/prod/config", "retry-1")
+	assert.NotEqual(t, key, GenerateIdempotencyKey("admin:token", "/admin/orgs/acme/apps/web/envs/dev/config", "retry-1"))
+	assert.NotEqual(t, key, GenerateIdempotencyKey("admin:other", "/admin/orgs/acme/apps/web/envs/prod/config", "retry-1"))
+
+	// The first request reserves the key
+	existing, err := cache.ReserveIdempotencyKey(key, "fingerprint", time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// A concurrent repeat sees the request in progress
+	existing, err = cache.ReserveIdempotencyKey(key, "fingerprint", time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.True(t, existing.InProgress())
+
+	// Once completed, repeats get the stored outcome
+	record := &IdempotencyRecord{Fingerprint: "fingerprint", StatusCode: 200, ContentType: "application/json; charset=utf-8", Body: []byte(`{"version":3}`)}
+	require.NoError(t, cache.CompleteIdempotencyKey(key, record, time.Hour))
+
+	existing, err = cache.ReserveIdempotencyKey(key, "fingerprint", time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.False(t, existing.InProgress())
+	assert.Equal(t, 200, existing.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", existing.ContentType)
+	assert.JSONEq(t, `{"version":3}`, string(existing.Body))
+
+	// Released keys can be reserved again
+	require.NoError(t, cache.ReleaseIdempotencyKey(key))
+	existing, err = cache.ReserveIdempotencyKey(key, "other", time.Hour)
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// Idempotency keys are counted apart from configuration reads
+	stats := cache.GetStats()
+	assert.Equal(t, UseStats{Hits: 2, Misses: 2, Sets: 1}, stats.Idempotency)
+	assert.Zero(t, stats.Hits)
+	assert.Zero(t, stats.Misses)
+	assert.Zero(t, stats.Sets)
+}
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Keys of the organization read counters. Each organization has a counter per month with
+// its running total; the counters changed since the last flush are listed in the pending
+// set, which a flush moves to the draining set until the totals have been stored.
+const (
+	orgReadsKeyPrefix   = "quota:reads:"
+	orgReadsPendingKey  = "quota:reads:pending"
+	orgReadsDrainingKey = "quota:reads:draining"
+)
+
+// orgReadsMonthLayout is the layout of the month part of organization read counters
+const orgReadsMonthLayout = "2006-01"
+
+// orgReadsTTL keeps the counter of a month until well after the month has been flushed
+const orgReadsTTL = 62 * 24 * time.Hour
+
+// OrgReadCount is the running total of an organization's reads in one month
+type OrgReadCount struct {
+	Organization string
+	Month        time.Time
+	Reads        int64
+}
+
+// OrgReadsMonth returns the first day of the month of a time, in UTC
+func OrgReadsMonth(at time.Time) time.Time {
+	at = at.UTC()
+	return time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// orgReadsMember identifies the counter of an organization's month in the pending set
+func orgReadsMember(orgSlug string, month time.Time) string {
+	return orgSlug + "|" + month.UTC().Format(orgReadsMonthLayout)
+}
+
+// orgReadsKey returns the key of the counter of an organization's month
+func orgReadsKey(orgSlug string, month time.Time) string {
+	return orgReadsKeyPrefix + orgReadsMember(orgSlug, month)
+}
+
+// RecordOrgRead counts a configuration read of an organization and returns the
+// organization's reads in the month of the read, including it
+func (r *RedisClient) RecordOrgRead(orgSlug string, at time.Time) (int64, error) {
+	return r.AddOrgReads(orgSlug, at, 1)
+}
+
+// AddOrgReads adds reads to the counter of an organization's month and returns the new total
+func (r *RedisClient) AddOrgReads(orgSlug string, at time.Time, reads int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	month := OrgReadsMonth(at)
+	key := orgReadsKey(orgSlug, month)
+	pipe := r.client.Pipeline()
+	total := pipe.IncrBy(ctx, key, reads)
+	pipe.Expire(ctx, key, orgReadsTTL)
+	pipe.SAdd(ctx, orgReadsPendingKey, orgReadsMember(orgSlug, month))
+	if _, err := pipe.Exec(ctx); err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return 0, fmt.Errorf("failed to record organization reads: %w", err)
+	}
+	return total.Val(), nil
+}
+
+// GetOrgReads returns the reads of an organization counted in the month of a time, and
+// whether the month has a counter at all
+func (r *RedisClient) GetOrgReads(orgSlug string, at time.Time) (int64, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	reads, err := r.client.Get(ctx, orgReadsKey(orgSlug, OrgReadsMonth(at))).Int64()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return 0, false, fmt.Errorf("failed to get organization reads: %w", err)
+	}
+	return reads, true, nil
+}
+
+// DrainOrgReads returns the totals of the organization read counters changed since the
+// last flush. As with DrainUsage, they are returned again until ClearDrainedOrgReads is
+// called; storing a total twice is harmless, as totals replace the stored ones.
+func (r *RedisClient) DrainOrgReads() ([]OrgReadCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	draining, err := r.client.Exists(ctx, orgReadsDrainingKey).Result()
+	if err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return nil, fmt.Errorf("failed to check drained organization reads: %w", err)
+	}
+	if draining == 0 {
+		if err := r.renameIfExists(ctx, orgReadsPendingKey, orgReadsDrainingKey); err != nil {
+			return nil, err
+		}
+	}
+
+	members, err := r.client.SMembers(ctx, orgReadsDrainingKey).Result()
+	if err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return nil, fmt.Errorf("failed to read changed organization reads: %w", err)
+	}
+
+	counts := make([]OrgReadCount, 0, len(members))
+	for _, member := range members {
+		orgSlug, monthPart, ok := strings.Cut(member, "|")
+		if !ok {
+			continue
+		}
+		month, err := time.Parse(orgReadsMonthLayout, monthPart)
+		if err != nil {
+			continue
+		}
+
+		value, err := r.client.Get(ctx, orgReadsKeyPrefix+member).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			atomic.AddInt64(&r.stats.Errors, 1)
+			return nil, fmt.Errorf("failed to read organization reads: %w", err)
+		}
+		reads, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		counts = append(counts, OrgReadCount{Organization: orgSlug, Month: month, Reads: reads})
+	}
+
+	return counts, nil
+}
+
+// ClearDrainedOrgReads forgets the counters returned by DrainOrgReads once they are stored
+func (r *RedisClient) ClearDrainedOrgReads() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := r.client.Del(ctx, orgReadsDrainingKey).Err(); err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return fmt.Errorf("failed to clear drained organization reads: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgReads_Unit(t *testing.T) {
+	cache, cleanup := setupMockRedis(t)
+	defer cleanup()
+
+	october := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	november := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	reads, err := cache.RecordOrgRead("acme", october)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), reads)
+	reads, err = cache.RecordOrgRead("acme", october.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), reads)
+	_, err = cache.RecordOrgRead("acme", november)
+	require.NoError(t, err)
+	reads, err = cache.AddOrgReads("globex", october, 40)
+	require.NoError(t, err)
+	assert.Equal(t, int64(40), reads)
+
+	t.Run("reads of a month", func(t *testing.T) {
+		reads, exists, err := cache.GetOrgReads("acme", october.AddDate(0, 0, 10))
+		require.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, int64(2), reads)
+
+		reads, exists, err = cache.GetOrgReads("acme", october.AddDate(0, -1, 0))
+		require.NoError(t, err)
+		assert.False(t, exists)
+		assert.Zero(t, reads)
+	})
+
+	counts, err := cache.DrainOrgReads()
+	require.NoError(t, err)
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Organization != counts[j].Organization {
+			return counts[i].Organization < counts[j].Organization
+		}
+		return counts[i].Month.Before(counts[j].Month)
+	})
+	assert.Equal(t, []OrgReadCount{
+		{Organization: "acme", Month: OrgReadsMonth(october), Reads: 2},
+		{Organization: "acme", Month: november, Reads: 1},
+		{Organization: "globex", Month: OrgReadsMonth(october), Reads: 40},
+	}, counts)
+
+	t.Run("drained totals are returned until cleared", func(t *testing.T) {
+		// Reads counted during a flush are stored by the next one
+		_, err := cache.RecordOrgRead("acme", october)
+		require.NoError(t, err)
+
+		again, err := cache.DrainOrgReads()
+		require.NoError(t, err)
+		assert.Len(t, again, 3)
+
+		require.NoError(t, cache.ClearDrainedOrgReads())
+		next, err := cache.DrainOrgReads()
+		require.NoError(t, err)
+		assert.Equal(t, []OrgReadCount{{Organization: "acme", Month: OrgReadsMonth(october), Reads: 3}}, next)
+
+		require.NoError(t, cache.ClearDrainedOrgReads())
+		empty, err := cache.DrainOrgReads()
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+	})
+}
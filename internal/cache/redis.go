@@ -4,13 +4,16 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -24,7 +27,37 @@ type CacheStats struct {
 	Sets        int64 `json:"sets"`
 	Deletes     int64 `json:"deletes"`
 	Errors      int64 `json:"errors"`
+	ReadErrors  int64 `json:"read_errors"` // Reads that failed, as opposed to misses
+	Bypassed    int64 `json:"bypassed"`    // Reads skipped while the circuit breaker was open
 	TotalKeys   int64 `json:"total_keys"`
+
+	// Other uses of the cache are counted apart, so that they do not skew the hit ratio
+	// of configurations
+	Computed    UseStats `json:"computed"`    // Results of computed endpoints
+	Idempotency UseStats `json:"idempotency"` // Idempotency keys: misses are new requests, hits retries
+}
+
+// UseStats counts the reads and writes of one use of the cache
+type UseStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	Sets   int64 `json:"sets"`
+}
+
+// load returns a copy of the counters
+func (s *UseStats) load() UseStats {
+	return UseStats{
+		Hits:   atomic.LoadInt64(&s.Hits),
+		Misses: atomic.LoadInt64(&s.Misses),
+		Sets:   atomic.LoadInt64(&s.Sets),
+	}
+}
+
+// reset sets the counters to zero
+func (s *UseStats) reset() {
+	atomic.StoreInt64(&s.Hits, 0)
+	atomic.StoreInt64(&s.Misses, 0)
+	atomic.StoreInt64(&s.Sets, 0)
 }
 
 // GetHitRatio returns the cache hit ratio as a percentage
@@ -38,32 +71,68 @@ func (s *CacheStats) GetHitRatio() float64 {
 
 // RedisClient wraps the Redis client with configuration caching functionality
 type RedisClient struct {
-	client       *redis.Client
+	client       redis.UniversalClient
 	ttl          time.Duration
 	shortTTL     time.Duration // For frequently changing data
 	longTTL      time.Duration // For rarely changing data
+	computeTTL   time.Duration // For results of computed endpoints
 	stats        *CacheStats
 	enableCompress bool
+	availability availability
+	breaker      breaker
+	stopMonitor  chan struct{}
 }
 
+// Redis deployment modes
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+
 // Config holds Redis configuration
 type Config struct {
-	Host           string
-	Port           string
-	Password       string
-	DB             int
-	TTL            time.Duration // Default TTL
-	ShortTTL       time.Duration // For frequently changing data
-	LongTTL        time.Duration // For rarely changing data
-	EnableCompress bool          // Enable compression for large values
+	Mode             string   // standalone (default), sentinel or cluster
+	Host             string   // Standalone mode only
+	Port             string   // Standalone mode only
+	MasterName       string   // Sentinel mode: name of the monitored master
+	SentinelAddrs    []string // Sentinel mode: host:port of the sentinels
+	SentinelPassword string   // Sentinel mode: password of the sentinels, if they require one
+	ClusterAddrs     []string // Cluster mode: host:port of one or more cluster nodes
+	Username         string   // ACL user; empty authenticates with the password only
+	Password         string
+	DB               int
+	TLS              bool          // Connect over TLS, as most managed Redis services require
+	PoolSize         int           // Maximum number of connections (0 uses 10 per CPU)
+	DialTimeout      time.Duration // Timeout for establishing connections
+	ReadTimeout      time.Duration // Timeout for reading replies
+	WriteTimeout     time.Duration // Timeout for writing commands
+	TTL              time.Duration // Default TTL
+	ShortTTL         time.Duration // For frequently changing data
+	LongTTL          time.Duration // For rarely changing data
+	ComputeTTL       time.Duration // For results of computed endpoints (0 disables)
+	EnableCompress   bool          // Enable compression for large values
+	HealthInterval   time.Duration // How often to ping Redis to detect outages and recoveries (0 disables)
+	BreakerThreshold int           // Consecutive failed reads that stop reads from Redis (0 disables)
+	BreakerCooldown  time.Duration // How long reads skip Redis once the breaker opened
 }
 
+// Hardcoded TTLs, used when neither the environment variables nor a Config set a TTL
+const (
+	DefaultTTL        = 300 * time.Second  // 5 minutes
+	DefaultShortTTL   = 60 * time.Second   // 1 minute for frequently changing data
+	DefaultLongTTL    = 3600 * time.Second // 1 hour for rarely changing data
+	DefaultComputeTTL = 30 * time.Second   // 30 seconds for computed results
+)
+
 // NewConfig creates a new Redis configuration from environment variables
 func NewConfig() *Config {
 	// Default TTL values
-	ttl := 300 * time.Second      // Default 5 minutes
-	shortTTL := 60 * time.Second  // 1 minute for frequently changing data
-	longTTL := 3600 * time.Second // 1 hour for rarely changing data
+	ttl := DefaultTTL
+	shortTTL := DefaultShortTTL
+	longTTL := DefaultLongTTL
+	computeTTL := DefaultComputeTTL
 
 	// Parse TTL from environment
 	if ttlStr := os.Getenv("CACHE_TTL"); ttlStr != "" {
@@ -84,6 +153,12 @@ func NewConfig() *Config {
 		}
 	}
 
+	if computeTTLStr := os.Getenv("CACHE_COMPUTE_TTL"); computeTTLStr != "" {
+		if parsedTTL, err := strconv.Atoi(computeTTLStr); err == nil {
+			computeTTL = time.Duration(parsedTTL) * time.Second
+		}
+	}
+
 	db := 0
 	if dbStr := os.Getenv("REDIS_DB"); dbStr != "" {
 		if parsedDB, err := strconv.Atoi(dbStr); err == nil {
@@ -93,48 +168,241 @@ func NewConfig() *Config {
 
 	enableCompress := getEnv("CACHE_ENABLE_COMPRESSION", "false") == "true"
 
+	healthInterval := 5 * time.Second
+	if intervalStr := os.Getenv("CACHE_HEALTH_INTERVAL"); intervalStr != "" {
+		if parsedInterval, err := strconv.Atoi(intervalStr); err == nil {
+			healthInterval = time.Duration(parsedInterval) * time.Second
+		}
+	}
+
+
 	return &Config{
-		Host:           getEnv("REDIS_HOST", "localhost"),
-		Port:           getEnv("REDIS_PORT", "6379"),
-		Password:       getEnv("REDIS_PASSWORD", ""),
-		DB:             db,
-		TTL:            ttl,
-		ShortTTL:       shortTTL,
-		LongTTL:        longTTL,
-		EnableCompress: enableCompress,
+		Mode:             strings.ToLower(getEnv("REDIS_MODE", ModeStandalone)),
+		Host:             getEnv("REDIS_HOST", "localhost"),
+		Port:             getEnv("REDIS_PORT", "6379"),
+		MasterName:       getEnv("REDIS_MASTER_NAME", ""),
+		SentinelAddrs:    getEnvList("REDIS_SENTINEL_ADDRS"),
+		SentinelPassword: getEnv("REDIS_SENTINEL_PASSWORD", ""),
+		ClusterAddrs:     getEnvList("REDIS_CLUSTER_ADDRS"),
+		Username:         getEnv("REDIS_USERNAME", ""),
+		Password:         getEnv("REDIS_PASSWORD", ""),
+		DB:               db,
+		TLS:              strings.EqualFold(getEnv("REDIS_TLS", "false"), "true"),
+		PoolSize:         getEnvInt("REDIS_POOL_SIZE", 0),
+		DialTimeout:      time.Duration(getEnvInt("REDIS_DIAL_TIMEOUT", 5)) * time.Second,
+		ReadTimeout:      time.Duration(getEnvInt("REDIS_READ_TIMEOUT", 3)) * time.Second,
+		WriteTimeout:     time.Duration(getEnvInt("REDIS_WRITE_TIMEOUT", 3)) * time.Second,
+		TTL:              ttl,
+		ShortTTL:         shortTTL,
+		LongTTL:          longTTL,
+		ComputeTTL:       computeTTL,
+		EnableCompress:   enableCompress,
+		HealthInterval:   healthInterval,
+		BreakerThreshold: getEnvInt("CACHE_BREAKER_THRESHOLD", 5),
+		BreakerCooldown:  time.Duration(getEnvInt("CACHE_BREAKER_COOLDOWN", 30)) * time.Second,
+	}
+}
+
+// Validate checks the connection options. Zero timeouts are allowed so that configurations
+// built in code keep the client defaults.
+func (c *Config) Validate() error {
+	switch c.Mode {
+	case "", ModeStandalone:
+		if c.Host == "" {
+			return fmt.Errorf("invalid Redis configuration: host is required")
+		}
+		if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("invalid Redis configuration: port must be between 1 and 65535, got %q", c.Port)
+		}
+	case ModeSentinel:
+		if c.MasterName == "" {
+			return fmt.Errorf("invalid Redis configuration: sentinel mode requires a master name")
+		}
+		if err := validateAddrs("sentinel", c.SentinelAddrs); err != nil {
+			return err
+		}
+	case ModeCluster:
+		if err := validateAddrs("cluster", c.ClusterAddrs); err != nil {
+			return err
+		}
+		// A cluster only has database 0
+		if c.DB != 0 {
+			return fmt.Errorf("invalid Redis configuration: cluster mode does not support selecting a database")
+		}
+	default:
+		return fmt.Errorf("invalid Redis configuration: unknown mode %q, expected %s, %s or %s", c.Mode, ModeStandalone, ModeSentinel, ModeCluster)
+	}
+	if c.DB < 0 {
+		return fmt.Errorf("invalid Redis configuration: database must not be negative")
+	}
+	if c.Username != "" && c.Password == "" {
+		return fmt.Errorf("invalid Redis configuration: username requires a password")
+	}
+	if c.PoolSize < 0 {
+		return fmt.Errorf("invalid Redis configuration: pool size must not be negative")
+	}
+	if c.DialTimeout < 0 || c.ReadTimeout < 0 || c.WriteTimeout < 0 {
+		return fmt.Errorf("invalid Redis configuration: timeouts must not be negative")
+	}
+	if c.BreakerThreshold < 0 || c.BreakerCooldown < 0 {
+		return fmt.Errorf("invalid Redis configuration: circuit breaker settings must not be negative")
+	}
+	return nil
+}
+
+// validateAddrs checks that a mode is given at least one address and that each is a
+// host:port pair
+func validateAddrs(mode string, addrs []string) error {
+	if len(addrs) == 0 {
+		return fmt.Errorf("invalid Redis configuration: %s mode requires at least one address", mode)
+	}
+	for _, addr := range addrs {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("invalid Redis configuration: %s address %q is not host:port", mode, addr)
+		}
+	}
+	return nil
+}
+
+// Address describes where the configuration connects to, for logging
+func (c *Config) Address() string {
+	switch c.Mode {
+	case ModeSentinel:
+		return fmt.Sprintf("master %s via sentinels %s", c.MasterName, strings.Join(c.SentinelAddrs, ","))
+	case ModeCluster:
+		return "cluster " + strings.Join(c.ClusterAddrs, ",")
+	default:
+		return fmt.Sprintf("%s:%s", c.Host, c.Port)
 	}
 }
 
-// NewRedisClient creates a new Redis client
+// tlsConfig returns the TLS settings, or nil when TLS is disabled. The server name is
+// taken from the address of each node when connecting.
+func (c *Config) tlsConfig() *tls.Config {
+	if !c.TLS {
+		return nil
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
+// options builds the standalone client options from the configuration
+func (c *Config) options() *redis.Options {
+	return &redis.Options{
+		Addr:         fmt.Sprintf("%s:%s", c.Host, c.Port),
+		Username:     c.Username,
+		Password:     c.Password,
+		DB:           c.DB,
+		PoolSize:     c.PoolSize,
+		DialTimeout:  c.DialTimeout,
+		ReadTimeout:  c.ReadTimeout,
+		WriteTimeout: c.WriteTimeout,
+		TLSConfig:    c.tlsConfig(),
+	}
+}
+
+// newClient builds the client for the configured mode. Sentinel mode follows the master
+// across failovers; cluster mode routes each key to the node that owns it.
+func (c *Config) newClient() redis.UniversalClient {
+	switch c.Mode {
+	case ModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       c.MasterName,
+			SentinelAddrs:    c.SentinelAddrs,
+			SentinelPassword: c.SentinelPassword,
+			Username:         c.Username,
+			Password:         c.Password,
+			DB:               c.DB,
+			PoolSize:         c.PoolSize,
+			DialTimeout:      c.DialTimeout,
+			ReadTimeout:      c.ReadTimeout,
+			WriteTimeout:     c.WriteTimeout,
+			TLSConfig:        c.tlsConfig(),
+		})
+	case ModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        c.ClusterAddrs,
+			Username:     c.Username,
+			Password:     c.Password,
+			PoolSize:     c.PoolSize,
+			DialTimeout:  c.DialTimeout,
+			ReadTimeout:  c.ReadTimeout,
+			WriteTimeout: c.WriteTimeout,
+			TLSConfig:    c.tlsConfig(),
+		})
+	default:
+		return redis.NewClient(c.options())
+	}
+}
+
+// NewRedisClient creates a new Redis client. A TTL, short TTL or long TTL of the
+// configuration that is not positive, e.g. unset in a Config built in code, is replaced by
+// its hardcoded default, as Redis would otherwise keep or drop such keys at once.
 func NewRedisClient(config *Config) (*RedisClient, error) {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", config.Host, config.Port),
-		Password: config.Password,
-		DB:       config.DB,
-	})
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	rdb := config.newClient()
 
 	// Test the connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
-	log.Printf("Successfully connected to Redis %s:%s", config.Host, config.Port)
+	log.Printf("Successfully connected to Redis %s (tls=%t pool_size=%d)", config.Address(), config.TLS, config.PoolSize)
 
-	return &RedisClient{
+	client := &RedisClient{
 		client:       rdb,
-		ttl:          config.TTL,
-		shortTTL:     config.ShortTTL,
-		longTTL:      config.LongTTL,
+		ttl:          positiveTTL("TTL", config.TTL, DefaultTTL),
+		shortTTL:     positiveTTL("short TTL", config.ShortTTL, DefaultShortTTL),
+		longTTL:      positiveTTL("long TTL", config.LongTTL, DefaultLongTTL),
+		computeTTL:   config.ComputeTTL,
 		enableCompress: config.EnableCompress,
 		stats:        &CacheStats{},
-	}, nil
+		breaker:      breaker{threshold: int64(config.BreakerThreshold), cooldown: config.BreakerCooldown},
+		stopMonitor:  make(chan struct{}),
+	}
+
+	// Track outages and recoveries after startup
+	rdb.AddHook(availabilityHook{client: client})
+	if config.HealthInterval > 0 {
+		go client.monitor(config.HealthInterval)
+	}
+
+	return client, nil
+}
+
+// positiveTTL returns a configured TTL, or its default if the TTL is not positive
+func positiveTTL(name string, ttl, fallback time.Duration) time.Duration {
+	if ttl > 0 {
+		return ttl
+	}
+	log.Printf("Cache %s of %s is not positive, using the default of %s", name, ttl, fallback)
+	return fallback
+}
+
+// configTTL resolves the TTL a configuration is cached for: the given TTL, such as an
+// environment's override, then the client's TTL, then DefaultTTL, whichever is the first
+// to be positive
+func (r *RedisClient) configTTL(ttl time.Duration) time.Duration {
+	if ttl > 0 {
+		return ttl
+	}
+	if r.ttl > 0 {
+		return r.ttl
+	}
+	return DefaultTTL
 }
 
 // Close closes the Redis connection
 func (r *RedisClient) Close() error {
+	if r.stopMonitor != nil {
+		close(r.stopMonitor)
+	}
 	return r.client.Close()
 }
 
@@ -152,45 +420,64 @@ func (r *RedisClient) Health() error {
 
 // GetConfig retrieves a configuration from cache
 func (r *RedisClient) GetConfig(key string) ([]byte, error) {
+	// A sick Redis is left alone until the breaker's cooldown is over
+	if !r.breaker.allow() {
+		atomic.AddInt64(&r.stats.Bypassed, 1)
+		return nil, ErrBreakerOpen
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
 	val, err := r.client.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
+			r.breaker.success()
 			atomic.AddInt64(&r.stats.Misses, 1)
 			return nil, nil // Cache miss
 		}
-		atomic.AddInt64(&r.stats.Errors, 1)
-		return nil, fmt.Errorf("failed to get config from cache: %w", err)
+		err = fmt.Errorf("failed to get config from cache: %w", err)
+		r.readFailed(err)
+		return nil, err
 	}
 
-	atomic.AddInt64(&r.stats.Hits, 1)
-
 	// Handle compression
 	data := []byte(val)
 	if r.enableCompress && strings.HasPrefix(key, "compressed:") {
 		decompressed, err := r.decompress(data)
 		if err != nil {
-			atomic.AddInt64(&r.stats.Errors, 1)
-			return nil, fmt.Errorf("failed to decompress cached data: %w", err)
+			err = fmt.Errorf("failed to decompress cached data: %w", err)
+			r.readFailed(err)
+			return nil, err
 		}
 		data = decompressed
 	}
 
+	r.breaker.success()
+	atomic.AddInt64(&r.stats.Hits, 1)
 	return data, nil
 }
 
+// readFailed counts a failed read towards the error counters and the circuit breaker
+func (r *RedisClient) readFailed(err error) {
+	atomic.AddInt64(&r.stats.Errors, 1)
+	atomic.AddInt64(&r.stats.ReadErrors, 1)
+	r.breaker.failure(err)
+}
+
 // SetConfig stores a configuration in cache with default TTL
 func (r *RedisClient) SetConfig(key string, config interface{}) error {
 	return r.SetConfigWithTTL(key, config, r.ttl)
 }
 
-// SetConfigWithTTL stores a configuration in cache with custom TTL
+// SetConfigWithTTL stores a configuration in cache with custom TTL. A TTL that is not
+// positive falls back to the default TTL rather than storing the key without expiry.
 func (r *RedisClient) SetConfigWithTTL(key string, config interface{}, ttl time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
+	ttl = r.configTTL(ttl)
+
 	data, err := json.Marshal(config)
 	if err != nil {
 		atomic.AddInt64(&r.stats.Errors, 1)
@@ -227,7 +514,7 @@ func (r *RedisClient) DeleteConfig(key string) error {
 	// Delete both regular and compressed versions
 	keys := []string{key, "compressed:" + key}
 
-	deleted, err := r.client.Del(ctx, keys...).Result()
+	deleted, err := r.deleteKeys(ctx, keys)
 	if err != nil {
 		atomic.AddInt64(&r.stats.Errors, 1)
 		return fmt.Errorf("failed to delete config from cache: %w", err)
@@ -248,11 +535,10 @@ func (r *RedisClient) InvalidatePattern(pattern string) error {
 
 	// Use SCAN instead of KEYS for better performance
 	var keys []string
-	iter := r.client.Scan(ctx, 0, pattern, 0).Iterator()
-	for iter.Next(ctx) {
-		keys = append(keys, iter.Val())
-	}
-	if err := iter.Err(); err != nil {
+	err := r.scanKeys(ctx, pattern, func(key string) {
+		keys = append(keys, key)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to get keys for pattern %s: %w", pattern, err)
 	}
 
@@ -260,7 +546,7 @@ func (r *RedisClient) InvalidatePattern(pattern string) error {
 		return nil
 	}
 
-	if err := r.client.Del(ctx, keys...).Err(); err != nil {
+	if _, err := r.deleteKeys(ctx, keys); err != nil {
 		return fmt.Errorf("failed to delete keys for pattern %s: %w", pattern, err)
 	}
 
@@ -268,19 +554,93 @@ func (r *RedisClient) InvalidatePattern(pattern string) error {
 	return nil
 }
 
+// scanKeys calls fn with every key matching a pattern. SCAN only covers the node it is
+// sent to, so a cluster is scanned master by master.
+func (r *RedisClient) scanKeys(ctx context.Context, pattern string, fn func(key string)) error {
+	cluster, ok := r.client.(*redis.ClusterClient)
+	if !ok {
+		iter := r.client.Scan(ctx, 0, pattern, 0).Iterator()
+		for iter.Next(ctx) {
+			fn(iter.Val())
+		}
+		return iter.Err()
+	}
+
+	// Masters are scanned concurrently
+	var mu sync.Mutex
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		iter := node.Scan(ctx, 0, pattern, 0).Iterator()
+		for iter.Next(ctx) {
+			mu.Lock()
+			fn(iter.Val())
+			mu.Unlock()
+		}
+		return iter.Err()
+	})
+}
+
+// deleteKeys deletes keys and returns how many existed. A single DEL cannot span hash
+// slots in a cluster, so there the keys are deleted one by one in a pipeline.
+func (r *RedisClient) deleteKeys(ctx context.Context, keys []string) (int64, error) {
+	if _, ok := r.client.(*redis.ClusterClient); !ok {
+		return r.client.Del(ctx, keys...).Result()
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(keys))
+	for i, key := range keys {
+		cmds[i] = pipe.Del(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, cmd := range cmds {
+		deleted += cmd.Val()
+	}
+	return deleted, nil
+}
+
+// keyPartEscaper percent-encodes the key separator, the escape character itself and the
+// characters Redis treats as pattern syntax, so that an escaped key part never contains a
+// colon and distinct parts always produce distinct keys
+var keyPartEscaper = strings.NewReplacer(
+	"%", "%25",
+	":", "%3A",
+	"*", "%2A",
+	"?", "%3F",
+	"[", "%5B",
+	"]", "%5D",
+	"\\", "%5C",
+)
+
+// escapeKeyPart escapes one component of a cache key
+func escapeKeyPart(part string) string {
+	return keyPartEscaper.Replace(part)
+}
+
 // GenerateConfigKey generates a cache key for configuration
 func GenerateConfigKey(orgSlug, appSlug, envSlug string) string {
-	return fmt.Sprintf("config:%s:%s:%s", orgSlug, appSlug, envSlug)
+	return fmt.Sprintf("config:env:%s:%s:%s", escapeKeyPart(orgSlug), escapeKeyPart(appSlug), escapeKeyPart(envSlug))
 }
 
-// GenerateAPIKeyConfigKey generates a cache key for API key-based configuration
-func GenerateAPIKeyConfigKey(apiKey, envSlug string) string {
-	return fmt.Sprintf("config:api:%s:%s", apiKey, envSlug)
+// GenerateAPIKeyConfigKey generates a cache key for the configuration an application
+// reads with its API key. Entries are keyed by application ID rather than by key, so
+// that keys are neither needed to invalidate them nor stored in the cache.
+func GenerateAPIKeyConfigKey(appID, envSlug string) string {
+	return fmt.Sprintf("config:api:%s:%s", escapeKeyPart(appID), escapeKeyPart(envSlug))
+}
+
+// GenerateAPIKeyFlagsKey generates a cache key for the feature flags an application reads
+// with its API key
+func GenerateAPIKeyFlagsKey(appID, envSlug string) string {
+	return fmt.Sprintf("flags:api:%s:%s", escapeKeyPart(appID), escapeKeyPart(envSlug))
 }
 
 // GenerateInvalidationPattern generates a pattern for cache invalidation
 func GenerateInvalidationPattern(orgSlug, appSlug, envSlug string) string {
-	return fmt.Sprintf("config:*:%s:%s:%s", orgSlug, appSlug, envSlug)
+	return fmt.Sprintf("config:*:%s:%s:%s", escapeKeyPart(orgSlug), escapeKeyPart(appSlug), escapeKeyPart(envSlug))
 }
 
 // SetConfigShortTTL stores a configuration with short TTL (for frequently changing data)
@@ -319,7 +679,7 @@ func (r *RedisClient) WarmCache(configs map[string]interface{}) error {
 			finalKey = "compressed:" + key
 		}
 
-		pipe.Set(ctx, finalKey, data, r.ttl)
+		pipe.Set(ctx, finalKey, data, r.configTTL(r.ttl))
 	}
 
 	_, err := pipe.Exec(ctx)
@@ -343,16 +703,19 @@ func (r *RedisClient) GetCacheInfo() (map[string]interface{}, error) {
 
 	// Get total keys count using SCAN instead of KEYS for better performance
 	var totalKeys int64
-	iter := r.client.Scan(ctx, 0, "config:*", 0).Iterator()
-	for iter.Next(ctx) {
+	err := r.scanKeys(ctx, "config:*", func(string) {
 		totalKeys++
-	}
-	if err := iter.Err(); err != nil {
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to get cache info: %w", err)
 	}
 
+	stats := r.GetStats()
 	info["total_keys"] = totalKeys
-	info["stats"] = r.GetStats()
+	info["stats"] = stats
+	info["hit_ratio"] = stats.GetHitRatio()
+	info["availability"] = r.GetAvailability()
+	info["breaker"] = r.GetBreaker()
 
 	// Get memory usage if available
 	if memInfo, err := r.client.Info(ctx, "memory").Result(); err == nil {
@@ -370,18 +733,32 @@ func (r *RedisClient) GetStats() *CacheStats {
 		Sets:      atomic.LoadInt64(&r.stats.Sets),
 		Deletes:   atomic.LoadInt64(&r.stats.Deletes),
 		Errors:    atomic.LoadInt64(&r.stats.Errors),
+		ReadErrors: atomic.LoadInt64(&r.stats.ReadErrors),
+		Bypassed:  atomic.LoadInt64(&r.stats.Bypassed),
 		TotalKeys: atomic.LoadInt64(&r.stats.TotalKeys),
+		Computed:    r.stats.Computed.load(),
+		Idempotency: r.stats.Idempotency.load(),
 	}
 }
 
 // ResetStats resets cache statistics
 func (r *RedisClient) ResetStats() {
+	r.ResetCounters()
+	atomic.StoreInt64(&r.stats.TotalKeys, 0)
+}
+
+// ResetCounters resets the hit, miss, set, delete and error counters. The key count is
+// kept, since the cached data is.
+func (r *RedisClient) ResetCounters() {
 	atomic.StoreInt64(&r.stats.Hits, 0)
 	atomic.StoreInt64(&r.stats.Misses, 0)
 	atomic.StoreInt64(&r.stats.Sets, 0)
 	atomic.StoreInt64(&r.stats.Deletes, 0)
 	atomic.StoreInt64(&r.stats.Errors, 0)
-	atomic.StoreInt64(&r.stats.TotalKeys, 0)
+	atomic.StoreInt64(&r.stats.ReadErrors, 0)
+	atomic.StoreInt64(&r.stats.Bypassed, 0)
+	r.stats.Computed.reset()
+	r.stats.Idempotency.reset()
 }
 
 // compress compresses data using gzip
@@ -423,3 +800,25 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvList gets a comma-separated environment variable, skipping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvInt gets an integer environment variable with a fallback value for unset or
+// unparsable values. Negative values are kept so that validation can report them.
+func getEnvInt(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
@@ -1,8 +1,10 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -169,6 +171,52 @@ func TestRedisClient_InvalidatePattern_Unit(t *testing.T) {
 	}
 }
 
+func TestGenerateKeys_NoCollisions_Unit(t *testing.T) {
+	// Slug tuples that produced the same key when slugs were joined with colons
+	envTuples := [][3]string{
+		{"org", "app", "prod"},
+		{"org:app", "prod", ""},
+		{"org", "app:prod", ""},
+		{"org", "", "app:prod"},
+		{"org%3Aapp", "prod", ""},
+		{"api", "key", "prod"},
+		{"org", "app", "prod*"},
+		{"org", "app", "pro?"},
+		{"org", "app", "[p]rod"},
+		{"org", "app", "prod\\"},
+		{"", "", ""},
+	}
+	apiKeyPairs := [][2]string{
+		{"key", "prod"},
+		{"key:prod", ""},
+		{"key", ":prod"},
+		{"key%3Aprod", ""},
+		{"app", "prod"},
+		{"app:prod", ""},
+	}
+
+	keys := make(map[string]string)
+	record := func(key, input string) {
+		if existing, ok := keys[key]; ok {
+			t.Errorf("key %q generated for both %s and %s", key, existing, input)
+		}
+		keys[key] = input
+	}
+
+	for _, tuple := range envTuples {
+		record(GenerateConfigKey(tuple[0], tuple[1], tuple[2]), fmt.Sprintf("config %q", tuple))
+	}
+	for _, pair := range apiKeyPairs {
+		record(GenerateAPIKeyConfigKey(pair[0], pair[1]), fmt.Sprintf("API key config %q", pair))
+		record(GenerateAPIKeyFlagsKey(pair[0], pair[1]), fmt.Sprintf("API key flags %q", pair))
+	}
+
+	// Escaped parts never contain the separator
+	for _, tuple := range envTuples {
+		assert.Equal(t, 4, strings.Count(GenerateConfigKey(tuple[0], tuple[1], tuple[2]), ":"))
+	}
+}
+
 func TestRedisClient_SetConfigWithTTL_Unit(t *testing.T) {
 	cache, cleanup := setupMockRedis(t)
 	defer cleanup()
@@ -322,3 +370,234 @@ func TestRedisClient_StatsTracking_Unit(t *testing.T) {
 	assert.GreaterOrEqual(t, finalStats.Hits, initialHits)
 	assert.Greater(t, finalStats.Misses, initialMisses)
 }
+
+func TestRedisClient_ResetCounters_Unit(t *testing.T) {
+	cache, cleanup := setupMockRedis(t)
+	defer cleanup()
+
+	key := GenerateConfigKey("org", "app", "prod")
+	require.NoError(t, cache.SetConfig(key, map[string]interface{}{"test": "value"}))
+	_, err := cache.GetConfig(key)
+	require.NoError(t, err)
+	_, err = cache.GetConfig(GenerateConfigKey("org", "app", "dev"))
+	require.NoError(t, err)
+
+	info, err := cache.GetCacheInfo()
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, info["hit_ratio"])
+
+	cache.ResetCounters()
+
+	stats := cache.GetStats()
+	assert.Zero(t, stats.Hits)
+	assert.Zero(t, stats.Misses)
+	assert.Zero(t, stats.Sets)
+	assert.Equal(t, int64(1), stats.TotalKeys)
+
+	// Cached data is kept
+	data, err := cache.GetConfig(key)
+	require.NoError(t, err)
+	assert.NotNil(t, data)
+
+	info, err = cache.GetCacheInfo()
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, info["hit_ratio"])
+}
+
+func TestNewConfig_ConnectionOptions_Unit(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		config := NewConfig()
+		assert.Empty(t, config.Username)
+		assert.False(t, config.TLS)
+		assert.Equal(t, 0, config.PoolSize)
+		assert.Equal(t, 5*time.Second, config.DialTimeout)
+		assert.Equal(t, 3*time.Second, config.ReadTimeout)
+		assert.Equal(t, 3*time.Second, config.WriteTimeout)
+		assert.NoError(t, config.Validate())
+	})
+
+	t.Run("from environment", func(t *testing.T) {
+		t.Setenv("REDIS_USERNAME", "config-service")
+		t.Setenv("REDIS_PASSWORD", "secret")
+		t.Setenv("REDIS_TLS", "true")
+		t.Setenv("REDIS_POOL_SIZE", "50")
+		t.Setenv("REDIS_DIAL_TIMEOUT", "10")
+		t.Setenv("REDIS_READ_TIMEOUT", "1")
+		t.Setenv("REDIS_WRITE_TIMEOUT", "2")
+
+		config := NewConfig()
+		require.NoError(t, config.Validate())
+
+		options := config.options()
+		assert.Equal(t, "config-service", options.Username)
+		assert.Equal(t, "secret", options.Password)
+		assert.Equal(t, 50, options.PoolSize)
+		assert.Equal(t, 10*time.Second, options.DialTimeout)
+		assert.Equal(t, time.Second, options.ReadTimeout)
+		assert.Equal(t, 2*time.Second, options.WriteTimeout)
+		require.NotNil(t, options.TLSConfig)
+		assert.False(t, options.TLSConfig.InsecureSkipVerify)
+	})
+
+	t.Run("invalid values fall back to defaults", func(t *testing.T) {
+		t.Setenv("REDIS_TLS", "maybe")
+		t.Setenv("REDIS_POOL_SIZE", "lots")
+
+		config := NewConfig()
+		assert.False(t, config.TLS)
+		assert.Nil(t, config.options().TLSConfig)
+		assert.Equal(t, 0, config.PoolSize)
+	})
+}
+
+func TestConfig_Validate_Unit(t *testing.T) {
+	valid := func() *Config {
+		return &Config{Host: "localhost", Port: "6379", DialTimeout: 5 * time.Second}
+	}
+
+	tests := []struct {
+		name          string
+		modify        func(*Config)
+		expectedError string
+	}{
+		{"missing host", func(c *Config) { c.Host = "" }, "host is required"},
+		{"invalid port", func(c *Config) { c.Port = "redis" }, "port must be between 1 and 65535"},
+		{"port out of range", func(c *Config) { c.Port = "70000" }, "port must be between 1 and 65535"},
+		{"negative database", func(c *Config) { c.DB = -1 }, "database must not be negative"},
+		{"username without password", func(c *Config) { c.Username = "config-service" }, "username requires a password"},
+		{"negative pool size", func(c *Config) { c.PoolSize = -1 }, "pool size must not be negative"},
+		{"negative timeout", func(c *Config) { c.DialTimeout = -time.Second }, "timeouts must not be negative"},
+		{"unknown mode", func(c *Config) { c.Mode = "replicated" }, `unknown mode "replicated"`},
+		{"sentinel without master name", func(c *Config) {
+			c.Mode, c.SentinelAddrs = ModeSentinel, []string{"sentinel-1:26379"}
+		}, "sentinel mode requires a master name"},
+		{"sentinel without addresses", func(c *Config) {
+			c.Mode, c.MasterName = ModeSentinel, "mymaster"
+		}, "sentinel mode requires at least one address"},
+		{"cluster address without port", func(c *Config) {
+			c.Mode, c.ClusterAddrs = ModeCluster, []string{"redis-1"}
+		}, `cluster address "redis-1" is not host:port`},
+		{"cluster with a database", func(c *Config) {
+			c.Mode, c.ClusterAddrs, c.DB = ModeCluster, []string{"redis-1:6379"}, 1
+		}, "cluster mode does not support selecting a database"},
+	}
+
+	require.NoError(t, valid().Validate())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid()
+			tt.modify(config)
+
+			err := config.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+
+	_, err := NewRedisClient(&Config{Host: "localhost", Port: "6379", PoolSize: -1})
+	assert.EqualError(t, err, "invalid Redis configuration: pool size must not be negative")
+}
+
+func TestNewConfig_Modes_Unit(t *testing.T) {
+	t.Run("sentinel", func(t *testing.T) {
+		t.Setenv("REDIS_MODE", "Sentinel")
+		t.Setenv("REDIS_MASTER_NAME", "mymaster")
+		t.Setenv("REDIS_SENTINEL_ADDRS", "sentinel-1:26379, sentinel-2:26379,")
+
+		config := NewConfig()
+		require.NoError(t, config.Validate())
+		assert.Equal(t, ModeSentinel, config.Mode)
+		assert.Equal(t, []string{"sentinel-1:26379", "sentinel-2:26379"}, config.SentinelAddrs)
+		assert.Equal(t, "master mymaster via sentinels sentinel-1:26379,sentinel-2:26379", config.Address())
+
+		// A failover client follows the master
+		client := config.newClient()
+		defer client.Close()
+		assert.IsType(t, &redis.Client{}, client)
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		t.Setenv("REDIS_MODE", "cluster")
+		t.Setenv("REDIS_CLUSTER_ADDRS", "redis-1:6379,redis-2:6379")
+
+		config := NewConfig()
+		require.NoError(t, config.Validate())
+		assert.Equal(t, "cluster redis-1:6379,redis-2:6379", config.Address())
+
+		client := config.newClient()
+		defer client.Close()
+		assert.IsType(t, &redis.ClusterClient{}, client)
+	})
+}
+
+func TestRedisClient_ClusterMode_Unit(t *testing.T) {
+	// miniredis answers CLUSTER SLOTS as a single node owning every slot
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client, err := NewRedisClient(&Config{Mode: ModeCluster, ClusterAddrs: []string{mr.Addr()}, TTL: time.Minute})
+	require.NoError(t, err)
+	defer client.Close()
+
+	for _, env := range []string{"prod", "staging"} {
+		require.NoError(t, client.SetConfig(GenerateConfigKey("acme", "web", env), map[string]string{"env": env}))
+	}
+
+	info, err := client.GetCacheInfo()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), info["total_keys"])
+
+	require.NoError(t, client.DeleteConfig(GenerateConfigKey("acme", "web", "prod")))
+	assert.False(t, mr.Exists(GenerateConfigKey("acme", "web", "prod")))
+
+	require.NoError(t, client.InvalidatePattern("config:*"))
+	assert.Empty(t, mr.Keys())
+}
+
+func TestRedisClient_ZeroTTL_Unit(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	host, port, ok := strings.Cut(mr.Addr(), ":")
+	require.True(t, ok)
+
+	t.Run("non-positive TTLs of the configuration use the defaults", func(t *testing.T) {
+		client, err := NewRedisClient(&Config{Host: host, Port: port, TTL: 0, ShortTTL: -time.Second, LongTTL: 2 * time.Hour})
+		require.NoError(t, err)
+		defer client.Close()
+
+		assert.Equal(t, DefaultTTL, client.ttl)
+		assert.Equal(t, DefaultShortTTL, client.shortTTL)
+		assert.Equal(t, 2*time.Hour, client.longTTL)
+
+		require.NoError(t, client.SetConfig("config:zero", map[string]string{"env": "prod"}))
+		assert.Equal(t, DefaultTTL, mr.TTL("config:zero"))
+	})
+
+	t.Run("precedence of the TTLs", func(t *testing.T) {
+		cache, cleanup := setupMockRedis(t)
+		defer cleanup()
+
+		// An environment's override wins over the client's TTL
+		assert.Equal(t, 30*time.Second, cache.configTTL(30*time.Second))
+		assert.Equal(t, 5*time.Minute, cache.configTTL(0))
+		assert.Equal(t, 5*time.Minute, cache.configTTL(-time.Second))
+
+		// A client built without a TTL falls back to the hardcoded default
+		cache.ttl = 0
+		assert.Equal(t, DefaultTTL, cache.configTTL(0))
+	})
+
+	t.Run("keys never lack an expiry", func(t *testing.T) {
+		cache, cleanup := setupMockRedis(t)
+		defer cleanup()
+		cache.ttl = 0
+
+		require.NoError(t, cache.SetConfigWithTTL("config:zero", map[string]string{"env": "prod"}, 0))
+		value, err := cache.client.TTL(context.Background(), "config:zero").Result()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultTTL, value)
+	})
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Keys of the usage counters. Requests are counted in the pending hashes; a flush moves
+// them to the draining hashes, which are kept until the counts have been stored.
+const (
+	usageCountsKey           = "usage:counts"
+	usageLastSeenKey         = "usage:last_seen"
+	usageDrainingCountsKey   = "usage:draining:counts"
+	usageDrainingLastSeenKey = "usage:draining:last_seen"
+)
+
+// usageDayLayout is the layout of the day part of usage fields
+const usageDayLayout = "2006-01-02"
+
+// UsageCount is the number of requests an application made for an environment on one day
+type UsageCount struct {
+	AppID       string
+	Environment string
+	Day         time.Time
+	Requests    int64
+	LastSeenAt  time.Time
+}
+
+// usageField identifies the counter of an application's environment on a day
+func usageField(appID, envSlug string, day time.Time) string {
+	return strings.Join([]string{appID, envSlug, day.UTC().Format(usageDayLayout)}, "|")
+}
+
+// RecordUsage counts a request of an application for an environment
+func (r *RedisClient) RecordUsage(appID, envSlug string, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	field := usageField(appID, envSlug, at)
+	pipe := r.client.Pipeline()
+	pipe.HIncrBy(ctx, usageCountsKey, field, 1)
+	pipe.HSet(ctx, usageLastSeenKey, field, at.Unix())
+	if _, err := pipe.Exec(ctx); err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+	return nil
+}
+
+// DrainUsage moves the pending usage counters aside and returns them. The counters stay
+// in Redis until ClearDrainedUsage is called, so that a flush that fails to store them
+// returns the same counters again on the next call instead of losing them.
+func (r *RedisClient) DrainUsage() ([]UsageCount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	draining, err := r.client.Exists(ctx, usageDrainingCountsKey).Result()
+	if err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return nil, fmt.Errorf("failed to check drained usage: %w", err)
+	}
+
+	// Counters left by a failed flush are returned before new ones are moved aside
+	if draining == 0 {
+		if err := r.renameIfExists(ctx, usageCountsKey, usageDrainingCountsKey); err != nil {
+			return nil, err
+		}
+		if err := r.renameIfExists(ctx, usageLastSeenKey, usageDrainingLastSeenKey); err != nil {
+			return nil, err
+		}
+	}
+
+	counts, err := r.client.HGetAll(ctx, usageDrainingCountsKey).Result()
+	if err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return nil, fmt.Errorf("failed to read usage counts: %w", err)
+	}
+	lastSeen, err := r.client.HGetAll(ctx, usageDrainingLastSeenKey).Result()
+	if err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return nil, fmt.Errorf("failed to read usage timestamps: %w", err)
+	}
+
+	usage := make([]UsageCount, 0, len(counts))
+	for field, value := range counts {
+		parts := strings.Split(field, "|")
+		if len(parts) != 3 {
+			continue
+		}
+		day, err := time.Parse(usageDayLayout, parts[2])
+		if err != nil {
+			continue
+		}
+		requests, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		count := UsageCount{AppID: parts[0], Environment: parts[1], Day: day, Requests: requests, LastSeenAt: day}
+		if seconds, err := strconv.ParseInt(lastSeen[field], 10, 64); err == nil {
+			count.LastSeenAt = time.Unix(seconds, 0).UTC()
+		}
+		usage = append(usage, count)
+	}
+
+	return usage, nil
+}
+
+// ClearDrainedUsage deletes the counters returned by DrainUsage once they are stored
+func (r *RedisClient) ClearDrainedUsage() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := r.client.Del(ctx, usageDrainingCountsKey, usageDrainingLastSeenKey).Err(); err != nil {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return fmt.Errorf("failed to clear drained usage: %w", err)
+	}
+	return nil
+}
+
+// renameIfExists renames a key, doing nothing if the key does not exist
+func (r *RedisClient) renameIfExists(ctx context.Context, key, newKey string) error {
+	err := r.client.Rename(ctx, key, newKey).Err()
+	if err != nil && !isNoSuchKey(err) {
+		atomic.AddInt64(&r.stats.Errors, 1)
+		return fmt.Errorf("failed to move usage counters: %w", err)
+	}
+	return nil
+}
+
+// isNoSuchKey reports whether a command failed because its key does not exist
+func isNoSuchKey(err error) bool {
+	return err == redis.Nil || strings.Contains(err.Error(), "no such key")
+}
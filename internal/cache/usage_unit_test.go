@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage_Unit(t *testing.T) {
+	cache, cleanup := setupMockRedis(t)
+	defer cleanup()
+
+	day := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, cache.RecordUsage("app-1", "prod", day))
+	require.NoError(t, cache.RecordUsage("app-1", "prod", day.Add(time.Hour)))
+	require.NoError(t, cache.RecordUsage("app-1", "dev", day))
+	require.NoError(t, cache.RecordUsage("app-1", "prod", day.Add(24*time.Hour)))
+
+	usage, err := cache.DrainUsage()
+	require.NoError(t, err)
+	require.Len(t, usage, 3)
+	sort.Slice(usage, func(i, j int) bool {
+		if !usage[i].Day.Equal(usage[j].Day) {
+			return usage[i].Day.Before(usage[j].Day)
+		}
+		return usage[i].Environment < usage[j].Environment
+	})
+
+	assert.Equal(t, UsageCount{AppID: "app-1", Environment: "dev", Day: day.Truncate(24 * time.Hour), Requests: 1, LastSeenAt: day}, usage[0])
+	assert.Equal(t, "prod", usage[1].Environment)
+	assert.Equal(t, int64(2), usage[1].Requests)
+	assert.Equal(t, day.Add(time.Hour), usage[1].LastSeenAt)
+	assert.Equal(t, int64(1), usage[2].Requests)
+
+	t.Run("drained counters are returned until cleared", func(t *testing.T) {
+		// Requests counted during a flush are kept for the next one
+		require.NoError(t, cache.RecordUsage("app-2", "prod", day))
+
+		again, err := cache.DrainUsage()
+		require.NoError(t, err)
+		assert.Len(t, again, 3)
+
+		require.NoError(t, cache.ClearDrainedUsage())
+		next, err := cache.DrainUsage()
+		require.NoError(t, err)
+		require.Len(t, next, 1)
+		assert.Equal(t, "app-2", next[0].AppID)
+
+		require.NoError(t, cache.ClearDrainedUsage())
+		empty, err := cache.DrainUsage()
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+	})
+}
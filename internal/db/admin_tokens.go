@@ -0,0 +1,114 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// AdminTokenRepository handles database operations for admin tokens
+type AdminTokenRepository struct {
+	db *DB
+}
+
+// NewAdminTokenRepository creates a new admin token repository
+func NewAdminTokenRepository(db *DB) *AdminTokenRepository {
+	return &AdminTokenRepository{db: db}
+}
+
+// GetByHash retrieves an admin token by the hash of its secret value
+func (r *AdminTokenRepository) GetByHash(tokenHash string) (*models.AdminToken, error) {
+	query := `
+		SELECT id, name, role, created_at
+		FROM admin_tokens
+		WHERE token_hash = $1
+	`
+
+	var token models.AdminToken
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&token.ID, &token.Name, &token.Role, &token.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("admin token not found")
+		}
+		return nil, fmt.Errorf("failed to get admin token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// List retrieves all admin tokens
+func (r *AdminTokenRepository) List() ([]models.AdminToken, error) {
+	query := `
+		SELECT id, name, role, created_at
+		FROM admin_tokens
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admin tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []models.AdminToken{}
+	for rows.Next() {
+		var token models.AdminToken
+		if err := rows.Scan(&token.ID, &token.Name, &token.Role, &token.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan admin token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating admin tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Create creates a new admin token with the given secret hash
+func (r *AdminTokenRepository) Create(token *models.AdminToken, tokenHash string) error {
+	query := `
+		INSERT INTO admin_tokens (id, name, token_hash, role)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+
+	err := r.db.QueryRow(query, token.ID, token.Name, tokenHash, token.Role).Scan(&token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create admin token: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes an admin token
+func (r *AdminTokenRepository) Delete(id uuid.UUID) error {
+	query := `DELETE FROM admin_tokens WHERE id = $1`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete admin token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFoundError("admin token not found: %s", id)
+	}
+
+	return nil
+}
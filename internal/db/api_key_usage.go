@@ -0,0 +1,83 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyUsageRepository handles database operations for API key usage counts
+type APIKeyUsageRepository struct {
+	db *DB
+}
+
+// NewAPIKeyUsageRepository creates a new API key usage repository
+func NewAPIKeyUsageRepository(db *DB) *APIKeyUsageRepository {
+	return &APIKeyUsageRepository{db: db}
+}
+
+// Add adds usage counts to the stored counts of the same application, environment and
+// day, in a single transaction. Counts of applications that no longer exist are dropped.
+func (r *APIKeyUsageRepository) Add(usage []models.APIKeyUsage) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO api_key_usage (app_id, environment, day, request_count, last_seen_at)
+		SELECT $1, $2, $3, $4, $5
+		WHERE EXISTS (SELECT 1 FROM applications WHERE id = $1)
+		ON CONFLICT (app_id, environment, day) DO UPDATE
+		SET request_count = api_key_usage.request_count + EXCLUDED.request_count,
+		    last_seen_at = GREATEST(api_key_usage.last_seen_at, EXCLUDED.last_seen_at)
+	`
+
+	for _, count := range usage {
+		if _, err := tx.Exec(query, count.AppID, count.Environment, count.Day, count.Requests, count.LastSeenAt); err != nil {
+			return fmt.Errorf("failed to add API key usage: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListByApplication retrieves the usage counts of an application from a day on, ordered
+// by day and environment
+func (r *APIKeyUsageRepository) ListByApplication(appID uuid.UUID, since time.Time) ([]models.APIKeyUsage, error) {
+	query := `
+		SELECT app_id, environment, day, request_count, last_seen_at
+		FROM api_key_usage
+		WHERE app_id = $1 AND day >= $2
+		ORDER BY day, environment
+	`
+
+	rows, err := r.db.Query(query, appID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API key usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []models.APIKeyUsage{}
+	for rows.Next() {
+		var count models.APIKeyUsage
+		if err := rows.Scan(&count.AppID, &count.Environment, &count.Day, &count.Requests, &count.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan API key usage: %w", err)
+		}
+		usage = append(usage, count)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API key usage: %w", err)
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ApplicationDefaultsRepository handles database operations for application default configurations
+type ApplicationDefaultsRepository struct {
+	db *DB
+}
+
+// NewApplicationDefaultsRepository creates a new application defaults repository
+func NewApplicationDefaultsRepository(db *DB) *ApplicationDefaultsRepository {
+	return &ApplicationDefaultsRepository{db: db}
+}
+
+// GetByApplication retrieves the default configuration of an application
+func (r *ApplicationDefaultsRepository) GetByApplication(appID uuid.UUID) (*models.ApplicationDefaults, error) {
+	query := `
+		SELECT app_id, version, config_json, created_at, updated_at, updated_by
+		FROM application_defaults
+		WHERE app_id = $1
+	`
+
+	var defaults models.ApplicationDefaults
+	err := r.db.QueryRow(query, appID).Scan(
+		&defaults.AppID, &defaults.Version, &defaults.ConfigJSON,
+		&defaults.CreatedAt, &defaults.UpdatedAt, &defaults.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("application defaults not found for application: %s", appID)
+		}
+		return nil, fmt.Errorf("failed to get application defaults: %w", err)
+	}
+
+	return &defaults, nil
+}
+
+// Upsert creates or replaces the default configuration of an application, bumping its version
+func (r *ApplicationDefaultsRepository) Upsert(defaults *models.ApplicationDefaults) error {
+	query := `
+		INSERT INTO application_defaults (app_id, config_json, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (app_id) DO UPDATE SET
+			config_json = EXCLUDED.config_json,
+			updated_by = EXCLUDED.updated_by,
+			version = application_defaults.version + 1
+		RETURNING version, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, defaults.AppID, defaults.ConfigJSON, defaults.UpdatedBy).Scan(
+		&defaults.Version,
+		&defaults.CreatedAt,
+		&defaults.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save application defaults: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the default configuration of an application. It reports whether there was one.
+func (r *ApplicationDefaultsRepository) Delete(appID uuid.UUID) (bool, error) {
+	query := "DELETE FROM application_defaults WHERE app_id = $1"
+
+	result, err := r.db.Exec(query, appID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete application defaults: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
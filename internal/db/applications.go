@@ -1,7 +1,11 @@
 package db
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
 
 	"remote-config-system/internal/models"
@@ -22,7 +26,7 @@ func NewApplicationRepository(db *DB) *ApplicationRepository {
 // GetBySlug retrieves an application by organization slug and application slug
 func (r *ApplicationRepository) GetBySlug(orgSlug, appSlug string) (*models.Application, error) {
 	query := `
-		SELECT a.id, a.org_id, a.name, a.slug, a.api_key, a.created_at, a.updated_at,
+		SELECT a.id, a.org_id, a.name, a.slug, a.api_key_prefix, COALESCE(a.default_env, ''), a.created_at, a.updated_at,
 		       o.id, o.name, o.slug, o.created_at, o.updated_at
 		FROM applications a
 		JOIN organizations o ON a.org_id = o.id
@@ -33,13 +37,13 @@ func (r *ApplicationRepository) GetBySlug(orgSlug, appSlug string) (*models.Appl
 	var org models.Organization
 
 	err := r.db.QueryRow(query, orgSlug, appSlug).Scan(
-		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKey, &app.CreatedAt, &app.UpdatedAt,
+		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.DefaultEnv, &app.CreatedAt, &app.UpdatedAt,
 		&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("application not found: %s/%s", orgSlug, appSlug)
+			return nil, notFoundError("application not found: %s/%s", orgSlug, appSlug)
 		}
 		return nil, fmt.Errorf("failed to get application: %w", err)
 	}
@@ -48,39 +52,59 @@ func (r *ApplicationRepository) GetBySlug(orgSlug, appSlug string) (*models.Appl
 	return &app, nil
 }
 
-// GetByAPIKey retrieves an application by its API key
+// GetByAPIKey retrieves an application by its API key. Only salted hashes of the keys are
+// stored, so the key is checked against the hash of each application whose key has the
+// same prefix, in constant time (see matchAPIKey).
 func (r *ApplicationRepository) GetByAPIKey(apiKey string) (*models.Application, error) {
 	query := `
-		SELECT a.id, a.org_id, a.name, a.slug, a.api_key, a.created_at, a.updated_at,
+		SELECT a.id, a.org_id, a.name, a.slug, a.api_key_prefix, COALESCE(a.default_env, ''), a.created_at, a.updated_at,
+		       a.api_key_salt, a.api_key_hash,
 		       o.id, o.name, o.slug, o.created_at, o.updated_at
 		FROM applications a
 		JOIN organizations o ON a.org_id = o.id
-		WHERE a.api_key = $1
+		WHERE a.api_key_prefix = $1
 	`
 
-	var app models.Application
-	var org models.Organization
+	rows, err := r.db.Query(query, apiKeyPrefix(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get application by API key: %w", err)
+	}
+	defer rows.Close()
 
-	err := r.db.QueryRow(query, apiKey).Scan(
-		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKey, &app.CreatedAt, &app.UpdatedAt,
-		&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
-	)
+	var candidates []apiKeyCandidate
+	for rows.Next() {
+		var app models.Application
+		var org models.Organization
+		var candidate apiKeyCandidate
 
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("application not found for API key")
+		err := rows.Scan(
+			&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.DefaultEnv, &app.CreatedAt, &app.UpdatedAt,
+			&candidate.salt, &candidate.hash,
+			&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan application: %w", err)
 		}
+
+		app.Organization = &org
+		candidate.app = &app
+		candidates = append(candidates, candidate)
+	}
+
+	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("failed to get application by API key: %w", err)
 	}
 
-	app.Organization = &org
-	return &app, nil
+	if app := matchAPIKey(apiKey, candidates); app != nil {
+		return app, nil
+	}
+	return nil, notFoundError("application not found for API key")
 }
 
 // GetByID retrieves an application by its ID
 func (r *ApplicationRepository) GetByID(id uuid.UUID) (*models.Application, error) {
 	query := `
-		SELECT a.id, a.org_id, a.name, a.slug, a.api_key, a.created_at, a.updated_at,
+		SELECT a.id, a.org_id, a.name, a.slug, a.api_key_prefix, COALESCE(a.default_env, ''), a.created_at, a.updated_at,
 		       o.id, o.name, o.slug, o.created_at, o.updated_at
 		FROM applications a
 		JOIN organizations o ON a.org_id = o.id
@@ -91,13 +115,13 @@ func (r *ApplicationRepository) GetByID(id uuid.UUID) (*models.Application, erro
 	var org models.Organization
 
 	err := r.db.QueryRow(query, id).Scan(
-		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKey, &app.CreatedAt, &app.UpdatedAt,
+		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.DefaultEnv, &app.CreatedAt, &app.UpdatedAt,
 		&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("application not found: %s", id)
+			return nil, notFoundError("application not found: %s", id)
 		}
 		return nil, fmt.Errorf("failed to get application: %w", err)
 	}
@@ -118,7 +142,7 @@ func (r *ApplicationRepository) ListByOrganization(orgID uuid.UUID, params model
 
 	// Get paginated results
 	query := `
-		SELECT a.id, a.org_id, a.name, a.slug, a.api_key, a.created_at, a.updated_at,
+		SELECT a.id, a.org_id, a.name, a.slug, a.api_key_prefix, COALESCE(a.default_env, ''), a.created_at, a.updated_at,
 		       o.id, o.name, o.slug, o.created_at, o.updated_at
 		FROM applications a
 		JOIN organizations o ON a.org_id = o.id
@@ -139,7 +163,7 @@ func (r *ApplicationRepository) ListByOrganization(orgID uuid.UUID, params model
 		var org models.Organization
 
 		err := rows.Scan(
-			&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKey, &app.CreatedAt, &app.UpdatedAt,
+			&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.DefaultEnv, &app.CreatedAt, &app.UpdatedAt,
 			&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
 		)
 		if err != nil {
@@ -157,11 +181,11 @@ func (r *ApplicationRepository) ListByOrganization(orgID uuid.UUID, params model
 	return applications, totalCount, nil
 }
 
-// Create creates a new application
+// Create creates a new application, storing a salted hash of app.APIKey
 func (r *ApplicationRepository) Create(app *models.Application) error {
 	query := `
-		INSERT INTO applications (id, org_id, name, slug, api_key)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO applications (id, org_id, name, slug, api_key_prefix, api_key_salt, api_key_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING created_at, updated_at
 	`
 
@@ -169,7 +193,13 @@ func (r *ApplicationRepository) Create(app *models.Application) error {
 		app.ID = uuid.New()
 	}
 
-	err := r.db.QueryRow(query, app.ID, app.OrgID, app.Name, app.Slug, app.APIKey).Scan(
+	salt, err := newAPIKeySalt()
+	if err != nil {
+		return fmt.Errorf("failed to create application: %w", err)
+	}
+	app.APIKeyPrefix = apiKeyPrefix(app.APIKey)
+
+	err = r.db.QueryRow(query, app.ID, app.OrgID, app.Name, app.Slug, app.APIKeyPrefix, salt, hashAPIKey(app.APIKey, salt)).Scan(
 		&app.CreatedAt,
 		&app.UpdatedAt,
 	)
@@ -181,19 +211,19 @@ func (r *ApplicationRepository) Create(app *models.Application) error {
 	return nil
 }
 
-// Update updates an existing application
+// Update updates an existing application. Its API key is changed with UpdateAPIKey.
 func (r *ApplicationRepository) Update(app *models.Application) error {
 	query := `
 		UPDATE applications
-		SET name = $2, slug = $3, api_key = $4
+		SET name = $2, slug = $3, default_env = NULLIF($4, '')
 		WHERE id = $1
 		RETURNING updated_at
 	`
 
-	err := r.db.QueryRow(query, app.ID, app.Name, app.Slug, app.APIKey).Scan(&app.UpdatedAt)
+	err := r.db.QueryRow(query, app.ID, app.Name, app.Slug, app.DefaultEnv).Scan(&app.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("application not found: %s", app.ID)
+			return notFoundError("application not found: %s", app.ID)
 		}
 		return fmt.Errorf("failed to update application: %w", err)
 	}
@@ -201,6 +231,33 @@ func (r *ApplicationRepository) Update(app *models.Application) error {
 	return nil
 }
 
+// UpdateAPIKey replaces the API key of an application with app.APIKey, storing its salted
+// hash under a new salt. The previous key stops working at once.
+func (r *ApplicationRepository) UpdateAPIKey(app *models.Application) error {
+	query := `
+		UPDATE applications
+		SET api_key_prefix = $2, api_key_salt = $3, api_key_hash = $4
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	salt, err := newAPIKeySalt()
+	if err != nil {
+		return fmt.Errorf("failed to update API key: %w", err)
+	}
+	app.APIKeyPrefix = apiKeyPrefix(app.APIKey)
+
+	err = r.db.QueryRow(query, app.ID, app.APIKeyPrefix, salt, hashAPIKey(app.APIKey, salt)).Scan(&app.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundError("application not found: %s", app.ID)
+		}
+		return fmt.Errorf("failed to update API key: %w", err)
+	}
+
+	return nil
+}
+
 // Delete deletes an application
 func (r *ApplicationRepository) Delete(id uuid.UUID) error {
 	query := "DELETE FROM applications WHERE id = $1"
@@ -216,7 +273,7 @@ func (r *ApplicationRepository) Delete(id uuid.UUID) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("application not found: %s", id)
+		return notFoundError("application not found: %s", id)
 	}
 
 	return nil
@@ -234,3 +291,66 @@ func (r *ApplicationRepository) Exists(orgID uuid.UUID, slug string) (bool, erro
 
 	return exists, nil
 }
+
+// apiKeyPrefixLength is the length of the prefix of API keys kept in the clear. Keys are
+// looked up by their prefix, which also tells them apart in listings.
+const apiKeyPrefixLength = 8
+
+// apiKeyPrefix returns the prefix of an API key kept in the clear. It is at most half of
+// the key, so that short custom keys are not given away.
+func apiKeyPrefix(apiKey string) string {
+	runes := []rune(apiKey)
+	return string(runes[:min(apiKeyPrefixLength, len(runes)/2)])
+}
+
+// hashAPIKey returns the hex-encoded SHA-256 hash of an API key with its salt
+func hashAPIKey(apiKey, salt string) string {
+	sum := sha256.Sum256([]byte(salt + apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyCandidate is an application whose API key has the prefix of a presented key
+type apiKeyCandidate struct {
+	app  *models.Application
+	salt string
+	hash string
+}
+
+// compareAPIKeyHashes compares API key hashes without returning early on the first
+// difference. It is a variable so that tests can check that every comparison goes through it.
+var compareAPIKeyHashes = subtle.ConstantTimeCompare
+
+// unknownAPIKeySalt and unknownAPIKeyHash stand in for a stored key when no application has
+// the prefix of a presented key
+const unknownAPIKeySalt = "00000000000000000000000000000000"
+
+var unknownAPIKeyHash = hashAPIKey("", unknownAPIKeySalt)
+
+// matchAPIKey returns the candidate whose hash matches an API key, or nil. Only the prefix
+// of a key, which is at most half of it, is matched by the database; the rest is checked by
+// comparing hashes in constant time. Every candidate is compared, even after a match, and
+// a key without candidates is compared with a stand-in hash, so that response times tell
+// neither which candidate matched nor, beyond the prefix, whether any application came close.
+func matchAPIKey(apiKey string, candidates []apiKeyCandidate) *models.Application {
+	if len(candidates) == 0 {
+		compareAPIKeyHashes([]byte(hashAPIKey(apiKey, unknownAPIKeySalt)), []byte(unknownAPIKeyHash))
+		return nil
+	}
+
+	var match *models.Application
+	for _, candidate := range candidates {
+		if compareAPIKeyHashes([]byte(hashAPIKey(apiKey, candidate.salt)), []byte(candidate.hash)) == 1 && match == nil {
+			match = candidate.app
+		}
+	}
+	return match
+}
+
+// newAPIKeySalt returns a random hex-encoded salt for an API key hash
+func newAPIKeySalt() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate API key salt: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
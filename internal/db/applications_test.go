@@ -0,0 +1,72 @@
+package db
+
+import (
+	"crypto/subtle"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyPrefix(t *testing.T) {
+	assert.Equal(t, "rck_0123", apiKeyPrefix("rck_0123456789abcdef"))
+	assert.Equal(t, "secr", apiKeyPrefix("secret12"), "at most half of a short key")
+	assert.Equal(t, "", apiKeyPrefix("k"))
+	assert.Equal(t, "ключ", apiKeyPrefix("ключключ"), "prefixes are cut at characters, not bytes")
+}
+
+func TestHashAPIKey(t *testing.T) {
+	hash := hashAPIKey("my-api-key", "salt")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, hashAPIKey("my-api-key", "salt"))
+	assert.NotEqual(t, hash, hashAPIKey("my-api-key", "other-salt"))
+	assert.NotEqual(t, hash, hashAPIKey("my-api-kez", "salt"))
+
+	salt, err := newAPIKeySalt()
+	assert.NoError(t, err)
+	assert.Len(t, salt, 32)
+}
+
+func TestMatchAPIKey(t *testing.T) {
+	// Count the comparisons, which must all go through the constant-time function
+	comparisons := 0
+	original := compareAPIKeyHashes
+	compareAPIKeyHashes = func(x, y []byte) int {
+		comparisons++
+		return subtle.ConstantTimeCompare(x, y)
+	}
+	t.Cleanup(func() { compareAPIKeyHashes = original })
+
+	candidate := func(apiKey, salt string) apiKeyCandidate {
+		return apiKeyCandidate{app: &models.Application{Slug: apiKey}, salt: salt, hash: hashAPIKey(apiKey, salt)}
+	}
+	candidates := []apiKeyCandidate{
+		candidate("key-aaaa-1", "salt-1"),
+		candidate("key-aaaa-2", "salt-2"),
+		candidate("key-aaaa-3", "salt-3"),
+	}
+
+	t.Run("every candidate is compared", func(t *testing.T) {
+		comparisons = 0
+		app := matchAPIKey("key-aaaa-1", candidates)
+		require.NotNil(t, app)
+		assert.Equal(t, "key-aaaa-1", app.Slug)
+		assert.Equal(t, len(candidates), comparisons)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		comparisons = 0
+		assert.Nil(t, matchAPIKey("key-aaaa-4", candidates))
+		assert.Equal(t, len(candidates), comparisons)
+	})
+
+	t.Run("unknown prefix is compared too", func(t *testing.T) {
+		comparisons = 0
+		assert.Nil(t, matchAPIKey("other-key", nil))
+		assert.Equal(t, 1, comparisons)
+
+		assert.Nil(t, matchAPIKey("", nil), "the stand-in hash must not match")
+	})
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"remote-config-system/internal/models"
 
@@ -31,9 +32,9 @@ func (r *ConfigChangeRepository) ListByEnvironment(envID uuid.UUID, params model
 
 	// Get paginated results
 	query := `
-		SELECT cc.id, cc.env_id, cc.version_from, cc.version_to, cc.action, cc.created_at, cc.created_by,
+		SELECT cc.id, cc.env_id, cc.version_from, cc.version_to, cc.action, cc.created_at, cc.created_by, cc.approved_by, cc.message, cc.tag, cc.scope, cc.source_env, cc.source_version,
 		       e.id, e.app_id, e.name, e.slug, e.created_at, e.updated_at,
-		       a.id, a.org_id, a.name, a.slug, a.api_key, a.created_at, a.updated_at,
+		       a.id, a.org_id, a.name, a.slug, a.api_key_prefix, a.created_at, a.updated_at,
 		       o.id, o.name, o.slug, o.created_at, o.updated_at
 		FROM config_changes cc
 		JOIN environments e ON cc.env_id = e.id
@@ -58,9 +59,9 @@ func (r *ConfigChangeRepository) ListByEnvironment(envID uuid.UUID, params model
 		var org models.Organization
 
 		err := rows.Scan(
-			&cc.ID, &cc.EnvID, &cc.VersionFrom, &cc.VersionTo, &cc.Action, &cc.CreatedAt, &cc.CreatedBy,
+			&cc.ID, &cc.EnvID, &cc.VersionFrom, &cc.VersionTo, &cc.Action, &cc.CreatedAt, &cc.CreatedBy, &cc.ApprovedBy, &cc.Message, &cc.Tag, &cc.Scope, &cc.SourceEnv, &cc.SourceVersion,
 			&env.ID, &env.AppID, &env.Name, &env.Slug, &env.CreatedAt, &env.UpdatedAt,
-			&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKey, &app.CreatedAt, &app.UpdatedAt,
+			&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.CreatedAt, &app.UpdatedAt,
 			&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
 		)
 		if err != nil {
@@ -83,9 +84,9 @@ func (r *ConfigChangeRepository) ListByEnvironment(envID uuid.UUID, params model
 // ListRecent retrieves recent configuration changes across all environments
 func (r *ConfigChangeRepository) ListRecent(limit int) ([]models.ConfigChange, error) {
 	query := `
-		SELECT cc.id, cc.env_id, cc.version_from, cc.version_to, cc.action, cc.created_at, cc.created_by,
+		SELECT cc.id, cc.env_id, cc.version_from, cc.version_to, cc.action, cc.created_at, cc.created_by, cc.approved_by, cc.message, cc.tag, cc.scope, cc.source_env, cc.source_version,
 		       e.id, e.app_id, e.name, e.slug, e.created_at, e.updated_at,
-		       a.id, a.org_id, a.name, a.slug, a.api_key, a.created_at, a.updated_at,
+		       a.id, a.org_id, a.name, a.slug, a.api_key_prefix, a.created_at, a.updated_at,
 		       o.id, o.name, o.slug, o.created_at, o.updated_at
 		FROM config_changes cc
 		JOIN environments e ON cc.env_id = e.id
@@ -109,9 +110,9 @@ func (r *ConfigChangeRepository) ListRecent(limit int) ([]models.ConfigChange, e
 		var org models.Organization
 
 		err := rows.Scan(
-			&cc.ID, &cc.EnvID, &cc.VersionFrom, &cc.VersionTo, &cc.Action, &cc.CreatedAt, &cc.CreatedBy,
+			&cc.ID, &cc.EnvID, &cc.VersionFrom, &cc.VersionTo, &cc.Action, &cc.CreatedAt, &cc.CreatedBy, &cc.ApprovedBy, &cc.Message, &cc.Tag, &cc.Scope, &cc.SourceEnv, &cc.SourceVersion,
 			&env.ID, &env.AppID, &env.Name, &env.Slug, &env.CreatedAt, &env.UpdatedAt,
-			&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKey, &app.CreatedAt, &app.UpdatedAt,
+			&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.CreatedAt, &app.UpdatedAt,
 			&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
 		)
 		if err != nil {
@@ -131,19 +132,145 @@ func (r *ConfigChangeRepository) ListRecent(limit int) ([]models.ConfigChange, e
 	return changes, nil
 }
 
+// EachByEnvironment calls fn with each configuration change of an environment, oldest
+// first, as the changes are read. It stops at the first error fn returns.
+func (r *ConfigChangeRepository) EachByEnvironment(envID uuid.UUID, fn func(*models.ConfigChange) error) error {
+	return r.each("cc.env_id = $1", envID, fn)
+}
+
+// EachByOrganization calls fn with each configuration change of every environment of an
+// organization, oldest first, as the changes are read. It stops at the first error fn returns.
+func (r *ConfigChangeRepository) EachByOrganization(orgID uuid.UUID, fn func(*models.ConfigChange) error) error {
+	return r.each("o.id = $1", orgID, fn)
+}
+
+// each streams the configuration changes matching a condition on one argument, so long
+// histories are never held in memory
+func (r *ConfigChangeRepository) each(condition string, arg interface{}, fn func(*models.ConfigChange) error) error {
+	query := `
+		SELECT cc.id, cc.env_id, cc.version_from, cc.version_to, cc.action, cc.created_at, cc.created_by, cc.approved_by, cc.message, cc.tag, cc.scope, cc.source_env, cc.source_version,
+		       e.id, e.app_id, e.name, e.slug, e.created_at, e.updated_at,
+		       a.id, a.org_id, a.name, a.slug, a.api_key_prefix, a.created_at, a.updated_at,
+		       o.id, o.name, o.slug, o.created_at, o.updated_at
+		FROM config_changes cc
+		JOIN environments e ON cc.env_id = e.id
+		JOIN applications a ON e.app_id = a.id
+		JOIN organizations o ON a.org_id = o.id
+		WHERE ` + condition + `
+		ORDER BY cc.created_at, cc.id
+	`
+
+	rows, err := r.db.Query(query, arg)
+	if err != nil {
+		return fmt.Errorf("failed to list config changes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cc models.ConfigChange
+		var env models.Environment
+		var app models.Application
+		var org models.Organization
+
+		err := rows.Scan(
+			&cc.ID, &cc.EnvID, &cc.VersionFrom, &cc.VersionTo, &cc.Action, &cc.CreatedAt, &cc.CreatedBy, &cc.ApprovedBy, &cc.Message, &cc.Tag, &cc.Scope, &cc.SourceEnv, &cc.SourceVersion,
+			&env.ID, &env.AppID, &env.Name, &env.Slug, &env.CreatedAt, &env.UpdatedAt,
+			&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.CreatedAt, &app.UpdatedAt,
+			&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan config change: %w", err)
+		}
+
+		app.Organization = &org
+		env.Application = &app
+		cc.Environment = &env
+		if err := fn(&cc); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating config changes: %w", err)
+	}
+
+	return nil
+}
+
+// activations selects the changes of an environment ($1) that make a version the active one
+const activations = `env_id = $1 AND scope = 'environment' AND action IN ('create', 'update', 'promote', 'rollback', 'approve')`
+
+// GetActiveVersionAt returns the version of an environment that the change log shows as
+// active at a point in time: the version the latest activation at or before it activated,
+// or else the version the first activation after it replaced, nil when there was none.
+// found is false when the change log has no activation of the environment at all.
+func (r *ConfigChangeRepository) GetActiveVersionAt(envID uuid.UUID, at time.Time) (version *int, found bool, err error) {
+
+	err = r.db.QueryRow(`
+		SELECT version_to FROM config_changes
+		WHERE `+activations+` AND version_to IS NOT NULL AND created_at <= $2
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, envID, at).Scan(&version)
+	if err == nil {
+		return version, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to get active version: %w", err)
+	}
+
+	err = r.db.QueryRow(`
+		SELECT version_from FROM config_changes
+		WHERE `+activations+` AND created_at > $2
+		ORDER BY created_at, id
+		LIMIT 1
+	`, envID, at).Scan(&version)
+	if err == nil {
+		return version, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to get active version: %w", err)
+	}
+	return nil, false, nil
+}
+
+// WasActivated reports whether a version of an environment has ever been the active one:
+// it is active now, or the change log shows an activation that activated or replaced it.
+// Proposals that were never approved and rollout versions that were never promoted have
+// not been active.
+func (r *ConfigChangeRepository) WasActivated(envID uuid.UUID, version int) (bool, error) {
+	var activated bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM config_changes
+			WHERE `+activations+` AND (version_to = $2 OR version_from = $2)
+		) OR EXISTS (
+			SELECT 1 FROM config_versions
+			WHERE env_id = $1 AND version = $2 AND is_active = TRUE
+		)
+	`, envID, version).Scan(&activated)
+	if err != nil {
+		return false, fmt.Errorf("failed to check configuration version activations: %w", err)
+	}
+	return activated, nil
+}
+
 // Create creates a new configuration change log entry
 func (r *ConfigChangeRepository) Create(cc *models.ConfigChange) error {
 	query := `
-		INSERT INTO config_changes (id, env_id, version_from, version_to, action, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO config_changes (id, env_id, version_from, version_to, action, created_by, approved_by, message, tag, scope, source_env, source_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING created_at
 	`
 
 	if cc.ID == uuid.Nil {
 		cc.ID = uuid.New()
 	}
+	if cc.Scope == "" {
+		cc.Scope = models.ChangeScopeEnvironment
+	}
 
-	err := r.db.QueryRow(query, cc.ID, cc.EnvID, cc.VersionFrom, cc.VersionTo, cc.Action, cc.CreatedBy).Scan(&cc.CreatedAt)
+	err := r.db.QueryRow(query, cc.ID, cc.EnvID, cc.VersionFrom, cc.VersionTo, cc.Action, cc.CreatedBy, cc.ApprovedBy, cc.Message, cc.Tag, cc.Scope, cc.SourceEnv, cc.SourceVersion).Scan(&cc.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create config change: %w", err)
 	}
@@ -154,9 +281,9 @@ func (r *ConfigChangeRepository) Create(cc *models.ConfigChange) error {
 // GetByID retrieves a configuration change by its ID
 func (r *ConfigChangeRepository) GetByID(id uuid.UUID) (*models.ConfigChange, error) {
 	query := `
-		SELECT cc.id, cc.env_id, cc.version_from, cc.version_to, cc.action, cc.created_at, cc.created_by,
+		SELECT cc.id, cc.env_id, cc.version_from, cc.version_to, cc.action, cc.created_at, cc.created_by, cc.approved_by, cc.message, cc.tag, cc.scope, cc.source_env, cc.source_version,
 		       e.id, e.app_id, e.name, e.slug, e.created_at, e.updated_at,
-		       a.id, a.org_id, a.name, a.slug, a.api_key, a.created_at, a.updated_at,
+		       a.id, a.org_id, a.name, a.slug, a.api_key_prefix, a.created_at, a.updated_at,
 		       o.id, o.name, o.slug, o.created_at, o.updated_at
 		FROM config_changes cc
 		JOIN environments e ON cc.env_id = e.id
@@ -171,15 +298,15 @@ func (r *ConfigChangeRepository) GetByID(id uuid.UUID) (*models.ConfigChange, er
 	var org models.Organization
 
 	err := r.db.QueryRow(query, id).Scan(
-		&cc.ID, &cc.EnvID, &cc.VersionFrom, &cc.VersionTo, &cc.Action, &cc.CreatedAt, &cc.CreatedBy,
+		&cc.ID, &cc.EnvID, &cc.VersionFrom, &cc.VersionTo, &cc.Action, &cc.CreatedAt, &cc.CreatedBy, &cc.ApprovedBy, &cc.Message, &cc.Tag, &cc.Scope, &cc.SourceEnv, &cc.SourceVersion,
 		&env.ID, &env.AppID, &env.Name, &env.Slug, &env.CreatedAt, &env.UpdatedAt,
-		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKey, &app.CreatedAt, &app.UpdatedAt,
+		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.CreatedAt, &app.UpdatedAt,
 		&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("config change not found: %s", id)
+			return nil, notFoundError("config change not found: %s", id)
 		}
 		return nil, fmt.Errorf("failed to get config change: %w", err)
 	}
@@ -205,7 +332,7 @@ func (r *ConfigChangeRepository) Delete(id uuid.UUID) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("config change not found: %s", id)
+		return notFoundError("config change not found: %s", id)
 	}
 
 	return nil
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ConfigRolloutRepository handles database operations for gradual rollouts
+type ConfigRolloutRepository struct {
+	db *DB
+}
+
+// NewConfigRolloutRepository creates a new config rollout repository
+func NewConfigRolloutRepository(db *DB) *ConfigRolloutRepository {
+	return &ConfigRolloutRepository{db: db}
+}
+
+// GetByEnvironment retrieves the rollout in progress for an environment
+func (r *ConfigRolloutRepository) GetByEnvironment(envID uuid.UUID) (*models.ConfigRollout, error) {
+	query := `
+		SELECT env_id, version, stable_version, percentage, created_at, updated_at, updated_by
+		FROM config_rollouts
+		WHERE env_id = $1
+	`
+
+	var rollout models.ConfigRollout
+	err := r.db.QueryRow(query, envID).Scan(
+		&rollout.EnvID, &rollout.Version, &rollout.StableVersion, &rollout.Percentage,
+		&rollout.CreatedAt, &rollout.UpdatedAt, &rollout.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("rollout not found for environment: %s", envID)
+		}
+		return nil, fmt.Errorf("failed to get rollout: %w", err)
+	}
+
+	return &rollout, nil
+}
+
+// Upsert starts a rollout for an environment, replacing any rollout already in progress
+func (r *ConfigRolloutRepository) Upsert(rollout *models.ConfigRollout) error {
+	query := `
+		INSERT INTO config_rollouts (env_id, version, stable_version, percentage, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (env_id) DO UPDATE
+		SET version = EXCLUDED.version,
+		    stable_version = EXCLUDED.stable_version,
+		    percentage = EXCLUDED.percentage,
+		    created_at = NOW(),
+		    updated_at = NOW(),
+		    updated_by = EXCLUDED.updated_by
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, rollout.EnvID, rollout.Version, rollout.StableVersion, rollout.Percentage, rollout.UpdatedBy).Scan(
+		&rollout.CreatedAt,
+		&rollout.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save rollout: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePercentage changes the share of clients of a rollout
+func (r *ConfigRolloutRepository) UpdatePercentage(rollout *models.ConfigRollout) error {
+	query := `
+		UPDATE config_rollouts
+		SET percentage = $2, updated_by = $3, updated_at = NOW()
+		WHERE env_id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(query, rollout.EnvID, rollout.Percentage, rollout.UpdatedBy).Scan(&rollout.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundError("rollout not found for environment: %s", rollout.EnvID)
+		}
+		return fmt.Errorf("failed to update rollout: %w", err)
+	}
+
+	return nil
+}
+
+// Delete ends the rollout of an environment. It reports whether there was one.
+func (r *ConfigRolloutRepository) Delete(envID uuid.UUID) (bool, error) {
+	query := "DELETE FROM config_rollouts WHERE env_id = $1"
+
+	result, err := r.db.Exec(query, envID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete rollout: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
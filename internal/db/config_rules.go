@@ -0,0 +1,92 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ConfigRuleRepository handles database operations for configuration rulesets
+type ConfigRuleRepository struct {
+	db *DB
+}
+
+// NewConfigRuleRepository creates a new config rule repository
+func NewConfigRuleRepository(db *DB) *ConfigRuleRepository {
+	return &ConfigRuleRepository{db: db}
+}
+
+// GetByEnvironment retrieves the ruleset of an environment
+func (r *ConfigRuleRepository) GetByEnvironment(envID uuid.UUID) (*models.ConfigRuleset, error) {
+	query := `
+		SELECT env_id, rules, created_at, updated_at
+		FROM config_rules
+		WHERE env_id = $1
+	`
+
+	var ruleset models.ConfigRuleset
+	var rulesJSON []byte
+	err := r.db.QueryRow(query, envID).Scan(
+		&ruleset.EnvID, &rulesJSON, &ruleset.CreatedAt, &ruleset.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("config rules not found for environment: %s", envID)
+		}
+		return nil, fmt.Errorf("failed to get config rules: %w", err)
+	}
+
+	if err := json.Unmarshal(rulesJSON, &ruleset.Rules); err != nil {
+		return nil, fmt.Errorf("failed to decode config rules: %w", err)
+	}
+
+	return &ruleset, nil
+}
+
+// Upsert creates or replaces the ruleset of an environment
+func (r *ConfigRuleRepository) Upsert(ruleset *models.ConfigRuleset) error {
+	rulesJSON, err := json.Marshal(ruleset.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to encode config rules: %w", err)
+	}
+
+	query := `
+		INSERT INTO config_rules (env_id, rules)
+		VALUES ($1, $2)
+		ON CONFLICT (env_id) DO UPDATE SET rules = EXCLUDED.rules
+		RETURNING created_at, updated_at
+	`
+
+	err = r.db.QueryRow(query, ruleset.EnvID, rulesJSON).Scan(
+		&ruleset.CreatedAt,
+		&ruleset.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save config rules: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the ruleset of an environment. It reports whether there was one.
+func (r *ConfigRuleRepository) Delete(envID uuid.UUID) (bool, error) {
+	query := "DELETE FROM config_rules WHERE env_id = $1"
+
+	result, err := r.db.Exec(query, envID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete config rules: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ConfigSchemaRepository handles database operations for configuration schemas
+type ConfigSchemaRepository struct {
+	db *DB
+}
+
+// NewConfigSchemaRepository creates a new config schema repository
+func NewConfigSchemaRepository(db *DB) *ConfigSchemaRepository {
+	return &ConfigSchemaRepository{db: db}
+}
+
+// GetByEnvironment retrieves the configuration schema for an environment
+func (r *ConfigSchemaRepository) GetByEnvironment(envID uuid.UUID) (*models.ConfigSchema, error) {
+	query := `
+		SELECT env_id, schema_json, created_at, updated_at
+		FROM config_schemas
+		WHERE env_id = $1
+	`
+
+	var schema models.ConfigSchema
+	err := r.db.QueryRow(query, envID).Scan(
+		&schema.EnvID, &schema.SchemaJSON, &schema.CreatedAt, &schema.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("config schema not found for environment: %s", envID)
+		}
+		return nil, fmt.Errorf("failed to get config schema: %w", err)
+	}
+
+	return &schema, nil
+}
+
+// Upsert creates or replaces the configuration schema for an environment
+func (r *ConfigSchemaRepository) Upsert(schema *models.ConfigSchema) error {
+	query := `
+		INSERT INTO config_schemas (env_id, schema_json)
+		VALUES ($1, $2)
+		ON CONFLICT (env_id) DO UPDATE SET schema_json = EXCLUDED.schema_json
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, schema.EnvID, schema.SchemaJSON).Scan(
+		&schema.CreatedAt,
+		&schema.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save config schema: %w", err)
+	}
+
+	return nil
+}
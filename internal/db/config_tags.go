@@ -0,0 +1,142 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ConfigTagRepository handles database operations for configuration version tags
+type ConfigTagRepository struct {
+	db *DB
+}
+
+// NewConfigTagRepository creates a new config tag repository
+func NewConfigTagRepository(db *DB) *ConfigTagRepository {
+	return &ConfigTagRepository{db: db}
+}
+
+// GetByName retrieves a tag of an environment by its name
+func (r *ConfigTagRepository) GetByName(envID uuid.UUID, name string) (*models.ConfigTag, error) {
+	query := `
+		SELECT id, env_id, name, version, created_at, updated_at, updated_by
+		FROM config_tags
+		WHERE env_id = $1 AND name = $2
+	`
+
+	var tag models.ConfigTag
+	err := r.db.QueryRow(query, envID, name).Scan(
+		&tag.ID, &tag.EnvID, &tag.Name, &tag.Version, &tag.CreatedAt, &tag.UpdatedAt, &tag.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("tag not found: %s", name)
+		}
+		return nil, fmt.Errorf("failed to get tag: %w", err)
+	}
+
+	return &tag, nil
+}
+
+// ListByEnvironment retrieves all tags of an environment
+func (r *ConfigTagRepository) ListByEnvironment(envID uuid.UUID) ([]models.ConfigTag, error) {
+	query := `
+		SELECT id, env_id, name, version, created_at, updated_at, updated_by
+		FROM config_tags
+		WHERE env_id = $1
+		ORDER BY name
+	`
+
+	rows, err := r.db.Query(query, envID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []models.ConfigTag{}
+	for rows.Next() {
+		var tag models.ConfigTag
+		err := rows.Scan(
+			&tag.ID, &tag.EnvID, &tag.Name, &tag.Version, &tag.CreatedAt, &tag.UpdatedAt, &tag.UpdatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// Create creates a new tag
+func (r *ConfigTagRepository) Create(tag *models.ConfigTag) error {
+	query := `
+		INSERT INTO config_tags (id, env_id, name, version, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`
+
+	if tag.ID == uuid.Nil {
+		tag.ID = uuid.New()
+	}
+
+	err := r.db.QueryRow(query, tag.ID, tag.EnvID, tag.Name, tag.Version, tag.UpdatedBy).Scan(
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return nil
+}
+
+// Move points an existing tag at another version
+func (r *ConfigTagRepository) Move(tag *models.ConfigTag) error {
+	query := `
+		UPDATE config_tags
+		SET version = $2, updated_by = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(query, tag.ID, tag.Version, tag.UpdatedBy).Scan(&tag.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundError("tag not found: %s", tag.Name)
+		}
+		return fmt.Errorf("failed to move tag: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a tag
+func (r *ConfigTagRepository) Delete(id uuid.UUID) error {
+	query := "DELETE FROM config_tags WHERE id = $1"
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFoundError("tag not found: %s", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ConfigTemplateRepository handles database operations for configuration templates
+type ConfigTemplateRepository struct {
+	db *DB
+}
+
+// NewConfigTemplateRepository creates a new config template repository
+func NewConfigTemplateRepository(db *DB) *ConfigTemplateRepository {
+	return &ConfigTemplateRepository{db: db}
+}
+
+// GetByName retrieves a template of an organization by its name
+func (r *ConfigTemplateRepository) GetByName(orgID uuid.UUID, name string) (*models.ConfigTemplate, error) {
+	query := `
+		SELECT id, org_id, name, description, template, created_at, updated_at, updated_by
+		FROM config_templates
+		WHERE org_id = $1 AND name = $2
+	`
+
+	var template models.ConfigTemplate
+	err := r.db.QueryRow(query, orgID, name).Scan(
+		&template.ID, &template.OrgID, &template.Name, &template.Description, &template.Template,
+		&template.CreatedAt, &template.UpdatedAt, &template.UpdatedBy,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("template not found: %s", name)
+		}
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	return &template, nil
+}
+
+// ListByOrganization retrieves all templates of an organization
+func (r *ConfigTemplateRepository) ListByOrganization(orgID uuid.UUID) ([]models.ConfigTemplate, error) {
+	query := `
+		SELECT id, org_id, name, description, template, created_at, updated_at, updated_by
+		FROM config_templates
+		WHERE org_id = $1
+		ORDER BY name
+	`
+
+	rows, err := r.db.Query(query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []models.ConfigTemplate{}
+	for rows.Next() {
+		var template models.ConfigTemplate
+		err := rows.Scan(
+			&template.ID, &template.OrgID, &template.Name, &template.Description, &template.Template,
+			&template.CreatedAt, &template.UpdatedAt, &template.UpdatedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// Create creates a new template
+func (r *ConfigTemplateRepository) Create(template *models.ConfigTemplate) error {
+	query := `
+		INSERT INTO config_templates (id, org_id, name, description, template, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at
+	`
+
+	if template.ID == uuid.Nil {
+		template.ID = uuid.New()
+	}
+
+	err := r.db.QueryRow(query, template.ID, template.OrgID, template.Name, template.Description, template.Template, template.UpdatedBy).Scan(
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create template: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces the description and document of an existing template
+func (r *ConfigTemplateRepository) Update(template *models.ConfigTemplate) error {
+	query := `
+		UPDATE config_templates
+		SET description = $2, template = $3, updated_by = $4
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(query, template.ID, template.Description, template.Template, template.UpdatedBy).Scan(&template.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundError("template not found: %s", template.Name)
+		}
+		return fmt.Errorf("failed to update template: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a template
+func (r *ConfigTemplateRepository) Delete(id uuid.UUID) error {
+	query := "DELETE FROM config_templates WHERE id = $1"
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFoundError("template not found: %s", id)
+	}
+
+	return nil
+}
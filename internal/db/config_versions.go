@@ -2,11 +2,14 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"remote-config-system/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // ConfigVersionRepository handles database operations for configuration versions
@@ -19,12 +22,41 @@ func NewConfigVersionRepository(db *DB) *ConfigVersionRepository {
 	return &ConfigVersionRepository{db: db}
 }
 
+// ErrCorruptConfig is matched by errors about a stored configuration document that cannot
+// be served
+var ErrCorruptConfig = errors.New("corrupt configuration")
+
+// CorruptConfigError reports a configuration version whose stored document is not a JSON
+// object. The column only holds valid JSON, but a document written outside the service,
+// e.g. null or an array, would fail later in merges, the cache and SSE events.
+type CorruptConfigError struct {
+	Organization string
+	Application  string
+	Environment  string
+	Version      int
+}
+
+func (e *CorruptConfigError) Error() string {
+	return fmt.Sprintf("stored configuration of environment '%s/%s/%s' version %d is corrupt: not a JSON object",
+		e.Organization, e.Application, e.Environment, e.Version)
+}
+
+func (e *CorruptConfigError) Is(target error) bool {
+	return target == ErrCorruptConfig
+}
+
+// isConfigObject reports whether a stored configuration document is a JSON object
+func isConfigObject(config json.RawMessage) bool {
+	var document map[string]json.RawMessage
+	return json.Unmarshal(config, &document) == nil && document != nil
+}
+
 // GetActiveByEnvironment retrieves the active configuration for an environment
 func (r *ConfigVersionRepository) GetActiveByEnvironment(envID uuid.UUID) (*models.ConfigVersion, error) {
 	query := `
 		SELECT cv.id, cv.env_id, cv.version, cv.config_json, cv.is_active, cv.created_at, cv.created_by,
 		       e.id, e.app_id, e.name, e.slug, e.created_at, e.updated_at,
-		       a.id, a.org_id, a.name, a.slug, a.api_key, a.created_at, a.updated_at,
+		       a.id, a.org_id, a.name, a.slug, a.api_key_prefix, a.created_at, a.updated_at,
 		       o.id, o.name, o.slug, o.created_at, o.updated_at
 		FROM config_versions cv
 		JOIN environments e ON cv.env_id = e.id
@@ -41,7 +73,7 @@ func (r *ConfigVersionRepository) GetActiveByEnvironment(envID uuid.UUID) (*mode
 	err := r.db.QueryRow(query, envID).Scan(
 		&cv.ID, &cv.EnvID, &cv.Version, &cv.ConfigJSON, &cv.IsActive, &cv.CreatedAt, &cv.CreatedBy,
 		&env.ID, &env.AppID, &env.Name, &env.Slug, &env.CreatedAt, &env.UpdatedAt,
-		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKey, &app.CreatedAt, &app.UpdatedAt,
+		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.CreatedAt, &app.UpdatedAt,
 		&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
 	)
 
@@ -51,6 +83,9 @@ func (r *ConfigVersionRepository) GetActiveByEnvironment(envID uuid.UUID) (*mode
 		}
 		return nil, fmt.Errorf("failed to get active configuration: %w", err)
 	}
+	if !isConfigObject(cv.ConfigJSON) {
+		return nil, &CorruptConfigError{Organization: org.Slug, Application: app.Slug, Environment: env.Slug, Version: cv.Version}
+	}
 
 	app.Organization = &org
 	env.Application = &app
@@ -63,7 +98,7 @@ func (r *ConfigVersionRepository) GetByVersion(envID uuid.UUID, version int) (*m
 	query := `
 		SELECT cv.id, cv.env_id, cv.version, cv.config_json, cv.is_active, cv.created_at, cv.created_by,
 		       e.id, e.app_id, e.name, e.slug, e.created_at, e.updated_at,
-		       a.id, a.org_id, a.name, a.slug, a.api_key, a.created_at, a.updated_at,
+		       a.id, a.org_id, a.name, a.slug, a.api_key_prefix, a.created_at, a.updated_at,
 		       o.id, o.name, o.slug, o.created_at, o.updated_at
 		FROM config_versions cv
 		JOIN environments e ON cv.env_id = e.id
@@ -80,16 +115,19 @@ func (r *ConfigVersionRepository) GetByVersion(envID uuid.UUID, version int) (*m
 	err := r.db.QueryRow(query, envID, version).Scan(
 		&cv.ID, &cv.EnvID, &cv.Version, &cv.ConfigJSON, &cv.IsActive, &cv.CreatedAt, &cv.CreatedBy,
 		&env.ID, &env.AppID, &env.Name, &env.Slug, &env.CreatedAt, &env.UpdatedAt,
-		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKey, &app.CreatedAt, &app.UpdatedAt,
+		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.CreatedAt, &app.UpdatedAt,
 		&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("configuration version not found: env=%s, version=%d", envID, version)
+			return nil, notFoundError("configuration version not found: env=%s, version=%d", envID, version)
 		}
 		return nil, fmt.Errorf("failed to get configuration version: %w", err)
 	}
+	if !isConfigObject(cv.ConfigJSON) {
+		return nil, &CorruptConfigError{Organization: org.Slug, Application: app.Slug, Environment: env.Slug, Version: cv.Version}
+	}
 
 	app.Organization = &org
 	env.Application = &app
@@ -141,6 +179,200 @@ func (r *ConfigVersionRepository) ListByEnvironment(envID uuid.UUID, params mode
 	return versions, totalCount, nil
 }
 
+// ListEncrypted retrieves configuration versions across all environments that contain
+// encrypted top-level values, ordered so that pagination is stable while rows are updated
+func (r *ConfigVersionRepository) ListEncrypted(params models.PaginationParams) ([]models.ConfigVersion, int, error) {
+	const encryptedPath = `$.*."$encrypted"`
+
+	// Get total count
+	countQuery := "SELECT COUNT(*) FROM config_versions WHERE config_json @? $1::jsonpath"
+	var totalCount int
+	err := r.db.QueryRow(countQuery, encryptedPath).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get encrypted config versions count: %w", err)
+	}
+
+	// Get paginated results
+	query := `
+		SELECT cv.id, cv.env_id, cv.version, cv.config_json, cv.is_active, cv.created_at, cv.created_by
+		FROM config_versions cv
+		WHERE cv.config_json @? $1::jsonpath
+		ORDER BY cv.id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, encryptedPath, params.PageSize, params.Offset())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list encrypted config versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []models.ConfigVersion
+	for rows.Next() {
+		var cv models.ConfigVersion
+		err := rows.Scan(
+			&cv.ID, &cv.EnvID, &cv.Version, &cv.ConfigJSON, &cv.IsActive, &cv.CreatedAt, &cv.CreatedBy,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan config version: %w", err)
+		}
+		versions = append(versions, cv)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating config versions: %w", err)
+	}
+
+	return versions, totalCount, nil
+}
+
+// SearchActive finds active configuration versions whose document matches a JSON path query
+func (r *ConfigVersionRepository) SearchActive(jsonPath string, params models.PaginationParams) ([]models.ConfigSearchResult, int, error) {
+	// Get total count
+	countQuery := "SELECT COUNT(*) FROM config_versions WHERE is_active = TRUE AND config_json @? $1::jsonpath"
+	var totalCount int
+	err := r.db.QueryRow(countQuery, jsonPath).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	// Get paginated results
+	query := `
+		SELECT o.slug, a.slug, e.slug, cv.version, cv.created_at
+		FROM config_versions cv
+		JOIN environments e ON cv.env_id = e.id
+		JOIN applications a ON e.app_id = a.id
+		JOIN organizations o ON a.org_id = o.id
+		WHERE cv.is_active = TRUE AND cv.config_json @? $1::jsonpath
+		ORDER BY o.slug, a.slug, e.slug
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(query, jsonPath, params.PageSize, params.Offset())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search config versions: %w", err)
+	}
+	defer rows.Close()
+
+	results := []models.ConfigSearchResult{}
+	for rows.Next() {
+		var result models.ConfigSearchResult
+		err := rows.Scan(&result.Organization, &result.Application, &result.Environment, &result.Version, &result.UpdatedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	return results, totalCount, nil
+}
+
+// ListActive retrieves the active configuration version of every environment, with the
+// slugs of its organization and application, the application ID and the application
+// defaults, in a single query. Non-empty
+// orgSlug and appSlug limit the results to an organization or one of its applications.
+func (r *ConfigVersionRepository) ListActive(orgSlug, appSlug string) ([]models.ActiveConfig, error) {
+	query := `
+		SELECT o.slug, a.slug, e.slug, a.id, e.blob_keys, cv.id, cv.version, cv.config_json, cv.created_at,
+			EXISTS (SELECT 1 FROM config_rollouts r WHERE r.env_id = e.id),
+			d.config_json, COALESCE(d.version, 0)
+		FROM config_versions cv
+		JOIN environments e ON cv.env_id = e.id
+		JOIN applications a ON e.app_id = a.id
+		JOIN organizations o ON a.org_id = o.id
+		LEFT JOIN application_defaults d ON d.app_id = a.id
+		WHERE cv.is_active = TRUE
+			AND ($1 = '' OR o.slug = $1)
+			AND ($2 = '' OR a.slug = $2)
+		ORDER BY o.slug, a.slug, e.slug
+	`
+
+	rows, err := r.db.Query(query, orgSlug, appSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active config versions: %w", err)
+	}
+	defer rows.Close()
+
+	configs := []models.ActiveConfig{}
+	for rows.Next() {
+		var config models.ActiveConfig
+		err := rows.Scan(
+			&config.Organization, &config.Application, &config.Environment, &config.AppID, pq.Array(&config.BlobKeys),
+			&config.VersionID, &config.Version, &config.ConfigJSON, &config.CreatedAt, &config.HasRollout,
+			&config.DefaultsJSON, &config.DefaultsVersion,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan active config version: %w", err)
+		}
+		configs = append(configs, config)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating active config versions: %w", err)
+	}
+
+	return configs, nil
+}
+
+// GetSummary retrieves the active version, the number of versions and the time of the
+// latest configuration change of an environment. Tag changes do not count as changes.
+func (r *ConfigVersionRepository) GetSummary(envID uuid.UUID) (*models.EnvironmentConfigSummary, error) {
+	query := `
+		SELECT (SELECT version FROM config_versions WHERE env_id = $1 AND is_active = TRUE),
+		       (SELECT COUNT(*) FROM config_versions WHERE env_id = $1),
+		       (SELECT MAX(created_at) FROM config_changes
+		        WHERE env_id = $1 AND action NOT IN ('tag_create', 'tag_move', 'tag_delete'))
+	`
+
+	var summary models.EnvironmentConfigSummary
+	err := r.db.QueryRow(query, envID).Scan(&summary.ActiveVersion, &summary.VersionCount, &summary.LastChangedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config version summary: %w", err)
+	}
+
+	return &summary, nil
+}
+
+// ListKeyValues retrieves the value at a path of every version of an environment, oldest
+// first. Only the value is extracted from each document.
+func (r *ConfigVersionRepository) ListKeyValues(envID uuid.UUID, path []string) ([]models.KeyVersionValue, error) {
+	query := `
+		SELECT version, created_at, created_by, config_json #> $2::text[]
+		FROM config_versions
+		WHERE env_id = $1
+		ORDER BY version
+	`
+
+	rows, err := r.db.Query(query, envID, pq.Array(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list key values: %w", err)
+	}
+	defer rows.Close()
+
+	values := []models.KeyVersionValue{}
+	for rows.Next() {
+		var value models.KeyVersionValue
+		var raw []byte
+		if err := rows.Scan(&value.Version, &value.CreatedAt, &value.CreatedBy, &raw); err != nil {
+			return nil, fmt.Errorf("failed to scan key value: %w", err)
+		}
+		if raw != nil {
+			value.Value = json.RawMessage(raw)
+		}
+		values = append(values, value)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating key values: %w", err)
+	}
+
+	return values, nil
+}
+
 // GetNextVersion returns the next version number for an environment
 func (r *ConfigVersionRepository) GetNextVersion(envID uuid.UUID) (int, error) {
 	query := "SELECT COALESCE(MAX(version), 0) + 1 FROM config_versions WHERE env_id = $1"
@@ -154,40 +386,88 @@ func (r *ConfigVersionRepository) GetNextVersion(envID uuid.UUID) (int, error) {
 	return nextVersion, nil
 }
 
-// Create creates a new configuration version
+// versionAttempts is how many times Create numbers a new version before giving up, when
+// concurrent updates of the same environment keep taking the number first
+const versionAttempts = 3
+
+// Create creates a new configuration version. Unless the version is set, it is numbered
+// one after the latest version of the environment by the insert itself.
 func (r *ConfigVersionRepository) Create(cv *models.ConfigVersion) error {
-	tx, err := r.db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to start transaction: %w", err)
+	if cv.ID == uuid.Nil {
+		cv.ID = uuid.New()
 	}
-	defer tx.Rollback()
 
-	// Get next version if not set
-	if cv.Version == 0 {
-		nextVersion, err := r.GetNextVersion(cv.EnvID)
-		if err != nil {
+	for attempt := 1; ; attempt++ {
+		err := r.create(cv)
+		if err == nil || !isVersionConflict(err) {
 			return err
 		}
-		cv.Version = nextVersion
+
+		// Another update of the environment took the number; a version given by the
+		// caller is not renumbered
+		if cv.Version != 0 || attempt == versionAttempts {
+			return versionConflictError("version conflict: another version of environment %s was created at the same time, try again", cv.EnvID)
+		}
+	}
+}
+
+// create inserts a configuration version, numbering it unless its version is set
+func (r *ConfigVersionRepository) create(cv *models.ConfigVersion) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Create the new version
+	// The number is computed in the same statement as the insert, so the constraint on
+	// (env_id, version) only fails if a concurrent insert committed the same number
 	query := `
 		INSERT INTO config_versions (id, env_id, version, config_json, is_active, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING created_at
+		SELECT $1, $2, COALESCE(NULLIF($3, 0), COALESCE(MAX(version), 0) + 1), $4, $5, $6
+		FROM config_versions WHERE env_id = $2
+		RETURNING version, created_at
 	`
 
-	if cv.ID == uuid.Nil {
-		cv.ID = uuid.New()
+	var version int
+	err = tx.QueryRow(query, cv.ID, cv.EnvID, cv.Version, cv.ConfigJSON, cv.IsActive, cv.CreatedBy).Scan(&version, &cv.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create config version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	err = tx.QueryRow(query, cv.ID, cv.EnvID, cv.Version, cv.ConfigJSON, cv.IsActive, cv.CreatedBy).Scan(&cv.CreatedAt)
+	cv.Version = version
+	return nil
+}
+
+// isVersionConflict reports whether an insert failed because the environment already has
+// a version with the same number
+func isVersionConflict(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "config_versions_env_id_version_key"
+}
+
+// UpdateConfigJSON replaces the stored document of an existing configuration version in place
+func (r *ConfigVersionRepository) UpdateConfigJSON(id uuid.UUID, config json.RawMessage) error {
+	query := "UPDATE config_versions SET config_json = $2 WHERE id = $1"
+
+	result, err := r.db.Exec(query, id, config)
 	if err != nil {
-		return fmt.Errorf("failed to create config version: %w", err)
+		return fmt.Errorf("failed to update config version: %w", err)
 	}
 
-	return tx.Commit()
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFoundError("config version not found: %s", id)
+	}
+
+	return nil
 }
 
 // SetActive sets a configuration version as active (deactivating others)
@@ -216,12 +496,214 @@ func (r *ConfigVersionRepository) SetActive(envID uuid.UUID, version int) error
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("configuration version not found: env=%s, version=%d", envID, version)
+		return notFoundError("configuration version not found: env=%s, version=%d", envID, version)
 	}
 
 	return tx.Commit()
 }
 
+// Rollback activates an earlier version of an environment and records the change in the
+// change log in a single transaction. The active version is locked while it is replaced,
+// and change.VersionFrom is set to it; change.CreatedAt is set to the time of activation.
+// Rolling back to the version that is already active returns an ErrAlreadyActive error.
+func (r *ConfigVersionRepository) Rollback(change *models.ConfigChange) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var activeVersion int
+	err = tx.QueryRow("SELECT version FROM config_versions WHERE env_id = $1 AND is_active = TRUE FOR UPDATE", change.EnvID).Scan(&activeVersion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return notFoundError("no active configuration found for environment: %s", change.EnvID)
+		}
+		return fmt.Errorf("failed to get active configuration: %w", err)
+	}
+	if activeVersion == change.VersionTo {
+		return alreadyActiveError("configuration version already active: env=%s, version=%d", change.EnvID, change.VersionTo)
+	}
+	change.VersionFrom = &activeVersion
+
+	if _, err := tx.Exec("UPDATE config_versions SET is_active = FALSE WHERE env_id = $1 AND version = $2", change.EnvID, activeVersion); err != nil {
+		return fmt.Errorf("failed to deactivate config version: %w", err)
+	}
+
+	result, err := tx.Exec("UPDATE config_versions SET is_active = TRUE WHERE env_id = $1 AND version = $2", change.EnvID, change.VersionTo)
+	if err != nil {
+		return fmt.Errorf("failed to activate config version: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFoundError("configuration version not found: env=%s, version=%d", change.EnvID, change.VersionTo)
+	}
+
+	// The change log entry is dated with the transaction, and so with the activation
+	query := `
+		INSERT INTO config_changes (id, env_id, version_from, version_to, action, created_by, approved_by, message, tag, scope)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at
+	`
+
+	if change.ID == uuid.Nil {
+		change.ID = uuid.New()
+	}
+	if change.Scope == "" {
+		change.Scope = models.ChangeScopeEnvironment
+	}
+
+	err = tx.QueryRow(query, change.ID, change.EnvID, change.VersionFrom, change.VersionTo, change.Action, change.CreatedBy, change.ApprovedBy, change.Message, change.Tag, change.Scope).Scan(&change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create config change: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// CompareAndSwap creates a new active version of an environment and records the change in
+// the change log in a single transaction, provided the active version is still
+// change.VersionFrom (nil if the environment had none). The active version is locked while
+// it is compared and replaced; a "version conflict" error is returned if another version
+// became active in the meantime.
+func (r *ConfigVersionRepository) CompareAndSwap(cv *models.ConfigVersion, change *models.ConfigChange) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var activeVersion *int
+	var version int
+	err = tx.QueryRow("SELECT version FROM config_versions WHERE env_id = $1 AND is_active = TRUE FOR UPDATE", cv.EnvID).Scan(&version)
+	if err == nil {
+		activeVersion = &version
+	} else if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get active configuration: %w", err)
+	}
+	if (activeVersion == nil) != (change.VersionFrom == nil) || (activeVersion != nil && *activeVersion != *change.VersionFrom) {
+		return versionConflictError("version conflict: the active version of environment %s changed at the same time, try again", cv.EnvID)
+	}
+
+	if cv.ID == uuid.Nil {
+		cv.ID = uuid.New()
+	}
+	cv.IsActive = true
+
+	versionQuery := `
+		INSERT INTO config_versions (id, env_id, version, config_json, is_active, created_by)
+		SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, TRUE, $4
+		FROM config_versions WHERE env_id = $2
+		RETURNING version, created_at
+	`
+	if err := tx.QueryRow(versionQuery, cv.ID, cv.EnvID, cv.ConfigJSON, cv.CreatedBy).Scan(&cv.Version, &cv.CreatedAt); err != nil {
+		if isVersionConflict(err) {
+			return versionConflictError("version conflict: another version of environment %s was created at the same time, try again", cv.EnvID)
+		}
+		return fmt.Errorf("failed to create config version: %w", err)
+	}
+
+	if change.ID == uuid.Nil {
+		change.ID = uuid.New()
+	}
+	if change.Scope == "" {
+		change.Scope = models.ChangeScopeEnvironment
+	}
+	change.EnvID = cv.EnvID
+	change.VersionTo = cv.Version
+
+	changeQuery := `
+		INSERT INTO config_changes (id, env_id, version_from, version_to, action, created_by, message, scope)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+	if err := tx.QueryRow(changeQuery, change.ID, change.EnvID, change.VersionFrom, change.VersionTo, change.Action, change.CreatedBy, change.Message, change.Scope).Scan(&change.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create config change: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CreateMany creates a new active version for each of several environments and records
+// each change in the change log, in a single transaction. Each version must have its
+// change at the same index; the version numbers and change.VersionFrom are set from the
+// environment's versions, whose active version is locked while it is replaced. An update
+// of an environment without an active version is logged as its creation.
+func (r *ConfigVersionRepository) CreateMany(versions []*models.ConfigVersion, changes []*models.ConfigChange) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	versionQuery := `
+		INSERT INTO config_versions (id, env_id, version, config_json, is_active, created_by)
+		SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, TRUE, $4
+		FROM config_versions WHERE env_id = $2
+		RETURNING version, created_at
+	`
+	changeQuery := `
+		INSERT INTO config_changes (id, env_id, version_from, version_to, action, created_by, message, scope)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+
+	for i, cv := range versions {
+		change := changes[i]
+
+		var activeVersion int
+		err := tx.QueryRow("SELECT version FROM config_versions WHERE env_id = $1 AND is_active = TRUE FOR UPDATE", cv.EnvID).Scan(&activeVersion)
+		if err == nil {
+			change.VersionFrom = &activeVersion
+		} else if err != sql.ErrNoRows {
+			return fmt.Errorf("failed to get active configuration: %w", err)
+		} else if change.Action == "update" {
+			// The environment's first configuration
+			change.Action = "create"
+		}
+
+		if cv.ID == uuid.Nil {
+			cv.ID = uuid.New()
+		}
+		cv.IsActive = true
+
+		if err := tx.QueryRow(versionQuery, cv.ID, cv.EnvID, cv.ConfigJSON, cv.CreatedBy).Scan(&cv.Version, &cv.CreatedAt); err != nil {
+			if isVersionConflict(err) {
+				return versionConflictError("version conflict: another version of environment %s was created at the same time, try again", cv.EnvID)
+			}
+			return fmt.Errorf("failed to create config version: %w", err)
+		}
+
+		if change.ID == uuid.Nil {
+			change.ID = uuid.New()
+		}
+		if change.Scope == "" {
+			change.Scope = models.ChangeScopeEnvironment
+		}
+		change.EnvID = cv.EnvID
+		change.VersionTo = cv.Version
+
+		if err := tx.QueryRow(changeQuery, change.ID, change.EnvID, change.VersionFrom, change.VersionTo, change.Action, change.CreatedBy, change.Message, change.Scope).Scan(&change.CreatedAt); err != nil {
+			return fmt.Errorf("failed to create config change: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // Delete deletes a configuration version (only if not active)
 func (r *ConfigVersionRepository) Delete(envID uuid.UUID, version int) error {
 	query := "DELETE FROM config_versions WHERE env_id = $1 AND version = $2 AND is_active = FALSE"
@@ -237,8 +719,42 @@ func (r *ConfigVersionRepository) Delete(envID uuid.UUID, version int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("configuration version not found or is active: env=%s, version=%d", envID, version)
+		return notFoundError("configuration version not found or is active: env=%s, version=%d", envID, version)
 	}
 
 	return nil
 }
+
+// Prune deletes the inactive versions of an environment that are neither among its
+// keepVersions latest versions nor younger than keepDays days, and returns how many were
+// deleted. A zero limit keeps no version by that rule. Tagged versions, the version of a
+// rollout in progress and versions awaiting approval are always kept.
+func (r *ConfigVersionRepository) Prune(envID uuid.UUID, keepVersions, keepDays int) (int, error) {
+	query := `
+		DELETE FROM config_versions cv
+		WHERE cv.env_id = $1
+		  AND cv.is_active = FALSE
+		  AND cv.version NOT IN (
+		      SELECT version FROM config_versions WHERE env_id = $1 ORDER BY version DESC LIMIT $2
+		  )
+		  AND cv.created_at < NOW() - $3 * INTERVAL '1 day'
+		  AND NOT EXISTS (SELECT 1 FROM config_tags t WHERE t.env_id = cv.env_id AND t.version = cv.version)
+		  AND NOT EXISTS (SELECT 1 FROM config_rollouts ro WHERE ro.env_id = cv.env_id AND ro.version = cv.version)
+		  AND NOT EXISTS (
+		      SELECT 1 FROM pending_changes pc
+		      WHERE pc.env_id = cv.env_id AND pc.version = cv.version AND pc.status = 'pending'
+		  )
+	`
+
+	result, err := r.db.Exec(query, envID, keepVersions, keepDays)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune config versions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsConfigObject(t *testing.T) {
+	assert.True(t, isConfigObject(json.RawMessage(`{}`)))
+	assert.True(t, isConfigObject(json.RawMessage(`{"feature": {"enabled": true}}`)))
+
+	for _, document := range []string{`null`, `[1, 2]`, `"oops"`, `42`, `true`, `{"feature":`, ``} {
+		assert.False(t, isConfigObject(json.RawMessage(document)), document)
+	}
+}
+
+func TestCorruptConfigError(t *testing.T) {
+	err := error(&CorruptConfigError{Organization: "acme", Application: "web", Environment: "prod", Version: 7})
+	assert.Equal(t, "stored configuration of environment 'acme/web/prod' version 7 is corrupt: not a JSON object", err.Error())
+	assert.True(t, errors.Is(err, ErrCorruptConfig))
+}
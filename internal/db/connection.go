@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -14,6 +15,7 @@ import (
 // DB holds the database connection
 type DB struct {
 	*sql.DB
+	config *Config
 }
 
 // Config holds database configuration
@@ -24,17 +26,43 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	// Connection pool settings; zero means no limit
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// PoolStats holds the connection pool settings and usage statistics
+type PoolStats struct {
+	MaxOpenConns      int    `json:"max_open_conns"`
+	MaxIdleConns      int    `json:"max_idle_conns"`
+	ConnMaxLifetime   string `json:"conn_max_lifetime"`
+	ConnMaxIdleTime   string `json:"conn_max_idle_time"`
+	OpenConnections   int    `json:"open_connections"`
+	InUse             int    `json:"in_use"`
+	Idle              int    `json:"idle"`
+	WaitCount         int64  `json:"wait_count"`
+	WaitDuration      string `json:"wait_duration"`
+	MaxIdleClosed     int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64  `json:"max_lifetime_closed"`
 }
 
 // NewConfig creates a new database configuration from environment variables
 func NewConfig() *Config {
 	return &Config{
-		Host:     getEnv("DB_HOST", "localhost"),
-		Port:     getEnv("DB_PORT", "5432"),
-		User:     getEnv("DB_USER", "postgres"),
-		Password: getEnv("DB_PASSWORD", "postgres"),
-		DBName:   getEnv("DB_NAME", "remote_config"),
-		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+		Host:            getEnv("DB_HOST", "localhost"),
+		Port:            getEnv("DB_PORT", "5432"),
+		User:            getEnv("DB_USER", "postgres"),
+		Password:        getEnv("DB_PASSWORD", "postgres"),
+		DBName:          getEnv("DB_NAME", "remote_config"),
+		SSLMode:         getEnv("DB_SSLMODE", "disable"),
+		MaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 25),
+		MaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 5),
+		ConnMaxLifetime: time.Duration(getEnvInt("DB_CONN_MAX_LIFETIME", 300)) * time.Second,
+		ConnMaxIdleTime: time.Duration(getEnvInt("DB_CONN_MAX_IDLE_TIME", 0)) * time.Second,
 	}
 }
 
@@ -51,9 +79,10 @@ func Connect(config *Config) (*DB, error) {
 	}
 
 	// Configure connection pool
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
@@ -61,8 +90,10 @@ func Connect(config *Config) (*DB, error) {
 	}
 
 	log.Printf("Successfully connected to database %s:%s/%s", config.Host, config.Port, config.DBName)
+	log.Printf("Database pool: max_open_conns=%d max_idle_conns=%d conn_max_lifetime=%s conn_max_idle_time=%s",
+		config.MaxOpenConns, config.MaxIdleConns, config.ConnMaxLifetime, config.ConnMaxIdleTime)
 
-	return &DB{db}, nil
+	return &DB{DB: db, config: config}, nil
 }
 
 // Close closes the database connection
@@ -87,6 +118,25 @@ func (db *DB) Health() error {
 	return nil
 }
 
+// PoolStats returns the connection pool settings and usage statistics
+func (db *DB) PoolStats() *PoolStats {
+	stats := db.Stats()
+	return &PoolStats{
+		MaxOpenConns:      db.config.MaxOpenConns,
+		MaxIdleConns:      db.config.MaxIdleConns,
+		ConnMaxLifetime:   db.config.ConnMaxLifetime.String(),
+		ConnMaxIdleTime:   db.config.ConnMaxIdleTime.String(),
+		OpenConnections:   stats.OpenConnections,
+		InUse:             stats.InUse,
+		Idle:              stats.Idle,
+		WaitCount:         stats.WaitCount,
+		WaitDuration:      stats.WaitDuration.String(),
+		MaxIdleClosed:     stats.MaxIdleClosed,
+		MaxIdleTimeClosed: stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed: stats.MaxLifetimeClosed,
+	}
+}
+
 // getEnv gets an environment variable with a fallback value
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
@@ -94,3 +144,13 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getEnvInt gets an integer environment variable with a fallback value
+func getEnvInt(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+			return parsed
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfig_PoolSettings(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		config := NewConfig()
+		assert.Equal(t, 25, config.MaxOpenConns)
+		assert.Equal(t, 5, config.MaxIdleConns)
+		assert.Equal(t, 5*time.Minute, config.ConnMaxLifetime)
+		assert.Equal(t, time.Duration(0), config.ConnMaxIdleTime)
+	})
+
+	t.Run("from environment", func(t *testing.T) {
+		t.Setenv("DB_MAX_OPEN_CONNS", "100")
+		t.Setenv("DB_MAX_IDLE_CONNS", "20")
+		t.Setenv("DB_CONN_MAX_LIFETIME", "0")
+		t.Setenv("DB_CONN_MAX_IDLE_TIME", "60")
+
+		config := NewConfig()
+		assert.Equal(t, 100, config.MaxOpenConns)
+		assert.Equal(t, 20, config.MaxIdleConns)
+		assert.Equal(t, time.Duration(0), config.ConnMaxLifetime)
+		assert.Equal(t, time.Minute, config.ConnMaxIdleTime)
+	})
+
+	t.Run("invalid values fall back to defaults", func(t *testing.T) {
+		t.Setenv("DB_MAX_OPEN_CONNS", "lots")
+		t.Setenv("DB_MAX_IDLE_CONNS", "-1")
+
+		config := NewConfig()
+		assert.Equal(t, 25, config.MaxOpenConns)
+		assert.Equal(t, 5, config.MaxIdleConns)
+	})
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// EnvironmentLabelRepository handles database operations for environment labels
+type EnvironmentLabelRepository struct {
+	db *DB
+}
+
+// NewEnvironmentLabelRepository creates a new environment label repository
+func NewEnvironmentLabelRepository(db *DB) *EnvironmentLabelRepository {
+	return &EnvironmentLabelRepository{db: db}
+}
+
+// ListByEnvironment retrieves the labels of an environment
+func (r *EnvironmentLabelRepository) ListByEnvironment(envID uuid.UUID) (map[string]string, error) {
+	rows, err := r.db.Query("SELECT key, value FROM environment_labels WHERE env_id = $1", envID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	defer rows.Close()
+
+	labels := map[string]string{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan label: %w", err)
+		}
+		labels[key] = value
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+// Set sets a label of an environment and reports whether the label was created
+func (r *EnvironmentLabelRepository) Set(envID uuid.UUID, key, value string) (bool, error) {
+	// xmax is 0 only for rows the statement inserted
+	query := `
+		INSERT INTO environment_labels (env_id, key, value)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (env_id, key) DO UPDATE SET value = EXCLUDED.value
+		RETURNING xmax = 0
+	`
+
+	var created bool
+	if err := r.db.QueryRow(query, envID, key, value).Scan(&created); err != nil {
+		return false, fmt.Errorf("failed to set label: %w", err)
+	}
+
+	return created, nil
+}
+
+// Delete removes a label from an environment
+func (r *EnvironmentLabelRepository) Delete(envID uuid.UUID, key string) error {
+	result, err := r.db.Exec("DELETE FROM environment_labels WHERE env_id = $1 AND key = $2", envID, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete label: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFoundError("label not found: %s", key)
+	}
+
+	return nil
+}
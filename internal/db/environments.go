@@ -3,10 +3,12 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"remote-config-system/internal/models"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // EnvironmentRepository handles database operations for environments
@@ -22,8 +24,8 @@ func NewEnvironmentRepository(db *DB) *EnvironmentRepository {
 // GetBySlug retrieves an environment by organization, application, and environment slugs
 func (r *EnvironmentRepository) GetBySlug(orgSlug, appSlug, envSlug string) (*models.Environment, error) {
 	query := `
-		SELECT e.id, e.app_id, e.name, e.slug, e.created_at, e.updated_at,
-		       a.id, a.org_id, a.name, a.slug, a.api_key, a.created_at, a.updated_at,
+		SELECT e.id, e.app_id, e.name, e.slug, e.secret_keys, e.blob_keys, e.requires_approval, e.cache_ttl_seconds, e.retention_versions, e.retention_days, e.golden_environment, e.golden_strict, e.locked, e.lock_reason, e.locked_by, e.locked_at, e.created_at, e.updated_at,
+		       a.id, a.org_id, a.name, a.slug, a.api_key_prefix, a.created_at, a.updated_at,
 		       o.id, o.name, o.slug, o.created_at, o.updated_at
 		FROM environments e
 		JOIN applications a ON e.app_id = a.id
@@ -36,14 +38,14 @@ func (r *EnvironmentRepository) GetBySlug(orgSlug, appSlug, envSlug string) (*mo
 	var org models.Organization
 
 	err := r.db.QueryRow(query, orgSlug, appSlug, envSlug).Scan(
-		&env.ID, &env.AppID, &env.Name, &env.Slug, &env.CreatedAt, &env.UpdatedAt,
-		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKey, &app.CreatedAt, &app.UpdatedAt,
+		&env.ID, &env.AppID, &env.Name, &env.Slug, pq.Array(&env.SecretKeys), pq.Array(&env.BlobKeys), &env.RequiresApproval, &env.CacheTTLSeconds, &env.RetentionVersions, &env.RetentionDays, &env.GoldenEnvironment, &env.GoldenStrict, &env.Locked, &env.LockReason, &env.LockedBy, &env.LockedAt, &env.CreatedAt, &env.UpdatedAt,
+		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.CreatedAt, &app.UpdatedAt,
 		&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("environment not found: %s/%s/%s", orgSlug, appSlug, envSlug)
+			return nil, notFoundError("environment not found: %s/%s/%s", orgSlug, appSlug, envSlug)
 		}
 		return nil, fmt.Errorf("failed to get environment: %w", err)
 	}
@@ -56,8 +58,8 @@ func (r *EnvironmentRepository) GetBySlug(orgSlug, appSlug, envSlug string) (*mo
 // GetByID retrieves an environment by its ID
 func (r *EnvironmentRepository) GetByID(id uuid.UUID) (*models.Environment, error) {
 	query := `
-		SELECT e.id, e.app_id, e.name, e.slug, e.created_at, e.updated_at,
-		       a.id, a.org_id, a.name, a.slug, a.api_key, a.created_at, a.updated_at,
+		SELECT e.id, e.app_id, e.name, e.slug, e.secret_keys, e.blob_keys, e.requires_approval, e.cache_ttl_seconds, e.retention_versions, e.retention_days, e.golden_environment, e.golden_strict, e.locked, e.lock_reason, e.locked_by, e.locked_at, e.created_at, e.updated_at,
+		       a.id, a.org_id, a.name, a.slug, a.api_key_prefix, a.created_at, a.updated_at,
 		       o.id, o.name, o.slug, o.created_at, o.updated_at
 		FROM environments e
 		JOIN applications a ON e.app_id = a.id
@@ -70,14 +72,14 @@ func (r *EnvironmentRepository) GetByID(id uuid.UUID) (*models.Environment, erro
 	var org models.Organization
 
 	err := r.db.QueryRow(query, id).Scan(
-		&env.ID, &env.AppID, &env.Name, &env.Slug, &env.CreatedAt, &env.UpdatedAt,
-		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKey, &app.CreatedAt, &app.UpdatedAt,
+		&env.ID, &env.AppID, &env.Name, &env.Slug, pq.Array(&env.SecretKeys), pq.Array(&env.BlobKeys), &env.RequiresApproval, &env.CacheTTLSeconds, &env.RetentionVersions, &env.RetentionDays, &env.GoldenEnvironment, &env.GoldenStrict, &env.Locked, &env.LockReason, &env.LockedBy, &env.LockedAt, &env.CreatedAt, &env.UpdatedAt,
+		&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.CreatedAt, &app.UpdatedAt,
 		&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("environment not found: %s", id)
+			return nil, notFoundError("environment not found: %s", id)
 		}
 		return nil, fmt.Errorf("failed to get environment: %w", err)
 	}
@@ -99,8 +101,8 @@ func (r *EnvironmentRepository) ListByApplication(appID uuid.UUID, params models
 
 	// Get paginated results
 	query := `
-		SELECT e.id, e.app_id, e.name, e.slug, e.created_at, e.updated_at,
-		       a.id, a.org_id, a.name, a.slug, a.api_key, a.created_at, a.updated_at,
+		SELECT e.id, e.app_id, e.name, e.slug, e.secret_keys, e.blob_keys, e.requires_approval, e.cache_ttl_seconds, e.retention_versions, e.retention_days, e.golden_environment, e.golden_strict, e.locked, e.lock_reason, e.locked_by, e.locked_at, e.created_at, e.updated_at,
+		       a.id, a.org_id, a.name, a.slug, a.api_key_prefix, a.created_at, a.updated_at,
 		       o.id, o.name, o.slug, o.created_at, o.updated_at
 		FROM environments e
 		JOIN applications a ON e.app_id = a.id
@@ -123,8 +125,8 @@ func (r *EnvironmentRepository) ListByApplication(appID uuid.UUID, params models
 		var org models.Organization
 
 		err := rows.Scan(
-			&env.ID, &env.AppID, &env.Name, &env.Slug, &env.CreatedAt, &env.UpdatedAt,
-			&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKey, &app.CreatedAt, &app.UpdatedAt,
+			&env.ID, &env.AppID, &env.Name, &env.Slug, pq.Array(&env.SecretKeys), pq.Array(&env.BlobKeys), &env.RequiresApproval, &env.CacheTTLSeconds, &env.RetentionVersions, &env.RetentionDays, &env.GoldenEnvironment, &env.GoldenStrict, &env.Locked, &env.LockReason, &env.LockedBy, &env.LockedAt, &env.CreatedAt, &env.UpdatedAt,
+			&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.CreatedAt, &app.UpdatedAt,
 			&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
 		)
 		if err != nil {
@@ -143,19 +145,215 @@ func (r *EnvironmentRepository) ListByApplication(appID uuid.UUID, params models
 	return environments, totalCount, nil
 }
 
+// ListAllByApplication retrieves every environment of an application, without
+// pagination, relationships or settings other than the lock
+func (r *EnvironmentRepository) ListAllByApplication(appID uuid.UUID) ([]models.Environment, error) {
+	query := `
+		SELECT id, app_id, name, slug, locked, lock_reason, locked_by, locked_at, created_at, updated_at
+		FROM environments
+		WHERE app_id = $1
+		ORDER BY slug
+	`
+
+	rows, err := r.db.Query(query, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+	defer rows.Close()
+
+	environments := []models.Environment{}
+	for rows.Next() {
+		var env models.Environment
+		if err := rows.Scan(&env.ID, &env.AppID, &env.Name, &env.Slug, &env.Locked, &env.LockReason, &env.LockedBy, &env.LockedAt, &env.CreatedAt, &env.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan environment: %w", err)
+		}
+		environments = append(environments, env)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating environments: %w", err)
+	}
+
+	return environments, nil
+}
+
+// ListTreeByOrganization retrieves every environment of an organization's applications
+// along with its active version, keyed by application ID and ordered by slug
+func (r *EnvironmentRepository) ListTreeByOrganization(orgID uuid.UUID) (map[uuid.UUID][]models.EnvironmentTreeNode, error) {
+	query := `
+		SELECT e.id, e.app_id, e.name, e.slug, e.requires_approval, e.locked, cv.version
+		FROM environments e
+		JOIN applications a ON e.app_id = a.id
+		LEFT JOIN config_versions cv ON cv.env_id = e.id AND cv.is_active = TRUE
+		WHERE a.org_id = $1
+		ORDER BY e.slug
+	`
+
+	rows, err := r.db.Query(query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+	defer rows.Close()
+
+	environments := make(map[uuid.UUID][]models.EnvironmentTreeNode)
+	for rows.Next() {
+		var env models.EnvironmentTreeNode
+		var appID uuid.UUID
+		if err := rows.Scan(&env.ID, &appID, &env.Name, &env.Slug, &env.RequiresApproval, &env.Locked, &env.ActiveVersion); err != nil {
+			return nil, fmt.Errorf("failed to scan environment: %w", err)
+		}
+		environments[appID] = append(environments[appID], env)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating environments: %w", err)
+	}
+
+	return environments, nil
+}
+
+// ListAll retrieves every environment with its application and organization, without
+// pagination
+func (r *EnvironmentRepository) ListAll() ([]models.Environment, error) {
+	return r.list("TRUE")
+}
+
+// ListBySelector retrieves the environments of an organization, or of one of its
+// applications when appID is set, whose labels meet every requirement of a label selector.
+// A key!=value requirement is also met by environments without the label.
+func (r *EnvironmentRepository) ListBySelector(orgID uuid.UUID, appID *uuid.UUID, selector []models.LabelRequirement) ([]models.Environment, error) {
+	conditions := []string{"o.id = $1"}
+	args := []interface{}{orgID}
+	if appID != nil {
+		args = append(args, *appID)
+		conditions = append(conditions, fmt.Sprintf("a.id = $%d", len(args)))
+	}
+
+	for _, requirement := range selector {
+		args = append(args, requirement.Key)
+		label := fmt.Sprintf("SELECT 1 FROM environment_labels l WHERE l.env_id = e.id AND l.key = $%d", len(args))
+		switch requirement.Operator {
+		case models.LabelEquals:
+			args = append(args, requirement.Value)
+			conditions = append(conditions, fmt.Sprintf("EXISTS (%s AND l.value = $%d)", label, len(args)))
+		case models.LabelNotEquals:
+			args = append(args, requirement.Value)
+			conditions = append(conditions, fmt.Sprintf("NOT EXISTS (%s AND l.value = $%d)", label, len(args)))
+		default:
+			conditions = append(conditions, fmt.Sprintf("EXISTS (%s)", label))
+		}
+	}
+
+	return r.list(strings.Join(conditions, " AND "), args...)
+}
+
+// list retrieves the environments meeting a condition with their applications and
+// organizations
+func (r *EnvironmentRepository) list(condition string, args ...interface{}) ([]models.Environment, error) {
+	query := `
+		SELECT e.id, e.app_id, e.name, e.slug, e.secret_keys, e.blob_keys, e.requires_approval, e.cache_ttl_seconds, e.retention_versions, e.retention_days, e.golden_environment, e.golden_strict, e.locked, e.lock_reason, e.locked_by, e.locked_at, e.created_at, e.updated_at,
+		       a.id, a.org_id, a.name, a.slug, a.api_key_prefix, a.created_at, a.updated_at,
+		       o.id, o.name, o.slug, o.created_at, o.updated_at
+		FROM environments e
+		JOIN applications a ON e.app_id = a.id
+		JOIN organizations o ON a.org_id = o.id
+		WHERE ` + condition + `
+		ORDER BY o.slug, a.slug, e.slug
+	`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+	defer rows.Close()
+
+	environments := []models.Environment{}
+	for rows.Next() {
+		var env models.Environment
+		var app models.Application
+		var org models.Organization
+
+		err := rows.Scan(
+			&env.ID, &env.AppID, &env.Name, &env.Slug, pq.Array(&env.SecretKeys), pq.Array(&env.BlobKeys), &env.RequiresApproval, &env.CacheTTLSeconds, &env.RetentionVersions, &env.RetentionDays, &env.GoldenEnvironment, &env.GoldenStrict, &env.Locked, &env.LockReason, &env.LockedBy, &env.LockedAt, &env.CreatedAt, &env.UpdatedAt,
+			&app.ID, &app.OrgID, &app.Name, &app.Slug, &app.APIKeyPrefix, &app.CreatedAt, &app.UpdatedAt,
+			&org.ID, &org.Name, &org.Slug, &org.CreatedAt, &org.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan environment: %w", err)
+		}
+
+		app.Organization = &org
+		env.Application = &app
+		environments = append(environments, env)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating environments: %w", err)
+	}
+
+	return environments, nil
+}
+
+// ListActivity retrieves every environment that has an active version, with when its
+// configuration last changed and when it was last read with the application's API key.
+// Tag changes do not count as changes; an environment without change log entries counts
+// as changed when its active version was created. Batch reads, which are counted without
+// an environment, count as reads of every environment of the application.
+func (r *EnvironmentRepository) ListActivity() ([]models.EnvironmentActivity, error) {
+	query := `
+		SELECT o.slug, a.slug, e.slug, cv.version, e.created_at,
+		       COALESCE((SELECT MAX(cc.created_at) FROM config_changes cc
+		                 WHERE cc.env_id = e.id AND cc.action NOT IN ('tag_create', 'tag_move', 'tag_delete')), cv.created_at),
+		       (SELECT MAX(u.last_seen_at) FROM api_key_usage u
+		        WHERE u.app_id = a.id AND u.environment IN (e.slug, ''))
+		FROM environments e
+		JOIN applications a ON e.app_id = a.id
+		JOIN organizations o ON a.org_id = o.id
+		JOIN config_versions cv ON cv.env_id = e.id AND cv.is_active = TRUE
+		ORDER BY o.slug, a.slug, e.slug
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environment activity: %w", err)
+	}
+	defer rows.Close()
+
+	activity := []models.EnvironmentActivity{}
+	for rows.Next() {
+		var env models.EnvironmentActivity
+		if err := rows.Scan(&env.Organization, &env.Application, &env.Environment, &env.ActiveVersion, &env.CreatedAt, &env.LastChangedAt, &env.LastReadAt); err != nil {
+			return nil, fmt.Errorf("failed to scan environment activity: %w", err)
+		}
+		activity = append(activity, env)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating environment activity: %w", err)
+	}
+
+	return activity, nil
+}
+
 // Create creates a new environment
 func (r *EnvironmentRepository) Create(env *models.Environment) error {
 	query := `
-		INSERT INTO environments (id, app_id, name, slug)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO environments (id, app_id, name, slug, secret_keys, blob_keys, requires_approval, cache_ttl_seconds, retention_versions, retention_days, golden_environment, golden_strict)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING created_at, updated_at
 	`
 
 	if env.ID == uuid.Nil {
 		env.ID = uuid.New()
 	}
+	if env.SecretKeys == nil {
+		env.SecretKeys = []string{}
+	}
+	if env.BlobKeys == nil {
+		env.BlobKeys = []string{}
+	}
 
-	err := r.db.QueryRow(query, env.ID, env.AppID, env.Name, env.Slug).Scan(
+	err := r.db.QueryRow(query, env.ID, env.AppID, env.Name, env.Slug, pq.Array(env.SecretKeys), pq.Array(env.BlobKeys), env.RequiresApproval, env.CacheTTLSeconds, env.RetentionVersions, env.RetentionDays, env.GoldenEnvironment, env.GoldenStrict).Scan(
 		&env.CreatedAt,
 		&env.UpdatedAt,
 	)
@@ -167,19 +365,118 @@ func (r *EnvironmentRepository) Create(env *models.Environment) error {
 	return nil
 }
 
+// CreateMany creates environments, each with its initial configuration version if
+// configs holds one at the same index, in a single transaction. Initial configurations are
+// logged as "create" changes in the same transaction. The returned slice holds
+// the error of each environment that could not be created. Unless continueOnError is
+// set, the first such error rolls back every environment and is also returned as the
+// overall error; otherwise only the failed environments are rolled back.
+func (r *EnvironmentRepository) CreateMany(envs []*models.Environment, configs []*models.ConfigVersion, continueOnError bool) ([]error, error) {
+	errs := make([]error, len(envs))
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errs, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	envQuery := `
+		INSERT INTO environments (id, app_id, name, slug, secret_keys, blob_keys, requires_approval, cache_ttl_seconds, retention_versions, retention_days, golden_environment, golden_strict)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING created_at, updated_at
+	`
+	configQuery := `
+		INSERT INTO config_versions (id, env_id, version, config_json, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`
+	changeQuery := `
+		INSERT INTO config_changes (id, env_id, version_to, action, created_by)
+		VALUES ($1, $2, $3, 'create', $4)
+	`
+
+	for i, env := range envs {
+		if env.ID == uuid.Nil {
+			env.ID = uuid.New()
+		}
+		if env.SecretKeys == nil {
+			env.SecretKeys = []string{}
+		}
+		if env.BlobKeys == nil {
+			env.BlobKeys = []string{}
+		}
+
+		// A savepoint lets a failed environment be undone without aborting the transaction
+		if _, err := tx.Exec("SAVEPOINT create_environment"); err != nil {
+			return errs, fmt.Errorf("failed to create savepoint: %w", err)
+		}
+
+		err := tx.QueryRow(envQuery, env.ID, env.AppID, env.Name, env.Slug, pq.Array(env.SecretKeys), pq.Array(env.BlobKeys), env.RequiresApproval, env.CacheTTLSeconds, env.RetentionVersions, env.RetentionDays, env.GoldenEnvironment, env.GoldenStrict).Scan(
+			&env.CreatedAt,
+			&env.UpdatedAt,
+		)
+		if err != nil {
+			err = fmt.Errorf("failed to create environment: %w", err)
+		} else if i < len(configs) && configs[i] != nil {
+			cv := configs[i]
+			if cv.ID == uuid.Nil {
+				cv.ID = uuid.New()
+			}
+			if cv.Version == 0 {
+				cv.Version = 1
+			}
+			cv.EnvID = env.ID
+
+			if scanErr := tx.QueryRow(configQuery, cv.ID, cv.EnvID, cv.Version, cv.ConfigJSON, cv.IsActive, cv.CreatedBy).Scan(&cv.CreatedAt); scanErr != nil {
+				err = fmt.Errorf("failed to create config version: %w", scanErr)
+			} else if _, execErr := tx.Exec(changeQuery, uuid.New(), cv.EnvID, cv.Version, cv.CreatedBy); execErr != nil {
+				err = fmt.Errorf("failed to create config change: %w", execErr)
+			}
+		}
+
+		if err != nil {
+			errs[i] = err
+			if !continueOnError {
+				return errs, err
+			}
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT create_environment"); err != nil {
+				return errs, fmt.Errorf("failed to roll back to savepoint: %w", err)
+			}
+			continue
+		}
+
+		if _, err := tx.Exec("RELEASE SAVEPOINT create_environment"); err != nil {
+			return errs, fmt.Errorf("failed to release savepoint: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errs, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return errs, nil
+}
+
 // Update updates an existing environment
 func (r *EnvironmentRepository) Update(env *models.Environment) error {
 	query := `
 		UPDATE environments
-		SET name = $2, slug = $3
+		SET name = $2, slug = $3, secret_keys = $4, blob_keys = $5, requires_approval = $6, cache_ttl_seconds = $7, retention_versions = $8, retention_days = $9, golden_environment = $10, golden_strict = $11
 		WHERE id = $1
 		RETURNING updated_at
 	`
 
-	err := r.db.QueryRow(query, env.ID, env.Name, env.Slug).Scan(&env.UpdatedAt)
+	if env.SecretKeys == nil {
+		env.SecretKeys = []string{}
+	}
+	if env.BlobKeys == nil {
+		env.BlobKeys = []string{}
+	}
+
+	err := r.db.QueryRow(query, env.ID, env.Name, env.Slug, pq.Array(env.SecretKeys), pq.Array(env.BlobKeys), env.RequiresApproval, env.CacheTTLSeconds, env.RetentionVersions, env.RetentionDays, env.GoldenEnvironment, env.GoldenStrict).Scan(&env.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("environment not found: %s", env.ID)
+			return notFoundError("environment not found: %s", env.ID)
 		}
 		return fmt.Errorf("failed to update environment: %w", err)
 	}
@@ -187,6 +484,41 @@ func (r *EnvironmentRepository) Update(env *models.Environment) error {
 	return nil
 }
 
+// SetLock locks or unlocks an environment. The reason and locker are recorded with a lock
+// and cleared with it. Locking a locked environment or unlocking an unlocked one fails
+// with an "already" error, so that concurrent requests cannot overwrite each other.
+func (r *EnvironmentRepository) SetLock(env *models.Environment, locked bool, reason, lockedBy *string) error {
+	query := `
+		UPDATE environments
+		SET locked = $2,
+		    lock_reason = $3,
+		    locked_by = $4,
+		    locked_at = CASE WHEN $2 THEN NOW() END
+		WHERE id = $1 AND locked <> $2
+		RETURNING locked_at, updated_at
+	`
+
+	if !locked {
+		reason, lockedBy = nil, nil
+	}
+
+	err := r.db.QueryRow(query, env.ID, locked, reason, lockedBy).Scan(&env.LockedAt, &env.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			if locked {
+				return fmt.Errorf("environment already locked: %s", env.Slug)
+			}
+			return fmt.Errorf("environment already unlocked: %s", env.Slug)
+		}
+		return fmt.Errorf("failed to lock environment: %w", err)
+	}
+
+	env.Locked = locked
+	env.LockReason = reason
+	env.LockedBy = lockedBy
+	return nil
+}
+
 // Delete deletes an environment
 func (r *EnvironmentRepository) Delete(id uuid.UUID) error {
 	query := "DELETE FROM environments WHERE id = $1"
@@ -202,7 +534,7 @@ func (r *EnvironmentRepository) Delete(id uuid.UUID) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("environment not found: %s", id)
+		return notFoundError("environment not found: %s", id)
 	}
 
 	return nil
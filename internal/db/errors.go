@@ -0,0 +1,48 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors that callers match with errors.Is. The repositories' errors keep their
+// descriptive messages.
+var (
+	// ErrNotFound is matched by errors about a record that does not exist
+	ErrNotFound = errors.New("record not found")
+	// ErrVersionConflict is matched by errors about a configuration version that a
+	// concurrent write numbered or activated first
+	ErrVersionConflict = errors.New("version conflict")
+	// ErrAlreadyActive is matched by errors about activating the configuration version
+	// that is already active, e.g. after a concurrent rollback to it
+	ErrAlreadyActive = errors.New("version already active")
+)
+
+// kindError tags an error with one of the sentinel errors without changing its message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// notFoundError formats an error that matches ErrNotFound
+func notFoundError(format string, args ...interface{}) error {
+	return &kindError{kind: ErrNotFound, err: fmt.Errorf(format, args...)}
+}
+
+// alreadyActiveError formats an error that matches ErrAlreadyActive
+func alreadyActiveError(format string, args ...interface{}) error {
+	return &kindError{kind: ErrAlreadyActive, err: fmt.Errorf(format, args...)}
+}
+
+// versionConflictError formats an error that matches ErrVersionConflict
+func versionConflictError(format string, args ...interface{}) error {
+	return &kindError{kind: ErrVersionConflict, err: fmt.Errorf(format, args...)}
+}
@@ -1,32 +1,50 @@
 package db
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 )
 
-// Migration represents a database migration
+// Migration represents a database migration. Migrations are either a single
+// forward-only NNN_name.sql file or a pair of NNN_name.up.sql and NNN_name.down.sql files.
 type Migration struct {
-	Version  string
-	Filename string
-	SQL      string
+	Version      string
+	Filename     string
+	SQL          string
+	Checksum     string
+	DownFilename string
+	DownSQL      string
+}
+
+// HasDown reports whether the migration can be rolled back
+func (m Migration) HasDown() bool {
+	return m.DownFilename != ""
 }
 
 // MigrationRunner handles database migrations
 type MigrationRunner struct {
 	db            *DB
 	migrationsDir string
+	// allowChecksumMismatch accepts edits to applied migrations instead of failing
+	allowChecksumMismatch bool
 }
 
-// NewMigrationRunner creates a new migration runner
+// NewMigrationRunner creates a new migration runner. Setting
+// MIGRATIONS_ALLOW_CHECKSUM_MISMATCH=true accepts intentional edits to applied migrations.
 func NewMigrationRunner(db *DB, migrationsDir string) *MigrationRunner {
+	allowChecksumMismatch, _ := strconv.ParseBool(os.Getenv("MIGRATIONS_ALLOW_CHECKSUM_MISMATCH"))
+
 	return &MigrationRunner{
-		db:            db,
-		migrationsDir: migrationsDir,
+		db:                    db,
+		migrationsDir:         migrationsDir,
+		allowChecksumMismatch: allowChecksumMismatch,
 	}
 }
 
@@ -35,8 +53,10 @@ func (mr *MigrationRunner) CreateMigrationsTable() error {
 	query := `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
 			version VARCHAR(255) PRIMARY KEY,
-			applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+			applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+			checksum VARCHAR(64)
 		);
+		ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum VARCHAR(64);
 	`
 	
 	_, err := mr.db.Exec(query)
@@ -68,6 +88,28 @@ func (mr *MigrationRunner) GetAppliedMigrations() (map[string]bool, error) {
 	return applied, nil
 }
 
+// GetAppliedChecksums returns the checksums of applied migrations by version. Migrations
+// applied before checksums were recorded have an empty checksum.
+func (mr *MigrationRunner) GetAppliedChecksums() (map[string]string, error) {
+	query := "SELECT version, COALESCE(checksum, '') FROM schema_migrations"
+	rows, err := mr.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	checksums := make(map[string]string)
+	for rows.Next() {
+		var version, checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, fmt.Errorf("failed to scan migration checksum: %w", err)
+		}
+		checksums[version] = checksum
+	}
+
+	return checksums, rows.Err()
+}
+
 // LoadMigrations loads all migration files from the migrations directory
 func (mr *MigrationRunner) LoadMigrations() ([]Migration, error) {
 	files, err := os.ReadDir(mr.migrationsDir)
@@ -76,6 +118,7 @@ func (mr *MigrationRunner) LoadMigrations() ([]Migration, error) {
 	}
 
 	var migrations []Migration
+	downs := make(map[string]Migration)
 	for _, file := range files {
 		if !strings.HasSuffix(file.Name(), ".sql") {
 			continue
@@ -91,13 +134,35 @@ func (mr *MigrationRunner) LoadMigrations() ([]Migration, error) {
 			return nil, fmt.Errorf("failed to read migration file %s: %w", file.Name(), err)
 		}
 
+		// Down scripts are attached to their up migration below
+		if strings.HasSuffix(file.Name(), ".down.sql") {
+			downs[version] = Migration{
+				Version:      version,
+				DownFilename: file.Name(),
+				DownSQL:      string(sqlBytes),
+			}
+			continue
+		}
+
 		migrations = append(migrations, Migration{
 			Version:  version,
 			Filename: file.Name(),
 			SQL:      string(sqlBytes),
+			Checksum: checksumSQL(sqlBytes),
 		})
 	}
 
+	for i := range migrations {
+		if down, ok := downs[migrations[i].Version]; ok {
+			migrations[i].DownFilename = down.DownFilename
+			migrations[i].DownSQL = down.DownSQL
+			delete(downs, migrations[i].Version)
+		}
+	}
+	for _, down := range downs {
+		return nil, fmt.Errorf("down migration %s has no matching up migration", down.DownFilename)
+	}
+
 	// Sort migrations by version
 	sort.Slice(migrations, func(i, j int) bool {
 		return migrations[i].Version < migrations[j].Version
@@ -125,6 +190,11 @@ func (mr *MigrationRunner) RunMigrations() error {
 		return err
 	}
 
+	// Refuse to build on applied migrations whose files have since been edited
+	if err := mr.VerifyChecksums(migrations); err != nil {
+		return err
+	}
+
 	// Run pending migrations
 	for _, migration := range migrations {
 		if applied[migration.Version] {
@@ -147,7 +217,7 @@ func (mr *MigrationRunner) RunMigrations() error {
 		}
 
 		// Record migration as applied
-		if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", migration.Version); err != nil {
+		if _, err := tx.Exec("INSERT INTO schema_migrations (version, checksum) VALUES ($1, $2)", migration.Version, migration.Checksum); err != nil {
 			tx.Rollback()
 			return fmt.Errorf("failed to record migration %s: %w", migration.Filename, err)
 		}
@@ -163,6 +233,156 @@ func (mr *MigrationRunner) RunMigrations() error {
 	return nil
 }
 
+// VerifyChecksums checks that the files of applied migrations have not changed since they
+// were applied. Checksums missing from migrations applied before they were recorded are
+// filled in. Mismatches are an error unless MIGRATIONS_ALLOW_CHECKSUM_MISMATCH is set, in
+// which case the new checksums are recorded so that the edit is accepted from then on.
+func (mr *MigrationRunner) VerifyChecksums(migrations []Migration) error {
+	checksums, err := mr.GetAppliedChecksums()
+	if err != nil {
+		return err
+	}
+
+	updates, err := mr.checkChecksums(migrations, checksums)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range updates {
+		if _, err := mr.db.Exec("UPDATE schema_migrations SET checksum = $2 WHERE version = $1", migration.Version, migration.Checksum); err != nil {
+			return fmt.Errorf("failed to record checksum of migration %s: %w", migration.Filename, err)
+		}
+	}
+
+	return nil
+}
+
+// checkChecksums compares migrations with the checksums recorded when they were applied.
+// It returns the migrations whose recorded checksum needs to be updated.
+func (mr *MigrationRunner) checkChecksums(migrations []Migration, checksums map[string]string) ([]Migration, error) {
+	var updates []Migration
+	var mismatched []string
+	for _, migration := range migrations {
+		checksum, applied := checksums[migration.Version]
+		if !applied || checksum == migration.Checksum {
+			continue
+		}
+
+		switch {
+		case checksum == "":
+			updates = append(updates, migration)
+		case mr.allowChecksumMismatch:
+			log.Printf("WARNING: migration %s was modified after it was applied; accepting the change", migration.Filename)
+			updates = append(updates, migration)
+		default:
+			mismatched = append(mismatched, migration.Filename)
+		}
+	}
+
+	if len(mismatched) > 0 {
+		return nil, fmt.Errorf("applied migrations were modified: %s (restore the files, or set MIGRATIONS_ALLOW_CHECKSUM_MISMATCH=true to accept the changes)", strings.Join(mismatched, ", "))
+	}
+
+	return updates, nil
+}
+
+// checksumSQL returns the hex-encoded SHA-256 checksum of a migration script
+func checksumSQL(sql []byte) string {
+	sum := sha256.Sum256(sql)
+	return hex.EncodeToString(sum[:])
+}
+
+// RollbackMigration reverts the most recently applied migrations, up to steps of them,
+// by executing their down scripts in reverse order. Each migration is reverted in its own
+// transaction together with the removal of its schema_migrations row. Nothing is reverted
+// if any of the migrations has no down script.
+func (mr *MigrationRunner) RollbackMigration(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("invalid number of steps: %d", steps)
+	}
+
+	// Create migrations table if it doesn't exist
+	if err := mr.CreateMigrationsTable(); err != nil {
+		return err
+	}
+
+	// Get applied migrations
+	applied, err := mr.GetAppliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	// Load all migrations
+	migrations, err := mr.LoadMigrations()
+	if err != nil {
+		return err
+	}
+
+	byVersion := make(map[string]Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	versions := make([]string, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+
+	if steps > len(versions) {
+		steps = len(versions)
+	}
+
+	// Check every migration before reverting any of them
+	var rollbacks []Migration
+	for _, version := range versions[:steps] {
+		migration, ok := byVersion[version]
+		if !ok {
+			return fmt.Errorf("applied migration %s not found in %s", version, mr.migrationsDir)
+		}
+		if !migration.HasDown() {
+			return fmt.Errorf("migration %s has no down script", migration.Filename)
+		}
+		rollbacks = append(rollbacks, migration)
+	}
+
+	if len(rollbacks) == 0 {
+		log.Println("No applied migrations to roll back")
+		return nil
+	}
+
+	for _, migration := range rollbacks {
+		log.Printf("Rolling back migration %s", migration.Filename)
+
+		// Start transaction
+		tx, err := mr.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to start transaction for rollback of %s: %w", migration.Filename, err)
+		}
+
+		// Execute down SQL
+		if _, err := tx.Exec(migration.DownSQL); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to execute down migration %s: %w", migration.DownFilename, err)
+		}
+
+		// Record migration as no longer applied
+		if _, err := tx.Exec("DELETE FROM schema_migrations WHERE version = $1", migration.Version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to unrecord migration %s: %w", migration.Filename, err)
+		}
+
+		// Commit transaction
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit rollback of %s: %w", migration.Filename, err)
+		}
+
+		log.Printf("Successfully rolled back migration %s", migration.Filename)
+	}
+
+	return nil
+}
+
 // GetMigrationStatus returns the status of all migrations
 func (mr *MigrationRunner) GetMigrationStatus() ([]map[string]interface{}, error) {
 	applied, err := mr.GetAppliedMigrations()
@@ -181,6 +401,7 @@ func (mr *MigrationRunner) GetMigrationStatus() ([]map[string]interface{}, error
 			"version":  migration.Version,
 			"filename": migration.Filename,
 			"applied":  applied[migration.Version],
+			"down":     migration.HasDown(),
 		})
 	}
 
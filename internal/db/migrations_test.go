@@ -0,0 +1,95 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeMigration(t *testing.T, dir, name, sql string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(sql), 0o644))
+}
+
+func TestMigrationRunner_LoadMigrations(t *testing.T) {
+	dir := t.TempDir()
+	writeMigration(t, dir, "001_initial.sql", "CREATE TABLE a (id INT);")
+	writeMigration(t, dir, "002_b.up.sql", "CREATE TABLE b (id INT);")
+	writeMigration(t, dir, "002_b.down.sql", "DROP TABLE b;")
+
+	mr := NewMigrationRunner(nil, dir)
+	migrations, err := mr.LoadMigrations()
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	assert.Equal(t, "001", migrations[0].Version)
+	assert.False(t, migrations[0].HasDown())
+	assert.Equal(t, checksumSQL([]byte("CREATE TABLE a (id INT);")), migrations[0].Checksum)
+
+	assert.Equal(t, "002_b.up.sql", migrations[1].Filename)
+	assert.Equal(t, "DROP TABLE b;", migrations[1].DownSQL)
+
+	t.Run("down without up", func(t *testing.T) {
+		writeMigration(t, dir, "003_c.down.sql", "DROP TABLE c;")
+		_, err := mr.LoadMigrations()
+		assert.ErrorContains(t, err, "003_c.down.sql has no matching up migration")
+	})
+}
+
+func TestMigrationRunner_CheckChecksums(t *testing.T) {
+	dir := t.TempDir()
+	writeMigration(t, dir, "001_initial.sql", "CREATE TABLE a (id INT);")
+	writeMigration(t, dir, "002_b.up.sql", "CREATE TABLE b (id INT);")
+	writeMigration(t, dir, "003_c.up.sql", "CREATE TABLE c (id INT);")
+
+	original, err := NewMigrationRunner(nil, dir).LoadMigrations()
+	require.NoError(t, err)
+
+	// 001 and 002 are applied, 001 before checksums were recorded; 003 is pending
+	applied := map[string]string{
+		"001": "",
+		"002": original[1].Checksum,
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		mr := &MigrationRunner{migrationsDir: dir}
+		updates, err := mr.checkChecksums(original, applied)
+		require.NoError(t, err)
+		require.Len(t, updates, 1)
+		assert.Equal(t, "001", updates[0].Version)
+	})
+
+	// Tamper with the applied migration
+	writeMigration(t, dir, "002_b.up.sql", "CREATE TABLE b (id INT, name TEXT);")
+	tampered, err := NewMigrationRunner(nil, dir).LoadMigrations()
+	require.NoError(t, err)
+	require.NotEqual(t, original[1].Checksum, tampered[1].Checksum)
+
+	t.Run("tampered", func(t *testing.T) {
+		mr := &MigrationRunner{migrationsDir: dir}
+		_, err := mr.checkChecksums(tampered, applied)
+		assert.ErrorContains(t, err, "applied migrations were modified: 002_b.up.sql")
+	})
+
+	t.Run("tampered with escape hatch", func(t *testing.T) {
+		t.Setenv("MIGRATIONS_ALLOW_CHECKSUM_MISMATCH", "true")
+		mr := NewMigrationRunner(nil, dir)
+		updates, err := mr.checkChecksums(tampered, applied)
+		require.NoError(t, err)
+		require.Len(t, updates, 2)
+		assert.Equal(t, tampered[1].Checksum, updates[1].Checksum)
+	})
+
+	t.Run("pending migrations are not checked", func(t *testing.T) {
+		writeMigration(t, dir, "003_c.up.sql", "CREATE TABLE c (id BIGINT);")
+		pending, err := NewMigrationRunner(nil, dir).LoadMigrations()
+		require.NoError(t, err)
+
+		mr := &MigrationRunner{migrationsDir: dir}
+		_, err = mr.checkChecksums(pending, map[string]string{"001": original[0].Checksum})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// NotificationRepository handles database operations for environment notification settings
+type NotificationRepository struct {
+	db *DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// GetByEnvironment retrieves the notification settings of an environment
+func (r *NotificationRepository) GetByEnvironment(envID uuid.UUID) (*models.NotificationSettings, error) {
+	query := `
+		SELECT env_id, COALESCE(slack_webhook_url, ''), email_recipients, events, created_at, updated_at
+		FROM environment_notifications
+		WHERE env_id = $1
+	`
+
+	var settings models.NotificationSettings
+	err := r.db.QueryRow(query, envID).Scan(
+		&settings.EnvID, &settings.SlackWebhookURL, pq.Array(&settings.EmailRecipients),
+		pq.Array(&settings.Events), &settings.CreatedAt, &settings.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("notification settings not found for environment: %s", envID)
+		}
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// Upsert creates or replaces the notification settings of an environment
+func (r *NotificationRepository) Upsert(settings *models.NotificationSettings) error {
+	query := `
+		INSERT INTO environment_notifications (env_id, slack_webhook_url, email_recipients, events)
+		VALUES ($1, NULLIF($2, ''), $3, $4)
+		ON CONFLICT (env_id) DO UPDATE SET
+			slack_webhook_url = EXCLUDED.slack_webhook_url,
+			email_recipients = EXCLUDED.email_recipients,
+			events = EXCLUDED.events
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, settings.EnvID, settings.SlackWebhookURL, pq.Array(settings.EmailRecipients), pq.Array(settings.Events)).Scan(
+		&settings.CreatedAt,
+		&settings.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save notification settings: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the notification settings of an environment. It reports whether there
+// were any.
+func (r *NotificationRepository) Delete(envID uuid.UUID) (bool, error) {
+	query := "DELETE FROM environment_notifications WHERE env_id = $1"
+
+	result, err := r.db.Exec(query, envID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete notification settings: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
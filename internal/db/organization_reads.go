@@ -0,0 +1,76 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationReadsRepository handles database operations for the monthly configuration
+// reads of organizations
+type OrganizationReadsRepository struct {
+	db *DB
+}
+
+// NewOrganizationReadsRepository creates a new organization reads repository
+func NewOrganizationReadsRepository(db *DB) *OrganizationReadsRepository {
+	return &OrganizationReadsRepository{db: db}
+}
+
+// Store stores the running totals of organizations' monthly reads, in a single
+// transaction. A stored total is never lowered, so totals counted again after Redis lost
+// its counters do not overwrite the higher stored ones. Totals of organizations that no
+// longer exist are dropped.
+func (r *OrganizationReadsRepository) Store(reads []models.OrganizationReads) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO organization_read_usage (org_id, month, read_count, updated_at)
+		SELECT id, $2, $3, NOW()
+		FROM organizations
+		WHERE slug = $1
+		ON CONFLICT (org_id, month) DO UPDATE
+		SET read_count = GREATEST(organization_read_usage.read_count, EXCLUDED.read_count),
+		    updated_at = EXCLUDED.updated_at
+	`
+
+	for _, count := range reads {
+		if _, err := tx.Exec(query, count.Organization, count.Month, count.Reads); err != nil {
+			return fmt.Errorf("failed to store organization reads: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves the stored reads of an organization in a month, 0 if none are stored
+func (r *OrganizationReadsRepository) Get(orgID uuid.UUID, month time.Time) (int64, error) {
+	query := `
+		SELECT read_count
+		FROM organization_read_usage
+		WHERE org_id = $1 AND month = $2
+	`
+
+	var reads int64
+	err := r.db.QueryRow(query, orgID, month).Scan(&reads)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get organization reads: %w", err)
+	}
+
+	return reads, nil
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"remote-config-system/internal/models"
 
@@ -38,7 +39,7 @@ func (r *OrganizationRepository) GetBySlug(slug string) (*models.Organization, e
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("organization not found: %s", slug)
+			return nil, notFoundError("organization not found: %s", slug)
 		}
 		return nil, fmt.Errorf("failed to get organization: %w", err)
 	}
@@ -65,7 +66,7 @@ func (r *OrganizationRepository) GetByID(id uuid.UUID) (*models.Organization, er
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("organization not found: %s", id)
+			return nil, notFoundError("organization not found: %s", id)
 		}
 		return nil, fmt.Errorf("failed to get organization: %w", err)
 	}
@@ -73,6 +74,68 @@ func (r *OrganizationRepository) GetByID(id uuid.UUID) (*models.Organization, er
 	return &org, nil
 }
 
+// GetWithApplications retrieves an organization by its slug along with its applications,
+// ordered by name, in a single query
+func (r *OrganizationRepository) GetWithApplications(slug string) (*models.OrganizationWithApplications, error) {
+	query := `
+		SELECT o.id, o.name, o.slug, o.created_at, o.updated_at,
+		       a.id, a.org_id, a.name, a.slug, a.api_key_prefix, COALESCE(a.default_env, ''), a.created_at, a.updated_at
+		FROM organizations o
+		LEFT JOIN applications a ON a.org_id = o.id
+		WHERE o.slug = $1
+		ORDER BY a.name
+	`
+
+	rows, err := r.db.Query(query, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	defer rows.Close()
+
+	var org *models.OrganizationWithApplications
+	for rows.Next() {
+		var o models.Organization
+		// Application columns are NULL for an organization without applications
+		var appID, appOrgID *uuid.UUID
+		var appName, appSlug, appAPIKeyPrefix, appDefaultEnv *string
+		var appCreatedAt, appUpdatedAt *time.Time
+
+		err := rows.Scan(
+			&o.ID, &o.Name, &o.Slug, &o.CreatedAt, &o.UpdatedAt,
+			&appID, &appOrgID, &appName, &appSlug, &appAPIKeyPrefix, &appDefaultEnv, &appCreatedAt, &appUpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+
+		if org == nil {
+			org = &models.OrganizationWithApplications{Organization: o, Applications: []models.Application{}}
+		}
+		if appID != nil {
+			org.Applications = append(org.Applications, models.Application{
+				ID:           *appID,
+				OrgID:        *appOrgID,
+				Name:         *appName,
+				Slug:         *appSlug,
+				APIKeyPrefix: *appAPIKeyPrefix,
+				DefaultEnv:   *appDefaultEnv,
+				CreatedAt:    *appCreatedAt,
+				UpdatedAt:    *appUpdatedAt,
+			})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating organization applications: %w", err)
+	}
+
+	if org == nil {
+		return nil, notFoundError("organization not found: %s", slug)
+	}
+
+	return org, nil
+}
+
 // List retrieves all organizations with pagination
 func (r *OrganizationRepository) List(params models.PaginationParams) ([]models.Organization, int, error) {
 	// Get total count
@@ -156,7 +219,7 @@ func (r *OrganizationRepository) Update(org *models.Organization) error {
 	err := r.db.QueryRow(query, org.ID, org.Name).Scan(&org.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return fmt.Errorf("organization not found: %s", org.ID)
+			return notFoundError("organization not found: %s", org.ID)
 		}
 		return fmt.Errorf("failed to update organization: %w", err)
 	}
@@ -179,7 +242,7 @@ func (r *OrganizationRepository) Delete(id uuid.UUID) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("organization not found: %s", id)
+		return notFoundError("organization not found: %s", id)
 	}
 
 	return nil
@@ -0,0 +1,231 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// PendingChangeRepository handles database operations for configuration changes awaiting approval
+type PendingChangeRepository struct {
+	db *DB
+}
+
+// NewPendingChangeRepository creates a new pending change repository
+func NewPendingChangeRepository(db *DB) *PendingChangeRepository {
+	return &PendingChangeRepository{db: db}
+}
+
+// GetByID retrieves a pending change of an environment by its ID
+func (r *PendingChangeRepository) GetByID(envID, id uuid.UUID) (*models.PendingChange, error) {
+	query := `
+		SELECT id, env_id, version, base_version, status, proposed_by, message, reviewed_by, review_comment, created_at, reviewed_at
+		FROM pending_changes
+		WHERE env_id = $1 AND id = $2
+	`
+
+	var pc models.PendingChange
+	err := r.db.QueryRow(query, envID, id).Scan(
+		&pc.ID, &pc.EnvID, &pc.Version, &pc.BaseVersion, &pc.Status,
+		&pc.ProposedBy, &pc.Message, &pc.ReviewedBy, &pc.ReviewComment, &pc.CreatedAt, &pc.ReviewedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("pending change not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get pending change: %w", err)
+	}
+
+	return &pc, nil
+}
+
+// GetByVersion retrieves the pending change that proposed a configuration version
+func (r *PendingChangeRepository) GetByVersion(envID uuid.UUID, version int) (*models.PendingChange, error) {
+	query := `
+		SELECT id, env_id, version, base_version, status, proposed_by, message, reviewed_by, review_comment, created_at, reviewed_at
+		FROM pending_changes
+		WHERE env_id = $1 AND version = $2
+	`
+
+	var pc models.PendingChange
+	err := r.db.QueryRow(query, envID, version).Scan(
+		&pc.ID, &pc.EnvID, &pc.Version, &pc.BaseVersion, &pc.Status,
+		&pc.ProposedBy, &pc.Message, &pc.ReviewedBy, &pc.ReviewComment, &pc.CreatedAt, &pc.ReviewedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("pending change not found: env=%s, version=%d", envID, version)
+		}
+		return nil, fmt.Errorf("failed to get pending change: %w", err)
+	}
+
+	return &pc, nil
+}
+
+// ListByEnvironment retrieves the changes of an environment with the given status, newest first.
+// An empty status lists changes of every status.
+func (r *PendingChangeRepository) ListByEnvironment(envID uuid.UUID, status string, params models.PaginationParams) ([]models.PendingChange, int, error) {
+	// Get total count
+	countQuery := "SELECT COUNT(*) FROM pending_changes WHERE env_id = $1 AND ($2::text = '' OR status = $2::text)"
+	var totalCount int
+	err := r.db.QueryRow(countQuery, envID, status).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get pending changes count: %w", err)
+	}
+
+	// Get paginated results
+	query := `
+		SELECT id, env_id, version, base_version, status, proposed_by, message, reviewed_by, review_comment, created_at, reviewed_at
+		FROM pending_changes
+		WHERE env_id = $1 AND ($2::text = '' OR status = $2::text)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.Query(query, envID, status, params.PageSize, params.Offset())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list pending changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []models.PendingChange{}
+	for rows.Next() {
+		var pc models.PendingChange
+		err := rows.Scan(
+			&pc.ID, &pc.EnvID, &pc.Version, &pc.BaseVersion, &pc.Status,
+			&pc.ProposedBy, &pc.Message, &pc.ReviewedBy, &pc.ReviewComment, &pc.CreatedAt, &pc.ReviewedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan pending change: %w", err)
+		}
+		changes = append(changes, pc)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating pending changes: %w", err)
+	}
+
+	return changes, totalCount, nil
+}
+
+// Create creates a new pending change
+func (r *PendingChangeRepository) Create(pc *models.PendingChange) error {
+	query := `
+		INSERT INTO pending_changes (id, env_id, version, base_version, status, proposed_by, message)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
+	`
+
+	if pc.ID == uuid.Nil {
+		pc.ID = uuid.New()
+	}
+	if pc.Status == "" {
+		pc.Status = models.PendingStatusPending
+	}
+
+	err := r.db.QueryRow(query, pc.ID, pc.EnvID, pc.Version, pc.BaseVersion, pc.Status, pc.ProposedBy, pc.Message).Scan(&pc.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create pending change: %w", err)
+	}
+
+	return nil
+}
+
+// Review records the outcome of a review. Only changes that are still pending can be
+// reviewed, so concurrent approvals of the same change cannot both succeed.
+func (r *PendingChangeRepository) Review(pc *models.PendingChange) error {
+	query := `
+		UPDATE pending_changes
+		SET status = $2, reviewed_by = $3, review_comment = $4, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING reviewed_at
+	`
+
+	err := r.db.QueryRow(query, pc.ID, pc.Status, pc.ReviewedBy, pc.ReviewComment).Scan(&pc.ReviewedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("pending change already reviewed: %s", pc.ID)
+		}
+		return fmt.Errorf("failed to review pending change: %w", err)
+	}
+
+	return nil
+}
+
+// Approve records the approval of a pending change, activates its version and logs the
+// change in a single transaction, so that an approved change is always live. Like Review,
+// it fails if the change is no longer pending. change.VersionFrom is set to the version
+// that was active, if any, and change.CreatedAt to the time of activation.
+func (r *PendingChangeRepository) Approve(pc *models.PendingChange, change *models.ConfigChange) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		UPDATE pending_changes
+		SET status = $2, reviewed_by = $3, review_comment = $4, reviewed_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING reviewed_at
+	`, pc.ID, models.PendingStatusApproved, pc.ReviewedBy, pc.ReviewComment).Scan(&pc.ReviewedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("pending change already reviewed: %s", pc.ID)
+		}
+		return fmt.Errorf("failed to review pending change: %w", err)
+	}
+	pc.Status = models.PendingStatusApproved
+
+	var activeVersion int
+	err = tx.QueryRow("SELECT version FROM config_versions WHERE env_id = $1 AND is_active = TRUE FOR UPDATE", pc.EnvID).Scan(&activeVersion)
+	switch {
+	case err == nil:
+		change.VersionFrom = &activeVersion
+	case err != sql.ErrNoRows:
+		return fmt.Errorf("failed to get active configuration: %w", err)
+	}
+
+	if _, err := tx.Exec("UPDATE config_versions SET is_active = FALSE WHERE env_id = $1 AND is_active = TRUE", pc.EnvID); err != nil {
+		return fmt.Errorf("failed to deactivate config versions: %w", err)
+	}
+
+	result, err := tx.Exec("UPDATE config_versions SET is_active = TRUE WHERE env_id = $1 AND version = $2", pc.EnvID, pc.Version)
+	if err != nil {
+		return fmt.Errorf("failed to activate config version: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFoundError("configuration version not found: env=%s, version=%d", pc.EnvID, pc.Version)
+	}
+
+	query := `
+		INSERT INTO config_changes (id, env_id, version_from, version_to, action, created_by, approved_by, message, tag, scope)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at
+	`
+
+	if change.ID == uuid.Nil {
+		change.ID = uuid.New()
+	}
+	if change.Scope == "" {
+		change.Scope = models.ChangeScopeEnvironment
+	}
+
+	err = tx.QueryRow(query, change.ID, pc.EnvID, change.VersionFrom, pc.Version, change.Action, change.CreatedBy, change.ApprovedBy, change.Message, change.Tag, change.Scope).Scan(&change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create config change: %w", err)
+	}
+
+	return tx.Commit()
+}
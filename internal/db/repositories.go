@@ -7,6 +7,20 @@ type Repositories struct {
 	Environments   *EnvironmentRepository
 	ConfigVersions *ConfigVersionRepository
 	ConfigChanges  *ConfigChangeRepository
+	ConfigSchemas  *ConfigSchemaRepository
+	ConfigRules    *ConfigRuleRepository
+	AdminTokens    *AdminTokenRepository
+	PendingChanges *PendingChangeRepository
+	ConfigTags     *ConfigTagRepository
+	ConfigRollouts *ConfigRolloutRepository
+	AppDefaults    *ApplicationDefaultsRepository
+	Notifications  *NotificationRepository
+	Templates      *ConfigTemplateRepository
+	APIKeyUsage    *APIKeyUsageRepository
+	OrgReads       *OrganizationReadsRepository
+	Labels         *EnvironmentLabelRepository
+
+	db *DB
 }
 
 // NewRepositories creates a new repositories instance
@@ -17,5 +31,23 @@ func NewRepositories(db *DB) *Repositories {
 		Environments:   NewEnvironmentRepository(db),
 		ConfigVersions: NewConfigVersionRepository(db),
 		ConfigChanges:  NewConfigChangeRepository(db),
+		ConfigSchemas:  NewConfigSchemaRepository(db),
+		ConfigRules:    NewConfigRuleRepository(db),
+		AdminTokens:    NewAdminTokenRepository(db),
+		PendingChanges: NewPendingChangeRepository(db),
+		ConfigTags:     NewConfigTagRepository(db),
+		ConfigRollouts: NewConfigRolloutRepository(db),
+		AppDefaults:    NewApplicationDefaultsRepository(db),
+		Notifications:  NewNotificationRepository(db),
+		Templates:      NewConfigTemplateRepository(db),
+		APIKeyUsage:    NewAPIKeyUsageRepository(db),
+		OrgReads:       NewOrganizationReadsRepository(db),
+		Labels:         NewEnvironmentLabelRepository(db),
+		db:             db,
 	}
 }
+
+// PoolStats returns the statistics of the database connection pool
+func (r *Repositories) PoolStats() *PoolStats {
+	return r.db.PoolStats()
+}
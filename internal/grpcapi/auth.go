@@ -0,0 +1,108 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// contextKey is the type of values stored in the context of authenticated calls
+type contextKey string
+
+const applicationKey contextKey = "application"
+
+// AuthInterceptor authenticates gRPC calls with an application API key, accepted in the
+// same forms as by the HTTP API
+type AuthInterceptor struct {
+	configService services.ConfigServiceInterface
+}
+
+// NewAuthInterceptor creates a new auth interceptor
+func NewAuthInterceptor(configService services.ConfigServiceInterface) *AuthInterceptor {
+	return &AuthInterceptor{configService: configService}
+}
+
+// Unary returns the interceptor for unary calls
+func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the interceptor for streaming calls
+func (a *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(stream.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticate validates the API key of a call and stores its application in the context
+func (a *AuthInterceptor) authenticate(ctx context.Context) (context.Context, error) {
+	apiKey := apiKeyFromMetadata(ctx)
+	if apiKey == "" {
+		return nil, status.Error(codes.Unauthenticated, "API key is required")
+	}
+
+	app, err := a.configService.ValidateAPIKey(apiKey)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Invalid API key")
+	}
+
+	return context.WithValue(ctx, applicationKey, app), nil
+}
+
+// apiKeyFromMetadata reads the API key from the "authorization" metadata, with or without
+// a "Bearer " or "ApiKey " prefix, falling back to "x-api-key"
+func apiKeyFromMetadata(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if values := md.Get("authorization"); len(values) > 0 && values[0] != "" {
+		authHeader := values[0]
+		if strings.HasPrefix(authHeader, "Bearer ") {
+			return strings.TrimPrefix(authHeader, "Bearer ")
+		} else if strings.HasPrefix(authHeader, "ApiKey ") {
+			return strings.TrimPrefix(authHeader, "ApiKey ")
+		}
+		return authHeader
+	}
+
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// authenticatedStream carries the context of an authenticated streaming call
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// applicationFromContext returns the application of an authenticated call
+func applicationFromContext(ctx context.Context) (*models.Application, bool) {
+	app, ok := ctx.Value(applicationKey).(*models.Application)
+	return app, ok
+}
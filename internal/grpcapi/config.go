@@ -0,0 +1,25 @@
+package grpcapi
+
+import (
+	"os"
+	"strings"
+)
+
+// Config holds gRPC server configuration
+type Config struct {
+	Enabled bool
+	Port    string
+}
+
+// NewConfig creates a new gRPC configuration from environment variables
+func NewConfig() *Config {
+	port := os.Getenv("GRPC_PORT")
+	if port == "" {
+		port = "9090"
+	}
+
+	return &Config{
+		Enabled: !strings.EqualFold(os.Getenv("GRPC_ENABLED"), "false"),
+		Port:    port,
+	}
+}
@@ -0,0 +1,276 @@
+package grpcapi
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"remote-config-system/internal/grpcapi/configpb"
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/sse"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// watchPingInterval is how often watching clients are marked as alive in the SSE service
+const watchPingInterval = 30 * time.Second
+
+// ConfigServer implements the gRPC configuration service on top of the configuration
+// and SSE services used by the HTTP API
+type ConfigServer struct {
+	configpb.UnimplementedConfigServiceServer
+
+	configService services.ConfigServiceInterface
+	sseService    sse.SSEServiceInterface
+}
+
+// NewConfigServer creates a new gRPC configuration server
+func NewConfigServer(configService services.ConfigServiceInterface, sseService sse.SSEServiceInterface) *ConfigServer {
+	return &ConfigServer{
+		configService: configService,
+		sseService:    sseService,
+	}
+}
+
+// NewGRPCServer creates a gRPC server that serves the configuration service to clients
+// authenticated with an API key
+func NewGRPCServer(configService services.ConfigServiceInterface, sseService sse.SSEServiceInterface) *grpc.Server {
+	auth := NewAuthInterceptor(configService)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(auth.Unary()),
+		grpc.StreamInterceptor(auth.Stream()),
+	)
+	configpb.RegisterConfigServiceServer(server, NewConfigServer(configService, sseService))
+	return server
+}
+
+// GetConfig returns the active configuration of an environment of the authenticated application
+func (s *ConfigServer) GetConfig(ctx context.Context, req *configpb.GetConfigRequest) (*configpb.Config, error) {
+	app, ok := applicationFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "API key is required")
+	}
+
+	envSlug, err := environmentOrDefault(app, req.GetEnvironment())
+	if err != nil {
+		return nil, err
+	}
+	s.recordUsage(app, envSlug)
+	if err := s.checkReadQuota(app); err != nil {
+		return nil, err
+	}
+
+	config, err := s.configService.GetConfigurationByAPIKey(app, envSlug, req.GetClientId())
+	if err != nil {
+		return nil, configError(err)
+	}
+	s.recordRead(app)
+
+	return toProtoConfig(config.Organization, config.Application, config.Environment, config.Version, config.Config, config.UpdatedAt), nil
+}
+
+// WatchConfig streams the active configuration of an environment followed by its updates,
+// with the same events as the SSE stream
+func (s *ConfigServer) WatchConfig(req *configpb.WatchConfigRequest, stream configpb.ConfigService_WatchConfigServer) error {
+	app, ok := applicationFromContext(stream.Context())
+	if !ok {
+		return status.Error(codes.Unauthenticated, "API key is required")
+	}
+
+	envSlug, err := environmentOrDefault(app, req.GetEnvironment())
+	if err != nil {
+		return err
+	}
+	s.recordUsage(app, envSlug)
+	if err := s.checkReadQuota(app); err != nil {
+		return err
+	}
+
+	// Streams follow the active version, as rollouts are not broadcast
+	config, err := s.configService.GetConfigurationByAPIKey(app, envSlug, "")
+	if err != nil {
+		return configError(err)
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	// Subscribe before sending the initial configuration, so that no update is missed
+	client := &sse.Client{
+		ID:           uuid.New().String(),
+		Organization: app.Organization.Slug,
+		Application:  app.Slug,
+		Environment:  envSlug,
+		Channel:      s.sseService.NewClientChannel(),
+		Context:      ctx,
+		Cancel:       cancel,
+		ConnectedAt:  time.Now(),
+		LastPing:     time.Now(),
+	}
+
+	s.sseService.RegisterClient(client)
+	defer s.sseService.UnregisterClient(client)
+
+	// A watch counts as one read once it is open, like an SSE stream
+	s.recordRead(app)
+
+	// Send initial configuration
+	if err := stream.Send(&configpb.ConfigEvent{
+		Event:  "initial_config",
+		Action: "initial",
+		Config: toProtoConfig(config.Organization, config.Application, config.Environment, config.Version, config.Config, config.UpdatedAt),
+	}); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(watchPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case message, ok := <-client.Channel:
+			if !ok {
+				return nil
+			}
+
+			// Update last ping
+			s.sseService.Ping(client.ID)
+
+			// Broadcasts are public and mask secret values, so configuration updates are
+			// read again with the watcher's API key, like the initial configuration
+			if update, ok := message.Data.(models.ConfigUpdateEvent); ok {
+				event, err := s.configUpdateEvent(app, envSlug, message.Event, update.Action)
+				if err != nil {
+					return err
+				}
+				if err := stream.Send(event); err != nil {
+					return err
+				}
+				continue
+			}
+
+			event, err := toProtoEvent(message)
+			if err != nil {
+				log.Printf("Failed to convert %s event for gRPC client %s: %v", message.Event, client.ID, err)
+				continue
+			}
+
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+
+		case <-ticker.C:
+			// Keep the subscription from being reaped as stale
+			s.sseService.Ping(client.ID)
+		}
+	}
+}
+
+// configUpdateEvent builds the event of a configuration update for a watcher from its
+// active configuration, with secret values decrypted. Failing to read it ends the watch,
+// so that the client reconnects rather than miss the update.
+func (s *ConfigServer) configUpdateEvent(app *models.Application, envSlug, eventName, action string) (*configpb.ConfigEvent, error) {
+	config, err := s.configService.GetConfigurationByAPIKey(app, envSlug, "")
+	if err != nil {
+		return nil, configError(err)
+	}
+
+	return &configpb.ConfigEvent{
+		Event:  eventName,
+		Action: action,
+		Config: toProtoConfig(config.Organization, config.Application, config.Environment, config.Version, config.Config, config.UpdatedAt),
+	}, nil
+}
+
+// recordUsage counts a call towards the usage reports of the application's API key, like
+// requests of the HTTP API. Calls are counted before they are handled, as watches only
+// return once the client disconnects.
+func (s *ConfigServer) recordUsage(app *models.Application, envSlug string) {
+	if err := s.configService.RecordAPIKeyUsage(app, envSlug); err != nil {
+		log.Printf("Failed to record API key usage: %v", err)
+	}
+}
+
+// checkReadQuota rejects reads of an application whose organization has used up its
+// monthly read quota. Reads are let through when the quota cannot be checked.
+func (s *ConfigServer) checkReadQuota(app *models.Application) error {
+	err := s.configService.CheckOrganizationReadQuota(app.Organization.Slug)
+	if errors.Is(err, services.ErrQuotaExceeded) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err != nil {
+		log.Printf("Failed to check the read quota of organization %s: %v", app.Organization.Slug, err)
+	}
+	return nil
+}
+
+// recordRead counts a read towards the monthly read quota of the application's organization
+func (s *ConfigServer) recordRead(app *models.Application) {
+	if err := s.configService.RecordOrganizationRead(app.Organization.Slug); err != nil {
+		log.Printf("Failed to count a read of organization %s: %v", app.Organization.Slug, err)
+	}
+}
+
+// environmentOrDefault resolves an empty environment to the application's default environment
+func environmentOrDefault(app *models.Application, envSlug string) (string, error) {
+	if envSlug != "" {
+		return envSlug, nil
+	}
+	if app.DefaultEnv == "" {
+		return "", status.Error(codes.InvalidArgument, "environment is required: the application has no default environment")
+	}
+	return app.DefaultEnv, nil
+}
+
+// configError maps configuration service errors to gRPC status errors. Lost database
+// connections and timeouts are reported as unavailable, so that clients retry.
+func configError(err error) error {
+	if errors.Is(err, services.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if errors.Is(err, services.ErrBrokenReference) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// toProtoConfig converts a configuration to its protobuf message
+func toProtoConfig(orgSlug, appSlug, envSlug string, version int, config json.RawMessage, updatedAt time.Time) *configpb.Config {
+	return &configpb.Config{
+		Organization: orgSlug,
+		Application:  appSlug,
+		Environment:  envSlug,
+		Version:      int32(version),
+		Config:       string(config),
+		UpdatedAt:    timestamppb.New(updatedAt),
+	}
+}
+
+// toProtoEvent converts an SSE message other than a configuration update to a stream
+// event carrying its JSON payload
+func toProtoEvent(message models.SSEMessage) (*configpb.ConfigEvent, error) {
+	data, err := json.Marshal(message.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &configpb.ConfigEvent{
+		Event: message.Event,
+		Data:  string(data),
+	}, nil
+}
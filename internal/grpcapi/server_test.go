@@ -0,0 +1,234 @@
+package grpcapi
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/grpcapi/configpb"
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/sse"
+	"remote-config-system/internal/testutil"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startTestServer serves the gRPC API over an in-memory connection and returns a client
+func startTestServer(t *testing.T, mockService *testutil.MockConfigService, sseService sse.SSEServiceInterface) configpb.ConfigServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := NewGRPCServer(mockService, sseService)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return configpb.NewConfigServiceClient(conn)
+}
+
+func withAPIKey(ctx context.Context, apiKey string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "x-api-key", apiKey)
+}
+
+func TestConfigServer_GetConfig(t *testing.T) {
+	app := testutil.CreateTestApplication(uuid.New(), "Test App", "test-app", "test-api-key")
+
+	t.Run("missing API key", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		client := startTestServer(t, mockService, testutil.NewMockSSEService())
+
+		_, err := client.GetConfig(context.Background(), &configpb.GetConfigRequest{Environment: "prod"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("invalid API key", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ValidateAPIKey", "wrong-key").Return(nil, fmt.Errorf("invalid API key"))
+		client := startTestServer(t, mockService, testutil.NewMockSSEService())
+
+		_, err := client.GetConfig(withAPIKey(context.Background(), "wrong-key"), &configpb.GetConfigRequest{Environment: "prod"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("active configuration for a client", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ValidateAPIKey", "test-api-key").Return(app, nil)
+		mockService.On("RecordAPIKeyUsage", app, "prod").Return(nil).Once()
+		mockService.On("CheckOrganizationReadQuota", "test-org").Return(nil)
+		mockService.On("RecordOrganizationRead", "test-org").Return(nil).Once()
+		mockService.On("GetConfigurationByAPIKey", app, "prod", "device-42").
+			Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 3), nil)
+		client := startTestServer(t, mockService, testutil.NewMockSSEService())
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer test-api-key")
+		config, err := client.GetConfig(ctx, &configpb.GetConfigRequest{Environment: "prod", ClientId: "device-42"})
+		require.NoError(t, err)
+
+		assert.Equal(t, int32(3), config.Version)
+		var values map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(config.Config), &values))
+		assert.Equal(t, true, values["debug"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ValidateAPIKey", "test-api-key").Return(app, nil)
+		mockService.On("RecordAPIKeyUsage", app, "qa").Return(nil)
+		mockService.On("CheckOrganizationReadQuota", "test-org").Return(nil)
+		mockService.On("GetConfigurationByAPIKey", app, "qa", "").
+			Return(nil, fmt.Errorf("environment not found: qa: %w", services.ErrNotFound))
+		client := startTestServer(t, mockService, testutil.NewMockSSEService())
+
+		_, err := client.GetConfig(withAPIKey(context.Background(), "test-api-key"), &configpb.GetConfigRequest{Environment: "qa"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		mockService.AssertNotCalled(t, "RecordOrganizationRead", "test-org")
+	})
+
+	t.Run("read quota exceeded", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ValidateAPIKey", "test-api-key").Return(app, nil)
+		mockService.On("RecordAPIKeyUsage", app, "prod").Return(nil)
+		mockService.On("CheckOrganizationReadQuota", "test-org").
+			Return(&services.QuotaExceededError{Organization: "test-org", Quota: 100, ResetsAt: time.Now().Add(time.Hour)})
+		client := startTestServer(t, mockService, testutil.NewMockSSEService())
+
+		_, err := client.GetConfig(withAPIKey(context.Background(), "test-api-key"), &configpb.GetConfigRequest{Environment: "prod"})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Contains(t, status.Convert(err).Message(), "monthly read quota of 100 reads exceeded")
+		mockService.AssertNotCalled(t, "GetConfigurationByAPIKey", app, "prod", "")
+	})
+
+	t.Run("no environment and no default", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ValidateAPIKey", "test-api-key").Return(app, nil)
+		client := startTestServer(t, mockService, testutil.NewMockSSEService())
+
+		_, err := client.GetConfig(withAPIKey(context.Background(), "test-api-key"), &configpb.GetConfigRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestConfigServer_WatchConfig(t *testing.T) {
+	app := testutil.CreateTestApplication(uuid.New(), "Test App", "test-app", "test-api-key")
+
+	mockService := &testutil.MockConfigService{}
+	mockService.On("ValidateAPIKey", "test-api-key").Return(app, nil)
+	mockService.On("RecordAPIKeyUsage", app, "prod").Return(nil).Once()
+	mockService.On("CheckOrganizationReadQuota", "test-org").Return(nil)
+	mockService.On("RecordOrganizationRead", "test-org").Return(nil).Once()
+	mockService.On("GetConfigurationByAPIKey", app, "prod", "").
+		Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 1), nil).Once()
+	updated := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 2)
+	updated.Config = json.RawMessage(`{"debug":false,"db_password":"hunter2"}`)
+	mockService.On("GetConfigurationByAPIKey", app, "prod", "").Return(updated, nil).Once()
+
+	sseService := sse.NewSSEService()
+	client := startTestServer(t, mockService, sseService)
+
+	ctx, cancel := context.WithTimeout(withAPIKey(context.Background(), "test-api-key"), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.WatchConfig(ctx, &configpb.WatchConfigRequest{Environment: "prod"})
+	require.NoError(t, err)
+
+	// The active configuration comes first
+	initial, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "initial_config", initial.Event)
+	assert.Equal(t, int32(1), initial.Config.Version)
+
+	// Updates are pushed as they are broadcast, with the secret values the broadcast masks
+	sseService.BroadcastConfigUpdate(models.ConfigUpdateEvent{
+		Organization: "test-org",
+		Application:  "test-app",
+		Environment:  "prod",
+		Version:      2,
+		Config:       json.RawMessage(`{"debug":false,"db_password":"<encrypted>"}`),
+		Action:       "update",
+		UpdatedAt:    time.Now(),
+	})
+	sseService.BroadcastCustomEvent("test-org", "test-app", "prod", "tag_update", models.TagUpdateEvent{Tag: "stable", Version: 2})
+
+	var events []*configpb.ConfigEvent
+	for len(events) < 2 {
+		event, err := stream.Recv()
+		require.NoError(t, err)
+		if event.Event == "connected" {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	assert.Equal(t, "config_update", events[0].Event)
+	assert.Equal(t, "update", events[0].Action)
+	assert.Equal(t, int32(2), events[0].Config.Version)
+	assert.JSONEq(t, `{"debug":false,"db_password":"hunter2"}`, events[0].Config.Config)
+
+	assert.Equal(t, "tag_update", events[1].Event)
+	assert.Nil(t, events[1].Config)
+	assert.Contains(t, events[1].Data, `"tag":"stable"`)
+
+	// The watch counted as a single read however many events it received
+	mockService.AssertNumberOfCalls(t, "RecordOrganizationRead", 1)
+}
+
+func TestConfigServer_WatchConfigErrors(t *testing.T) {
+	app := testutil.CreateTestApplication(uuid.New(), "Test App", "test-app", "test-api-key")
+
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"unknown environment", fmt.Errorf("environment not found: qa: %w", services.ErrNotFound), codes.NotFound},
+		{"broken reference", fmt.Errorf("broken reference env://shared/db: %w", services.ErrBrokenReference), codes.FailedPrecondition},
+		{"lost database connection", fmt.Errorf("failed to get configuration: %w", driver.ErrBadConn), codes.Unavailable},
+		{"other errors", fmt.Errorf("failed to decrypt secret values: cipher: message authentication failed"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &testutil.MockConfigService{}
+			mockService.On("ValidateAPIKey", "test-api-key").Return(app, nil)
+			mockService.On("RecordAPIKeyUsage", app, "qa").Return(nil)
+			mockService.On("CheckOrganizationReadQuota", "test-org").Return(nil)
+			mockService.On("GetConfigurationByAPIKey", app, "qa", "").Return(nil, tt.err)
+			sseService := sse.NewSSEService()
+			client := startTestServer(t, mockService, sseService)
+
+			ctx, cancel := context.WithTimeout(withAPIKey(context.Background(), "test-api-key"), 5*time.Second)
+			defer cancel()
+
+			stream, err := client.WatchConfig(ctx, &configpb.WatchConfigRequest{Environment: "qa"})
+			require.NoError(t, err)
+			_, err = stream.Recv()
+			assert.Equal(t, tt.code, status.Code(err))
+			assert.Empty(t, sseService.GetClients(), "failed watches do not subscribe")
+			mockService.AssertNotCalled(t, "RecordOrganizationRead", "test-org")
+			mockService.AssertExpectations(t)
+		})
+	}
+}
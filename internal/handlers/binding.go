@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		// Request models validate slugs with the "slug" binding tag
+		v.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+			return models.ValidateSlug(fl.Field().String()) == nil
+		})
+		// Validation errors name fields as the client sends them
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+// fieldName returns the JSON or query parameter name of a struct field
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// bindingErrorResponse builds the error response for a request that failed to bind.
+// Each field that failed validation is listed with the constraint it violated.
+func bindingErrorResponse(c *gin.Context, code, prefix string, err error) models.ErrorResponse {
+	return models.ErrorResponse{
+		Error:     code,
+		Message:   prefix + bindingErrorMessage(err),
+		Timestamp: time.Now(),
+		Path:      c.Request.URL.Path,
+		Fields:    bindingFieldErrors(err),
+	}
+}
+
+// bindingErrorMessage describes a request binding error. Invalid fields are listed
+// with the reason they were rejected, instead of the raw validator output.
+func bindingErrorMessage(err error) string {
+	fields := bindingFieldErrors(err)
+	if len(fields) == 0 {
+		return err.Error()
+	}
+	return fieldErrorsMessage(fields)
+}
+
+// fieldErrorsMessage lists invalid fields as "page: must be an integer; ..."
+func fieldErrorsMessage(fields []models.FieldError) string {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// bindingFieldErrors lists the fields of a binding error, or nil when the error
+// is not tied to fields (e.g. malformed JSON)
+func bindingFieldErrors(err error) []models.FieldError {
+	var sliceErrs binding.SliceValidationError
+	if errors.As(err, &sliceErrs) {
+		var fields []models.FieldError
+		for _, elemErr := range sliceErrs {
+			fields = append(fields, bindingFieldErrors(elemErr)...)
+		}
+		return fields
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]models.FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			constraint := fieldErr.Tag()
+			if fieldErr.Param() != "" {
+				constraint += "=" + fieldErr.Param()
+			}
+			fields = append(fields, models.FieldError{
+				Field:      fieldPath(fieldErr.Namespace()),
+				Constraint: constraint,
+				Message:    constraintMessage(fieldErr),
+			})
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []models.FieldError{{
+			Field:      typeErr.Field,
+			Constraint: "type",
+			Message:    "must be " + typeName(typeErr.Type),
+		}}
+	}
+
+	return nil
+}
+
+// fieldPath strips the request type from a validator namespace, so
+// "BulkCreateEnvironmentsRequest.environments[1].CreateEnvironmentRequest.slug"
+// becomes "environments[1].slug". Embedded structs keep their Go name in the
+// namespace but are flattened in JSON, so capitalised segments are dropped.
+func fieldPath(namespace string) string {
+	segments := strings.Split(namespace, ".")
+	if len(segments) == 1 {
+		return namespace
+	}
+
+	path := make([]string, 0, len(segments)-1)
+	for i, segment := range segments[1:] {
+		last := i == len(segments)-2
+		if !last && segment != "" && unicode.IsUpper(rune(segment[0])) {
+			continue
+		}
+		path = append(path, segment)
+	}
+	return strings.Join(path, ".")
+}
+
+// constraintMessage explains a failed binding tag
+func constraintMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "slug":
+		slug := fmt.Sprint(fieldErr.Value())
+		if slugErr := models.ValidateSlug(slug); slugErr != nil {
+			return fmt.Sprintf("invalid slug %q: %v", slug, slugErr)
+		}
+		return "must be a valid slug"
+	case "min":
+		return "must " + sizeMessage(fieldErr.Kind(), "at least", param)
+	case "max":
+		return "must " + sizeMessage(fieldErr.Kind(), "at most", param)
+	}
+	if param != "" {
+		return fmt.Sprintf("must satisfy %s=%s", fieldErr.Tag(), param)
+	}
+	return "must satisfy " + fieldErr.Tag()
+}
+
+// sizeMessage words a min/max bound for the kind of value it applies to
+func sizeMessage(kind reflect.Kind, bound, param string) string {
+	switch kind {
+	case reflect.String:
+		return fmt.Sprintf("be %s %s %s long", bound, param, plural(param, "character"))
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("contain %s %s %s", bound, param, plural(param, "item"))
+	default:
+		return fmt.Sprintf("be %s %s", bound, param)
+	}
+}
+
+func plural(count, noun string) string {
+	if count == "1" {
+		return noun
+	}
+	return noun + "s"
+}
+
+// typeName describes a JSON type expected by a request field
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "a valid value"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return "a " + t.String()
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindingErrorResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	bind := func(body string, obj any) models.ErrorResponse {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		err := c.ShouldBindJSON(obj)
+		require.Error(t, err)
+		return bindingErrorResponse(c, "bad_request", "Invalid request body: ", err)
+	}
+
+	t.Run("validation errors", func(t *testing.T) {
+		response := bind(`{"name": "`+strings.Repeat("a", 101)+`", "slug": "Foo"}`, &models.CreateOrganizationRequest{})
+
+		assert.Equal(t, "bad_request", response.Error)
+		assert.Equal(t, "/test", response.Path)
+		require.Len(t, response.Fields, 2)
+		assert.Equal(t, models.FieldError{Field: "name", Constraint: "max=100", Message: "must be at most 100 characters long"}, response.Fields[0])
+		assert.Equal(t, "slug", response.Fields[1].Field)
+		assert.Equal(t, "slug", response.Fields[1].Constraint)
+		assert.Contains(t, response.Fields[1].Message, `invalid slug "Foo"`)
+		assert.Contains(t, response.Message, "Invalid request body: name: must be at most 100 characters long; slug: invalid slug")
+	})
+
+	t.Run("nested fields", func(t *testing.T) {
+		response := bind(`{"environments": [{"name": "Dev", "slug": "dev"}, {"slug": "qa"}]}`, &models.BulkCreateEnvironmentsRequest{})
+
+		assert.Equal(t, []models.FieldError{
+			{Field: "environments[1].name", Constraint: "required", Message: "is required"},
+		}, response.Fields)
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		response := bind(`{"name": 5, "slug": "foo"}`, &models.CreateOrganizationRequest{})
+
+		assert.Equal(t, []models.FieldError{
+			{Field: "name", Constraint: "type", Message: "must be a string"},
+		}, response.Fields)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		response := bind(`{"name":`, &models.CreateOrganizationRequest{})
+
+		assert.Empty(t, response.Fields)
+		assert.Contains(t, response.Message, "Invalid request body: ")
+	})
+}
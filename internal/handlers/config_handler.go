@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"remote-config-system/internal/cache"
 	"remote-config-system/internal/models"
 	"remote-config-system/internal/services"
 
@@ -29,10 +34,1557 @@ func (h *ConfigHandler) GetConfig(c *gin.Context) {
 	appSlug := c.Param("app")
 	envSlug := c.Param("env")
 
-	config, err := h.configService.GetConfiguration(orgSlug, appSlug, envSlug)
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+	meta, ok := parseMeta(c)
+	if !ok {
+		return
+	}
+
+	raw, ok := parseRaw(c)
+	if !ok {
+		return
+	}
+	at, ok := parseAt(c)
+	if !ok {
+		return
+	}
+
+	var config *models.ConfigResponse
+	var err error
+	if at != nil {
+		// The version that was active then, as stored, like a version of the history
+		config, err = h.configService.GetConfigurationAtTime(orgSlug, appSlug, envSlug, *at)
+	} else if tag := c.Query("tag"); tag != "" {
+		config, err = h.configService.GetConfigurationByTag(orgSlug, appSlug, envSlug, tag)
+	} else if raw {
+		// Only the environment's own overrides, without the application defaults
+		config, err = h.configService.GetRawConfiguration(orgSlug, appSlug, envSlug)
+	} else {
+		config, err = h.configService.GetConfiguration(orgSlug, appSlug, envSlug)
+	}
+	if err == nil {
+		// The endpoint is public, so secret values are never sent in plaintext
+		config, err = services.MaskSecretValues(config)
+	}
+	if err != nil {
+		status, code := configReadError(err)
+		c.JSON(status, models.ErrorResponse{
+			Error:     code,
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	if meta {
+		// The age changes with every request, so responses with metadata are not cached
+		c.Header("Cache-Control", "no-store")
+		if config, ok = h.addConfigMeta(c, config); !ok {
+			return
+		}
+	} else {
+		// Set cache headers and check if client has the latest version
+		c.Header("Cache-Control", "public, max-age=300") // 5 minutes
+		if notModified(c, configETag(config), config) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	if config, ok = applyFields(c, config); !ok {
+		return
+	}
+	renderConfig(c, config, format)
+}
+
+// DownloadConfig handles GET /admin/orgs/:org/apps/:app/envs/:env/config/download,
+// responding with the active configuration document as a file attachment named after the
+// environment and version, e.g. acme-web-prod-v3.json
+func (h *ConfigHandler) DownloadConfig(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+	raw, ok := parseRaw(c)
+	if !ok {
+		return
+	}
+
+	var config *models.ConfigResponse
+	var err error
+	if raw {
+		config, err = h.configService.GetRawConfiguration(orgSlug, appSlug, envSlug)
+	} else {
+		config, err = h.configService.GetConfiguration(orgSlug, appSlug, envSlug)
+	}
+	if err != nil {
+		status, code := configReadError(err)
+		c.JSON(status, models.ErrorResponse{
+			Error:     code,
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	data, err := encodeConfigDocument(config, format)
+	if err != nil {
+		c.JSON(http.StatusNotAcceptable, models.ErrorResponse{
+			Error:     "not_acceptable",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s-%s-v%d.%s", config.Organization, config.Application, config.Environment, config.Version, formatExtensions[format])
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	// Downloads may contain decrypted secret values
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, formatContentTypes[format], data)
+}
+
+// parseRaw parses the raw query parameter of configuration reads, which asks for the
+// environment's own keys only. It writes a 400 response and returns false if it is invalid.
+func parseRaw(c *gin.Context) (bool, bool) {
+	value := c.Query("raw")
+	if value == "" {
+		return false, true
+	}
+
+	raw, err := strconv.ParseBool(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "Invalid raw parameter: " + err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return false, false
+	}
+	return raw, true
+}
+
+// parseAt parses the optional ?at query parameter, an RFC 3339 timestamp to read the
+// configuration at. It cannot be combined with ?tag. Responds with 400 and returns false
+// when the parameter is invalid.
+func parseAt(c *gin.Context) (*time.Time, bool) {
+	value := c.Query("at")
+	if value == "" {
+		return nil, true
+	}
+
+	message := ""
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		message = "Invalid at parameter: expected an RFC 3339 timestamp, e.g. 2024-01-01T00:00:00Z"
+	} else if c.Query("tag") != "" {
+		message = "The at and tag parameters cannot be combined"
+	}
+	if message != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   message,
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return nil, false
+	}
+	return &at, true
+}
+
+// configReadError maps errors of configuration reads to an HTTP status code and error
+// code. A configuration whose references cannot be resolved cannot be served, and a
+// corrupt stored document is a server error. Failures that are not about a missing
+// configuration, such as a secret that cannot be decrypted or a lost database
+// connection, are not reported as not found.
+func configReadError(err error) (int, string) {
+	switch {
+	case errors.Is(err, services.ErrCorruptConfig):
+		return http.StatusInternalServerError, "corrupt_config"
+	case errors.Is(err, services.ErrBrokenReference):
+		return http.StatusUnprocessableEntity, "broken_reference"
+	case errors.Is(err, services.ErrNotFound):
+		return http.StatusNotFound, "not_found"
+	}
+
+	status := statusForError(err)
+	if status == http.StatusInternalServerError {
+		return status, "internal_error"
+	}
+	return status, "read_failed"
+}
+
+// configETag returns the entity tag of a configuration. Edits of the application defaults
+// and of referenced environments change the served configuration without changing its
+// version, so their versions are part of the tag.
+func configETag(config *models.ConfigResponse) string {
+	tag := strconv.Itoa(config.Version)
+	if config.DefaultsVersion > 0 {
+		tag += "-" + strconv.Itoa(config.DefaultsVersion)
+	}
+	for _, source := range config.References {
+		tag += "-" + source.Environment + "." + strconv.Itoa(source.Version)
+	}
+	return `"` + tag + `"`
+}
+
+// configLastModified returns the Last-Modified date of a configuration: the creation time
+// of its version, truncated to the second precision of HTTP dates. Configurations merged
+// with application defaults or resolved from references change without a new version, so
+// they have no date and are only validated by their ETag.
+func configLastModified(config *models.ConfigResponse) (time.Time, bool) {
+	if config.DefaultsVersion > 0 || len(config.References) > 0 || config.UpdatedAt.IsZero() {
+		return time.Time{}, false
+	}
+	return config.UpdatedAt.UTC().Truncate(time.Second), true
+}
+
+// notModified sets the ETag and Last-Modified headers of a configuration and reports
+// whether the client already has it. If-None-Match takes precedence over If-Modified-Since,
+// which only matches the exact date: rollbacks and tags can serve a version created before
+// the one the client has.
+func notModified(c *gin.Context, etag string, config *models.ConfigResponse) bool {
+	c.Header("ETag", etag)
+	modified, hasDate := configLastModified(config)
+	if hasDate {
+		c.Header("Last-Modified", modified.Format(http.TimeFormat))
+	}
+
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		return match == etag
+	}
+	if hasDate {
+		if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil {
+			return since.Equal(modified)
+		}
+	}
+	return false
+}
+
+// parseMeta parses the meta query parameter of configuration reads. It writes a 400
+// response and returns false if the parameter is not a boolean.
+func parseMeta(c *gin.Context) (bool, bool) {
+	raw := c.Query("meta")
+	if raw == "" {
+		return false, true
+	}
+
+	meta, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "Invalid meta parameter: " + err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return false, false
+	}
+	return meta, true
+}
+
+// addConfigMeta adds who created the served version, and when, to a configuration
+// response. The response is copied, so cached responses are never modified. It writes an
+// error response and returns false if the metadata cannot be loaded.
+func (h *ConfigHandler) addConfigMeta(c *gin.Context, config *models.ConfigResponse) (*models.ConfigResponse, bool) {
+	meta, err := h.configService.GetConfigurationMeta(config.Organization, config.Application, config.Environment, config.Version)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "meta_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return nil, false
+	}
+
+	withMeta := *config
+	withMeta.Meta = meta
+	return &withMeta, true
+}
+
+// GetConfigByAPIKey handles GET /api/config/:env with API key authentication
+func (h *ConfigHandler) GetConfigByAPIKey(c *gin.Context) {
+	h.serveConfigByAPIKey(c, c.Param("env"))
+}
+
+// authenticatedApplication returns the application set by the API key middleware,
+// responding with 401 when there is none
+func authenticatedApplication(c *gin.Context) (*models.Application, bool) {
+	value, _ := c.Get("application")
+	app, ok := value.(*models.Application)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:     "unauthorized",
+			Message:   "API key is required",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+	}
+	return app, ok
+}
+
+// GetDefaultConfigByAPIKey handles GET /api/config with API key authentication,
+// serving the configuration of the application's default environment
+func (h *ConfigHandler) GetDefaultConfigByAPIKey(c *gin.Context) {
+	app, ok := authenticatedApplication(c)
+	if !ok {
+		return
+	}
+
+	if app.DefaultEnv == "" {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:     "not_found",
+			Message:   "Application has no default environment; request /api/config/:env instead",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	h.serveConfigByAPIKey(c, app.DefaultEnv)
+}
+
+// serveConfigByAPIKey responds with the active configuration of an environment of the
+// authenticated application, honoring If-None-Match
+func (h *ConfigHandler) serveConfigByAPIKey(c *gin.Context, envSlug string) {
+	app, ok := authenticatedApplication(c)
+	if !ok {
+		return
+	}
+
+	format, ok := responseFormat(c)
+	if !ok {
+		return
+	}
+	meta, ok := parseMeta(c)
+	if !ok {
+		return
+	}
+
+	var config *models.ConfigResponse
+	var err error
+	if tag := c.Query("tag"); tag != "" {
+		config, err = h.configService.GetConfigurationByAPIKeyAndTag(app, envSlug, tag)
+	} else {
+		config, err = h.configService.GetConfigurationByAPIKey(app, envSlug, c.GetHeader("X-Client-ID"))
+	}
+	if err != nil {
+		status, code := configReadError(err)
+		c.JSON(status, models.ErrorResponse{
+			Error:     code,
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	if meta {
+		// The age changes with every request, so responses with metadata are not cached
+		c.Header("Cache-Control", "no-store")
+		if config, ok = h.addConfigMeta(c, config); !ok {
+			return
+		}
+	} else {
+		// Set cache headers and check if client has the latest version
+		c.Header("Cache-Control", "public, max-age=300") // 5 minutes
+		if notModified(c, configETag(config), config) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	if config, ok = applyFields(c, config); !ok {
+		return
+	}
+	renderConfig(c, config, format)
+}
+
+// maxBatchEnvironments limits how many environments can be fetched in one batch request
+const maxBatchEnvironments = services.MaxBatchEnvironments
+
+// GetConfigBatchByAPIKey handles POST /api/config/batch with API key authentication
+func (h *ConfigHandler) GetConfigBatchByAPIKey(c *gin.Context) {
+	app, ok := authenticatedApplication(c)
+	if !ok {
+		return
+	}
+
+	var req models.BatchConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	if len(req.Environments) == 0 || len(req.Environments) > maxBatchEnvironments {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   fmt.Sprintf("Between 1 and %d environments must be requested", maxBatchEnvironments),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	result, err := h.configService.GetConfigurationsByAPIKey(app, req.Environments, c.GetHeader("X-Client-ID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:     "batch_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	respondConfigBatch(c, http.StatusOK, result)
+}
+
+// UpdateConfig handles PUT /admin/orgs/:org/apps/:app/envs/:env
+func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	// YAML and TOML bodies are converted to JSON first
+	if !convertRequestBody(c) {
+		return
+	}
+
+	var req models.CreateConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	req.AdminToken = adminTokenName(c)
+	if req.CreatedBy == nil {
+		req.CreatedBy = req.AdminToken
+	}
+
+	// Parse optional dry_run query parameter
+	dryRun, ok := parseDryRun(c)
+	if !ok {
+		return
+	}
+
+	config, err := h.configService.UpdateConfiguration(orgSlug, appSlug, envSlug, &req, dryRun)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "update_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	// Updates to environments that require approval are accepted but not yet active
+	if config.Pending != nil {
+		respondConfig(c, http.StatusAccepted, config)
+		return
+	}
+
+	respondConfig(c, http.StatusOK, config)
+}
+
+// UpdateConfigBatch handles POST /admin/orgs/:org/apps/:app/config/batch-update and
+// POST /admin/orgs/:org/config/batch-update. With ?selector the environments are picked by
+// their labels and all get the request's config; the organization-wide endpoint requires it.
+func (h *ConfigHandler) UpdateConfigBatch(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	selector, hasSelector := c.GetQuery("selector")
+
+	var req models.BatchUpdateConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	message := ""
+	switch {
+	case appSlug == "" && !hasSelector:
+		message = "A label selector must be given with ?selector, e.g. ?selector=tier=canary"
+	case hasSelector && (req.Config == nil || req.Configs != nil):
+		message = "Environments picked by a label selector are updated with config, not configs"
+	case !hasSelector && (len(req.Configs) == 0 || len(req.Configs) > maxBatchEnvironments):
+		message = fmt.Sprintf("Between 1 and %d environments must be updated", maxBatchEnvironments)
+	}
+	if message != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   message,
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	if req.CreatedBy == nil {
+		if name := c.GetString("admin_token_name"); name != "" {
+			req.CreatedBy = &name
+		}
+	}
+
+	dryRun, ok := parseDryRun(c)
+	if !ok {
+		return
+	}
+
+	var result *models.BatchConfigResponse
+	var err error
+	if hasSelector {
+		result, err = h.configService.UpdateConfigurationsBySelector(orgSlug, appSlug, selector, &req, dryRun)
+	} else {
+		result, err = h.configService.UpdateConfigurations(orgSlug, appSlug, &req, dryRun)
+	}
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "update_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	// Each environment carries its own status when the batch was not applied
+	statusCode := http.StatusOK
+	if len(result.Errors) > 0 {
+		statusCode = http.StatusMultiStatus
+	}
+	respondConfigBatch(c, statusCode, result)
+}
+
+// RollbackConfig handles POST /admin/orgs/:org/apps/:app/envs/:env/rollback
+func (h *ConfigHandler) RollbackConfig(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	var req models.RollbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+	if (req.ToVersion == 0) == (req.ToTag == "") {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "Invalid request body: exactly one of to_version and to_tag must be provided",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	config, err := h.configService.RollbackConfiguration(orgSlug, appSlug, envSlug, &req)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "rollback_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	respondConfig(c, http.StatusOK, config)
+}
+
+// CompareAndSwapConfig handles POST /admin/orgs/:org/apps/:app/envs/:env/config/cas
+func (h *ConfigHandler) CompareAndSwapConfig(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	var req models.CompareAndSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	if req.CreatedBy == nil {
+		if name := c.GetString("admin_token_name"); name != "" {
+			req.CreatedBy = &name
+		}
+	}
+
+	config, err := h.configService.CompareAndSwapKey(orgSlug, appSlug, envSlug, &req)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "compare_and_swap_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	respondConfig(c, http.StatusOK, config)
+}
+
+// PromoteConfig handles POST /admin/orgs/:org/apps/:app/envs/:env/promote
+func (h *ConfigHandler) PromoteConfig(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	var req models.PromoteConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	req.AdminToken = adminTokenName(c)
+	if req.CreatedBy == nil {
+		req.CreatedBy = req.AdminToken
+	}
+
+	dryRun, ok := parseDryRun(c)
+	if !ok {
+		return
+	}
+
+	config, err := h.configService.PromoteConfiguration(orgSlug, appSlug, envSlug, &req, dryRun)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "promote_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	// Promotions to environments that require approval are accepted but not yet active
+	if config.Pending != nil {
+		respondConfig(c, http.StatusAccepted, config)
+		return
+	}
+
+	respondConfig(c, http.StatusOK, config)
+}
+
+// CreateConfigFromTemplate handles POST /admin/orgs/:org/apps/:app/envs/:env/config/from-template
+func (h *ConfigHandler) CreateConfigFromTemplate(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	var req models.InstantiateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	req.AdminToken = adminTokenName(c)
+	if req.CreatedBy == nil {
+		req.CreatedBy = req.AdminToken
+	}
+
+	dryRun, ok := parseDryRun(c)
+	if !ok {
+		return
+	}
+
+	config, err := h.configService.InstantiateTemplate(orgSlug, appSlug, envSlug, &req, dryRun)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "instantiate_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	// Instances in environments that require approval are accepted but not yet active
+	if config.Pending != nil {
+		respondConfig(c, http.StatusAccepted, config)
+		return
+	}
+
+	respondConfig(c, http.StatusOK, config)
+}
+
+// GetConfigHistory handles GET /admin/orgs/:org/apps/:app/envs/:env/history
+func (h *ConfigHandler) GetConfigHistory(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	params, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.configService.GetConfigurationHistory(orgSlug, appSlug, envSlug, params)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "history_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetConfigVersion handles GET /admin/orgs/:org/apps/:app/envs/:env/history/:version
+func (h *ConfigHandler) GetConfigVersion(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+	versionStr := c.Param("version")
+
+	// Parse version parameter
+	version, err := parseVersionParam(versionStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "Invalid version parameter: " + err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	config, err := h.configService.GetConfigurationVersion(orgSlug, appSlug, envSlug, version)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		code := "version_not_found"
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		} else if errors.Is(err, services.ErrCorruptConfig) {
+			code = "corrupt_config"
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     code,
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	// Set cache headers for historical versions (longer cache time since they don't change)
+	c.Header("Cache-Control", "public, max-age=3600") // 1 hour
+
+	// Check if client has the version cached
+	if notModified(c, `"`+strconv.Itoa(config.Version)+`"`, config) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	config, ok := applyFields(c, config)
+	if !ok {
+		return
+	}
+	respondConfig(c, http.StatusOK, config)
+}
+
+// GetConfigVersionByAPIKey handles GET /api/config/:env/:version with API key authentication
+func (h *ConfigHandler) GetConfigVersionByAPIKey(c *gin.Context) {
+	envSlug := c.Param("env")
+	versionStr := c.Param("version")
+
+	app, ok := authenticatedApplication(c)
+	if !ok {
+		return
+	}
+
+	// Parse version parameter
+	version, err := parseVersionParam(versionStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "Invalid version parameter: " + err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	config, err := h.configService.GetConfigurationVersionByAPIKey(app, envSlug, version)
+	if err != nil {
+		statusCode, code := configReadError(err)
+		if statusCode == http.StatusNotFound {
+			code = "version_not_found"
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     code,
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	// A version on its own never changes, so it can be cached for longer. One served with
+	// application defaults or references changes with them, like the active configuration.
+	if config.DefaultsVersion > 0 || len(config.References) > 0 {
+		c.Header("Cache-Control", "public, max-age=300") // 5 minutes
+	} else {
+		c.Header("Cache-Control", "public, max-age=3600") // 1 hour
+	}
+
+	// Check if client has the version cached
+	if notModified(c, configETag(config), config) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	config, ok = applyFields(c, config)
+	if !ok {
+		return
+	}
+	respondConfig(c, http.StatusOK, config)
+}
+
+// GetFlagsByAPIKey handles GET /api/flags/:env with API key authentication
+func (h *ConfigHandler) GetFlagsByAPIKey(c *gin.Context) {
+	envSlug := c.Param("env")
+
+	app, ok := authenticatedApplication(c)
+	if !ok {
+		return
+	}
+
+	// Parse requested flag names
+	var names []string
+	if flags := c.Query("flags"); flags != "" {
+		for _, name := range strings.Split(flags, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	// Parse default value for missing flags
+	var defaultValue *bool
+	if defaultStr := c.Query("default"); defaultStr != "" {
+		value, err := strconv.ParseBool(defaultStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:     "bad_request",
+				Message:   "Invalid default parameter: must be true or false",
+				Timestamp: time.Now(),
+				Path:      c.Request.URL.Path,
+			})
+			return
+		}
+		defaultValue = &value
+	}
+
+	flagSet, err := h.configService.GetFlagsByAPIKey(app, envSlug, names, defaultValue)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:     "not_found",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	// Set cache headers
+	etag := configETag(&models.ConfigResponse{Version: flagSet.Version, DefaultsVersion: flagSet.DefaultsVersion})
+	c.Header("Cache-Control", "public, max-age=300") // 5 minutes
+	c.Header("ETag", etag)
+
+	// Check if client has the latest version
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		if match == etag {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, flagSet.Flags)
+}
+
+// GetConfigChanges handles GET /admin/orgs/:org/apps/:app/envs/:env/changes
+func (h *ConfigHandler) GetConfigChanges(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	params, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	changes, err := h.configService.GetConfigurationChanges(orgSlug, appSlug, envSlug, params)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "changes_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// changesCSVHeader names the columns of change log exports
+var changesCSVHeader = []string{
+	"created_at", "organization", "application", "environment", "scope", "action",
+	"version_from", "version_to", "created_by", "approved_by", "message",
+}
+
+// changesFlushRows is how many rows of a change log export are written between flushes
+const changesFlushRows = 100
+
+// ExportConfigChanges handles GET /admin/orgs/:org/apps/:app/envs/:env/changes/export
+func (h *ConfigHandler) ExportConfigChanges(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	if format := c.DefaultQuery("format", "csv"); format != "csv" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   fmt.Sprintf("Invalid format parameter: %q is not supported, use csv", format),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	orgScope := false
+	switch scope := c.Query("scope"); scope {
+	case "", models.ChangeScopeEnvironment:
+	case "org":
+		orgScope = true
+	default:
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   fmt.Sprintf("Invalid scope parameter: %q must be environment or org", scope),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s-%s-changes.csv", orgSlug, appSlug, envSlug)
+	if orgScope {
+		filename = fmt.Sprintf("%s-changes.csv", orgSlug)
+	}
+
+	// The response starts with the first change, so that a missing environment can
+	// still be reported as an error
+	writer := csv.NewWriter(c.Writer)
+	started := false
+	start := func() error {
+		started = true
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Header("Cache-Control", "no-store")
+		c.Status(http.StatusOK)
+		return writer.Write(changesCSVHeader)
+	}
+
+	rows := 0
+	err := h.configService.ExportConfigurationChanges(orgSlug, appSlug, envSlug, orgScope, func(change *models.ConfigChange) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := writer.Write(changeCSVRecord(change)); err != nil {
+			return err
+		}
+		if rows++; rows%changesFlushRows == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+		return writer.Error()
+	})
+	if err != nil {
+		if started {
+			// The status is already sent, so the export just ends early
+			_ = c.Error(err)
+			writer.Flush()
+			return
+		}
+
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "export_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	if !started {
+		if err := start(); err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+	writer.Flush()
+}
+
+// changeCSVRecord formats a change as a row of a change log export. Missing versions and
+// names are empty cells.
+func changeCSVRecord(change *models.ConfigChange) []string {
+	var orgSlug, appSlug, envSlug string
+	if env := change.Environment; env != nil {
+		envSlug = env.Slug
+		if app := env.Application; app != nil {
+			appSlug = app.Slug
+			if app.Organization != nil {
+				orgSlug = app.Organization.Slug
+			}
+		}
+	}
+
+	versionFrom := ""
+	if change.VersionFrom != nil {
+		versionFrom = strconv.Itoa(*change.VersionFrom)
+	}
+	text := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return csvCell(*value)
+	}
+
+	return []string{
+		change.CreatedAt.UTC().Format(time.RFC3339),
+		orgSlug,
+		appSlug,
+		envSlug,
+		change.Scope,
+		change.Action,
+		versionFrom,
+		strconv.Itoa(change.VersionTo),
+		text(change.CreatedBy),
+		text(change.ApprovedBy),
+		text(change.Message),
+	}
+}
+
+// csvCell keeps free text from being evaluated as a formula when the export is opened in
+// a spreadsheet, by prefixing values that start like one with a quote
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// ListTags handles GET /admin/orgs/:org/apps/:app/envs/:env/tags
+func (h *ConfigHandler) ListTags(c *gin.Context) {
+	tags, err := h.configService.ListTags(c.Param("org"), c.Param("app"), c.Param("env"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "tags_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// SetTag handles PUT /admin/orgs/:org/apps/:app/envs/:env/tags/:tag
+func (h *ConfigHandler) SetTag(c *gin.Context) {
+	var req models.SetTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	tag, created, err := h.configService.SetTag(c.Param("org"), c.Param("app"), c.Param("env"), c.Param("tag"), &req)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "tag_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	if created {
+		c.JSON(http.StatusCreated, tag)
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag handles DELETE /admin/orgs/:org/apps/:app/envs/:env/tags/:tag
+func (h *ConfigHandler) DeleteTag(c *gin.Context) {
+	var deletedBy *string
+	if name := c.GetString("admin_token_name"); name != "" {
+		deletedBy = &name
+	}
+
+	err := h.configService.DeleteTag(c.Param("org"), c.Param("app"), c.Param("env"), c.Param("tag"), deletedBy)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "delete_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ListTemplates handles GET /admin/orgs/:org/templates
+func (h *ConfigHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.configService.ListTemplates(c.Param("org"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "templates_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate handles GET /admin/orgs/:org/templates/:template
+func (h *ConfigHandler) GetTemplate(c *gin.Context) {
+	template, err := h.configService.GetTemplate(c.Param("org"), c.Param("template"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "template_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// SetTemplate handles PUT /admin/orgs/:org/templates/:template
+func (h *ConfigHandler) SetTemplate(c *gin.Context) {
+	var req models.SetConfigTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	if req.UpdatedBy == nil {
+		if name := c.GetString("admin_token_name"); name != "" {
+			req.UpdatedBy = &name
+		}
+	}
+
+	template, created, err := h.configService.SetTemplate(c.Param("org"), c.Param("template"), &req)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "template_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	if created {
+		c.JSON(http.StatusCreated, template)
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate handles DELETE /admin/orgs/:org/templates/:template
+func (h *ConfigHandler) DeleteTemplate(c *gin.Context) {
+	err := h.configService.DeleteTemplate(c.Param("org"), c.Param("template"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "delete_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ListPendingChanges handles GET /admin/orgs/:org/apps/:app/envs/:env/config/pending
+func (h *ConfigHandler) ListPendingChanges(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	params, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	// Only open proposals are listed unless another status is requested
+	status := c.DefaultQuery("status", models.PendingStatusPending)
+	if status == "all" {
+		status = ""
+	}
+
+	changes, err := h.configService.ListPendingChanges(orgSlug, appSlug, envSlug, status, params)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "pending_changes_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+// ApprovePendingChange handles POST /admin/orgs/:org/apps/:app/envs/:env/config/approve
+func (h *ConfigHandler) ApprovePendingChange(c *gin.Context) {
+	req, ok := bindReviewChangeRequest(c)
+	if !ok {
+		return
+	}
+
+	config, err := h.configService.ApprovePendingChange(c.Param("org"), c.Param("app"), c.Param("env"), req)
+	if err != nil {
+		c.JSON(statusForError(err), models.ErrorResponse{
+			Error:     "approve_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	respondConfig(c, http.StatusOK, config)
+}
+
+// RejectPendingChange handles POST /admin/orgs/:org/apps/:app/envs/:env/config/reject
+func (h *ConfigHandler) RejectPendingChange(c *gin.Context) {
+	req, ok := bindReviewChangeRequest(c)
+	if !ok {
+		return
+	}
+
+	pending, err := h.configService.RejectPendingChange(c.Param("org"), c.Param("app"), c.Param("env"), req)
+	if err != nil {
+		c.JSON(statusForError(err), models.ErrorResponse{
+			Error:     "reject_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, pending)
+}
+
+// GetRollout handles GET /admin/orgs/:org/apps/:app/envs/:env/rollout
+func (h *ConfigHandler) GetRollout(c *gin.Context) {
+	rollout, err := h.configService.GetRollout(c.Param("org"), c.Param("app"), c.Param("env"))
+	if err != nil {
+		c.JSON(statusForError(err), models.ErrorResponse{
+			Error:     "not_found",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, rollout)
+}
+
+// UpdateRollout handles PUT /admin/orgs/:org/apps/:app/envs/:env/rollout
+func (h *ConfigHandler) UpdateRollout(c *gin.Context) {
+	var req models.UpdateRolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	if req.UpdatedBy == nil {
+		if name := c.GetString("admin_token_name"); name != "" {
+			req.UpdatedBy = &name
+		}
+	}
+
+	rollout, err := h.configService.UpdateRollout(c.Param("org"), c.Param("app"), c.Param("env"), &req)
+	if err != nil {
+		c.JSON(statusForError(err), models.ErrorResponse{
+			Error:     "rollout_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, rollout)
+}
+
+// PromoteRollout handles POST /admin/orgs/:org/apps/:app/envs/:env/rollout/promote
+func (h *ConfigHandler) PromoteRollout(c *gin.Context) {
+	var req models.PromoteRolloutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+			return
+		}
+	}
+
+	if req.UpdatedBy == nil {
+		if name := c.GetString("admin_token_name"); name != "" {
+			req.UpdatedBy = &name
+		}
+	}
+
+	config, err := h.configService.PromoteRollout(c.Param("org"), c.Param("app"), c.Param("env"), &req)
+	if err != nil {
+		c.JSON(statusForError(err), models.ErrorResponse{
+			Error:     "promote_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	respondConfig(c, http.StatusOK, config)
+}
+
+// AbortRollout handles DELETE /admin/orgs/:org/apps/:app/envs/:env/rollout
+func (h *ConfigHandler) AbortRollout(c *gin.Context) {
+	var abortedBy *string
+	if name := c.GetString("admin_token_name"); name != "" {
+		abortedBy = &name
+	}
+
+	if err := h.configService.AbortRollout(c.Param("org"), c.Param("app"), c.Param("env"), abortedBy); err != nil {
+		c.JSON(statusForError(err), models.ErrorResponse{
+			Error:     "delete_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// bindReviewChangeRequest parses the body of an approve or reject request. The reviewer
+// defaults to the name of the admin token making the request.
+func bindReviewChangeRequest(c *gin.Context) (*models.ReviewChangeRequest, bool) {
+	var req models.ReviewChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return nil, false
+	}
+
+	req.AdminToken = adminTokenName(c)
+	if req.ReviewedBy == nil {
+		req.ReviewedBy = req.AdminToken
+	}
+
+	return &req, true
+}
+
+// adminTokenName returns the name of the admin token that authenticated the request, or
+// nil when admin auth is disabled
+func adminTokenName(c *gin.Context) *string {
+	if name := c.GetString("admin_token_name"); name != "" {
+		return &name
+	}
+	return nil
+}
+
+// DiffDraftConfig handles POST /admin/orgs/:org/apps/:app/envs/:env/config/diff-draft
+func (h *ConfigHandler) DiffDraftConfig(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	var req models.DiffDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	diff, err := h.configService.DiffDraftConfiguration(orgSlug, appSlug, envSlug, req.Config)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "diff_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// LintConfig handles POST /admin/orgs/:org/apps/:app/envs/:env/config/lint
+func (h *ConfigHandler) LintConfig(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	var req models.LintConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	lint, err := h.configService.LintConfiguration(orgSlug, appSlug, envSlug, req.Config)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "lint_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, lint)
+}
+
+// HealthCheck handles GET /health
+func (h *ConfigHandler) HealthCheck(c *gin.Context) {
+	services := h.configService.HealthCheck()
+
+	// The service keeps working without its cache, but slower
+	status := "ok"
+	message := "Remote Config System is running"
+	if services["cache"] == "disconnected" || services["cache_breaker"] == cache.BreakerOpen {
+		status = "degraded"
+		message = "Remote Config System is running without its cache"
+	}
+
+	c.JSON(http.StatusOK, models.HealthResponse{
+		Status:    status,
+		Message:   message,
+		Timestamp: time.Now(),
+		Services:  services,
+	})
+}
+
+// EncryptSecrets handles POST /admin/orgs/:org/apps/:app/envs/:env/config/encrypt-secrets
+func (h *ConfigHandler) EncryptSecrets(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	result, err := h.configService.EncryptExistingSecrets(orgSlug, appSlug, envSlug)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Error:     "not_found",
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		} else if strings.HasPrefix(err.Error(), "encryption is not configured") {
+			statusCode = http.StatusConflict
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "encryption_failed",
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -40,41 +1592,52 @@ func (h *ConfigHandler) GetConfig(c *gin.Context) {
 		return
 	}
 
-	// Set cache headers
-	c.Header("Cache-Control", "public, max-age=300") // 5 minutes
-	c.Header("ETag", `"`+strconv.Itoa(config.Version)+`"`)
+	c.JSON(http.StatusOK, result)
+}
 
-	// Check if client has the latest version
-	if match := c.GetHeader("If-None-Match"); match != "" {
-		if match == `"`+strconv.Itoa(config.Version)+`"` {
-			c.Status(http.StatusNotModified)
-			return
+// PruneHistory handles POST /admin/orgs/:org/apps/:app/envs/:env/history/prune
+func (h *ConfigHandler) PruneHistory(c *gin.Context) {
+	result, err := h.configService.PruneHistory(c.Param("org"), c.Param("app"), c.Param("env"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
 		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "prune_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
 	}
 
-	c.JSON(http.StatusOK, config)
+	c.JSON(http.StatusOK, result)
 }
 
-// GetConfigByAPIKey handles GET /api/config/:env with API key authentication
-func (h *ConfigHandler) GetConfigByAPIKey(c *gin.Context) {
-	envSlug := c.Param("env")
-
-	// Get API key from context (set by middleware)
-	apiKey, exists := c.Get("api_key")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-			Error:     "unauthorized",
-			Message:   "API key is required",
+// GetKeyHistory handles GET /admin/orgs/:org/apps/:app/envs/:env/history/key/:keyPath
+func (h *ConfigHandler) GetKeyHistory(c *gin.Context) {
+	keyPath := c.Param("keyPath")
+	if fields := parseFields(keyPath); len(fields) != 1 || fields[0] != keyPath {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "Invalid key path: must be a dot-notated key such as database.timeout",
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
 		})
 		return
 	}
 
-	config, err := h.configService.GetConfigurationByAPIKey(apiKey.(string), envSlug)
+	history, err := h.configService.GetKeyHistory(c.Param("org"), c.Param("app"), c.Param("env"), keyPath)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Error:     "not_found",
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "key_history_failed",
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -82,49 +1645,103 @@ func (h *ConfigHandler) GetConfigByAPIKey(c *gin.Context) {
 		return
 	}
 
-	// Set cache headers
-	c.Header("Cache-Control", "public, max-age=300") // 5 minutes
-	c.Header("ETag", `"`+strconv.Itoa(config.Version)+`"`)
+	c.JSON(http.StatusOK, history)
+}
 
-	// Check if client has the latest version
-	if match := c.GetHeader("If-None-Match"); match != "" {
-		if match == `"`+strconv.Itoa(config.Version)+`"` {
-			c.Status(http.StatusNotModified)
-			return
+// GetAppDefaults handles GET /admin/orgs/:org/apps/:app/defaults
+func (h *ConfigHandler) GetAppDefaults(c *gin.Context) {
+	defaults, err := h.configService.GetApplicationDefaults(c.Param("org"), c.Param("app"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
 		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "defaults_not_found",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
 	}
 
-	c.JSON(http.StatusOK, config)
+	c.JSON(http.StatusOK, defaults)
 }
 
-// UpdateConfig handles PUT /admin/orgs/:org/apps/:app/envs/:env
-func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
-	orgSlug := c.Param("org")
-	appSlug := c.Param("app")
-	envSlug := c.Param("env")
+// UpdateAppDefaults handles PUT /admin/orgs/:org/apps/:app/defaults
+func (h *ConfigHandler) UpdateAppDefaults(c *gin.Context) {
+	// YAML and TOML bodies are converted to JSON first
+	if !convertRequestBody(c) {
+		return
+	}
 
-	var req models.CreateConfigRequest
+	var req models.UpdateApplicationDefaultsRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:     "bad_request",
-			Message:   "Invalid request body: " + err.Error(),
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	// The editor defaults to the name of the admin token making the request
+	if req.UpdatedBy == nil {
+		if name := c.GetString("admin_token_name"); name != "" {
+			req.UpdatedBy = &name
+		}
+	}
+
+	defaults, err := h.configService.SetApplicationDefaults(c.Param("org"), c.Param("app"), &req)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "defaults_update_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, defaults)
+}
+
+// DeleteAppDefaults handles DELETE /admin/orgs/:org/apps/:app/defaults
+func (h *ConfigHandler) DeleteAppDefaults(c *gin.Context) {
+	var deletedBy *string
+	if name := c.GetString("admin_token_name"); name != "" {
+		deletedBy = &name
+	}
+
+	if err := h.configService.DeleteApplicationDefaults(c.Param("org"), c.Param("app"), deletedBy); err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "delete_failed",
+			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
 		})
 		return
 	}
 
-	config, err := h.configService.UpdateConfiguration(orgSlug, appSlug, envSlug, &req)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetConfigSchema handles GET /admin/orgs/:org/apps/:app/envs/:env/schema
+func (h *ConfigHandler) GetConfigSchema(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	schema, err := h.configService.GetConfigSchema(orgSlug, appSlug, envSlug)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "environment not found" {
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
-		} else if err.Error() == "invalid JSON configuration" {
-			statusCode = http.StatusBadRequest
 		}
 
 		c.JSON(statusCode, models.ErrorResponse{
-			Error:     "update_failed",
+			Error:     "schema_not_found",
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -132,35 +1749,27 @@ func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, config)
+	c.JSON(http.StatusOK, schema)
 }
 
-// RollbackConfig handles POST /admin/orgs/:org/apps/:app/envs/:env/rollback
-func (h *ConfigHandler) RollbackConfig(c *gin.Context) {
+// UpdateConfigSchema handles PUT /admin/orgs/:org/apps/:app/envs/:env/schema
+func (h *ConfigHandler) UpdateConfigSchema(c *gin.Context) {
 	orgSlug := c.Param("org")
 	appSlug := c.Param("app")
 	envSlug := c.Param("env")
 
-	var req models.RollbackRequest
+	var req models.UpdateConfigSchemaRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:     "bad_request",
-			Message:   "Invalid request body: " + err.Error(),
-			Timestamp: time.Now(),
-			Path:      c.Request.URL.Path,
-		})
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
 		return
 	}
 
-	config, err := h.configService.RollbackConfiguration(orgSlug, appSlug, envSlug, &req)
+	schema, err := h.configService.SetConfigSchema(orgSlug, appSlug, envSlug, &req)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "environment not found" || err.Error() == "target version not found" {
-			statusCode = http.StatusNotFound
-		}
+		statusCode := statusForError(err)
 
 		c.JSON(statusCode, models.ErrorResponse{
-			Error:     "rollback_failed",
+			Error:     "schema_update_failed",
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -168,37 +1777,42 @@ func (h *ConfigHandler) RollbackConfig(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, config)
+	c.JSON(http.StatusOK, schema)
 }
 
-// GetConfigHistory handles GET /admin/orgs/:org/apps/:app/envs/:env/history
-func (h *ConfigHandler) GetConfigHistory(c *gin.Context) {
+// GetFormSchema handles GET /admin/orgs/:org/apps/:app/envs/:env/form-schema
+func (h *ConfigHandler) GetFormSchema(c *gin.Context) {
 	orgSlug := c.Param("org")
 	appSlug := c.Param("app")
 	envSlug := c.Param("env")
 
-	// Parse pagination parameters
-	params := models.DefaultPaginationParams()
-	if page := c.Query("page"); page != "" {
-		if p, err := strconv.Atoi(page); err == nil && p > 0 {
-			params.Page = p
-		}
-	}
-	if pageSize := c.Query("page_size"); pageSize != "" {
-		if ps, err := strconv.Atoi(pageSize); err == nil && ps > 0 && ps <= 100 {
-			params.PageSize = ps
-		}
+	form, err := h.configService.GetFormSchema(orgSlug, appSlug, envSlug)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "form_schema_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
 	}
 
-	history, err := h.configService.GetConfigurationHistory(orgSlug, appSlug, envSlug, params)
+	c.JSON(http.StatusOK, form)
+}
+
+// GetConfigRules handles GET /admin/orgs/:org/apps/:app/envs/:env/rules
+func (h *ConfigHandler) GetConfigRules(c *gin.Context) {
+	ruleset, err := h.configService.GetConfigRules(c.Param("org"), c.Param("app"), c.Param("env"))
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "environment not found" {
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
 		c.JSON(statusCode, models.ErrorResponse{
-			Error:     "history_failed",
+			Error:     "rules_not_found",
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -206,37 +1820,43 @@ func (h *ConfigHandler) GetConfigHistory(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, history)
+	c.JSON(http.StatusOK, ruleset)
 }
 
-// GetConfigVersion handles GET /admin/orgs/:org/apps/:app/envs/:env/history/:version
-func (h *ConfigHandler) GetConfigVersion(c *gin.Context) {
-	orgSlug := c.Param("org")
-	appSlug := c.Param("app")
-	envSlug := c.Param("env")
-	versionStr := c.Param("version")
+// UpdateConfigRules handles PUT /admin/orgs/:org/apps/:app/envs/:env/rules
+func (h *ConfigHandler) UpdateConfigRules(c *gin.Context) {
+	var req models.UpdateConfigRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
 
-	// Parse version parameter
-	version, err := strconv.Atoi(versionStr)
+	ruleset, err := h.configService.SetConfigRules(c.Param("org"), c.Param("app"), c.Param("env"), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:     "bad_request",
-			Message:   "Invalid version parameter: " + err.Error(),
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "rules_update_failed",
+			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
 		})
 		return
 	}
 
-	config, err := h.configService.GetConfigurationVersion(orgSlug, appSlug, envSlug, version)
-	if err != nil {
+	c.JSON(http.StatusOK, ruleset)
+}
+
+// DeleteConfigRules handles DELETE /admin/orgs/:org/apps/:app/envs/:env/rules
+func (h *ConfigHandler) DeleteConfigRules(c *gin.Context) {
+	if err := h.configService.DeleteConfigRules(c.Param("org"), c.Param("app"), c.Param("env")); err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "environment not found" || err.Error() == "configuration version not found" {
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
 		c.JSON(statusCode, models.ErrorResponse{
-			Error:     "version_not_found",
+			Error:     "delete_failed",
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -244,49 +1864,64 @@ func (h *ConfigHandler) GetConfigVersion(c *gin.Context) {
 		return
 	}
 
-	// Set cache headers for historical versions (longer cache time since they don't change)
-	c.Header("Cache-Control", "public, max-age=3600") // 1 hour
-	c.Header("ETag", `"`+strconv.Itoa(config.Version)+`"`)
+	c.JSON(http.StatusNoContent, nil)
+}
 
-	// Check if client has the version cached
-	if match := c.GetHeader("If-None-Match"); match != "" {
-		if match == `"`+strconv.Itoa(config.Version)+`"` {
-			c.Status(http.StatusNotModified)
-			return
+// GetNotificationSettings handles GET /admin/orgs/:org/apps/:app/envs/:env/notifications
+func (h *ConfigHandler) GetNotificationSettings(c *gin.Context) {
+	settings, err := h.configService.GetNotificationSettings(c.Param("org"), c.Param("app"), c.Param("env"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
 		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "notifications_not_found",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
 	}
 
-	c.JSON(http.StatusOK, config)
+	c.JSON(http.StatusOK, settings)
 }
 
-// GetConfigChanges handles GET /admin/orgs/:org/apps/:app/envs/:env/changes
-func (h *ConfigHandler) GetConfigChanges(c *gin.Context) {
-	orgSlug := c.Param("org")
-	appSlug := c.Param("app")
-	envSlug := c.Param("env")
-
-	// Parse pagination parameters
-	params := models.DefaultPaginationParams()
-	if page := c.Query("page"); page != "" {
-		if p, err := strconv.Atoi(page); err == nil && p > 0 {
-			params.Page = p
-		}
-	}
-	if pageSize := c.Query("page_size"); pageSize != "" {
-		if ps, err := strconv.Atoi(pageSize); err == nil && ps > 0 && ps <= 100 {
-			params.PageSize = ps
-		}
+// UpdateNotificationSettings handles PUT /admin/orgs/:org/apps/:app/envs/:env/notifications
+func (h *ConfigHandler) UpdateNotificationSettings(c *gin.Context) {
+	var req models.UpdateNotificationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
 	}
 
-	changes, err := h.configService.GetConfigurationChanges(orgSlug, appSlug, envSlug, params)
+	settings, err := h.configService.SetNotificationSettings(c.Param("org"), c.Param("app"), c.Param("env"), &req)
 	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "notifications_update_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// DeleteNotificationSettings handles DELETE /admin/orgs/:org/apps/:app/envs/:env/notifications
+func (h *ConfigHandler) DeleteNotificationSettings(c *gin.Context) {
+	if err := h.configService.DeleteNotificationSettings(c.Param("org"), c.Param("app"), c.Param("env")); err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "environment not found" {
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
 		c.JSON(statusCode, models.ErrorResponse{
-			Error:     "changes_failed",
+			Error:     "delete_failed",
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -294,17 +1929,88 @@ func (h *ConfigHandler) GetConfigChanges(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, changes)
+	c.JSON(http.StatusNoContent, nil)
 }
 
-// HealthCheck handles GET /health
-func (h *ConfigHandler) HealthCheck(c *gin.Context) {
-	services := h.configService.HealthCheck()
+// SearchConfigs handles GET /admin/search
+func (h *ConfigHandler) SearchConfigs(c *gin.Context) {
+	key := c.Query("key")
+	value := c.Query("value")
 
-	c.JSON(http.StatusOK, models.HealthResponse{
-		Status:    "ok",
-		Message:   "Remote Config System is running",
-		Timestamp: time.Now(),
-		Services:  services,
-	})
+	if key == "" && value == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "At least one of the key or value query parameters is required",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	params, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	results, err := h.configService.SearchConfigurations(key, value, params)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "search_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// ValidateManifest handles POST /admin/validate/manifest
+func (h *ConfigHandler) ValidateManifest(c *gin.Context) {
+	var req models.ManifestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "bad_request", "Invalid request body: ", err))
+		return
+	}
+
+	result := h.configService.ValidateManifest(req.Items)
+
+	// Each item carries its own status, so the overall response is multi-status
+	c.JSON(http.StatusMultiStatus, result)
+}
+
+// parseDryRun parses the optional dry_run query parameter, responding with 400 if it is invalid
+func parseDryRun(c *gin.Context) (bool, bool) {
+	raw := c.Query("dry_run")
+	if raw == "" {
+		return false, true
+	}
+
+	dryRun, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "Invalid dry_run parameter: " + err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return false, false
+	}
+
+	return dryRun, true
+}
+
+// parseVersionParam parses a configuration version path parameter, which must be a positive integer
+func parseVersionParam(versionStr string) (int, error) {
+	version, err := strconv.Atoi(versionStr)
+	if err != nil {
+		return 0, err
+	}
+	if version < 1 {
+		return 0, fmt.Errorf("version must be a positive integer")
+	}
+	return version, nil
 }
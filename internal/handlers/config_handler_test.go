@@ -2,13 +2,18 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"remote-config-system/internal/db"
 	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
 	"remote-config-system/internal/testutil"
 
 	"github.com/gin-gonic/gin"
@@ -18,6 +23,9 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// testAPIKeyApp is the application the API key middleware puts in the context of API key requests
+var testAPIKeyApp = testutil.CreateTestApplication(uuid.Nil, "Test App", "test-app", "test-api-key")
+
 func TestConfigHandler_GetConfig(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -65,7 +73,7 @@ func TestConfigHandler_GetConfig(t *testing.T) {
 		// Setup mock service
 		mockService := &testutil.MockConfigService{}
 		mockService.On("GetConfiguration", "test-org", "test-app", "nonexistent").
-			Return(nil, assert.AnError)
+			Return(nil, fmt.Errorf("environment not found: test-org/test-app/nonexistent: %w", services.ErrNotFound))
 
 		// Create handler
 		handler := NewConfigHandler(mockService)
@@ -95,6 +103,33 @@ func TestConfigHandler_GetConfig(t *testing.T) {
 
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("other read errors are not reported as not found", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfiguration", "test-org", "test-app", "prod").
+			Return(nil, fmt.Errorf("failed to decrypt secret values: cipher: message authentication failed"))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/config/test-org/test-app/prod", nil)
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		handler.GetConfig(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "internal_error", response.Error)
+
+		mockService.AssertExpectations(t)
+	})
 }
 
 func TestConfigHandler_GetConfigByAPIKey(t *testing.T) {
@@ -105,7 +140,7 @@ func TestConfigHandler_GetConfigByAPIKey(t *testing.T) {
 		mockService := &testutil.MockConfigService{}
 		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 1)
 		
-		mockService.On("GetConfigurationByAPIKey", "test-api-key", "prod").
+		mockService.On("GetConfigurationByAPIKey", testAPIKeyApp, "prod", "").
 			Return(expectedConfig, nil)
 
 		// Create handler
@@ -120,7 +155,7 @@ func TestConfigHandler_GetConfigByAPIKey(t *testing.T) {
 		c.Params = gin.Params{
 			{Key: "env", Value: "prod"},
 		}
-		c.Set("api_key", "test-api-key")
+		c.Set("application", testAPIKeyApp)
 
 		// Execute handler
 		handler.GetConfigByAPIKey(c)
@@ -166,171 +201,2956 @@ func TestConfigHandler_GetConfigByAPIKey(t *testing.T) {
 		
 		assert.Equal(t, "unauthorized", response.Error)
 	})
+
+	run := func(mockService *testutil.MockConfigService, query string) *httptest.ResponseRecorder {
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/config/prod"+query, nil)
+		c.Params = gin.Params{{Key: "env", Value: "prod"}}
+		c.Set("application", testAPIKeyApp)
+
+		handler.GetConfigByAPIKey(c)
+		return w
+	}
+
+	t.Run("metadata is only included on request", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfigurationByAPIKey", testAPIKeyApp, "prod", "").
+			Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 3), nil)
+
+		w := run(mockService, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), `"meta"`)
+		assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+
+		createdAt := time.Now().Add(-50 * time.Hour)
+		mockService.On("GetConfigurationMeta", "test-org", "test-app", "prod", 3).
+			Return(&models.ConfigMeta{CreatedAt: createdAt, Age: "2 days"}, nil)
+
+		w = run(mockService, "?meta=true")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.Empty(t, w.Header().Get("ETag"))
+
+		var response struct {
+			Meta map[string]interface{} `json:"meta"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Contains(t, response.Meta, "created_by")
+		assert.Nil(t, response.Meta["created_by"])
+		assert.Equal(t, "2 days", response.Meta["age"])
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid meta parameter", func(t *testing.T) {
+		w := run(&testutil.MockConfigService{}, "?meta=maybe")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid meta parameter")
+	})
 }
 
-func TestConfigHandler_UpdateConfig(t *testing.T) {
+func TestConfigHandler_GetDefaultConfigByAPIKey(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Run("successful config update", func(t *testing.T) {
-		// Setup mock service
+	newContext := func(w *httptest.ResponseRecorder, defaultEnv string) *gin.Context {
+		app := testutil.CreateTestApplication(uuid.New(), "Test App", "test-app", "test-api-key")
+		app.DefaultEnv = defaultEnv
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/config", nil)
+		c.Set("application", app)
+		return c
+	}
+
+	t.Run("serves the default environment", func(t *testing.T) {
 		mockService := &testutil.MockConfigService{}
-		
-		updateReq := testutil.CreateTestUpdateConfigRequest("admin")
-		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 2)
-		
-		mockService.On("UpdateConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.CreateConfigRequest")).
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 3)
+
+		mockService.On("GetConfigurationByAPIKey", mock.AnythingOfType("*models.Application"), "prod", "").
 			Return(expectedConfig, nil)
 
-		// Create handler
 		handler := NewConfigHandler(mockService)
 
-		// Create test request
-		reqBody, _ := json.Marshal(updateReq)
 		w := httptest.NewRecorder()
+		handler.GetDefaultConfigByAPIKey(newContext(w, "prod"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+		var response models.ConfigResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "prod", response.Environment)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("no default environment", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetDefaultConfigByAPIKey(newContext(w, ""))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertNotCalled(t, "GetConfigurationByAPIKey", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigHandler_GetConfigWithDefaults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, target string) *gin.Context {
 		c, _ := gin.CreateTestContext(w)
-		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBuffer(reqBody))
-		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request = httptest.NewRequest("GET", target, nil)
 		c.Params = gin.Params{
 			{Key: "org", Value: "test-org"},
 			{Key: "app", Value: "test-app"},
 			{Key: "env", Value: "prod"},
 		}
+		return c
+	}
 
-		// Execute handler
-		handler.UpdateConfig(c)
+	t.Run("merged configuration includes the defaults version in the ETag", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 3)
+		expectedConfig.DefaultsVersion = 2
+
+		mockService.On("GetConfiguration", "test-org", "test-app", "prod").Return(expectedConfig, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetConfig(newContext(w, "/config/test-org/test-app/prod"))
 
-		// Assert response
 		assert.Equal(t, http.StatusOK, w.Code)
-		
-		var response models.ConfigResponse
-		err := json.Unmarshal(w.Body.Bytes(), &response)
-		require.NoError(t, err)
-		
-		assert.Equal(t, expectedConfig.Version, response.Version)
+		assert.Equal(t, `"3-2"`, w.Header().Get("ETag"))
+
+		// A client holding the version from before the defaults changed refetches
+		w = httptest.NewRecorder()
+		c := newContext(w, "/config/test-org/test-app/prod")
+		c.Request.Header.Set("If-None-Match", `"3"`)
+		handler.GetConfig(c)
+		assert.Equal(t, http.StatusOK, w.Code)
 
 		mockService.AssertExpectations(t)
 	})
 
-	t.Run("invalid JSON request", func(t *testing.T) {
-		// Setup mock service
+	t.Run("resolved references include the referenced versions in the ETag", func(t *testing.T) {
 		mockService := &testutil.MockConfigService{}
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 3)
+		expectedConfig.References = []models.ConfigReferenceSource{{Environment: "shared", Version: 5}}
+
+		mockService.On("GetConfiguration", "test-org", "test-app", "prod").Return(expectedConfig, nil)
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).GetConfig(newContext(w, "/config/test-org/test-app/prod"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3-shared.5"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("broken reference", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfiguration", "test-org", "test-app", "prod").
+			Return(nil, fmt.Errorf("broken reference env://shared/api.base: key api.base not found in environment shared: %w", services.ErrBrokenReference))
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).GetConfig(newContext(w, "/config/test-org/test-app/prod"))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "broken_reference", response.Error)
+	})
+
+	t.Run("raw configuration", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 3)
+
+		mockService.On("GetRawConfiguration", "test-org", "test-app", "prod").Return(expectedConfig, nil)
 
-		// Create handler
 		handler := NewConfigHandler(mockService)
 
-		// Create test request with invalid JSON
 		w := httptest.NewRecorder()
+		handler.GetConfig(newContext(w, "/config/test-org/test-app/prod?raw=true"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+		mockService.AssertExpectations(t)
+		mockService.AssertNotCalled(t, "GetConfiguration", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid raw parameter", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetConfig(newContext(w, "/config/test-org/test-app/prod?raw=maybe"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestConfigHandler_UpdateAppDefaults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, body string) *gin.Context {
 		c, _ := gin.CreateTestContext(w)
-		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBufferString("invalid json"))
+		c.Request = httptest.NewRequest("PUT", "/admin/orgs/test-org/apps/test-app/defaults", bytes.NewBufferString(body))
 		c.Request.Header.Set("Content-Type", "application/json")
 		c.Params = gin.Params{
 			{Key: "org", Value: "test-org"},
 			{Key: "app", Value: "test-app"},
-			{Key: "env", Value: "prod"},
 		}
+		return c
+	}
 
-		// Execute handler
-		handler.UpdateConfig(c)
+	t.Run("success", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		expected := &models.ApplicationDefaults{Version: 2, ConfigJSON: json.RawMessage(`{"timeout":30}`)}
+		mockService.On("SetApplicationDefaults", "test-org", "test-app", mock.MatchedBy(func(req *models.UpdateApplicationDefaultsRequest) bool {
+			return req.UpdatedBy != nil && *req.UpdatedBy == "deploy-bot"
+		})).Return(expected, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, `{"config": {"timeout": 30}}`)
+		c.Set("admin_token_name", "deploy-bot")
+		handler.UpdateAppDefaults(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.ApplicationDefaults
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.Version)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("defaults must be an object", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("SetApplicationDefaults", "test-org", "test-app", mock.AnythingOfType("*models.UpdateApplicationDefaultsRequest")).
+			Return(nil, fmt.Errorf("invalid defaults: must be a JSON object: %w", services.ErrInvalidInput))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.UpdateAppDefaults(newContext(w, `{"config": [1, 2]}`))
 
-		// Assert response
 		assert.Equal(t, http.StatusBadRequest, w.Code)
-		
-		var response models.ErrorResponse
-		err := json.Unmarshal(w.Body.Bytes(), &response)
-		require.NoError(t, err)
-		
-		assert.Equal(t, "bad_request", response.Error)
+
+		mockService.AssertExpectations(t)
 	})
 }
 
-func TestConfigHandler_HealthCheck(t *testing.T) {
+func TestConfigHandler_GetConfigByTag(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	t.Run("successful health check", func(t *testing.T) {
-		// Setup mock service
+	t.Run("public config by tag", func(t *testing.T) {
 		mockService := &testutil.MockConfigService{}
-		expectedHealth := map[string]string{
-			"database": "connected",
-			"cache":    "connected",
-		}
-		
-		mockService.On("HealthCheck").Return(expectedHealth)
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 5)
+		expectedConfig.Tag = "canary"
+
+		mockService.On("GetConfigurationByTag", "test-org", "test-app", "prod", "canary").
+			Return(expectedConfig, nil)
 
-		// Create handler
 		handler := NewConfigHandler(mockService)
 
-		// Create test request
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/config/test-org/test-app/prod?tag=canary", nil)
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
 
-		// Execute handler
-		handler.HealthCheck(c)
+		handler.GetConfig(c)
 
-		// Assert response
 		assert.Equal(t, http.StatusOK, w.Code)
-		
-		var response models.HealthResponse
-		err := json.Unmarshal(w.Body.Bytes(), &response)
-		require.NoError(t, err)
-		
-		assert.Equal(t, "ok", response.Status)
-		assert.Equal(t, "Remote Config System is running", response.Message)
-		assert.Equal(t, expectedHealth, response.Services)
+		assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+
+		var response models.ConfigResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "canary", response.Tag)
 
 		mockService.AssertExpectations(t)
+		mockService.AssertNotCalled(t, "GetConfiguration", "test-org", "test-app", "prod")
 	})
-}
 
-func TestConfigHandler_GetConfigHistory(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-
-	t.Run("successful history retrieval", func(t *testing.T) {
-		// Setup mock service
+	t.Run("API key config by unknown tag", func(t *testing.T) {
 		mockService := &testutil.MockConfigService{}
-		
-		// Mock the GetConfigurationHistory method (we need to add this to our mock)
-		expectedHistory := &models.PaginatedResponse{
-			Data: []models.ConfigVersion{
-				{
-					ID:      uuid.New(),
-					Version: 2,
-					IsActive: true,
-					CreatedAt: time.Now(),
-				},
-				{
-					ID:      uuid.New(),
-					Version: 1,
-					IsActive: false,
-					CreatedAt: time.Now().Add(-time.Hour),
-				},
-			},
-			Page:       1,
-			PageSize:   10,
-			TotalCount: 2,
-			TotalPages: 1,
-		}
+		mockService.On("GetConfigurationByAPIKeyAndTag", testAPIKeyApp, "prod", "beta").
+			Return(nil, fmt.Errorf("tag not found: beta: %w", services.ErrNotFound))
 
-		// We'll need to add this method to our mock service
-		// For now, let's skip this test or create a simpler version
+		handler := NewConfigHandler(mockService)
 
-		// Create handler
-		_ = NewConfigHandler(mockService)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/config/prod?tag=beta", nil)
+		c.Params = gin.Params{{Key: "env", Value: "prod"}}
+		c.Set("application", testAPIKeyApp)
 
-		// Create test request
+		handler.GetConfigByAPIKey(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestConfigHandler_GetConfigAtTime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(query string) (*gin.Context, *httptest.ResponseRecorder) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/config/test-org/test-app/prod?"+query, nil)
 		c.Params = gin.Params{
 			{Key: "org", Value: "test-org"},
 			{Key: "app", Value: "test-app"},
 			{Key: "env", Value: "prod"},
 		}
+		return c, w
+	}
 
-		// For now, we'll test that the handler exists and doesn't panic
-		// In a full implementation, we'd mock the GetConfigurationHistory method
-		assert.NotPanics(t, func() {
-			// handler.GetConfigHistory(c)
-		})
+	t.Run("config active at a point in time", func(t *testing.T) {
+		at := time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)
+		mockService := &testutil.MockConfigService{}
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 3)
+		expectedConfig.At = &at
 
-		_ = expectedHistory // Use the variable to avoid unused variable error
-	})
+		mockService.On("GetConfigurationAtTime", "test-org", "test-app", "prod", at).
+			Return(expectedConfig, nil)
+
+		handler := NewConfigHandler(mockService)
+		c, w := newContext("at=2024-01-01T03:00:00Z")
+		handler.GetConfig(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.ConfigResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 3, response.Version)
+		require.NotNil(t, response.At)
+		assert.True(t, at.Equal(*response.At))
+
+		mockService.AssertExpectations(t)
+		mockService.AssertNotCalled(t, "GetConfiguration", "test-org", "test-app", "prod")
+	})
+
+	t.Run("nothing active yet", func(t *testing.T) {
+		at := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfigurationAtTime", "test-org", "test-app", "prod", at).
+			Return(nil, fmt.Errorf("no configuration was active at 2020-01-01T00:00:00Z: %w", services.ErrNotFound))
+
+		handler := NewConfigHandler(mockService)
+		c, w := newContext("at=2020-01-01T00:00:00Z")
+		handler.GetConfig(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	for name, query := range map[string]string{
+		"invalid timestamp": "at=yesterday",
+		"combined with tag": "at=2024-01-01T00:00:00Z&tag=canary",
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockService := &testutil.MockConfigService{}
+			handler := NewConfigHandler(mockService)
+			c, w := newContext(query)
+			handler.GetConfig(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertNotCalled(t, "GetConfigurationAtTime", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			mockService.AssertNotCalled(t, "GetConfigurationByTag", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestConfigHandler_GetConfigVersionByAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, version string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/config/prod/"+version, nil)
+		c.Params = gin.Params{
+			{Key: "env", Value: "prod"},
+			{Key: "version", Value: version},
+		}
+		c.Set("application", testAPIKeyApp)
+		return c
+	}
+
+	t.Run("successful version retrieval", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 2)
+
+		mockService.On("GetConfigurationVersionByAPIKey", testAPIKeyApp, "prod", 2).
+			Return(expectedConfig, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetConfigVersionByAPIKey(newContext(w, "2"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+		var response models.ConfigResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Equal(t, 2, response.Version)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid version parameter", func(t *testing.T) {
+		for _, version := range []string{"abc", "0", "-1"} {
+			mockService := &testutil.MockConfigService{}
+			handler := NewConfigHandler(mockService)
+
+			w := httptest.NewRecorder()
+			handler.GetConfigVersionByAPIKey(newContext(w, version))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, "version %q", version)
+			mockService.AssertNotCalled(t, "GetConfigurationVersionByAPIKey", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("version not found", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfigurationVersionByAPIKey", testAPIKeyApp, "prod", 99).
+			Return(nil, fmt.Errorf("configuration version not found: %w", services.ErrNotFound))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetConfigVersionByAPIKey(newContext(w, "99"))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		var response models.ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Equal(t, "version_not_found", response.Error)
+
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestConfigHandler_GetConfigBatchByAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/config/batch", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("application", testAPIKeyApp)
+		return c
+	}
+
+	t.Run("partial failure is reported per environment", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfigurationsByAPIKey", testAPIKeyApp, []string{"dev", "prod"}, "").
+			Return(&models.BatchConfigResponse{
+				Configs: map[string]*models.ConfigResponse{
+					"prod": testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 3),
+				},
+				Errors: map[string]models.BatchConfigError{
+					"dev": {Status: http.StatusNotFound, Message: "environment not found: dev"},
+				},
+			}, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetConfigBatchByAPIKey(newContext(w, `{"environments": ["dev", "prod"]}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.BatchConfigResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Equal(t, 3, response.Configs["prod"].Version)
+		assert.Equal(t, http.StatusNotFound, response.Errors["dev"].Status)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("empty environment list", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetConfigBatchByAPIKey(newContext(w, `{"environments": []}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetConfigurationsByAPIKey", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing application in context", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/config/batch", bytes.NewBufferString(`{"environments": ["prod"]}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler.GetConfigBatchByAPIKey(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockService.AssertNotCalled(t, "GetConfigurationsByAPIKey", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigHandler_GetFlagsByAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, query string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/flags/prod"+query, nil)
+		c.Params = gin.Params{{Key: "env", Value: "prod"}}
+		c.Set("application", testAPIKeyApp)
+		return c
+	}
+
+	t.Run("requested flags with default", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		defaultValue := false
+		mockService.On("GetFlagsByAPIKey", testAPIKeyApp, "prod", []string{"a", "b"}, &defaultValue).
+			Return(&models.FlagSet{Version: 4, Flags: map[string]bool{"a": true, "b": false}}, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetFlagsByAPIKey(newContext(w, "?flags=a,%20b&default=false"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+
+		var response map[string]bool
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"a": true, "b": false}, response)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid default", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetFlagsByAPIKey(newContext(w, "?default=maybe"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetFlagsByAPIKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("environment not found", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetFlagsByAPIKey", testAPIKeyApp, "prod", []string(nil), (*bool)(nil)).
+			Return(nil, fmt.Errorf("environment not found: %w", services.ErrNotFound))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetFlagsByAPIKey(newContext(w, ""))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestConfigHandler_UpdateConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("successful config update", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+		
+		updateReq := testutil.CreateTestUpdateConfigRequest("admin")
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 2)
+		
+		mockService.On("UpdateConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.CreateConfigRequest"), false).
+			Return(expectedConfig, nil)
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request
+		reqBody, _ := json.Marshal(updateReq)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBuffer(reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		// Execute handler
+		handler.UpdateConfig(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusOK, w.Code)
+		
+		var response models.ConfigResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		
+		assert.Equal(t, expectedConfig.Version, response.Version)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid JSON request", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request with invalid JSON
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBufferString("invalid json"))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		// Execute handler
+		handler.UpdateConfig(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		
+		var response models.ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		
+		assert.Equal(t, "bad_request", response.Error)
+	})
+
+	t.Run("message too long", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		body := fmt.Sprintf(`{"config": {"timeout": 30}, "message": %q}`, strings.Repeat("a", 1001))
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		handler.UpdateConfig(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, []models.FieldError{
+			{Field: "message", Constraint: "max=1000", Message: "must be at most 1000 characters long"},
+		}, response.Fields)
+		mockService.AssertNotCalled(t, "UpdateConfiguration", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("configuration too large", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("UpdateConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.CreateConfigRequest"), false).
+			Return(nil, fmt.Errorf("configuration too large: 2048 bytes exceeds the maximum of 1024 bytes: %w", services.ErrTooLarge))
+
+		handler := NewConfigHandler(mockService)
+
+		reqBody, _ := json.Marshal(testutil.CreateTestUpdateConfigRequest("admin"))
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBuffer(reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		handler.UpdateConfig(c)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+		var response models.ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Contains(t, response.Message, "maximum of 1024 bytes")
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unchanged configuration", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 1)
+		expectedConfig.Unchanged = true
+		mockService.On("UpdateConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.CreateConfigRequest"), false).
+			Return(expectedConfig, nil)
+
+		reqBody, _ := json.Marshal(testutil.CreateTestUpdateConfigRequest("admin"))
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBuffer(reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		NewConfigHandler(mockService).UpdateConfig(c)
+
+		// Clients of every schema version can tell that no version was created
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, true, response["unchanged"])
+		assert.Equal(t, float64(1), response["version"])
+	})
+
+	t.Run("configuration with too many keys", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("UpdateConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.CreateConfigRequest"), false).
+			Return(nil, fmt.Errorf("configuration has too many keys: 10001 keys exceed the maximum of 10000: %w", services.ErrValidation))
+
+		reqBody, _ := json.Marshal(testutil.CreateTestUpdateConfigRequest("admin"))
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBuffer(reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		NewConfigHandler(mockService).UpdateConfig(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Contains(t, response.Message, "10001 keys exceed the maximum of 10000")
+	})
+
+	t.Run("concurrent update", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("UpdateConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.CreateConfigRequest"), false).
+			Return(nil, fmt.Errorf("failed to create configuration version: version conflict: another version was created at the same time: %w", services.ErrConflict))
+
+		reqBody, _ := json.Marshal(testutil.CreateTestUpdateConfigRequest("admin"))
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBuffer(reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		NewConfigHandler(mockService).UpdateConfig(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("dry run update", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+
+		updateReq := testutil.CreateTestUpdateConfigRequest("admin")
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 3)
+		expectedConfig.DryRun = true
+		expectedConfig.Diff = &models.ConfigDiff{
+			HasChanges: true,
+			Changed:    1,
+			Entries: []models.ConfigDiffEntry{
+				{Path: "timeout", Type: "changed", OldValue: 30, NewValue: 60},
+			},
+		}
+
+		mockService.On("UpdateConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.CreateConfigRequest"), true).
+			Return(expectedConfig, nil)
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request
+		reqBody, _ := json.Marshal(updateReq)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/?dry_run=true", bytes.NewBuffer(reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		// Execute handler
+		handler.UpdateConfig(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.ConfigResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.True(t, response.DryRun)
+		assert.Equal(t, 3, response.Version)
+		require.NotNil(t, response.Diff)
+		assert.Equal(t, 1, response.Diff.Changed)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid dry_run parameter", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request
+		reqBody, _ := json.Marshal(testutil.CreateTestUpdateConfigRequest("admin"))
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/?dry_run=maybe", bytes.NewBuffer(reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		// Execute handler
+		handler.UpdateConfig(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "UpdateConfiguration", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update awaiting approval", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 4)
+		expectedConfig.Pending = &models.PendingChange{ID: uuid.New(), Version: 4, Status: models.PendingStatusPending}
+
+		mockService.On("UpdateConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.CreateConfigRequest"), false).
+			Return(expectedConfig, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		reqBody, _ := json.Marshal(testutil.CreateTestUpdateConfigRequest("admin"))
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBuffer(reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		handler.UpdateConfig(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		var response models.ConfigResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.NotNil(t, response.Pending)
+		assert.Equal(t, expectedConfig.Pending.ID, response.Pending.ID)
+
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestConfigHandler_ApprovePendingChange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	changeID := uuid.New()
+
+	newContext := func(w *httptest.ResponseRecorder, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		return c
+	}
+
+	t.Run("reviewer defaults to the admin token name", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 4)
+
+		mockService.On("ApprovePendingChange", "test-org", "test-app", "prod", mock.MatchedBy(func(req *models.ReviewChangeRequest) bool {
+			return req.ChangeID == changeID && req.ReviewedBy != nil && *req.ReviewedBy == "release-bot" &&
+				req.AdminToken != nil && *req.AdminToken == "release-bot"
+		})).Return(expectedConfig, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, `{"change_id": "`+changeID.String()+`"}`)
+		c.Set("admin_token_name", "release-bot")
+		handler.ApprovePendingChange(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("admin token is passed even when a reviewer is named", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 4)
+
+		mockService.On("ApprovePendingChange", "test-org", "test-app", "prod", mock.MatchedBy(func(req *models.ReviewChangeRequest) bool {
+			return req.AdminToken != nil && *req.AdminToken == "release-bot"
+		})).Return(expectedConfig, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, `{"change_id": "`+changeID.String()+`", "reviewed_by": "alice"}`)
+		c.Set("admin_token_name", "release-bot")
+		handler.ApprovePendingChange(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("review errors", func(t *testing.T) {
+		tests := map[error]int{
+			fmt.Errorf("pending change not found: %s: %w", changeID, services.ErrNotFound):        http.StatusNotFound,
+			fmt.Errorf("pending change already reviewed: %s: %w", changeID, services.ErrConflict): http.StatusConflict,
+			fmt.Errorf("approver must differ from proposer: %w", services.ErrForbidden):              http.StatusForbidden,
+		}
+
+		for reviewErr, expectedStatus := range tests {
+			mockService := &testutil.MockConfigService{}
+			mockService.On("ApprovePendingChange", "test-org", "test-app", "prod", mock.AnythingOfType("*models.ReviewChangeRequest")).
+				Return(nil, reviewErr)
+
+			handler := NewConfigHandler(mockService)
+
+			w := httptest.NewRecorder()
+			handler.ApprovePendingChange(newContext(w, `{"change_id": "`+changeID.String()+`", "reviewed_by": "alice"}`))
+
+			assert.Equal(t, expectedStatus, w.Code, reviewErr.Error())
+		}
+	})
+
+	t.Run("missing change ID", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.ApprovePendingChange(newContext(w, `{"reviewed_by": "alice"}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ApprovePendingChange", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigHandler_PromoteConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, target, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/orgs/test-org/apps/test-app/envs/prod/promote"+target, bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		return c
+	}
+
+	t.Run("promoter defaults to the admin token name", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 5)
+
+		mockService.On("PromoteConfiguration", "test-org", "test-app", "prod", mock.MatchedBy(func(req *models.PromoteConfigRequest) bool {
+			return req.Source == "staging" && len(req.Keys) == 1 && req.CreatedBy != nil && *req.CreatedBy == "release-bot"
+		}), false).Return(expectedConfig, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, "", `{"source": "staging", "keys": ["feature_flags"]}`)
+		c.Set("admin_token_name", "release-bot")
+		handler.PromoteConfig(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("PromoteConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.PromoteConfigRequest"), true).
+			Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 5), nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.PromoteConfig(newContext(w, "?dry_run=true", `{"source": "staging"}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("promote errors", func(t *testing.T) {
+		tests := map[error]int{
+			fmt.Errorf("source environment not found: %w", services.ErrNotFound):               http.StatusNotFound,
+			fmt.Errorf("invalid promotion: key 'timeouts' is not in the source configuration: %w", services.ErrInvalidInput): http.StatusBadRequest,
+			fmt.Errorf("configuration violates rules: limits.rps must be at most 100: %w", services.ErrValidation):         http.StatusUnprocessableEntity,
+			fmt.Errorf("failed to create configuration version: connection refused"):           http.StatusInternalServerError,
+		}
+
+		for promoteErr, expectedStatus := range tests {
+			mockService := &testutil.MockConfigService{}
+			mockService.On("PromoteConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.PromoteConfigRequest"), false).
+				Return(nil, promoteErr)
+
+			handler := NewConfigHandler(mockService)
+
+			w := httptest.NewRecorder()
+			handler.PromoteConfig(newContext(w, "", `{"source": "staging"}`))
+
+			assert.Equal(t, expectedStatus, w.Code, promoteErr.Error())
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		for target, body := range map[string]string{
+			"":               `{"keys": ["feature_flags"]}`,
+			"?dry_run=maybe": `{"source": "staging"}`,
+		} {
+			w := httptest.NewRecorder()
+			handler.PromoteConfig(newContext(w, target, body))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+		mockService.AssertNotCalled(t, "PromoteConfiguration", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigHandler_SetTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	run := func(mockService *testutil.MockConfigService, tag, body string) *httptest.ResponseRecorder {
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+			{Key: "tag", Value: tag},
+		}
+		handler.SetTag(c)
+		return w
+	}
+
+	t.Run("create tag", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		tag := &models.ConfigTag{ID: uuid.New(), Name: "v2.3.0", Version: 7}
+		mockService.On("SetTag", "test-org", "test-app", "prod", "v2.3.0", mock.MatchedBy(func(req *models.SetTagRequest) bool {
+			return req.Version == 7
+		})).Return(tag, true, nil)
+
+		w := run(mockService, "v2.3.0", `{"version": 7}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("move tag", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		tag := &models.ConfigTag{ID: uuid.New(), Name: "canary", Version: 8}
+		mockService.On("SetTag", "test-org", "test-app", "prod", "canary", mock.AnythingOfType("*models.SetTagRequest")).
+			Return(tag, false, nil)
+
+		w := run(mockService, "canary", `{"version": 8}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := map[error]int{
+			fmt.Errorf(`invalid tag name: "-bad": %w`, services.ErrInvalidInput):                                                    http.StatusBadRequest,
+			fmt.Errorf("configuration version not found: version 99: %w", services.ErrNotFound):       http.StatusNotFound,
+			fmt.Errorf("target version not approved: version 9 is pending: %w", services.ErrConflict): http.StatusConflict,
+		}
+
+		for tagErr, expectedStatus := range tests {
+			mockService := &testutil.MockConfigService{}
+			mockService.On("SetTag", "test-org", "test-app", "prod", "canary", mock.AnythingOfType("*models.SetTagRequest")).
+				Return(nil, false, tagErr)
+
+			w := run(mockService, "canary", `{"version": 9}`)
+
+			assert.Equal(t, expectedStatus, w.Code, tagErr.Error())
+		}
+	})
+
+	t.Run("missing version", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+
+		w := run(mockService, "canary", `{}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "SetTag", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigHandler_ListPendingChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	run := func(mockService *testutil.MockConfigService, query string) *httptest.ResponseRecorder {
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/"+query, nil)
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		handler.ListPendingChanges(c)
+		return w
+	}
+
+	t.Run("lists open proposals by default", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		response := models.NewPaginatedResponse([]models.PendingChange{}, 1, 20, 0)
+		mockService.On("ListPendingChanges", "test-org", "test-app", "prod", models.PendingStatusPending, mock.AnythingOfType("models.PaginationParams")).
+			Return(&response, nil)
+
+		w := run(mockService, "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("all statuses", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		response := models.NewPaginatedResponse([]models.PendingChange{}, 1, 20, 0)
+		mockService.On("ListPendingChanges", "test-org", "test-app", "prod", "", mock.AnythingOfType("models.PaginationParams")).
+			Return(&response, nil)
+
+		w := run(mockService, "?status=all")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ListPendingChanges", "test-org", "test-app", "prod", "merged", mock.AnythingOfType("models.PaginationParams")).
+			Return(nil, fmt.Errorf("invalid status: merged: %w", services.ErrInvalidInput))
+
+		w := run(mockService, "?status=merged")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestConfigHandler_DiffDraftConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("successful draft diff", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+
+		draft := json.RawMessage(`{"api_timeout":60}`)
+		expectedDiff := &models.ConfigDiffResponse{
+			Organization: "test-org",
+			Application:  "test-app",
+			Environment:  "prod",
+			FromVersion:  1,
+			Diff: models.ConfigDiff{
+				HasChanges: true,
+				Changed:    1,
+				Entries: []models.ConfigDiffEntry{
+					{Path: "api_timeout", Type: "changed", OldValue: 30, NewValue: 60},
+				},
+			},
+		}
+
+		mockService.On("DiffDraftConfiguration", "test-org", "test-app", "prod", draft).
+			Return(expectedDiff, nil)
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request
+		reqBody, _ := json.Marshal(models.DiffDraftRequest{Config: draft})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/", bytes.NewBuffer(reqBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		// Execute handler
+		handler.DiffDraftConfig(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.ConfigDiffResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, response.FromVersion)
+		assert.True(t, response.Diff.HasChanges)
+		require.Len(t, response.Diff.Entries, 1)
+		assert.Equal(t, "api_timeout", response.Diff.Entries[0].Path)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("environment not found", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+		mockService.On("DiffDraftConfiguration", "test-org", "test-app", "missing", mock.Anything).
+			Return(nil, fmt.Errorf("environment not found: %w", services.ErrNotFound))
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"config":{"a":1}}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "missing"},
+		}
+
+		// Execute handler
+		handler.DiffDraftConfig(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid JSON request", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request with invalid JSON
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/", bytes.NewBufferString("invalid json"))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		// Execute handler
+		handler.DiffDraftConfig(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "DiffDraftConfiguration")
+	})
+}
+
+func TestConfigHandler_EncryptSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/orgs/test-org/apps/test-app/envs/prod/config/encrypt-secrets", nil)
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		return c
+	}
+
+	t.Run("successful encryption", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		expected := &models.EncryptSecretsResponse{
+			Organization:      "test-org",
+			Application:       "test-app",
+			Environment:       "prod",
+			SecretKeys:        []string{"db_password"},
+			VersionsScanned:   3,
+			VersionsEncrypted: 2,
+		}
+		mockService.On("EncryptExistingSecrets", "test-org", "test-app", "prod").Return(expected, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.EncryptSecrets(newContext(w))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.EncryptSecretsResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Equal(t, 2, response.VersionsEncrypted)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("encryption not configured", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("EncryptExistingSecrets", "test-org", "test-app", "prod").
+			Return(nil, fmt.Errorf("encryption is not configured: set CONFIG_ENCRYPTION_KEY"))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.EncryptSecrets(newContext(w))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestConfigHandler_SearchConfigs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("successful search", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+		results := []models.ConfigSearchResult{
+			{Organization: "test-org", Application: "test-app", Environment: "prod", Version: 3},
+		}
+		expected := models.NewPaginatedResponse(results, 1, 10, 1)
+
+		mockService.On("SearchConfigurations", "database_url", "old-host", models.PaginationParams{Page: 1, PageSize: 10}).
+			Return(&expected, nil)
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/admin/search?key=database_url&value=old-host&page_size=10", nil)
+
+		// Execute handler
+		handler.SearchConfigs(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.PaginatedResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		assert.Equal(t, 1, response.TotalCount)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("missing key and value", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/admin/search", nil)
+
+		// Execute handler
+		handler.SearchConfigs(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "SearchConfigurations", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigHandler_GetFormSchema(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/admin/orgs/test-org/apps/test-app/envs/prod/form-schema", nil)
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		return c
+	}
+
+	t.Run("successful form schema retrieval", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		expected := &models.FormSchemaResponse{
+			Organization: "test-org",
+			Application:  "test-app",
+			Environment:  "prod",
+			Version:      2,
+			Schema:       json.RawMessage(`{"type": "object", "properties": {"timeout": {"type": "integer", "default": 30}}}`),
+			Fields: []models.FormField{
+				{Path: "timeout", Type: "integer", Value: 30},
+			},
+		}
+		mockService.On("GetFormSchema", "test-org", "test-app", "prod").Return(expected, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetFormSchema(newContext(w))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.FormSchemaResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		require.Len(t, response.Fields, 1)
+		assert.Equal(t, "timeout", response.Fields[0].Path)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("schema not found", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetFormSchema", "test-org", "test-app", "prod").
+			Return(nil, fmt.Errorf("config schema not found: %w", services.ErrNotFound))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetFormSchema(newContext(w))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestConfigHandler_UpdateConfigSchema(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("invalid schema", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("SetConfigSchema", "test-org", "test-app", "prod", mock.AnythingOfType("*models.UpdateConfigSchemaRequest")).
+			Return(nil, fmt.Errorf("invalid schema: root type must be \"object\": %w", services.ErrInvalidInput))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBufferString(`{"schema": {"type": "array"}}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		handler.UpdateConfigSchema(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestConfigHandler_UpdateConfigRules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		return c
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		maxTimeout := 300.0
+		expected := &models.ConfigRuleset{
+			Rules: []models.ConfigRule{{Key: "api_timeout", Type: "int", Max: &maxTimeout}},
+		}
+		mockService.On("SetConfigRules", "test-org", "test-app", "prod", mock.MatchedBy(func(req *models.UpdateConfigRulesRequest) bool {
+			return len(req.Rules) == 1 && req.Rules[0].Key == "api_timeout" && *req.Rules[0].Max == 300
+		})).Return(expected, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.UpdateConfigRules(newContext(w, `{"rules": [{"key": "api_timeout", "type": "int", "max": 300}]}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.ConfigRuleset
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, expected.Rules, response.Rules)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid rules", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("SetConfigRules", "test-org", "test-app", "prod", mock.AnythingOfType("*models.UpdateConfigRulesRequest")).
+			Return(nil, fmt.Errorf("invalid rules: rule 1 (a): pattern requires type string: %w", services.ErrInvalidInput))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.UpdateConfigRules(newContext(w, `{"rules": [{"key": "a", "pattern": "^x"}]}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestConfigHandler_UpdateNotificationSettings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		return c
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		expected := &models.NotificationSettings{
+			EmailRecipients: []string{"ops@example.com"},
+			Events:          []string{"update", "rollback"},
+		}
+		mockService.On("SetNotificationSettings", "test-org", "test-app", "prod", mock.MatchedBy(func(req *models.UpdateNotificationSettingsRequest) bool {
+			return len(req.EmailRecipients) == 1 && len(req.Events) == 2
+		})).Return(expected, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.UpdateNotificationSettings(newContext(w, `{"email_recipients": ["ops@example.com"], "events": ["update", "rollback"]}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.NotificationSettings
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, expected.Events, response.Events)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("SetNotificationSettings", "test-org", "test-app", "prod", mock.AnythingOfType("*models.UpdateNotificationSettingsRequest")).
+			Return(nil, fmt.Errorf("invalid notification settings: slack_webhook_url must be an https URL: %w", services.ErrInvalidInput))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.UpdateNotificationSettings(newContext(w, `{"slack_webhook_url": "http://hooks.slack.com/x"}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestConfigHandler_UpdateConfigRuleViolations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &testutil.MockConfigService{}
+	mockService.On("UpdateConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.CreateConfigRequest"), false).
+		Return(nil, fmt.Errorf("configuration violates rules: api_timeout must be at most 300; database_url is required: %w", services.ErrValidation))
+
+	handler := NewConfigHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("PUT", "/", bytes.NewBufferString(`{"config": {"api_timeout": 500}}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{
+		{Key: "org", Value: "test-org"},
+		{Key: "app", Value: "test-app"},
+		{Key: "env", Value: "prod"},
+	}
+
+	handler.UpdateConfig(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var response models.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Contains(t, response.Message, "api_timeout must be at most 300")
+	assert.Contains(t, response.Message, "database_url is required")
+
+	mockService.AssertExpectations(t)
+}
+
+func TestConfigHandler_ValidateManifest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("multi-status results", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+		expected := &models.ManifestValidationResponse{
+			Valid: false,
+			Results: []models.ManifestItemResult{
+				{Organization: "test-org", Application: "test-app", Environment: "prod", Status: http.StatusOK, Valid: true, Errors: []models.ValidationIssue{}},
+				{Organization: "test-org", Application: "test-app", Environment: "dev", Status: http.StatusUnprocessableEntity, Valid: false, Errors: []models.ValidationIssue{{Check: "syntax", Message: "configuration is not valid JSON"}}},
+			},
+		}
+
+		mockService.On("ValidateManifest", mock.AnythingOfType("[]models.ManifestItem")).Return(expected)
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request
+		body := `[
+			{"org": "test-org", "app": "test-app", "env": "prod", "config": {"timeout": 30}},
+			{"org": "test-org", "app": "test-app", "env": "dev", "config": {"timeout": 60}}
+		]`
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/validate/manifest", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		// Execute handler
+		handler.ValidateManifest(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+
+		var response models.ManifestValidationResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.False(t, response.Valid)
+		require.Len(t, response.Results, 2)
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Results[1].Status)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("empty manifest", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/validate/manifest", bytes.NewBufferString("[]"))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		// Execute handler
+		handler.ValidateManifest(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ValidateManifest", mock.Anything)
+	})
+
+	t.Run("item missing required fields", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/validate/manifest", bytes.NewBufferString(`[
+			{"org": "test-org", "app": "test-app", "env": "prod", "config": {}},
+			{"org": "test-org", "config": {}}
+		]`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		// Execute handler
+		handler.ValidateManifest(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ValidateManifest", mock.Anything)
+
+		// Errors name the item that is missing fields
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		fields := make([]string, len(response.Fields))
+		for i, field := range response.Fields {
+			fields[i] = field.Field
+		}
+		assert.ElementsMatch(t, []string{"manifest[1].app", "manifest[1].env"}, fields)
+	})
+}
+
+func TestConfigHandler_HealthCheck(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("successful health check", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+		expectedHealth := map[string]string{
+			"database": "connected",
+			"cache":    "connected",
+		}
+		
+		mockService.On("HealthCheck").Return(expectedHealth)
+
+		// Create handler
+		handler := NewConfigHandler(mockService)
+
+		// Create test request
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		// Execute handler
+		handler.HealthCheck(c)
+
+		// Assert response
+		assert.Equal(t, http.StatusOK, w.Code)
+		
+		var response models.HealthResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+		
+		assert.Equal(t, "ok", response.Status)
+		assert.Equal(t, "Remote Config System is running", response.Message)
+		assert.Equal(t, expectedHealth, response.Services)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("degraded without cache", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("HealthCheck").Return(map[string]string{
+			"database": "connected",
+			"cache":    "disconnected",
+		})
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		handler.HealthCheck(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.HealthResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "degraded", response.Status)
+	})
+
+	t.Run("degraded while the cache breaker is open", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("HealthCheck").Return(map[string]string{
+			"database":      "connected",
+			"cache":         "connected",
+			"cache_breaker": "open",
+		})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		NewConfigHandler(mockService).HealthCheck(c)
+
+		var response models.HealthResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "degraded", response.Status)
+		assert.Equal(t, "open", response.Services["cache_breaker"])
+	})
+}
+
+func TestConfigHandler_GetConfigHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("successful history retrieval", func(t *testing.T) {
+		// Setup mock service
+		mockService := &testutil.MockConfigService{}
+		
+		// Mock the GetConfigurationHistory method (we need to add this to our mock)
+		expectedHistory := &models.PaginatedResponse{
+			Data: []models.ConfigVersion{
+				{
+					ID:      uuid.New(),
+					Version: 2,
+					IsActive: true,
+					CreatedAt: time.Now(),
+				},
+				{
+					ID:      uuid.New(),
+					Version: 1,
+					IsActive: false,
+					CreatedAt: time.Now().Add(-time.Hour),
+				},
+			},
+			Page:       1,
+			PageSize:   10,
+			TotalCount: 2,
+			TotalPages: 1,
+		}
+
+		// We'll need to add this method to our mock service
+		// For now, let's skip this test or create a simpler version
+
+		// Create handler
+		_ = NewConfigHandler(mockService)
+
+		// Create test request
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+
+		// For now, we'll test that the handler exists and doesn't panic
+		// In a full implementation, we'd mock the GetConfigurationHistory method
+		assert.NotPanics(t, func() {
+			// handler.GetConfigHistory(c)
+		})
+
+		_ = expectedHistory // Use the variable to avoid unused variable error
+	})
+}
+
+func TestConfigHandler_GetKeyHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, keyPath string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+			{Key: "keyPath", Value: keyPath},
+		}
+		return c
+	}
+
+	t.Run("values of a key", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetKeyHistory", "test-org", "test-app", "prod", "database.timeout").Return(&models.KeyHistoryResponse{
+			Key: "database.timeout",
+			Changes: []models.KeyHistoryEntry{
+				{Version: 1, Present: true, Value: 30},
+				{Version: 4, Present: true, Value: 60},
+			},
+		}, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetKeyHistory(newContext(w, "database.timeout"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response models.KeyHistoryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Changes, 2)
+		assert.Equal(t, 4, response.Changes[1].Version)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid key path", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		for _, keyPath := range []string{"database..timeout", ".timeout", "a,b"} {
+			w := httptest.NewRecorder()
+			handler.GetKeyHistory(newContext(w, keyPath))
+			assert.Equal(t, http.StatusBadRequest, w.Code, keyPath)
+		}
+		mockService.AssertNotCalled(t, "GetKeyHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetKeyHistory", "test-org", "test-app", "prod", "timeout").
+			Return(nil, fmt.Errorf("environment not found: %w", services.ErrNotFound))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.GetKeyHistory(newContext(w, "timeout"))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestConfigHandler_Rollouts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, method, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		return c
+	}
+
+	t.Run("API key reads pass the client ID", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfigurationByAPIKey", testAPIKeyApp, "prod", "device-42").
+			Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 8), nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/config/prod", nil)
+		c.Request.Header.Set("X-Client-ID", "device-42")
+		c.Params = gin.Params{{Key: "env", Value: "prod"}}
+		c.Set("application", testAPIKeyApp)
+
+		handler.GetConfigByAPIKey(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"8"`, w.Header().Get("ETag"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("rollout percentage out of range", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.UpdateRollout(newContext(w, "PUT", `{"percentage": 150}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "UpdateRollout", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update percentage", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("UpdateRollout", "test-org", "test-app", "prod", mock.MatchedBy(func(req *models.UpdateRolloutRequest) bool {
+			return *req.Percentage == 0 && req.UpdatedBy != nil && *req.UpdatedBy == "release-bot"
+		})).Return(&models.ConfigRollout{Version: 5, StableVersion: 4, Percentage: 0}, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, "PUT", `{"percentage": 0}`)
+		c.Set("admin_token_name", "release-bot")
+		handler.UpdateRollout(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("promote without a body", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("PromoteRollout", "test-org", "test-app", "prod", mock.AnythingOfType("*models.PromoteRolloutRequest")).
+			Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 5), nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.PromoteRollout(newContext(w, "POST", ""))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("no rollout in progress", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("AbortRollout", "test-org", "test-app", "prod", (*string)(nil)).
+			Return(fmt.Errorf("rollout not found for environment: prod: %w", services.ErrNotFound))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.AbortRollout(newContext(w, "DELETE", ""))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("concurrent promotion", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("PromoteRollout", "test-org", "test-app", "prod", mock.AnythingOfType("*models.PromoteRolloutRequest")).
+			Return(nil, fmt.Errorf("another version was created at the same time: %w", services.ErrConflict))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.PromoteRollout(newContext(w, "POST", ""))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestConfigHandler_NotFoundErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Services wrap the repository error, so the message only starts with the resource
+	notFound := fmt.Errorf("environment not found: %w", services.ErrNotFound)
+	params := mock.AnythingOfType("models.PaginationParams")
+
+	newContext := func(w *httptest.ResponseRecorder, method, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/admin/orgs/test-org/apps/test-app/envs/qa", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "qa"},
+		}
+		return c
+	}
+
+	t.Run("rollback", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("RollbackConfiguration", "test-org", "test-app", "qa", mock.AnythingOfType("*models.RollbackRequest")).
+			Return(nil, notFound)
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).RollbackConfig(newContext(w, "POST", `{"to_version": 1}`))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("rollback to the active version", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("RollbackConfiguration", "test-org", "test-app", "qa", mock.AnythingOfType("*models.RollbackRequest")).
+			Return(nil, fmt.Errorf("invalid rollback: version 3 is already active: %w", services.ErrInvalidInput))
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).RollbackConfig(newContext(w, "POST", `{"to_version": 3}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("rollback to a tag", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("RollbackConfiguration", "test-org", "test-app", "qa", &models.RollbackRequest{ToTag: "last-known-good"}).
+			Return(nil, notFound)
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).RollbackConfig(newContext(w, "POST", `{"to_tag": "last-known-good"}`))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("rollback needs exactly one target", func(t *testing.T) {
+		for _, body := range []string{`{}`, `{"to_version": 2, "to_tag": "last-known-good"}`} {
+			w := httptest.NewRecorder()
+			NewConfigHandler(&testutil.MockConfigService{}).RollbackConfig(newContext(w, "POST", body))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+			assert.Contains(t, w.Body.String(), "exactly one of to_version and to_tag", body)
+		}
+	})
+
+	t.Run("history", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfigurationHistory", "test-org", "test-app", "qa", params).Return(nil, notFound)
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).GetConfigHistory(newContext(w, "GET", ""))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("change log", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfigurationChanges", "test-org", "test-app", "qa", params).Return(nil, notFound)
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).GetConfigChanges(newContext(w, "GET", ""))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("history pruning", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("PruneHistory", "test-org", "test-app", "qa").Return(nil, notFound)
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).PruneHistory(newContext(w, "POST", ""))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("other failures stay internal errors", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfigurationHistory", "test-org", "test-app", "qa", params).
+			Return(nil, fmt.Errorf("failed to list config versions: connection refused"))
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).GetConfigHistory(newContext(w, "GET", ""))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestConfigHandler_LockedEnvironment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	locked := fmt.Errorf("environment prod is locked by alice: release freeze: %w", services.ErrLocked)
+
+	newContext := func(w *httptest.ResponseRecorder, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/orgs/test-org/apps/test-app/envs/prod", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		return c
+	}
+
+	t.Run("update", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("UpdateConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.CreateConfigRequest"), false).
+			Return(nil, locked)
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).UpdateConfig(newContext(w, `{"config": {"theme": "dark"}}`))
+
+		assert.Equal(t, http.StatusLocked, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Contains(t, response.Message, "release freeze")
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("RollbackConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.RollbackRequest")).
+			Return(nil, locked)
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).RollbackConfig(newContext(w, `{"to_version": 1}`))
+
+		assert.Equal(t, http.StatusLocked, w.Code)
+	})
+
+	t.Run("promote", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("PromoteConfiguration", "test-org", "test-app", "prod", mock.AnythingOfType("*models.PromoteConfigRequest"), false).
+			Return(nil, locked)
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).PromoteConfig(newContext(w, `{"source": "staging"}`))
+
+		assert.Equal(t, http.StatusLocked, w.Code)
+	})
+
+	t.Run("approve", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ApprovePendingChange", "test-org", "test-app", "prod", mock.AnythingOfType("*models.ReviewChangeRequest")).
+			Return(nil, locked)
+
+		w := httptest.NewRecorder()
+		NewConfigHandler(mockService).ApprovePendingChange(newContext(w, `{"change_id": "`+uuid.New().String()+`"}`))
+
+		assert.Equal(t, http.StatusLocked, w.Code)
+	})
+}
+
+func TestConfigHandler_SetTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	run := func(mockService *testutil.MockConfigService, body string) *httptest.ResponseRecorder {
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "template", Value: "web-service"},
+		}
+		c.Set("admin_token_name", "platform-team")
+		handler.SetTemplate(c)
+		return w
+	}
+
+	t.Run("create template", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		template := &models.ConfigTemplate{ID: uuid.New(), Name: "web-service", Variables: []string{"db_host"}}
+		mockService.On("SetTemplate", "test-org", "web-service", mock.MatchedBy(func(req *models.SetConfigTemplateRequest) bool {
+			return req.UpdatedBy != nil && *req.UpdatedBy == "platform-team"
+		})).Return(template, true, nil)
+
+		w := run(mockService, `{"template": {"db": {"host": "{{db_host}}"}}}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("update template", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("SetTemplate", "test-org", "web-service", mock.AnythingOfType("*models.SetConfigTemplateRequest")).
+			Return(&models.ConfigTemplate{ID: uuid.New(), Name: "web-service"}, false, nil)
+
+		w := run(mockService, `{"template": {}}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := map[error]int{
+			fmt.Errorf(`invalid template name: "-bad": %w`, services.ErrInvalidInput):                               http.StatusBadRequest,
+			fmt.Errorf("invalid template: must be a JSON object: %w", services.ErrInvalidInput):                     http.StatusBadRequest,
+			fmt.Errorf("configuration too large: 2000000 bytes exceeds the limit: %w", services.ErrTooLarge):    http.StatusRequestEntityTooLarge,
+			fmt.Errorf("organization not found: no rows: %w", services.ErrNotFound):   http.StatusNotFound,
+			fmt.Errorf("failed to create configuration template: connection refused"): http.StatusInternalServerError,
+		}
+
+		for templateErr, expectedStatus := range tests {
+			mockService := &testutil.MockConfigService{}
+			mockService.On("SetTemplate", "test-org", "web-service", mock.AnythingOfType("*models.SetConfigTemplateRequest")).
+				Return(nil, false, templateErr)
+
+			w := run(mockService, `{"template": {}}`)
+
+			assert.Equal(t, expectedStatus, w.Code, templateErr.Error())
+		}
+	})
+
+	t.Run("missing template", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+
+		w := run(mockService, `{"description": "Base web service"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "SetTemplate", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigHandler_CreateConfigFromTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, target, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/orgs/test-org/apps/test-app/envs/prod/config/from-template"+target, bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		return c
+	}
+
+	t.Run("creator defaults to the admin token name", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		expectedConfig := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 3)
+
+		mockService.On("InstantiateTemplate", "test-org", "test-app", "prod", mock.MatchedBy(func(req *models.InstantiateTemplateRequest) bool {
+			return req.Template == "web-service" && string(req.Variables["db_host"]) == `"prod-db"` && req.CreatedBy != nil && *req.CreatedBy == "platform-team"
+		}), false).Return(expectedConfig, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, "", `{"template": "web-service", "variables": {"db_host": "prod-db"}}`)
+		c.Set("admin_token_name", "platform-team")
+		handler.CreateConfigFromTemplate(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("instantiate errors", func(t *testing.T) {
+		tests := map[error]int{
+			fmt.Errorf("template not found: web-service: %w", services.ErrNotFound):     http.StatusNotFound,
+			fmt.Errorf("invalid template variables: missing db_host: %w", services.ErrValidation):                   http.StatusUnprocessableEntity,
+			fmt.Errorf("configuration violates rules: limits.rps must be at most 100: %w", services.ErrValidation):  http.StatusUnprocessableEntity,
+			fmt.Errorf("environment is locked: release freeze: %w", services.ErrLocked): http.StatusLocked,
+			fmt.Errorf("failed to create configuration version: connection refused"):    http.StatusInternalServerError,
+		}
+
+		for instantiateErr, expectedStatus := range tests {
+			mockService := &testutil.MockConfigService{}
+			mockService.On("InstantiateTemplate", "test-org", "test-app", "prod", mock.AnythingOfType("*models.InstantiateTemplateRequest"), false).
+				Return(nil, instantiateErr)
+
+			handler := NewConfigHandler(mockService)
+
+			w := httptest.NewRecorder()
+			handler.CreateConfigFromTemplate(newContext(w, "", `{"template": "web-service"}`))
+
+			assert.Equal(t, expectedStatus, w.Code, instantiateErr.Error())
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		for target, body := range map[string]string{
+			"":               `{"variables": {"db_host": "prod-db"}}`,
+			"?dry_run=maybe": `{"template": "web-service"}`,
+		} {
+			w := httptest.NewRecorder()
+			handler.CreateConfigFromTemplate(newContext(w, target, body))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+		mockService.AssertNotCalled(t, "InstantiateTemplate", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigHandler_UpdateConfigBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, target, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/orgs/test-org/apps/test-app/config/batch-update"+target, bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+		}
+		return c
+	}
+
+	t.Run("every environment is updated", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		result := &models.BatchConfigResponse{
+			Configs: map[string]*models.ConfigResponse{
+				"dev":  testutil.CreateTestConfigResponse("test-org", "test-app", "dev", 4),
+				"prod": testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 9),
+			},
+			Errors: map[string]models.BatchConfigError{},
+		}
+		mockService.On("UpdateConfigurations", "test-org", "test-app", mock.MatchedBy(func(req *models.BatchUpdateConfigRequest) bool {
+			return len(req.Configs) == 2 && req.CreatedBy != nil && *req.CreatedBy == "release-bot"
+		}), false).Return(result, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, "", `{"configs": {"dev": {"checkout": true}, "prod": {"checkout": true}}}`)
+		c.Set("admin_token_name", "release-bot")
+		handler.UpdateConfigBatch(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"configs":{"dev":`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("failed batch is multi-status", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		result := &models.BatchConfigResponse{
+			Configs: map[string]*models.ConfigResponse{},
+			Errors: map[string]models.BatchConfigError{
+				"dev":  {Status: http.StatusFailedDependency, Message: "not updated: another environment failed, so no environment was updated"},
+				"prod": {Status: http.StatusLocked, Message: "environment prod is locked"},
+			},
+		}
+		mockService.On("UpdateConfigurations", "test-org", "test-app", mock.AnythingOfType("*models.BatchUpdateConfigRequest"), true).
+			Return(result, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.UpdateConfigBatch(newContext(w, "?dry_run=true", `{"configs": {"dev": {}, "prod": {}}}`))
+
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("update errors", func(t *testing.T) {
+		tests := map[error]int{
+			fmt.Errorf("application not found: %w", services.ErrNotFound):                    http.StatusNotFound,
+			fmt.Errorf("failed to update configurations: failed to commit transaction: EOF"): http.StatusInternalServerError,
+		}
+
+		for updateErr, expectedStatus := range tests {
+			mockService := &testutil.MockConfigService{}
+			mockService.On("UpdateConfigurations", "test-org", "test-app", mock.AnythingOfType("*models.BatchUpdateConfigRequest"), false).
+				Return(nil, updateErr)
+
+			handler := NewConfigHandler(mockService)
+
+			w := httptest.NewRecorder()
+			handler.UpdateConfigBatch(newContext(w, "", `{"configs": {"dev": {}}}`))
+
+			assert.Equal(t, expectedStatus, w.Code, updateErr.Error())
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		for target, body := range map[string]string{
+			"":               `{"configs": {}}`,
+			"?":              `{"created_by": "release-bot"}`,
+			"?dry_run=maybe": `{"configs": {"dev": {}}}`,
+		} {
+			w := httptest.NewRecorder()
+			handler.UpdateConfigBatch(newContext(w, target, body))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+		mockService.AssertNotCalled(t, "UpdateConfigurations", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigHandler_UpdateConfigBatchBySelector(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, appSlug, target, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/orgs/test-org/config/batch-update"+target, bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "org", Value: "test-org"}}
+		if appSlug != "" {
+			c.Params = append(c.Params, gin.Param{Key: "app", Value: appSlug})
+		}
+		return c
+	}
+
+	t.Run("environments picked across applications", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		result := &models.BatchConfigResponse{
+			Configs: map[string]*models.ConfigResponse{
+				"api/canary": testutil.CreateTestConfigResponse("test-org", "api", "canary", 2),
+				"web/canary": testutil.CreateTestConfigResponse("test-org", "web", "canary", 7),
+			},
+			Errors: map[string]models.BatchConfigError{},
+		}
+		mockService.On("UpdateConfigurationsBySelector", "test-org", "", "tier=canary", mock.MatchedBy(func(req *models.BatchUpdateConfigRequest) bool {
+			return string(req.Config) == `{"checkout": true}`
+		}), false).Return(result, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.UpdateConfigBatch(newContext(w, "", "?selector=tier=canary", `{"config": {"checkout": true}}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"web/canary":`)
+		mockService.AssertExpectations(t)
+		mockService.AssertNotCalled(t, "UpdateConfigurations", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("selector errors", func(t *testing.T) {
+		tests := map[error]int{
+			fmt.Errorf("no environments match the label selector \"tier=canary\": %w", services.ErrNotFound): http.StatusNotFound,
+			fmt.Errorf("invalid label selector: \"tier==canary\" has an invalid value: %w", services.ErrInvalidInput):                      http.StatusBadRequest,
+		}
+
+		for updateErr, expectedStatus := range tests {
+			mockService := &testutil.MockConfigService{}
+			mockService.On("UpdateConfigurationsBySelector", "test-org", "web", "tier=canary", mock.AnythingOfType("*models.BatchUpdateConfigRequest"), false).
+				Return(nil, updateErr)
+
+			handler := NewConfigHandler(mockService)
+
+			w := httptest.NewRecorder()
+			handler.UpdateConfigBatch(newContext(w, "web", "?selector=tier=canary", `{"config": {}}`))
+
+			assert.Equal(t, expectedStatus, w.Code, updateErr.Error())
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		for _, request := range []struct{ appSlug, target, body string }{
+			{"", "", `{"config": {}}`},
+			{"", "?selector=tier=canary", `{"configs": {"dev": {}}}`},
+			{"web", "?selector=tier=canary", `{"config": {}, "configs": {"dev": {}}}`},
+		} {
+			w := httptest.NewRecorder()
+			handler.UpdateConfigBatch(newContext(w, request.appSlug, request.target, request.body))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, request.body)
+		}
+		mockService.AssertNotCalled(t, "UpdateConfigurationsBySelector", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigHandler_DownloadConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	download := func(handler *ConfigHandler, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/admin/orgs/test-org/apps/test-app/envs/prod/config/download"+query, nil)
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		handler.DownloadConfig(c)
+		return w
+	}
+
+	t.Run("json attachment", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfiguration", "test-org", "test-app", "prod").
+			Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 7), nil)
+
+		w := download(NewConfigHandler(mockService), "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `attachment; filename="test-org-test-app-prod-v7.json"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+		// The file holds the configuration document alone, indented
+		var document map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+		assert.Equal(t, true, document["debug"])
+		assert.NotContains(t, document, "version")
+		assert.Contains(t, w.Body.String(), "\n  \"debug\": true")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("yaml of the raw configuration", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetRawConfiguration", "test-org", "test-app", "prod").
+			Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 2), nil)
+
+		w := download(NewConfigHandler(mockService), "?raw=true&format=yaml")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `attachment; filename="test-org-test-app-prod-v2.yaml"`, w.Header().Get("Content-Disposition"))
+		assert.Contains(t, w.Body.String(), "debug: true\n")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		assert.Equal(t, http.StatusBadRequest, download(handler, "?format=xml").Code)
+		assert.Equal(t, http.StatusBadRequest, download(handler, "?raw=maybe").Code)
+		mockService.AssertNotCalled(t, "GetConfiguration", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfiguration", "test-org", "test-app", "prod").
+			Return(nil, fmt.Errorf("environment not found: %w", services.ErrNotFound))
+
+		w := download(NewConfigHandler(mockService), "")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+	})
+}
+
+func TestConfigHandler_CompareAndSwapConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	swap := func(handler *ConfigHandler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/orgs/test-org/apps/test-app/envs/prod/config/cas", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		c.Set("admin_token_name", "ci")
+		handler.CompareAndSwapConfig(c)
+		return w
+	}
+
+	t.Run("swapped", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("CompareAndSwapKey", "test-org", "test-app", "prod", mock.MatchedBy(func(req *models.CompareAndSwapRequest) bool {
+			return req.Key == "leader.holder" && string(req.Expected) == `"worker-1"` && string(req.Value) == `"worker-2"` &&
+				req.CreatedBy != nil && *req.CreatedBy == "ci"
+		})).Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 4), nil)
+
+		w := swap(NewConfigHandler(mockService), `{"key": "leader.holder", "expected": "worker-1", "value": "worker-2"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("expected value omitted", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("CompareAndSwapKey", "test-org", "test-app", "prod", mock.MatchedBy(func(req *models.CompareAndSwapRequest) bool {
+			return req.Expected == nil
+		})).Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 1), nil)
+
+		w := swap(NewConfigHandler(mockService), `{"key": "leader", "value": "worker-1"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("CompareAndSwapKey", "test-org", "test-app", "prod", mock.AnythingOfType("*models.CompareAndSwapRequest")).
+			Return(nil, fmt.Errorf("compare-and-swap failed: leader does not have the expected value: %w", services.ErrConflict))
+
+		w := swap(NewConfigHandler(mockService), `{"key": "leader", "expected": "worker-1", "value": "worker-2"}`)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "compare_and_swap_failed")
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("CompareAndSwapKey", "test-org", "test-app", "prod", mock.AnythingOfType("*models.CompareAndSwapRequest")).
+			Return(nil, fmt.Errorf("invalid compare-and-swap: leader is not an object: %w", services.ErrInvalidInput))
+
+		w := swap(NewConfigHandler(mockService), `{"key": "leader.holder", "value": "worker-2"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("missing fields", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		assert.Equal(t, http.StatusBadRequest, swap(handler, `{"value": true}`).Code)
+		assert.Equal(t, http.StatusBadRequest, swap(handler, `{"key": "leader"}`).Code)
+		mockService.AssertNotCalled(t, "CompareAndSwapKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestConfigHandler_ExportConfigChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	export := func(handler *ConfigHandler, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/admin/orgs/test-org/apps/test-app/envs/prod/changes/export"+query, nil)
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		handler.ExportConfigChanges(c)
+		return w
+	}
+
+	env := &models.Environment{Slug: "prod", Application: &models.Application{Slug: "test-app", Organization: &models.Organization{Slug: "test-org"}}}
+	from := 1
+	author := "alice@example.com"
+	message := "=HYPERLINK(\"http://example.com\")"
+	changes := []models.ConfigChange{
+		{VersionTo: 1, Action: "update", Scope: models.ChangeScopeEnvironment, CreatedAt: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC), Environment: env},
+		{VersionFrom: &from, VersionTo: 2, Action: "update", Scope: models.ChangeScopeEnvironment, CreatedAt: time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC), CreatedBy: &author, Message: &message, Environment: env},
+	}
+
+	t.Run("csv rows", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ExportConfigurationChanges", "test-org", "test-app", "prod", false).Return(changes, nil)
+
+		w := export(NewConfigHandler(mockService), "?format=csv")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="test-org-test-app-prod-changes.csv"`, w.Header().Get("Content-Disposition"))
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, []string{"created_at", "organization", "application", "environment", "scope", "action", "version_from", "version_to", "created_by", "approved_by", "message"}, records[0])
+		assert.Equal(t, []string{"2024-03-01T09:30:00Z", "test-org", "test-app", "prod", "environment", "update", "", "1", "", "", ""}, records[1])
+		assert.Equal(t, []string{"2024-03-02T10:00:00Z", "test-org", "test-app", "prod", "environment", "update", "1", "2", "alice@example.com", "", "'" + message}, records[2])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("organization scope", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ExportConfigurationChanges", "test-org", "test-app", "prod", true).Return(nil, nil)
+
+		w := export(NewConfigHandler(mockService), "?scope=org")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `attachment; filename="test-org-changes.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "created_at,organization,application,environment,scope,action,version_from,version_to,created_by,approved_by,message\n", w.Body.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		assert.Equal(t, http.StatusBadRequest, export(handler, "?format=xlsx").Code)
+		assert.Equal(t, http.StatusBadRequest, export(handler, "?scope=app").Code)
+		mockService.AssertNotCalled(t, "ExportConfigurationChanges", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ExportConfigurationChanges", "test-org", "test-app", "prod", false).
+			Return(nil, fmt.Errorf("environment not found: %w", services.ErrNotFound))
+
+		w := export(NewConfigHandler(mockService), "")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+	})
+}
+
+func TestConfigHandler_LintConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/orgs/test-org/apps/test-app/envs/prod/config/lint", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		return c
+	}
+
+	t.Run("warnings are returned", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("LintConfiguration", "test-org", "test-app", "prod", json.RawMessage(`{"password": "CHANGEME"}`)).
+			Return(&models.ConfigLintResponse{
+				Environment: "prod",
+				Production:  true,
+				Errors:      1,
+				Warnings: []models.LintWarning{
+					{Check: "placeholder", Severity: "error", Path: "password", Message: `value contains the placeholder "CHANGEME"`},
+				},
+			}, nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.LintConfig(newContext(w, `{"config": {"password": "CHANGEME"}}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.ConfigLintResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Errors)
+		require.Len(t, response.Warnings, 1)
+		assert.Equal(t, "password", response.Warnings[0].Path)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("missing config", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.LintConfig(newContext(w, `{}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "LintConfiguration", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("LintConfiguration", "test-org", "test-app", "prod", mock.Anything).
+			Return(nil, fmt.Errorf("environment not found: %w", services.ErrNotFound))
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		handler.LintConfig(newContext(w, `{"config": {}}`))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "lint_failed")
+	})
+}
+
+func TestConfigHandler_LastModified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	createdAt := time.Date(2024, 3, 1, 12, 30, 15, 500_000_000, time.UTC)
+	lastModified := "Fri, 01 Mar 2024 12:30:15 GMT"
+
+	run := func(config *models.ConfigResponse, headers map[string]string) *httptest.ResponseRecorder {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfiguration", "test-org", "test-app", "prod").Return(config, nil)
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/config/test-org/test-app/prod", nil)
+		for name, value := range headers {
+			c.Request.Header.Set(name, value)
+		}
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		handler.GetConfig(c)
+		c.Writer.WriteHeaderNow()
+		return w
+	}
+
+	newConfig := func() *models.ConfigResponse {
+		config := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 3)
+		config.UpdatedAt = createdAt
+		return config
+	}
+
+	t.Run("date of the version, to the second", func(t *testing.T) {
+		w := run(newConfig(), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, lastModified, w.Header().Get("Last-Modified"))
+	})
+
+	t.Run("matching If-Modified-Since", func(t *testing.T) {
+		w := run(newConfig(), map[string]string{"If-Modified-Since": lastModified})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Equal(t, lastModified, w.Header().Get("Last-Modified"))
+	})
+
+	t.Run("other dates are served in full", func(t *testing.T) {
+		// A later date means the client has a version that was rolled back
+		for _, since := range []string{"Fri, 01 Mar 2024 12:30:14 GMT", "Sat, 02 Mar 2024 00:00:00 GMT", "yesterday"} {
+			w := run(newConfig(), map[string]string{"If-Modified-Since": since})
+			assert.Equal(t, http.StatusOK, w.Code, since)
+		}
+	})
+
+	t.Run("If-None-Match takes precedence", func(t *testing.T) {
+		w := run(newConfig(), map[string]string{"If-None-Match": `"2"`, "If-Modified-Since": lastModified})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = run(newConfig(), map[string]string{"If-None-Match": `"3"`, "If-Modified-Since": "Sat, 02 Mar 2024 00:00:00 GMT"})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("merged configurations have no date", func(t *testing.T) {
+		config := newConfig()
+		config.DefaultsVersion = 2
+		w := run(config, map[string]string{"If-Modified-Since": lastModified})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Last-Modified"))
+		assert.Equal(t, `"3-2"`, w.Header().Get("ETag"))
+	})
+}
+
+func TestConfigHandler_CorruptConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	corrupt := &db.CorruptConfigError{Organization: "test-org", Application: "test-app", Environment: "prod", Version: 4}
+	mockService := &testutil.MockConfigService{}
+	mockService.On("GetConfiguration", "test-org", "test-app", "prod").Return(nil, corrupt)
+	handler := NewConfigHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/config/test-org/test-app/prod", nil)
+	c.Params = gin.Params{
+		{Key: "org", Value: "test-org"},
+		{Key: "app", Value: "test-app"},
+		{Key: "env", Value: "prod"},
+	}
+	handler.GetConfig(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var response models.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "corrupt_config", response.Error)
+	assert.Contains(t, response.Message, "'test-org/test-app/prod' version 4")
+}
+
+func TestConfigHandler_GetConfigMasksSecrets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	config := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 2)
+	config.Config = json.RawMessage(`{"db_password": "hunter2", "timeout": 30}`)
+	config.SecretKeys = []string{"db_password"}
+	mockService := &testutil.MockConfigService{}
+	mockService.On("GetConfiguration", "test-org", "test-app", "prod").Return(config, nil)
+	handler := NewConfigHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/config/test-org/test-app/prod", nil)
+	c.Params = gin.Params{
+		{Key: "org", Value: "test-org"},
+		{Key: "app", Value: "test-app"},
+		{Key: "env", Value: "prod"},
+	}
+	handler.GetConfig(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "hunter2")
+	var response models.ConfigResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.JSONEq(t, `{"db_password": "<encrypted>", "timeout": 30}`, string(response.Config))
 }
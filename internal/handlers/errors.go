@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"remote-config-system/internal/services"
+)
+
+// statusForError returns the HTTP status describing a configuration service error. Errors
+// of no known kind are server errors.
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrLocked):
+		return http.StatusLocked
+	case errors.Is(err, services.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, services.ErrValidation):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
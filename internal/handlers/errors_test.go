@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"remote-config-system/internal/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusForError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{fmt.Errorf("label not found: tier: %w", services.ErrNotFound), http.StatusNotFound},
+		{fmt.Errorf("approver must differ from proposer: %w", services.ErrForbidden), http.StatusForbidden},
+		{fmt.Errorf("environment prod is locked: %w", services.ErrLocked), http.StatusLocked},
+		{&services.AlreadyExistsError{Resource: "organization", Slug: "acme"}, http.StatusConflict},
+		{fmt.Errorf("invalid label key: %w", services.ErrInvalidInput), http.StatusBadRequest},
+		{fmt.Errorf("configuration too large: %w", services.ErrTooLarge), http.StatusRequestEntityTooLarge},
+		{fmt.Errorf("configuration has too many keys: %w", services.ErrValidation), http.StatusUnprocessableEntity},
+		// The message does not decide the status, only the kind of error
+		{fmt.Errorf("configuration too large"), http.StatusInternalServerError},
+		{fmt.Errorf("failed to set label: EOF"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.status, statusForError(tt.err), tt.err.Error())
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFields limits how many paths a fields parameter may list
+const maxFields = 100
+
+// parseFields splits a comma-separated fields parameter into dot-notated paths. Empty
+// entries and paths with empty segments are dropped.
+func parseFields(value string) []string {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || strings.HasPrefix(field, ".") || strings.HasSuffix(field, ".") || strings.Contains(field, "..") {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// projectConfig reduces a configuration document to the given dot-notated paths. Paths
+// only descend through objects; paths that do not exist are ignored.
+func projectConfig(document json.RawMessage, fields []string) (json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var root interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+
+	projected := map[string]interface{}{}
+	for _, field := range fields {
+		if value, ok := lookupPath(root, field); ok {
+			setPath(projected, field, value)
+		}
+	}
+	return json.Marshal(projected)
+}
+
+// lookupPath resolves a dot-notated path through nested objects
+func lookupPath(root interface{}, path string) (interface{}, bool) {
+	value := root
+	for _, part := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// setPath stores a value at a dot-notated path, creating the objects along the way. A
+// path inside a value that was already projected whole leaves it unchanged.
+func setPath(root map[string]interface{}, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	object := root
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := object[part].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			object[part] = next
+		}
+		object = next
+	}
+	object[parts[len(parts)-1]] = value
+}
+
+// applyFields projects a configuration response down to the paths of the fields query
+// parameter, if there is one. The response is copied, so cached responses are never
+// modified. It writes a 400 or 500 response and returns false if projection fails.
+func applyFields(c *gin.Context, config *models.ConfigResponse) (*models.ConfigResponse, bool) {
+	value, ok := c.GetQuery("fields")
+	if !ok {
+		return config, true
+	}
+
+	fields := parseFields(value)
+	if len(fields) > maxFields {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   fmt.Sprintf("At most %d fields can be requested", maxFields),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return nil, false
+	}
+
+	document, err := projectConfig(config.Config, fields)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to select configuration fields: " + err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return nil, false
+	}
+
+	projected := *config
+	projected.Config = document
+	return &projected, true
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"remote-config-system/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFields(t *testing.T) {
+	assert.Equal(t, []string{"a", "b.c", "d"}, parseFields(" a, b.c ,,d"))
+	assert.Equal(t, []string{"ok"}, parseFields(".a,a.,a..b,ok"))
+	assert.Empty(t, parseFields(""))
+}
+
+func TestProjectConfig(t *testing.T) {
+	document := json.RawMessage(`{
+		"api_timeout": 30,
+		"ratio": 0.1234567890123456789,
+		"database": {"host": "db.internal", "port": 5432, "pool": {"size": 10, "idle": 2}},
+		"features": ["search", "checkout"],
+		"theme": null
+	}`)
+
+	tests := map[string]struct {
+		fields   []string
+		expected string
+	}{
+		"top-level keys":        {[]string{"api_timeout", "features"}, `{"api_timeout": 30, "features": ["search", "checkout"]}`},
+		"nested key":            {[]string{"database.pool.size"}, `{"database": {"pool": {"size": 10}}}`},
+		"siblings are merged":   {[]string{"database.host", "database.pool.idle"}, `{"database": {"host": "db.internal", "pool": {"idle": 2}}}`},
+		"whole object and path": {[]string{"database.pool", "database.pool.size"}, `{"database": {"pool": {"size": 10, "idle": 2}}}`},
+		"path then whole":       {[]string{"database.pool.size", "database.pool"}, `{"database": {"pool": {"size": 10, "idle": 2}}}`},
+		"null values are kept":  {[]string{"theme"}, `{"theme": null}`},
+		"unknown paths ignored": {[]string{"missing", "database.missing", "features.0", "api_timeout.x"}, `{}`},
+		"no fields":             {nil, `{}`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			projected, err := projectConfig(document, tt.fields)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(projected))
+		})
+	}
+
+	t.Run("numbers keep their precision", func(t *testing.T) {
+		projected, err := projectConfig(document, []string{"ratio"})
+		require.NoError(t, err)
+		assert.Equal(t, `{"ratio":0.1234567890123456789}`, string(projected))
+	})
+
+	t.Run("documents that are not objects project to nothing", func(t *testing.T) {
+		projected, err := projectConfig(json.RawMessage(`[1, 2]`), []string{"a"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{}`, string(projected))
+	})
+}
+
+func TestConfigHandler_GetConfigFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	config := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 2)
+	config.Config = json.RawMessage(`{"api_timeout": 30, "database": {"host": "db.internal", "port": 5432}}`)
+	original := string(config.Config)
+
+	mockService := &testutil.MockConfigService{}
+	mockService.On("GetConfiguration", "test-org", "test-app", "prod").Return(config, nil)
+
+	handler := NewConfigHandler(mockService)
+	router := gin.New()
+	router.GET("/config/:org/:app/:env", handler.GetConfig)
+
+	get := func(target, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", target, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("projects the configuration", func(t *testing.T) {
+		w := get("/config/test-org/test-app/prod?fields=database.port,missing", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Version int             `json:"version"`
+			Config  json.RawMessage `json:"config"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.Version)
+		assert.JSONEq(t, `{"database": {"port": 5432}}`, string(response.Config))
+
+		// The service's response, which may be cached, is left alone
+		assert.Equal(t, original, string(config.Config))
+	})
+
+	t.Run("env output is projected too", func(t *testing.T) {
+		w := get("/config/test-org/test-app/prod?format=env&fields=api_timeout", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "API_TIMEOUT=30\n", w.Body.String())
+	})
+
+	t.Run("without fields the whole configuration is returned", func(t *testing.T) {
+		w := get("/config/test-org/test-app/prod", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "db.internal")
+	})
+
+	t.Run("too many fields", func(t *testing.T) {
+		fields := strings.Repeat("a,", maxFields) + "b"
+		w := get("/config/test-org/test-app/prod?fields="+fields, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
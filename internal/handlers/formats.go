@@ -0,0 +1,526 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"remote-config-system/internal/middleware"
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Configuration document formats
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+	formatTOML = "toml"
+	formatEnv  = "env"
+)
+
+// formatMediaTypes maps supported media types to formats
+var formatMediaTypes = map[string]string{
+	"application/json":   formatJSON,
+	"application/yaml":   formatYAML,
+	"application/x-yaml": formatYAML,
+	"text/yaml":          formatYAML,
+	"application/toml":   formatTOML,
+	"text/plain":         formatEnv,
+}
+
+// formatContentTypes are the content types responses are sent with
+var formatContentTypes = map[string]string{
+	formatJSON: "application/json; charset=utf-8",
+	formatYAML: "application/yaml; charset=utf-8",
+	formatTOML: "application/toml; charset=utf-8",
+	formatEnv:  "text/plain; charset=utf-8",
+}
+
+// formatExtensions are the file name extensions of downloaded configurations
+var formatExtensions = map[string]string{
+	formatJSON: "json",
+	formatYAML: "yaml",
+	formatTOML: "toml",
+	formatEnv:  "env",
+}
+
+// supportedMediaTypes lists the media types in error messages
+const supportedMediaTypes = "application/json, application/yaml, application/toml or text/plain"
+
+// supportedRequestMediaTypes lists the media types request bodies can be sent as
+const supportedRequestMediaTypes = "application/json, application/yaml or application/toml"
+
+// negotiateFormat picks the response format from an Accept header. JSON is the default
+// and is also used for wildcards. The first supported type wins; quality values are not
+// weighed. It reports false if none of the accepted types is supported.
+func negotiateFormat(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return formatJSON, true
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.ToLower(strings.TrimSpace(strings.Split(part, ";")[0]))
+		if mediaType == "*/*" || mediaType == "application/*" {
+			return formatJSON, true
+		}
+		if format, ok := formatMediaTypes[mediaType]; ok {
+			return format, true
+		}
+	}
+
+	return "", false
+}
+
+// requestFormat determines the format of a request body from its Content-Type header.
+// A missing Content-Type is treated as JSON.
+func requestFormat(contentType string) (string, bool) {
+	if strings.TrimSpace(contentType) == "" {
+		return formatJSON, true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+
+	// Env output is flattened and cannot be read back
+	format, ok := formatMediaTypes[mediaType]
+	if !ok || format == formatEnv {
+		return "", false
+	}
+	return format, true
+}
+
+// responseFormat negotiates the format of a configuration response. A format query
+// parameter takes precedence over the Accept header. It writes a 400 or 406 response and
+// returns false if the requested format is not supported.
+func responseFormat(c *gin.Context) (string, bool) {
+	if value := c.Query("format"); value != "" {
+		format := strings.ToLower(value)
+		if _, ok := formatContentTypes[format]; !ok {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:     "bad_request",
+				Message:   fmt.Sprintf("Unsupported format %q, use json, yaml, toml or env", value),
+				Timestamp: time.Now(),
+				Path:      c.Request.URL.Path,
+			})
+			return "", false
+		}
+		return format, true
+	}
+
+	format, ok := negotiateFormat(c.GetHeader("Accept"))
+	if !ok {
+		c.JSON(http.StatusNotAcceptable, models.ErrorResponse{
+			Error:     "not_acceptable",
+			Message:   fmt.Sprintf("Unsupported Accept type %q, use %s", c.GetHeader("Accept"), supportedMediaTypes),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return "", false
+	}
+
+	// Added rather than set, so as to keep the Vary values of other middleware such as CORS
+	c.Writer.Header().Add("Vary", "Accept")
+	return format, true
+}
+
+// renderConfig writes a configuration response in the negotiated format
+func renderConfig(c *gin.Context, config *models.ConfigResponse, format string) {
+	if format == formatJSON {
+		respondConfig(c, http.StatusOK, config)
+		return
+	}
+
+	var data []byte
+	var err error
+	if format == formatEnv {
+		// Env output carries the configuration document only
+		data, err = encodeEnv(config.Config, config.BlobKeys)
+	} else {
+		version := middleware.SchemaVersion(c)
+		setSchemaVersionHeaders(c, version)
+		data, err = encodeAs(shapeConfig(config, version), format)
+	}
+	if err != nil {
+		c.JSON(http.StatusNotAcceptable, models.ErrorResponse{
+			Error:     "not_acceptable",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, formatContentTypes[format], data)
+}
+
+// encodeConfigDocument encodes the configuration document of a response, without the
+// response's other fields, for saving as a file. JSON is indented for reading.
+func encodeConfigDocument(config *models.ConfigResponse, format string) ([]byte, error) {
+	switch format {
+	case formatJSON:
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, config.Config, "", "  "); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	case formatEnv:
+		return encodeEnv(config.Config, config.BlobKeys)
+	default:
+		return encodeAs(config.Config, format)
+	}
+}
+
+// convertRequestBody replaces a YAML or TOML request body with its JSON equivalent, so
+// that it can be bound like a JSON request. It writes a 415 or 400 response and returns
+// false if the body cannot be converted.
+func convertRequestBody(c *gin.Context) bool {
+	format, ok := requestFormat(c.GetHeader("Content-Type"))
+	if !ok {
+		c.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse{
+			Error:     "unsupported_media_type",
+			Message:   fmt.Sprintf("Unsupported Content-Type %q, use %s", c.GetHeader("Content-Type"), supportedRequestMediaTypes),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return false
+	}
+	if format == formatJSON {
+		return true
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err == nil {
+		var converted json.RawMessage
+		if converted, err = decodeToJSON(body, format); err == nil {
+			c.Request.Body = io.NopCloser(bytes.NewReader(converted))
+			return true
+		}
+	}
+
+	c.JSON(http.StatusBadRequest, models.ErrorResponse{
+		Error:     "bad_request",
+		Message:   "Invalid request body: " + err.Error(),
+		Timestamp: time.Now(),
+		Path:      c.Request.URL.Path,
+	})
+	return false
+}
+
+// encodeAs converts a value to the given format by way of its JSON representation, so
+// that field names and omitted fields are the same in every format
+func encodeAs(value interface{}, format string) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if format == formatJSON {
+		return data, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	document = fromJSONNumbers(document)
+
+	switch format {
+	case formatYAML:
+		return yaml.Marshal(document)
+	case formatTOML:
+		// TOML has no null; refuse rather than silently dropping keys
+		if path, ok := findNull(document, ""); ok {
+			return nil, fmt.Errorf("configuration cannot be represented as TOML: %s is null", path)
+		}
+		if _, ok := document.(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("configuration cannot be represented as TOML: the document is not a table")
+		}
+		return toml.Marshal(document)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// decodeToJSON converts a YAML or TOML document to JSON
+func decodeToJSON(data []byte, format string) (json.RawMessage, error) {
+	var document interface{}
+
+	switch format {
+	case formatJSON:
+		return data, nil
+	case formatYAML:
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+		var err error
+		if document, err = fromYAMLNode(&node); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	case formatTOML:
+		var table map[string]interface{}
+		if err := toml.Unmarshal(data, &table); err != nil {
+			return nil, fmt.Errorf("invalid TOML: %w", err)
+		}
+		document = table
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	return json.Marshal(document)
+}
+
+// fromJSONNumbers replaces json.Number values, which YAML and TOML encoders would write
+// as strings, with int64 or float64 values
+func fromJSONNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = fromJSONNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = fromJSONNumbers(item)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	}
+	return value
+}
+
+// findNull returns the path of the first null value in a document
+func findNull(value interface{}, path string) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return path, true
+	case map[string]interface{}:
+		for key, item := range v {
+			if found, ok := findNull(item, joinPath(path, key)); ok {
+				return found, true
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if found, ok := findNull(item, fmt.Sprintf("%s[%d]", path, i)); ok {
+				return found, true
+			}
+		}
+	}
+	return "", false
+}
+
+// joinPath appends a key to a dotted document path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// fromYAMLNode converts a YAML node to values that encode to JSON. Mapping keys become
+// strings, and timestamps are kept as written instead of being reformatted.
+func fromYAMLNode(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return fromYAMLNode(node.Content[0])
+	case yaml.AliasNode:
+		return fromYAMLNode(node.Alias)
+	case yaml.MappingNode:
+		mapping := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: mapping keys must be scalars", key.Line)
+			}
+			value, err := fromYAMLNode(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			mapping[key.Value] = value
+		}
+		return mapping, nil
+	case yaml.SequenceNode:
+		sequence := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := fromYAMLNode(item)
+			if err != nil {
+				return nil, err
+			}
+			sequence = append(sequence, value)
+		}
+		return sequence, nil
+	default:
+		if node.Tag == "!!timestamp" {
+			return node.Value, nil
+		}
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+// encodeEnv flattens a configuration document into KEY=value lines that can be sourced
+// by a shell. Keys are converted to UPPER_SNAKE_CASE and nested keys are joined with
+// "__"; array elements are keyed by their index. Lines are sorted by name. Values of
+// blob keys are written without whitespace, so that a line-wrapped blob fits on one line.
+func encodeEnv(config json.RawMessage, blobKeys []string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(config))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	object, ok := document.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("configuration cannot be represented as env: the document is not an object")
+	}
+
+	vars := make(map[string]string)
+	sources := make(map[string]string)
+	if err := flattenEnv(object, "", "", vars, sources); err != nil {
+		return nil, err
+	}
+	for name, path := range sources {
+		if services.IsBlobPath(path, blobKeys) {
+			vars[name] = services.CompactBlob(vars[name])
+		}
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		buf.WriteString(name)
+		buf.WriteByte('=')
+		buf.WriteString(shellQuote(vars[name]))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// flattenEnv adds the leaves of a document to vars under their variable names. sources
+// records the document path of each name, to report keys that map to the same name.
+func flattenEnv(value interface{}, name, path string, vars, sources map[string]string) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			part := envName(key)
+			if part == "" {
+				return fmt.Errorf("configuration cannot be represented as env: key %q has no usable characters", joinPath(path, key))
+			}
+			if err := flattenEnv(item, joinEnvName(name, part), joinPath(path, key), vars, sources); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		for i, item := range v {
+			if err := flattenEnv(item, joinEnvName(name, strconv.Itoa(i)), fmt.Sprintf("%s[%d]", path, i), vars, sources); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Names must not start with a digit
+	if name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	if existing, ok := sources[name]; ok {
+		return fmt.Errorf("configuration cannot be represented as env: %s and %s both map to %s", existing, path, name)
+	}
+	sources[name] = path
+
+	switch v := value.(type) {
+	case nil:
+		vars[name] = ""
+	case string:
+		vars[name] = v
+	case json.Number:
+		vars[name] = v.String()
+	case bool:
+		vars[name] = strconv.FormatBool(v)
+	}
+	return nil
+}
+
+// joinEnvName appends a part to a nested variable name
+func joinEnvName(name, part string) string {
+	if name == "" {
+		return part
+	}
+	return name + "__" + part
+}
+
+// envName converts a document key to UPPER_SNAKE_CASE, so that "apiTimeout",
+// "api-timeout" and "api_timeout" all become API_TIMEOUT. Characters that are not
+// allowed in variable names become underscores, and runs of underscores are collapsed
+// so that names cannot be confused with the "__" nesting separator.
+func envName(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		ch := key[i]
+		switch {
+		case ch >= 'A' && ch <= 'Z':
+			// Start a new word at camelCase boundaries, including "HTTPServer" -> HTTP_SERVER
+			if i > 0 && (isLowerOrDigit(key[i-1]) || (isUpper(key[i-1]) && i+1 < len(key) && isLower(key[i+1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteByte(ch)
+		case ch >= 'a' && ch <= 'z':
+			b.WriteByte(ch - 'a' + 'A')
+		case ch >= '0' && ch <= '9':
+			b.WriteByte(ch)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	parts := strings.FieldsFunc(b.String(), func(r rune) bool { return r == '_' })
+	return strings.Join(parts, "_")
+}
+
+func isUpper(ch byte) bool        { return ch >= 'A' && ch <= 'Z' }
+func isLower(ch byte) bool        { return ch >= 'a' && ch <= 'z' }
+func isLowerOrDigit(ch byte) bool { return isLower(ch) || (ch >= '0' && ch <= '9') }
+
+// shellQuote quotes a value for a shell unless it consists of safe characters only
+func shellQuote(value string) string {
+	if value != "" && strings.IndexFunc(value, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-.,:/@%+=", r))
+	}) == -1 {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := map[string]struct {
+		accept string
+		format string
+		ok     bool
+	}{
+		"no header":            {"", formatJSON, true},
+		"json":                 {"application/json", formatJSON, true},
+		"wildcard":             {"*/*", formatJSON, true},
+		"browser":              {"text/html,application/xhtml+xml,*/*;q=0.8", formatJSON, true},
+		"yaml":                 {"application/yaml", formatYAML, true},
+		"legacy yaml":          {"application/x-yaml; charset=utf-8", formatYAML, true},
+		"toml":                 {"application/toml", formatTOML, true},
+		"plain text":           {"text/plain", formatEnv, true},
+		"first supported wins": {"text/csv, application/toml, application/json", formatTOML, true},
+		"unsupported":          {"application/xml", "", false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			format, ok := negotiateFormat(tt.accept)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.format, format)
+		})
+	}
+}
+
+func TestEncodeAs(t *testing.T) {
+	config := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 7)
+	config.Config = json.RawMessage(`{"api_timeout": 30, "ratio": 0.25, "debug": true, "hosts": ["a", "b"], "db": {"port": 5432}}`)
+
+	t.Run("yaml", func(t *testing.T) {
+		data, err := encodeAs(config, formatYAML)
+		require.NoError(t, err)
+
+		var decoded map[string]interface{}
+		require.NoError(t, yaml.Unmarshal(data, &decoded))
+		assert.Equal(t, 7, decoded["version"])
+		assert.Equal(t, 30, decoded["config"].(map[string]interface{})["api_timeout"])
+		assert.Equal(t, 0.25, decoded["config"].(map[string]interface{})["ratio"])
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		data, err := encodeAs(config, formatTOML)
+		require.NoError(t, err)
+
+		var decoded map[string]interface{}
+		require.NoError(t, toml.Unmarshal(data, &decoded))
+		assert.Equal(t, int64(5432), decoded["config"].(map[string]interface{})["db"].(map[string]interface{})["port"])
+		assert.Equal(t, []interface{}{"a", "b"}, decoded["config"].(map[string]interface{})["hosts"])
+	})
+
+	t.Run("toml has no null", func(t *testing.T) {
+		config.Config = json.RawMessage(`{"db": {"password": null}}`)
+		_, err := encodeAs(config, formatTOML)
+		assert.ErrorContains(t, err, "config.db.password is null")
+	})
+}
+
+func TestEncodeEnv(t *testing.T) {
+	t.Run("flattens nested keys", func(t *testing.T) {
+		data, err := encodeEnv(json.RawMessage(`{"apiTimeout": 30, "debug": true, "db": {"host": "localhost", "pool-size": 10, "password": null}, "hosts": ["a", "b c"], "HTTPServer": {"greeting": "it's up"}}`), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "API_TIMEOUT=30\n"+
+			"DB__HOST=localhost\n"+
+			"DB__PASSWORD=''\n"+
+			"DB__POOL_SIZE=10\n"+
+			"DEBUG=true\n"+
+			"HOSTS__0=a\n"+
+			"HOSTS__1='b c'\n"+
+			"HTTP_SERVER__GREETING='it'\\''s up'\n", string(data))
+	})
+
+	t.Run("colliding keys", func(t *testing.T) {
+		_, err := encodeEnv(json.RawMessage(`{"db": {"pool-size": 1, "pool_size": 2}}`), nil)
+		assert.ErrorContains(t, err, "both map to DB__POOL_SIZE")
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		_, err := encodeEnv(json.RawMessage(`["a"]`), nil)
+		assert.ErrorContains(t, err, "not an object")
+	})
+
+	t.Run("blobs are written on one line", func(t *testing.T) {
+		data, err := encodeEnv(json.RawMessage(`{"tls": {"cert": "TUlJQ\nZGVm\n"}, "motd": "a\nb"}`), []string{"tls.cert"})
+		require.NoError(t, err)
+		assert.Equal(t, "MOTD='a\nb'\n"+
+			"TLS__CERT=TUlJQZGVm\n", string(data))
+	})
+}
+
+func TestDecodeToJSON(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		data, err := decodeToJSON([]byte("config:\n  release: 2024-01-15\n  retries: 3\n  tags: [a, b]\n  404: not found\ncreated_by: alice\n"), formatYAML)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"config": {"release": "2024-01-15", "retries": 3, "tags": ["a", "b"], "404": "not found"}, "created_by": "alice"}`, string(data))
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		data, err := decodeToJSON([]byte("created_by = \"alice\"\n\n[config]\nretries = 3\n\n[config.db]\nhost = \"localhost\"\n"), formatTOML)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"config": {"retries": 3, "db": {"host": "localhost"}}, "created_by": "alice"}`, string(data))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := decodeToJSON([]byte("config: [unclosed"), formatYAML)
+		assert.ErrorContains(t, err, "invalid YAML")
+
+		_, err = decodeToJSON([]byte("config = "), formatTOML)
+		assert.ErrorContains(t, err, "invalid TOML")
+	})
+}
+
+func TestConfigHandler_ContentNegotiation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newContext := func(w *httptest.ResponseRecorder, method, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/", bytes.NewBufferString(body))
+		c.Params = gin.Params{
+			{Key: "org", Value: "test-org"},
+			{Key: "app", Value: "test-app"},
+			{Key: "env", Value: "prod"},
+		}
+		return c
+	}
+
+	t.Run("get config as YAML", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfiguration", "test-org", "test-app", "prod").
+			Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 2), nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, "GET", "")
+		c.Request.Header.Set("Accept", "application/yaml")
+		c.Writer.Header().Add("Vary", "Origin") // as set by the CORS middleware
+		handler.GetConfig(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/yaml; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Values("Vary"), "Origin")
+		assert.Contains(t, w.Header().Values("Vary"), "Accept")
+
+		var decoded map[string]interface{}
+		require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &decoded))
+		assert.Equal(t, true, decoded["config"].(map[string]interface{})["debug"])
+	})
+
+	t.Run("get config by API key as TOML", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfigurationByAPIKey", testAPIKeyApp, "prod", "").
+			Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 2), nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, "GET", "")
+		c.Request.Header.Set("Accept", "application/toml")
+		c.Set("application", testAPIKeyApp)
+		handler.GetConfigByAPIKey(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/toml; charset=utf-8", w.Header().Get("Content-Type"))
+
+		var decoded map[string]interface{}
+		require.NoError(t, toml.Unmarshal(w.Body.Bytes(), &decoded))
+		assert.Equal(t, int64(30), decoded["config"].(map[string]interface{})["api_timeout"])
+	})
+
+	t.Run("get config as env", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetConfiguration", "test-org", "test-app", "prod").
+			Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 2), nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, "GET", "")
+		c.Request.URL.RawQuery = "format=env"
+		c.Request.Header.Set("Accept", "application/json")
+		handler.GetConfig(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), "DEBUG=true\n")
+	})
+
+	t.Run("unsupported format parameter", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, "GET", "")
+		c.Request.URL.RawQuery = "format=xml"
+		handler.GetConfig(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unsupported Accept type", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, "GET", "")
+		c.Request.Header.Set("Accept", "application/xml")
+		handler.GetConfig(c)
+
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		mockService.AssertNotCalled(t, "GetConfiguration", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("update config from YAML", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("UpdateConfiguration", "test-org", "test-app", "prod", mock.MatchedBy(func(req *models.CreateConfigRequest) bool {
+			var config map[string]interface{}
+			return json.Unmarshal(req.Config, &config) == nil && config["theme"] == "dark" &&
+				req.CreatedBy != nil && *req.CreatedBy == "alice"
+		}), false).Return(testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 3), nil)
+
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, "PUT", "config:\n  theme: dark\ncreated_by: alice\n")
+		c.Request.Header.Set("Content-Type", "application/yaml")
+		handler.UpdateConfig(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unsupported Content-Type", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		handler := NewConfigHandler(mockService)
+
+		w := httptest.NewRecorder()
+		c := newContext(w, "PUT", "<config/>")
+		c.Request.Header.Set("Content-Type", "application/xml")
+		handler.UpdateConfig(c)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		mockService.AssertNotCalled(t, "UpdateConfiguration", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -1,13 +1,19 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"remote-config-system/internal/middleware"
 	"remote-config-system/internal/models"
 	"remote-config-system/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // ManagementHandler handles management API endpoints
@@ -26,14 +32,8 @@ func NewManagementHandler(configService *services.ConfigService) *ManagementHand
 
 // ListOrganizations handles GET /admin/orgs
 func (h *ManagementHandler) ListOrganizations(c *gin.Context) {
-	params := models.DefaultPaginationParams()
-	if err := c.ShouldBindQuery(&params); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:     "invalid_parameters",
-			Message:   err.Error(),
-			Timestamp: time.Now(),
-			Path:      c.Request.URL.Path,
-		})
+	params, ok := parsePagination(c)
+	if !ok {
 		return
 	}
 
@@ -55,10 +55,28 @@ func (h *ManagementHandler) ListOrganizations(c *gin.Context) {
 func (h *ManagementHandler) GetOrganization(c *gin.Context) {
 	orgSlug := c.Param("org")
 
-	org, err := h.configService.GetOrganization(orgSlug)
+	include, ok := parseInclude(c, "apps")
+	if !ok {
+		return
+	}
+
+	var org interface{}
+	var err error
+	if include["apps"] {
+		org, err = h.configService.GetOrganizationWithApplications(orgSlug)
+	} else {
+		org, err = h.configService.GetOrganization(orgSlug)
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Error:     "not_found",
+		statusCode := http.StatusInternalServerError
+		errorCode := "internal_error"
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+			errorCode = "not_found"
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     errorCode,
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -69,12 +87,37 @@ func (h *ManagementHandler) GetOrganization(c *gin.Context) {
 	c.JSON(http.StatusOK, org)
 }
 
-// CreateOrganization handles POST /admin/orgs
-func (h *ManagementHandler) CreateOrganization(c *gin.Context) {
-	var req models.CreateOrganizationRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:     "invalid_request",
+// GetOrganizationTree handles GET /admin/orgs/:org/tree
+func (h *ManagementHandler) GetOrganizationTree(c *gin.Context) {
+	orgSlug := c.Param("org")
+
+	// Parse optional depth query parameter
+	depth := services.MaxTreeDepth
+	if depthStr := c.Query("depth"); depthStr != "" {
+		parsed, err := strconv.Atoi(depthStr)
+		if err != nil || parsed < 0 || parsed > services.MaxTreeDepth {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:     "invalid_parameters",
+				Message:   fmt.Sprintf("Invalid depth parameter: must be an integer between 0 and %d", services.MaxTreeDepth),
+				Timestamp: time.Now(),
+				Path:      c.Request.URL.Path,
+			})
+			return
+		}
+		depth = parsed
+	}
+
+	tree, err := h.configService.GetOrganizationTree(orgSlug, depth)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "internal_error"
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+			errorCode = "not_found"
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     errorCode,
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -82,15 +125,22 @@ func (h *ManagementHandler) CreateOrganization(c *gin.Context) {
 		return
 	}
 
-	org, err := h.configService.CreateOrganization(&req)
+	c.JSON(http.StatusOK, tree)
+}
+
+// GetOrganizationQuota handles GET /admin/orgs/:org/quota
+func (h *ManagementHandler) GetOrganizationQuota(c *gin.Context) {
+	quota, err := h.configService.GetOrganizationQuota(c.Param("org"))
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "organization with slug '"+req.Slug+"' already exists" {
-			statusCode = http.StatusConflict
+		errorCode := "internal_error"
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+			errorCode = "not_found"
 		}
 
 		c.JSON(statusCode, models.ErrorResponse{
-			Error:     "creation_failed",
+			Error:     errorCode,
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -98,6 +148,30 @@ func (h *ManagementHandler) CreateOrganization(c *gin.Context) {
 		return
 	}
 
+	c.JSON(http.StatusOK, quota)
+}
+
+// CreateOrganization handles POST /admin/orgs
+func (h *ManagementHandler) CreateOrganization(c *gin.Context) {
+	var req models.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "invalid_request", "Invalid request body: ", err))
+		return
+	}
+
+	org, err := h.configService.CreateOrganization(&req)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, withExistingResource(models.ErrorResponse{
+			Error:     "creation_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		}, err))
+		return
+	}
+
 	c.JSON(http.StatusCreated, org)
 }
 
@@ -107,19 +181,14 @@ func (h *ManagementHandler) UpdateOrganization(c *gin.Context) {
 
 	var req models.UpdateOrganizationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:     "invalid_request",
-			Message:   err.Error(),
-			Timestamp: time.Now(),
-			Path:      c.Request.URL.Path,
-		})
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "invalid_request", "Invalid request body: ", err))
 		return
 	}
 
 	org, err := h.configService.UpdateOrganization(orgSlug, &req)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "organization not found: "+orgSlug {
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
@@ -142,7 +211,7 @@ func (h *ManagementHandler) DeleteOrganization(c *gin.Context) {
 	err := h.configService.DeleteOrganization(orgSlug)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "organization not found: "+orgSlug {
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
@@ -164,21 +233,15 @@ func (h *ManagementHandler) DeleteOrganization(c *gin.Context) {
 func (h *ManagementHandler) ListApplications(c *gin.Context) {
 	orgSlug := c.Param("org")
 
-	params := models.DefaultPaginationParams()
-	if err := c.ShouldBindQuery(&params); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:     "invalid_parameters",
-			Message:   err.Error(),
-			Timestamp: time.Now(),
-			Path:      c.Request.URL.Path,
-		})
+	params, ok := parsePagination(c)
+	if !ok {
 		return
 	}
 
 	response, err := h.configService.ListApplications(orgSlug, params)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "organization not found: "+orgSlug {
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
@@ -213,14 +276,38 @@ func (h *ManagementHandler) GetApplication(c *gin.Context) {
 	c.JSON(http.StatusOK, app)
 }
 
-// CreateApplication handles POST /admin/orgs/:org/apps
-func (h *ManagementHandler) CreateApplication(c *gin.Context) {
+// GetApplicationUsage handles GET /admin/orgs/:org/apps/:app/usage
+func (h *ManagementHandler) GetApplicationUsage(c *gin.Context) {
 	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
 
-	var req models.CreateApplicationRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:     "invalid_request",
+	// Parse optional days query parameter
+	days := services.DefaultUsageDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 1 || parsed > services.MaxUsageDays {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:     "invalid_parameters",
+				Message:   fmt.Sprintf("Invalid days parameter: must be an integer between 1 and %d", services.MaxUsageDays),
+				Timestamp: time.Now(),
+				Path:      c.Request.URL.Path,
+			})
+			return
+		}
+		days = parsed
+	}
+
+	usage, err := h.configService.GetApplicationUsage(orgSlug, appSlug, days)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := "internal_error"
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+			errorCode = "not_found"
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     errorCode,
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -228,21 +315,29 @@ func (h *ManagementHandler) CreateApplication(c *gin.Context) {
 		return
 	}
 
+	c.JSON(http.StatusOK, usage)
+}
+
+// CreateApplication handles POST /admin/orgs/:org/apps
+func (h *ManagementHandler) CreateApplication(c *gin.Context) {
+	orgSlug := c.Param("org")
+
+	var req models.CreateApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "invalid_request", "Invalid request body: ", err))
+		return
+	}
+
 	app, err := h.configService.CreateApplication(orgSlug, &req)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "organization not found: "+orgSlug {
-			statusCode = http.StatusNotFound
-		} else if err.Error() == "application with slug '"+req.Slug+"' already exists in organization '"+orgSlug+"'" {
-			statusCode = http.StatusConflict
-		}
+		statusCode := statusForError(err)
 
-		c.JSON(statusCode, models.ErrorResponse{
+		c.JSON(statusCode, withExistingResource(models.ErrorResponse{
 			Error:     "creation_failed",
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
-		})
+		}, err))
 		return
 	}
 
@@ -256,8 +351,16 @@ func (h *ManagementHandler) UpdateApplication(c *gin.Context) {
 
 	var req models.UpdateApplicationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:     "invalid_request",
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "invalid_request", "Invalid request body: ", err))
+		return
+	}
+
+	app, err := h.configService.UpdateApplication(orgSlug, appSlug, &req)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "update_failed",
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -265,15 +368,20 @@ func (h *ManagementHandler) UpdateApplication(c *gin.Context) {
 		return
 	}
 
-	app, err := h.configService.UpdateApplication(orgSlug, appSlug, &req)
+	c.JSON(http.StatusOK, app)
+}
+
+// RotateAPIKey handles POST /admin/orgs/:org/apps/:app/api-key/rotate
+func (h *ManagementHandler) RotateAPIKey(c *gin.Context) {
+	app, err := h.configService.RotateAPIKey(c.Param("org"), c.Param("app"))
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "application not found: "+appSlug {
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
 		c.JSON(statusCode, models.ErrorResponse{
-			Error:     "update_failed",
+			Error:     "rotation_failed",
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -292,7 +400,7 @@ func (h *ManagementHandler) DeleteApplication(c *gin.Context) {
 	err := h.configService.DeleteApplication(orgSlug, appSlug)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "application not found: "+appSlug {
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
@@ -315,21 +423,15 @@ func (h *ManagementHandler) ListEnvironments(c *gin.Context) {
 	orgSlug := c.Param("org")
 	appSlug := c.Param("app")
 
-	params := models.DefaultPaginationParams()
-	if err := c.ShouldBindQuery(&params); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:     "invalid_parameters",
-			Message:   err.Error(),
-			Timestamp: time.Now(),
-			Path:      c.Request.URL.Path,
-		})
+	params, ok := parsePagination(c)
+	if !ok {
 		return
 	}
 
 	response, err := h.configService.ListEnvironments(orgSlug, appSlug, params)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "application not found: "+appSlug {
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
@@ -351,10 +453,28 @@ func (h *ManagementHandler) GetEnvironment(c *gin.Context) {
 	appSlug := c.Param("app")
 	envSlug := c.Param("env")
 
-	env, err := h.configService.GetEnvironment(orgSlug, appSlug, envSlug)
+	includeSummary, ok := parseEnvironmentInclude(c)
+	if !ok {
+		return
+	}
+
+	var env *models.Environment
+	var err error
+	if includeSummary {
+		env, err = h.configService.GetEnvironmentWithConfigSummary(orgSlug, appSlug, envSlug)
+	} else {
+		env, err = h.configService.GetEnvironment(orgSlug, appSlug, envSlug)
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.ErrorResponse{
-			Error:     "not_found",
+		statusCode := http.StatusInternalServerError
+		errorCode := "internal_error"
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+			errorCode = "not_found"
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     errorCode,
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -365,6 +485,45 @@ func (h *ManagementHandler) GetEnvironment(c *gin.Context) {
 	c.JSON(http.StatusOK, env)
 }
 
+// parseEnvironmentInclude parses the include query parameter of GET requests for an
+// environment and reports whether the configuration summary was requested
+func parseEnvironmentInclude(c *gin.Context) (bool, bool) {
+	include, ok := parseInclude(c, "config_summary")
+	return include["config_summary"], ok
+}
+
+// parseInclude parses the include query parameter of GET requests, a comma-separated list
+// of optional sections, and returns the requested sections. It responds with 400 for
+// sections other than the given ones.
+func parseInclude(c *gin.Context, sections ...string) (map[string]bool, bool) {
+	include := make(map[string]bool)
+	raw := c.Query("include")
+	if raw == "" {
+		return include, true
+	}
+
+	known := make(map[string]bool, len(sections))
+	for _, section := range sections {
+		known[section] = true
+	}
+
+	for _, section := range strings.Split(raw, ",") {
+		section = strings.TrimSpace(section)
+		if !known[section] {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:     "invalid_parameters",
+				Message:   fmt.Sprintf("Invalid include parameter: unknown section %q", section),
+				Timestamp: time.Now(),
+				Path:      c.Request.URL.Path,
+			})
+			return nil, false
+		}
+		include[section] = true
+	}
+
+	return include, true
+}
+
 // CreateEnvironment handles POST /admin/orgs/:org/apps/:app/envs
 func (h *ManagementHandler) CreateEnvironment(c *gin.Context) {
 	orgSlug := c.Param("org")
@@ -372,22 +531,70 @@ func (h *ManagementHandler) CreateEnvironment(c *gin.Context) {
 
 	var req models.CreateEnvironmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:     "invalid_request",
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "invalid_request", "Invalid request body: ", err))
+		return
+	}
+
+	if req.CreatedBy == nil {
+		if name := c.GetString("admin_token_name"); name != "" {
+			req.CreatedBy = &name
+		}
+	}
+
+	env, err := h.configService.CreateEnvironment(orgSlug, appSlug, &req)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, withExistingResource(models.ErrorResponse{
+			Error:     "creation_failed",
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
-		})
+		}, err))
 		return
 	}
 
-	env, err := h.configService.CreateEnvironment(orgSlug, appSlug, &req)
+	c.JSON(http.StatusCreated, env)
+}
+
+// BulkCreateEnvironments handles POST /admin/orgs/:org/apps/:app/envs/bulk
+func (h *ManagementHandler) BulkCreateEnvironments(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+
+	// Parse optional continue_on_error query parameter
+	continueOnError := false
+	if continueStr := c.Query("continue_on_error"); continueStr != "" {
+		parsed, err := strconv.ParseBool(continueStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:     "bad_request",
+				Message:   "Invalid continue_on_error parameter: " + err.Error(),
+				Timestamp: time.Now(),
+				Path:      c.Request.URL.Path,
+			})
+			return
+		}
+		continueOnError = parsed
+	}
+
+	var req models.BulkCreateEnvironmentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "invalid_request", "Invalid request body: ", err))
+		return
+	}
+
+	if req.CreatedBy == nil {
+		if name := c.GetString("admin_token_name"); name != "" {
+			req.CreatedBy = &name
+		}
+	}
+
+	result, err := h.configService.CreateEnvironments(orgSlug, appSlug, &req, continueOnError)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "application not found: "+appSlug {
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
-		} else if err.Error() == "environment with slug '"+req.Slug+"' already exists in application '"+appSlug+"'" {
-			statusCode = http.StatusConflict
 		}
 
 		c.JSON(statusCode, models.ErrorResponse{
@@ -399,7 +606,12 @@ func (h *ManagementHandler) CreateEnvironment(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, env)
+	// Each environment carries its own status, so a partial result is multi-status
+	statusCode := http.StatusCreated
+	if result.Failed > 0 {
+		statusCode = http.StatusMultiStatus
+	}
+	c.JSON(statusCode, result)
 }
 
 // UpdateEnvironment handles PUT /admin/orgs/:org/apps/:app/envs/:env
@@ -410,9 +622,15 @@ func (h *ManagementHandler) UpdateEnvironment(c *gin.Context) {
 
 	var req models.UpdateEnvironmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{
-			Error:     "invalid_request",
-			Message:   err.Error(),
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "invalid_request", "Invalid request body: ", err))
+		return
+	}
+
+	// Editors could otherwise turn off the approval their own updates need
+	if req.RequiresApproval != nil && !middleware.RoleGranted(c, models.RoleAdmin) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:     "forbidden",
+			Message:   fmt.Sprintf("Changing requires_approval requires the %s role", models.RoleAdmin),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
 		})
@@ -421,10 +639,7 @@ func (h *ManagementHandler) UpdateEnvironment(c *gin.Context) {
 
 	env, err := h.configService.UpdateEnvironment(orgSlug, appSlug, envSlug, &req)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "environment not found: "+envSlug {
-			statusCode = http.StatusNotFound
-		}
+		statusCode := statusForError(err)
 
 		c.JSON(statusCode, models.ErrorResponse{
 			Error:     "update_failed",
@@ -447,7 +662,197 @@ func (h *ManagementHandler) DeleteEnvironment(c *gin.Context) {
 	err := h.configService.DeleteEnvironment(orgSlug, appSlug, envSlug)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
-		if err.Error() == "environment not found: "+envSlug {
+		if errors.Is(err, services.ErrNotFound) {
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "deletion_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// LockEnvironment handles POST /admin/orgs/:org/apps/:app/envs/:env/lock
+func (h *ManagementHandler) LockEnvironment(c *gin.Context) {
+	var req models.LockEnvironmentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "invalid_request", "Invalid request body: ", err))
+			return
+		}
+	}
+
+	// The locker defaults to the name of the admin token making the request
+	if req.LockedBy == nil {
+		if name := c.GetString("admin_token_name"); name != "" {
+			req.LockedBy = &name
+		}
+	}
+
+	env, err := h.configService.LockEnvironment(c.Param("org"), c.Param("app"), c.Param("env"), &req)
+	if err != nil {
+		c.JSON(lockErrorStatus(err), models.ErrorResponse{
+			Error:     "lock_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, env)
+}
+
+// UnlockEnvironment handles DELETE /admin/orgs/:org/apps/:app/envs/:env/lock
+func (h *ManagementHandler) UnlockEnvironment(c *gin.Context) {
+	env, err := h.configService.UnlockEnvironment(c.Param("org"), c.Param("app"), c.Param("env"))
+	if err != nil {
+		c.JSON(lockErrorStatus(err), models.ErrorResponse{
+			Error:     "unlock_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, env)
+}
+
+// lockErrorStatus maps lock and unlock errors to HTTP status codes
+func lockErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ListEnvironmentLabels handles GET /admin/orgs/:org/apps/:app/envs/:env/labels
+func (h *ManagementHandler) ListEnvironmentLabels(c *gin.Context) {
+	labels, err := h.configService.ListLabels(c.Param("org"), c.Param("app"), c.Param("env"))
+	if err != nil {
+		c.JSON(statusForError(err), models.ErrorResponse{
+			Error:     "labels_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, labels)
+}
+
+// SetEnvironmentLabel handles PUT /admin/orgs/:org/apps/:app/envs/:env/labels/:label
+func (h *ManagementHandler) SetEnvironmentLabel(c *gin.Context) {
+	var req models.SetLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "invalid_request", "Invalid request body: ", err))
+		return
+	}
+
+	labels, created, err := h.configService.SetLabel(c.Param("org"), c.Param("app"), c.Param("env"), c.Param("label"), &req)
+	if err != nil {
+		c.JSON(statusForError(err), models.ErrorResponse{
+			Error:     "label_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	if created {
+		c.JSON(http.StatusCreated, labels)
+		return
+	}
+	c.JSON(http.StatusOK, labels)
+}
+
+// DeleteEnvironmentLabel handles DELETE /admin/orgs/:org/apps/:app/envs/:env/labels/:label
+func (h *ManagementHandler) DeleteEnvironmentLabel(c *gin.Context) {
+	err := h.configService.DeleteLabel(c.Param("org"), c.Param("app"), c.Param("env"), c.Param("label"))
+	if err != nil {
+		c.JSON(statusForError(err), models.ErrorResponse{
+			Error:     "delete_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Admin Token Management Endpoints
+
+// ListAdminTokens handles GET /admin/tokens
+func (h *ManagementHandler) ListAdminTokens(c *gin.Context) {
+	tokens, err := h.configService.ListAdminTokens()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:     "internal_error",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreateAdminToken handles POST /admin/tokens
+func (h *ManagementHandler) CreateAdminToken(c *gin.Context) {
+	var req models.CreateAdminTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(c, "invalid_request", "Invalid request body: ", err))
+		return
+	}
+
+	token, err := h.configService.CreateAdminToken(&req)
+	if err != nil {
+		statusCode := statusForError(err)
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "creation_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+// DeleteAdminToken handles DELETE /admin/tokens/:id
+func (h *ManagementHandler) DeleteAdminToken(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "Invalid token ID",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	if err := h.configService.DeleteAdminToken(id); err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
 		}
 
@@ -481,12 +886,11 @@ func (h *ManagementHandler) GetCacheStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
-// WarmCache handles POST /admin/cache/warm
-func (h *ManagementHandler) WarmCache(c *gin.Context) {
-	err := h.configService.WarmCache()
-	if err != nil {
+// ResetCacheStats handles POST /admin/cache/stats/reset
+func (h *ManagementHandler) ResetCacheStats(c *gin.Context) {
+	if err := h.configService.ResetCacheStats(); err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
-			Error:     "cache_warm_failed",
+			Error:     "cache_stats_reset_failed",
 			Message:   err.Error(),
 			Timestamp: time.Now(),
 			Path:      c.Request.URL.Path,
@@ -495,11 +899,119 @@ func (h *ManagementHandler) WarmCache(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, map[string]interface{}{
-		"message":   "Cache warming completed successfully",
+		"message":   "Cache statistics reset successfully",
 		"timestamp": time.Now(),
 	})
 }
 
+// GetDatabaseStats handles GET /admin/db/stats
+func (h *ManagementHandler) GetDatabaseStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.configService.GetDatabaseStats())
+}
+
+// WarmCache handles POST /admin/cache/warm, optionally limited with ?org= and ?app=
+func (h *ManagementHandler) WarmCache(c *gin.Context) {
+	orgSlug := c.Query("org")
+	appSlug := c.Query("app")
+	if appSlug != "" && orgSlug == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "app requires org",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	warmed, err := h.configService.WarmCacheScope(orgSlug, appSlug)
+	if err != nil {
+		status := http.StatusInternalServerError
+		errorCode := "cache_warm_failed"
+		if errors.Is(err, services.ErrNotFound) {
+			status = http.StatusNotFound
+			errorCode = "not_found"
+		}
+		c.JSON(status, models.ErrorResponse{
+			Error:     errorCode,
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"message":        "Cache warming completed successfully",
+		"configurations": warmed,
+		"timestamp":      time.Now(),
+	})
+}
+
+// GetCacheWarmStatus handles GET /admin/cache/warm/status
+func (h *ManagementHandler) GetCacheWarmStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.configService.CacheWarmStatus())
+}
+
+// GetStaleConfigs handles GET /admin/stats/stale, returning the latest stale configuration
+// check or running a new one with ?refresh=true
+func (h *ManagementHandler) GetStaleConfigs(c *gin.Context) {
+	// Parse optional refresh query parameter
+	refresh := false
+	if refreshStr := c.Query("refresh"); refreshStr != "" {
+		parsed, err := strconv.ParseBool(refreshStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:     "bad_request",
+				Message:   "Invalid refresh parameter: " + err.Error(),
+				Timestamp: time.Now(),
+				Path:      c.Request.URL.Path,
+			})
+			return
+		}
+		refresh = parsed
+	}
+
+	var report *models.StaleConfigReport
+	var err error
+	if refresh {
+		report, err = h.configService.CheckStaleConfigs()
+	} else {
+		report, err = h.configService.StaleConfigReport()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:     "stale_check_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ReencryptSecrets handles POST /admin/encryption/reencrypt
+func (h *ManagementHandler) ReencryptSecrets(c *gin.Context) {
+	result, err := h.configService.ReencryptSecrets()
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if strings.HasPrefix(err.Error(), "encryption is not configured") {
+			statusCode = http.StatusConflict
+		}
+
+		c.JSON(statusCode, models.ErrorResponse{
+			Error:     "reencrypt_failed",
+			Message:   err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 // ClearCache handles DELETE /admin/cache
 func (h *ManagementHandler) ClearCache(c *gin.Context) {
 	err := h.configService.ClearCache()
@@ -518,3 +1030,14 @@ func (h *ManagementHandler) ClearCache(c *gin.Context) {
 		"timestamp": time.Now(),
 	})
 }
+
+// withExistingResource identifies the resource in an error response when the error is
+// about a slug that is already in use
+func withExistingResource(response models.ErrorResponse, err error) models.ErrorResponse {
+	var exists *services.AlreadyExistsError
+	if errors.As(err, &exists) {
+		response.Resource = exists.Resource
+		response.Slug = exists.Slug
+	}
+	return response
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithExistingResource(t *testing.T) {
+	t.Run("slug already in use", func(t *testing.T) {
+		err := &services.AlreadyExistsError{Resource: "application", Slug: "web", ParentResource: "organization", ParentSlug: "acme"}
+
+		response := withExistingResource(models.ErrorResponse{Error: "creation_failed", Message: err.Error()}, err)
+
+		assert.Equal(t, "application", response.Resource)
+		assert.Equal(t, "web", response.Slug)
+		assert.Equal(t, "application with slug 'web' already exists in organization 'acme'", response.Message)
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		err := fmt.Errorf("failed to create application: connection refused")
+
+		response := withExistingResource(models.ErrorResponse{Error: "creation_failed", Message: err.Error()}, err)
+
+		assert.Empty(t, response.Resource)
+		assert.Empty(t, response.Slug)
+	})
+}
+
+func TestManagementHandler_CreateRejectsInvalidSlugs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Requests are rejected while binding, before the service is used
+	handler := NewManagementHandler(nil)
+
+	run := func(create gin.HandlerFunc, body string) (*httptest.ResponseRecorder, models.ErrorResponse) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/orgs", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "org", Value: "acme"}, {Key: "app", Value: "web"}}
+
+		create(c)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w, response
+	}
+
+	tests := map[string]string{
+		"foo/bar":               `invalid slug "foo/bar": must contain only lowercase letters`,
+		"foo bar":               `invalid slug "foo bar": must contain only lowercase letters`,
+		strings.Repeat("a", 51): "must be at most 50 characters long",
+		"":                      "required",
+	}
+
+	creates := map[string]gin.HandlerFunc{
+		"organization": handler.CreateOrganization,
+		"application":  handler.CreateApplication,
+		"environment":  handler.CreateEnvironment,
+	}
+
+	for resource, create := range creates {
+		for slug, expected := range tests {
+			w, response := run(create, `{"name": "Test", "slug": "`+slug+`"}`)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, resource+" "+slug)
+			assert.Contains(t, response.Message, expected, resource+" "+slug)
+		}
+	}
+
+	t.Run("golden environment", func(t *testing.T) {
+		for _, update := range []gin.HandlerFunc{handler.CreateEnvironment, handler.UpdateEnvironment} {
+			w, response := run(update, `{"name": "Staging", "slug": "staging", "golden_environment": "Pro d"}`)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, response.Message, `golden_environment: invalid slug "Pro d"`)
+		}
+	})
+
+	t.Run("bulk environments", func(t *testing.T) {
+		w, response := run(handler.BulkCreateEnvironments, `{"environments": [{"name": "Dev", "slug": "dev"}, {"name": "QA", "slug": "q/a"}]}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, response.Message, `invalid slug "q/a"`)
+	})
+}
+
+func TestManagementHandler_GetOrganizationRejectsUnknownIncludes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The include parameter is checked before the service is used
+	handler := NewManagementHandler(nil)
+
+	for _, include := range []string{"applications", "apps,envs"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/admin/orgs/acme?include="+include, nil)
+		c.Params = gin.Params{{Key: "org", Value: "acme"}}
+
+		handler.GetOrganization(c)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusBadRequest, w.Code, include)
+		assert.Contains(t, response.Message, "Invalid include parameter", include)
+	}
+}
+
+func TestManagementHandler_GetOrganizationTreeRejectsInvalidDepths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The depth parameter is checked before the service is used
+	handler := NewManagementHandler(nil)
+
+	for _, depth := range []string{"-1", "3", "all"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/admin/orgs/acme/tree?depth="+depth, nil)
+		c.Params = gin.Params{{Key: "org", Value: "acme"}}
+
+		handler.GetOrganizationTree(c)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusBadRequest, w.Code, depth)
+		assert.Contains(t, response.Message, "Invalid depth parameter", depth)
+	}
+}
+
+func TestManagementHandler_GetApplicationUsageRejectsInvalidDays(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The days parameter is checked before the service is used
+	handler := NewManagementHandler(nil)
+
+	for _, days := range []string{"0", "366", "week"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/admin/orgs/acme/apps/web/usage?days="+days, nil)
+		c.Params = gin.Params{{Key: "org", Value: "acme"}, {Key: "app", Value: "web"}}
+
+		handler.GetApplicationUsage(c)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusBadRequest, w.Code, days)
+		assert.Contains(t, response.Message, "Invalid days parameter", days)
+	}
+}
+
+func TestManagementHandler_GetStaleConfigsRejectsInvalidRefresh(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The refresh parameter is checked before the service is used
+	handler := NewManagementHandler(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/stats/stale?refresh=sometimes", nil)
+
+	handler.GetStaleConfigs(c)
+
+	var response models.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, response.Message, "Invalid refresh parameter")
+}
+
+func TestManagementHandler_GetEnvironmentRejectsUnknownIncludes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The include parameter is checked before the service is used
+	handler := NewManagementHandler(nil)
+
+	for _, include := range []string{"versions", "config_summary,history"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/admin/orgs/acme/apps/web/envs/prod?include="+include, nil)
+		c.Params = gin.Params{{Key: "org", Value: "acme"}, {Key: "app", Value: "web"}, {Key: "env", Value: "prod"}}
+
+		handler.GetEnvironment(c)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusBadRequest, w.Code, include)
+		assert.Contains(t, response.Message, "Invalid include parameter", include)
+	}
+}
+
+func TestManagementHandler_SetEnvironmentLabelRejectsInvalidBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Requests are rejected while binding, before the service is used
+	handler := NewManagementHandler(nil)
+
+	for _, body := range []string{`{}`, `{"value": ""}`, `{"value": 1}`} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/admin/orgs/acme/apps/web/envs/prod/labels/tier", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "org", Value: "acme"}, {Key: "app", Value: "web"}, {Key: "env", Value: "prod"}, {Key: "label", Value: "tier"}}
+
+		handler.SetEnvironmentLabel(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
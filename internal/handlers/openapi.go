@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"remote-config-system/internal/middleware"
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OpenAPIHandler serves an OpenAPI 3 document of the HTTP API and a Swagger UI page for
+// it. The paths of the document are taken from the routes registered on the router, so
+// it cannot list endpoints that do not exist; apiOperations describes each of them.
+type OpenAPIHandler struct {
+	routes func() gin.RoutesInfo
+
+	once     sync.Once
+	document []byte
+	err      error
+}
+
+// NewOpenAPIHandler creates a new OpenAPI handler. Routes is called once, on the first
+// request, so that routes registered after the handler was created are included.
+func NewOpenAPIHandler(routes func() gin.RoutesInfo) *OpenAPIHandler {
+	return &OpenAPIHandler{routes: routes}
+}
+
+// GetSpec handles GET /openapi.json
+func (h *OpenAPIHandler) GetSpec(c *gin.Context) {
+	h.once.Do(func() {
+		h.document, h.err = json.Marshal(buildOpenAPIDocument(h.routes()))
+	})
+	if h.err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to generate the OpenAPI document: " + h.err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", h.document)
+}
+
+// SwaggerUI handles GET /docs
+func (h *OpenAPIHandler) SwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+// Undocumented returns the registered routes that apiOperations does not describe, as
+// "METHOD path". They are still listed in the document, without request or response models.
+func (h *OpenAPIHandler) Undocumented() []string {
+	var routes []string
+	for _, route := range h.routes() {
+		if route.Method == http.MethodHead {
+			continue
+		}
+		if _, ok := apiOperations[route.Method+" "+route.Path]; !ok {
+			routes = append(routes, route.Method+" "+route.Path)
+		}
+	}
+	return routes
+}
+
+// swaggerUIPage renders the OpenAPI document with Swagger UI
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Remote Config API</title>
+    <link rel="stylesheet" href="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/5.11.0/swagger-ui.min.css">
+</head>
+<body>
+    <div id="swagger-ui"></div>
+    <script src="https://cdnjs.cloudflare.com/ajax/libs/swagger-ui/5.11.0/swagger-ui-bundle.min.js"></script>
+    <script>
+        window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+    </script>
+</body>
+</html>
+`
+
+// openAPIDocument is the subset of the OpenAPI 3.0 document structure the API uses
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Tags       []openAPITag                            `json:"tags"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type openAPITag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema         `json:"schemas"`
+	SecuritySchemes map[string]*openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type        string `json:"type"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Scheme      string `json:"scheme,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIOperation struct {
+	Tags        []string                    `json:"tags,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	OperationID string                      `json:"operationId,omitempty"`
+	Parameters  []openAPIParameter          `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*openAPIResponse `json:"responses"`
+	Security    []map[string][]string       `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	AllOf                []*openAPISchema          `json:"allOf,omitempty"`
+	OneOf                []*openAPISchema          `json:"oneOf,omitempty"`
+}
+
+// Security schemes of the API
+const (
+	securityAPIKey     = "apiKey"
+	securityBearer     = "bearerAuth"
+	securityAdminToken = "adminToken"
+)
+
+// buildOpenAPIDocument describes the given routes. HEAD routes and operations marked
+// hidden are left out.
+func buildOpenAPIDocument(routes gin.RoutesInfo) *openAPIDocument {
+	schemas := newSchemaRegistry()
+	document := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:       "Remote Config API",
+			Description: "Real-time configuration management for applications. Configuration responses are shaped by the schema version negotiated with the " + middleware.SchemaVersionHeader + " or " + middleware.AcceptVersionHeader + " header.",
+			Version:     "1.0.0",
+		},
+		Tags:  apiTags,
+		Paths: make(map[string]map[string]*openAPIOperation),
+		Components: openAPIComponents{
+			Schemas: schemas.components,
+			SecuritySchemes: map[string]*openAPISecurityScheme{
+				securityAPIKey:     {Type: "apiKey", In: "header", Name: "X-API-Key", Description: "Application API key. It can also be sent as an api_key query parameter or in the Authorization header."},
+				securityBearer:     {Type: "http", Scheme: "bearer", Description: "Application API key or, when JWT authentication is configured, a JWT issued by the identity provider."},
+				securityAdminToken: {Type: "apiKey", In: "header", Name: "X-Admin-Token", Description: "Admin token, required unless ADMIN_AUTH_ENABLED=false."},
+			},
+		},
+	}
+
+	for _, route := range routes {
+		if route.Method == http.MethodHead {
+			continue
+		}
+
+		spec, ok := apiOperations[route.Method+" "+route.Path]
+		if !ok {
+			spec = apiOperation{Summary: "Undocumented endpoint"}
+		}
+		if spec.Hidden {
+			continue
+		}
+
+		path, pathParams := openAPIPath(route.Path)
+		if document.Paths[path] == nil {
+			document.Paths[path] = make(map[string]*openAPIOperation)
+		}
+		document.Paths[path][strings.ToLower(route.Method)] = spec.describe(route, pathParams, schemas)
+	}
+
+	return document
+}
+
+// openAPIPath converts a gin route path to an OpenAPI path and returns its parameters
+func openAPIPath(path string) (string, []string) {
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// handlerName returns the name of the method or function handling a route
+func handlerName(route gin.RouteInfo) string {
+	name := strings.TrimSuffix(route.Handler, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// schemaRegistry generates schemas for Go types, as encoding/json marshals them. Named
+// struct types become components and are referenced.
+type schemaRegistry struct {
+	components map[string]*openAPISchema
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{components: make(map[string]*openAPISchema)}
+}
+
+var (
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	timeType          = reflect.TypeOf(time.Time{})
+	uuidType          = reflect.TypeOf(uuid.UUID{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaOf returns the schema of a value's type. A nil value has no schema.
+func (r *schemaRegistry) schemaOf(value interface{}) *openAPISchema {
+	if value == nil {
+		return nil
+	}
+	return r.schemaFor(reflect.TypeOf(value))
+}
+
+func (r *schemaRegistry) schemaFor(t reflect.Type) *openAPISchema {
+	switch t {
+	case rawMessageType:
+		return &openAPISchema{Description: "Any JSON value"}
+	case timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &openAPISchema{Type: "string", Format: "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := r.schemaFor(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &openAPISchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number"}
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: r.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: r.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+			return &openAPISchema{Type: "string"}
+		}
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		if _, ok := r.components[t.Name()]; !ok {
+			// Registered before the fields are described, for recursive types
+			r.components[t.Name()] = &openAPISchema{}
+			*r.components[t.Name()] = *r.structSchema(t)
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		return &openAPISchema{}
+	}
+}
+
+// structSchema describes the JSON object of a struct. Fields of embedded structs are
+// promoted, and fields bound with the required rule are required.
+func (r *schemaRegistry) structSchema(t reflect.Type) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+	r.addFields(schema, t)
+	sort.Strings(schema.Required)
+	return schema
+}
+
+func (r *schemaRegistry) addFields(schema *openAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+			if rule == "required" {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+		schema.Properties[name] = r.schemaFor(field.Type)
+	}
+}
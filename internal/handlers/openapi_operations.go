@@ -0,0 +1,657 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"remote-config-system/internal/db"
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Security requirements of operations
+const (
+	authNone = iota
+	authAPIKey
+	authAdmin
+)
+
+// apiOperation describes an endpoint of the HTTP API in apiOperations
+type apiOperation struct {
+	Tag         string
+	Summary     string
+	Description string
+	Auth        int
+	Role        string // Minimum admin role, for admin operations
+	Query       []apiParameter
+	Headers     []apiParameter
+	Request     interface{} // Zero value of the request body
+	Status      int         // Success status, 200 when unset
+	Response    interface{} // Zero value of the response body
+	Paginated   interface{} // Zero value of the items of a paginated response
+	Config      bool        // Responds with a configuration in the negotiated schema version
+	ConfigBatch bool        // Responds with configurations in the negotiated schema version
+	Formats     bool        // The configuration can also be returned as YAML, TOML or env
+	Cached      bool        // Responds with 304 Not Modified to a matching If-None-Match, or for configurations If-Modified-Since, header
+	MediaType   string      // Media type of a response that is not JSON, such as an event stream
+	Hidden      bool        // Left out of the document
+}
+
+// apiParameter describes a query or header parameter of an operation
+type apiParameter struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+}
+
+// apiTags groups the operations of the document
+var apiTags = []openAPITag{
+	{Name: "Configuration", Description: "Configuration reads for applications"},
+	{Name: "Streaming", Description: "Real-time configuration updates over SSE, WebSocket and long polling"},
+	{Name: "Organizations", Description: "Organization management"},
+	{Name: "Templates", Description: "Organization configuration templates"},
+	{Name: "Applications", Description: "Application management and application defaults"},
+	{Name: "Environments", Description: "Environment management and locks"},
+	{Name: "Configuration Management", Description: "Configuration updates, history, tags, approvals and rollouts"},
+	{Name: "Validation", Description: "JSON Schemas, validation rules and manifest validation"},
+	{Name: "Notifications", Description: "Change notifications over Slack and email"},
+	{Name: "Administration", Description: "Admin tokens, caches, encryption and monitoring"},
+	{Name: "System", Description: "Health checks and API documentation"},
+}
+
+// Parameters shared by several operations
+var (
+	pageParams = []apiParameter{
+		{Name: "page", Type: "integer", Description: "Page number, starting at 1. Lower values are raised to 1"},
+		{Name: "page_size", Type: "integer", Description: "Items per page, defaults to 20. Values above 100 are clamped to 100"},
+	}
+	dryRunParam  = apiParameter{Name: "dry_run", Type: "boolean", Description: "Validate and diff the change without applying it"}
+	labelsParam  = apiParameter{Name: "selector", Type: "string", Description: "Label selector picking the environments, e.g. tier=canary,region!=eu; a bare key requires the label"}
+	tagParam     = apiParameter{Name: "tag", Type: "string", Description: "Serve the version the tag points at"}
+	fieldsParam  = apiParameter{Name: "fields", Type: "string", Description: "Comma-separated dot-notated paths to project the configuration down to"}
+	metaParam    = apiParameter{Name: "meta", Type: "boolean", Description: "Add who created the served version and how long ago; such responses are not cached"}
+	formatParam  = apiParameter{Name: "format", Type: "string", Description: "json, yaml, toml or env; takes precedence over the Accept header"}
+	replayParam  = apiParameter{Name: "replay", Type: "boolean", Description: "Send the recent configuration updates of the environment when connecting"}
+	initialParam = apiParameter{Name: "initial", Type: "boolean", Description: "Send the active configuration as an initial_config event when connecting (default true)"}
+	keysParam    = apiParameter{Name: "keys", Type: "string", Description: "Comma-separated dot-notated keys; only updates changing them are sent, with the configuration reduced to them"}
+	staleParam   = apiParameter{Name: "stale_timeout", Type: "integer", Description: "Seconds the connection may stay idle before it is cleaned up"}
+	clientHeader = apiParameter{Name: "X-Client-ID", Type: "string", Description: "Stable client identifier, which places the client in or out of a gradual rollout"}
+	idempotency  = apiParameter{Name: "Idempotency-Key", Type: "string", Description: "Retries with the same key return the stored response instead of applying the change again"}
+)
+
+// apiOperations describes the endpoints of the HTTP API, keyed by method and gin route
+// path. Every route registered in cmd/api should have an entry; the server logs the
+// routes that do not.
+var apiOperations = map[string]apiOperation{
+	// System
+	"GET /health":       {Tag: "System", Summary: "Health check", Response: models.HealthResponse{}},
+	"GET /openapi.json": {Tag: "System", Summary: "OpenAPI document of the HTTP API", Description: "This document."},
+	"GET /docs":         {Tag: "System", Summary: "Swagger UI for the OpenAPI document", MediaType: "text/html"},
+
+	// Pages served in development mode
+	"GET /":                 {Hidden: true},
+	"GET /dashboard":        {Hidden: true},
+	"GET /demo/sse":         {Hidden: true},
+	"GET /static/*filepath": {Hidden: true},
+
+	// Public configuration reads
+	"GET /config/:org/:app/:env": {
+		Tag:         "Configuration",
+		Summary:     "Get the active configuration",
+		Description: "Returns the active configuration merged with the application defaults and with references resolved.",
+		Query: []apiParameter{
+			{Name: "raw", Type: "boolean", Description: "Return the environment's own keys only, without defaults or resolved references"},
+			tagParam,
+			{Name: "at", Type: "string", Description: "RFC 3339 timestamp; return the environment's own keys of the version that was active then"},
+			fieldsParam, metaParam, formatParam,
+		},
+		Config: true, Formats: true, Cached: true,
+	},
+	"GET /events/:org/:app/:env": {
+		Tag:         "Streaming",
+		Summary:     "Stream configuration updates over SSE",
+		Description: "Each config_update event carries a ConfigUpdateEvent.",
+		Query:       []apiParameter{replayParam, initialParam, keysParam, staleParam},
+		MediaType:   "text/event-stream",
+	},
+	"GET /events/:org/:app/:env/history": {
+		Tag:      "Streaming",
+		Summary:  "Get the recent configuration updates",
+		Response: models.ConfigEventHistoryResponse{},
+	},
+	"GET /ws/:org/:app/:env": {
+		Tag:         "Streaming",
+		Summary:     "Stream configuration updates over a WebSocket",
+		Description: "Upgrades the connection to a WebSocket that receives the same events as the SSE stream.",
+		Query:       []apiParameter{replayParam, initialParam, keysParam, staleParam},
+		Status:      http.StatusSwitchingProtocols,
+	},
+
+	// Configuration reads with an API key
+	"GET /api/config": {
+		Tag:     "Configuration",
+		Summary: "Get the configuration of the application's default environment",
+		Auth:    authAPIKey,
+		Query:   []apiParameter{tagParam, fieldsParam, metaParam, formatParam},
+		Headers: []apiParameter{clientHeader},
+		Config:  true, Formats: true, Cached: true,
+	},
+	"GET /api/config/:env": {
+		Tag:     "Configuration",
+		Summary: "Get the active configuration",
+		Auth:    authAPIKey,
+		Query:   []apiParameter{tagParam, fieldsParam, metaParam, formatParam},
+		Headers: []apiParameter{clientHeader},
+		Config:  true, Formats: true, Cached: true,
+	},
+	"GET /api/config/:env/poll": {
+		Tag:         "Streaming",
+		Summary:     "Wait for a configuration change",
+		Description: "Responds as soon as the active version differs from since_version, or with 304 Not Modified when the timeout expires.",
+		Auth:        authAPIKey,
+		Query: []apiParameter{
+			{Name: "since_version", Type: "integer", Description: "Version the client already has", Required: true},
+			{Name: "timeout", Type: "integer", Description: "Seconds to wait for a change"},
+			fieldsParam,
+		},
+		Headers: []apiParameter{clientHeader},
+		Config:  true,
+	},
+	"GET /api/config/:env/:version": {
+		Tag:     "Configuration",
+		Summary: "Get a configuration version",
+		Auth:    authAPIKey,
+		Query:   []apiParameter{fieldsParam},
+		Config:  true, Cached: true,
+	},
+	"GET /api/flags/:env": {
+		Tag:     "Configuration",
+		Summary: "Get the boolean feature flags",
+		Auth:    authAPIKey,
+		Query: []apiParameter{
+			{Name: "flags", Type: "string", Description: "Comma-separated flag names to return"},
+			{Name: "default", Type: "boolean", Description: "Value of requested flags that are not set"},
+		},
+		Response: map[string]bool{},
+		Cached:   true,
+	},
+	"POST /api/config/batch": {
+		Tag:         "Configuration",
+		Summary:     "Get the configurations of several environments",
+		Auth:        authAPIKey,
+		Headers:     []apiParameter{clientHeader},
+		Request:     models.BatchConfigRequest{},
+		ConfigBatch: true,
+	},
+	"GET /api/events/:env": {
+		Tag:         "Streaming",
+		Summary:     "Stream configuration updates over SSE",
+		Description: "Each config_update event carries a ConfigUpdateEvent.",
+		Auth:        authAPIKey,
+		Query:       []apiParameter{replayParam, initialParam, keysParam, staleParam},
+		MediaType:   "text/event-stream",
+	},
+	"GET /api/events/:env/history": {
+		Tag:      "Streaming",
+		Summary:  "Get the recent configuration updates",
+		Auth:     authAPIKey,
+		Response: models.ConfigEventHistoryResponse{},
+	},
+
+	// Administration
+	"GET /admin/tokens":        {Tag: "Administration", Summary: "List admin tokens", Auth: authAdmin, Role: models.RoleAdmin, Response: []models.AdminToken{}},
+	"POST /admin/tokens":       {Tag: "Administration", Summary: "Create an admin token", Auth: authAdmin, Role: models.RoleAdmin, Request: models.CreateAdminTokenRequest{}, Status: http.StatusCreated, Response: models.CreateAdminTokenResponse{}},
+	"DELETE /admin/tokens/:id": {Tag: "Administration", Summary: "Revoke an admin token", Auth: authAdmin, Role: models.RoleAdmin, Status: http.StatusNoContent},
+	"GET /admin/cache/stats":   {Tag: "Administration", Summary: "Get cache statistics", Auth: authAdmin, Response: map[string]interface{}{}},
+	"POST /admin/cache/stats/reset": {
+		Tag: "Administration", Summary: "Reset cache statistics without clearing the cache", Auth: authAdmin, Role: models.RoleEditor, Response: map[string]interface{}{},
+	},
+	"POST /admin/cache/warm": {
+		Tag: "Administration", Summary: "Warm the cache with active configurations", Auth: authAdmin, Role: models.RoleEditor,
+		Query: []apiParameter{
+			{Name: "org", Type: "string", Description: "Only warm this organization"},
+			{Name: "app", Type: "string", Description: "Only warm this application; requires org"},
+		},
+		Response: map[string]interface{}{},
+	},
+	"GET /admin/cache/warm/status": {Tag: "Administration", Summary: "Get the progress of startup cache warming", Auth: authAdmin, Response: models.CacheWarmStatus{}},
+	"DELETE /admin/cache":          {Tag: "Administration", Summary: "Clear the cache", Auth: authAdmin, Role: models.RoleEditor, Response: map[string]interface{}{}},
+	"GET /admin/db/stats":          {Tag: "Administration", Summary: "Get database connection pool statistics", Auth: authAdmin, Response: db.PoolStats{}},
+	"GET /admin/stats/stale": {
+		Tag: "Administration", Summary: "List environments whose configuration is no longer changed or read", Auth: authAdmin,
+		Description: "Returns the latest background check, which flags environments whose active configuration has not changed, or has not been read with the application's API key, for longer than CONFIG_STALE_DAYS days. Reads are only tracked with Redis.",
+		Query:       []apiParameter{{Name: "refresh", Type: "boolean", Description: "Run the check now instead of returning the latest one"}},
+		Response:    models.StaleConfigReport{},
+	},
+	"POST /admin/encryption/reencrypt": {Tag: "Administration", Summary: "Re-encrypt secret values with the primary key", Auth: authAdmin, Role: models.RoleAdmin, Response: models.ReencryptSecretsResponse{}},
+	"GET /admin/sse/stats":             {Tag: "Administration", Summary: "Get SSE statistics and connected clients", Auth: authAdmin, Response: map[string]interface{}{}},
+	"DELETE /admin/sse/clients/:id": {
+		Tag: "Administration", Summary: "Disconnect an SSE client", Auth: authAdmin, Role: models.RoleAdmin, Status: http.StatusNoContent,
+	},
+	"POST /admin/validate/manifest": {
+		Tag: "Validation", Summary: "Validate configurations for many environments", Auth: authAdmin,
+		Request: []models.ManifestItem{}, Status: http.StatusMultiStatus, Response: models.ManifestValidationResponse{},
+	},
+	"GET /admin/search": {
+		Tag: "Configuration Management", Summary: "Find environments whose active configuration has a key or value", Auth: authAdmin,
+		Query: append([]apiParameter{
+			{Name: "key", Type: "string", Description: "Dot-notated key path"},
+			{Name: "value", Type: "string", Description: "Value to match"},
+		}, pageParams...),
+		Paginated: models.ConfigSearchResult{},
+	},
+
+	// Organizations
+	"GET /admin/orgs":  {Tag: "Organizations", Summary: "List organizations", Auth: authAdmin, Query: pageParams, Paginated: models.Organization{}},
+	"POST /admin/orgs": {Tag: "Organizations", Summary: "Create an organization", Auth: authAdmin, Role: models.RoleEditor, Request: models.CreateOrganizationRequest{}, Status: http.StatusCreated, Response: models.Organization{}},
+	"GET /admin/orgs/:org": {
+		Tag: "Organizations", Summary: "Get an organization", Auth: authAdmin,
+		Description: "With include=apps, the organization's applications are nested under applications.",
+		Query:       []apiParameter{{Name: "include", Type: "string", Description: "Comma-separated optional sections: apps"}},
+		Response:    models.OrganizationWithApplications{},
+	},
+	"PUT /admin/orgs/:org":    {Tag: "Organizations", Summary: "Update an organization", Auth: authAdmin, Role: models.RoleEditor, Request: models.UpdateOrganizationRequest{}, Response: models.Organization{}},
+	"DELETE /admin/orgs/:org": {Tag: "Organizations", Summary: "Delete an organization", Auth: authAdmin, Role: models.RoleEditor, Status: http.StatusNoContent},
+	"GET /admin/orgs/:org/tree": {
+		Tag: "Organizations", Summary: "Get the tree of applications and environments", Auth: authAdmin,
+		Description: "Levels beyond depth are null.",
+		Query:       []apiParameter{{Name: "depth", Type: "integer", Description: "0 for the organization alone, 1 to add applications, 2 (default) to add environments"}},
+		Response:    models.OrganizationTree{},
+	},
+	"GET /admin/orgs/:org/quota": {
+		Tag: "Organizations", Summary: "Get the monthly read quota of an organization", Auth: authAdmin,
+		Description: "Configuration reads of the organization in the current calendar month (UTC) and the remaining reads of its quota. Counts are stored periodically; reads counted in Redis since the last store are included while Redis is available.",
+		Response:    models.OrganizationQuota{},
+	},
+
+	// Templates
+	"GET /admin/orgs/:org/templates":           {Tag: "Templates", Summary: "List configuration templates", Auth: authAdmin, Response: []models.ConfigTemplate{}},
+	"GET /admin/orgs/:org/templates/:template": {Tag: "Templates", Summary: "Get a configuration template", Auth: authAdmin, Response: models.ConfigTemplate{}},
+	"PUT /admin/orgs/:org/templates/:template": {
+		Tag: "Templates", Summary: "Create or update a configuration template", Auth: authAdmin, Role: models.RoleEditor,
+		Description: "String values may contain {{variable}} placeholders. Responds with 201 Created for a new template and 200 OK when an existing template was updated.",
+		Request:     models.SetConfigTemplateRequest{}, Status: http.StatusCreated, Response: models.ConfigTemplate{},
+	},
+	"DELETE /admin/orgs/:org/templates/:template": {Tag: "Templates", Summary: "Delete a configuration template", Auth: authAdmin, Role: models.RoleEditor, Status: http.StatusNoContent},
+
+	// Applications
+	"GET /admin/orgs/:org/apps":         {Tag: "Applications", Summary: "List applications", Auth: authAdmin, Query: pageParams, Paginated: models.Application{}},
+	"POST /admin/orgs/:org/apps":        {Tag: "Applications", Summary: "Create an application", Auth: authAdmin, Role: models.RoleEditor, Request: models.CreateApplicationRequest{}, Status: http.StatusCreated, Response: models.Application{}},
+	"GET /admin/orgs/:org/apps/:app":    {Tag: "Applications", Summary: "Get an application", Auth: authAdmin, Response: models.Application{}},
+	"PUT /admin/orgs/:org/apps/:app":    {Tag: "Applications", Summary: "Update an application", Auth: authAdmin, Role: models.RoleEditor, Request: models.UpdateApplicationRequest{}, Response: models.Application{}},
+	"DELETE /admin/orgs/:org/apps/:app": {Tag: "Applications", Summary: "Delete an application", Auth: authAdmin, Role: models.RoleEditor, Status: http.StatusNoContent},
+	"GET /admin/orgs/:org/apps/:app/usage": {
+		Tag: "Applications", Summary: "Get the API key usage of an application", Auth: authAdmin,
+		Description: "Requests made with the API key, in total, per environment and per day. Counts are stored periodically, so the latest requests may not be included yet.",
+		Query:       []apiParameter{{Name: "days", Type: "integer", Description: "Number of days to report, including today (1-365, default 30)"}},
+		Response:    models.ApplicationUsage{},
+	},
+	"POST /admin/orgs/:org/apps/:app/api-key/rotate": {
+		Tag: "Applications", Summary: "Rotate the API key of an application", Auth: authAdmin, Role: models.RoleAdmin,
+		Description: "Replaces the API key with a newly generated one, which is only returned in this response. The previous key stops working at once.",
+		Response:    models.Application{},
+	},
+	"GET /admin/orgs/:org/apps/:app/defaults": {
+		Tag: "Applications", Summary: "Get the application defaults", Auth: authAdmin, Response: models.ApplicationDefaults{},
+	},
+	"PUT /admin/orgs/:org/apps/:app/defaults": {
+		Tag: "Applications", Summary: "Set the application defaults", Auth: authAdmin, Role: models.RoleEditor,
+		Request: models.UpdateApplicationDefaultsRequest{}, Response: models.ApplicationDefaults{},
+	},
+	"DELETE /admin/orgs/:org/apps/:app/defaults": {
+		Tag: "Applications", Summary: "Remove the application defaults", Auth: authAdmin, Role: models.RoleEditor, Status: http.StatusNoContent,
+	},
+
+	// Environments
+	"GET /admin/orgs/:org/apps/:app/envs": {Tag: "Environments", Summary: "List environments", Auth: authAdmin, Query: pageParams, Paginated: models.Environment{}},
+	"POST /admin/orgs/:org/apps/:app/envs": {
+		Tag: "Environments", Summary: "Create an environment", Auth: authAdmin, Role: models.RoleEditor,
+		Description: "An initial config is created as version 1 in the same transaction; if it is rejected, the environment is not created.",
+		Request:     models.CreateEnvironmentRequest{}, Status: http.StatusCreated, Response: models.Environment{},
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/bulk": {
+		Tag: "Environments", Summary: "Create several environments", Auth: authAdmin, Role: models.RoleEditor,
+		Description: "Responds with 207 Multi-Status when some environments could not be created.",
+		Query:       []apiParameter{{Name: "continue_on_error", Type: "boolean", Description: "Create the other environments when one fails instead of creating none"}},
+		Request:     models.BulkCreateEnvironmentsRequest{}, Status: http.StatusCreated, Response: models.BulkCreateEnvironmentsResponse{},
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env": {
+		Tag: "Environments", Summary: "Get an environment", Auth: authAdmin,
+		Query:    []apiParameter{{Name: "include", Type: "string", Description: "Comma-separated optional sections: config_summary"}},
+		Response: models.Environment{},
+	},
+	"PUT /admin/orgs/:org/apps/:app/envs/:env": {
+		Tag: "Environments", Summary: "Update an environment", Auth: authAdmin, Role: models.RoleEditor,
+		Request: models.UpdateEnvironmentRequest{}, Response: models.Environment{},
+	},
+	"DELETE /admin/orgs/:org/apps/:app/envs/:env": {
+		Tag: "Environments", Summary: "Delete an environment", Auth: authAdmin, Role: models.RoleEditor, Status: http.StatusNoContent,
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env/labels": {
+		Tag: "Environments", Summary: "List the environment's labels", Auth: authAdmin, Response: models.EnvironmentLabels{},
+	},
+	"PUT /admin/orgs/:org/apps/:app/envs/:env/labels/:label": {
+		Tag: "Environments", Summary: "Set an environment label", Auth: authAdmin, Role: models.RoleEditor,
+		Description: "Responds with 201 Created for a new label and 200 OK when an existing label changed, with every label of the environment.",
+		Request:     models.SetLabelRequest{}, Response: models.EnvironmentLabels{},
+	},
+	"DELETE /admin/orgs/:org/apps/:app/envs/:env/labels/:label": {
+		Tag: "Environments", Summary: "Remove an environment label", Auth: authAdmin, Role: models.RoleEditor, Status: http.StatusNoContent,
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/:env/lock": {
+		Tag: "Environments", Summary: "Lock the environment against configuration changes", Auth: authAdmin, Role: models.RoleAdmin,
+		Request: models.LockEnvironmentRequest{}, Response: models.Environment{},
+	},
+	"DELETE /admin/orgs/:org/apps/:app/envs/:env/lock": {
+		Tag: "Environments", Summary: "Unlock the environment", Auth: authAdmin, Role: models.RoleAdmin, Response: models.Environment{},
+	},
+
+	// Configuration management
+	"PUT /admin/orgs/:org/apps/:app/envs/:env/config": {
+		Tag: "Configuration Management", Summary: "Update the configuration", Auth: authAdmin, Role: models.RoleEditor,
+		Description: "Responds with 202 Accepted when the environment requires approval. A configuration matching the active version creates no version and returns it with unchanged set, unless allow_unchanged is set. The body can also be sent as YAML or TOML.",
+		Query:       []apiParameter{dryRunParam},
+		Headers:     []apiParameter{idempotency},
+		Request:     models.CreateConfigRequest{}, Config: true,
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env/config/download": {
+		Tag: "Configuration Management", Summary: "Download the active configuration as a file", Auth: authAdmin,
+		Description: "Returns the configuration document alone, without the response envelope, with a Content-Disposition header naming the file <org>-<app>-<env>-v<version>.<format>.",
+		Query:       []apiParameter{{Name: "raw", Type: "boolean", Description: "Download the environment's own keys only, without defaults or resolved references"}, formatParam},
+		MediaType:   "application/json",
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/:env/config/diff-draft": {
+		Tag: "Configuration Management", Summary: "Preview the diff of a draft configuration", Auth: authAdmin,
+		Request: models.DiffDraftRequest{}, Response: models.ConfigDiffResponse{},
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/:env/config/lint": {
+		Tag: "Configuration Management", Summary: "Check a configuration for common mistakes", Auth: authAdmin,
+		Description: "Reports placeholder values, addresses of the local host in production environments, empty values of keys such as urls and passwords, and keys that differ only by case. Nothing is saved or rejected.",
+		Request:     models.LintConfigRequest{}, Response: models.ConfigLintResponse{},
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/:env/config/encrypt-secrets": {
+		Tag: "Configuration Management", Summary: "Encrypt stored plaintext secret values", Auth: authAdmin, Role: models.RoleAdmin,
+		Response: models.EncryptSecretsResponse{},
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env/history": {
+		Tag: "Configuration Management", Summary: "Get the configuration history", Auth: authAdmin, Query: pageParams, Paginated: map[string]interface{}{},
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env/history/:version": {
+		Tag: "Configuration Management", Summary: "Get a configuration version", Auth: authAdmin, Query: []apiParameter{fieldsParam}, Config: true, Cached: true,
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env/history/key/:keyPath": {
+		Tag: "Configuration Management", Summary: "Get the values a configuration key took across versions", Auth: authAdmin,
+		Description: "Lists the changes of the key's value, oldest first, leaving out versions in which it kept its value. Values of secret keys are masked and blob values summarized.",
+		Response:    models.KeyHistoryResponse{},
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/:env/history/prune": {
+		Tag: "Configuration Management", Summary: "Prune old configuration versions", Auth: authAdmin, Role: models.RoleAdmin,
+		Response: models.PruneHistoryResponse{},
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env/changes": {
+		Tag: "Configuration Management", Summary: "Get the change log", Auth: authAdmin, Query: pageParams, Paginated: map[string]interface{}{},
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env/changes/export": {
+		Tag: "Configuration Management", Summary: "Export the change log as CSV", Auth: authAdmin,
+		Description: "Streams the changes oldest first, one row each, with the columns created_at, organization, application, environment, scope, action, version_from, version_to, created_by, approved_by and message.",
+		Query: []apiParameter{
+			{Name: "format", Type: "string", Description: "Export format; only csv is supported"},
+			{Name: "scope", Type: "string", Description: "environment (the default), or org to export the changes of every environment of the organization"},
+		},
+		MediaType: "text/csv",
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/:env/rollback": {
+		Tag: "Configuration Management", Summary: "Roll back to a previous version", Auth: authAdmin, Role: models.RoleEditor,
+		Description: "The version is given as exactly one of to_version and to_tag.",
+		Headers:     []apiParameter{idempotency}, Request: models.RollbackRequest{}, Config: true,
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/:env/config/cas": {
+		Tag: "Configuration Management", Summary: "Set a key if it has the expected value", Auth: authAdmin, Role: models.RoleEditor,
+		Description: "Atomically sets the key to value and creates a new version, only if the key's current value equals expected; omitting expected requires the key to be absent. Responds with 409 Conflict on a mismatch.",
+		Request:     models.CompareAndSwapRequest{}, Config: true,
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/:env/promote": {
+		Tag: "Configuration Management", Summary: "Promote another environment's configuration", Auth: authAdmin, Role: models.RoleEditor,
+		Description: "Responds with 202 Accepted when the environment requires approval.",
+		Query:       []apiParameter{dryRunParam},
+		Headers:     []apiParameter{idempotency},
+		Request:     models.PromoteConfigRequest{}, Config: true,
+	},
+	"POST /admin/orgs/:org/apps/:app/config/batch-update": {
+		Tag: "Configuration Management", Summary: "Update several environments atomically", Auth: authAdmin, Role: models.RoleEditor,
+		Description: "Either every environment gets a new version or none does. When an environment fails its checks, responds with 207 Multi-Status and the reason of each environment under errors. With selector, the environments the selector picks all get config instead of being listed in configs.",
+		Query:       []apiParameter{dryRunParam, labelsParam},
+		Headers:     []apiParameter{idempotency},
+		Request:     models.BatchUpdateConfigRequest{}, ConfigBatch: true,
+	},
+	"POST /admin/orgs/:org/config/batch-update": {
+		Tag: "Configuration Management", Summary: "Update the environments a label selector picks atomically", Auth: authAdmin, Role: models.RoleEditor,
+		Description: "Every environment of the organization's applications that the selector picks gets config as a new version, or none does. The response is keyed by application and environment slug, e.g. web/canary.",
+		Query:       []apiParameter{dryRunParam, {Name: "selector", Type: "string", Description: labelsParam.Description, Required: true}},
+		Headers:     []apiParameter{idempotency},
+		Request:     models.BatchUpdateConfigRequest{}, ConfigBatch: true,
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/:env/config/from-template": {
+		Tag: "Configuration Management", Summary: "Create a configuration from a template", Auth: authAdmin, Role: models.RoleEditor,
+		Description: "Every variable the template uses must be given. Responds with 202 Accepted when the environment requires approval.",
+		Query:       []apiParameter{dryRunParam},
+		Headers:     []apiParameter{idempotency},
+		Request:     models.InstantiateTemplateRequest{}, Config: true,
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env/tags": {
+		Tag: "Configuration Management", Summary: "List version tags", Auth: authAdmin, Response: []models.ConfigTag{},
+	},
+	"PUT /admin/orgs/:org/apps/:app/envs/:env/tags/:tag": {
+		Tag: "Configuration Management", Summary: "Create or move a version tag", Auth: authAdmin, Role: models.RoleEditor,
+		Description: "Responds with 201 Created for a new tag and 200 OK when an existing tag was moved.",
+		Request:     models.SetTagRequest{}, Status: http.StatusCreated, Response: models.ConfigTag{},
+	},
+	"DELETE /admin/orgs/:org/apps/:app/envs/:env/tags/:tag": {
+		Tag: "Configuration Management", Summary: "Delete a version tag", Auth: authAdmin, Role: models.RoleEditor, Status: http.StatusNoContent,
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env/config/pending": {
+		Tag: "Configuration Management", Summary: "List proposed configuration changes", Auth: authAdmin,
+		Query:     append([]apiParameter{{Name: "status", Type: "string", Description: "pending (default), approved or rejected"}}, pageParams...),
+		Paginated: models.PendingChange{},
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/:env/config/approve": {
+		Tag: "Configuration Management", Summary: "Approve and activate a proposed change", Auth: authAdmin, Role: models.RoleAdmin,
+		Request: models.ReviewChangeRequest{}, Config: true,
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/:env/config/reject": {
+		Tag: "Configuration Management", Summary: "Reject a proposed change", Auth: authAdmin, Role: models.RoleAdmin,
+		Request: models.ReviewChangeRequest{}, Response: models.PendingChange{},
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env/rollout": {
+		Tag: "Configuration Management", Summary: "Get the rollout in progress", Auth: authAdmin, Response: models.ConfigRollout{},
+	},
+	"PUT /admin/orgs/:org/apps/:app/envs/:env/rollout": {
+		Tag: "Configuration Management", Summary: "Change the rollout percentage", Auth: authAdmin, Role: models.RoleEditor,
+		Request: models.UpdateRolloutRequest{}, Response: models.ConfigRollout{},
+	},
+	"POST /admin/orgs/:org/apps/:app/envs/:env/rollout/promote": {
+		Tag: "Configuration Management", Summary: "Promote the rollout to every client", Auth: authAdmin, Role: models.RoleEditor,
+		Request: models.PromoteRolloutRequest{}, Config: true,
+	},
+	"DELETE /admin/orgs/:org/apps/:app/envs/:env/rollout": {
+		Tag: "Configuration Management", Summary: "Abort the rollout", Auth: authAdmin, Role: models.RoleEditor, Status: http.StatusNoContent,
+	},
+
+	// Validation
+	"GET /admin/orgs/:org/apps/:app/envs/:env/schema": {
+		Tag: "Validation", Summary: "Get the configuration JSON Schema", Auth: authAdmin, Response: models.ConfigSchema{},
+	},
+	"PUT /admin/orgs/:org/apps/:app/envs/:env/schema": {
+		Tag: "Validation", Summary: "Set the configuration JSON Schema", Auth: authAdmin, Role: models.RoleEditor,
+		Request: models.UpdateConfigSchemaRequest{}, Response: models.ConfigSchema{},
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env/form-schema": {
+		Tag: "Validation", Summary: "Get the schema pre-filled with the current values", Auth: authAdmin, Response: models.FormSchemaResponse{},
+	},
+	"GET /admin/orgs/:org/apps/:app/envs/:env/rules": {
+		Tag: "Validation", Summary: "Get the validation rules", Auth: authAdmin, Response: models.ConfigRuleset{},
+	},
+	"PUT /admin/orgs/:org/apps/:app/envs/:env/rules": {
+		Tag: "Validation", Summary: "Set the validation rules", Auth: authAdmin, Role: models.RoleEditor,
+		Request: models.UpdateConfigRulesRequest{}, Response: models.ConfigRuleset{},
+	},
+	"DELETE /admin/orgs/:org/apps/:app/envs/:env/rules": {
+		Tag: "Validation", Summary: "Remove the validation rules", Auth: authAdmin, Role: models.RoleEditor, Status: http.StatusNoContent,
+	},
+
+	// Notifications
+	"GET /admin/orgs/:org/apps/:app/envs/:env/notifications": {
+		Tag: "Notifications", Summary: "Get the change notification settings", Auth: authAdmin, Role: models.RoleEditor,
+		Response: models.NotificationSettings{},
+	},
+	"PUT /admin/orgs/:org/apps/:app/envs/:env/notifications": {
+		Tag: "Notifications", Summary: "Set the change notification settings", Auth: authAdmin, Role: models.RoleEditor,
+		Request: models.UpdateNotificationSettingsRequest{}, Response: models.NotificationSettings{},
+	},
+	"DELETE /admin/orgs/:org/apps/:app/envs/:env/notifications": {
+		Tag: "Notifications", Summary: "Remove the change notification settings", Auth: authAdmin, Role: models.RoleEditor, Status: http.StatusNoContent,
+	},
+}
+
+// pathParamDescriptions describes the path parameters of the routes
+var pathParamDescriptions = map[string]string{
+	"org":      "Organization slug",
+	"app":      "Application slug",
+	"env":      "Environment slug",
+	"version":  "Configuration version",
+	"tag":      "Tag name",
+	"label":    "Label key, e.g. tier",
+	"template": "Template name",
+	"keyPath":  "Dot-notated configuration key, e.g. database.timeout",
+	"id":       "Admin token or SSE client ID",
+}
+
+// describe converts the operation of a route to its OpenAPI form
+func (op apiOperation) describe(route gin.RouteInfo, pathParams []string, schemas *schemaRegistry) *openAPIOperation {
+	operation := &openAPIOperation{
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: handlerName(route),
+		Responses:   make(map[string]*openAPIResponse),
+	}
+	if op.Tag != "" {
+		operation.Tags = []string{op.Tag}
+	}
+
+	for _, name := range pathParams {
+		schema := &openAPISchema{Type: "string"}
+		if name == "version" {
+			schema.Type = "integer"
+		}
+		operation.Parameters = append(operation.Parameters, openAPIParameter{
+			Name: name, In: "path", Description: pathParamDescriptions[name], Required: true, Schema: schema,
+		})
+	}
+	for _, params := range []struct {
+		in     string
+		params []apiParameter
+	}{{"query", op.Query}, {"header", op.Headers}} {
+		for _, param := range params.params {
+			operation.Parameters = append(operation.Parameters, openAPIParameter{
+				Name: param.Name, In: params.in, Description: param.Description, Required: param.Required,
+				Schema: &openAPISchema{Type: param.Type},
+			})
+		}
+	}
+
+	switch op.Auth {
+	case authAPIKey:
+		operation.Security = []map[string][]string{{securityAPIKey: {}}, {securityBearer: {}}}
+	case authAdmin:
+		operation.Security = []map[string][]string{{securityAdminToken: {}}}
+		role := op.Role
+		if role == "" {
+			role = models.RoleViewer
+		}
+		operation.Description = joinSentences(operation.Description, "Requires the "+role+" role.")
+	}
+
+	if op.Request != nil {
+		operation.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content:  map[string]openAPIMediaType{"application/json": {Schema: schemas.schemaOf(op.Request)}},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &openAPIResponse{Description: http.StatusText(status)}
+	switch {
+	case op.Config:
+		success.Content = map[string]openAPIMediaType{"application/json": {Schema: configSchema(schemas)}}
+		if op.Formats {
+			for _, mediaType := range []string{"application/yaml", "application/toml"} {
+				success.Content[mediaType] = openAPIMediaType{Schema: configSchema(schemas)}
+			}
+			success.Content["text/plain"] = openAPIMediaType{Schema: &openAPISchema{Type: "string", Description: "The configuration document as KEY=value lines"}}
+		}
+	case op.ConfigBatch:
+		success.Content = map[string]openAPIMediaType{"application/json": {Schema: &openAPISchema{
+			Type: "object",
+			Properties: map[string]*openAPISchema{
+				"configs": {Type: "object", AdditionalProperties: configSchema(schemas)},
+				"errors":  schemas.schemaOf(map[string]models.BatchConfigError{}),
+			},
+		}}}
+	case op.Paginated != nil:
+		success.Content = map[string]openAPIMediaType{"application/json": {Schema: &openAPISchema{
+			AllOf: []*openAPISchema{
+				schemas.schemaOf(models.PaginatedResponse{}),
+				{Type: "object", Properties: map[string]*openAPISchema{"data": {Type: "array", Items: schemas.schemaOf(op.Paginated)}}},
+			},
+		}}}
+	case op.MediaType != "":
+		success.Content = map[string]openAPIMediaType{op.MediaType: {Schema: &openAPISchema{Type: "string"}}}
+		if op.MediaType == "text/event-stream" {
+			// Events are described by the component they carry
+			schemas.schemaOf(models.ConfigUpdateEvent{})
+		}
+	case op.Response != nil:
+		success.Content = map[string]openAPIMediaType{"application/json": {Schema: schemas.schemaOf(op.Response)}}
+	}
+	operation.Responses[strconv.Itoa(status)] = success
+
+	if op.Cached {
+		operation.Parameters = append(operation.Parameters, openAPIParameter{
+			Name: "If-None-Match", In: "header", Description: "ETag of the configuration the client already has", Schema: &openAPISchema{Type: "string"},
+		})
+		if op.Config {
+			operation.Parameters = append(operation.Parameters, openAPIParameter{
+				Name: "If-Modified-Since", In: "header", Description: "Last-Modified date of the configuration the client already has; ignored when If-None-Match is sent", Schema: &openAPISchema{Type: "string"},
+			})
+		}
+		operation.Responses[strconv.Itoa(http.StatusNotModified)] = &openAPIResponse{Description: http.StatusText(http.StatusNotModified)}
+	}
+	operation.Responses["default"] = &openAPIResponse{
+		Description: "Error",
+		Content:     map[string]openAPIMediaType{"application/json": {Schema: schemas.schemaOf(models.ErrorResponse{})}},
+	}
+
+	return operation
+}
+
+// configSchema is the schema of a configuration response, which depends on the
+// negotiated schema version
+func configSchema(schemas *schemaRegistry) *openAPISchema {
+	return &openAPISchema{OneOf: []*openAPISchema{
+		schemas.schemaOf(models.ConfigResponseV1{}),
+		schemas.schemaOf(models.ConfigResponseV2{}),
+	}}
+}
+
+// joinSentences appends a sentence to a description
+func joinSentences(description, sentence string) string {
+	if description == "" {
+		return sentence
+	}
+	return description + " " + sentence
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewConfigHandler(nil)
+	router := gin.New()
+	openAPI := NewOpenAPIHandler(router.Routes)
+	router.GET("/openapi.json", openAPI.GetSpec)
+	router.GET("/docs", openAPI.SwaggerUI)
+	router.GET("/config/:org/:app/:env", handler.GetConfig)
+	router.PUT("/admin/orgs/:org/apps/:app/envs/:env/config", handler.UpdateConfig)
+	router.GET("/admin/orgs/:org/apps/:app/envs/:env/config/pending", handler.ListPendingChanges)
+	router.Static("/static", ".")
+	router.GET("/internal", handler.HealthCheck)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, "3.0.3", document["openapi"])
+
+	paths := document["paths"].(map[string]interface{})
+	assert.NotContains(t, paths, "/static/{filepath}", "hidden routes are left out")
+
+	t.Run("operations are taken from the registered routes", func(t *testing.T) {
+		get := paths["/config/{org}/{app}/{env}"].(map[string]interface{})["get"].(map[string]interface{})
+		assert.Equal(t, "GetConfig", get["operationId"])
+		assert.Equal(t, []interface{}{"Configuration"}, get["tags"])
+		assert.Contains(t, get["responses"], "304")
+		assert.Nil(t, get["security"])
+
+		params := get["parameters"].([]interface{})
+		assert.Equal(t, map[string]interface{}{
+			"name": "org", "in": "path", "description": "Organization slug", "required": true,
+			"schema": map[string]interface{}{"type": "string"},
+		}, params[0])
+
+		put := paths["/admin/orgs/{org}/apps/{app}/envs/{env}/config"].(map[string]interface{})["put"].(map[string]interface{})
+		assert.Equal(t, "UpdateConfig", put["operationId"])
+		assert.Contains(t, put["description"], "Requires the editor role.")
+		assert.Equal(t, []interface{}{map[string]interface{}{"adminToken": []interface{}{}}}, put["security"])
+		assert.Contains(t, w.Body.String(), `"requestBody":{"required":true,"content":{"application/json":{"schema":{"$ref":"#/components/schemas/CreateConfigRequest"}}}}`)
+	})
+
+	t.Run("undocumented routes are listed without models", func(t *testing.T) {
+		assert.Equal(t, []string{"GET /internal"}, openAPI.Undocumented())
+
+		get := paths["/internal"].(map[string]interface{})["get"].(map[string]interface{})
+		assert.Equal(t, "Undocumented endpoint", get["summary"])
+	})
+
+	t.Run("models are described as they are marshaled", func(t *testing.T) {
+		schemas := document["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+
+		request := schemas["CreateConfigRequest"].(map[string]interface{})
+		assert.Equal(t, []interface{}{"config"}, request["required"])
+
+		// Fields of the embedded response are promoted
+		v2 := schemas["ConfigResponseV2"].(map[string]interface{})["properties"].(map[string]interface{})
+		assert.Contains(t, v2, "schema_version")
+		assert.Contains(t, v2, "content_hash")
+		assert.Equal(t, map[string]interface{}{"type": "string", "format": "date-time"}, v2["updated_at"])
+		assert.Equal(t, map[string]interface{}{"type": "string", "format": "uuid", "nullable": true}, v2["version_id"])
+
+		// Every reference points to a component
+		for _, match := range regexp.MustCompile(`"#/components/schemas/(\w+)"`).FindAllStringSubmatch(w.Body.String(), -1) {
+			assert.Contains(t, schemas, match[1])
+		}
+	})
+
+	t.Run("swagger UI loads the document", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/docs", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/html"))
+		assert.Contains(t, w.Body.String(), `url: "/openapi.json"`)
+	})
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePagination parses the page and page_size query parameters of list requests.
+// Out-of-range values are clamped instead of rejected, so page_size=500 returns pages
+// of models.MaxPageSize and the response echoes the page_size that was used. It only
+// responds with 400 for values that are not integers.
+func parsePagination(c *gin.Context) (models.PaginationParams, bool) {
+	params := models.DefaultPaginationParams()
+
+	var fields []models.FieldError
+	for _, param := range []struct {
+		name   string
+		target *int
+	}{{"page", &params.Page}, {"page_size", &params.PageSize}} {
+		raw := c.Query(param.name)
+		if raw == "" {
+			continue
+		}
+
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			fields = append(fields, models.FieldError{
+				Field:      param.name,
+				Constraint: "type",
+				Message:    "must be an integer",
+			})
+			continue
+		}
+		*param.target = value
+	}
+
+	if len(fields) > 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "invalid_parameters",
+			Message:   "Invalid query parameters: " + fieldErrorsMessage(fields),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+			Fields:    fields,
+		})
+		return params, false
+	}
+
+	return params.Clamp(), true
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	parse := func(query string) (*httptest.ResponseRecorder, models.PaginationParams, bool) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/admin/orgs"+query, nil)
+
+		params, ok := parsePagination(c)
+		return w, params, ok
+	}
+
+	tests := map[string]models.PaginationParams{
+		"":                         {Page: 1, PageSize: 20},
+		"?page=3&page_size=50":     {Page: 3, PageSize: 50},
+		"?page=0&page_size=500":    {Page: 1, PageSize: 100},
+		"?page=-2&page_size=0":     {Page: 1, PageSize: 1},
+		"?page_size=100":           {Page: 1, PageSize: 100},
+		"?page=2&page_size=-10000": {Page: 2, PageSize: 1},
+	}
+	for query, expected := range tests {
+		_, params, ok := parse(query)
+		assert.True(t, ok, query)
+		assert.Equal(t, expected, params, query)
+	}
+
+	t.Run("non-integer values are rejected", func(t *testing.T) {
+		w, _, ok := parse("?page=two&page_size=1.5")
+		assert.False(t, ok)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "invalid_parameters", response.Error)
+		assert.Equal(t, "Invalid query parameters: page: must be an integer; page_size: must be an integer", response.Message)
+		assert.Equal(t, []models.FieldError{
+			{Field: "page", Constraint: "type", Message: "must be an integer"},
+			{Field: "page_size", Constraint: "type", Message: "must be an integer"},
+		}, response.Fields)
+	})
+}
+
+func TestManagementHandler_ListOrganizationsRejectsMalformedPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Pagination is validated before the service is used
+	handler := NewManagementHandler(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/admin/orgs?page_size=lots", nil)
+
+	handler.ListOrganizations(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"remote-config-system/internal/middleware"
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// shapeConfig converts a configuration response to the shape of a schema version
+func shapeConfig(config *models.ConfigResponse, version int) interface{} {
+	if version < models.ConfigSchemaV2 {
+		return &models.ConfigResponseV1{
+			Organization:    config.Organization,
+			Application:     config.Application,
+			Environment:     config.Environment,
+			Version:         config.Version,
+			Config:          config.Config,
+			UpdatedAt:       config.UpdatedAt,
+			DryRun:          config.DryRun,
+			Unchanged:       config.Unchanged,
+			Diff:            config.Diff,
+			Pending:         config.Pending,
+			Tag:             config.Tag,
+			At:              config.At,
+			Rollout:         config.Rollout,
+			DefaultsVersion: config.DefaultsVersion,
+			Meta:            config.Meta,
+		}
+	}
+
+	return &models.ConfigResponseV2{
+		SchemaVersion:  models.ConfigSchemaV2,
+		ConfigResponse: config,
+		ContentHash:    contentHash(config.Config),
+	}
+}
+
+// contentHash returns the hex SHA-256 of a configuration document. The document is
+// compacted first, so that the hash does not depend on how it was formatted.
+func contentHash(document json.RawMessage) string {
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, document); err != nil {
+		compacted.Reset()
+		compacted.Write(document)
+	}
+
+	sum := sha256.Sum256(compacted.Bytes())
+	return hex.EncodeToString(sum[:])
+}
+
+// setSchemaVersionHeaders reports the schema version a response was shaped for. Shared
+// caches must key on the version headers, since they change the response body.
+func setSchemaVersionHeaders(c *gin.Context, version int) {
+	c.Header(middleware.SchemaVersionHeader, strconv.Itoa(version))
+	c.Writer.Header().Add("Vary", middleware.SchemaVersionHeader+", "+middleware.AcceptVersionHeader)
+}
+
+// respondConfig writes a configuration response in the negotiated schema version
+func respondConfig(c *gin.Context, status int, config *models.ConfigResponse) {
+	version := middleware.SchemaVersion(c)
+	setSchemaVersionHeaders(c, version)
+	c.JSON(status, shapeConfig(config, version))
+}
+
+// respondConfigBatch writes a batch of configuration responses in the negotiated schema
+// version
+func respondConfigBatch(c *gin.Context, status int, batch *models.BatchConfigResponse) {
+	version := middleware.SchemaVersion(c)
+	setSchemaVersionHeaders(c, version)
+
+	configs := make(map[string]interface{}, len(batch.Configs))
+	for env, config := range batch.Configs {
+		configs[env] = shapeConfig(config, version)
+	}
+	c.JSON(status, gin.H{
+		"configs": configs,
+		"errors":  batch.Errors,
+	})
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"remote-config-system/internal/middleware"
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestContentHash(t *testing.T) {
+	// Formatting does not change the hash, content does
+	compact := contentHash(json.RawMessage(`{"a":1,"b":[true]}`))
+	assert.Equal(t, compact, contentHash(json.RawMessage("{\n  \"a\": 1,\n  \"b\": [true]\n}")))
+	assert.NotEqual(t, compact, contentHash(json.RawMessage(`{"a":2,"b":[true]}`)))
+	assert.Len(t, compact, 64)
+}
+
+func TestConfigHandler_SchemaVersions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	versionID := uuid.New()
+	config := testutil.CreateTestConfigResponse("test-org", "test-app", "prod", 4)
+	config.VersionID = &versionID
+
+	mockService := &testutil.MockConfigService{}
+	mockService.On("GetConfiguration", "test-org", "test-app", "prod").Return(config, nil)
+	mockService.On("GetConfigurationsByAPIKey", testAPIKeyApp, []string{"prod"}, "").
+		Return(&models.BatchConfigResponse{
+			Configs: map[string]*models.ConfigResponse{"prod": config},
+			Errors:  map[string]models.BatchConfigError{},
+		}, nil)
+
+	handler := NewConfigHandler(mockService)
+	router := gin.New()
+	router.Use(middleware.ConfigSchemaVersion())
+	router.GET("/config/:org/:app/:env", handler.GetConfig)
+	router.POST("/api/config/batch", func(c *gin.Context) {
+		c.Set("application", testAPIKeyApp)
+		handler.GetConfigBatchByAPIKey(c)
+	})
+
+	get := func(headers map[string]string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/config/test-org/test-app/prod", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("clients without a version get the v1 shape", func(t *testing.T) {
+		w := get(nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "1", w.Header().Get("X-Config-Schema-Version"))
+		assert.Contains(t, w.Header().Values("Vary"), "X-Config-Schema-Version, Accept-Version")
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(4), response["version"])
+		assert.NotContains(t, response, "version_id")
+		assert.NotContains(t, response, "content_hash")
+		assert.NotContains(t, response, "schema_version")
+	})
+
+	t.Run("v2 adds the version ID and content hash", func(t *testing.T) {
+		for _, headers := range []map[string]string{
+			{"X-Config-Schema-Version": "2"},
+			{"Accept-Version": "v2"},
+			{"X-Config-Schema-Version": "2", "Accept-Version": "2"},
+		} {
+			w := get(headers)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "2", w.Header().Get("X-Config-Schema-Version"))
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, float64(2), response["schema_version"])
+			assert.Equal(t, versionID.String(), response["version_id"])
+			assert.Equal(t, contentHash(config.Config), response["content_hash"])
+			assert.Equal(t, float64(4), response["version"])
+		}
+	})
+
+	t.Run("newer versions are served the latest", func(t *testing.T) {
+		w := get(map[string]string{"X-Config-Schema-Version": "9"})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2", w.Header().Get("X-Config-Schema-Version"))
+	})
+
+	t.Run("invalid versions are rejected", func(t *testing.T) {
+		for _, headers := range []map[string]string{
+			{"X-Config-Schema-Version": "latest"},
+			{"Accept-Version": "0"},
+			{"X-Config-Schema-Version": "1", "Accept-Version": "2"},
+		} {
+			w := get(headers)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "invalid_schema_version")
+		}
+	})
+
+	t.Run("YAML follows the negotiated shape", func(t *testing.T) {
+		w := get(map[string]string{"Accept": "application/yaml", "X-Config-Schema-Version": "2"})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, versionID.String(), response["version_id"])
+		assert.Equal(t, contentHash(config.Config), response["content_hash"])
+	})
+
+	t.Run("batch entries are shaped", func(t *testing.T) {
+		for version, hasVersionID := range map[string]bool{"1": false, "2": true} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/api/config/batch", bytes.NewBufferString(`{"environments": ["prod"]}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Config-Schema-Version", version)
+			router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var response struct {
+				Configs map[string]map[string]interface{} `json:"configs"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			_, ok := response.Configs["prod"]["version_id"]
+			assert.Equal(t, hasVersionID, ok, "schema version %s", version)
+		}
+	})
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"remote-config-system/internal/models"
@@ -13,6 +15,19 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// websocketWriteTimeout bounds how long a frame may take to reach a client before the
+	// connection is considered dead
+	websocketWriteTimeout = 10 * time.Second
+	// sseWriteTimeout does the same for SSE events. Streaming routes have no server write
+	// timeout, so without it a client that stops reading would block its stream forever.
+	sseWriteTimeout = 10 * time.Second
+	// websocketMaxPayload limits frames received from WebSocket clients, which have
+	// nothing to send
+	websocketMaxPayload = 4096
 )
 
 // SSEHandler handles Server-Sent Events endpoints
@@ -47,11 +62,30 @@ func (h *SSEHandler) StreamConfigUpdates(c *gin.Context) {
 		return
 	}
 
+	staleThreshold, ok := h.parseStaleTimeout(c)
+	if !ok {
+		return
+	}
+
+	replay, ok := h.parseReplay(c)
+	if !ok {
+		return
+	}
+
+	initial, ok := h.parseInitial(c)
+	if !ok {
+		return
+	}
+
+	keys, ok := h.parseKeys(c)
+	if !ok {
+		return
+	}
+
 	// Set SSE headers
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
 	c.Header("Access-Control-Allow-Headers", "Cache-Control")
 
 	// Create client context
@@ -64,37 +98,28 @@ func (h *SSEHandler) StreamConfigUpdates(c *gin.Context) {
 		Organization: orgSlug,
 		Application:  appSlug,
 		Environment:  envSlug,
-		Channel:      make(chan models.SSEMessage, 100),
+		Channel:      h.sseService.NewClientChannel(),
 		Context:      ctx,
 		Cancel:       cancel,
 		ConnectedAt:  time.Now(),
 		LastPing:     time.Now(),
+
+		StaleThreshold: staleThreshold,
+		Keys:           keys,
 	}
 
 	// Register client with SSE service
 	h.sseService.RegisterClient(client)
 	defer h.sseService.UnregisterClient(client)
 
-	// Send initial configuration
-	if config, err := h.configService.GetConfiguration(orgSlug, appSlug, envSlug); err == nil {
-		initialEvent := models.ConfigUpdateEvent{
-			Organization: config.Organization,
-			Application:  config.Application,
-			Environment:  config.Environment,
-			Version:      config.Version,
-			Config:       config.Config,
-			Action:       "initial",
-			UpdatedAt:    config.UpdatedAt,
-		}
-
-		initialMsg := models.SSEMessage{
-			Event: "initial_config",
-			Data:  initialEvent,
-		}
+	if replay {
+		h.queueReplay(client)
+	}
 
-		select {
-		case client.Channel <- initialMsg:
-		default:
+	// Send initial configuration, unless the client manages its own state
+	if initial {
+		if config, err := h.configService.GetConfiguration(orgSlug, appSlug, envSlug); err == nil {
+			h.queueInitialConfig(client, config)
 		}
 	}
 
@@ -102,7 +127,6 @@ func (h *SSEHandler) StreamConfigUpdates(c *gin.Context) {
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
 	c.Writer.Header().Set("Connection", "keep-alive")
-	c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 	c.Writer.Header().Set("Access-Control-Allow-Headers", "Cache-Control")
 
 	for {
@@ -119,7 +143,7 @@ func (h *SSEHandler) StreamConfigUpdates(c *gin.Context) {
 			h.sseService.Ping(client.ID)
 
 			// Send SSE message
-			if err := h.writeSSEMessage(c.Writer, message); err != nil {
+			if err := h.writeSSEMessage(c.Writer, projectMessage(message, client.Keys)); err != nil {
 				return
 			}
 
@@ -146,32 +170,13 @@ func (h *SSEHandler) StreamConfigUpdates(c *gin.Context) {
 func (h *SSEHandler) StreamConfigUpdatesWithAPIKey(c *gin.Context) {
 	envSlug := c.Param("env")
 
-	// Get API key from context (set by middleware)
-	apiKey, exists := c.Get("api_key")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-			Error:     "unauthorized",
-			Message:   "API key is required",
-			Timestamp: time.Now(),
-			Path:      c.Request.URL.Path,
-		})
-		return
-	}
-
-	// Get application info from API key
-	app, err := h.configService.ValidateAPIKey(apiKey.(string))
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
-			Error:     "unauthorized",
-			Message:   "Invalid API key",
-			Timestamp: time.Now(),
-			Path:      c.Request.URL.Path,
-		})
+	app, ok := authenticatedApplication(c)
+	if !ok {
 		return
 	}
 
 	// Validate that the environment exists
-	_, err = h.configService.GetEnvironment(app.Organization.Slug, app.Slug, envSlug)
+	_, err := h.configService.GetEnvironment(app.Organization.Slug, app.Slug, envSlug)
 	if err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error:     "not_found",
@@ -182,11 +187,30 @@ func (h *SSEHandler) StreamConfigUpdatesWithAPIKey(c *gin.Context) {
 		return
 	}
 
+	staleThreshold, ok := h.parseStaleTimeout(c)
+	if !ok {
+		return
+	}
+
+	replay, ok := h.parseReplay(c)
+	if !ok {
+		return
+	}
+
+	initial, ok := h.parseInitial(c)
+	if !ok {
+		return
+	}
+
+	keys, ok := h.parseKeys(c)
+	if !ok {
+		return
+	}
+
 	// Set SSE headers
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
 	c.Header("Access-Control-Allow-Headers", "Cache-Control")
 
 	// Create client context
@@ -199,37 +223,29 @@ func (h *SSEHandler) StreamConfigUpdatesWithAPIKey(c *gin.Context) {
 		Organization: app.Organization.Slug,
 		Application:  app.Slug,
 		Environment:  envSlug,
-		Channel:      make(chan models.SSEMessage, 100),
+		Channel:      h.sseService.NewClientChannel(),
 		Context:      ctx,
 		Cancel:       cancel,
 		ConnectedAt:  time.Now(),
 		LastPing:     time.Now(),
+
+		StaleThreshold: staleThreshold,
+		Keys:           keys,
 	}
 
 	// Register client with SSE service
 	h.sseService.RegisterClient(client)
 	defer h.sseService.UnregisterClient(client)
 
-	// Send initial configuration
-	if config, err := h.configService.GetConfigurationByAPIKey(apiKey.(string), envSlug); err == nil {
-		initialEvent := models.ConfigUpdateEvent{
-			Organization: config.Organization,
-			Application:  config.Application,
-			Environment:  config.Environment,
-			Version:      config.Version,
-			Config:       config.Config,
-			Action:       "initial",
-			UpdatedAt:    config.UpdatedAt,
-		}
-
-		initialMsg := models.SSEMessage{
-			Event: "initial_config",
-			Data:  initialEvent,
-		}
+	if replay {
+		h.queueReplay(client)
+	}
 
-		select {
-		case client.Channel <- initialMsg:
-		default:
+	// Send initial configuration, unless the client manages its own state. Streams follow
+	// the active version, as rollouts are not broadcast.
+	if initial {
+		if config, err := h.configService.GetConfigurationByAPIKey(app, envSlug, ""); err == nil {
+			h.queueInitialConfig(client, config)
 		}
 	}
 
@@ -237,7 +253,6 @@ func (h *SSEHandler) StreamConfigUpdatesWithAPIKey(c *gin.Context) {
 	c.Writer.Header().Set("Content-Type", "text/event-stream")
 	c.Writer.Header().Set("Cache-Control", "no-cache")
 	c.Writer.Header().Set("Connection", "keep-alive")
-	c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 	c.Writer.Header().Set("Access-Control-Allow-Headers", "Cache-Control")
 
 	for {
@@ -254,7 +269,7 @@ func (h *SSEHandler) StreamConfigUpdatesWithAPIKey(c *gin.Context) {
 			h.sseService.Ping(client.ID)
 
 			// Send SSE message
-			if err := h.writeSSEMessage(c.Writer, message); err != nil {
+			if err := h.writeSSEMessage(c.Writer, projectMessage(message, client.Keys)); err != nil {
 				return
 			}
 
@@ -277,6 +292,322 @@ func (h *SSEHandler) StreamConfigUpdatesWithAPIKey(c *gin.Context) {
 	}
 }
 
+// StreamConfigUpdatesWebSocket handles GET /ws/:org/:app/:env. It sends the same events as
+// StreamConfigUpdates, as JSON text frames, for clients behind proxies that break SSE.
+func (h *SSEHandler) StreamConfigUpdatesWebSocket(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	// Validate that the environment exists
+	if _, err := h.configService.GetEnvironment(orgSlug, appSlug, envSlug); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:     "not_found",
+			Message:   fmt.Sprintf("Environment %s/%s/%s not found", orgSlug, appSlug, envSlug),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	staleThreshold, ok := h.parseStaleTimeout(c)
+	if !ok {
+		return
+	}
+
+	replay, ok := h.parseReplay(c)
+	if !ok {
+		return
+	}
+
+	initial, ok := h.parseInitial(c)
+	if !ok {
+		return
+	}
+
+	keys, ok := h.parseKeys(c)
+	if !ok {
+		return
+	}
+
+	if !c.IsWebsocket() {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "WebSocket upgrade required",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	server := websocket.Server{
+		// Like the public SSE stream, the connection carries no credentials, so any origin
+		// and non-browser clients without one may connect
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			client := &sse.Client{
+				ID:           uuid.New().String(),
+				Organization: orgSlug,
+				Application:  appSlug,
+				Environment:  envSlug,
+				Channel:      h.sseService.NewClientChannel(),
+				ConnectedAt:  time.Now(),
+				LastPing:     time.Now(),
+
+				StaleThreshold: staleThreshold,
+				Keys:           keys,
+			}
+			h.serveWebSocket(conn, client, replay, initial)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serveWebSocket registers a client for a WebSocket connection and sends it its events
+// until either side closes the connection
+func (h *SSEHandler) serveWebSocket(conn *websocket.Conn, client *sse.Client, replay, initial bool) {
+	defer conn.Close()
+	conn.MaxPayloadBytes = websocketMaxPayload
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client.Context = ctx
+	client.Cancel = cancel
+
+	// Register client with SSE service
+	h.sseService.RegisterClient(client)
+	defer h.sseService.UnregisterClient(client)
+
+	if replay {
+		h.queueReplay(client)
+	}
+
+	// Send initial configuration, unless the client manages its own state
+	if initial {
+		if config, err := h.configService.GetConfiguration(client.Organization, client.Application, client.Environment); err == nil {
+			h.queueInitialConfig(client, config)
+		}
+	}
+
+	// Reading answers pings and consumes pongs. Clients have nothing else to send, so the
+	// connection is done once reading fails, e.g. on a close frame.
+	go func() {
+		defer cancel()
+		for {
+			var frame []byte
+			if err := websocket.Message.Receive(conn, &frame); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case message, ok := <-client.Channel:
+			if !ok {
+				return
+			}
+
+			// Update last ping
+			h.sseService.Ping(client.ID)
+
+			if err := writeWebSocketMessage(conn, projectMessage(message, client.Keys)); err != nil {
+				return
+			}
+
+		case <-time.After(30 * time.Second):
+			// Send keep-alive ping; the client answers with a pong
+			if err := writeWebSocketPing(conn); err != nil {
+				return
+			}
+
+			// Update last ping
+			h.sseService.Ping(client.ID)
+		}
+	}
+}
+
+// writeWebSocketMessage writes an event to a WebSocket connection as a JSON text frame
+func writeWebSocketMessage(conn *websocket.Conn, message models.SSEMessage) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout)); err != nil {
+		return err
+	}
+	return websocket.JSON.Send(conn, message)
+}
+
+// writeWebSocketPing writes a ping control frame to a WebSocket connection
+func writeWebSocketPing(conn *websocket.Conn) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(websocketWriteTimeout)); err != nil {
+		return err
+	}
+
+	// Write sends frames of the connection's payload type
+	conn.PayloadType = websocket.PingFrame
+	defer func() { conn.PayloadType = websocket.TextFrame }()
+	_, err := conn.Write(nil)
+	return err
+}
+
+// PollConfigWithAPIKey handles GET /api/config/:env/poll with API key authentication. It
+// waits until the active version differs from since_version, because a newer version was
+// activated or an older one was rolled back to, and responds with the configuration. Once
+// the timeout expires it responds with 304 Not Modified.
+func (h *SSEHandler) PollConfigWithAPIKey(c *gin.Context) {
+	envSlug := c.Param("env")
+
+	app, ok := authenticatedApplication(c)
+	if !ok {
+		return
+	}
+
+	sinceVersion, err := strconv.Atoi(c.Query("since_version"))
+	if err != nil || sinceVersion < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "since_version must be the non-negative version the client has",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	timeout, ok := h.parsePollTimeout(c)
+	if !ok {
+		return
+	}
+
+	// Validate that the environment exists
+	if _, err := h.configService.GetEnvironment(app.Organization.Slug, app.Slug, envSlug); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:     "not_found",
+			Message:   fmt.Sprintf("Environment %s not found", envSlug),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+	defer cancel()
+
+	// Subscribe to the environment's updates like a stream does
+	client := &sse.Client{
+		ID:           uuid.New().String(),
+		Organization: app.Organization.Slug,
+		Application:  app.Slug,
+		Environment:  envSlug,
+		Channel:      make(chan models.SSEMessage, 10),
+		Context:      ctx,
+		Cancel:       cancel,
+		ConnectedAt:  time.Now(),
+		LastPing:     time.Now(),
+	}
+	h.sseService.RegisterClient(client)
+	defer h.sseService.UnregisterClient(client)
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.Status(http.StatusNotModified)
+			return
+
+		case message, ok := <-client.Channel:
+			if !ok {
+				c.Status(http.StatusNotModified)
+				return
+			}
+
+			// The welcome message confirms the subscription, so the active version is checked
+			// once no later update can be missed, and again after each update
+			if message.Event != "connected" && message.Event != "config_update" {
+				continue
+			}
+
+			// Like the streams, polling follows the active version rather than rollouts
+			config, err := h.configService.GetConfigurationByAPIKey(app, envSlug, "")
+			if errors.Is(err, services.ErrNotFound) {
+				// No configuration yet; the first one ends the wait
+				continue
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+					Error:     "poll_failed",
+					Message:   err.Error(),
+					Timestamp: time.Now(),
+					Path:      c.Request.URL.Path,
+				})
+				return
+			}
+
+			if config.Version != sinceVersion {
+				c.Header("Cache-Control", "no-store")
+				c.Header("ETag", configETag(config))
+				if config, ok = applyFields(c, config); !ok {
+					return
+				}
+				respondConfig(c, http.StatusOK, config)
+				return
+			}
+		}
+	}
+}
+
+// GetEventHistory handles GET /events/:org/:app/:env/history
+func (h *SSEHandler) GetEventHistory(c *gin.Context) {
+	orgSlug := c.Param("org")
+	appSlug := c.Param("app")
+	envSlug := c.Param("env")
+
+	// Validate that the environment exists
+	if _, err := h.configService.GetEnvironment(orgSlug, appSlug, envSlug); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:     "not_found",
+			Message:   fmt.Sprintf("Environment %s/%s/%s not found", orgSlug, appSlug, envSlug),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.eventHistory(orgSlug, appSlug, envSlug))
+}
+
+// GetEventHistoryWithAPIKey handles GET /api/events/:env/history with API key authentication
+func (h *SSEHandler) GetEventHistoryWithAPIKey(c *gin.Context) {
+	envSlug := c.Param("env")
+
+	app, ok := authenticatedApplication(c)
+	if !ok {
+		return
+	}
+
+	// Validate that the environment exists
+	if _, err := h.configService.GetEnvironment(app.Organization.Slug, app.Slug, envSlug); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:     "not_found",
+			Message:   fmt.Sprintf("Environment %s not found", envSlug),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.eventHistory(app.Organization.Slug, app.Slug, envSlug))
+}
+
+// eventHistory builds the response listing the recent configuration updates of an environment
+func (h *SSEHandler) eventHistory(orgSlug, appSlug, envSlug string) *models.ConfigEventHistoryResponse {
+	return &models.ConfigEventHistoryResponse{
+		Organization: orgSlug,
+		Application:  appSlug,
+		Environment:  envSlug,
+		Events:       h.sseService.RecentEvents(orgSlug, appSlug, envSlug),
+	}
+}
+
 // GetSSEStats handles GET /admin/sse/stats
 func (h *SSEHandler) GetSSEStats(c *gin.Context) {
 	stats := h.sseService.GetStats()
@@ -290,6 +621,22 @@ func (h *SSEHandler) GetSSEStats(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// DisconnectSSEClient handles DELETE /admin/sse/clients/:id
+func (h *SSEHandler) DisconnectSSEClient(c *gin.Context) {
+	clientID := c.Param("id")
+	if !h.sseService.DisconnectClient(clientID) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:     "not_found",
+			Message:   fmt.Sprintf("SSE client %s not connected", clientID),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 // writeSSEMessage writes an SSE message to the response writer
 func (h *SSEHandler) writeSSEMessage(w gin.ResponseWriter, message models.SSEMessage) error {
 	// Convert data to JSON
@@ -298,6 +645,12 @@ func (h *SSEHandler) writeSSEMessage(w gin.ResponseWriter, message models.SSEMes
 		return fmt.Errorf("failed to marshal SSE data: %w", err)
 	}
 
+	// The deadline is unsupported where there is no connection, e.g. in tests
+	err = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(sseWriteTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
 	// Write SSE format
 	if _, err := fmt.Fprintf(w, "event: %s\n", message.Event); err != nil {
 		return err
@@ -314,3 +667,183 @@ func (h *SSEHandler) writeSSEMessage(w gin.ResponseWriter, message models.SSEMes
 
 	return nil
 }
+
+// parseStaleTimeout reads the optional stale_timeout query parameter (in seconds) and
+// bounds it by the server maximum. It writes a 400 response and returns false if invalid.
+func (h *SSEHandler) parseStaleTimeout(c *gin.Context) (time.Duration, bool) {
+	raw := c.Query("stale_timeout")
+	if raw == "" {
+		return 0, true
+	}
+
+	seconds, err := strconv.Atoi(raw)
+	if err != nil || seconds <= 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "stale_timeout must be a positive number of seconds",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return 0, false
+	}
+
+	return h.sseService.ClientStaleThreshold(time.Duration(seconds) * time.Second), true
+}
+
+// parsePollTimeout reads the optional timeout query parameter (in seconds) of a long-polling
+// request and bounds it by the server maximum. It writes a 400 response and returns false if
+// invalid.
+func (h *SSEHandler) parsePollTimeout(c *gin.Context) (time.Duration, bool) {
+	raw := c.Query("timeout")
+	if raw == "" {
+		return h.sseService.ClientPollTimeout(0), true
+	}
+
+	seconds, err := strconv.Atoi(raw)
+	if err != nil || seconds <= 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "timeout must be a positive number of seconds",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return 0, false
+	}
+
+	return h.sseService.ClientPollTimeout(time.Duration(seconds) * time.Second), true
+}
+
+// parseReplay reads the optional replay query parameter, which asks for the recent
+// configuration updates of the environment when connecting. It writes a 400 response and
+// returns false if invalid.
+func (h *SSEHandler) parseReplay(c *gin.Context) (bool, bool) {
+	raw := c.Query("replay")
+	if raw == "" {
+		return false, true
+	}
+
+	replay, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "Invalid replay parameter: " + err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return false, false
+	}
+
+	return replay, true
+}
+
+// parseInitial reads the optional initial query parameter, which can turn off the
+// initial_config event sent when connecting. It writes a 400 response and returns false
+// if invalid.
+func (h *SSEHandler) parseInitial(c *gin.Context) (bool, bool) {
+	raw := c.Query("initial")
+	if raw == "" {
+		return true, true
+	}
+
+	initial, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   "Invalid initial parameter: " + err.Error(),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return false, false
+	}
+
+	return initial, true
+}
+
+// parseKeys reads the optional keys query parameter, a comma-separated list of
+// dot-notated paths that limits the stream to updates changing them and projects the
+// configurations it sends to them. It writes a 400 response and returns false if invalid.
+func (h *SSEHandler) parseKeys(c *gin.Context) ([]string, bool) {
+	value, ok := c.GetQuery("keys")
+	if !ok {
+		return nil, true
+	}
+
+	keys := parseFields(value)
+	if len(keys) == 0 || len(keys) > maxFields {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:     "bad_request",
+			Message:   fmt.Sprintf("Invalid keys parameter: must list between 1 and %d keys", maxFields),
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		return nil, false
+	}
+
+	return keys, true
+}
+
+// projectMessage reduces the configuration of a configuration event to the client's keys.
+// Other events, and configurations that cannot be projected, are sent as they are.
+func projectMessage(message models.SSEMessage, keys []string) models.SSEMessage {
+	if len(keys) == 0 {
+		return message
+	}
+
+	event, ok := message.Data.(models.ConfigUpdateEvent)
+	if !ok {
+		return message
+	}
+	document, err := projectConfig(event.Config, keys)
+	if err != nil {
+		return message
+	}
+
+	event.Config = document
+	message.Data = event
+	return message
+}
+
+// queueReplay queues the recent configuration updates of a client's environment as
+// config_replay events, oldest first, skipping those that changed none of the client's
+// keys. They are sent ahead of the initial configuration, which stays the one to apply.
+func (h *SSEHandler) queueReplay(client *sse.Client) {
+	for _, event := range h.sseService.RecentEvents(client.Organization, client.Application, client.Environment) {
+		if !client.WantsChanges(event.ChangedKeys) {
+			continue
+		}
+		select {
+		case client.Channel <- models.SSEMessage{Event: "config_replay", Data: event}:
+		default:
+			return
+		}
+	}
+}
+
+// queueInitialConfig queues a configuration as the client's initial_config event. Clients
+// are registered first, so it follows the connected welcome message and any replayed
+// updates.
+func (h *SSEHandler) queueInitialConfig(client *sse.Client, config *models.ConfigResponse) {
+	// Streams carry secret values masked, like the updates broadcast to them
+	config, err := services.MaskSecretValues(config)
+	if err != nil {
+		return
+	}
+
+	initialMsg := models.SSEMessage{
+		Event: "initial_config",
+		Data: models.ConfigUpdateEvent{
+			Organization: config.Organization,
+			Application:  config.Application,
+			Environment:  config.Environment,
+			Version:      config.Version,
+			Config:       config.Config,
+			Action:       "initial",
+			UpdatedAt:    config.UpdatedAt,
+		},
+	}
+
+	select {
+	case client.Channel <- initialMsg:
+	default:
+	}
+}
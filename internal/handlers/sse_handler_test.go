@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/sse"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocketFrames(t *testing.T) {
+	server := httptest.NewServer(websocket.Server{
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			// Pings are answered by the client without reaching the application
+			assert.NoError(t, writeWebSocketPing(conn))
+			assert.NoError(t, writeWebSocketMessage(conn, models.SSEMessage{
+				Event: "config_update",
+				Data:  models.ConfigUpdateEvent{Environment: "prod", Version: 2, Action: "update"},
+			}))
+
+			// The pong and the close frame are consumed while waiting for the client
+			var frame []byte
+			assert.Error(t, websocket.Message.Receive(conn, &frame))
+		},
+	})
+	defer server.Close()
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", server.URL)
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	var message struct {
+		Event string                   `json:"event"`
+		Data  models.ConfigUpdateEvent `json:"data"`
+	}
+	require.NoError(t, websocket.JSON.Receive(conn, &message))
+	assert.Equal(t, "config_update", message.Event)
+	assert.Equal(t, 2, message.Data.Version)
+
+	require.NoError(t, conn.Close())
+}
+
+func TestProjectMessage(t *testing.T) {
+	message := models.SSEMessage{
+		Event: "config_update",
+		Data:  models.ConfigUpdateEvent{Version: 3, Config: json.RawMessage(`{"db": {"host": "a", "port": 5432}, "theme": "dark"}`)},
+	}
+
+	projected := projectMessage(message, []string{"db.host", "missing"})
+	event := projected.Data.(models.ConfigUpdateEvent)
+	assert.Equal(t, 3, event.Version)
+	assert.JSONEq(t, `{"db": {"host": "a"}}`, string(event.Config))
+
+	// The broadcast message is left unchanged for other clients
+	assert.JSONEq(t, `{"db": {"host": "a", "port": 5432}, "theme": "dark"}`, string(message.Data.(models.ConfigUpdateEvent).Config))
+
+	ping := models.SSEMessage{Event: "ping", Data: map[string]interface{}{"timestamp": "now"}}
+	assert.Equal(t, ping, projectMessage(ping, []string{"db"}))
+}
+
+func TestSSEHandler_ParseKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := &SSEHandler{}
+
+	for query, expected := range map[string][]string{
+		"":                       nil,
+		"?keys=db.host,%20theme": {"db.host", "theme"},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/events/prod"+query, nil)
+
+		keys, ok := handler.parseKeys(c)
+		assert.True(t, ok, query)
+		assert.Equal(t, expected, keys, query)
+	}
+
+	for _, query := range []string{"?keys=", "?keys=,.a"} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/events/prod"+query, nil)
+
+		_, ok := handler.parseKeys(c)
+		assert.False(t, ok, query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestSSEHandler_DisconnectSSEClient(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := sse.NewSSEServiceWithConfig(&sse.Config{StaleThreshold: 5 * time.Minute})
+	handler := NewSSEHandler(nil, service)
+
+	router := gin.New()
+	router.DELETE("/admin/sse/clients/:id", handler.DisconnectSSEClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &sse.Client{
+		ID:           "client-1",
+		Organization: "test-org",
+		Application:  "test-app",
+		Environment:  "prod",
+		Channel:      service.NewClientChannel(),
+		Context:      ctx,
+		Cancel:       cancel,
+		ConnectedAt:  time.Now(),
+		LastPing:     time.Now(),
+	}
+	service.RegisterClient(client)
+	require.Eventually(t, func() bool { return len(service.GetClients()) == 1 }, time.Second, 10*time.Millisecond)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/sse/clients/client-1", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, service.GetClients())
+	assert.Error(t, ctx.Err())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/sse/clients/client-1", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_APIKeyHashes(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Hash Org", "hash-org")
+	suite.CreateTestApplication(t, org.ID, "Web", "web", "hash-web-api-key")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	t.Run("only a hash of the key is stored", func(t *testing.T) {
+		var prefix, salt, hash string
+		err := suite.DB.QueryRow(`SELECT api_key_prefix, api_key_salt, api_key_hash FROM applications WHERE slug = 'web'`).
+			Scan(&prefix, &salt, &hash)
+		require.NoError(t, err)
+		assert.Equal(t, "hash-web", prefix)
+		assert.NotContains(t, hash, "hash-web-api-key")
+
+		app, err := configService.ValidateAPIKey("hash-web-api-key")
+		require.NoError(t, err)
+		assert.Equal(t, "web", app.Slug)
+		assert.Empty(t, app.APIKey)
+
+		_, err = configService.ValidateAPIKey("hash-web-api-kez")
+		assert.Error(t, err, "keys sharing a prefix must not match")
+	})
+
+	t.Run("custom keys must be unique", func(t *testing.T) {
+		_, err := configService.CreateApplication("hash-org", &models.CreateApplicationRequest{
+			Name:   "Mobile",
+			Slug:   "mobile",
+			APIKey: "hash-web-api-key",
+		})
+		assert.True(t, errors.Is(err, services.ErrConflict))
+	})
+
+	t.Run("rotation replaces the key", func(t *testing.T) {
+		app, err := configService.RotateAPIKey("hash-org", "web")
+		require.NoError(t, err)
+		require.NotEmpty(t, app.APIKey)
+		assert.Equal(t, app.APIKey[:len(app.APIKeyPrefix)], app.APIKeyPrefix)
+
+		_, err = configService.ValidateAPIKey("hash-web-api-key")
+		assert.Error(t, err)
+
+		rotated, err := configService.ValidateAPIKey(app.APIKey)
+		require.NoError(t, err)
+		assert.Equal(t, app.ID, rotated.ID)
+
+		_, err = configService.RotateAPIKey("hash-org", "missing")
+		assert.True(t, errors.Is(err, services.ErrNotFound))
+	})
+}
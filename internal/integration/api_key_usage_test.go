@@ -0,0 +1,77 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_APIKeyUsage(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Usage Org", "usage-org")
+	web := suite.CreateTestApplication(t, org.ID, "Web", "web", "usage-web-api-key")
+	suite.CreateTestApplication(t, org.ID, "Idle", "idle", "usage-idle-api-key")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	for _, at := range []time.Time{yesterday, now, now} {
+		require.NoError(t, suite.Redis.Client.RecordUsage(web.ID.String(), "prod", at))
+	}
+	require.NoError(t, suite.Redis.Client.RecordUsage(web.ID.String(), "dev", now))
+
+	flushed, err := configService.FlushUsage()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), flushed)
+
+	// Later flushes add to the stored counts
+	require.NoError(t, suite.Redis.Client.RecordUsage(web.ID.String(), "prod", now))
+	flushed, err = configService.FlushUsage()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), flushed)
+
+	t.Run("usage by environment and day", func(t *testing.T) {
+		usage, err := configService.GetApplicationUsage("usage-org", "web", services.DefaultUsageDays)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), usage.Requests)
+		require.NotNil(t, usage.LastSeenAt)
+
+		require.Len(t, usage.Environments, 2)
+		assert.Equal(t, "dev", usage.Environments[0].Environment)
+		assert.Equal(t, int64(1), usage.Environments[0].Requests)
+		assert.Equal(t, "prod", usage.Environments[1].Environment)
+		assert.Equal(t, int64(4), usage.Environments[1].Requests)
+
+		require.Len(t, usage.Daily, 2)
+		assert.Equal(t, yesterday.UTC().Format("2006-01-02"), usage.Daily[0].Date)
+		assert.Equal(t, map[string]int64{"prod": 1}, usage.Daily[0].Environments)
+		assert.Equal(t, int64(4), usage.Daily[1].Requests)
+	})
+
+	t.Run("the period is limited to the last days", func(t *testing.T) {
+		usage, err := configService.GetApplicationUsage("usage-org", "web", 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), usage.Requests)
+		assert.Len(t, usage.Daily, 1)
+	})
+
+	t.Run("unused keys have no usage", func(t *testing.T) {
+		usage, err := configService.GetApplicationUsage("usage-org", "idle", services.DefaultUsageDays)
+		require.NoError(t, err)
+		assert.Zero(t, usage.Requests)
+		assert.Nil(t, usage.LastSeenAt)
+	})
+
+	t.Run("unknown application", func(t *testing.T) {
+		_, err := configService.GetApplicationUsage("usage-org", "missing", services.DefaultUsageDays)
+		assert.ErrorIs(t, err, services.ErrNotFound)
+	})
+}
@@ -42,7 +42,7 @@ func SetupIntegrationTest(t *testing.T) *IntegrationTestSuite {
 	sseService := sse.NewSSEService()
 	
 	// Initialize services
-	configService := services.NewConfigService(testSuite.Repos, testSuite.Redis.Client, sseService)
+	configService := services.NewConfigService(services.NewConfig(), testSuite.Repos, testSuite.Redis.Client, sseService)
 	
 	// Initialize handlers
 	configHandler := handlers.NewConfigHandler(configService)
@@ -54,8 +54,9 @@ func SetupIntegrationTest(t *testing.T) *IntegrationTestSuite {
 	
 	// Setup router
 	router := gin.New()
-	router.Use(middleware.CORS())
-	router.Use(middleware.RequestLogger())
+	router.Use(middleware.CORS(middleware.NewCORSConfig()))
+	router.Use(middleware.RequestID())
+	router.Use(middleware.RequestLogger(middleware.NewAccessLogConfig()))
 	router.Use(middleware.ErrorHandler())
 	
 	// Health check endpoint
@@ -142,6 +143,7 @@ func TestIntegration_FullConfigurationWorkflow(t *testing.T) {
 		assert.Equal(t, "Test Application", app.Name)
 		assert.Equal(t, "testapp", app.Slug)
 		assert.Equal(t, "testapikey123", app.APIKey)
+		assert.Equal(t, "testap", app.APIKeyPrefix)
 		
 		// Step 3: Create environment
 		envReq := &models.CreateEnvironmentRequest{
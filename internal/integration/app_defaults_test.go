@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ApplicationDefaults(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Defaults Org", "defaults-org")
+	app := suite.CreateTestApplication(t, org.ID, "Defaults App", "defaults-app", "defaults-api-key")
+	suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+	suite.CreateTestEnvironment(t, app.ID, "Staging", "staging")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+	keyApp, err := configService.ValidateAPIKey("defaults-api-key")
+	require.NoError(t, err)
+
+	_, err = configService.UpdateConfiguration("defaults-org", "defaults-app", "prod", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"features": {"reviews": true}}`),
+	}, false)
+	require.NoError(t, err)
+
+	defaults, err := configService.SetApplicationDefaults("defaults-org", "defaults-app", &models.UpdateApplicationDefaultsRequest{
+		Config: json.RawMessage(`{"timeout": 30, "features": {"search": true, "reviews": false}}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, defaults.Version)
+
+	t.Run("configuration is merged with the defaults", func(t *testing.T) {
+		config, err := configService.GetConfiguration("defaults-org", "defaults-app", "prod")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"timeout": 30, "features": {"search": true, "reviews": true}}`, string(config.Config))
+		assert.Equal(t, 1, config.DefaultsVersion)
+
+		config, err = configService.GetConfigurationByAPIKey(keyApp, "prod", "")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"timeout": 30, "features": {"search": true, "reviews": true}}`, string(config.Config))
+
+		// Versions read by API key are served the same way
+		config, err = configService.GetConfigurationVersionByAPIKey(keyApp, "prod", 1)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"timeout": 30, "features": {"search": true, "reviews": true}}`, string(config.Config))
+		assert.Equal(t, 1, config.DefaultsVersion)
+	})
+
+	t.Run("raw configuration has only the overrides", func(t *testing.T) {
+		config, err := configService.GetRawConfiguration("defaults-org", "defaults-app", "prod")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"features": {"reviews": true}}`, string(config.Config))
+		assert.Zero(t, config.DefaultsVersion)
+	})
+
+	t.Run("updating the defaults invalidates cached configurations", func(t *testing.T) {
+		// Both reads above cached the merged configuration
+		defaults, err := configService.SetApplicationDefaults("defaults-org", "defaults-app", &models.UpdateApplicationDefaultsRequest{
+			Config: json.RawMessage(`{"timeout": 45}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, defaults.Version)
+
+		config, err := configService.GetConfiguration("defaults-org", "defaults-app", "prod")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"timeout": 45, "features": {"reviews": true}}`, string(config.Config))
+		assert.Equal(t, 2, config.DefaultsVersion)
+
+		config, err = configService.GetConfigurationByAPIKey(keyApp, "prod", "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, config.DefaultsVersion)
+	})
+
+	t.Run("change log distinguishes default edits", func(t *testing.T) {
+		for _, envSlug := range []string{"prod", "staging"} {
+			result, err := configService.GetConfigurationChanges("defaults-org", "defaults-app", envSlug, models.PaginationParams{Page: 1, PageSize: 10})
+			require.NoError(t, err)
+
+			changes := result.Data.([]map[string]interface{})
+			assert.Equal(t, 2, countScope(changes, models.ChangeScopeDefaults), "environment %s", envSlug)
+		}
+
+		result, err := configService.GetConfigurationChanges("defaults-org", "defaults-app", "prod", models.PaginationParams{Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 1, countScope(result.Data.([]map[string]interface{}), models.ChangeScopeEnvironment))
+	})
+
+	t.Run("removing the defaults", func(t *testing.T) {
+		require.NoError(t, configService.DeleteApplicationDefaults("defaults-org", "defaults-app", nil))
+
+		config, err := configService.GetConfiguration("defaults-org", "defaults-app", "prod")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"features": {"reviews": true}}`, string(config.Config))
+		assert.Zero(t, config.DefaultsVersion)
+
+		_, err = configService.GetApplicationDefaults("defaults-org", "defaults-app")
+		assert.ErrorContains(t, err, "application defaults not found")
+	})
+
+	t.Run("defaults cannot bypass approval", func(t *testing.T) {
+		requiresApproval := true
+		_, err := configService.UpdateEnvironment("defaults-org", "defaults-app", "staging", &models.UpdateEnvironmentRequest{RequiresApproval: &requiresApproval})
+		require.NoError(t, err)
+
+		_, err = configService.SetApplicationDefaults("defaults-org", "defaults-app", &models.UpdateApplicationDefaultsRequest{
+			Config: json.RawMessage(`{"timeout": 5}`),
+		})
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, services.ErrConflict))
+		assert.Contains(t, err.Error(), "environment staging requires approval")
+
+		_, err = configService.GetApplicationDefaults("defaults-org", "defaults-app")
+		assert.True(t, errors.Is(err, services.ErrNotFound), "rejected defaults are not stored")
+
+		requiresApproval = false
+		_, err = configService.UpdateEnvironment("defaults-org", "defaults-app", "staging", &models.UpdateEnvironmentRequest{RequiresApproval: &requiresApproval})
+		require.NoError(t, err)
+		_, err = configService.SetApplicationDefaults("defaults-org", "defaults-app", &models.UpdateApplicationDefaultsRequest{
+			Config: json.RawMessage(`{"timeout": 5}`),
+		})
+		require.NoError(t, err)
+	})
+}
+
+// countScope counts the change log entries with the given scope
+func countScope(changes []map[string]interface{}, scope string) int {
+	count := 0
+	for _, change := range changes {
+		if change["scope"] == scope {
+			count++
+		}
+	}
+	return count
+}
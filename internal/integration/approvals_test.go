@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ApprovalIsAtomic(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Gate Org", Slug: "gate-org"})
+	require.NoError(t, err)
+	app, err := configService.CreateApplication("gate-org", &models.CreateApplicationRequest{Name: "Web", Slug: "web"})
+	require.NoError(t, err)
+	keyApp, err := configService.ValidateAPIKey(app.APIKey)
+	require.NoError(t, err)
+	env, err := configService.CreateEnvironment("gate-org", "web", &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod", RequiresApproval: true})
+	require.NoError(t, err)
+
+	propose := func(config string) *models.PendingChange {
+		response, err := configService.UpdateConfiguration("gate-org", "web", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(config),
+		}, false)
+		require.NoError(t, err)
+		require.NotNil(t, response.Pending)
+		return response.Pending
+	}
+
+	t.Run("a failed activation leaves the change pending", func(t *testing.T) {
+		pending := propose(`{"timeout": 10}`)
+		_, err := suite.DB.Exec(`DELETE FROM config_versions WHERE env_id = $1 AND version = $2`, env.ID, pending.Version)
+		require.NoError(t, err)
+
+		err = suite.Repos.PendingChanges.Approve(pending, &models.ConfigChange{EnvID: env.ID, Action: "approve"})
+		require.Error(t, err)
+
+		stored, err := suite.Repos.PendingChanges.GetByID(env.ID, pending.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.PendingStatusPending, stored.Status)
+		assert.Nil(t, stored.ReviewedAt)
+	})
+
+	t.Run("an approval activates the version and logs it", func(t *testing.T) {
+		pending := propose(`{"timeout": 20}`)
+
+		// Proposals cannot be fetched by version before they are approved
+		_, err := configService.GetConfigurationVersionByAPIKey(keyApp, "prod", pending.Version)
+		assert.True(t, errors.Is(err, services.ErrNotFound), "error: %v", err)
+
+		response, err := configService.ApprovePendingChange("gate-org", "web", "prod", &models.ReviewChangeRequest{ChangeID: pending.ID})
+		require.NoError(t, err)
+		assert.Equal(t, models.PendingStatusApproved, response.Pending.Status)
+
+		active, err := suite.Repos.ConfigVersions.GetActiveByEnvironment(env.ID)
+		require.NoError(t, err)
+		assert.Equal(t, pending.Version, active.Version)
+
+		changes, total, err := suite.Repos.ConfigChanges.ListByEnvironment(env.ID, models.DefaultPaginationParams())
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		assert.Equal(t, "approve", changes[0].Action)
+		assert.Nil(t, changes[0].VersionFrom)
+
+		config, err := configService.GetConfigurationVersionByAPIKey(keyApp, "prod", pending.Version)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"timeout": 20}`, string(config.Config))
+	})
+
+	t.Run("an admin token cannot approve its own change", func(t *testing.T) {
+		token := func(name string) *string { return &name }
+
+		response, err := configService.UpdateConfiguration("gate-org", "web", "prod", &models.CreateConfigRequest{
+			Config:     json.RawMessage(`{"timeout": 30}`),
+			CreatedBy:  token("alice"),
+			AdminToken: token("ci-bot"),
+		}, false)
+		require.NoError(t, err)
+		require.NotNil(t, response.Pending)
+		assert.Equal(t, "ci-bot", *response.Pending.ProposedBy)
+
+		// Naming someone else as the reviewer does not help
+		_, err = configService.ApprovePendingChange("gate-org", "web", "prod", &models.ReviewChangeRequest{
+			ChangeID: response.Pending.ID, ReviewedBy: token("bob"), AdminToken: token("ci-bot"),
+		})
+		assert.True(t, errors.Is(err, services.ErrForbidden), "error: %v", err)
+
+		// Changes proposed without a known proposer cannot be approved with admin auth
+		unknown := propose(`{"timeout": 40}`)
+		_, err = configService.ApprovePendingChange("gate-org", "web", "prod", &models.ReviewChangeRequest{
+			ChangeID: unknown.ID, AdminToken: token("release-manager"),
+		})
+		assert.True(t, errors.Is(err, services.ErrForbidden), "error: %v", err)
+
+		approved, err := configService.ApprovePendingChange("gate-org", "web", "prod", &models.ReviewChangeRequest{
+			ChangeID: response.Pending.ID, AdminToken: token("release-manager"),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "release-manager", *approved.Pending.ReviewedBy)
+	})
+}
@@ -0,0 +1,121 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_BatchUpdateConfigurations(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Batch Org", "batch-org")
+	app := suite.CreateTestApplication(t, org.ID, "Batch App", "batch-app", "batch-api-key")
+	for _, slug := range []string{"dev", "staging", "prod"} {
+		suite.CreateTestEnvironment(t, app.ID, slug, slug)
+	}
+
+	config := services.NewConfig()
+	config.MaxConfigDepth = 3
+	configService := services.NewConfigService(config, suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.UpdateConfiguration("batch-org", "batch-app", "prod", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"checkout": false}`),
+	}, false)
+	require.NoError(t, err)
+
+	activeVersion := func(slug string) int {
+		active, err := configService.GetConfiguration("batch-org", "batch-app", slug)
+		if err != nil {
+			return 0
+		}
+		return active.Version
+	}
+
+	t.Run("every environment gets a new version", func(t *testing.T) {
+		result, err := configService.UpdateConfigurations("batch-org", "batch-app", &models.BatchUpdateConfigRequest{
+			Configs: map[string]json.RawMessage{
+				"dev":     json.RawMessage(`{"checkout": true}`),
+				"staging": json.RawMessage(`{"checkout": true}`),
+				"prod":    json.RawMessage(`{"checkout": true}`),
+			},
+			CreatedBy: stringPtr("release-bot"),
+		}, false)
+		require.NoError(t, err)
+		assert.Empty(t, result.Errors)
+		require.Len(t, result.Configs, 3)
+		assert.Equal(t, 1, result.Configs["dev"].Version)
+		assert.Equal(t, 2, result.Configs["prod"].Version)
+
+		response, err := configService.GetConfiguration("batch-org", "batch-app", "prod")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"checkout": true}`, string(response.Config))
+
+		env, err := suite.Repos.Environments.GetBySlug("batch-org", "batch-app", "prod")
+		require.NoError(t, err)
+		changes, _, err := suite.Repos.ConfigChanges.ListByEnvironment(env.ID, models.DefaultPaginationParams())
+		require.NoError(t, err)
+		require.NotEmpty(t, changes)
+		require.NotNil(t, changes[0].VersionFrom)
+		assert.Equal(t, 1, *changes[0].VersionFrom)
+		assert.Equal(t, 2, changes[0].VersionTo)
+		assert.Equal(t, "release-bot", *changes[0].CreatedBy)
+		assert.Equal(t, "update", changes[0].Action)
+		require.Len(t, changes, 2)
+		assert.Equal(t, "create", changes[1].Action)
+
+		dev, err := suite.Repos.Environments.GetBySlug("batch-org", "batch-app", "dev")
+		require.NoError(t, err)
+		changes, _, err = suite.Repos.ConfigChanges.ListByEnvironment(dev.ID, models.DefaultPaginationParams())
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, "create", changes[0].Action)
+	})
+
+	t.Run("one failed environment leaves every environment unchanged", func(t *testing.T) {
+		result, err := configService.UpdateConfigurations("batch-org", "batch-app", &models.BatchUpdateConfigRequest{
+			Configs: map[string]json.RawMessage{
+				"dev":  json.RawMessage(`{"checkout": false}`),
+				"prod": json.RawMessage(`{"a": {"b": [{"c": 1}]}}`),
+			},
+		}, false)
+		require.NoError(t, err)
+		assert.Empty(t, result.Configs)
+		assert.Equal(t, http.StatusUnprocessableEntity, result.Errors["prod"].Status)
+		assert.Equal(t, http.StatusFailedDependency, result.Errors["dev"].Status)
+
+		assert.Equal(t, 1, activeVersion("dev"))
+		assert.Equal(t, 2, activeVersion("prod"))
+	})
+
+	t.Run("unknown environments fail the batch", func(t *testing.T) {
+		result, err := configService.UpdateConfigurations("batch-org", "batch-app", &models.BatchUpdateConfigRequest{
+			Configs: map[string]json.RawMessage{
+				"dev":     json.RawMessage(`{"checkout": false}`),
+				"missing": json.RawMessage(`{}`),
+			},
+		}, false)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, result.Errors["missing"].Status)
+		assert.Equal(t, 1, activeVersion("dev"))
+	})
+
+	t.Run("dry run saves nothing", func(t *testing.T) {
+		result, err := configService.UpdateConfigurations("batch-org", "batch-app", &models.BatchUpdateConfigRequest{
+			Configs: map[string]json.RawMessage{"dev": json.RawMessage(`{"checkout": false}`)},
+		}, true)
+		require.NoError(t, err)
+		require.Contains(t, result.Configs, "dev")
+		assert.True(t, result.Configs["dev"].DryRun)
+		assert.Equal(t, 2, result.Configs["dev"].Version)
+		assert.Equal(t, 1, activeVersion("dev"))
+	})
+}
@@ -0,0 +1,67 @@
+package integration
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_BlobKeys(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Blob Org", "blob-org")
+	app := suite.CreateTestApplication(t, org.ID, "Web", "web", "blob-web-api-key")
+	suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	env, err := configService.UpdateEnvironment("blob-org", "web", "prod", &models.UpdateEnvironmentRequest{
+		Name:     "Production",
+		BlobKeys: []string{"tls.cert"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tls.cert"}, env.BlobKeys)
+
+	oldCert := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 100)))
+	_, err = configService.UpdateConfiguration("blob-org", "web", "prod", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"tls": {"cert": "` + oldCert + `"}}`),
+	}, false)
+	require.NoError(t, err)
+
+	t.Run("diffs summarize blobs", func(t *testing.T) {
+		newCert := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("b", 120)))
+		response, err := configService.UpdateConfiguration("blob-org", "web", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"tls": {"cert": "` + newCert + `"}}`),
+		}, true)
+		require.NoError(t, err)
+		require.NotNil(t, response.Diff)
+		require.Len(t, response.Diff.Entries, 1)
+		assert.Contains(t, response.Diff.Entries[0].OldValue, "<binary: 100 bytes")
+		assert.Contains(t, response.Diff.Entries[0].NewValue, "<binary: 120 bytes")
+	})
+
+	t.Run("blobs must be base64", func(t *testing.T) {
+		_, err := configService.UpdateConfiguration("blob-org", "web", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"tls": {"cert": "-----BEGIN CERTIFICATE-----"}}`),
+		}, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "configuration has invalid blobs: tls.cert is marked as a blob but is not base64-encoded")
+		assert.True(t, errors.Is(err, services.ErrValidation))
+	})
+
+	t.Run("responses list blob keys", func(t *testing.T) {
+		response, err := configService.GetConfiguration("blob-org", "web", "prod")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"tls.cert"}, response.BlobKeys)
+	})
+}
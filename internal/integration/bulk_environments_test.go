@@ -0,0 +1,117 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bulkItem(name, slug, config string) models.BulkEnvironmentItem {
+	item := models.BulkEnvironmentItem{
+		CreateEnvironmentRequest: models.CreateEnvironmentRequest{Name: name, Slug: slug},
+	}
+	if config != "" {
+		item.Config = json.RawMessage(config)
+	}
+	return item
+}
+
+func TestIntegration_BulkCreateEnvironments(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Bulk Org", "bulk-org")
+	app := suite.CreateTestApplication(t, org.ID, "Bulk App", "bulk-app", "bulk-api-key")
+	suite.CreateTestEnvironment(t, app.ID, "Existing", "existing")
+
+	config := services.NewConfig()
+	config.MaxConfigDepth = 3
+	configService := services.NewConfigService(config, suite.Repos, suite.Redis.Client, nil)
+
+	envExists := func(slug string) bool {
+		exists, err := suite.Repos.Environments.Exists(app.ID, slug)
+		require.NoError(t, err)
+		return exists
+	}
+
+	t.Run("creates every environment with its initial configuration", func(t *testing.T) {
+		result, err := configService.CreateEnvironments("bulk-org", "bulk-app", &models.BulkCreateEnvironmentsRequest{
+			Environments: []models.BulkEnvironmentItem{
+				bulkItem("Development", "dev", `{"debug": true}`),
+				bulkItem("Staging", "staging", ""),
+			},
+			CreatedBy: stringPtr("admin"),
+		}, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, result.Created)
+		assert.Equal(t, 0, result.Failed)
+		assert.Equal(t, http.StatusCreated, result.Results[0].Status)
+		assert.Equal(t, 1, result.Results[0].Version)
+		assert.Equal(t, 0, result.Results[1].Version)
+
+		response, err := configService.GetConfiguration("bulk-org", "bulk-app", "dev")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"debug": true}`, string(response.Config))
+
+		_, err = configService.GetConfiguration("bulk-org", "bulk-app", "staging")
+		assert.Error(t, err)
+
+		changes, _, err := suite.Repos.ConfigChanges.ListByEnvironment(result.Results[0].Environment.ID, models.PaginationParams{Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, 1, changes[0].VersionTo)
+	})
+
+	t.Run("any failure rolls back every environment", func(t *testing.T) {
+		result, err := configService.CreateEnvironments("bulk-org", "bulk-app", &models.BulkCreateEnvironmentsRequest{
+			Environments: []models.BulkEnvironmentItem{
+				bulkItem("QA", "qa", ""),
+				bulkItem("Existing", "existing", ""),
+				bulkItem("Broken", "broken", `{"a": {"b": {"c": {"d": 1}}}}`),
+			},
+		}, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, 0, result.Created)
+		assert.Equal(t, 3, result.Failed)
+		assert.Equal(t, http.StatusFailedDependency, result.Results[0].Status)
+		assert.Equal(t, http.StatusConflict, result.Results[1].Status)
+		assert.Equal(t, http.StatusUnprocessableEntity, result.Results[2].Status)
+		assert.False(t, envExists("qa"))
+		assert.False(t, envExists("broken"))
+	})
+
+	t.Run("continue on error keeps the environments that succeed", func(t *testing.T) {
+		result, err := configService.CreateEnvironments("bulk-org", "bulk-app", &models.BulkCreateEnvironmentsRequest{
+			Environments: []models.BulkEnvironmentItem{
+				bulkItem("QA", "qa", `{"debug": false}`),
+				bulkItem("QA again", "qa", ""),
+				bulkItem("Invalid", "invalid", `{invalid`),
+			},
+		}, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, result.Created)
+		assert.Equal(t, 2, result.Failed)
+		assert.Equal(t, http.StatusCreated, result.Results[0].Status)
+		assert.Equal(t, http.StatusConflict, result.Results[1].Status)
+		assert.Equal(t, http.StatusBadRequest, result.Results[2].Status)
+		assert.True(t, envExists("qa"))
+		assert.False(t, envExists("invalid"))
+	})
+
+	t.Run("unknown application", func(t *testing.T) {
+		_, err := configService.CreateEnvironments("bulk-org", "missing-app", &models.BulkCreateEnvironmentsRequest{
+			Environments: []models.BulkEnvironmentItem{bulkItem("Dev", "dev", "")},
+		}, false)
+		assert.ErrorContains(t, err, "application not found")
+	})
+}
@@ -0,0 +1,64 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/cache"
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_CacheInvalidationIsScopedToTheApplication(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Tenant Org", "tenant-org")
+	appA := suite.CreateTestApplication(t, org.ID, "Tenant A", "tenant-a", "tenant-a-api-key")
+	appB := suite.CreateTestApplication(t, org.ID, "Tenant B", "tenant-b", "tenant-b-api-key")
+	suite.CreateTestEnvironment(t, appA.ID, "Production", "prod")
+	suite.CreateTestEnvironment(t, appB.ID, "Production", "prod")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	for _, appSlug := range []string{"tenant-a", "tenant-b"} {
+		_, err := configService.UpdateConfiguration("tenant-org", appSlug, "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"release": 1}`),
+		}, false)
+		require.NoError(t, err)
+	}
+
+	// Reading through the API keys caches both configurations
+	keyApps := make(map[string]*models.Application)
+	for _, apiKey := range []string{appA.APIKey, appB.APIKey} {
+		app, err := configService.ValidateAPIKey(apiKey)
+		require.NoError(t, err)
+		keyApps[apiKey] = app
+
+		_, err = configService.GetConfigurationByAPIKey(app, "prod", "")
+		require.NoError(t, err)
+		_, err = suite.Redis.Client.GetConfig(cache.GenerateAPIKeyConfigKey(app.ID.String(), "prod"))
+		require.NoError(t, err)
+	}
+
+	_, err := configService.UpdateConfiguration("tenant-org", "tenant-a", "prod", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"release": 2}`),
+	}, false)
+	require.NoError(t, err)
+
+	_, err = suite.Redis.Client.GetConfig(cache.GenerateAPIKeyConfigKey(appA.ID.String(), "prod"))
+	assert.Error(t, err)
+	_, err = suite.Redis.Client.GetConfig(cache.GenerateAPIKeyConfigKey(appB.ID.String(), "prod"))
+	assert.NoError(t, err, "updating one application must not evict another application's cache")
+
+	config, err := configService.GetConfigurationByAPIKey(keyApps[appA.APIKey], "prod", "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"release": 2}`, string(config.Config))
+	config, err = configService.GetConfigurationByAPIKey(keyApps[appB.APIKey], "prod", "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"release": 1}`, string(config.Config))
+}
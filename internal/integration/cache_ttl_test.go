@@ -0,0 +1,54 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_EnvironmentCacheTTL(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "TTL Org", Slug: "ttl-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("ttl-org", &models.CreateApplicationRequest{Name: "TTL App", Slug: "ttl-app"})
+	require.NoError(t, err)
+
+	ttl := 30
+	_, err = configService.CreateEnvironment("ttl-org", "ttl-app", &models.CreateEnvironmentRequest{Name: "Development", Slug: "dev", CacheTTLSeconds: &ttl})
+	require.NoError(t, err)
+
+	env, err := suite.Repos.Environments.GetBySlug("ttl-org", "ttl-app", "dev")
+	require.NoError(t, err)
+	require.NotNil(t, env.CacheTTLSeconds)
+	assert.Equal(t, 30, *env.CacheTTLSeconds)
+
+	// Configurations of the environment are still served and cached
+	_, err = configService.UpdateConfiguration("ttl-org", "ttl-app", "dev", &models.CreateConfigRequest{Config: json.RawMessage(`{"debug": true}`)}, false)
+	require.NoError(t, err)
+	config, err := configService.GetConfiguration("ttl-org", "ttl-app", "dev")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"debug": true}`, string(config.Config))
+
+	// Other settings leave the TTL unchanged, and 0 restores the global TTL
+	env, err = configService.UpdateEnvironment("ttl-org", "ttl-app", "dev", &models.UpdateEnvironmentRequest{Name: "Dev"})
+	require.NoError(t, err)
+	require.NotNil(t, env.CacheTTLSeconds)
+
+	reset := 0
+	_, err = configService.UpdateEnvironment("ttl-org", "ttl-app", "dev", &models.UpdateEnvironmentRequest{Name: "Dev", CacheTTLSeconds: &reset})
+	require.NoError(t, err)
+
+	env, err = suite.Repos.Environments.GetBySlug("ttl-org", "ttl-app", "dev")
+	require.NoError(t, err)
+	assert.Nil(t, env.CacheTTLSeconds)
+}
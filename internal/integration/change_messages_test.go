@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ChangeMessages(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Message Org", Slug: "message-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("message-org", &models.CreateApplicationRequest{Name: "Message App", Slug: "message-app"})
+	require.NoError(t, err)
+	_, err = configService.CreateEnvironment("message-org", "message-app", &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod"})
+	require.NoError(t, err)
+	_, err = configService.CreateEnvironment("message-org", "message-app", &models.CreateEnvironmentRequest{Name: "Reviewed", Slug: "reviewed", RequiresApproval: true})
+	require.NoError(t, err)
+
+	message := func(text string) *string { return &text }
+	latestChange := func(envSlug string) map[string]interface{} {
+		changes, err := configService.GetConfigurationChanges("message-org", "message-app", envSlug, models.DefaultPaginationParams())
+		require.NoError(t, err)
+		data := changes.Data.([]map[string]interface{})
+		require.NotEmpty(t, data)
+		return data[0]
+	}
+
+	_, err = configService.UpdateConfiguration("message-org", "message-app", "prod", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"timeout": 30}`),
+	}, false)
+	require.NoError(t, err)
+
+	t.Run("changes without a message", func(t *testing.T) {
+		assert.Nil(t, latestChange("prod")["message"])
+	})
+
+	t.Run("updates log their message", func(t *testing.T) {
+		_, err := configService.UpdateConfiguration("message-org", "message-app", "prod", &models.CreateConfigRequest{
+			Config:  json.RawMessage(`{"timeout": 60}`),
+			Message: message("bumped timeout for Black Friday"),
+		}, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, message("bumped timeout for Black Friday"), latestChange("prod")["message"])
+	})
+
+	t.Run("rollbacks log their message", func(t *testing.T) {
+		_, err := configService.RollbackConfiguration("message-org", "message-app", "prod", &models.RollbackRequest{
+			ToVersion: 1,
+			Message:   message("Black Friday is over"),
+		})
+		require.NoError(t, err)
+
+		change := latestChange("prod")
+		assert.Equal(t, "rollback", change["action"])
+		assert.Equal(t, message("Black Friday is over"), change["message"])
+	})
+
+	t.Run("approved changes keep the proposer's message", func(t *testing.T) {
+		proposed, err := configService.UpdateConfiguration("message-org", "message-app", "reviewed", &models.CreateConfigRequest{
+			Config:  json.RawMessage(`{"timeout": 45}`),
+			Message: message("slower upstream"),
+		}, false)
+		require.NoError(t, err)
+		require.NotNil(t, proposed.Pending)
+		assert.Equal(t, message("slower upstream"), proposed.Pending.Message)
+
+		_, err = configService.ApprovePendingChange("message-org", "message-app", "reviewed", &models.ReviewChangeRequest{ChangeID: proposed.Pending.ID})
+		require.NoError(t, err)
+
+		change := latestChange("reviewed")
+		assert.Equal(t, "approve", change["action"])
+		assert.Equal(t, message("slower upstream"), change["message"])
+	})
+}
@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ExportConfigChanges(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Export Org", Slug: "export-org"})
+	require.NoError(t, err)
+	for _, appSlug := range []string{"web", "worker"} {
+		_, err = configService.CreateApplication("export-org", &models.CreateApplicationRequest{Name: appSlug, Slug: appSlug})
+		require.NoError(t, err)
+		_, err = configService.CreateEnvironment("export-org", appSlug, &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod"})
+		require.NoError(t, err)
+	}
+
+	message := "first version"
+	for i, config := range []string{`{"timeout": 30}`, `{"timeout": 60}`, `{"timeout": 90}`} {
+		req := &models.CreateConfigRequest{Config: json.RawMessage(config)}
+		if i == 0 {
+			req.Message = &message
+		}
+		_, err := configService.UpdateConfiguration("export-org", "web", "prod", req, false)
+		require.NoError(t, err)
+	}
+	_, err = configService.UpdateConfiguration("export-org", "worker", "prod", &models.CreateConfigRequest{Config: json.RawMessage(`{"queue": "jobs"}`)}, false)
+	require.NoError(t, err)
+
+	collect := func(appSlug string, orgScope bool) []models.ConfigChange {
+		var changes []models.ConfigChange
+		err := configService.ExportConfigurationChanges("export-org", appSlug, "prod", orgScope, func(change *models.ConfigChange) error {
+			changes = append(changes, *change)
+			return nil
+		})
+		require.NoError(t, err)
+		return changes
+	}
+
+	t.Run("environment changes oldest first", func(t *testing.T) {
+		changes := collect("web", false)
+		require.Len(t, changes, 3)
+		for i, change := range changes {
+			assert.Equal(t, i+1, change.VersionTo)
+			assert.Equal(t, "web", change.Environment.Application.Slug)
+		}
+		assert.Equal(t, &message, changes[0].Message)
+	})
+
+	t.Run("organization changes", func(t *testing.T) {
+		changes := collect("web", true)
+		require.Len(t, changes, 4)
+		assert.Equal(t, "worker", changes[3].Environment.Application.Slug)
+		assert.Equal(t, "export-org", changes[3].Environment.Application.Organization.Slug)
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		stop := errors.New("client went away")
+		calls := 0
+		err := configService.ExportConfigurationChanges("export-org", "web", "prod", false, func(*models.ConfigChange) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		err := configService.ExportConfigurationChanges("export-org", "web", "missing", false, func(*models.ConfigChange) error {
+			t.Fatal("no change expected")
+			return nil
+		})
+		assert.ErrorIs(t, err, services.ErrNotFound)
+	})
+}
@@ -0,0 +1,115 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_CompareAndSwap(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "CAS Org", Slug: "cas-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("cas-org", &models.CreateApplicationRequest{Name: "CAS App", Slug: "cas-app"})
+	require.NoError(t, err)
+	_, err = configService.CreateEnvironment("cas-org", "cas-app", &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod"})
+	require.NoError(t, err)
+
+	swap := func(key, expected, value string) (*models.ConfigResponse, error) {
+		req := &models.CompareAndSwapRequest{Key: key, Value: json.RawMessage(value)}
+		if expected != "" {
+			req.Expected = json.RawMessage(expected)
+		}
+		return configService.CompareAndSwapKey("cas-org", "cas-app", "prod", req)
+	}
+	activeConfig := func() map[string]interface{} {
+		config, err := configService.GetRawConfiguration("cas-org", "cas-app", "prod")
+		require.NoError(t, err)
+		var document map[string]interface{}
+		require.NoError(t, json.Unmarshal(config.Config, &document))
+		return document
+	}
+
+	t.Run("claims an absent key without an active version", func(t *testing.T) {
+		response, err := swap("leader.holder", "", `"worker-1"`)
+		require.NoError(t, err)
+		assert.Equal(t, 1, response.Version)
+		assert.Equal(t, map[string]interface{}{"holder": "worker-1"}, activeConfig()["leader"])
+
+		changes, err := configService.GetConfigurationChanges("cas-org", "cas-app", "prod", models.DefaultPaginationParams())
+		require.NoError(t, err)
+		assert.Equal(t, "create", changes.Data.([]map[string]interface{})[0]["action"])
+	})
+
+	t.Run("swaps the expected value", func(t *testing.T) {
+		_, err := configService.UpdateConfiguration("cas-org", "cas-app", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"timeout": 30, "leader": {"holder": "worker-1"}}`),
+		}, false)
+		require.NoError(t, err)
+
+		response, err := swap("leader.holder", `"worker-1"`, `"worker-2"`)
+		require.NoError(t, err)
+		assert.Equal(t, 3, response.Version)
+
+		config := activeConfig()
+		assert.Equal(t, map[string]interface{}{"holder": "worker-2"}, config["leader"])
+		assert.Equal(t, float64(30), config["timeout"])
+
+		changes, err := configService.GetConfigurationChanges("cas-org", "cas-app", "prod", models.DefaultPaginationParams())
+		require.NoError(t, err)
+		latest := changes.Data.([]map[string]interface{})[0]
+		assert.Equal(t, "update", latest["action"])
+		assert.Equal(t, 3, latest["version_to"])
+	})
+
+	t.Run("mismatches are conflicts", func(t *testing.T) {
+		_, err := swap("leader.holder", `"worker-1"`, `"worker-3"`)
+		assert.True(t, errors.Is(err, services.ErrConflict))
+
+		_, err = swap("leader.holder", "", `"worker-3"`)
+		assert.True(t, errors.Is(err, services.ErrConflict))
+
+		assert.Equal(t, map[string]interface{}{"holder": "worker-2"}, activeConfig()["leader"])
+	})
+
+	t.Run("only one concurrent swap wins", func(t *testing.T) {
+		const workers = 8
+
+		var wg sync.WaitGroup
+		results := make(chan error, workers)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := swap("leader.holder", `"worker-2"`, fmt.Sprintf(`"worker-%d"`, 10+i))
+				results <- err
+			}(i)
+		}
+		wg.Wait()
+		close(results)
+
+		succeeded := 0
+		for err := range results {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.True(t, errors.Is(err, services.ErrConflict), err.Error())
+		}
+		assert.Equal(t, 1, succeeded)
+		assert.NotEqual(t, "worker-2", activeConfig()["leader"].(map[string]interface{})["holder"])
+	})
+}
@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ConcurrentUpdates(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Concurrent Org", "concurrent-org")
+	app := suite.CreateTestApplication(t, org.ID, "Web", "web", "concurrent-web-api-key")
+	env := suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	const updates = 10
+	versions := make([]int, updates)
+	errs := make([]error, updates)
+
+	var wg sync.WaitGroup
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, err := configService.UpdateConfiguration("concurrent-org", "web", "prod", &models.CreateConfigRequest{
+				Config: json.RawMessage(fmt.Sprintf(`{"writer": %d}`, i)),
+			}, false)
+			errs[i] = err
+			if err == nil {
+				versions[i] = response.Version
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Updates either get a version of their own or fail with a conflict
+	seen := make(map[int]bool)
+	for i, err := range errs {
+		if err != nil {
+			assert.ErrorIs(t, err, services.ErrConflict)
+			continue
+		}
+		assert.False(t, seen[versions[i]], "version %d was returned twice", versions[i])
+		seen[versions[i]] = true
+	}
+	require.NotEmpty(t, seen)
+
+	history, total, err := suite.Repos.ConfigVersions.ListByEnvironment(env.ID, models.DefaultPaginationParams())
+	require.NoError(t, err)
+	assert.Equal(t, len(seen), total)
+	for _, version := range history {
+		assert.True(t, seen[version.Version], "version %d was not returned", version.Version)
+	}
+
+	active, err := suite.Repos.ConfigVersions.GetActiveByEnvironment(env.ID)
+	require.NoError(t, err)
+	assert.True(t, seen[active.Version])
+}
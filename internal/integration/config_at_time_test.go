@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ConfigAtTime(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Incident Org", Slug: "incident-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("incident-org", &models.CreateApplicationRequest{Name: "Incident App", Slug: "incident-app"})
+	require.NoError(t, err)
+	_, err = configService.CreateEnvironment("incident-org", "incident-app", &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod"})
+	require.NoError(t, err)
+
+	for _, config := range []string{`{"timeout": 10}`, `{"timeout": 20}`} {
+		_, err := configService.UpdateConfiguration("incident-org", "incident-app", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(config),
+		}, false)
+		require.NoError(t, err)
+	}
+	_, err = configService.RollbackConfiguration("incident-org", "incident-app", "prod", &models.RollbackRequest{ToVersion: 1})
+	require.NoError(t, err)
+
+	// The change log, oldest first: v1, v2, then the rollback to v1
+	changes, err := configService.GetConfigurationChanges("incident-org", "incident-app", "prod", models.DefaultPaginationParams())
+	require.NoError(t, err)
+	data := changes.Data.([]map[string]interface{})
+	require.Len(t, data, 3)
+	changedAt := func(i int) time.Time {
+		return data[len(data)-1-i]["created_at"].(time.Time)
+	}
+
+	versionAt := func(at time.Time) int {
+		response, err := configService.GetConfigurationAtTime("incident-org", "incident-app", "prod", at)
+		require.NoError(t, err)
+		require.NotNil(t, response.At)
+		return response.Version
+	}
+
+	t.Run("follows the change log", func(t *testing.T) {
+		assert.Equal(t, 1, versionAt(changedAt(0)))
+		assert.Equal(t, 1, versionAt(changedAt(1).Add(-time.Microsecond)))
+		assert.Equal(t, 2, versionAt(changedAt(1)))
+		assert.Equal(t, 1, versionAt(changedAt(2)))
+		assert.Equal(t, 1, versionAt(time.Now().Add(time.Hour)))
+	})
+
+	t.Run("returns the version as stored", func(t *testing.T) {
+		response, err := configService.GetConfigurationAtTime("incident-org", "incident-app", "prod", changedAt(1))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"timeout": 20}`, string(response.Config))
+	})
+
+	t.Run("nothing active before the first version", func(t *testing.T) {
+		_, err := configService.GetConfigurationAtTime("incident-org", "incident-app", "prod", changedAt(0).Add(-time.Second))
+		assert.True(t, errors.Is(err, services.ErrNotFound))
+	})
+
+	t.Run("proposals that were never approved are not served", func(t *testing.T) {
+		_, err := configService.CreateEnvironment("incident-org", "incident-app", &models.CreateEnvironmentRequest{Name: "Gated", Slug: "gated", RequiresApproval: true})
+		require.NoError(t, err)
+
+		// Only a proposal: nothing was ever active
+		proposal := func(config string) *models.PendingChange {
+			response, err := configService.UpdateConfiguration("incident-org", "incident-app", "gated", &models.CreateConfigRequest{
+				Config: json.RawMessage(config),
+			}, false)
+			require.NoError(t, err)
+			require.NotNil(t, response.Pending)
+			return response.Pending
+		}
+		first := proposal(`{"timeout": 10}`)
+		_, err = configService.GetConfigurationAtTime("incident-org", "incident-app", "gated", time.Now().Add(time.Hour))
+		assert.True(t, errors.Is(err, services.ErrNotFound))
+
+		// Version 1 goes live, then version 2 is proposed
+		_, err = configService.ApprovePendingChange("incident-org", "incident-app", "gated", &models.ReviewChangeRequest{ChangeID: first.ID})
+		require.NoError(t, err)
+		proposal(`{"timeout": 99}`)
+
+		response, err := configService.GetConfigurationAtTime("incident-org", "incident-app", "gated", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, response.Version)
+
+		// Without the change log, only the active version can be served
+		_, err = suite.DB.Exec(`DELETE FROM config_changes WHERE env_id = (SELECT id FROM environments WHERE slug = 'gated')`)
+		require.NoError(t, err)
+		response, err = configService.GetConfigurationAtTime("incident-org", "incident-app", "gated", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, response.Version)
+		assert.JSONEq(t, `{"timeout": 10}`, string(response.Config))
+	})
+}
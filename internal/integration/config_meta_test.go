@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ConfigMeta(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Meta Org", "meta-org")
+	app := suite.CreateTestApplication(t, org.ID, "Web", "web", "meta-web-api-key")
+	suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.UpdateConfiguration("meta-org", "web", "prod", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"api_timeout": 30}`),
+	}, false)
+	require.NoError(t, err)
+
+	createdBy := "alice"
+	response, err := configService.UpdateConfiguration("meta-org", "web", "prod", &models.CreateConfigRequest{
+		Config:    json.RawMessage(`{"api_timeout": 60}`),
+		CreatedBy: &createdBy,
+	}, false)
+	require.NoError(t, err)
+
+	t.Run("creator of the version", func(t *testing.T) {
+		meta, err := configService.GetConfigurationMeta("meta-org", "web", "prod", response.Version)
+		require.NoError(t, err)
+		require.NotNil(t, meta.CreatedBy)
+		assert.Equal(t, "alice", *meta.CreatedBy)
+		assert.Equal(t, response.UpdatedAt.Unix(), meta.CreatedAt.Unix())
+		assert.Equal(t, "less than a minute", meta.Age)
+	})
+
+	t.Run("versions created without a name", func(t *testing.T) {
+		meta, err := configService.GetConfigurationMeta("meta-org", "web", "prod", response.Version-1)
+		require.NoError(t, err)
+		assert.Nil(t, meta.CreatedBy)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		_, err := configService.GetConfigurationMeta("meta-org", "web", "prod", 99)
+		assert.ErrorIs(t, err, services.ErrNotFound)
+	})
+}
@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ConfigNormalization(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Canonical Org", "canonical-org")
+	app := suite.CreateTestApplication(t, org.ID, "Web", "web", "canonical-web-api-key")
+	suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+	suite.CreateTestEnvironment(t, app.ID, "Staging", "staging")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+	canonical := `{"database":{"host":"db","port":5432},"ratio":1.0,"timeout":30}`
+
+	t.Run("updates store the canonical form", func(t *testing.T) {
+		response, err := configService.UpdateConfiguration("canonical-org", "web", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage("{\n  \"timeout\": 30,\n  \"ratio\": 1.0,\n  \"database\": {\"port\": 5432, \"host\": \"db\"}\n}"),
+		}, false)
+		require.NoError(t, err)
+		assert.Equal(t, canonical, string(response.Config))
+
+		config, err := configService.GetConfiguration("canonical-org", "web", "prod")
+		require.NoError(t, err)
+		assert.JSONEq(t, canonical, string(config.Config))
+		assert.Contains(t, string(config.Config), "1.0", "numbers keep their digits")
+	})
+
+	t.Run("batch updates store the canonical form", func(t *testing.T) {
+		response, err := configService.UpdateConfigurations("canonical-org", "web", &models.BatchUpdateConfigRequest{
+			Configs: map[string]json.RawMessage{
+				"staging": json.RawMessage(`{ "timeout": 30, "ratio": 1.0, "database": { "port": 5432, "host": "db" } }`),
+			},
+		}, false)
+		require.NoError(t, err)
+		require.Contains(t, response.Configs, "staging")
+		assert.Equal(t, canonical, string(response.Configs["staging"].Config))
+	})
+}
@@ -0,0 +1,80 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ConfigReferences(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Ref Org", "ref-org")
+	app := suite.CreateTestApplication(t, org.ID, "Ref App", "ref-app", "ref-app-api-key")
+	suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+	suite.CreateTestEnvironment(t, app.ID, "Staging", "staging")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+	keyApp, err := configService.ValidateAPIKey("ref-app-api-key")
+	require.NoError(t, err)
+
+	update := func(envSlug, config string) error {
+		_, err := configService.UpdateConfiguration("ref-org", "ref-app", envSlug, &models.CreateConfigRequest{
+			Config: json.RawMessage(config),
+		}, false)
+		return err
+	}
+
+	require.NoError(t, update("prod", `{"shared_api_base": "https://api.example.com", "replicas": 3}`))
+	require.NoError(t, update("staging", `{"api_base": {"$ref": "env://prod/shared_api_base"}, "replicas": 1}`))
+
+	t.Run("references are resolved on read", func(t *testing.T) {
+		config, err := configService.GetConfiguration("ref-org", "ref-app", "staging")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"api_base": "https://api.example.com", "replicas": 1}`, string(config.Config))
+		assert.Equal(t, []models.ConfigReferenceSource{{Environment: "prod", Version: 1}}, config.References)
+
+		byKey, err := configService.GetConfigurationByAPIKey(keyApp, "staging", "")
+		require.NoError(t, err)
+		assert.JSONEq(t, string(config.Config), string(byKey.Config))
+
+		raw, err := configService.GetRawConfiguration("ref-org", "ref-app", "staging")
+		require.NoError(t, err)
+		assert.Contains(t, string(raw.Config), "env://prod/shared_api_base")
+	})
+
+	t.Run("changes of the referenced environment reach cached dependents", func(t *testing.T) {
+		require.NoError(t, update("prod", `{"shared_api_base": "https://api-v2.example.com", "replicas": 3}`))
+
+		config, err := configService.GetConfiguration("ref-org", "ref-app", "staging")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"api_base": "https://api-v2.example.com", "replicas": 1}`, string(config.Config))
+		assert.Equal(t, 2, config.References[0].Version)
+
+		byKey, err := configService.GetConfigurationByAPIKey(keyApp, "staging", "")
+		require.NoError(t, err)
+		assert.JSONEq(t, string(config.Config), string(byKey.Config))
+	})
+
+	t.Run("updates with broken references are rejected", func(t *testing.T) {
+		err := update("staging", `{"api_base": {"$ref": "env://prod/missing"}}`)
+		assert.EqualError(t, err, "broken reference env://prod/missing: key missing not found in environment prod")
+
+		err = update("prod", `{"shared_api_base": {"$ref": "env://staging/api_base"}}`)
+		assert.ErrorContains(t, err, "cycle env://staging/api_base -> env://prod/shared_api_base -> env://staging/api_base")
+	})
+
+	t.Run("reads report references that broke later", func(t *testing.T) {
+		require.NoError(t, update("prod", `{"replicas": 3}`))
+
+		_, err := configService.GetConfiguration("ref-org", "ref-app", "staging")
+		assert.EqualError(t, err, "broken reference env://prod/shared_api_base: key shared_api_base not found in environment prod")
+	})
+}
@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ConfigRules(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Rules Org", "rules-org")
+	app := suite.CreateTestApplication(t, org.ID, "Rules App", "rules-app", "rules-api-key")
+	suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	minTimeout, maxTimeout := 1.0, 300.0
+	_, err := configService.SetConfigRules("rules-org", "rules-app", "prod", &models.UpdateConfigRulesRequest{
+		Rules: []models.ConfigRule{
+			{Key: "api_timeout", Type: services.RuleTypeInt, Min: &minTimeout, Max: &maxTimeout},
+			{Key: "database_url", Required: true, Type: services.RuleTypeString, Pattern: "^postgres://"},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("rules are stored", func(t *testing.T) {
+		ruleset, err := configService.GetConfigRules("rules-org", "rules-app", "prod")
+		require.NoError(t, err)
+		require.Len(t, ruleset.Rules, 2)
+		assert.Equal(t, "database_url", ruleset.Rules[1].Key)
+		assert.Equal(t, 300.0, *ruleset.Rules[0].Max)
+	})
+
+	t.Run("update violating the rules is rejected", func(t *testing.T) {
+		_, err := configService.UpdateConfiguration("rules-org", "rules-app", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"api_timeout": 500}`),
+		}, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "configuration violates rules")
+		assert.Contains(t, err.Error(), "api_timeout must be at most 300")
+		assert.Contains(t, err.Error(), "database_url is required")
+	})
+
+	t.Run("update satisfying the rules is saved", func(t *testing.T) {
+		config, err := configService.UpdateConfiguration("rules-org", "rules-app", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"api_timeout": 30, "database_url": "postgres://db/app"}`),
+		}, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, config.Version)
+	})
+
+	t.Run("rules can be removed", func(t *testing.T) {
+		require.NoError(t, configService.DeleteConfigRules("rules-org", "rules-app", "prod"))
+
+		_, err := configService.GetConfigRules("rules-org", "rules-app", "prod")
+		assert.ErrorContains(t, err, "config rules not found")
+
+		err = configService.DeleteConfigRules("rules-org", "rules-app", "prod")
+		assert.ErrorContains(t, err, "config rules not found")
+	})
+}
@@ -0,0 +1,90 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ConfigTemplates(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Template Org", "template-org")
+	app := suite.CreateTestApplication(t, org.ID, "Template App", "template-app", "template-app-api-key")
+	suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	description := "Base web service"
+	template, created, err := configService.SetTemplate("template-org", "web-service", &models.SetConfigTemplateRequest{
+		Description: &description,
+		Template:    json.RawMessage(`{"db": {"host": "{{db_host}}", "port": "{{db_port}}"}, "url": "https://{{domain}}/api"}`),
+	})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, []string{"db_host", "db_port", "domain"}, template.Variables)
+
+	t.Run("templates are listed and updated", func(t *testing.T) {
+		_, created, err := configService.SetTemplate("template-org", "web-service", &models.SetConfigTemplateRequest{
+			Description: &description,
+			Template:    json.RawMessage(`{"db": {"host": "{{db_host}}", "port": "{{db_port}}"}, "url": "https://{{domain}}/api", "replicas": 2}`),
+		})
+		require.NoError(t, err)
+		assert.False(t, created)
+
+		templates, err := configService.ListTemplates("template-org")
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+		assert.Equal(t, "web-service", templates[0].Name)
+		assert.Contains(t, string(templates[0].Template), "replicas")
+	})
+
+	t.Run("instantiating creates a configuration version", func(t *testing.T) {
+		createdBy := "platform-team"
+		config, err := configService.InstantiateTemplate("template-org", "template-app", "prod", &models.InstantiateTemplateRequest{
+			Template: "web-service",
+			Variables: map[string]json.RawMessage{
+				"db_host": json.RawMessage(`"prod-db"`),
+				"db_port": json.RawMessage(`5432`),
+				"domain":  json.RawMessage(`"shop.example.com"`),
+			},
+			CreatedBy: &createdBy,
+		}, false)
+		require.NoError(t, err)
+		assert.Equal(t, 1, config.Version)
+		assert.JSONEq(t, `{"db": {"host": "prod-db", "port": 5432}, "url": "https://shop.example.com/api", "replicas": 2}`, string(config.Config))
+
+		active, err := configService.GetConfiguration("template-org", "template-app", "prod")
+		require.NoError(t, err)
+		assert.Equal(t, config.Version, active.Version)
+	})
+
+	t.Run("missing variables are rejected", func(t *testing.T) {
+		_, err := configService.InstantiateTemplate("template-org", "template-app", "prod", &models.InstantiateTemplateRequest{
+			Template:  "web-service",
+			Variables: map[string]json.RawMessage{"db_host": json.RawMessage(`"prod-db"`)},
+		}, false)
+		require.Error(t, err)
+		assert.Equal(t, "invalid template variables: missing db_port, domain", err.Error())
+	})
+
+	t.Run("deleted templates are not found", func(t *testing.T) {
+		require.NoError(t, configService.DeleteTemplate("template-org", "web-service"))
+
+		_, err := configService.GetTemplate("template-org", "web-service")
+		assert.True(t, errors.Is(err, services.ErrNotFound))
+
+		// Configurations created from the template are kept
+		active, err := configService.GetConfiguration("template-org", "template-app", "prod")
+		require.NoError(t, err)
+		assert.Equal(t, 1, active.Version)
+	})
+}
@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/db"
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_CorruptConfig(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Corrupt Org", Slug: "corrupt-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("corrupt-org", &models.CreateApplicationRequest{Name: "Web", Slug: "web"})
+	require.NoError(t, err)
+	env, err := configService.CreateEnvironment("corrupt-org", "web", &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod"})
+	require.NoError(t, err)
+	_, err = configService.UpdateConfiguration("corrupt-org", "web", "prod", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"feature": true}`),
+	}, false)
+	require.NoError(t, err)
+
+	// Valid JSONB, but not a configuration document
+	_, err = suite.DB.Exec(`UPDATE config_versions SET config_json = '[1, 2]'::jsonb WHERE env_id = $1`, env.ID)
+	require.NoError(t, err)
+
+	t.Run("the repository reports the version", func(t *testing.T) {
+		_, err := suite.Repos.ConfigVersions.GetActiveByEnvironment(env.ID)
+		var corrupt *db.CorruptConfigError
+		require.True(t, errors.As(err, &corrupt))
+		assert.Equal(t, "corrupt-org", corrupt.Organization)
+		assert.Equal(t, "web", corrupt.Application)
+		assert.Equal(t, "prod", corrupt.Environment)
+		assert.Equal(t, 1, corrupt.Version)
+
+		_, err = suite.Repos.ConfigVersions.GetByVersion(env.ID, 1)
+		assert.True(t, errors.Is(err, db.ErrCorruptConfig))
+	})
+
+	t.Run("reads fail as corrupt, not as missing", func(t *testing.T) {
+		_, err := configService.GetConfiguration("corrupt-org", "web", "prod")
+		assert.True(t, errors.Is(err, services.ErrCorruptConfig))
+		assert.False(t, errors.Is(err, services.ErrNotFound))
+
+		_, err = configService.GetConfigurationVersion("corrupt-org", "web", "prod", 1)
+		assert.True(t, errors.Is(err, services.ErrCorruptConfig))
+		assert.False(t, errors.Is(err, services.ErrNotFound))
+	})
+}
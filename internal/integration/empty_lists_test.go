@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_EmptyLists(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+	params := models.DefaultPaginationParams()
+
+	assertEmpty := func(t *testing.T, response *models.PaginatedResponse, err error) {
+		require.NoError(t, err)
+		data, err := json.Marshal(response)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"data": [], "page": 1, "page_size": 20, "total_count": 0, "total_pages": 0}`, string(data))
+	}
+
+	t.Run("organizations", func(t *testing.T) {
+		response, err := configService.ListOrganizations(params)
+		assertEmpty(t, response, err)
+	})
+
+	org := suite.CreateTestOrganization(t, "Empty Org", "empty-org")
+
+	t.Run("applications", func(t *testing.T) {
+		response, err := configService.ListApplications("empty-org", params)
+		assertEmpty(t, response, err)
+	})
+
+	app := suite.CreateTestApplication(t, org.ID, "Web", "web", "empty-web-api-key")
+
+	t.Run("environments", func(t *testing.T) {
+		response, err := configService.ListEnvironments("empty-org", "web", params)
+		assertEmpty(t, response, err)
+	})
+
+	suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+
+	t.Run("configuration history", func(t *testing.T) {
+		response, err := configService.GetConfigurationHistory("empty-org", "web", "prod", params)
+		assertEmpty(t, response, err)
+	})
+
+	t.Run("configuration changes", func(t *testing.T) {
+		response, err := configService.GetConfigurationChanges("empty-org", "web", "prod", params)
+		assertEmpty(t, response, err)
+	})
+
+	t.Run("pending changes", func(t *testing.T) {
+		response, err := configService.ListPendingChanges("empty-org", "web", "prod", "", params)
+		assertEmpty(t, response, err)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		response, err := configService.SearchConfigurations("missing.key", "", params)
+		assertEmpty(t, response, err)
+	})
+}
@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_CreateEnvironmentWithConfig(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Bootstrap Org", "bootstrap-org")
+	app := suite.CreateTestApplication(t, org.ID, "Web", "web", "bootstrap-web-api-key")
+
+	config := services.NewConfig()
+	config.MaxConfigDepth = 3
+	configService := services.NewConfigService(config, suite.Repos, suite.Redis.Client, nil)
+
+	t.Run("the configuration becomes version 1", func(t *testing.T) {
+		env, err := configService.CreateEnvironment("bootstrap-org", "web", &models.CreateEnvironmentRequest{
+			Name:      "Production",
+			Slug:      "prod",
+			Config:    json.RawMessage(`{"api_timeout": 30}`),
+			CreatedBy: stringPtr("alice"),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, env.ConfigSummary)
+		require.NotNil(t, env.ConfigSummary.ActiveVersion)
+		assert.Equal(t, 1, *env.ConfigSummary.ActiveVersion)
+
+		response, err := configService.GetConfiguration("bootstrap-org", "web", "prod")
+		require.NoError(t, err)
+		assert.Equal(t, 1, response.Version)
+		assert.JSONEq(t, `{"api_timeout": 30}`, string(response.Config))
+
+		changes, total, err := suite.Repos.ConfigChanges.ListByEnvironment(env.ID, models.DefaultPaginationParams())
+		require.NoError(t, err)
+		require.Equal(t, 1, total)
+		assert.Equal(t, "create", changes[0].Action)
+		assert.Equal(t, 1, changes[0].VersionTo)
+		assert.Equal(t, stringPtr("alice"), changes[0].CreatedBy)
+	})
+
+	t.Run("without a configuration the environment is empty", func(t *testing.T) {
+		env, err := configService.CreateEnvironment("bootstrap-org", "web", &models.CreateEnvironmentRequest{Name: "Staging", Slug: "staging"})
+		require.NoError(t, err)
+		assert.Nil(t, env.ConfigSummary)
+
+		_, err = configService.GetConfiguration("bootstrap-org", "web", "staging")
+		assert.Error(t, err)
+	})
+
+	t.Run("a rejected configuration creates no environment", func(t *testing.T) {
+		_, err := configService.CreateEnvironment("bootstrap-org", "web", &models.CreateEnvironmentRequest{
+			Name:   "Development",
+			Slug:   "dev",
+			Config: json.RawMessage(`{"a": {"b": {"c": {"d": 1}}}}`),
+		})
+		assert.ErrorContains(t, err, "configuration too deeply nested")
+
+		exists, err := suite.Repos.Environments.Exists(app.ID, "dev")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("environments requiring approval cannot have one", func(t *testing.T) {
+		_, err := configService.CreateEnvironment("bootstrap-org", "web", &models.CreateEnvironmentRequest{
+			Name:             "Reviewed",
+			Slug:             "reviewed",
+			RequiresApproval: true,
+			Config:           json.RawMessage(`{}`),
+		})
+		assert.ErrorContains(t, err, "invalid configuration: environment reviewed requires approval")
+	})
+}
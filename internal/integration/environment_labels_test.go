@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_EnvironmentLabels(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Fleet Org", Slug: "fleet-org"})
+	require.NoError(t, err)
+	for _, app := range []string{"api", "web"} {
+		_, err := configService.CreateApplication("fleet-org", &models.CreateApplicationRequest{Name: app, Slug: app})
+		require.NoError(t, err)
+		for _, env := range []string{"canary", "prod"} {
+			_, err := configService.CreateEnvironment("fleet-org", app, &models.CreateEnvironmentRequest{Name: env, Slug: env})
+			require.NoError(t, err)
+		}
+	}
+
+	setLabel := func(app, env, key, value string) bool {
+		_, created, err := configService.SetLabel("fleet-org", app, env, key, &models.SetLabelRequest{Value: value})
+		require.NoError(t, err)
+		return created
+	}
+
+	t.Run("label CRUD", func(t *testing.T) {
+		assert.True(t, setLabel("api", "canary", "tier", "canary"))
+		assert.True(t, setLabel("web", "canary", "tier", "canary"))
+		assert.True(t, setLabel("web", "prod", "tier", "prod"))
+		assert.True(t, setLabel("web", "prod", "region", "eu"))
+		assert.False(t, setLabel("web", "prod", "region", "us"))
+
+		labels, err := configService.ListLabels("fleet-org", "web", "prod")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"tier": "prod", "region": "us"}, labels.Labels)
+
+		require.NoError(t, configService.DeleteLabel("fleet-org", "web", "prod", "region"))
+		err = configService.DeleteLabel("fleet-org", "web", "prod", "region")
+		assert.True(t, errors.Is(err, services.ErrNotFound))
+
+		_, _, err = configService.SetLabel("fleet-org", "web", "prod", "bad key", &models.SetLabelRequest{Value: "x"})
+		assert.Error(t, err)
+	})
+
+	t.Run("batch update across applications", func(t *testing.T) {
+		req := &models.BatchUpdateConfigRequest{Config: json.RawMessage(`{"checkout_v2": true}`)}
+		response, err := configService.UpdateConfigurationsBySelector("fleet-org", "", "tier=canary", req, false)
+		require.NoError(t, err)
+		assert.Empty(t, response.Errors)
+		assert.Len(t, response.Configs, 2)
+		assert.Contains(t, response.Configs, "api/canary")
+		assert.Contains(t, response.Configs, "web/canary")
+
+		_, err = configService.GetConfiguration("fleet-org", "web", "prod")
+		assert.Error(t, err, "environments the selector does not pick keep their configuration")
+	})
+
+	t.Run("batch update within an application", func(t *testing.T) {
+		req := &models.BatchUpdateConfigRequest{Config: json.RawMessage(`{"checkout_v2": false}`)}
+		response, err := configService.UpdateConfigurationsBySelector("fleet-org", "web", "tier!=canary", req, true)
+		require.NoError(t, err)
+		assert.Len(t, response.Configs, 1)
+		assert.Contains(t, response.Configs, "prod")
+	})
+
+	t.Run("locked environments fail the whole batch", func(t *testing.T) {
+		_, err := configService.LockEnvironment("fleet-org", "api", "canary", &models.LockEnvironmentRequest{})
+		require.NoError(t, err)
+
+		req := &models.BatchUpdateConfigRequest{Config: json.RawMessage(`{"checkout_v2": false}`)}
+		response, err := configService.UpdateConfigurationsBySelector("fleet-org", "", "tier", req, false)
+		require.NoError(t, err)
+		assert.Empty(t, response.Configs)
+		assert.Equal(t, http.StatusLocked, response.Errors["api/canary"].Status)
+		assert.Equal(t, http.StatusFailedDependency, response.Errors["web/canary"].Status)
+	})
+
+	t.Run("selectors that pick nothing", func(t *testing.T) {
+		req := &models.BatchUpdateConfigRequest{Config: json.RawMessage(`{}`)}
+		_, err := configService.UpdateConfigurationsBySelector("fleet-org", "", "tier=staging", req, false)
+		assert.True(t, errors.Is(err, services.ErrNotFound))
+	})
+}
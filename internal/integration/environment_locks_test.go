@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_EnvironmentLocks(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Lock Org", "lock-org")
+	app := suite.CreateTestApplication(t, org.ID, "Lock App", "lock-app", "lock-app-api-key")
+	suite.CreateTestEnvironment(t, app.ID, "Staging", "staging")
+	suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	update := func(envSlug, config string) error {
+		_, err := configService.UpdateConfiguration("lock-org", "lock-app", envSlug, &models.CreateConfigRequest{
+			Config: json.RawMessage(config),
+		}, false)
+		return err
+	}
+	require.NoError(t, update("prod", `{"release": 1}`))
+	require.NoError(t, update("prod", `{"release": 2}`))
+	require.NoError(t, update("staging", `{"release": 3}`))
+
+	alice := "alice"
+	reason := "  incident 42  "
+	env, err := configService.LockEnvironment("lock-org", "lock-app", "prod", &models.LockEnvironmentRequest{Reason: &reason, LockedBy: &alice})
+	require.NoError(t, err)
+	assert.True(t, env.Locked)
+	require.NotNil(t, env.LockReason)
+	assert.Equal(t, "incident 42", *env.LockReason)
+	assert.NotNil(t, env.LockedAt)
+
+	t.Run("the lock is stored", func(t *testing.T) {
+		env, err := configService.GetEnvironment("lock-org", "lock-app", "prod")
+		require.NoError(t, err)
+		assert.True(t, env.Locked)
+		assert.Equal(t, "alice", *env.LockedBy)
+	})
+
+	t.Run("locking twice conflicts", func(t *testing.T) {
+		_, err := configService.LockEnvironment("lock-org", "lock-app", "prod", &models.LockEnvironmentRequest{})
+		assert.ErrorIs(t, err, services.ErrConflict)
+	})
+
+	t.Run("changes are rejected", func(t *testing.T) {
+		err := update("prod", `{"release": 4}`)
+		assert.ErrorIs(t, err, services.ErrLocked)
+		assert.EqualError(t, err, "environment prod is locked by alice: incident 42")
+
+		_, err = configService.RollbackConfiguration("lock-org", "lock-app", "prod", &models.RollbackRequest{ToVersion: 1})
+		assert.ErrorIs(t, err, services.ErrLocked)
+
+		_, err = configService.PromoteConfiguration("lock-org", "lock-app", "prod", &models.PromoteConfigRequest{Source: "staging"}, false)
+		assert.ErrorIs(t, err, services.ErrLocked)
+
+		_, err = configService.SetApplicationDefaults("lock-org", "lock-app", &models.UpdateApplicationDefaultsRequest{Config: json.RawMessage(`{"theme": "dark"}`)})
+		assert.ErrorIs(t, err, services.ErrLocked)
+	})
+
+	t.Run("reads, dry runs and other environments still work", func(t *testing.T) {
+		config, err := configService.GetConfiguration("lock-org", "lock-app", "prod")
+		require.NoError(t, err)
+		assert.Equal(t, 2, config.Version)
+
+		preview, err := configService.UpdateConfiguration("lock-org", "lock-app", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"release": 4}`),
+		}, true)
+		require.NoError(t, err)
+		assert.True(t, preview.DryRun)
+
+		assert.NoError(t, update("staging", `{"release": 4}`))
+	})
+
+	t.Run("unlocking allows changes again", func(t *testing.T) {
+		env, err := configService.UnlockEnvironment("lock-org", "lock-app", "prod")
+		require.NoError(t, err)
+		assert.False(t, env.Locked)
+		assert.Nil(t, env.LockedBy)
+		assert.Nil(t, env.LockReason)
+		assert.Nil(t, env.LockedAt)
+
+		assert.NoError(t, update("prod", `{"release": 4}`))
+
+		_, err = configService.UnlockEnvironment("lock-org", "lock-app", "prod")
+		assert.ErrorIs(t, err, services.ErrConflict)
+	})
+}
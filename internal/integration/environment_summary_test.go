@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_EnvironmentConfigSummary(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Summary Org", Slug: "summary-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("summary-org", &models.CreateApplicationRequest{Name: "Summary App", Slug: "summary-app"})
+	require.NoError(t, err)
+	_, err = configService.CreateEnvironment("summary-org", "summary-app", &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod"})
+	require.NoError(t, err)
+
+	// Environments without configuration have an empty summary
+	env, err := configService.GetEnvironmentWithConfigSummary("summary-org", "summary-app", "prod")
+	require.NoError(t, err)
+	require.NotNil(t, env.ConfigSummary)
+	assert.Nil(t, env.ConfigSummary.ActiveVersion)
+	assert.Equal(t, 0, env.ConfigSummary.VersionCount)
+	assert.Nil(t, env.ConfigSummary.LastChangedAt)
+
+	for i := 1; i <= 3; i++ {
+		_, err = configService.UpdateConfiguration("summary-org", "summary-app", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(fmt.Sprintf(`{"release": %d}`, i)),
+		}, false)
+		require.NoError(t, err)
+	}
+	rollback, err := configService.RollbackConfiguration("summary-org", "summary-app", "prod", &models.RollbackRequest{ToVersion: 2})
+	require.NoError(t, err)
+
+	env, err = configService.GetEnvironmentWithConfigSummary("summary-org", "summary-app", "prod")
+	require.NoError(t, err)
+	require.NotNil(t, env.ConfigSummary.ActiveVersion)
+	assert.Equal(t, 2, *env.ConfigSummary.ActiveVersion)
+	assert.Equal(t, 3, env.ConfigSummary.VersionCount)
+	require.NotNil(t, env.ConfigSummary.LastChangedAt)
+	assert.True(t, rollback.UpdatedAt.Equal(*env.ConfigSummary.LastChangedAt))
+
+	// Tag changes are not configuration changes
+	_, _, err = configService.SetTag("summary-org", "summary-app", "prod", "stable", &models.SetTagRequest{Version: 1})
+	require.NoError(t, err)
+	env, err = configService.GetEnvironmentWithConfigSummary("summary-org", "summary-app", "prod")
+	require.NoError(t, err)
+	assert.True(t, rollback.UpdatedAt.Equal(*env.ConfigSummary.LastChangedAt))
+
+	// The plain environment stays lean
+	env, err = configService.GetEnvironment("summary-org", "summary-app", "prod")
+	require.NoError(t, err)
+	assert.Nil(t, env.ConfigSummary)
+}
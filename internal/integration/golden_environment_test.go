@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_GoldenEnvironment(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Golden Org", "golden-org")
+	app := suite.CreateTestApplication(t, org.ID, "Web", "web", "golden-web-api-key")
+	suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+	suite.CreateTestEnvironment(t, app.ID, "Staging", "staging")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.UpdateConfiguration("golden-org", "web", "prod", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"api_timeout": 30, "database": {"host": "db.internal", "port": 5432}}`),
+	}, false)
+	require.NoError(t, err)
+
+	t.Run("golden environment must exist", func(t *testing.T) {
+		for _, golden := range []string{"qa", "staging"} {
+			_, err := configService.UpdateEnvironment("golden-org", "web", "staging", &models.UpdateEnvironmentRequest{
+				Name:              "Staging",
+				GoldenEnvironment: &golden,
+			})
+			assert.ErrorContains(t, err, "invalid golden environment", golden)
+		}
+	})
+
+	golden := "prod"
+	env, err := configService.UpdateEnvironment("golden-org", "web", "staging", &models.UpdateEnvironmentRequest{
+		Name:              "Staging",
+		GoldenEnvironment: &golden,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, env.GoldenEnvironment)
+	assert.Equal(t, "prod", *env.GoldenEnvironment)
+	assert.False(t, env.GoldenStrict)
+
+	dropped := json.RawMessage(`{"api_timeout": 60, "database": {"host": "db.staging"}, "debug": true}`)
+
+	t.Run("differences are reported", func(t *testing.T) {
+		for _, dryRun := range []bool{true, false} {
+			response, err := configService.UpdateConfiguration("golden-org", "web", "staging", &models.CreateConfigRequest{Config: dropped}, dryRun)
+			require.NoError(t, err)
+			require.NotNil(t, response.Golden)
+			assert.Equal(t, "prod", response.Golden.Environment)
+			assert.Equal(t, 1, response.Golden.Version)
+			assert.False(t, response.Golden.Strict)
+			assert.Equal(t, []string{"database.port"}, response.Golden.MissingKeys)
+			assert.Equal(t, []string{"debug"}, response.Golden.ExtraKeys)
+		}
+	})
+
+	strict := true
+	_, err = configService.UpdateEnvironment("golden-org", "web", "staging", &models.UpdateEnvironmentRequest{
+		Name:         "Staging",
+		GoldenStrict: &strict,
+	})
+	require.NoError(t, err)
+
+	t.Run("strict mode rejects differences", func(t *testing.T) {
+		_, err := configService.UpdateConfiguration("golden-org", "web", "staging", &models.CreateConfigRequest{Config: dropped}, true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "configuration does not match its golden environment: database.port is missing, but golden environment prod has it; debug is not in golden environment prod")
+		assert.True(t, errors.Is(err, services.ErrValidation))
+
+		env, err := configService.GetEnvironment("golden-org", "web", "staging")
+		require.NoError(t, err)
+		result := configService.ValidateConfiguration(env, dropped)
+		assert.False(t, result.Valid)
+		assert.Len(t, result.Errors, 2)
+
+		response, err := configService.UpdateConfiguration("golden-org", "web", "staging", &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"api_timeout": 60, "database": {"host": "db.staging", "port": 5433}}`),
+		}, false)
+		require.NoError(t, err)
+		assert.Empty(t, response.Golden.MissingKeys)
+		assert.Empty(t, response.Golden.ExtraKeys)
+	})
+
+	t.Run("removing the golden environment", func(t *testing.T) {
+		none := ""
+		env, err := configService.UpdateEnvironment("golden-org", "web", "staging", &models.UpdateEnvironmentRequest{
+			Name:              "Staging",
+			GoldenEnvironment: &none,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, env.GoldenEnvironment)
+
+		response, err := configService.UpdateConfiguration("golden-org", "web", "staging", &models.CreateConfigRequest{Config: dropped}, false)
+		require.NoError(t, err)
+		assert.Nil(t, response.Golden)
+	})
+}
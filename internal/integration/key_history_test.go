@@ -0,0 +1,90 @@
+package integration
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_KeyHistory(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+	encryptor, err := services.NewConfigEncryptor([]byte(strings.Repeat("k", 32)))
+	require.NoError(t, err)
+	configService.SetEncryptor(encryptor)
+
+	_, err = configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "History Org", Slug: "history-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("history-org", &models.CreateApplicationRequest{Name: "History App", Slug: "history-app"})
+	require.NoError(t, err)
+	_, err = configService.CreateEnvironment("history-org", "history-app", &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod", SecretKeys: []string{"credentials"}})
+	require.NoError(t, err)
+
+	author := "alice"
+	for _, config := range []string{
+		`{"name": "web"}`,
+		`{"name": "web", "database": {"timeout": 30}, "credentials": {"password": "one"}}`,
+		`{"name": "api", "database": {"timeout": 30}, "credentials": {"password": "one"}}`,
+		`{"name": "api", "database": {"timeout": 60}, "credentials": {"password": "two"}}`,
+		`{"name": "api", "credentials": {"password": "two"}}`,
+	} {
+		_, err = configService.UpdateConfiguration("history-org", "history-app", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(config), CreatedBy: &author,
+		}, false)
+		require.NoError(t, err)
+	}
+
+	versions := func(history *models.KeyHistoryResponse) []int {
+		var numbers []int
+		for _, change := range history.Changes {
+			numbers = append(numbers, change.Version)
+		}
+		return numbers
+	}
+
+	t.Run("changes of a nested key", func(t *testing.T) {
+		history, err := configService.GetKeyHistory("history-org", "history-app", "prod", "database.timeout")
+		require.NoError(t, err)
+
+		assert.Equal(t, "database.timeout", history.Key)
+		assert.Equal(t, []int{2, 4, 5}, versions(history))
+		assert.Equal(t, json.Number("30"), history.Changes[0].Value)
+		assert.Equal(t, json.Number("60"), history.Changes[1].Value)
+		require.NotNil(t, history.Changes[0].ChangedBy)
+		assert.Equal(t, "alice", *history.Changes[0].ChangedBy)
+
+		// The last version removed the key
+		assert.False(t, history.Changes[2].Present)
+		assert.Nil(t, history.Changes[2].Value)
+	})
+
+	t.Run("secret values are compared decrypted and masked", func(t *testing.T) {
+		history, err := configService.GetKeyHistory("history-org", "history-app", "prod", "credentials.password")
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{2, 4}, versions(history))
+		for _, change := range history.Changes {
+			assert.Equal(t, services.MaskedValue, change.Value)
+		}
+	})
+
+	t.Run("key that never existed", func(t *testing.T) {
+		history, err := configService.GetKeyHistory("history-org", "history-app", "prod", "missing")
+		require.NoError(t, err)
+		assert.Empty(t, history.Changes)
+	})
+
+	t.Run("unknown environment", func(t *testing.T) {
+		_, err := configService.GetKeyHistory("history-org", "history-app", "missing", "name")
+		assert.ErrorIs(t, err, services.ErrNotFound)
+	})
+}
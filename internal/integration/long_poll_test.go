@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/handlers"
+	"remote-config-system/internal/middleware"
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/sse"
+	"remote-config-system/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_LongPolling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	sseService := sse.NewSSEService()
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, sseService)
+	sseHandler := handlers.NewSSEHandler(configService, sseService)
+
+	router := gin.New()
+	apiV1 := router.Group("/api")
+	apiV1.Use(middleware.NewAuthMiddleware(configService).APIKeyAuth())
+	apiV1.GET("/config/:env/poll", sseHandler.PollConfigWithAPIKey)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Poll Org", Slug: "poll-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("poll-org", &models.CreateApplicationRequest{Name: "Poll App", Slug: "poll-app", APIKey: "pollapikey123"})
+	require.NoError(t, err)
+	_, err = configService.CreateEnvironment("poll-org", "poll-app", &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod"})
+	require.NoError(t, err)
+	_, err = configService.UpdateConfiguration("poll-org", "poll-app", "prod", &models.CreateConfigRequest{Config: json.RawMessage(`{"theme": "light"}`)}, false)
+	require.NoError(t, err)
+
+	poll := func(query string) (*http.Response, *models.ConfigResponse) {
+		req, err := http.NewRequest("GET", server.URL+"/api/config/prod/poll?"+query, nil)
+		require.NoError(t, err)
+		req.Header.Set("X-API-Key", "pollapikey123")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var config models.ConfigResponse
+		if resp.StatusCode == http.StatusOK {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&config))
+		}
+		return resp, &config
+	}
+
+	t.Run("outdated version returns immediately", func(t *testing.T) {
+		resp, config := poll("since_version=0")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 1, config.Version)
+	})
+
+	t.Run("timeout without a change", func(t *testing.T) {
+		start := time.Now()
+		resp, _ := poll("since_version=1&timeout=1")
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	})
+
+	t.Run("update ends the wait", func(t *testing.T) {
+		go func() {
+			time.Sleep(500 * time.Millisecond)
+			_, err := configService.UpdateConfiguration("poll-org", "poll-app", "prod", &models.CreateConfigRequest{Config: json.RawMessage(`{"theme": "dark"}`)}, false)
+			assert.NoError(t, err)
+		}()
+
+		resp, config := poll("since_version=1&timeout=10")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, 2, config.Version)
+		assert.JSONEq(t, `{"theme": "dark"}`, string(config.Config))
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		resp, _ := poll("since_version=latest")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, _ = poll("since_version=1&timeout=0")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
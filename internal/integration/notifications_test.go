@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/notify"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier hands the messages it is asked to deliver to the test
+type recordingNotifier struct {
+	messages chan *notify.Message
+}
+
+func (n *recordingNotifier) Check(settings *models.NotificationSettings) error {
+	return nil
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, settings *models.NotificationSettings, message *notify.Message) error {
+	n.messages <- message
+	return nil
+}
+
+func (n *recordingNotifier) next(t *testing.T) *notify.Message {
+	select {
+	case message := <-n.messages:
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification was sent")
+		return nil
+	}
+}
+
+func TestIntegration_ChangeNotifications(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Notify Org", "notify-org")
+	app := suite.CreateTestApplication(t, org.ID, "Notify App", "notify-app", "notify-app-api-key")
+	suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+
+	notifier := &recordingNotifier{messages: make(chan *notify.Message, 10)}
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+	configService.SetNotifier(notifier)
+
+	alice := "alice"
+	update := func(config string) {
+		_, err := configService.UpdateConfiguration("notify-org", "notify-app", "prod", &models.CreateConfigRequest{
+			Config:    json.RawMessage(config),
+			CreatedBy: &alice,
+		}, false)
+		require.NoError(t, err)
+	}
+
+	// Nothing is sent before the environment has notification settings
+	update(`{"theme": "light", "pool_size": 10}`)
+
+	_, err := configService.SetNotificationSettings("notify-org", "notify-app", "prod", &models.UpdateNotificationSettingsRequest{
+		SlackWebhookURL: "https://hooks.slack.com/services/T/B/X",
+		Events:          []string{"update"},
+	})
+	require.NoError(t, err)
+
+	settings, err := configService.GetNotificationSettings("notify-org", "notify-app", "prod")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"update"}, settings.Events)
+	assert.Empty(t, notifier.messages)
+
+	t.Run("updates are announced with who made them and a diff", func(t *testing.T) {
+		update(`{"theme": "dark", "pool_size": 10, "timeout": 5}`)
+
+		message := notifier.next(t)
+		assert.Equal(t, "update", message.Action)
+		assert.Equal(t, "alice", message.ChangedBy)
+		require.NotNil(t, message.VersionFrom)
+		assert.Equal(t, 1, *message.VersionFrom)
+		assert.Equal(t, 2, message.VersionTo)
+		require.NotNil(t, message.Diff)
+		assert.Equal(t, 1, message.Diff.Added)
+		assert.Equal(t, 1, message.Diff.Changed)
+	})
+
+	t.Run("actions outside the events are not announced", func(t *testing.T) {
+		_, err := configService.RollbackConfiguration("notify-org", "notify-app", "prod", &models.RollbackRequest{ToVersion: 1})
+		require.NoError(t, err)
+
+		select {
+		case message := <-notifier.messages:
+			t.Fatalf("unexpected notification for %s", message.Action)
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+
+	t.Run("settings can be removed", func(t *testing.T) {
+		require.NoError(t, configService.DeleteNotificationSettings("notify-org", "notify-app", "prod"))
+		_, err := configService.GetNotificationSettings("notify-org", "notify-app", "prod")
+		assert.ErrorIs(t, err, services.ErrNotFound)
+	})
+}
@@ -0,0 +1,79 @@
+package integration
+
+import (
+	"testing"
+
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_OrganizationReadQuota(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	suite.CreateTestOrganization(t, "Quota Org", "quota-org")
+	suite.CreateTestOrganization(t, "Other Org", "other-org")
+
+	config := services.NewConfig()
+	config.OrgMonthlyReadQuota = 3
+	configService := services.NewConfigService(config, suite.Repos, suite.Redis.Client, nil)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, configService.CheckOrganizationReadQuota("quota-org"))
+		require.NoError(t, configService.RecordOrganizationRead("quota-org"))
+	}
+
+	t.Run("reads over the quota are rejected", func(t *testing.T) {
+		err := configService.CheckOrganizationReadQuota("quota-org")
+		require.ErrorIs(t, err, services.ErrQuotaExceeded)
+
+		var quotaErr *services.QuotaExceededError
+		require.ErrorAs(t, err, &quotaErr)
+		assert.Equal(t, int64(3), quotaErr.Quota)
+
+		assert.NoError(t, configService.CheckOrganizationReadQuota("other-org"))
+	})
+
+	t.Run("usage includes reads not stored yet", func(t *testing.T) {
+		quota, err := configService.GetOrganizationQuota("quota-org")
+		require.NoError(t, err)
+		assert.True(t, quota.Tracked)
+		assert.Equal(t, int64(3), quota.Reads)
+		assert.Zero(t, *quota.Remaining)
+		assert.True(t, quota.Exceeded)
+	})
+
+	t.Run("stored reads survive the loss of the counters", func(t *testing.T) {
+		stored, err := configService.FlushOrganizationReads()
+		require.NoError(t, err)
+		assert.Equal(t, 1, stored)
+
+		require.NoError(t, suite.Redis.Client.InvalidatePattern("quota:reads:*"))
+
+		quota, err := configService.GetOrganizationQuota("quota-org")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), quota.Reads)
+
+		// The next read resumes from the stored count
+		require.NoError(t, configService.RecordOrganizationRead("quota-org"))
+		assert.ErrorIs(t, configService.CheckOrganizationReadQuota("quota-org"), services.ErrQuotaExceeded)
+
+		_, err = configService.FlushOrganizationReads()
+		require.NoError(t, err)
+		quota, err = configService.GetOrganizationQuota("quota-org")
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), quota.Reads)
+	})
+
+	t.Run("reads of unknown organizations are not stored", func(t *testing.T) {
+		require.NoError(t, configService.RecordOrganizationRead("missing-org"))
+		_, err := configService.FlushOrganizationReads()
+		require.NoError(t, err)
+
+		_, err = configService.GetOrganizationQuota("missing-org")
+		assert.ErrorIs(t, err, services.ErrNotFound)
+	})
+}
@@ -0,0 +1,49 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_OrganizationWithApplications(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Tree Org", Slug: "tree-org"})
+	require.NoError(t, err)
+
+	// Organizations without applications have an empty list
+	org, err := configService.GetOrganizationWithApplications("tree-org")
+	require.NoError(t, err)
+	assert.Equal(t, "tree-org", org.Slug)
+	assert.NotNil(t, org.Applications)
+	assert.Empty(t, org.Applications)
+
+	for _, req := range []models.CreateApplicationRequest{
+		{Name: "Web", Slug: "web"},
+		{Name: "API", Slug: "api"},
+	} {
+		_, err = configService.CreateApplication("tree-org", &req)
+		require.NoError(t, err)
+	}
+
+	org, err = configService.GetOrganizationWithApplications("tree-org")
+	require.NoError(t, err)
+	require.Len(t, org.Applications, 2)
+	assert.Equal(t, "api", org.Applications[0].Slug)
+	assert.Equal(t, "web", org.Applications[1].Slug)
+	assert.Equal(t, org.ID, org.Applications[0].OrgID)
+	assert.NotEmpty(t, org.Applications[0].APIKeyPrefix)
+
+	_, err = configService.GetOrganizationWithApplications("missing-org")
+	assert.True(t, errors.Is(err, services.ErrNotFound))
+}
@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_OrganizationTree(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Tree Org", "tree-org")
+	web := suite.CreateTestApplication(t, org.ID, "Web", "web", "tree-web-api-key")
+	suite.CreateTestApplication(t, org.ID, "API", "api", "tree-api-api-key")
+	suite.CreateTestEnvironment(t, web.ID, "Production", "prod")
+	suite.CreateTestEnvironment(t, web.ID, "Development", "dev")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	for _, config := range []string{`{"replicas": 2}`, `{"replicas": 3}`} {
+		_, err := configService.UpdateConfiguration("tree-org", "web", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(config),
+		}, false)
+		require.NoError(t, err)
+	}
+
+	t.Run("full tree", func(t *testing.T) {
+		tree, err := configService.GetOrganizationTree("tree-org", services.MaxTreeDepth)
+		require.NoError(t, err)
+		assert.Equal(t, "tree-org", tree.Slug)
+		require.Len(t, tree.Applications, 2)
+
+		api, web := tree.Applications[0], tree.Applications[1]
+		assert.Equal(t, "api", api.Slug)
+		assert.NotNil(t, api.Environments)
+		assert.Empty(t, api.Environments)
+
+		assert.Equal(t, "web", web.Slug)
+		require.Len(t, web.Environments, 2)
+		assert.Equal(t, "dev", web.Environments[0].Slug)
+		assert.Nil(t, web.Environments[0].ActiveVersion)
+		assert.Equal(t, "prod", web.Environments[1].Slug)
+		require.NotNil(t, web.Environments[1].ActiveVersion)
+		assert.Equal(t, 2, *web.Environments[1].ActiveVersion)
+	})
+
+	t.Run("depth limits the levels", func(t *testing.T) {
+		tree, err := configService.GetOrganizationTree("tree-org", 1)
+		require.NoError(t, err)
+		require.Len(t, tree.Applications, 2)
+		assert.Nil(t, tree.Applications[1].Environments)
+
+		tree, err = configService.GetOrganizationTree("tree-org", 0)
+		require.NoError(t, err)
+		assert.Equal(t, "Tree Org", tree.Name)
+		assert.Nil(t, tree.Applications)
+	})
+
+	t.Run("unknown organization", func(t *testing.T) {
+		_, err := configService.GetOrganizationTree("missing-org", services.MaxTreeDepth)
+		assert.True(t, errors.Is(err, services.ErrNotFound))
+	})
+}
@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_PromoteConfiguration(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Promote Org", Slug: "promote-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("promote-org", &models.CreateApplicationRequest{Name: "Promote App", Slug: "promote-app"})
+	require.NoError(t, err)
+	for _, slug := range []string{"staging", "prod"} {
+		_, err = configService.CreateEnvironment("promote-org", "promote-app", &models.CreateEnvironmentRequest{Name: slug, Slug: slug})
+		require.NoError(t, err)
+	}
+
+	staging, err := configService.UpdateConfiguration("promote-org", "promote-app", "staging", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"feature_flags": {"checkout": true}, "limits": {"rps": 50}, "db_host": "staging-db"}`),
+	}, false)
+	require.NoError(t, err)
+	_, err = configService.UpdateConfiguration("promote-org", "promote-app", "prod", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"feature_flags": {"checkout": false}, "db_host": "prod-db"}`),
+	}, false)
+	require.NoError(t, err)
+
+	t.Run("whitelisted keys are copied and the rest is kept", func(t *testing.T) {
+		promotedBy := "release-bot"
+		config, err := configService.PromoteConfiguration("promote-org", "promote-app", "prod", &models.PromoteConfigRequest{
+			Source:    "staging",
+			Keys:      []string{"feature_flags", "limits"},
+			CreatedBy: &promotedBy,
+		}, false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, config.Version)
+		assert.JSONEq(t, `{"feature_flags": {"checkout": true}, "limits": {"rps": 50}, "db_host": "prod-db"}`, string(config.Config))
+
+		env, err := suite.Repos.Environments.GetBySlug("promote-org", "promote-app", "prod")
+		require.NoError(t, err)
+		changes, _, err := suite.Repos.ConfigChanges.ListByEnvironment(env.ID, models.DefaultPaginationParams())
+		require.NoError(t, err)
+		require.NotEmpty(t, changes)
+
+		change := changes[0]
+		assert.Equal(t, "promote", change.Action)
+		assert.Equal(t, 2, change.VersionTo)
+		require.NotNil(t, change.SourceEnv)
+		assert.Equal(t, "staging", *change.SourceEnv)
+		require.NotNil(t, change.SourceVersion)
+		assert.Equal(t, staging.Version, *change.SourceVersion)
+		require.NotNil(t, change.CreatedBy)
+		assert.Equal(t, "release-bot", *change.CreatedBy)
+	})
+
+	t.Run("without keys the whole configuration is copied", func(t *testing.T) {
+		config, err := configService.PromoteConfiguration("promote-org", "promote-app", "prod", &models.PromoteConfigRequest{Source: "staging"}, false)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"feature_flags": {"checkout": true}, "limits": {"rps": 50}, "db_host": "staging-db"}`, string(config.Config))
+
+		active, err := configService.GetConfiguration("promote-org", "promote-app", "prod")
+		require.NoError(t, err)
+		assert.Equal(t, config.Version, active.Version)
+	})
+
+	t.Run("dry run saves nothing", func(t *testing.T) {
+		config, err := configService.PromoteConfiguration("promote-org", "promote-app", "prod", &models.PromoteConfigRequest{Source: "staging"}, true)
+		require.NoError(t, err)
+		assert.True(t, config.DryRun)
+
+		active, err := configService.GetConfiguration("promote-org", "promote-app", "prod")
+		require.NoError(t, err)
+		assert.Equal(t, config.Version-1, active.Version)
+	})
+
+	t.Run("unknown source environment", func(t *testing.T) {
+		_, err := configService.PromoteConfiguration("promote-org", "promote-app", "prod", &models.PromoteConfigRequest{Source: "qa"}, false)
+		assert.True(t, errors.Is(err, services.ErrNotFound))
+	})
+
+	t.Run("key missing from the source", func(t *testing.T) {
+		_, err := configService.PromoteConfiguration("promote-org", "promote-app", "prod", &models.PromoteConfigRequest{Source: "staging", Keys: []string{"timeouts"}}, false)
+		assert.ErrorContains(t, err, "invalid promotion: key 'timeouts' is not in the source configuration")
+	})
+}
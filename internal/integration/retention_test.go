@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_PruneHistory(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Retention Org", Slug: "retention-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("retention-org", &models.CreateApplicationRequest{Name: "Retention App", Slug: "retention-app"})
+	require.NoError(t, err)
+
+	keep := 2
+	_, err = configService.CreateEnvironment("retention-org", "retention-app", &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod", RetentionVersions: &keep})
+	require.NoError(t, err)
+	_, err = configService.CreateEnvironment("retention-org", "retention-app", &models.CreateEnvironmentRequest{Name: "Staging", Slug: "staging"})
+	require.NoError(t, err)
+
+	for _, envSlug := range []string{"prod", "staging"} {
+		for i := 1; i <= 5; i++ {
+			_, err = configService.UpdateConfiguration("retention-org", "retention-app", envSlug, &models.CreateConfigRequest{
+				Config: json.RawMessage(fmt.Sprintf(`{"release": %d}`, i)),
+			}, false)
+			require.NoError(t, err)
+		}
+	}
+
+	// Tagged versions are kept however old they are
+	_, _, err = configService.SetTag("retention-org", "retention-app", "prod", "v1", &models.SetTagRequest{Version: 1})
+	require.NoError(t, err)
+
+	result, err := configService.PruneHistory("retention-org", "retention-app", "prod")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.RetentionVersions)
+	assert.Equal(t, 0, result.RetentionDays)
+	assert.Equal(t, 2, result.Pruned)
+
+	env, err := suite.Repos.Environments.GetBySlug("retention-org", "retention-app", "prod")
+	require.NoError(t, err)
+	for version, kept := range map[int]bool{1: true, 2: false, 3: false, 4: true, 5: true} {
+		_, err := suite.Repos.ConfigVersions.GetByVersion(env.ID, version)
+		assert.Equal(t, kept, err == nil, "version %d", version)
+	}
+
+	// Pruning again finds nothing left to delete
+	result, err = configService.PruneHistory("retention-org", "retention-app", "prod")
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Pruned)
+
+	// Environments without a policy keep their whole history
+	result, err = configService.PruneHistory("retention-org", "retention-app", "staging")
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Pruned)
+
+	total, err := configService.PruneAllHistory()
+	require.NoError(t, err)
+	assert.Equal(t, 0, total)
+
+	// Removing the override restores the global policy
+	reset := 0
+	updated, err := configService.UpdateEnvironment("retention-org", "retention-app", "prod", &models.UpdateEnvironmentRequest{Name: "Production", RetentionVersions: &reset})
+	require.NoError(t, err)
+	assert.Nil(t, updated.RetentionVersions)
+
+	_, err = configService.PruneHistory("retention-org", "retention-app", "missing")
+	assert.ErrorIs(t, err, services.ErrNotFound)
+}
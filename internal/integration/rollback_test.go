@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"remote-config-system/internal/db"
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_RollbackConfiguration(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Rollback Org", Slug: "rollback-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("rollback-org", &models.CreateApplicationRequest{Name: "Rollback App", Slug: "rollback-app"})
+	require.NoError(t, err)
+	_, err = configService.CreateEnvironment("rollback-org", "rollback-app", &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod"})
+	require.NoError(t, err)
+
+	for i := 1; i <= 2; i++ {
+		_, err = configService.UpdateConfiguration("rollback-org", "rollback-app", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(fmt.Sprintf(`{"release": %d}`, i)),
+		}, false)
+		require.NoError(t, err)
+	}
+
+	env, err := suite.Repos.Environments.GetBySlug("rollback-org", "rollback-app", "prod")
+	require.NoError(t, err)
+	changeCount := func() int {
+		_, total, err := suite.Repos.ConfigChanges.ListByEnvironment(env.ID, models.DefaultPaginationParams())
+		require.NoError(t, err)
+		return total
+	}
+
+	t.Run("rolling back to the active version is rejected", func(t *testing.T) {
+		before := changeCount()
+
+		_, err := configService.RollbackConfiguration("rollback-org", "rollback-app", "prod", &models.RollbackRequest{ToVersion: 2})
+		assert.EqualError(t, err, "invalid rollback: version 2 is already active")
+
+		// Nothing is logged for the rejected rollback
+		assert.Equal(t, before, changeCount())
+	})
+
+	t.Run("a concurrent rollback to the same version is detected by its kind", func(t *testing.T) {
+		// The service checks the active version first; the repository catches the race
+		err := suite.Repos.ConfigVersions.Rollback(&models.ConfigChange{EnvID: env.ID, VersionTo: 2, Action: "rollback"})
+		assert.True(t, errors.Is(err, db.ErrAlreadyActive), "error: %v", err)
+	})
+
+	t.Run("the response is dated with the activation", func(t *testing.T) {
+		config, err := configService.RollbackConfiguration("rollback-org", "rollback-app", "prod", &models.RollbackRequest{ToVersion: 1})
+		require.NoError(t, err)
+		assert.Equal(t, 1, config.Version)
+		assert.JSONEq(t, `{"release": 1}`, string(config.Config))
+
+		changes, _, err := suite.Repos.ConfigChanges.ListByEnvironment(env.ID, models.DefaultPaginationParams())
+		require.NoError(t, err)
+		require.NotEmpty(t, changes)
+
+		change := changes[0]
+		assert.Equal(t, "rollback", change.Action)
+		require.NotNil(t, change.VersionFrom)
+		assert.Equal(t, 2, *change.VersionFrom)
+		assert.Equal(t, 1, change.VersionTo)
+		assert.True(t, config.UpdatedAt.Equal(change.CreatedAt))
+
+		active, err := suite.Repos.ConfigVersions.GetActiveByEnvironment(env.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, active.Version)
+	})
+
+	t.Run("unknown target version", func(t *testing.T) {
+		_, err := configService.RollbackConfiguration("rollback-org", "rollback-app", "prod", &models.RollbackRequest{ToVersion: 9})
+		assert.ErrorIs(t, err, services.ErrNotFound)
+	})
+
+	t.Run("rolling back to a tag activates its version", func(t *testing.T) {
+		_, _, err := configService.SetTag("rollback-org", "rollback-app", "prod", "last-known-good", &models.SetTagRequest{Version: 2})
+		require.NoError(t, err)
+
+		config, err := configService.RollbackConfiguration("rollback-org", "rollback-app", "prod", &models.RollbackRequest{ToTag: "last-known-good"})
+		require.NoError(t, err)
+		assert.Equal(t, 2, config.Version)
+		assert.Equal(t, "last-known-good", config.Tag)
+		assert.JSONEq(t, `{"release": 2}`, string(config.Config))
+	})
+
+	t.Run("unknown target tag", func(t *testing.T) {
+		_, err := configService.RollbackConfiguration("rollback-org", "rollback-app", "prod", &models.RollbackRequest{ToTag: "missing"})
+		assert.ErrorIs(t, err, services.ErrNotFound)
+	})
+}
@@ -0,0 +1,53 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_ConfigResponsesCarryTheVersionID(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Schema Org", "schema-org")
+	app := suite.CreateTestApplication(t, org.ID, "Schema App", "schema-app", "schema-app-api-key")
+	env := suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	updated, err := configService.UpdateConfiguration("schema-org", "schema-app", "prod", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"release": 1}`),
+	}, false)
+	require.NoError(t, err)
+
+	active, err := suite.Repos.ConfigVersions.GetActiveByEnvironment(env.ID)
+	require.NoError(t, err)
+	require.NotNil(t, updated.VersionID)
+	assert.Equal(t, active.ID, *updated.VersionID)
+
+	keyApp, err := configService.ValidateAPIKey("schema-app-api-key")
+	require.NoError(t, err)
+
+	// The first read comes from the database, the second from the cache
+	for i := 0; i < 2; i++ {
+		config, err := configService.GetConfigurationByAPIKey(keyApp, "prod", "")
+		require.NoError(t, err)
+		require.NotNil(t, config.VersionID)
+		assert.Equal(t, active.ID, *config.VersionID)
+	}
+
+	// Warmed cache entries carry it too
+	_, err = configService.WarmCacheScope("schema-org", "schema-app")
+	require.NoError(t, err)
+	config, err := configService.GetConfiguration("schema-org", "schema-app", "prod")
+	require.NoError(t, err)
+	require.NotNil(t, config.VersionID)
+	assert.Equal(t, active.ID, *config.VersionID)
+}
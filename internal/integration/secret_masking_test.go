@@ -0,0 +1,84 @@
+package integration
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"remote-config-system/internal/handlers"
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/sse"
+	"remote-config-system/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_SecretMasking(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	sseService := sse.NewSSEService()
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, sseService)
+	encryptor, err := services.NewConfigEncryptor([]byte(strings.Repeat("k", 32)))
+	require.NoError(t, err)
+	configService.SetEncryptor(encryptor)
+
+	router := gin.New()
+	router.GET("/config/:org/:app/:env", handlers.NewConfigHandler(configService).GetConfig)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	_, err = configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Secret Org", Slug: "secret-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("secret-org", &models.CreateApplicationRequest{Name: "Web", Slug: "web", APIKey: "secret-web-api-key"})
+	require.NoError(t, err)
+	_, err = configService.CreateEnvironment("secret-org", "web", &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod", SecretKeys: []string{"db_password"}})
+	require.NoError(t, err)
+	_, err = configService.UpdateConfiguration("secret-org", "web", "prod", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"db_password": "hunter2", "timeout": 30}`),
+	}, false)
+	require.NoError(t, err)
+
+	t.Run("the public endpoint masks secrets", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/config/secret-org/web/prod")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotContains(t, string(body), "hunter2")
+		assert.Contains(t, string(body), `"db_password":"<encrypted>"`)
+	})
+
+	t.Run("API key reads decrypt secrets", func(t *testing.T) {
+		app, err := configService.ValidateAPIKey("secret-web-api-key")
+		require.NoError(t, err)
+		config, err := configService.GetConfigurationByAPIKey(app, "prod", "")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"db_password": "hunter2", "timeout": 30}`, string(config.Config))
+	})
+
+	t.Run("broadcasts and their replay mask secrets", func(t *testing.T) {
+		_, err := configService.UpdateConfiguration("secret-org", "web", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"db_password": "hunter3", "timeout": 60}`),
+		}, false)
+		require.NoError(t, err)
+		_, err = configService.RollbackConfiguration("secret-org", "web", "prod", &models.RollbackRequest{ToVersion: 1})
+		require.NoError(t, err)
+
+		events := sseService.RecentEvents("secret-org", "web", "prod")
+		require.Len(t, events, 3)
+		for _, event := range events {
+			assert.NotContains(t, string(event.Config), "hunter")
+			assert.Contains(t, string(event.Config), "<encrypted>")
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_SlugValidation(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	org, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Slug Org", Slug: "slug-org"})
+	require.NoError(t, err)
+	app, err := configService.CreateApplication("slug-org", &models.CreateApplicationRequest{Name: "Slug App", Slug: "slug-app"})
+	require.NoError(t, err)
+
+	for _, slug := range []string{"foo/bar", "", strings.Repeat("a", 51), "Prod"} {
+		_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Invalid", Slug: slug})
+		assert.ErrorContains(t, err, "invalid slug", slug)
+
+		_, err = configService.CreateApplication("slug-org", &models.CreateApplicationRequest{Name: "Invalid", Slug: slug})
+		assert.ErrorContains(t, err, "invalid slug", slug)
+
+		_, err = configService.CreateEnvironment("slug-org", "slug-app", &models.CreateEnvironmentRequest{Name: "Invalid", Slug: slug})
+		assert.ErrorContains(t, err, "invalid slug", slug)
+	}
+
+	_, appCount, err := suite.Repos.Applications.ListByOrganization(org.ID, models.DefaultPaginationParams())
+	require.NoError(t, err)
+	assert.Equal(t, 1, appCount)
+
+	_, envCount, err := suite.Repos.Environments.ListByApplication(app.ID, models.DefaultPaginationParams())
+	require.NoError(t, err)
+	assert.Equal(t, 0, envCount)
+}
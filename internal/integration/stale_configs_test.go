@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_StaleConfigs(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "Stale Org", Slug: "stale-org"})
+	require.NoError(t, err)
+	app, err := configService.CreateApplication("stale-org", &models.CreateApplicationRequest{Name: "Stale App", Slug: "stale-app"})
+	require.NoError(t, err)
+
+	for _, envSlug := range []string{"prod", "staging", "dev", "empty"} {
+		_, err = configService.CreateEnvironment("stale-org", "stale-app", &models.CreateEnvironmentRequest{Name: envSlug, Slug: envSlug})
+		require.NoError(t, err)
+		if envSlug == "empty" {
+			continue
+		}
+		_, err = configService.UpdateConfiguration("stale-org", "stale-app", envSlug, &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"feature": true}`),
+		}, false)
+		require.NoError(t, err)
+	}
+
+	// prod and staging were last changed half a year ago
+	for _, query := range []string{
+		`UPDATE environments SET created_at = NOW() - INTERVAL '200 days' WHERE slug IN ('prod', 'staging', 'empty')`,
+		`UPDATE config_versions SET created_at = NOW() - INTERVAL '180 days' WHERE env_id IN (SELECT id FROM environments WHERE slug IN ('prod', 'staging'))`,
+		`UPDATE config_changes SET created_at = NOW() - INTERVAL '180 days' WHERE env_id IN (SELECT id FROM environments WHERE slug IN ('prod', 'staging'))`,
+	} {
+		_, err = suite.DB.Exec(query)
+		require.NoError(t, err)
+	}
+
+	// staging is still read, through a batch fetch
+	require.NoError(t, suite.Redis.Client.RecordUsage(app.ID.String(), "", time.Now()))
+	_, err = configService.FlushUsage()
+	require.NoError(t, err)
+
+	report, err := configService.CheckStaleConfigs()
+	require.NoError(t, err)
+
+	assert.Equal(t, services.DefaultStaleDays, report.ThresholdDays)
+	assert.True(t, report.UsageTracked)
+	require.Len(t, report.Environments, 2)
+
+	assert.Equal(t, "prod", report.Environments[0].Environment)
+	assert.Equal(t, []string{models.StaleReasonUnchanged, models.StaleReasonUnread}, report.Environments[0].Reasons)
+	assert.Nil(t, report.Environments[0].LastReadAt)
+
+	assert.Equal(t, "staging", report.Environments[1].Environment)
+	assert.Equal(t, []string{models.StaleReasonUnchanged}, report.Environments[1].Reasons)
+	require.NotNil(t, report.Environments[1].LastReadAt)
+
+	t.Run("the latest report is kept", func(t *testing.T) {
+		latest, err := configService.StaleConfigReport()
+		require.NoError(t, err)
+		assert.Equal(t, report.CheckedAt, latest.CheckedAt)
+	})
+
+	t.Run("a new change clears the flag", func(t *testing.T) {
+		_, err := configService.UpdateConfiguration("stale-org", "stale-app", "staging", &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"feature": false}`),
+		}, false)
+		require.NoError(t, err)
+
+		report, err := configService.CheckStaleConfigs()
+		require.NoError(t, err)
+		require.Len(t, report.Environments, 1)
+		assert.Equal(t, "prod", report.Environments[0].Environment)
+	})
+}
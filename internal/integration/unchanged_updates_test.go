@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_UnchangedUpdates(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	org := suite.CreateTestOrganization(t, "Noop Org", "noop-org")
+	app := suite.CreateTestApplication(t, org.ID, "Web", "web", "noop-web-api-key")
+	env := suite.CreateTestEnvironment(t, app.ID, "Production", "prod")
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+
+	first, err := configService.UpdateConfiguration("noop-org", "web", "prod", &models.CreateConfigRequest{
+		Config: json.RawMessage(`{"timeout": 30, "database": {"host": "db", "port": 5432}}`),
+	}, false)
+	require.NoError(t, err)
+	assert.False(t, first.Unchanged)
+
+	changeCount := func() int {
+		_, total, err := suite.Repos.ConfigChanges.ListByEnvironment(env.ID, models.DefaultPaginationParams())
+		require.NoError(t, err)
+		return total
+	}
+	require.Equal(t, 1, changeCount())
+
+	t.Run("the same configuration in another key order keeps the active version", func(t *testing.T) {
+		response, err := configService.UpdateConfiguration("noop-org", "web", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"database": {"port": 5432, "host": "db"}, "timeout": 30}`),
+		}, false)
+		require.NoError(t, err)
+		assert.True(t, response.Unchanged)
+		assert.Equal(t, first.Version, response.Version)
+		assert.Equal(t, first.VersionID, response.VersionID)
+		assert.Equal(t, 1, changeCount())
+	})
+
+	t.Run("a version can be asked for anyway", func(t *testing.T) {
+		response, err := configService.UpdateConfiguration("noop-org", "web", "prod", &models.CreateConfigRequest{
+			Config:         json.RawMessage(`{"timeout": 30, "database": {"host": "db", "port": 5432}}`),
+			AllowUnchanged: true,
+		}, false)
+		require.NoError(t, err)
+		assert.False(t, response.Unchanged)
+		assert.Equal(t, first.Version+1, response.Version)
+		assert.Equal(t, 2, changeCount())
+	})
+
+	t.Run("a changed configuration creates a version", func(t *testing.T) {
+		response, err := configService.UpdateConfiguration("noop-org", "web", "prod", &models.CreateConfigRequest{
+			Config: json.RawMessage(`{"timeout": 30, "database": {"host": "db", "port": 6432}}`),
+		}, false)
+		require.NoError(t, err)
+		assert.False(t, response.Unchanged)
+		assert.Equal(t, first.Version+2, response.Version)
+	})
+}
@@ -0,0 +1,241 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/cache"
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listActiveByTraversal loads active configurations the way cache warming used to, with
+// a query per organization, application and environment
+func listActiveByTraversal(t *testing.T, suite *testutil.TestSuite) []models.ActiveConfig {
+	params := models.PaginationParams{Page: 1, PageSize: 1000}
+
+	orgs, _, err := suite.Repos.Organizations.List(params)
+	require.NoError(t, err)
+
+	configs := []models.ActiveConfig{}
+	for _, org := range orgs {
+		apps, _, err := suite.Repos.Applications.ListByOrganization(org.ID, params)
+		require.NoError(t, err)
+
+		for _, app := range apps {
+			envs, _, err := suite.Repos.Environments.ListByApplication(app.ID, params)
+			require.NoError(t, err)
+
+			for _, env := range envs {
+				configVersion, err := suite.Repos.ConfigVersions.GetActiveByEnvironment(env.ID)
+				if err != nil {
+					continue
+				}
+
+				_, rolloutErr := suite.Repos.ConfigRollouts.GetByEnvironment(env.ID)
+				configs = append(configs, models.ActiveConfig{
+					Organization: org.Slug,
+					Application:  app.Slug,
+					Environment:  env.Slug,
+					AppID:        app.ID,
+					VersionID:    configVersion.ID,
+					Version:      configVersion.Version,
+					ConfigJSON:   configVersion.ConfigJSON,
+					CreatedAt:    configVersion.CreatedAt,
+					HasRollout:   rolloutErr == nil,
+				})
+			}
+		}
+	}
+
+	return configs
+}
+
+func TestIntegration_WarmCache(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	createVersion := func(env models.Environment, version int, active bool) {
+		configJSON, _ := json.Marshal(map[string]interface{}{"version": version})
+		err := suite.Repos.ConfigVersions.Create(&models.ConfigVersion{
+			EnvID:      env.ID,
+			Version:    version,
+			ConfigJSON: configJSON,
+			IsActive:   active,
+			CreatedBy:  stringPtr("admin"),
+		})
+		require.NoError(t, err)
+	}
+
+	// Two organizations with two applications of three environments each; the last
+	// environment of every application has no active configuration
+	var rolloutEnv *models.Environment
+	appIDs := make(map[string]string)
+	for o := 1; o <= 2; o++ {
+		org := suite.CreateTestOrganization(t, fmt.Sprintf("Warm Org %d", o), fmt.Sprintf("warm-org-%d", o))
+		for a := 1; a <= 2; a++ {
+			app := suite.CreateTestApplication(t, org.ID, fmt.Sprintf("Warm App %d", a), fmt.Sprintf("warm-app-%d", a), fmt.Sprintf("warm-key-%d-%d", o, a))
+			appIDs[app.APIKey] = app.ID.String()
+			for e := 1; e <= 3; e++ {
+				env := suite.CreateTestEnvironment(t, app.ID, fmt.Sprintf("Env %d", e), fmt.Sprintf("env-%d", e))
+				if e == 3 {
+					createVersion(*env, 1, false)
+					continue
+				}
+				createVersion(*env, 1, false)
+				createVersion(*env, 2, true)
+				rolloutEnv = env
+			}
+		}
+	}
+
+	// One environment has a rollout in progress
+	createVersion(*rolloutEnv, 3, false)
+	require.NoError(t, suite.Repos.ConfigRollouts.Upsert(&models.ConfigRollout{
+		EnvID:         rolloutEnv.ID,
+		Version:       3,
+		StableVersion: 2,
+		Percentage:    10,
+	}))
+
+	t.Run("single query matches the per-environment traversal", func(t *testing.T) {
+		active, err := suite.Repos.ConfigVersions.ListActive("", "")
+		require.NoError(t, err)
+
+		expected := listActiveByTraversal(t, suite)
+		assert.ElementsMatch(t, expected, active)
+		assert.GreaterOrEqual(t, len(active), 8)
+	})
+
+	t.Run("scoped to an organization or application", func(t *testing.T) {
+		active, err := suite.Repos.ConfigVersions.ListActive("warm-org-1", "")
+		require.NoError(t, err)
+		assert.Len(t, active, 4)
+
+		active, err = suite.Repos.ConfigVersions.ListActive("warm-org-1", "warm-app-2")
+		require.NoError(t, err)
+		require.Len(t, active, 2)
+		assert.Equal(t, "warm-app-2", active[0].Application)
+	})
+
+	t.Run("cache is warmed with every active configuration", func(t *testing.T) {
+		configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+		require.NoError(t, configService.WarmCache())
+
+		cachedData, err := suite.Redis.Client.GetConfig(cache.GenerateConfigKey("warm-org-2", "warm-app-1", "env-1"))
+		require.NoError(t, err)
+		var response models.ConfigResponse
+		require.NoError(t, json.Unmarshal(cachedData, &response))
+		assert.Equal(t, 2, response.Version)
+
+		cachedData, err = suite.Redis.Client.GetConfig(cache.GenerateConfigKey("warm-org-2", "warm-app-1", "env-3"))
+		require.NoError(t, err)
+		assert.Nil(t, cachedData)
+
+		// API key entries are warmed too, except where a rollout is in progress
+		cachedData, err = suite.Redis.Client.GetConfig(cache.GenerateAPIKeyConfigKey(appIDs["warm-key-1-1"], "env-2"))
+		require.NoError(t, err)
+		assert.NotNil(t, cachedData)
+
+		cachedData, err = suite.Redis.Client.GetConfig(cache.GenerateAPIKeyConfigKey(appIDs["warm-key-2-2"], "env-2"))
+		require.NoError(t, err)
+		assert.Nil(t, cachedData)
+
+		app, err := configService.ValidateAPIKey("warm-key-1-1")
+		require.NoError(t, err)
+		config, err := configService.GetConfigurationByAPIKey(app, "env-2", "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, config.Version)
+	})
+
+	t.Run("cache is warmed for one application", func(t *testing.T) {
+		configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+		require.NoError(t, configService.ClearCache())
+
+		warmed, err := configService.WarmCacheScope("warm-org-1", "warm-app-1")
+		require.NoError(t, err)
+		assert.Equal(t, 2, warmed)
+
+		cachedData, err := suite.Redis.Client.GetConfig(cache.GenerateConfigKey("warm-org-1", "warm-app-1", "env-2"))
+		require.NoError(t, err)
+		assert.NotNil(t, cachedData)
+
+		cachedData, err = suite.Redis.Client.GetConfig(cache.GenerateConfigKey("warm-org-1", "warm-app-2", "env-2"))
+		require.NoError(t, err)
+		assert.Nil(t, cachedData)
+
+		_, err = configService.WarmCacheScope("warm-org-1", "missing-app")
+		assert.ErrorContains(t, err, "application not found")
+	})
+
+	t.Run("startup warming reports its progress", func(t *testing.T) {
+		configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+		require.NoError(t, configService.ClearCache())
+		assert.Equal(t, models.CacheWarmStatusPending, configService.CacheWarmStatus().Status)
+
+		active, err := suite.Repos.ConfigVersions.ListActive("", "")
+		require.NoError(t, err)
+
+		done := configService.StartCacheWarming()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("cache warming did not finish")
+		}
+
+		status := configService.CacheWarmStatus()
+		assert.Equal(t, models.CacheWarmStatusCompleted, status.Status)
+		assert.Equal(t, len(active), status.Configurations)
+		require.NotNil(t, status.StartedAt)
+		require.NotNil(t, status.CompletedAt)
+		assert.False(t, status.CompletedAt.Before(*status.StartedAt))
+		assert.Equal(t, models.CacheWarmStatusCompleted, configService.HealthCheck()["cache_warming"])
+
+		// Warming runs once
+		assert.Equal(t, done, configService.StartCacheWarming())
+	})
+}
+
+func TestIntegration_WarmCacheBeyondOnePage(t *testing.T) {
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	// More environments than fit on a page of any list endpoint
+	const envCount = 120
+	org := suite.CreateTestOrganization(t, "Large Org", "large-org")
+	app := suite.CreateTestApplication(t, org.ID, "Large App", "large-app", "large-api-key")
+	for i := 1; i <= envCount; i++ {
+		env := suite.CreateTestEnvironment(t, app.ID, fmt.Sprintf("Env %d", i), fmt.Sprintf("env-%03d", i))
+		err := suite.Repos.ConfigVersions.Create(&models.ConfigVersion{
+			EnvID:      env.ID,
+			Version:    1,
+			ConfigJSON: json.RawMessage(fmt.Sprintf(`{"env": %d}`, i)),
+			IsActive:   true,
+			CreatedBy:  stringPtr("admin"),
+		})
+		require.NoError(t, err)
+	}
+
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, nil)
+	warmed, err := configService.WarmCacheScope("large-org", "")
+	require.NoError(t, err)
+	assert.Equal(t, envCount, warmed)
+
+	for i := 1; i <= envCount; i++ {
+		envSlug := fmt.Sprintf("env-%03d", i)
+
+		cachedData, err := suite.Redis.Client.GetConfig(cache.GenerateConfigKey("large-org", "large-app", envSlug))
+		require.NoError(t, err)
+		require.NotNil(t, cachedData, "environment %s was not warmed", envSlug)
+
+		cachedData, err = suite.Redis.Client.GetConfig(cache.GenerateAPIKeyConfigKey(app.ID.String(), envSlug))
+		require.NoError(t, err)
+		require.NotNil(t, cachedData, "API key entry of environment %s was not warmed", envSlug)
+	}
+}
@@ -0,0 +1,167 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/handlers"
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+	"remote-config-system/internal/sse"
+	"remote-config-system/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestIntegration_WebSocketStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	suite := testutil.SetupTestSuite(t)
+	defer suite.Cleanup(t)
+
+	sseService := sse.NewSSEService()
+	configService := services.NewConfigService(services.NewConfig(), suite.Repos, suite.Redis.Client, sseService)
+	sseHandler := handlers.NewSSEHandler(configService, sseService)
+
+	router := gin.New()
+	router.GET("/ws/:org/:app/:env", sseHandler.StreamConfigUpdatesWebSocket)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	_, err := configService.CreateOrganization(&models.CreateOrganizationRequest{Name: "WS Org", Slug: "ws-org"})
+	require.NoError(t, err)
+	_, err = configService.CreateApplication("ws-org", &models.CreateApplicationRequest{Name: "WS App", Slug: "ws-app"})
+	require.NoError(t, err)
+	_, err = configService.CreateEnvironment("ws-org", "ws-app", &models.CreateEnvironmentRequest{Name: "Production", Slug: "prod"})
+	require.NoError(t, err)
+	_, err = configService.UpdateConfiguration("ws-org", "ws-app", "prod", &models.CreateConfigRequest{Config: json.RawMessage(`{"theme": "light"}`)}, false)
+	require.NoError(t, err)
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	t.Run("unknown environment", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/ws/ws-org/ws-app/missing")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("plain HTTP request", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/ws/ws-org/ws-app/prod")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("initial configuration and updates", func(t *testing.T) {
+		conn, err := websocket.Dial(wsURL+"/ws/ws-org/ws-app/prod", "", server.URL)
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(10*time.Second)))
+
+		// receive returns the next event of the given type, skipping the others
+		receive := func(event string) models.ConfigUpdateEvent {
+			for {
+				var message struct {
+					Event string          `json:"event"`
+					Data  json.RawMessage `json:"data"`
+				}
+				require.NoError(t, websocket.JSON.Receive(conn, &message))
+				if message.Event == event {
+					var update models.ConfigUpdateEvent
+					require.NoError(t, json.Unmarshal(message.Data, &update))
+					return update
+				}
+			}
+		}
+
+		initial := receive("initial_config")
+		assert.Equal(t, 1, initial.Version)
+		assert.JSONEq(t, `{"theme": "light"}`, string(initial.Config))
+
+		_, err = configService.UpdateConfiguration("ws-org", "ws-app", "prod", &models.CreateConfigRequest{Config: json.RawMessage(`{"theme": "dark"}`)}, false)
+		require.NoError(t, err)
+
+		update := receive("config_update")
+		assert.Equal(t, 2, update.Version)
+		assert.JSONEq(t, `{"theme": "dark"}`, string(update.Config))
+	})
+
+	// next returns the event name of the next message
+	next := func(t *testing.T, conn *websocket.Conn) string {
+		var message struct {
+			Event string `json:"event"`
+		}
+		require.NoError(t, websocket.JSON.Receive(conn, &message))
+		return message.Event
+	}
+
+	t.Run("the welcome message comes first", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			conn, err := websocket.Dial(wsURL+"/ws/ws-org/ws-app/prod", "", server.URL)
+			require.NoError(t, err)
+			require.NoError(t, conn.SetReadDeadline(time.Now().Add(10*time.Second)))
+
+			assert.Equal(t, "connected", next(t, conn))
+			assert.Equal(t, "initial_config", next(t, conn))
+			conn.Close()
+		}
+	})
+
+	t.Run("initial configuration turned off", func(t *testing.T) {
+		conn, err := websocket.Dial(wsURL+"/ws/ws-org/ws-app/prod?initial=false", "", server.URL)
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(10*time.Second)))
+
+		assert.Equal(t, "connected", next(t, conn))
+
+		_, err = configService.UpdateConfiguration("ws-org", "ws-app", "prod", &models.CreateConfigRequest{Config: json.RawMessage(`{"theme": "blue"}`)}, false)
+		require.NoError(t, err)
+		assert.Equal(t, "config_update", next(t, conn))
+	})
+
+	t.Run("updates filtered by keys", func(t *testing.T) {
+		conn, err := websocket.Dial(wsURL+"/ws/ws-org/ws-app/prod?initial=false&keys=theme", "", server.URL)
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(10*time.Second)))
+
+		assert.Equal(t, "connected", next(t, conn))
+
+		// The first update leaves the theme as it is and is not sent
+		_, err = configService.UpdateConfiguration("ws-org", "ws-app", "prod", &models.CreateConfigRequest{Config: json.RawMessage(`{"theme": "blue", "size": 1}`)}, false)
+		require.NoError(t, err)
+		updated, err := configService.UpdateConfiguration("ws-org", "ws-app", "prod", &models.CreateConfigRequest{Config: json.RawMessage(`{"theme": "red", "size": 1}`)}, false)
+		require.NoError(t, err)
+
+		var message struct {
+			Event string                   `json:"event"`
+			Data  models.ConfigUpdateEvent `json:"data"`
+		}
+		require.NoError(t, websocket.JSON.Receive(conn, &message))
+		assert.Equal(t, "config_update", message.Event)
+		assert.Equal(t, updated.Version, message.Data.Version)
+		assert.JSONEq(t, `{"theme": "red"}`, string(message.Data.Config))
+		assert.Equal(t, []string{"theme"}, message.Data.ChangedKeys)
+	})
+
+	t.Run("invalid initial parameter", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/ws/ws-org/ws-app/prod?initial=maybe")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("disconnect unregisters the client", func(t *testing.T) {
+		require.Eventually(t, func() bool {
+			return sseService.GetStats().ActiveConnections == 0
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+}
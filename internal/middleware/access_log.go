@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Access log formats
+const (
+	AccessLogText = "text"
+	AccessLogJSON = "json"
+)
+
+// RequestIDHeader carries the ID of a request, taken from the client when it sends a
+// valid one and generated otherwise
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDContextKey is the gin context key of the request ID
+const requestIDContextKey = "request_id"
+
+// requestIDPattern restricts client request IDs to what is safe to log, e.g. UUIDs
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// latencyBuckets are the upper bounds of the latency buckets of structured access logs
+var latencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// AccessLogConfig holds the access log configuration
+type AccessLogConfig struct {
+	// Format is AccessLogText for a line of text per request or AccessLogJSON for a JSON
+	// object per request
+	Format string
+	// Output receives the access log
+	Output io.Writer
+}
+
+// NewAccessLogConfig creates a new access log configuration from the ACCESS_LOG_FORMAT
+// environment variable, logging text unless it is "json"
+func NewAccessLogConfig() *AccessLogConfig {
+	format := AccessLogText
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("ACCESS_LOG_FORMAT")), AccessLogJSON) {
+		format = AccessLogJSON
+	}
+	return &AccessLogConfig{Format: format, Output: gin.DefaultWriter}
+}
+
+// accessLogEntry is a structured access log entry. Requests are logged by route, e.g.
+// /config/:org/:app/:env, rather than by path, so that entries can be aggregated per
+// endpoint; the route is empty for requests that matched none.
+type accessLogEntry struct {
+	Time          time.Time `json:"time"`
+	Method        string    `json:"method"`
+	Route         string    `json:"route"`
+	Status        int       `json:"status"`
+	LatencyMS     float64   `json:"latency_ms"`
+	LatencyBucket string    `json:"latency_bucket"`
+	Bytes         int       `json:"bytes"`
+	RequestID     string    `json:"request_id"`
+	// APIKeyID is the ID of the application whose API key or token made the request; the
+	// key itself is never logged
+	APIKeyID string `json:"api_key_id,omitempty"`
+	ClientIP string `json:"client_ip"`
+	Error    string `json:"error,omitempty"`
+}
+
+// RequestID middleware gives every request an ID, returned in the X-Request-ID header
+// and logged with the request
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set(requestIDContextKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+// RequestLogger middleware logs HTTP requests in the configured format
+func RequestLogger(config *AccessLogConfig) gin.HandlerFunc {
+	if config.Format == AccessLogJSON {
+		return structuredLogger(config.Output)
+	}
+
+	return gin.LoggerWithConfig(gin.LoggerConfig{
+		Output: config.Output,
+		Formatter: func(param gin.LogFormatterParams) string {
+			return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
+				param.ClientIP,
+				param.TimeStamp.Format(time.RFC1123),
+				param.Method,
+				param.Path,
+				param.Request.Proto,
+				param.StatusCode,
+				param.Latency,
+				param.Request.UserAgent(),
+				param.ErrorMessage,
+			)
+		},
+	})
+}
+
+// structuredLogger logs a JSON object per request once it is handled. The latency of
+// streams is the time the client stayed connected.
+func structuredLogger(output io.Writer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		entry := accessLogEntry{
+			Time:          start.UTC(),
+			Method:        c.Request.Method,
+			Route:         c.FullPath(),
+			Status:        c.Writer.Status(),
+			LatencyMS:     float64(latency.Microseconds()) / 1000,
+			LatencyBucket: latencyBucket(latency),
+			Bytes:         c.Writer.Size(),
+			RequestID:     c.GetString(requestIDContextKey),
+			ClientIP:      c.ClientIP(),
+			Error:         c.Errors.ByType(gin.ErrorTypePrivate).String(),
+		}
+		if entry.Bytes < 0 {
+			entry.Bytes = 0
+		}
+		if value, ok := c.Get("application"); ok {
+			if app, ok := value.(*models.Application); ok {
+				entry.APIKeyID = app.ID.String()
+			}
+		}
+
+		line, err := json.Marshal(entry)
+		if err != nil {
+			log.Printf("Failed to encode access log entry: %v", err)
+			return
+		}
+		if _, err := output.Write(append(line, '\n')); err != nil {
+			log.Printf("Failed to write access log entry: %v", err)
+		}
+	}
+}
+
+// latencyBucket names the bucket of a latency by its upper bound, e.g. "<=100ms", or
+// ">10s" above the largest one
+func latencyBucket(latency time.Duration) string {
+	for _, bound := range latencyBuckets {
+		if latency <= bound {
+			return "<=" + bound.String()
+		}
+	}
+	return ">" + latencyBuckets[len(latencyBuckets)-1].String()
+}
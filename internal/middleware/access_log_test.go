@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLogger_JSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	appID := uuid.New()
+	var output bytes.Buffer
+
+	router := gin.New()
+	router.Use(RequestID(), RequestLogger(&AccessLogConfig{Format: AccessLogJSON, Output: &output}))
+	router.GET("/config/:org/:app/:env", func(c *gin.Context) {
+		c.Set("application", &models.Application{ID: appID, APIKey: "secret-key"})
+		c.String(http.StatusOK, "hello")
+	})
+
+	send := func(path, requestID string) accessLogEntry {
+		output.Reset()
+		req := httptest.NewRequest("GET", path, nil)
+		if requestID != "" {
+			req.Header.Set(RequestIDHeader, requestID)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var entry accessLogEntry
+		require.NoError(t, json.Unmarshal(output.Bytes(), &entry), output.String())
+		assert.Equal(t, w.Header().Get(RequestIDHeader), entry.RequestID)
+		return entry
+	}
+
+	t.Run("requests are logged by route", func(t *testing.T) {
+		entry := send("/config/acme/web/prod", "req-123")
+
+		assert.Equal(t, "GET", entry.Method)
+		assert.Equal(t, "/config/:org/:app/:env", entry.Route)
+		assert.Equal(t, http.StatusOK, entry.Status)
+		assert.Equal(t, 5, entry.Bytes)
+		assert.Equal(t, "req-123", entry.RequestID)
+		assert.Equal(t, appID.String(), entry.APIKeyID)
+		assert.True(t, strings.HasPrefix(entry.LatencyBucket, "<="), entry.LatencyBucket)
+		assert.NotContains(t, output.String(), "secret-key")
+	})
+
+	t.Run("invalid request IDs are replaced", func(t *testing.T) {
+		entry := send("/config/acme/web/prod", "bad id\"")
+
+		_, err := uuid.Parse(entry.RequestID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("unmatched requests have no route", func(t *testing.T) {
+		entry := send("/unknown/path", "")
+
+		assert.Equal(t, http.StatusNotFound, entry.Status)
+		assert.Empty(t, entry.Route)
+		assert.Empty(t, entry.APIKeyID)
+		assert.NotEmpty(t, entry.RequestID)
+	})
+}
+
+func TestRequestLogger_Text(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var output bytes.Buffer
+	router := gin.New()
+	router.Use(RequestLogger(&AccessLogConfig{Format: AccessLogText, Output: &output}))
+	router.GET("/orgs/:org", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/orgs/acme", nil))
+
+	assert.Contains(t, output.String(), `"GET /orgs/acme HTTP/1.1 200`)
+}
+
+func TestLatencyBucket(t *testing.T) {
+	tests := []struct {
+		latency  time.Duration
+		expected string
+	}{
+		{time.Millisecond, "<=5ms"},
+		{5 * time.Millisecond, "<=5ms"},
+		{80 * time.Millisecond, "<=100ms"},
+		{2 * time.Second, "<=2.5s"},
+		{time.Minute, ">10s"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, latencyBucket(tt.latency), tt.latency.String())
+	}
+}
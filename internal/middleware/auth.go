@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -12,9 +11,11 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware handles API key authentication
+// AuthMiddleware handles API key, JWT bearer and admin token authentication
 type AuthMiddleware struct {
 	configService services.ConfigServiceInterface
+	jwtValidator  *JWTValidator
+	adminAuth     *AdminAuthConfig
 }
 
 // NewAuthMiddleware creates a new auth middleware
@@ -24,6 +25,41 @@ func NewAuthMiddleware(configService services.ConfigServiceInterface) *AuthMiddl
 	}
 }
 
+// SetJWTValidator enables JWT bearer authentication alongside API keys.
+// A nil validator disables it; bearer credentials are then treated as API keys.
+func (m *AuthMiddleware) SetJWTValidator(validator *JWTValidator) {
+	m.jwtValidator = validator
+}
+
+// authenticateJWT validates a bearer JWT and stores the application it maps to in the
+// context, exactly as API key authentication does. It aborts with 401 on failure.
+func (m *AuthMiddleware) authenticateJWT(c *gin.Context, token string) bool {
+	orgSlug, appSlug, err := m.jwtValidator.Validate(token)
+	if err == nil {
+		var app *models.Application
+		if app, err = m.configService.GetApplication(orgSlug, appSlug); err == nil {
+			// Store application info in context
+			c.Set("application", app)
+			c.Set("auth_method", "jwt")
+			return true
+		}
+	}
+
+	c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+		Error:     "unauthorized",
+		Message:   "Invalid bearer token",
+		Timestamp: time.Now(),
+		Path:      c.Request.URL.Path,
+	})
+	c.Abort()
+	return false
+}
+
+// usesJWT reports whether a request should be authenticated with a bearer JWT
+func (m *AuthMiddleware) usesJWT(authHeader, credential string) bool {
+	return m.jwtValidator != nil && strings.HasPrefix(authHeader, "Bearer ") && isJWT(credential)
+}
+
 // APIKeyAuth middleware validates API key from header or query parameter
 func (m *AuthMiddleware) APIKeyAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -52,6 +88,14 @@ func (m *AuthMiddleware) APIKeyAuth() gin.HandlerFunc {
 			apiKey = c.GetHeader("X-API-Key")
 		}
 
+		// Bearer JWTs are validated against the identity provider instead
+		if m.usesJWT(authHeader, apiKey) {
+			if m.authenticateJWT(c, apiKey) {
+				c.Next()
+			}
+			return
+		}
+
 		if apiKey == "" {
 			c.JSON(http.StatusUnauthorized, models.ErrorResponse{
 				Error:     "unauthorized",
@@ -79,6 +123,7 @@ func (m *AuthMiddleware) APIKeyAuth() gin.HandlerFunc {
 		// Store application info in context
 		c.Set("application", app)
 		c.Set("api_key", apiKey)
+		c.Set("auth_method", "api_key")
 
 		c.Next()
 	}
@@ -109,6 +154,14 @@ func (m *AuthMiddleware) OptionalAPIKeyAuth() gin.HandlerFunc {
 			apiKey = c.GetHeader("X-API-Key")
 		}
 
+		// Bearer JWTs are validated against the identity provider instead
+		if m.usesJWT(authHeader, apiKey) {
+			if m.authenticateJWT(c, apiKey) {
+				c.Next()
+			}
+			return
+		}
+
 		// If API key is provided, validate it
 		if apiKey != "" {
 			app, err := m.configService.ValidateAPIKey(apiKey)
@@ -126,49 +179,13 @@ func (m *AuthMiddleware) OptionalAPIKeyAuth() gin.HandlerFunc {
 			// Store application info in context
 			c.Set("application", app)
 			c.Set("api_key", apiKey)
+			c.Set("auth_method", "api_key")
 		}
 
 		c.Next()
 	}
 }
 
-// CORS middleware handles Cross-Origin Resource Sharing
-func CORS() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Allow all origins for development (in production, you'd want to be more restrictive)
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key")
-		c.Header("Access-Control-Expose-Headers", "Content-Length")
-		c.Header("Access-Control-Allow-Credentials", "true")
-
-		// Handle preflight requests
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
-			return
-		}
-
-		c.Next()
-	}
-}
-
-// RequestLogger middleware logs HTTP requests
-func RequestLogger() gin.HandlerFunc {
-	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
-			param.ClientIP,
-			param.TimeStamp.Format(time.RFC1123),
-			param.Method,
-			param.Path,
-			param.Request.Proto,
-			param.StatusCode,
-			param.Latency,
-			param.Request.UserAgent(),
-			param.ErrorMessage,
-		)
-	})
-}
-
 // ErrorHandler middleware handles panics and errors
 func ErrorHandler() gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// chunkedReader hides the length of a body, so that requests are sent without a
+// Content-Length
+type chunkedReader struct {
+	io.Reader
+}
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	config := &BodyLimitConfig{MaxBytes: 16, ConfigMaxBytes: 32}
+	echo := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.String(http.StatusOK, string(body))
+	}
+
+	router := gin.New()
+	router.Use(BodyLimit(config))
+	router.POST("/orgs", echo)
+	router.GET("/orgs", echo)
+	router.PUT("/admin/orgs/:org/apps/:app/envs/:env/config", echo)
+
+	send := func(method, path string, body io.Reader) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, body))
+		return w
+	}
+
+	t.Run("body at the limit", func(t *testing.T) {
+		body := strings.Repeat("a", 16)
+		w := send("POST", "/orgs", strings.NewReader(body))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, body, w.Body.String())
+	})
+
+	t.Run("body above the limit", func(t *testing.T) {
+		w := send("POST", "/orgs", strings.NewReader(strings.Repeat("a", 17)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "Request body exceeds the maximum of 16 bytes")
+	})
+
+	t.Run("body without a length", func(t *testing.T) {
+		w := send("POST", "/orgs", chunkedReader{strings.NewReader(strings.Repeat("a", 16))})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = send("POST", "/orgs", chunkedReader{strings.NewReader(strings.Repeat("a", 17))})
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("configuration routes have their own limit", func(t *testing.T) {
+		w := send("PUT", "/admin/orgs/acme/apps/web/envs/prod/config", strings.NewReader(strings.Repeat("a", 32)))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = send("PUT", "/admin/orgs/acme/apps/web/envs/prod/config", strings.NewReader(strings.Repeat("a", 33)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), "maximum of 32 bytes")
+	})
+
+	t.Run("reads are not limited", func(t *testing.T) {
+		w := send("GET", "/orgs", strings.NewReader(strings.Repeat("a", 17)))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig holds Cross-Origin Resource Sharing configuration
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed to make credentialed requests.
+	// When empty, any origin is allowed but credentials are not.
+	AllowedOrigins []string
+}
+
+// NewCORSConfig creates a new CORS configuration from the comma-separated
+// CORS_ALLOWED_ORIGINS environment variable
+func NewCORSConfig() *CORSConfig {
+	config := &CORSConfig{}
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin == "" || origin == "*" {
+			continue
+		}
+		config.AllowedOrigins = append(config.AllowedOrigins, origin)
+	}
+	return config
+}
+
+// isAllowed reports whether an origin is in the allowlist
+func (cfg *CORSConfig) isAllowed(origin string) bool {
+	for _, allowed := range cfg.AllowedOrigins {
+		if strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS middleware handles Cross-Origin Resource Sharing
+func CORS(config *CORSConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(config.AllowedOrigins) == 0 {
+			// Without an allowlist any origin may read responses, but never with credentials
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			// The response depends on the request origin, so shared caches must key on it
+			c.Writer.Header().Add("Vary", "Origin")
+
+			if origin := c.GetHeader("Origin"); origin != "" && config.isAllowed(origin) {
+				c.Header("Access-Control-Allow-Origin", origin)
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		}
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, X-Admin-Token, Idempotency-Key, X-Client-ID, X-Config-Schema-Version, Accept-Version, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "Content-Length, Idempotent-Replayed, X-Config-Schema-Version, X-Request-ID")
+
+		// Handle preflight requests
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
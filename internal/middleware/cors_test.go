@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(config *CORSConfig, method, origin string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(CORS(config))
+		router.GET("/test", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		})
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/test", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	allowlist := &CORSConfig{AllowedOrigins: []string{"https://dashboard.example.com"}}
+
+	t.Run("allowed origin is echoed with credentials", func(t *testing.T) {
+		w := serve(allowlist, "GET", "https://dashboard.example.com")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://dashboard.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+	})
+
+	t.Run("disallowed origin gets no CORS grant", func(t *testing.T) {
+		w := serve(allowlist, "GET", "https://evil.example.com")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("disallowed preflight", func(t *testing.T) {
+		w := serve(allowlist, "OPTIONS", "https://evil.example.com")
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("wildcard without credentials when unset", func(t *testing.T) {
+		w := serve(&CORSConfig{}, "GET", "https://anything.example.com")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+}
+
+func TestNewCORSConfig(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+	assert.Empty(t, NewCORSConfig().AllowedOrigins)
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+	assert.Empty(t, NewCORSConfig().AllowedOrigins)
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://a.example.com, https://b.example.com/ ,")
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, NewCORSConfig().AllowedOrigins)
+}
@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"remote-config-system/internal/cache"
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxIdempotencyKeyLength bounds the length of client-supplied idempotency keys
+const maxIdempotencyKeyLength = 255
+
+// idempotencyReservationTTL bounds how long a key stays reserved by a request in
+// progress, in case the request is never completed nor released, e.g. on a crash
+const idempotencyReservationTTL = 2 * time.Minute
+
+// IdempotencyConfig holds configuration for idempotent mutations
+type IdempotencyConfig struct {
+	// TTL is how long the outcome of a request is remembered under its key
+	TTL time.Duration
+}
+
+// NewIdempotencyConfig creates a new idempotency configuration from environment variables
+func NewIdempotencyConfig() *IdempotencyConfig {
+	ttl := 24 * time.Hour
+	if value := os.Getenv("IDEMPOTENCY_KEY_TTL"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			ttl = time.Duration(seconds) * time.Second
+		}
+	}
+	return &IdempotencyConfig{TTL: ttl}
+}
+
+// responseRecorder captures the response body so that it can be replayed
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency middleware makes a mutation safe to retry. When a request carries an
+// Idempotency-Key header, its response is stored in Redis, and repeats with the same
+// key get the stored response instead of running the handler again. Reusing a key with
+// a different request returns 409. Requests without the header, or without an available
+// cache, are passed through unchanged.
+func Idempotency(redisClient *cache.RedisClient, config *IdempotencyConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" || redisClient == nil || !redisClient.IsAvailable() {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:     "bad_request",
+				Message:   "Idempotency-Key must be at most " + strconv.Itoa(maxIdempotencyKeyLength) + " characters",
+				Timestamp: time.Now(),
+				Path:      c.Request.URL.Path,
+			})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:     "bad_request",
+				Message:   "Failed to read request body",
+				Timestamp: time.Now(),
+				Path:      c.Request.URL.Path,
+			})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// The same key may only be reused for an identical request
+		cacheKey := cache.GenerateIdempotencyKey(idempotencyPrincipal(c), c.Request.URL.Path, key)
+		fingerprint := cache.HashContent(append([]byte(c.Request.Method+" "+c.Request.URL.RequestURI()+"\n"), body...))
+
+		existing, err := redisClient.ReserveIdempotencyKey(cacheKey, fingerprint, idempotencyReservationTTL)
+		if err != nil {
+			// Fall back to processing the request rather than failing the write
+			log.Printf("Idempotency check failed, processing request without it: %v", err)
+			c.Next()
+			return
+		}
+
+		if existing != nil {
+			replayIdempotentResponse(c, existing, fingerprint)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		// Unless the response is stored, the key is released so that the request can be
+		// retried, including when a handler panics or the request ends without a response
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := redisClient.ReleaseIdempotencyKey(cacheKey); err != nil {
+				log.Printf("Failed to release idempotency key: %v", err)
+			}
+		}()
+
+		c.Next()
+
+		// Server errors are not remembered, so that the request can be retried
+		status := recorder.Status()
+		if !recorder.Written() || status >= http.StatusInternalServerError {
+			return
+		}
+
+		record := &cache.IdempotencyRecord{
+			Fingerprint: fingerprint,
+			StatusCode:  status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}
+		if err := redisClient.CompleteIdempotencyKey(cacheKey, record, config.TTL); err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// idempotencyPrincipal identifies the caller of a request by the credential it presented,
+// so that one caller cannot get another's response by reusing their key
+func idempotencyPrincipal(c *gin.Context) string {
+	if token := c.GetHeader("X-Admin-Token"); token != "" {
+		return "admin:" + token
+	}
+	if apiKey := c.GetString("api_key"); apiKey != "" {
+		return "api_key:" + apiKey
+	}
+	return ""
+}
+
+// replayIdempotentResponse answers a request whose idempotency key was already used
+func replayIdempotentResponse(c *gin.Context, record *cache.IdempotencyRecord, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:     "idempotency_key_reused",
+			Message:   "Idempotency-Key was already used for a different request",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		c.Abort()
+		return
+	}
+
+	if record.InProgress() {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:     "idempotency_key_in_progress",
+			Message:   "A request with this Idempotency-Key is still being processed",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		c.Abort()
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	contentType := record.ContentType
+	if contentType == "" {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Data(record.StatusCode, contentType, record.Body)
+	c.Abort()
+}
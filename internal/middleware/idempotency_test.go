@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	redisClient, err := cache.NewRedisClient(&cache.Config{Host: mr.Host(), Port: mr.Port()})
+	require.NoError(t, err)
+	t.Cleanup(func() { redisClient.Close() })
+
+	version := 0
+	router := gin.New()
+	router.PUT("/config", Idempotency(redisClient, &IdempotencyConfig{TTL: time.Hour}), func(c *gin.Context) {
+		version++
+		c.JSON(http.StatusOK, gin.H{"version": version})
+	})
+	router.POST("/export", Idempotency(redisClient, &IdempotencyConfig{TTL: time.Hour}), func(c *gin.Context) {
+		version++
+		c.Data(http.StatusOK, "application/x-yaml", []byte("version: 1\n"))
+	})
+	router.POST("/broken", Idempotency(redisClient, &IdempotencyConfig{TTL: time.Hour}), func(c *gin.Context) {
+		version++
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+	})
+	router.POST("/panic", gin.CustomRecovery(func(c *gin.Context, _ interface{}) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}), Idempotency(redisClient, &IdempotencyConfig{TTL: time.Hour}), func(c *gin.Context) {
+		version++
+		panic("boom")
+	})
+	router.POST("/silent", Idempotency(redisClient, &IdempotencyConfig{TTL: time.Hour}), func(c *gin.Context) {
+		version++
+		c.Abort()
+	})
+
+	sendAs := func(token, method, path, key, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		if token != "" {
+			req.Header.Set("X-Admin-Token", token)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+	send := func(method, path, key, body string) *httptest.ResponseRecorder {
+		return sendAs("", method, path, key, body)
+	}
+
+	t.Run("repeat returns the original result", func(t *testing.T) {
+		version = 0
+
+		first := send("PUT", "/config", "key-1", `{"config":{"a":1}}`)
+		second := send("PUT", "/config", "key-1", `{"config":{"a":1}}`)
+
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.JSONEq(t, `{"version":1}`, second.Body.String())
+		assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, 1, version, "handler should only run once")
+	})
+
+	t.Run("different payload under the same key", func(t *testing.T) {
+		send("PUT", "/config", "key-2", `{"config":{"a":1}}`)
+		w := send("PUT", "/config", "key-2", `{"config":{"a":2}}`)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "idempotency_key_reused")
+	})
+
+	t.Run("requests without a key are not deduplicated", func(t *testing.T) {
+		version = 0
+
+		send("PUT", "/config", "", `{"config":{"a":1}}`)
+		send("PUT", "/config", "", `{"config":{"a":1}}`)
+
+		assert.Equal(t, 2, version)
+	})
+
+	t.Run("keys are scoped to the caller", func(t *testing.T) {
+		version = 0
+
+		first := sendAs("token-a", "PUT", "/config", "key-4", `{"config":{"a":1}}`)
+		other := sendAs("token-b", "PUT", "/config", "key-4", `{"config":{"a":1}}`)
+
+		assert.JSONEq(t, `{"version":1}`, first.Body.String())
+		assert.JSONEq(t, `{"version":2}`, other.Body.String())
+		assert.Empty(t, other.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, 2, version)
+	})
+
+	t.Run("replay keeps the content type", func(t *testing.T) {
+		send("POST", "/export", "key-5", `{}`)
+		w := send("POST", "/export", "key-5", `{}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-yaml", w.Header().Get("Content-Type"))
+		assert.Equal(t, "version: 1\n", w.Body.String())
+		assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("server errors can be retried", func(t *testing.T) {
+		version = 0
+
+		send("POST", "/broken", "key-3", `{}`)
+		w := send("POST", "/broken", "key-3", `{}`)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, 2, version)
+	})
+
+	t.Run("panics and unanswered requests can be retried", func(t *testing.T) {
+		version = 0
+
+		send("POST", "/panic", "key-6", `{}`)
+		w := send("POST", "/panic", "key-6", `{}`)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "idempotency_key_in_progress")
+
+		send("POST", "/silent", "key-7", `{}`)
+		send("POST", "/silent", "key-7", `{}`)
+		assert.Equal(t, 4, version)
+	})
+
+	t.Run("reservations expire sooner than responses", func(t *testing.T) {
+		cacheKey := cache.GenerateIdempotencyKey("", "/config", "key-8")
+		_, err := redisClient.ReserveIdempotencyKey(cacheKey, "fingerprint", idempotencyReservationTTL)
+		require.NoError(t, err)
+		assert.Equal(t, idempotencyReservationTTL, mr.TTL(cacheKey))
+		require.NoError(t, redisClient.ReleaseIdempotencyKey(cacheKey))
+
+		send("PUT", "/config", "key-8", `{"config":{"a":1}}`)
+		assert.Equal(t, time.Hour, mr.TTL(cacheKey))
+	})
+}
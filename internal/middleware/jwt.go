@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig holds configuration for JWT bearer authentication
+type JWTConfig struct {
+	JWKSURL         string        // URL of the identity provider's JSON Web Key Set
+	Issuer          string        // Expected "iss" claim (optional)
+	Audience        string        // Expected "aud" claim (optional)
+	OrgClaim        string        // Claim holding the organization slug
+	AppClaim        string        // Claim holding the application slug
+	RefreshInterval time.Duration // Minimum time between key set refreshes
+}
+
+// NewJWTConfig creates a new JWT configuration from environment variables.
+// JWT authentication is disabled when JWT_JWKS_URL is not set.
+func NewJWTConfig() *JWTConfig {
+	refreshInterval := 5 * time.Minute
+	if value := os.Getenv("JWT_JWKS_REFRESH_INTERVAL"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			refreshInterval = time.Duration(seconds) * time.Second
+		}
+	}
+
+	return &JWTConfig{
+		JWKSURL:         os.Getenv("JWT_JWKS_URL"),
+		Issuer:          os.Getenv("JWT_ISSUER"),
+		Audience:        os.Getenv("JWT_AUDIENCE"),
+		OrgClaim:        getEnv("JWT_ORG_CLAIM", "org_slug"),
+		AppClaim:        getEnv("JWT_APP_CLAIM", "app_slug"),
+		RefreshInterval: refreshInterval,
+	}
+}
+
+// Enabled reports whether JWT authentication is configured
+func (cfg *JWTConfig) Enabled() bool {
+	return cfg.JWKSURL != ""
+}
+
+// JWTValidator validates RS256 bearer tokens against a remote JSON Web Key Set
+type JWTValidator struct {
+	config *JWTConfig
+	client *http.Client
+
+	keysMux     sync.RWMutex
+	keys        map[string]*rsa.PublicKey
+	lastRefresh time.Time
+}
+
+// NewJWTValidator creates a new JWT validator. Keys are fetched lazily on first use.
+func NewJWTValidator(config *JWTConfig) *JWTValidator {
+	return &JWTValidator{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   make(map[string]*rsa.PublicKey),
+	}
+}
+
+// Validate verifies a token's signature and standard claims and returns the
+// organization and application slugs it grants access to
+func (v *JWTValidator) Validate(tokenString string) (string, string, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if v.config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(v.config.Issuer))
+	}
+	if v.config.Audience != "" {
+		options = append(options, jwt.WithAudience(v.config.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(tokenString, claims, v.keyFunc, options...); err != nil {
+		return "", "", fmt.Errorf("invalid token: %w", err)
+	}
+
+	orgSlug, _ := claims[v.config.OrgClaim].(string)
+	appSlug, _ := claims[v.config.AppClaim].(string)
+	if orgSlug == "" || appSlug == "" {
+		return "", "", fmt.Errorf("invalid token: missing %s or %s claim", v.config.OrgClaim, v.config.AppClaim)
+	}
+
+	return orgSlug, appSlug, nil
+}
+
+// keyFunc resolves the public key for a token by its "kid" header, refreshing the
+// key set when the key is unknown so that provider key rotation is picked up
+func (v *JWTValidator) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if key := v.lookupKey(kid); key != nil {
+		return key, nil
+	}
+
+	if err := v.refreshKeys(); err != nil {
+		return nil, err
+	}
+
+	if key := v.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key: %q", kid)
+}
+
+// lookupKey returns the cached key with the given ID. Tokens without a key ID
+// are accepted only when the key set holds a single key.
+func (v *JWTValidator) lookupKey(kid string) *rsa.PublicKey {
+	v.keysMux.RLock()
+	defer v.keysMux.RUnlock()
+
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key
+		}
+	}
+	return v.keys[kid]
+}
+
+// jsonWebKey is a single RSA key of a JSON Web Key Set
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// refreshKeys fetches the key set, at most once per refresh interval
+func (v *JWTValidator) refreshKeys() error {
+	v.keysMux.Lock()
+	defer v.keysMux.Unlock()
+
+	if time.Since(v.lastRefresh) < v.config.RefreshInterval {
+		return nil
+	}
+	v.lastRefresh = time.Now()
+
+	resp, err := v.client.Get(v.config.JWKSURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var keySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&keySet); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := parseRSAPublicKey(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	v.keys = keys
+	return nil
+}
+
+// parseRSAPublicKey builds an RSA public key from its base64url-encoded modulus and exponent
+func parseRSAPublicKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > int64(^uint32(0)>>1) {
+		return nil, fmt.Errorf("exponent too large")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+// isJWT reports whether a bearer credential is shaped like a compact JWS
+// (three base64url segments), as opposed to an opaque API key
+func isJWT(credential string) bool {
+	parts := strings.Split(credential, ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return false
+	}
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(header, &fields); err != nil {
+		return false
+	}
+	_, hasAlg := fields["alg"]
+	return hasAlg
+}
+
+// getEnv gets an environment variable with a fallback value
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
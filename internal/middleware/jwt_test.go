@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestJWKS serves a key set containing the public half of key under the given key ID
+func newTestJWKS(t *testing.T, kid string, key *rsa.PrivateKey) *httptest.Server {
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": kid,
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(jwks)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func signTestToken(t *testing.T, kid string, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestAuthMiddleware_JWTAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := newTestJWKS(t, "key-1", key)
+	config := &JWTConfig{
+		JWKSURL:         server.URL,
+		Issuer:          "https://idp.example.com",
+		OrgClaim:        "org_slug",
+		AppClaim:        "app_slug",
+		RefreshInterval: time.Minute,
+	}
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":      "https://idp.example.com",
+			"sub":      "user-1",
+			"exp":      time.Now().Add(time.Hour).Unix(),
+			"org_slug": "test-org",
+			"app_slug": "test-app",
+		}
+	}
+
+	run := func(mockService *testutil.MockConfigService, authHeader string) (*httptest.ResponseRecorder, *gin.Context) {
+		authMiddleware := NewAuthMiddleware(mockService)
+		authMiddleware.SetJWTValidator(NewJWTValidator(config))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/test", nil)
+		c.Request.Header.Set("Authorization", authHeader)
+
+		authMiddleware.APIKeyAuth()(c)
+		return w, c
+	}
+
+	t.Run("valid token maps to the application", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		app := testutil.CreateTestApplication(uuid.New(), "Test App", "test-app", "app-api-key")
+		mockService.On("GetApplication", "test-org", "test-app").Return(app, nil)
+
+		_, c := run(mockService, "Bearer "+signTestToken(t, "key-1", key, validClaims()))
+
+		assert.False(t, c.IsAborted())
+		appFromContext, _ := c.Get("application")
+		assert.Equal(t, app, appFromContext)
+		_, hasAPIKey := c.Get("api_key")
+		assert.False(t, hasAPIKey, "only the hash of the API key is known")
+		authMethod, _ := c.Get("auth_method")
+		assert.Equal(t, "jwt", authMethod)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("rejected tokens", func(t *testing.T) {
+		expired := validClaims()
+		expired["exp"] = time.Now().Add(-time.Minute).Unix()
+
+		wrongIssuer := validClaims()
+		wrongIssuer["iss"] = "https://other.example.com"
+
+		missingClaim := validClaims()
+		delete(missingClaim, "app_slug")
+
+		tokens := map[string]string{
+			"expired":       signTestToken(t, "key-1", key, expired),
+			"wrong issuer":  signTestToken(t, "key-1", key, wrongIssuer),
+			"missing claim": signTestToken(t, "key-1", key, missingClaim),
+			"wrong key":     signTestToken(t, "key-1", otherKey, validClaims()),
+			"unknown kid":   signTestToken(t, "key-2", otherKey, validClaims()),
+		}
+
+		for name, token := range tokens {
+			mockService := &testutil.MockConfigService{}
+			w, c := run(mockService, "Bearer "+token)
+
+			assert.True(t, c.IsAborted(), name)
+			assert.Equal(t, http.StatusUnauthorized, w.Code, name)
+			mockService.AssertNotCalled(t, "GetApplication", "test-org", "test-app")
+			mockService.AssertNotCalled(t, "ValidateAPIKey", token)
+		}
+	})
+
+	t.Run("unknown application", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("GetApplication", "test-org", "test-app").
+			Return(nil, fmt.Errorf("application not found: test-org/test-app"))
+
+		w, c := run(mockService, "Bearer "+signTestToken(t, "key-1", key, validClaims()))
+
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("opaque bearer credentials are still treated as API keys", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		app := testutil.CreateTestApplication(uuid.New(), "Test App", "test-app", "valid-api-key")
+		mockService.On("ValidateAPIKey", "valid-api-key").Return(app, nil)
+
+		_, c := run(mockService, "Bearer valid-api-key")
+
+		assert.False(t, c.IsAborted())
+		authMethod, _ := c.Get("auth_method")
+		assert.Equal(t, "api_key", authMethod)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestIsJWT(t *testing.T) {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+
+	assert.True(t, isJWT(header+".payload.signature"))
+	assert.False(t, isJWT("a1b2c3d4e5f6"))
+	assert.False(t, isJWT("not.a.jwt"))
+	assert.False(t, isJWT(header+"..signature"))
+}
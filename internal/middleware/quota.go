@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadQuotaService counts configuration reads towards the monthly read quotas of
+// organizations
+type ReadQuotaService interface {
+	CheckOrganizationReadQuota(orgSlug string) error
+	RecordOrganizationRead(orgSlug string) error
+}
+
+// ReadQuota middleware enforces the monthly read quota of the organization a configuration
+// is read from: the :org parameter of public reads, or the organization of the application
+// authenticated with an API key. Reads over the quota are rejected with 429 and a
+// quota_exceeded error. Only successful reads, including 304 responses, count towards the
+// quota. A stream, WebSocket or long poll counts as one read as soon as it is opened, so
+// connections that stay open are counted too. Reads are let through when the quota cannot
+// be checked.
+func ReadQuota(quotas ReadQuotaService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgSlug := c.Param("org")
+		if value, ok := c.Get("application"); ok {
+			if app, ok := value.(*models.Application); ok && app.Organization != nil {
+				orgSlug = app.Organization.Slug
+			}
+		}
+		if orgSlug == "" {
+			c.Next()
+			return
+		}
+
+		if err := quotas.CheckOrganizationReadQuota(orgSlug); err != nil {
+			var quotaErr *services.QuotaExceededError
+			if !errors.As(err, &quotaErr) {
+				log.Printf("Failed to check the read quota of organization %s: %v", orgSlug, err)
+				c.Next()
+				return
+			}
+
+			retryAfter := max(int(time.Until(quotaErr.ResetsAt).Seconds()), 1)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, models.ErrorResponse{
+				Error:     "quota_exceeded",
+				Message:   quotaErr.Error(),
+				Timestamp: time.Now(),
+				Path:      c.Request.URL.Path,
+			})
+			c.Abort()
+			return
+		}
+
+		writer := &readCountingWriter{ResponseWriter: c.Writer, record: func() {
+			if err := quotas.RecordOrganizationRead(orgSlug); err != nil {
+				log.Printf("Failed to count a read of organization %s: %v", orgSlug, err)
+			}
+		}}
+		c.Writer = writer
+		c.Next()
+
+		// Responses without a body, like 304, are only written once the handler returns
+		writer.count()
+	}
+}
+
+// readCountingWriter counts a read once its response starts, when the status is known
+// to be successful. Streams start their response long before they end.
+type readCountingWriter struct {
+	gin.ResponseWriter
+	record  func()
+	counted bool
+}
+
+// count records the read unless it was counted already or failed
+func (w *readCountingWriter) count() {
+	if w.counted || w.Status() >= http.StatusBadRequest {
+		return
+	}
+	w.counted = true
+	w.record()
+}
+
+func (w *readCountingWriter) WriteHeaderNow() {
+	w.count()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *readCountingWriter) Write(data []byte) (int, error) {
+	w.count()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *readCountingWriter) WriteString(s string) (int, error) {
+	w.count()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *readCountingWriter) Flush() {
+	w.count()
+	w.ResponseWriter.Flush()
+}
+
+// Hijack counts WebSocket upgrades, which take the connection over without a response
+func (w *readCountingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.count()
+	return w.ResponseWriter.Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the connection's deadlines
+func (w *readCountingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReadQuota allows a fixed number of reads per organization
+type fakeReadQuota struct {
+	quota    int
+	reads    map[string]int
+	checkErr error
+}
+
+func (f *fakeReadQuota) CheckOrganizationReadQuota(orgSlug string) error {
+	if f.checkErr != nil {
+		return f.checkErr
+	}
+	if f.reads[orgSlug] >= f.quota {
+		return &services.QuotaExceededError{Organization: orgSlug, Quota: int64(f.quota), ResetsAt: time.Now().Add(time.Hour)}
+	}
+	return nil
+}
+
+func (f *fakeReadQuota) RecordOrganizationRead(orgSlug string) error {
+	f.reads[orgSlug]++
+	return nil
+}
+
+func TestReadQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	quotas := &fakeReadQuota{quota: 2, reads: map[string]int{}}
+	app := &models.Application{Organization: &models.Organization{Slug: "keyed"}}
+
+	router := gin.New()
+	router.GET("/config/:org/:app/:env", ReadQuota(quotas), func(c *gin.Context) {
+		if c.Param("env") == "missing" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	router.GET("/api/config/:env", func(c *gin.Context) { c.Set("application", app) }, ReadQuota(quotas), func(c *gin.Context) {
+		c.Status(http.StatusNotModified)
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("reads are limited per organization", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get("/config/acme/web/prod").Code)
+		assert.Equal(t, http.StatusOK, get("/config/acme/web/prod").Code)
+
+		w := get("/config/acme/web/prod")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Contains(t, w.Body.String(), `"error":"quota_exceeded"`)
+		retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.InDelta(t, 3600, retryAfter, 5)
+		assert.Equal(t, 2, quotas.reads["acme"], "rejected reads are not counted")
+
+		assert.Equal(t, http.StatusOK, get("/config/globex/web/prod").Code)
+	})
+
+	t.Run("failed reads are not counted", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/config/initech/web/missing").Code)
+		assert.Zero(t, quotas.reads["initech"])
+	})
+
+	t.Run("reads with an API key count for the application's organization", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotModified, get("/api/config/prod").Code)
+		assert.Equal(t, 1, quotas.reads["keyed"])
+	})
+
+	t.Run("reads are let through when the quota cannot be checked", func(t *testing.T) {
+		quotas.checkErr = errors.New("redis down")
+		defer func() { quotas.checkErr = nil }()
+
+		assert.Equal(t, http.StatusOK, get("/config/acme/web/prod").Code)
+	})
+}
+
+func TestReadQuotaStreams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	quotas := &fakeReadQuota{quota: 1, reads: map[string]int{}}
+	var countedWhileOpen int
+
+	router := gin.New()
+	router.GET("/events/:org/:app/:env", ReadQuota(quotas), func(c *gin.Context) {
+		if c.Param("env") == "missing" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not_found"})
+			return
+		}
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+		countedWhileOpen = quotas.reads[c.Param("org")]
+		c.Writer.WriteString("event: ping\n\n")
+	})
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("streams count once when they are opened", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, get("/events/acme/web/prod").Code)
+		assert.Equal(t, 1, countedWhileOpen)
+		assert.Equal(t, 1, quotas.reads["acme"])
+	})
+
+	t.Run("streams over the quota are not opened", func(t *testing.T) {
+		assert.Equal(t, http.StatusTooManyRequests, get("/events/acme/web/prod").Code)
+		assert.Equal(t, 1, quotas.reads["acme"])
+	})
+
+	t.Run("streams that fail are not counted", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/events/initech/web/missing").Code)
+		assert.Zero(t, quotas.reads["initech"])
+	})
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuthConfig holds configuration for role-based access to the admin API
+type AdminAuthConfig struct {
+	// Enabled turns on role checks. When disabled, every admin request is allowed.
+	Enabled bool
+	// BootstrapToken is accepted with the admin role, so that the first tokens can be created
+	BootstrapToken string
+}
+
+// NewAdminAuthConfig creates a new admin auth configuration from environment variables.
+// Role checks are on unless ADMIN_AUTH_ENABLED explicitly turns them off; a value that
+// cannot be parsed leaves them on rather than opening the admin API.
+func NewAdminAuthConfig() *AdminAuthConfig {
+	enabled := true
+	if value := os.Getenv("ADMIN_AUTH_ENABLED"); value != "" {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			enabled = parsed
+		}
+	}
+	return &AdminAuthConfig{
+		Enabled:        enabled,
+		BootstrapToken: os.Getenv("ADMIN_BOOTSTRAP_TOKEN"),
+	}
+}
+
+// SetAdminAuth configures role-based access to the admin API. A nil or disabled
+// configuration leaves admin endpoints open.
+func (m *AuthMiddleware) SetAdminAuth(config *AdminAuthConfig) {
+	m.adminAuth = config
+}
+
+// RequireRole middleware authenticates the X-Admin-Token header and requires its
+// role to grant at least the given role. The resolved role is stored in the context,
+// so the token is only looked up once when several checks apply to a route.
+func (m *AuthMiddleware) RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m.adminAuth == nil || !m.adminAuth.Enabled {
+			c.Next()
+			return
+		}
+
+		tokenRole := c.GetString("admin_role")
+		if tokenRole == "" {
+			var ok bool
+			if tokenRole, ok = m.resolveAdminRole(c); !ok {
+				return
+			}
+		}
+
+		if !models.RoleAllows(tokenRole, role) {
+			c.JSON(http.StatusForbidden, models.ErrorResponse{
+				Error:     "forbidden",
+				Message:   fmt.Sprintf("This action requires the %s role, but the admin token has the %s role", role, tokenRole),
+				Timestamp: time.Now(),
+				Path:      c.Request.URL.Path,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RoleGranted reports whether the admin token of a request grants at least a role, for
+// handlers whose requests need a higher role only for some of their fields. It relies on
+// RequireRole having run for the route: without a resolved role, role checks are disabled.
+func RoleGranted(c *gin.Context, role string) bool {
+	tokenRole := c.GetString("admin_role")
+	return tokenRole == "" || models.RoleAllows(tokenRole, role)
+}
+
+// resolveAdminRole looks up the role of the request's admin token and stores it in
+// the context. It aborts with 401 if the token is missing or invalid.
+func (m *AuthMiddleware) resolveAdminRole(c *gin.Context) (string, bool) {
+	token := c.GetHeader("X-Admin-Token")
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:     "unauthorized",
+			Message:   "Admin token is required",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		c.Abort()
+		return "", false
+	}
+
+	bootstrap := m.adminAuth.BootstrapToken
+	if bootstrap != "" && subtle.ConstantTimeCompare([]byte(token), []byte(bootstrap)) == 1 {
+		c.Set("admin_role", models.RoleAdmin)
+		c.Set("admin_token_name", "bootstrap")
+		return models.RoleAdmin, true
+	}
+
+	adminToken, err := m.configService.ValidateAdminToken(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:     "unauthorized",
+			Message:   "Invalid admin token",
+			Timestamp: time.Now(),
+			Path:      c.Request.URL.Path,
+		})
+		c.Abort()
+		return "", false
+	}
+
+	c.Set("admin_role", adminToken.Role)
+	c.Set("admin_token_name", adminToken.Name)
+	return adminToken.Role, true
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthMiddleware_RequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	run := func(mockService *testutil.MockConfigService, config *AdminAuthConfig, role, token string) (*httptest.ResponseRecorder, *gin.Context) {
+		authMiddleware := NewAuthMiddleware(mockService)
+		authMiddleware.SetAdminAuth(config)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/admin/orgs/test-org", nil)
+		if token != "" {
+			c.Request.Header.Set("X-Admin-Token", token)
+		}
+
+		authMiddleware.RequireRole(role)(c)
+		return w, c
+	}
+
+	enabled := &AdminAuthConfig{Enabled: true, BootstrapToken: "bootstrap-token"}
+
+	t.Run("disabled allows every request", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+
+		_, c := run(mockService, &AdminAuthConfig{}, models.RoleAdmin, "")
+
+		assert.False(t, c.IsAborted())
+		mockService.AssertNotCalled(t, "ValidateAdminToken", "")
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		w, c := run(&testutil.MockConfigService{}, enabled, models.RoleViewer, "")
+
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ValidateAdminToken", "bad-token").
+			Return(nil, fmt.Errorf("invalid admin token: not found"))
+
+		w, c := run(mockService, enabled, models.RoleViewer, "bad-token")
+
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("insufficient role", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ValidateAdminToken", "viewer-token").
+			Return(&models.AdminToken{ID: uuid.New(), Name: "ci", Role: models.RoleViewer}, nil)
+
+		w, c := run(mockService, enabled, models.RoleEditor, "viewer-token")
+
+		assert.True(t, c.IsAborted())
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "requires the editor role")
+	})
+
+	t.Run("sufficient role", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+		mockService.On("ValidateAdminToken", "admin-token").
+			Return(&models.AdminToken{ID: uuid.New(), Name: "ops", Role: models.RoleAdmin}, nil)
+
+		_, c := run(mockService, enabled, models.RoleEditor, "admin-token")
+
+		assert.False(t, c.IsAborted())
+		role, _ := c.Get("admin_role")
+		assert.Equal(t, models.RoleAdmin, role)
+	})
+
+	t.Run("bootstrap token has the admin role", func(t *testing.T) {
+		mockService := &testutil.MockConfigService{}
+
+		_, c := run(mockService, enabled, models.RoleAdmin, "bootstrap-token")
+
+		assert.False(t, c.IsAborted())
+		mockService.AssertNotCalled(t, "ValidateAdminToken", "bootstrap-token")
+	})
+}
+
+func TestRoleGranted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	context := func(role string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		if role != "" {
+			c.Set("admin_role", role)
+		}
+		return c
+	}
+
+	assert.True(t, RoleGranted(context(""), models.RoleAdmin), "role checks are disabled")
+	assert.True(t, RoleGranted(context(models.RoleAdmin), models.RoleAdmin))
+	assert.False(t, RoleGranted(context(models.RoleEditor), models.RoleAdmin))
+	assert.True(t, RoleGranted(context(models.RoleEditor), models.RoleViewer))
+}
+
+func TestNewAdminAuthConfig(t *testing.T) {
+	t.Setenv("ADMIN_BOOTSTRAP_TOKEN", "bootstrap-token")
+
+	t.Setenv("ADMIN_AUTH_ENABLED", "")
+	assert.True(t, NewAdminAuthConfig().Enabled, "role checks should be on by default")
+	assert.Equal(t, "bootstrap-token", NewAdminAuthConfig().BootstrapToken)
+
+	t.Setenv("ADMIN_AUTH_ENABLED", "false")
+	assert.False(t, NewAdminAuthConfig().Enabled)
+
+	t.Setenv("ADMIN_AUTH_ENABLED", "off")
+	assert.True(t, NewAdminAuthConfig().Enabled, "an unparsable value should not open the admin API")
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Schema version request headers. Accept-Version is accepted for clients that already
+// send it; X-Config-Schema-Version is the documented one.
+const (
+	SchemaVersionHeader     = "X-Config-Schema-Version"
+	AcceptVersionHeader     = "Accept-Version"
+	schemaVersionContextKey = "config_schema_version"
+)
+
+// ConfigSchemaVersion negotiates the schema version of configuration responses. Clients
+// that send no version get version 1, and versions newer than the server knows are served
+// the latest one. A malformed version is rejected with a 400 response.
+func ConfigSchemaVersion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version, err := negotiateSchemaVersion(c.GetHeader(SchemaVersionHeader), c.GetHeader(AcceptVersionHeader))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:     "invalid_schema_version",
+				Message:   err.Error(),
+				Timestamp: time.Now(),
+				Path:      c.Request.URL.Path,
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(schemaVersionContextKey, version)
+		c.Next()
+	}
+}
+
+// SchemaVersion returns the configuration schema version negotiated for a request, or
+// version 1 if the request did not pass through ConfigSchemaVersion
+func SchemaVersion(c *gin.Context) int {
+	if version, ok := c.Get(schemaVersionContextKey); ok {
+		return version.(int)
+	}
+	return models.ConfigSchemaV1
+}
+
+// negotiateSchemaVersion resolves the schema version from the two request headers, which
+// must agree if both are sent
+func negotiateSchemaVersion(schemaHeader, acceptHeader string) (int, error) {
+	version := 0
+	for _, header := range []struct{ name, value string }{
+		{SchemaVersionHeader, schemaHeader},
+		{AcceptVersionHeader, acceptHeader},
+	} {
+		value := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(header.value)), "v")
+		if value == "" {
+			continue
+		}
+
+		requested, err := strconv.Atoi(value)
+		if err != nil || requested < models.ConfigSchemaV1 {
+			return 0, fmt.Errorf("invalid %s %q, use a schema version from %d to %d", header.name, header.value, models.ConfigSchemaV1, models.ConfigSchemaLatest)
+		}
+		if version != 0 && requested != version {
+			return 0, fmt.Errorf("%s and %s request different schema versions", SchemaVersionHeader, AcceptVersionHeader)
+		}
+		version = requested
+	}
+
+	switch {
+	case version == 0:
+		return models.ConfigSchemaV1, nil
+	case version > models.ConfigSchemaLatest:
+		return models.ConfigSchemaLatest, nil
+	}
+	return version, nil
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateSchemaVersion(t *testing.T) {
+	tests := map[string]struct {
+		schemaHeader string
+		acceptHeader string
+		version      int
+		wantErr      bool
+	}{
+		"no headers":                {"", "", 1, false},
+		"schema header":             {"2", "", 2, false},
+		"accept version":            {"", "1", 1, false},
+		"v prefix":                  {"", "V2", 2, false},
+		"matching headers":          {"2", "v2", 2, false},
+		"newer than latest":         {"7", "", 2, false},
+		"not a number":              {"two", "", 0, true},
+		"zero":                      {"0", "", 0, true},
+		"negative":                  {"", "-1", 0, true},
+		"conflicting headers":       {"1", "2", 0, true},
+		"whitespace is ignored":     {" 2 ", "", 2, false},
+		"empty accept is no header": {"2", "  ", 2, false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			version, err := negotiateSchemaVersion(tt.schemaHeader, tt.acceptHeader)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.version, version)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Streaming lifts the server's read and write deadlines for routes that hold the
+// connection open: SSE streams, WebSockets and long polls. The server timeouts are meant
+// for ordinary requests and would otherwise cut these off mid-stream. Streaming handlers
+// detect dead clients with keep-alives instead.
+func Streaming() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		controller := http.NewResponseController(c.Writer)
+
+		// A hijacked connection may keep its deadlines, so both are cleared before a
+		// WebSocket upgrade too
+		for _, setDeadline := range []func(time.Time) error{controller.SetWriteDeadline, controller.SetReadDeadline} {
+			if err := setDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				log.Printf("Failed to clear connection deadline for %s: %v", c.Request.URL.Path, err)
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"log"
+	"time"
+
+	"remote-config-system/internal/cache"
+	"remote-config-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageTracker middleware counts the requests of API-key authenticated applications per
+// environment and day, for usage reports. Counts are kept in Redis and stored in the
+// database periodically, so counting never slows requests down with a database write.
+// Requests for the application's default environment count towards that environment;
+// requests not made for one environment, like batch fetches, are counted without one.
+// Without an available cache, requests are not counted.
+func UsageTracker(redisClient *cache.RedisClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("application")
+		app, ok := value.(*models.Application)
+		if redisClient == nil || !redisClient.IsAvailable() || !ok {
+			c.Next()
+			return
+		}
+
+		envSlug := c.Param("env")
+		if envSlug == "" && c.FullPath() == "/api/config" {
+			envSlug = app.DefaultEnv
+		}
+
+		// Count before handling, as streams only return once the client disconnects
+		if err := redisClient.RecordUsage(app.ID.String(), envSlug, time.Now()); err != nil {
+			log.Printf("Failed to record API key usage: %v", err)
+		}
+		c.Next()
+	}
+}
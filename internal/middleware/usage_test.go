@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"remote-config-system/internal/cache"
+	"remote-config-system/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageTracker(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	redisClient, err := cache.NewRedisClient(&cache.Config{Host: mr.Host(), Port: mr.Port()})
+	require.NoError(t, err)
+	t.Cleanup(func() { redisClient.Close() })
+
+	app := &models.Application{ID: uuid.New(), DefaultEnv: "prod"}
+	authenticate := func(c *gin.Context) {
+		if c.GetHeader("X-API-Key") != "" {
+			c.Set("application", app)
+		}
+	}
+
+	router := gin.New()
+	api := router.Group("/api", authenticate, UsageTracker(redisClient))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	api.GET("/config", ok)
+	api.GET("/config/:env", ok)
+	api.POST("/config/batch", ok)
+
+	send := func(method, path string, authenticated bool) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		if authenticated {
+			req.Header.Set("X-API-Key", "key")
+		}
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	send("GET", "/api/config/dev", true)
+	send("GET", "/api/config/dev", true)
+	send("GET", "/api/config", true)
+	send("POST", "/api/config/batch", true)
+	send("GET", "/api/config/dev", false)
+
+	usage, err := redisClient.DrainUsage()
+	require.NoError(t, err)
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Environment < usage[j].Environment })
+
+	require.Len(t, usage, 3)
+	assert.Equal(t, "", usage[0].Environment, "batch fetches are not made for one environment")
+	assert.Equal(t, int64(1), usage[0].Requests)
+	assert.Equal(t, "dev", usage[1].Environment)
+	assert.Equal(t, int64(2), usage[1].Requests, "unauthenticated requests are not counted")
+	assert.Equal(t, "prod", usage[2].Environment, "the default environment is counted")
+	assert.Equal(t, app.ID.String(), usage[2].AppID)
+}
@@ -2,6 +2,9 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,15 +19,51 @@ type Organization struct {
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// OrganizationWithApplications represents an organization along with its applications
+type OrganizationWithApplications struct {
+	Organization
+	Applications []Application `json:"applications"`
+}
+
+// OrganizationTree represents the hierarchy of an organization, as shown by navigation
+// trees: its applications, each with its environments. Levels beyond the requested depth
+// are null.
+type OrganizationTree struct {
+	ID           uuid.UUID             `json:"id"`
+	Name         string                `json:"name"`
+	Slug         string                `json:"slug"`
+	Applications []ApplicationTreeNode `json:"applications"`
+}
+
+// ApplicationTreeNode represents an application in an organization tree
+type ApplicationTreeNode struct {
+	ID           uuid.UUID             `json:"id"`
+	Name         string                `json:"name"`
+	Slug         string                `json:"slug"`
+	Environments []EnvironmentTreeNode `json:"environments"`
+}
+
+// EnvironmentTreeNode represents an environment in an organization tree
+type EnvironmentTreeNode struct {
+	ID               uuid.UUID `json:"id"`
+	Name             string    `json:"name"`
+	Slug             string    `json:"slug"`
+	RequiresApproval bool      `json:"requires_approval"`
+	Locked           bool      `json:"locked"`
+	ActiveVersion    *int      `json:"active_version"` // nil until a version is active
+}
+
 // Application represents an application within an organization
 type Application struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	OrgID     uuid.UUID `json:"org_id" db:"org_id"`
-	Name      string    `json:"name" db:"name"`
-	Slug      string    `json:"slug" db:"slug"`
-	APIKey    string    `json:"api_key" db:"api_key"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID           uuid.UUID `json:"id" db:"id"`
+	OrgID        uuid.UUID `json:"org_id" db:"org_id"`
+	Name         string    `json:"name" db:"name"`
+	Slug         string    `json:"slug" db:"slug"`
+	APIKey       string    `json:"api_key,omitempty" db:"-"`               // Only set when the key is created or rotated; a salted hash is stored
+	APIKeyPrefix string    `json:"api_key_prefix" db:"api_key_prefix"`     // Leading characters of the key, to tell keys apart
+	DefaultEnv   string    `json:"default_env,omitempty" db:"default_env"` // Environment served when a client does not name one
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 
 	// Relationships
 	Organization *Organization `json:"organization,omitempty"`
@@ -32,17 +71,40 @@ type Application struct {
 
 // Environment represents an environment for an application
 type Environment struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	AppID     uuid.UUID `json:"app_id" db:"app_id"`
-	Name      string    `json:"name" db:"name"`
-	Slug      string    `json:"slug" db:"slug"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID                uuid.UUID  `json:"id" db:"id"`
+	AppID             uuid.UUID  `json:"app_id" db:"app_id"`
+	Name              string     `json:"name" db:"name"`
+	Slug              string     `json:"slug" db:"slug"`
+	SecretKeys        []string   `json:"secret_keys" db:"secret_keys"`
+	BlobKeys          []string   `json:"blob_keys" db:"blob_keys"`                             // Dot-notated paths of base64-encoded blob values
+	RequiresApproval  bool       `json:"requires_approval" db:"requires_approval"`             // Updates must be approved before they become active
+	CacheTTLSeconds   *int       `json:"cache_ttl_seconds,omitempty" db:"cache_ttl_seconds"`   // Overrides the global cache TTL; nil uses the global TTL
+	RetentionVersions *int       `json:"retention_versions,omitempty" db:"retention_versions"` // Overrides the global number of versions kept; nil uses the global policy
+	RetentionDays     *int       `json:"retention_days,omitempty" db:"retention_days"`         // Overrides the global number of days versions are kept; nil uses the global policy
+	GoldenEnvironment *string    `json:"golden_environment,omitempty" db:"golden_environment"` // Slug of the environment whose keys updates are compared with
+	GoldenStrict      bool       `json:"golden_strict" db:"golden_strict"`                     // Updates whose keys differ from the golden environment's are rejected
+	Locked            bool       `json:"locked" db:"locked"`                                   // Configuration changes are rejected while set
+	LockReason        *string    `json:"lock_reason,omitempty" db:"lock_reason"`
+	LockedBy          *string    `json:"locked_by,omitempty" db:"locked_by"`
+	LockedAt          *time.Time `json:"locked_at,omitempty" db:"locked_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+
+	// Set only when requested with ?include=config_summary, or when the environment was
+	// created with an initial configuration
+	ConfigSummary *EnvironmentConfigSummary `json:"config_summary,omitempty"`
 
 	// Relationships
 	Application *Application `json:"application,omitempty"`
 }
 
+// EnvironmentConfigSummary summarizes the configuration versions of an environment
+type EnvironmentConfigSummary struct {
+	ActiveVersion *int       `json:"active_version"` // nil until a version is active
+	VersionCount  int        `json:"version_count"`
+	LastChangedAt *time.Time `json:"last_changed_at"` // Latest change log entry other than tag changes; nil if there is none
+}
+
 // ConfigVersion represents a version of configuration for an environment
 type ConfigVersion struct {
 	ID         uuid.UUID       `json:"id" db:"id"`
@@ -66,37 +128,710 @@ type ConfigChange struct {
 	Action      string    `json:"action" db:"action"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	CreatedBy   *string   `json:"created_by" db:"created_by"`
+	ApprovedBy  *string   `json:"approved_by,omitempty" db:"approved_by"`
+	Message     *string   `json:"message" db:"message"`   // Note explaining the change, like a commit message
+	Tag         *string   `json:"tag,omitempty" db:"tag"` // Set for tag_create, tag_move and tag_delete entries
+	Scope       string    `json:"scope" db:"scope"`       // "environment", or "defaults" for edits of the application defaults
+
+	// Set for promote entries: the environment and version the configuration was copied from
+	SourceEnv     *string `json:"source_env,omitempty" db:"source_env"`
+	SourceVersion *int    `json:"source_version,omitempty" db:"source_version"`
 
 	// Relationships
 	Environment *Environment `json:"environment,omitempty"`
 }
 
+// Change log scopes. Entries with the defaults scope are recorded for every environment
+// of the application, and their versions are those of the application defaults.
+const (
+	ChangeScopeEnvironment = "environment"
+	ChangeScopeDefaults    = "defaults"
+)
+
+// ConfigTag represents a named label pointing at a configuration version of an environment
+type ConfigTag struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	EnvID     uuid.UUID `json:"env_id" db:"env_id"`
+	Name      string    `json:"name" db:"name"`
+	Version   int       `json:"version" db:"version"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	UpdatedBy *string   `json:"updated_by" db:"updated_by"`
+}
+
+// ConfigRollout represents a gradual rollout of a configuration version. Percentage of
+// API-key clients get Version, the rest keep getting StableVersion.
+type ConfigRollout struct {
+	EnvID         uuid.UUID `json:"env_id" db:"env_id"`
+	Version       int       `json:"version" db:"version"`
+	StableVersion int       `json:"stable_version" db:"stable_version"`
+	Percentage    int       `json:"percentage" db:"percentage"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	UpdatedBy     *string   `json:"updated_by" db:"updated_by"`
+}
+
+// ApplicationDefaults represents the configuration inherited by every environment of an
+// application. Environments override the keys they set themselves.
+type ApplicationDefaults struct {
+	AppID      uuid.UUID       `json:"app_id" db:"app_id"`
+	Version    int             `json:"version" db:"version"`
+	ConfigJSON json.RawMessage `json:"config" db:"config_json"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at" db:"updated_at"`
+	UpdatedBy  *string         `json:"updated_by" db:"updated_by"`
+}
+
+// ConfigSchema represents the JSON Schema describing an environment's configuration
+type ConfigSchema struct {
+	EnvID      uuid.UUID       `json:"env_id" db:"env_id"`
+	SchemaJSON json.RawMessage `json:"schema" db:"schema_json"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// ConfigRule is a declarative check on a single configuration key. Key is a
+// dot-notated path; every other field is optional.
+type ConfigRule struct {
+	Key      string        `json:"key"`
+	Required bool          `json:"required,omitempty"`
+	Type     string        `json:"type,omitempty"`    // string, int, number, bool, object or array
+	Min      *float64      `json:"min,omitempty"`     // Inclusive lower bound for int and number keys
+	Max      *float64      `json:"max,omitempty"`     // Inclusive upper bound for int and number keys
+	Pattern  string        `json:"pattern,omitempty"` // Regular expression string values must match
+	Enum     []interface{} `json:"enum,omitempty"`
+	Message  string        `json:"message,omitempty"` // Replaces the generated violation message
+}
+
+// ConfigRuleset represents the validation rules of an environment's configuration
+type ConfigRuleset struct {
+	EnvID     uuid.UUID    `json:"env_id" db:"env_id"`
+	Rules     []ConfigRule `json:"rules" db:"rules"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// NotificationSettings represents where configuration changes of an environment are
+// announced to people
+type NotificationSettings struct {
+	EnvID           uuid.UUID `json:"env_id" db:"env_id"`
+	SlackWebhookURL string    `json:"slack_webhook_url,omitempty" db:"slack_webhook_url"` // Slack incoming webhook; empty sends no Slack message
+	EmailRecipients []string  `json:"email_recipients" db:"email_recipients"`
+	Events          []string  `json:"events" db:"events"` // Change log actions that are announced
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ConfigTemplate represents a named base configuration shared by the applications of an
+// organization. String values may contain {{variable}} placeholders.
+type ConfigTemplate struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	OrgID       uuid.UUID       `json:"org_id" db:"org_id"`
+	Name        string          `json:"name" db:"name"`
+	Description *string         `json:"description,omitempty" db:"description"`
+	Template    json.RawMessage `json:"template" db:"template"`
+	Variables   []string        `json:"variables"` // Names of the placeholders in Template, sorted
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+	UpdatedBy   *string         `json:"updated_by" db:"updated_by"`
+}
+
+// APIKeyUsage represents the requests an application made with its API key for one
+// environment on one day
+type APIKeyUsage struct {
+	AppID       uuid.UUID `json:"app_id" db:"app_id"`
+	Environment string    `json:"environment" db:"environment"` // Empty for requests not made for one environment
+	Day         time.Time `json:"day" db:"day"`
+	Requests    int64     `json:"requests" db:"request_count"`
+	LastSeenAt  time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
+
+// ApplicationUsage represents how often an application fetched configuration with its
+// API key over the last days, in total, per environment and per day
+type ApplicationUsage struct {
+	Organization string             `json:"organization"`
+	Application  string             `json:"application"`
+	Days         int                `json:"days"`
+	Requests     int64              `json:"requests"`
+	LastSeenAt   *time.Time         `json:"last_seen_at"` // Null when the key was not used in the period
+	Environments []EnvironmentUsage `json:"environments"`
+	Daily        []DailyUsage       `json:"daily"`
+}
+
+// EnvironmentUsage represents the requests of an application for one environment
+type EnvironmentUsage struct {
+	Environment string    `json:"environment"`
+	Requests    int64     `json:"requests"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+// DailyUsage represents the requests of an application on one day, per environment
+type DailyUsage struct {
+	Date         string           `json:"date"` // YYYY-MM-DD, in UTC
+	Requests     int64            `json:"requests"`
+	Environments map[string]int64 `json:"environments"`
+}
+
+// OrganizationReads represents the configuration reads of an organization in one
+// calendar month
+type OrganizationReads struct {
+	Organization string    `json:"organization"`
+	Month        time.Time `json:"month" db:"month"` // First day of the month, in UTC
+	Reads        int64     `json:"reads" db:"read_count"`
+}
+
+// OrganizationQuota represents an organization's configuration reads of the current month
+// and its monthly read quota
+type OrganizationQuota struct {
+	Organization string    `json:"organization"`
+	Month        string    `json:"month"` // YYYY-MM, in UTC
+	Reads        int64     `json:"reads"`
+	Quota        *int64    `json:"quota"`     // Null when reads are unlimited
+	Remaining    *int64    `json:"remaining"` // Null when reads are unlimited
+	Exceeded     bool      `json:"exceeded"`
+	ResetsAt     time.Time `json:"resets_at"`
+	Tracked      bool      `json:"tracked"` // False without Redis, when reads are neither counted nor limited
+}
+
+// EnvironmentActivity records when an environment's configuration last changed and when
+// it was last read
+type EnvironmentActivity struct {
+	Organization  string
+	Application   string
+	Environment   string
+	ActiveVersion int
+	CreatedAt     time.Time
+	LastChangedAt time.Time
+	LastReadAt    *time.Time // nil if no read was ever counted
+}
+
+// Reasons an environment is reported as stale
+const (
+	StaleReasonUnchanged = "unchanged" // The active configuration has not changed for longer than the threshold
+	StaleReasonUnread    = "unread"    // The configuration has not been read for longer than the threshold
+)
+
+// StaleEnvironment is an environment flagged by the stale configuration check
+type StaleEnvironment struct {
+	Organization  string     `json:"organization"`
+	Application   string     `json:"application"`
+	Environment   string     `json:"environment"`
+	ActiveVersion int        `json:"active_version"`
+	Reasons       []string   `json:"reasons"`
+	LastChangedAt time.Time  `json:"last_changed_at"`
+	LastReadAt    *time.Time `json:"last_read_at"`
+}
+
+// StaleConfigReport lists the environments flagged by the latest stale configuration check
+type StaleConfigReport struct {
+	CheckedAt     time.Time          `json:"checked_at"`
+	ThresholdDays int                `json:"threshold_days"`
+	UsageTracked  bool               `json:"usage_tracked"` // Without usage tracking, unread environments are not reported
+	Unchanged     int                `json:"unchanged"`
+	Unread        int                `json:"unread"`
+	Environments  []StaleEnvironment `json:"environments"`
+}
+
+// Admin roles, in increasing order of privilege
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+// roleLevels ranks admin roles so that higher roles include the permissions of lower ones
+var roleLevels = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// IsValidRole reports whether a role name is known
+func IsValidRole(role string) bool {
+	_, ok := roleLevels[role]
+	return ok
+}
+
+// RoleAllows reports whether a role grants at least the permissions of the required role
+func RoleAllows(role, required string) bool {
+	return IsValidRole(role) && roleLevels[role] >= roleLevels[required]
+}
+
+// MaxSlugLength is the maximum length of organization, application and environment slugs
+const MaxSlugLength = 50
+
+// slugPattern matches groups of lowercase letters and digits separated by single hyphens
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ValidateSlug checks that a slug can be used as is in URL paths and cache keys
+func ValidateSlug(slug string) error {
+	if slug == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	if len(slug) > MaxSlugLength {
+		return fmt.Errorf("must be at most %d characters long", MaxSlugLength)
+	}
+	if !slugPattern.MatchString(slug) {
+		return fmt.Errorf("must contain only lowercase letters, digits and hyphens, and start and end with a letter or digit")
+	}
+	return nil
+}
+
+// Pending change statuses
+const (
+	PendingStatusPending  = "pending"
+	PendingStatusApproved = "approved"
+	PendingStatusRejected = "rejected"
+)
+
+// PendingChange represents a proposed configuration version awaiting approval
+type PendingChange struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	EnvID         uuid.UUID  `json:"env_id" db:"env_id"`
+	Version       int        `json:"version" db:"version"`
+	BaseVersion   *int       `json:"base_version" db:"base_version"` // Active version when the change was proposed
+	Status        string     `json:"status" db:"status"`
+	ProposedBy    *string    `json:"proposed_by" db:"proposed_by"`
+	Message       *string    `json:"message" db:"message"` // The proposer's note, logged with the change once approved
+	ReviewedBy    *string    `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewComment *string    `json:"review_comment,omitempty" db:"review_comment"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+}
+
+// AdminToken represents a credential for the admin API. The token itself is only
+// returned once, when it is created; only its hash is stored.
+type AdminToken struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Role      string    `json:"role" db:"role"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateAdminTokenRequest represents a request to create an admin token
+type CreateAdminTokenRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=255"`
+	Role string `json:"role" binding:"required"`
+}
+
+// CreateAdminTokenResponse represents a newly created admin token, including its secret value
+type CreateAdminTokenResponse struct {
+	AdminToken
+	Token string `json:"token"`
+}
+
 // ConfigResponse represents the response structure for configuration API
 type ConfigResponse struct {
+	Organization    string          `json:"organization"`
+	Application     string          `json:"application"`
+	Environment     string          `json:"environment"`
+	Version         int             `json:"version"`
+	VersionID       *uuid.UUID      `json:"version_id,omitempty"` // ID of the configuration version; unset for dry runs
+	Config          json.RawMessage `json:"config"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	DryRun          bool            `json:"dry_run,omitempty"`
+	Unchanged       bool            `json:"unchanged,omitempty"` // Set when an update matched the active version, which was kept
+	Diff            *ConfigDiff     `json:"diff,omitempty"`
+	Pending         *PendingChange  `json:"pending,omitempty"`          // Set when the update awaits approval instead of being active
+	Tag             string          `json:"tag,omitempty"`              // Set when the configuration was resolved through a tag
+	At              *time.Time      `json:"at,omitempty"`               // Set when the configuration was read at a point in time
+	Rollout         *ConfigRollout  `json:"rollout,omitempty"`          // Set when the update started a gradual rollout
+	DefaultsVersion int             `json:"defaults_version,omitempty"` // Set when application defaults were merged into Config
+	BlobKeys        []string        `json:"blob_keys,omitempty"`        // Paths of base64-encoded blob values in Config
+	Golden          *GoldenCheck    `json:"golden,omitempty"`           // Set by updates of environments that have a golden environment
+	Meta            *ConfigMeta     `json:"meta,omitempty"`             // Set on configuration reads with ?meta=true
+	// Versions of the other environments that references in Config were resolved from
+	References []ConfigReferenceSource `json:"references,omitempty"`
+	// Top-level keys of Config whose values were decrypted, for masking them on public reads
+	SecretKeys []string `json:"-"`
+}
+
+// ConfigMeta describes who created a served configuration version and when
+type ConfigMeta struct {
+	CreatedBy *string   `json:"created_by"` // nil when the version was created without a name
+	CreatedAt time.Time `json:"created_at"`
+	Age       string    `json:"age"` // Human-readable time since CreatedAt, e.g. "3 days"
+}
+
+// GoldenCheck compares the keys of a configuration update with the keys of the active
+// configuration of the environment's golden environment. Keys are dot-notated paths of
+// the values in the configurations merged with the application defaults.
+type GoldenCheck struct {
+	Environment string   `json:"environment"`  // Golden environment
+	Version     int      `json:"version"`      // Active version of the golden environment
+	Strict      bool     `json:"strict"`       // Updates with missing or extra keys are rejected
+	MissingKeys []string `json:"missing_keys"` // Keys of the golden environment the update does not have
+	ExtraKeys   []string `json:"extra_keys"`   // Keys of the update the golden environment does not have
+}
+
+// ConfigReferenceSource names the version of an environment that referenced values were
+// taken from
+type ConfigReferenceSource struct {
+	Environment string `json:"environment"`
+	Version     int    `json:"version"`
+}
+
+// Configuration response schema versions. Clients pick one with the X-Config-Schema-Version
+// or Accept-Version header; clients that send neither get ConfigSchemaV1.
+const (
+	ConfigSchemaV1     = 1
+	ConfigSchemaV2     = 2
+	ConfigSchemaLatest = ConfigSchemaV2
+)
+
+// ConfigResponseV1 is the configuration response served to clients of schema version 1.
+// Its fields are frozen: new fields are added to ConfigResponse and served from version 2
+// on, so that older SDKs keep working. Meta and At are exceptions, as clients only get
+// them by asking for them with ?meta=true or ?at. So is Unchanged, as clients of every
+// version need to tell that an update created no version.
+type ConfigResponseV1 struct {
+	Organization    string          `json:"organization"`
+	Application     string          `json:"application"`
+	Environment     string          `json:"environment"`
+	Version         int             `json:"version"`
+	Config          json.RawMessage `json:"config"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	DryRun          bool            `json:"dry_run,omitempty"`
+	Unchanged       bool            `json:"unchanged,omitempty"`
+	Diff            *ConfigDiff     `json:"diff,omitempty"`
+	Pending         *PendingChange  `json:"pending,omitempty"`
+	Tag             string          `json:"tag,omitempty"`
+	At              *time.Time      `json:"at,omitempty"`
+	Rollout         *ConfigRollout  `json:"rollout,omitempty"`
+	DefaultsVersion int             `json:"defaults_version,omitempty"`
+	Meta            *ConfigMeta     `json:"meta,omitempty"`
+}
+
+// ConfigResponseV2 is the configuration response served to clients of schema version 2
+type ConfigResponseV2 struct {
+	SchemaVersion int `json:"schema_version"`
+	*ConfigResponse
+	ContentHash string `json:"content_hash"` // Hex SHA-256 of the compacted configuration document
+}
+
+// BatchConfigRequest represents a request to fetch the configurations of several environments
+type BatchConfigRequest struct {
+	Environments []string `json:"environments" binding:"required"`
+}
+
+// BatchConfigError describes why a single environment of a batch could not be fetched
+type BatchConfigError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// BatchConfigResponse represents the configurations of several environments, keyed by environment slug
+type BatchConfigResponse struct {
+	Configs map[string]*ConfigResponse  `json:"configs"`
+	Errors  map[string]BatchConfigError `json:"errors"`
+}
+
+// BatchUpdateConfigRequest represents a request to update the configurations of several
+// environments at once, either keyed by environment slug or, for the environments a label
+// selector picks, with the same configuration for each
+type BatchUpdateConfigRequest struct {
+	Configs   map[string]json.RawMessage `json:"configs"`
+	Config    json.RawMessage            `json:"config"` // Used with a label selector instead of Configs
+	CreatedBy *string                    `json:"created_by"`
+	Message   *string                    `json:"message" binding:"omitempty,max=1000"` // Logged with the change of every environment
+}
+
+// CreateConfigRequest represents a request to create/update configuration
+type CreateConfigRequest struct {
+	Config            json.RawMessage `json:"config" binding:"required"`
+	CreatedBy         *string         `json:"created_by"`
+	Message           *string         `json:"message" binding:"omitempty,max=1000"`                 // Logged with the change, e.g. "bumped timeout for Black Friday"
+	RolloutPercentage *int            `json:"rollout_percentage" binding:"omitempty,min=1,max=100"` // Roll the version out to this share of clients first
+	AllowUnchanged    bool            `json:"allow_unchanged"`                                      // Create a version even when the configuration is unchanged
+	AdminToken        *string         `json:"-"`                                                    // Name of the authenticated admin token, set by the handler; it proposes changes that need approval
+}
+
+// CompareAndSwapRequest represents a request to set a single key of the active
+// configuration only if its current value is the expected one. An omitted Expected
+// requires the key to be absent; a JSON null requires it to be null.
+type CompareAndSwapRequest struct {
+	Key       string          `json:"key" binding:"required"` // Dot-notated path, e.g. "leader.holder"
+	Expected  json.RawMessage `json:"expected,omitempty"`
+	Value     json.RawMessage `json:"value" binding:"required"`
+	CreatedBy *string         `json:"created_by"`
+	Message   *string         `json:"message" binding:"omitempty,max=1000"` // Logged with the change
+}
+
+// DiffDraftRequest represents a request to preview a draft configuration against the active version
+type DiffDraftRequest struct {
+	Config json.RawMessage `json:"config" binding:"required"`
+}
+
+// ConfigDiffEntry represents a single changed path between two configurations
+type ConfigDiffEntry struct {
+	Path     string      `json:"path"`
+	Type     string      `json:"type"` // "added", "removed", "changed"
+	OldValue interface{} `json:"old_value,omitempty"`
+	NewValue interface{} `json:"new_value,omitempty"`
+}
+
+// ConfigDiff represents the differences between two configurations
+type ConfigDiff struct {
+	HasChanges bool              `json:"has_changes"`
+	Added      int               `json:"added"`
+	Removed    int               `json:"removed"`
+	Changed    int               `json:"changed"`
+	Entries    []ConfigDiffEntry `json:"entries"`
+}
+
+// ConfigDiffResponse represents the response structure for configuration diff endpoints
+type ConfigDiffResponse struct {
+	Organization string     `json:"organization"`
+	Application  string     `json:"application"`
+	Environment  string     `json:"environment"`
+	FromVersion  int        `json:"from_version"`
+	Diff         ConfigDiff `json:"diff"`
+}
+
+// LintConfigRequest represents a request to check a configuration for common mistakes
+type LintConfigRequest struct {
+	Config json.RawMessage `json:"config" binding:"required"`
+}
+
+// LintWarning describes a likely mistake found in a configuration
+type LintWarning struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"` // "error" or "warning"
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ConfigLintResponse represents the warnings found in a configuration that was not saved
+type ConfigLintResponse struct {
+	Organization string        `json:"organization"`
+	Application  string        `json:"application"`
+	Environment  string        `json:"environment"`
+	Production   bool          `json:"production"` // Whether the environment is treated as production
+	Errors       int           `json:"errors"`
+	Warnings     []LintWarning `json:"warnings"`
+}
+
+// ValidationIssue describes a single failed validation check
+type ValidationIssue struct {
+	Check   string `json:"check"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationResult represents the outcome of running the validation pipeline on a configuration
+type ValidationResult struct {
+	Valid  bool              `json:"valid"`
+	Errors []ValidationIssue `json:"errors"`
+}
+
+// ManifestItem represents a single proposed configuration in a validation manifest
+type ManifestItem struct {
+	Organization string          `json:"org" binding:"required"`
+	Application  string          `json:"app" binding:"required"`
+	Environment  string          `json:"env" binding:"required"`
+	Config       json.RawMessage `json:"config" binding:"required"`
+}
+
+// ManifestRequest is the body of a manifest validation request. The body is a bare JSON
+// array of items; wrapping it lets each item be validated, with errors naming its index.
+type ManifestRequest struct {
+	Items []ManifestItem `json:"manifest" binding:"required,min=1,dive"`
+}
+
+// UnmarshalJSON decodes the JSON array of manifest items
+func (r *ManifestRequest) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &r.Items)
+}
+
+// ManifestItemResult represents the validation result for a single manifest item
+type ManifestItemResult struct {
+	Organization string            `json:"org"`
+	Application  string            `json:"app"`
+	Environment  string            `json:"env"`
+	Status       int               `json:"status"`
+	Valid        bool              `json:"valid"`
+	Errors       []ValidationIssue `json:"errors"`
+}
+
+// ManifestValidationResponse represents the response for bulk manifest validation
+type ManifestValidationResponse struct {
+	Valid   bool                 `json:"valid"`
+	Results []ManifestItemResult `json:"results"`
+}
+
+// FlagSet represents the boolean top-level keys of an environment's active configuration
+type FlagSet struct {
+	Version         int             `json:"version"`
+	DefaultsVersion int             `json:"defaults_version,omitempty"` // Set when application defaults were merged into the configuration
+	Flags           map[string]bool `json:"flags"`
+}
+
+// ConfigSearchResult represents an environment whose active configuration matches a search
+type ConfigSearchResult struct {
+	Organization string    `json:"organization"`
+	Application  string    `json:"application"`
+	Environment  string    `json:"environment"`
+	Version      int       `json:"version"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ActiveConfig is the active configuration version of an environment along with the
+// slugs and application it is served under, as loaded for cache warming
+type ActiveConfig struct {
 	Organization string          `json:"organization"`
 	Application  string          `json:"application"`
 	Environment  string          `json:"environment"`
+	AppID        uuid.UUID       `json:"-"`
+	BlobKeys     []string        `json:"blob_keys,omitempty"`
+	VersionID    uuid.UUID       `json:"version_id"`
 	Version      int             `json:"version"`
-	Config       json.RawMessage `json:"config"`
-	UpdatedAt    time.Time       `json:"updated_at"`
+	ConfigJSON   json.RawMessage `json:"config_json"`
+	CreatedAt    time.Time       `json:"created_at"`
+	HasRollout   bool            `json:"has_rollout"`
+	// Application defaults, if the application has any
+	DefaultsJSON    json.RawMessage `json:"defaults_json,omitempty"`
+	DefaultsVersion int             `json:"defaults_version,omitempty"`
 }
 
-// CreateConfigRequest represents a request to create/update configuration
-type CreateConfigRequest struct {
+// UpdateConfigSchemaRequest represents a request to set an environment's configuration schema
+type UpdateConfigSchemaRequest struct {
+	Schema json.RawMessage `json:"schema" binding:"required"`
+}
+
+// UpdateApplicationDefaultsRequest represents a request to set an application's default configuration
+type UpdateApplicationDefaultsRequest struct {
 	Config    json.RawMessage `json:"config" binding:"required"`
-	CreatedBy *string         `json:"created_by"`
+	UpdatedBy *string         `json:"updated_by"`
+}
+
+// UpdateConfigRulesRequest represents a request to set an environment's validation rules
+type UpdateConfigRulesRequest struct {
+	Rules []ConfigRule `json:"rules" binding:"required"`
+}
+
+// UpdateNotificationSettingsRequest represents a request to set an environment's
+// notification settings. Omitted events announce every supported change.
+type UpdateNotificationSettingsRequest struct {
+	SlackWebhookURL string   `json:"slack_webhook_url"`
+	EmailRecipients []string `json:"email_recipients"`
+	Events          []string `json:"events"`
+}
+
+// SetConfigTemplateRequest represents a request to create a configuration template or
+// replace an existing one
+type SetConfigTemplateRequest struct {
+	Description *string         `json:"description,omitempty" binding:"omitempty,max=1000"`
+	Template    json.RawMessage `json:"template" binding:"required"`
+	UpdatedBy   *string         `json:"updated_by"`
+}
+
+// InstantiateTemplateRequest represents a request to store a configuration template, with
+// its variables substituted, as a new configuration version of an environment
+type InstantiateTemplateRequest struct {
+	Template          string                     `json:"template" binding:"required"`
+	Variables         map[string]json.RawMessage `json:"variables"`
+	CreatedBy         *string                    `json:"created_by"`
+	Message           *string                    `json:"message" binding:"omitempty,max=1000"` // Logged with the change
+	RolloutPercentage *int                       `json:"rollout_percentage" binding:"omitempty,min=1,max=100"`
+	AdminToken        *string                    `json:"-"` // Name of the authenticated admin token, set by the handler
+}
+
+// FormField describes a single editable configuration value derived from a JSON Schema
+type FormField struct {
+	Path        string        `json:"path"`
+	Type        string        `json:"type,omitempty"`
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required"`
+	Secret      bool          `json:"secret,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Value       interface{}   `json:"value"`
+}
+
+// FormSchemaResponse represents an environment's schema combined with its current values
+type FormSchemaResponse struct {
+	Organization string          `json:"organization"`
+	Application  string          `json:"application"`
+	Environment  string          `json:"environment"`
+	Version      int             `json:"version"`
+	Schema       json.RawMessage `json:"schema"`
+	Fields       []FormField     `json:"fields"`
+}
+
+// ReviewChangeRequest represents a request to approve or reject a pending change
+type ReviewChangeRequest struct {
+	ChangeID   uuid.UUID `json:"change_id" binding:"required"`
+	ReviewedBy *string   `json:"reviewed_by"`
+	Comment    *string   `json:"comment"`
+	AdminToken *string   `json:"-"` // Name of the authenticated admin token, set by the handler; nil when admin auth is disabled
 }
 
-// RollbackRequest represents a request to rollback configuration
+// SetTagRequest represents a request to create a tag or move it to another version
+type SetTagRequest struct {
+	Version   int     `json:"version" binding:"required,min=1"`
+	UpdatedBy *string `json:"updated_by"`
+}
+
+// SetLabelRequest represents a request to set a label of an environment
+type SetLabelRequest struct {
+	Value string `json:"value" binding:"required"`
+}
+
+// EnvironmentLabels represents the labels of an environment
+type EnvironmentLabels struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// Label selector operators
+const (
+	LabelEquals    = "="
+	LabelNotEquals = "!="
+	LabelExists    = "exists"
+)
+
+// LabelRequirement is one comma-separated requirement of a label selector, e.g.
+// tier=canary, region!=eu or a bare key that the label must exist
+type LabelRequirement struct {
+	Key      string
+	Operator string
+	Value    string // Unset for LabelExists
+}
+
+// UpdateRolloutRequest represents a request to change the share of clients of a rollout
+type UpdateRolloutRequest struct {
+	Percentage *int    `json:"percentage" binding:"required,min=0,max=100"`
+	UpdatedBy  *string `json:"updated_by"`
+}
+
+// PromoteRolloutRequest represents a request to roll a version out to every client
+type PromoteRolloutRequest struct {
+	UpdatedBy *string `json:"updated_by"`
+}
+
+// RollbackRequest represents a request to rollback configuration. Exactly one of
+// ToVersion and ToTag names the version to roll back to.
 type RollbackRequest struct {
-	ToVersion int     `json:"to_version" binding:"required"`
+	ToVersion int     `json:"to_version,omitempty" binding:"omitempty,min=1"`
+	ToTag     string  `json:"to_tag,omitempty"` // Rolls back to the version the tag points at
 	CreatedBy *string `json:"created_by"`
+	Message   *string `json:"message" binding:"omitempty,max=1000"` // Logged with the change
+}
+
+// PromoteConfigRequest represents a request to copy the active configuration of another
+// environment of the same application into an environment
+type PromoteConfigRequest struct {
+	Source     string   `json:"source" binding:"required,slug"`
+	Keys       []string `json:"keys,omitempty" binding:"omitempty,dive,min=1"` // Only promote these top-level keys; the others keep their target values
+	CreatedBy  *string  `json:"created_by"`
+	Message    *string  `json:"message" binding:"omitempty,max=1000"` // Logged with the change
+	AdminToken *string  `json:"-"`                                    // Name of the authenticated admin token, set by the handler
 }
 
 // CreateOrganizationRequest represents a request to create an organization
 type CreateOrganizationRequest struct {
 	Name string `json:"name" binding:"required,min=1,max=100"`
-	Slug string `json:"slug" binding:"required,min=1,max=50,alphanum"`
+	Slug string `json:"slug" binding:"required,slug"`
 }
 
 // UpdateOrganizationRequest represents a request to update an organization
@@ -107,24 +842,152 @@ type UpdateOrganizationRequest struct {
 // CreateApplicationRequest represents a request to create an application
 type CreateApplicationRequest struct {
 	Name   string `json:"name" binding:"required,min=1,max=100"`
-	Slug   string `json:"slug" binding:"required,min=1,max=50,alphanum"`
+	Slug   string `json:"slug" binding:"required,slug"`
 	APIKey string `json:"api_key,omitempty"`
 }
 
 // UpdateApplicationRequest represents a request to update an application
 type UpdateApplicationRequest struct {
-	Name string `json:"name" binding:"required,min=1,max=100"`
+	Name       string  `json:"name" binding:"required,min=1,max=100"`
+	DefaultEnv *string `json:"default_env,omitempty" binding:"omitempty,max=50"` // nil leaves the default unchanged, "" clears it
 }
 
 // CreateEnvironmentRequest represents a request to create an environment
 type CreateEnvironmentRequest struct {
-	Name string `json:"name" binding:"required,min=1,max=100"`
-	Slug string `json:"slug" binding:"required,min=1,max=50,alphanum"`
+	Name              string   `json:"name" binding:"required,min=1,max=100"`
+	Slug              string   `json:"slug" binding:"required,slug"`
+	SecretKeys        []string `json:"secret_keys,omitempty" binding:"omitempty,dive,min=1"`
+	BlobKeys          []string `json:"blob_keys,omitempty" binding:"omitempty,dive,min=1"`
+	RequiresApproval  bool     `json:"requires_approval,omitempty"`
+	CacheTTLSeconds   *int     `json:"cache_ttl_seconds,omitempty" binding:"omitempty,min=1,max=86400"`
+	RetentionVersions *int     `json:"retention_versions,omitempty" binding:"omitempty,min=1,max=100000"`
+	RetentionDays     *int     `json:"retention_days,omitempty" binding:"omitempty,min=1,max=36500"`
+	GoldenEnvironment *string  `json:"golden_environment,omitempty" binding:"omitempty,slug"`
+	GoldenStrict      bool     `json:"golden_strict,omitempty"`
+
+	// Config is the initial configuration, created as version 1 along with the environment
+	Config    json.RawMessage `json:"config,omitempty"`
+	CreatedBy *string         `json:"created_by,omitempty"` // Creator of the initial configuration
+}
+
+// BulkEnvironmentItem represents one environment of a bulk creation request, with an
+// optional initial configuration
+type BulkEnvironmentItem struct {
+	CreateEnvironmentRequest
+}
+
+// BulkCreateEnvironmentsRequest represents a request to create several environments at once
+type BulkCreateEnvironmentsRequest struct {
+	Environments []BulkEnvironmentItem `json:"environments" binding:"required,min=1,max=50,dive"`
+	CreatedBy    *string               `json:"created_by,omitempty"`
+}
+
+// BulkEnvironmentResult represents the outcome of creating a single environment in bulk
+type BulkEnvironmentResult struct {
+	Slug        string       `json:"slug"`
+	Status      int          `json:"status"`
+	Created     bool         `json:"created"`
+	Environment *Environment `json:"environment,omitempty"`
+	Version     int          `json:"version,omitempty"` // Version of the initial configuration, if one was given
+	Error       string       `json:"error,omitempty"`
+}
+
+// BulkCreateEnvironmentsResponse represents the response for bulk environment creation
+type BulkCreateEnvironmentsResponse struct {
+	Created int                     `json:"created"`
+	Failed  int                     `json:"failed"`
+	Results []BulkEnvironmentResult `json:"results"`
 }
 
 // UpdateEnvironmentRequest represents a request to update an environment
 type UpdateEnvironmentRequest struct {
-	Name string `json:"name" binding:"required,min=1,max=100"`
+	Name              string   `json:"name" binding:"required,min=1,max=100"`
+	SecretKeys        []string `json:"secret_keys,omitempty" binding:"omitempty,dive,min=1"`              // nil leaves the secret keys unchanged
+	BlobKeys          []string `json:"blob_keys,omitempty" binding:"omitempty,dive,min=1"`                // nil leaves the blob keys unchanged
+	RequiresApproval  *bool    `json:"requires_approval,omitempty"`                                       // nil leaves the setting unchanged
+	CacheTTLSeconds   *int     `json:"cache_ttl_seconds,omitempty" binding:"omitempty,min=0,max=86400"`   // nil leaves the TTL unchanged; 0 restores the global TTL
+	RetentionVersions *int     `json:"retention_versions,omitempty" binding:"omitempty,min=0,max=100000"` // nil leaves the setting unchanged; 0 restores the global policy
+	RetentionDays     *int     `json:"retention_days,omitempty" binding:"omitempty,min=0,max=36500"`      // nil leaves the setting unchanged; 0 restores the global policy
+	GoldenEnvironment *string  `json:"golden_environment,omitempty" binding:"omitempty,slug"`             // nil leaves the golden environment unchanged; "" removes it
+	GoldenStrict      *bool    `json:"golden_strict,omitempty"`                                           // nil leaves the setting unchanged
+}
+
+// LockEnvironmentRequest represents a request to lock an environment against configuration changes
+type LockEnvironmentRequest struct {
+	Reason   *string `json:"reason,omitempty" binding:"omitempty,max=1000"`
+	LockedBy *string `json:"locked_by,omitempty" binding:"omitempty,max=255"` // Defaults to the name of the admin token making the request
+}
+
+// PruneHistoryResponse represents the result of applying an environment's retention policy
+type PruneHistoryResponse struct {
+	Organization      string `json:"organization"`
+	Application       string `json:"application"`
+	Environment       string `json:"environment"`
+	RetentionVersions int    `json:"retention_versions"` // Latest versions kept; 0 keeps none by count
+	RetentionDays     int    `json:"retention_days"`     // Days versions are kept; 0 keeps none by age
+	Pruned            int    `json:"pruned"`
+}
+
+// KeyVersionValue is the value of a configuration key in one version
+type KeyVersionValue struct {
+	Version   int
+	CreatedAt time.Time
+	CreatedBy *string
+	Value     json.RawMessage // nil if the key is absent
+}
+
+// KeyHistoryEntry is a value a configuration key took, from the version that set it
+type KeyHistoryEntry struct {
+	Version   int         `json:"version"`
+	Present   bool        `json:"present"` // false from the version that removed the key
+	Value     interface{} `json:"value"`
+	ChangedAt time.Time   `json:"changed_at"`
+	ChangedBy *string     `json:"changed_by"`
+}
+
+// KeyHistoryResponse represents the values a configuration key took across the stored
+// versions of an environment
+type KeyHistoryResponse struct {
+	Organization string            `json:"organization"`
+	Application  string            `json:"application"`
+	Environment  string            `json:"environment"`
+	Key          string            `json:"key"`
+	Changes      []KeyHistoryEntry `json:"changes"` // Oldest first
+}
+
+// Cache warming statuses
+const (
+	CacheWarmStatusDisabled   = "disabled"
+	CacheWarmStatusPending    = "pending"
+	CacheWarmStatusInProgress = "in_progress"
+	CacheWarmStatusCompleted  = "completed"
+	CacheWarmStatusFailed     = "failed"
+)
+
+// CacheWarmStatus represents the progress of the cache warming run at startup
+type CacheWarmStatus struct {
+	Status         string     `json:"status"`
+	Configurations int        `json:"configurations"` // Configurations warmed once completed
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	Error          string     `json:"error,omitempty"`
+}
+
+// EncryptSecretsResponse represents the result of encrypting existing plaintext secret values
+type EncryptSecretsResponse struct {
+	Organization      string   `json:"organization"`
+	Application       string   `json:"application"`
+	Environment       string   `json:"environment"`
+	SecretKeys        []string `json:"secret_keys"`
+	VersionsScanned   int      `json:"versions_scanned"`
+	VersionsEncrypted int      `json:"versions_encrypted"`
+}
+
+// ReencryptSecretsResponse represents the result of re-encrypting secret values with the primary key
+type ReencryptSecretsResponse struct {
+	PrimaryKeyID        string `json:"primary_key_id"`
+	VersionsScanned     int    `json:"versions_scanned"`
+	VersionsReencrypted int    `json:"versions_reencrypted"`
 }
 
 // HealthResponse represents the health check response
@@ -137,16 +1000,29 @@ type HealthResponse struct {
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Error     string    `json:"error"`
-	Message   string    `json:"message"`
-	Timestamp time.Time `json:"timestamp"`
-	Path      string    `json:"path,omitempty"`
+	Error     string       `json:"error"`
+	Message   string       `json:"message"`
+	Timestamp time.Time    `json:"timestamp"`
+	Path      string       `json:"path,omitempty"`
+	Resource  string       `json:"resource,omitempty"` // Type of the resource whose slug is already in use
+	Slug      string       `json:"slug,omitempty"`     // Slug already in use
+	Fields    []FieldError `json:"fields,omitempty"`   // Request fields that failed validation
+}
+
+// FieldError describes a request field that failed validation
+type FieldError struct {
+	Field      string `json:"field"`      // Field name as sent by the client, e.g. "environments[1].slug"
+	Constraint string `json:"constraint"` // Violated constraint, e.g. "required" or "max=100"
+	Message    string `json:"message"`
 }
 
+// MaxPageSize is the largest page a list request returns
+const MaxPageSize = 100
+
 // PaginationParams represents pagination parameters
 type PaginationParams struct {
-	Page     int `form:"page" binding:"min=1"`
-	PageSize int `form:"page_size" binding:"min=1,max=100"`
+	Page     int `form:"page"`
+	PageSize int `form:"page_size"`
 }
 
 // DefaultPaginationParams returns default pagination parameters
@@ -157,6 +1033,14 @@ func DefaultPaginationParams() PaginationParams {
 	}
 }
 
+// Clamp brings out-of-range parameters within bounds: page starts at 1 and
+// page_size is between 1 and MaxPageSize
+func (p PaginationParams) Clamp() PaginationParams {
+	p.Page = max(p.Page, 1)
+	p.PageSize = min(max(p.PageSize, 1), MaxPageSize)
+	return p
+}
+
 // Offset calculates the offset for database queries
 func (p PaginationParams) Offset() int {
 	return (p.Page - 1) * p.PageSize
@@ -171,8 +1055,12 @@ type PaginatedResponse struct {
 	TotalPages int         `json:"total_pages"`
 }
 
-// NewPaginatedResponse creates a new paginated response
+// NewPaginatedResponse creates a new paginated response. A page without items is sent
+// as an empty array rather than null, and a collection without items has 0 pages.
 func NewPaginatedResponse(data interface{}, page, pageSize, totalCount int) PaginatedResponse {
+	if value := reflect.ValueOf(data); value.Kind() == reflect.Slice && value.IsNil() {
+		data = reflect.MakeSlice(value.Type(), 0, 0).Interface()
+	}
 	totalPages := (totalCount + pageSize - 1) / pageSize
 	return PaginatedResponse{
 		Data:       data,
@@ -195,7 +1083,34 @@ type ConfigUpdateEvent struct {
 	Application  string          `json:"application"`
 	Environment  string          `json:"environment"`
 	Version      int             `json:"version"`
-	Config       json.RawMessage `json:"config"`
-	Action       string          `json:"action"` // "update", "rollback"
+	Config       json.RawMessage `json:"config"` // Merged with the application defaults
+	Action       string          `json:"action"` // "create", "update", "rollback", "promote", or "defaults_create", "defaults_update", "defaults_delete"
 	UpdatedAt    time.Time       `json:"updated_at"`
+
+	DefaultsVersion int `json:"defaults_version,omitempty"` // Version of the application defaults merged into Config
+
+	ChangedKeys []string `json:"changed_keys,omitempty"` // Dot-notated paths changed since the previous update, when known
+
+	// Config with its secret values still encrypted instead of masked. When set, the
+	// changed keys are computed from it, so that a changed secret counts as a change.
+	Stored json.RawMessage `json:"-"`
+}
+
+// ConfigEventHistoryResponse represents the most recent configuration updates of an environment
+type ConfigEventHistoryResponse struct {
+	Organization string              `json:"organization"`
+	Application  string              `json:"application"`
+	Environment  string              `json:"environment"`
+	Events       []ConfigUpdateEvent `json:"events"` // Oldest first
+}
+
+// TagUpdateEvent represents an SSE event sent when a tag is created, moved or deleted
+type TagUpdateEvent struct {
+	Organization string    `json:"organization"`
+	Application  string    `json:"application"`
+	Environment  string    `json:"environment"`
+	Tag          string    `json:"tag"`
+	Version      int       `json:"version"`
+	Action       string    `json:"action"` // "tag_create", "tag_move", "tag_delete"
+	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -223,6 +224,40 @@ func TestPaginationParams_Validation(t *testing.T) {
 		offset := params.Offset()
 		assert.Equal(t, 20, offset) // (3-1) * 10 = 20
 	})
+
+	t.Run("out-of-range params are clamped", func(t *testing.T) {
+		assert.Equal(t, PaginationParams{Page: 1, PageSize: MaxPageSize}, PaginationParams{Page: 0, PageSize: 500}.Clamp())
+		assert.Equal(t, PaginationParams{Page: 1, PageSize: 1}, PaginationParams{Page: -3, PageSize: -1}.Clamp())
+		assert.Equal(t, PaginationParams{Page: 4, PageSize: 50}, PaginationParams{Page: 4, PageSize: 50}.Clamp())
+	})
+}
+
+func TestNewPaginatedResponse(t *testing.T) {
+	t.Run("pages are rounded up", func(t *testing.T) {
+		response := NewPaginatedResponse([]Organization{{Slug: "acme"}}, 1, 10, 21)
+		assert.Equal(t, 3, response.TotalPages)
+		assert.Equal(t, 21, response.TotalCount)
+	})
+
+	t.Run("empty collection", func(t *testing.T) {
+		var orgs []Organization
+		response := NewPaginatedResponse(orgs, 1, 20, 0)
+		assert.Equal(t, 0, response.TotalPages)
+
+		data, err := json.Marshal(response)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"data": [], "page": 1, "page_size": 20, "total_count": 0, "total_pages": 0}`, string(data))
+	})
+
+	t.Run("page past the last one", func(t *testing.T) {
+		var rows []map[string]interface{}
+		response := NewPaginatedResponse(rows, 4, 10, 15)
+		assert.Equal(t, 2, response.TotalPages)
+
+		data, err := json.Marshal(response)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"data":[]`)
+	})
 }
 
 func TestErrorResponse_Structure(t *testing.T) {
@@ -295,3 +330,38 @@ func TestSSEMessage_Structure(t *testing.T) {
 		assert.Equal(t, "2 hours", data["duration"])
 	})
 }
+
+func TestRoleAllows(t *testing.T) {
+	assert.True(t, RoleAllows(RoleAdmin, RoleEditor))
+	assert.True(t, RoleAllows(RoleEditor, RoleEditor))
+	assert.True(t, RoleAllows(RoleEditor, RoleViewer))
+	assert.False(t, RoleAllows(RoleViewer, RoleEditor))
+	assert.False(t, RoleAllows(RoleEditor, RoleAdmin))
+	assert.False(t, RoleAllows("owner", RoleViewer))
+
+	assert.True(t, IsValidRole(RoleViewer))
+	assert.False(t, IsValidRole("owner"))
+}
+
+func TestValidateSlug(t *testing.T) {
+	for _, slug := range []string{"prod", "demo-org", "web-2", "a", strings.Repeat("a", MaxSlugLength)} {
+		assert.NoError(t, ValidateSlug(slug), slug)
+	}
+
+	tests := map[string]string{
+		"":                                   "must not be empty",
+		strings.Repeat("a", MaxSlugLength+1): "at most 50 characters",
+		"foo/bar":                            "lowercase letters, digits and hyphens",
+		"foo bar":                            "lowercase letters, digits and hyphens",
+		"Prod":                               "lowercase letters, digits and hyphens",
+		"org:app":                            "lowercase letters, digits and hyphens",
+		"-prod":                              "start and end with a letter or digit",
+		"prod-":                              "start and end with a letter or digit",
+	}
+
+	for slug, expected := range tests {
+		err := ValidateSlug(slug)
+		require.Error(t, err, slug)
+		assert.Contains(t, err.Error(), expected, slug)
+	}
+}
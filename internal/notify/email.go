@@ -0,0 +1,134 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"remote-config-system/internal/models"
+)
+
+// EmailNotifier sends messages by email over SMTP
+type EmailNotifier struct {
+	config *Config
+
+	// send delivers an encoded email; replaced in tests
+	send func(ctx context.Context, from string, to []string, email []byte) error
+}
+
+// NewEmailNotifier creates an email notifier for the configured SMTP server
+func NewEmailNotifier(config *Config) *EmailNotifier {
+	n := &EmailNotifier{config: config}
+	n.send = n.sendSMTP
+	return n
+}
+
+// Check reports whether the recipients in settings are valid email addresses
+func (n *EmailNotifier) Check(settings *models.NotificationSettings) error {
+	for _, recipient := range settings.EmailRecipients {
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return fmt.Errorf("invalid email recipient %q", recipient)
+		}
+	}
+	return nil
+}
+
+// Notify emails a message to the recipients in settings
+func (n *EmailNotifier) Notify(ctx context.Context, settings *models.NotificationSettings, message *Message) error {
+	if len(settings.EmailRecipients) == 0 {
+		return nil
+	}
+
+	to := make([]string, 0, len(settings.EmailRecipients))
+	for _, recipient := range settings.EmailRecipients {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid email recipient %q", recipient)
+		}
+		to = append(to, address.Address)
+	}
+
+	if err := n.send(ctx, n.config.SMTPFrom, to, n.encode(to, message)); err != nil {
+		return fmt.Errorf("failed to send notification email: %w", err)
+	}
+	return nil
+}
+
+// encode builds a plain text email. The subject is MIME-encoded, which also keeps
+// attributed names from adding headers.
+func (n *EmailNotifier) encode(to []string, message *Message) []byte {
+	var email bytes.Buffer
+	fmt.Fprintf(&email, "From: %s\r\n", n.config.SMTPFrom)
+	fmt.Fprintf(&email, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&email, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject()))
+	fmt.Fprintf(&email, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	email.WriteString("MIME-Version: 1.0\r\n")
+	email.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	email.WriteString("\r\n")
+	for _, line := range message.Details() {
+		email.WriteString(line + "\r\n")
+	}
+	return email.Bytes()
+}
+
+// sendSMTP delivers an email through the configured SMTP server, using STARTTLS when the
+// server offers it. The context bounds the whole conversation.
+func (n *EmailNotifier) sendSMTP(ctx context.Context, from string, to []string, email []byte) error {
+	addr := net.JoinHostPort(n.config.SMTPHost, strconv.Itoa(n.config.SMTPPort))
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, n.config.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.config.SMTPHost}); err != nil {
+			return err
+		}
+	}
+	if n.config.SMTPUsername != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.config.SMTPUsername, n.config.SMTPPassword, n.config.SMTPHost)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(email); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailNotifier(t *testing.T) {
+	notifier := NewEmailNotifier(&Config{SMTPHost: "smtp.example.com", SMTPPort: 587, SMTPFrom: "config@example.com"})
+
+	var sentFrom string
+	var sentTo []string
+	var sent string
+	notifier.send = func(ctx context.Context, from string, to []string, email []byte) error {
+		sentFrom, sentTo, sent = from, to, string(email)
+		return nil
+	}
+
+	settings := &models.NotificationSettings{EmailRecipients: []string{"Ops <ops@example.com>", "dev@example.com"}}
+
+	t.Run("check validates the recipients", func(t *testing.T) {
+		assert.NoError(t, notifier.Check(settings))
+		assert.ErrorContains(t, notifier.Check(&models.NotificationSettings{EmailRecipients: []string{"not an address"}}), "invalid email recipient")
+	})
+
+	t.Run("sends a plain text email", func(t *testing.T) {
+		require.NoError(t, notifier.Notify(context.Background(), settings, testMessage()))
+
+		assert.Equal(t, "config@example.com", sentFrom)
+		assert.Equal(t, []string{"ops@example.com", "dev@example.com"}, sentTo)
+		assert.Contains(t, sent, "Subject: demo/shopflow/production: updated to version 4 by alice\r\n")
+		assert.Contains(t, sent, "To: ops@example.com, dev@example.com\r\n")
+		assert.Contains(t, sent, "\r\n\r\nVersion: 3 -> 4\r\n")
+		assert.Contains(t, sent, "  + theme\r\n")
+	})
+
+	t.Run("names cannot add headers", func(t *testing.T) {
+		message := testMessage()
+		message.ChangedBy = "mallory\r\nBcc: victim@example.com"
+		require.NoError(t, notifier.Notify(context.Background(), settings, message))
+
+		headers := strings.SplitN(sent, "\r\n\r\n", 2)[0]
+		assert.NotContains(t, headers, "\r\nBcc:")
+	})
+
+	t.Run("reports send failures", func(t *testing.T) {
+		notifier.send = func(ctx context.Context, from string, to []string, email []byte) error {
+			return errors.New("connection refused")
+		}
+		err := notifier.Notify(context.Background(), settings, testMessage())
+		assert.ErrorContains(t, err, "connection refused")
+	})
+
+	t.Run("does nothing without recipients", func(t *testing.T) {
+		assert.NoError(t, notifier.Notify(context.Background(), &models.NotificationSettings{}, testMessage()))
+	})
+}
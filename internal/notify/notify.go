@@ -0,0 +1,244 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"remote-config-system/internal/models"
+)
+
+// maxDiffLines limits how many changed paths a message lists
+const maxDiffLines = 20
+
+// Message describes a configuration change announced to people
+type Message struct {
+	Organization string
+	Application  string
+	Environment  string
+	Action       string // Change log action: "create", "update", "promote", "rollback" or "approve"
+	VersionFrom  *int   // nil for the first version of an environment
+	VersionTo    int
+	ChangedBy    string // Empty if the change was not attributed
+	ApprovedBy   string // Set for approved changes
+	SourceEnv    string // Set for promotions from another environment
+	Note         string // The message given with the change, if any
+	Diff         *models.ConfigDiff
+	ChangedAt    time.Time
+}
+
+// Subject returns a one-line summary of the change
+func (m *Message) Subject() string {
+	subject := fmt.Sprintf("%s/%s/%s: %s to version %d", m.Organization, m.Application, m.Environment, actionVerb(m.Action), m.VersionTo)
+	if m.ChangedBy != "" {
+		subject += " by " + m.ChangedBy
+	}
+	return subject
+}
+
+// Details returns the lines describing the change below its subject. Changed paths are
+// listed without their values, so that values never leave the system in a message.
+func (m *Message) Details() []string {
+	var lines []string
+	if m.VersionFrom != nil {
+		lines = append(lines, fmt.Sprintf("Version: %d -> %d", *m.VersionFrom, m.VersionTo))
+	} else {
+		lines = append(lines, fmt.Sprintf("Version: %d (first version)", m.VersionTo))
+	}
+	if m.SourceEnv != "" {
+		lines = append(lines, "Promoted from: "+m.SourceEnv)
+	}
+	if m.ApprovedBy != "" {
+		lines = append(lines, "Approved by: "+m.ApprovedBy)
+	}
+	if m.Note != "" {
+		lines = append(lines, "Message: "+m.Note)
+	}
+	lines = append(lines, "Changed at: "+m.ChangedAt.UTC().Format(time.RFC3339))
+
+	if m.Diff == nil {
+		return lines
+	}
+	if !m.Diff.HasChanges {
+		return append(lines, "No configuration keys changed")
+	}
+
+	lines = append(lines, fmt.Sprintf("Changes: %d added, %d changed, %d removed", m.Diff.Added, m.Diff.Changed, m.Diff.Removed))
+	for i, entry := range m.Diff.Entries {
+		if i == maxDiffLines {
+			lines = append(lines, fmt.Sprintf("  ... and %d more", len(m.Diff.Entries)-maxDiffLines))
+			break
+		}
+		lines = append(lines, "  "+diffSymbol(entry.Type)+" "+entry.Path)
+	}
+	return lines
+}
+
+// actionVerb describes a change log action in a subject line
+func actionVerb(action string) string {
+	switch action {
+	case "create":
+		return "initialized"
+	case "rollback":
+		return "rolled back"
+	case "promote":
+		return "promoted"
+	case "approve":
+		return "approved change"
+	}
+	return "updated"
+}
+
+// diffSymbol marks a diff entry like a unified diff does, with ~ for changed values
+func diffSymbol(entryType string) string {
+	switch entryType {
+	case "added":
+		return "+"
+	case "removed":
+		return "-"
+	}
+	return "~"
+}
+
+// Notifier delivers change messages over one channel
+type Notifier interface {
+	// Check reports whether the notifier can deliver to the destinations in settings
+	Check(settings *models.NotificationSettings) error
+	// Notify delivers a message to the destinations in settings. Notifiers without a
+	// destination in settings do nothing.
+	Notify(ctx context.Context, settings *models.NotificationSettings, message *Message) error
+}
+
+// Config holds notification configuration
+type Config struct {
+	Timeout time.Duration // Bounds delivering one message over all channels
+
+	// Hosts Slack webhook URLs may point at
+	SlackAllowedHosts []string
+
+	// SMTP server for email notifications; email is disabled without a host
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+}
+
+// NewConfig creates a new notification configuration from environment variables
+func NewConfig() *Config {
+	return &Config{
+		Timeout:           time.Duration(getEnvInt("NOTIFY_TIMEOUT", 10)) * time.Second,
+		SlackAllowedHosts: getEnvList("NOTIFY_SLACK_ALLOWED_HOSTS", []string{"hooks.slack.com"}),
+		SMTPHost:          os.Getenv("SMTP_HOST"),
+		SMTPPort:          getEnvInt("SMTP_PORT", 587),
+		SMTPUsername:      os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:      os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:          getEnv("SMTP_FROM", "remote-config@localhost"),
+	}
+}
+
+// Dispatcher delivers messages over every configured channel
+type Dispatcher struct {
+	notifiers []Notifier
+	timeout   time.Duration
+}
+
+// NewDispatcher creates a dispatcher with a Slack notifier and, if an SMTP host is
+// configured, an email notifier
+func NewDispatcher(config *Config) *Dispatcher {
+	notifiers := []Notifier{NewSlackNotifier(config.SlackAllowedHosts)}
+	if config.SMTPHost != "" {
+		notifiers = append(notifiers, NewEmailNotifier(config))
+	} else {
+		notifiers = append(notifiers, disabledEmail{})
+	}
+
+	return &Dispatcher{notifiers: notifiers, timeout: config.Timeout}
+}
+
+// Check reports whether every destination in settings can be delivered to
+func (d *Dispatcher) Check(settings *models.NotificationSettings) error {
+	for _, notifier := range d.notifiers {
+		if err := notifier.Check(settings); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Notify delivers a message over all channels at once, bounded by the configured timeout.
+// A failing channel does not stop the others; their errors are joined.
+func (d *Dispatcher) Notify(ctx context.Context, settings *models.NotificationSettings, message *Message) error {
+	if d.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+	}
+
+	errs := make([]error, len(d.notifiers))
+	var wg sync.WaitGroup
+	for i, notifier := range d.notifiers {
+		wg.Add(1)
+		go func(i int, notifier Notifier) {
+			defer wg.Done()
+			errs[i] = notifier.Notify(ctx, settings, message)
+		}(i, notifier)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// disabledEmail stands in for the email notifier when no SMTP server is configured, so
+// that settings with email recipients are rejected
+type disabledEmail struct{}
+
+func (disabledEmail) Check(settings *models.NotificationSettings) error {
+	if len(settings.EmailRecipients) > 0 {
+		return errors.New("email notifications are disabled; set SMTP_HOST to enable them")
+	}
+	return nil
+}
+
+func (disabledEmail) Notify(ctx context.Context, settings *models.NotificationSettings, message *Message) error {
+	return nil
+}
+
+// getEnv gets an environment variable with a fallback value
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvInt gets a positive integer environment variable with a fallback value
+func getEnvInt(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return fallback
+}
+
+// getEnvList gets a comma-separated environment variable with a fallback value
+func getEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
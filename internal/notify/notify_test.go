@@ -0,0 +1,163 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMessage() *Message {
+	from := 3
+	return &Message{
+		Organization: "demo",
+		Application:  "shopflow",
+		Environment:  "production",
+		Action:       "update",
+		VersionFrom:  &from,
+		VersionTo:    4,
+		ChangedBy:    "alice",
+		Diff: &models.ConfigDiff{
+			HasChanges: true,
+			Added:      1,
+			Changed:    1,
+			Entries: []models.ConfigDiffEntry{
+				{Path: "database.pool_size", Type: "changed", OldValue: 10, NewValue: 20},
+				{Path: "theme", Type: "added", NewValue: "dark"},
+			},
+		},
+		ChangedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestMessage(t *testing.T) {
+	t.Run("subject names the change and who made it", func(t *testing.T) {
+		message := testMessage()
+		assert.Equal(t, "demo/shopflow/production: updated to version 4 by alice", message.Subject())
+
+		message.Action = "rollback"
+		message.ChangedBy = ""
+		assert.Equal(t, "demo/shopflow/production: rolled back to version 4", message.Subject())
+
+		message.Action = "create"
+		assert.Equal(t, "demo/shopflow/production: initialized to version 4", message.Subject())
+	})
+
+	t.Run("details list the changed paths without values", func(t *testing.T) {
+		details := testMessage().Details()
+		assert.Equal(t, []string{
+			"Version: 3 -> 4",
+			"Changed at: 2024-05-01T12:00:00Z",
+			"Changes: 1 added, 1 changed, 0 removed",
+			"  ~ database.pool_size",
+			"  + theme",
+		}, details)
+	})
+
+	t.Run("first versions, promotions, approvals and messages", func(t *testing.T) {
+		message := testMessage()
+		message.VersionFrom = nil
+		message.SourceEnv = "staging"
+		message.ApprovedBy = "bob"
+		message.Note = "bumped timeout for Black Friday"
+		message.Diff = &models.ConfigDiff{}
+
+		assert.Equal(t, []string{
+			"Version: 4 (first version)",
+			"Promoted from: staging",
+			"Approved by: bob",
+			"Message: bumped timeout for Black Friday",
+			"Changed at: 2024-05-01T12:00:00Z",
+			"No configuration keys changed",
+		}, message.Details())
+	})
+
+	t.Run("long diffs are cut short", func(t *testing.T) {
+		message := testMessage()
+		message.Diff.Entries = nil
+		for i := 0; i < maxDiffLines+5; i++ {
+			message.Diff.Entries = append(message.Diff.Entries, models.ConfigDiffEntry{Path: fmt.Sprintf("key%d", i), Type: "removed"})
+		}
+
+		details := message.Details()
+		assert.Equal(t, "  ... and 5 more", details[len(details)-1])
+	})
+}
+
+// fakeNotifier records the messages it is asked to deliver
+type fakeNotifier struct {
+	checkErr  error
+	notifyErr error
+	messages  chan *Message
+}
+
+func (n *fakeNotifier) Check(settings *models.NotificationSettings) error {
+	return n.checkErr
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, settings *models.NotificationSettings, message *Message) error {
+	if n.messages != nil {
+		n.messages <- message
+	}
+	return n.notifyErr
+}
+
+func TestDispatcher(t *testing.T) {
+	settings := &models.NotificationSettings{SlackWebhookURL: "https://hooks.slack.com/services/T/B/X"}
+
+	t.Run("every channel is notified and failures are joined", func(t *testing.T) {
+		failing := &fakeNotifier{notifyErr: errors.New("slack is down"), messages: make(chan *Message, 1)}
+		working := &fakeNotifier{messages: make(chan *Message, 1)}
+		dispatcher := &Dispatcher{notifiers: []Notifier{failing, working}, timeout: time.Second}
+
+		err := dispatcher.Notify(context.Background(), settings, testMessage())
+		assert.ErrorContains(t, err, "slack is down")
+		assert.Len(t, failing.messages, 1)
+		assert.Len(t, working.messages, 1)
+	})
+
+	t.Run("check fails if any channel rejects the settings", func(t *testing.T) {
+		dispatcher := &Dispatcher{notifiers: []Notifier{&fakeNotifier{}, &fakeNotifier{checkErr: errors.New("bad host")}}}
+		assert.ErrorContains(t, dispatcher.Check(settings), "bad host")
+	})
+
+	t.Run("email recipients need an SMTP server", func(t *testing.T) {
+		dispatcher := NewDispatcher(&Config{SlackAllowedHosts: []string{"hooks.slack.com"}})
+		assert.NoError(t, dispatcher.Check(settings))
+		assert.ErrorContains(t, dispatcher.Check(&models.NotificationSettings{EmailRecipients: []string{"ops@example.com"}}), "SMTP_HOST")
+
+		dispatcher = NewDispatcher(&Config{SMTPHost: "smtp.example.com", SMTPPort: 587})
+		assert.NoError(t, dispatcher.Check(&models.NotificationSettings{EmailRecipients: []string{"ops@example.com"}}))
+	})
+}
+
+func TestNewConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		config := NewConfig()
+		assert.Equal(t, 10*time.Second, config.Timeout)
+		assert.Equal(t, []string{"hooks.slack.com"}, config.SlackAllowedHosts)
+		assert.Empty(t, config.SMTPHost)
+		assert.Equal(t, 587, config.SMTPPort)
+	})
+
+	t.Run("from environment", func(t *testing.T) {
+		t.Setenv("NOTIFY_TIMEOUT", "3")
+		t.Setenv("NOTIFY_SLACK_ALLOWED_HOSTS", "hooks.slack.com, chat.example.com")
+		t.Setenv("SMTP_HOST", "smtp.example.com")
+		t.Setenv("SMTP_PORT", "2525")
+		t.Setenv("SMTP_FROM", "config@example.com")
+
+		config := NewConfig()
+		require.Equal(t, 3*time.Second, config.Timeout)
+		assert.Equal(t, []string{"hooks.slack.com", "chat.example.com"}, config.SlackAllowedHosts)
+		assert.Equal(t, "smtp.example.com", config.SMTPHost)
+		assert.Equal(t, 2525, config.SMTPPort)
+		assert.Equal(t, "config@example.com", config.SMTPFrom)
+	})
+}
@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"remote-config-system/internal/models"
+)
+
+// SlackNotifier posts messages to Slack incoming webhooks
+type SlackNotifier struct {
+	client       *http.Client
+	allowedHosts map[string]bool
+}
+
+// NewSlackNotifier creates a Slack notifier that only posts to webhooks on the allowed
+// hosts. The allowlist stops the server from being used to send requests elsewhere.
+func NewSlackNotifier(allowedHosts []string) *SlackNotifier {
+	hosts := make(map[string]bool, len(allowedHosts))
+	for _, host := range allowedHosts {
+		hosts[strings.ToLower(host)] = true
+	}
+
+	return &SlackNotifier{client: &http.Client{}, allowedHosts: hosts}
+}
+
+// Check reports whether the webhook URL in settings is an HTTPS URL on an allowed host
+func (n *SlackNotifier) Check(settings *models.NotificationSettings) error {
+	if settings.SlackWebhookURL == "" {
+		return nil
+	}
+
+	webhook, err := url.Parse(settings.SlackWebhookURL)
+	if err != nil || webhook.Scheme != "https" || webhook.Host == "" {
+		return fmt.Errorf("slack_webhook_url must be an https URL")
+	}
+	if !n.allowedHosts[strings.ToLower(webhook.Hostname())] {
+		return fmt.Errorf("slack_webhook_url host %s is not allowed", webhook.Hostname())
+	}
+	return nil
+}
+
+// Notify posts a message to the webhook in settings
+func (n *SlackNotifier) Notify(ctx context.Context, settings *models.NotificationSettings, message *Message) error {
+	if settings.SlackWebhookURL == "" {
+		return nil
+	}
+
+	// The changed paths go in a code block so that they line up
+	text := "*" + message.Subject() + "*\n```\n" + strings.Join(message.Details(), "\n") + "\n```"
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, settings.SlackWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post Slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack webhook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(reason)))
+	}
+	return nil
+}
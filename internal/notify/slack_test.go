@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackNotifier_Check(t *testing.T) {
+	notifier := NewSlackNotifier([]string{"hooks.slack.com"})
+
+	tests := map[string]struct {
+		url     string
+		wantErr string
+	}{
+		"no webhook":               {"", ""},
+		"slack webhook":            {"https://hooks.slack.com/services/T000/B000/XXXX", ""},
+		"host is case-insensitive": {"https://HOOKS.slack.com/services/T000/B000/XXXX", ""},
+		"plain http":               {"http://hooks.slack.com/services/T000/B000/XXXX", "https URL"},
+		"not a URL":                {"hooks.slack.com/services", "https URL"},
+		"other host":               {"https://internal.example.com/hook", "not allowed"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := notifier.Check(&models.NotificationSettings{SlackWebhookURL: tt.url})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSlackNotifier_Notify(t *testing.T) {
+	var received map[string]string
+	status := http.StatusOK
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+		w.Write([]byte("invalid_token"))
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	notifier := NewSlackNotifier([]string{serverURL.Hostname()})
+	notifier.client = server.Client()
+	settings := &models.NotificationSettings{SlackWebhookURL: server.URL + "/services/T/B/X"}
+
+	t.Run("posts a formatted message", func(t *testing.T) {
+		require.NoError(t, notifier.Check(settings))
+		require.NoError(t, notifier.Notify(context.Background(), settings, testMessage()))
+
+		assert.Contains(t, received["text"], "*demo/shopflow/production: updated to version 4 by alice*")
+		assert.Contains(t, received["text"], "~ database.pool_size")
+		assert.NotContains(t, received["text"], "dark")
+	})
+
+	t.Run("reports webhook errors", func(t *testing.T) {
+		status = http.StatusForbidden
+		err := notifier.Notify(context.Background(), settings, testMessage())
+		assert.ErrorContains(t, err, "403: invalid_token")
+	})
+
+	t.Run("does nothing without a webhook", func(t *testing.T) {
+		assert.NoError(t, notifier.Notify(context.Background(), &models.NotificationSettings{}, testMessage()))
+	})
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds HTTP server configuration. A zero timeout disables it.
+type Config struct {
+	Port string
+
+	// ReadTimeout bounds reading a request, headers and body, which stops slow clients
+	// from holding connections open
+	ReadTimeout time.Duration
+	// WriteTimeout bounds handling a request and writing its response. Streaming routes
+	// lift it with middleware.Streaming.
+	WriteTimeout time.Duration
+	// IdleTimeout bounds how long a keep-alive connection waits for its next request
+	IdleTimeout time.Duration
+}
+
+// NewConfig creates a new HTTP server configuration from environment variables
+func NewConfig() *Config {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	return &Config{
+		Port:         port,
+		ReadTimeout:  getEnvSeconds("SERVER_READ_TIMEOUT", 30*time.Second),
+		WriteTimeout: getEnvSeconds("SERVER_WRITE_TIMEOUT", 60*time.Second),
+		IdleTimeout:  getEnvSeconds("SERVER_IDLE_TIMEOUT", 120*time.Second),
+	}
+}
+
+// New creates an HTTP server for a handler with the configured timeouts
+func New(config *Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         ":" + config.Port,
+		Handler:      handler,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		IdleTimeout:  config.IdleTimeout,
+	}
+}
+
+// getEnvSeconds reads a duration in whole seconds from an environment variable
+func getEnvSeconds(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return fallback
+}
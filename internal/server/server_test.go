@@ -0,0 +1,157 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestNewConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		config := NewConfig()
+		assert.Equal(t, "8080", config.Port)
+		assert.Equal(t, 30*time.Second, config.ReadTimeout)
+		assert.Equal(t, time.Minute, config.WriteTimeout)
+		assert.Equal(t, 2*time.Minute, config.IdleTimeout)
+	})
+
+	t.Run("from environment", func(t *testing.T) {
+		t.Setenv("PORT", "9000")
+		t.Setenv("SERVER_READ_TIMEOUT", "5")
+		t.Setenv("SERVER_WRITE_TIMEOUT", "0")
+		t.Setenv("SERVER_IDLE_TIMEOUT", "30")
+
+		config := NewConfig()
+		assert.Equal(t, "9000", config.Port)
+		assert.Equal(t, 5*time.Second, config.ReadTimeout)
+		assert.Equal(t, time.Duration(0), config.WriteTimeout)
+		assert.Equal(t, 30*time.Second, config.IdleTimeout)
+
+		server := New(config, http.NotFoundHandler())
+		assert.Equal(t, ":9000", server.Addr)
+		assert.Equal(t, time.Duration(0), server.WriteTimeout)
+	})
+
+	t.Run("invalid values fall back to defaults", func(t *testing.T) {
+		t.Setenv("SERVER_READ_TIMEOUT", "soon")
+		t.Setenv("SERVER_WRITE_TIMEOUT", "-1")
+
+		config := NewConfig()
+		assert.Equal(t, 30*time.Second, config.ReadTimeout)
+		assert.Equal(t, time.Minute, config.WriteTimeout)
+	})
+}
+
+// startServer serves a router with the given timeouts on a free local port
+func startServer(t *testing.T, config *Config, router http.Handler) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := New(config, router)
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	return listener.Addr().String()
+}
+
+func TestServer_StreamingRoutesOutliveTheWriteTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const timeout = 200 * time.Millisecond
+
+	// Each route writes its response after the deadlines have passed
+	stream := func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			time.Sleep(timeout)
+			c.SSEvent("ping", i)
+			c.Writer.Flush()
+		}
+	}
+	slow := func(c *gin.Context) {
+		time.Sleep(2 * timeout)
+		c.String(http.StatusOK, "done")
+	}
+
+	router := gin.New()
+	router.GET("/events", middleware.Streaming(), stream)
+	router.GET("/slow", middleware.Streaming(), slow)
+	router.GET("/events-unprotected", stream)
+	router.GET("/slow-unprotected", slow)
+	router.GET("/ws", middleware.Streaming(), func(c *gin.Context) {
+		websocket.Handler(func(conn *websocket.Conn) {
+			// The upgraded connection is read after the read timeout
+			var message string
+			if err := websocket.Message.Receive(conn, &message); err != nil {
+				return
+			}
+			websocket.Message.Send(conn, "echo: "+message)
+		}).ServeHTTP(c.Writer, c.Request)
+	})
+
+	addr := startServer(t, &Config{ReadTimeout: timeout, WriteTimeout: timeout}, router)
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	t.Run("SSE stream keeps sending", func(t *testing.T) {
+		resp, err := client.Get("http://" + addr + "/events")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		events := 0
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), "event:") {
+				events++
+			}
+		}
+		assert.NoError(t, scanner.Err())
+		assert.Equal(t, 3, events)
+	})
+
+	t.Run("long poll answers late", func(t *testing.T) {
+		resp, err := client.Get("http://" + addr + "/slow")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "done", string(body))
+	})
+
+	t.Run("WebSocket reads and writes after the deadlines", func(t *testing.T) {
+		conn, err := websocket.Dial("ws://"+addr+"/ws", "", "http://"+addr)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		time.Sleep(2 * timeout)
+		require.NoError(t, websocket.Message.Send(conn, "hello"))
+
+		var reply string
+		require.NoError(t, websocket.Message.Receive(conn, &reply))
+		assert.Equal(t, "echo: hello", reply)
+	})
+
+	t.Run("ordinary routes are cut off", func(t *testing.T) {
+		_, err := client.Get("http://" + addr + "/slow-unprotected")
+		assert.Error(t, err)
+
+		// A stream without the middleware loses its connection mid-stream
+		resp, err := client.Get("http://" + addr + "/events-unprotected")
+		if err == nil {
+			_, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ValidateAdminToken resolves an admin token to its stored record, including its role
+func (s *ConfigService) ValidateAdminToken(token string) (*models.AdminToken, error) {
+	adminToken, err := s.repos.AdminTokens.GetByHash(HashAdminToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("invalid admin token: %w", err)
+	}
+	return adminToken, nil
+}
+
+// ListAdminTokens retrieves all admin tokens, without their secret values
+func (s *ConfigService) ListAdminTokens() ([]models.AdminToken, error) {
+	return s.repos.AdminTokens.List()
+}
+
+// CreateAdminToken creates a new admin token. The secret value is only returned here.
+func (s *ConfigService) CreateAdminToken(req *models.CreateAdminTokenRequest) (*models.CreateAdminTokenResponse, error) {
+	if !models.IsValidRole(req.Role) {
+		return nil, invalidError("invalid role: %s (must be %s, %s or %s)", req.Role, models.RoleViewer, models.RoleEditor, models.RoleAdmin)
+	}
+
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return nil, fmt.Errorf("failed to generate admin token: %w", err)
+	}
+	token := "adm_" + hex.EncodeToString(bytes)
+
+	adminToken := &models.AdminToken{
+		Name: req.Name,
+		Role: req.Role,
+	}
+	if err := s.repos.AdminTokens.Create(adminToken, HashAdminToken(token)); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Created admin token %s (%s) with role %s", adminToken.ID, adminToken.Name, adminToken.Role)
+	return &models.CreateAdminTokenResponse{AdminToken: *adminToken, Token: token}, nil
+}
+
+// DeleteAdminToken revokes an admin token
+func (s *ConfigService) DeleteAdminToken(id uuid.UUID) error {
+	if err := s.repos.AdminTokens.Delete(id); err != nil {
+		return recordError(err)
+	}
+
+	log.Printf("Deleted admin token %s", id)
+	return nil
+}
+
+// HashAdminToken returns the hex-encoded SHA-256 hash under which an admin token is stored
+func HashAdminToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
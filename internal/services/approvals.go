@@ -0,0 +1,220 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"remote-config-system/internal/models"
+)
+
+// proposeConfiguration stores an update to an environment that requires approval as an
+// inactive version with a pending change. Nothing is invalidated or broadcast until the
+// change is approved.
+func (s *ConfigService) proposeConfiguration(env *models.Environment, storedConfig, config json.RawMessage, baseVersion *int, createdBy, proposedBy, message *string) (*models.ConfigResponse, error) {
+	proposedVersion := &models.ConfigVersion{
+		EnvID:      env.ID,
+		ConfigJSON: storedConfig,
+		IsActive:   false,
+		CreatedBy:  createdBy,
+	}
+
+	if err := s.repos.ConfigVersions.Create(proposedVersion); err != nil {
+		return nil, fmt.Errorf("failed to create configuration version: %w", recordError(err))
+	}
+
+	pending := &models.PendingChange{
+		EnvID:       env.ID,
+		Version:     proposedVersion.Version,
+		BaseVersion: baseVersion,
+		ProposedBy:  proposedBy,
+		Message:     message,
+	}
+
+	if err := s.repos.PendingChanges.Create(pending); err != nil {
+		return nil, fmt.Errorf("failed to create pending change: %w", err)
+	}
+
+	return &models.ConfigResponse{
+		Organization: env.Application.Organization.Slug,
+		Application:  env.Application.Slug,
+		Environment:  env.Slug,
+		Version:      proposedVersion.Version,
+		VersionID:    &proposedVersion.ID,
+		Config:       config,
+		UpdatedAt:    proposedVersion.CreatedAt,
+		Pending:      pending,
+	}, nil
+}
+
+// ListPendingChanges retrieves the proposed changes of an environment with the given status
+// (pending, approved or rejected). An empty status lists changes of every status.
+func (s *ConfigService) ListPendingChanges(orgSlug, appSlug, envSlug, status string, params models.PaginationParams) (*models.PaginatedResponse, error) {
+	switch status {
+	case "", models.PendingStatusPending, models.PendingStatusApproved, models.PendingStatusRejected:
+	default:
+		return nil, invalidError("invalid status: %s", status)
+	}
+
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	changes, totalCount, err := s.repos.PendingChanges.ListByEnvironment(env.ID, status, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending changes: %w", err)
+	}
+
+	response := models.NewPaginatedResponse(changes, params.Page, params.PageSize, totalCount)
+	return &response, nil
+}
+
+// ApprovePendingChange activates a proposed configuration version, logs the change with
+// both its proposer and its approver, and broadcasts the update
+func (s *ConfigService) ApprovePendingChange(orgSlug, appSlug, envSlug string, req *models.ReviewChangeRequest) (*models.ConfigResponse, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	// Rejecting a pending change is still allowed while the environment is locked
+	if err := checkUnlocked(env); err != nil {
+		return nil, err
+	}
+
+	pending, err := s.checkReview(env, req, models.PendingStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	proposedConfig, err := s.repos.ConfigVersions.GetByVersion(env.ID, pending.Version)
+	if err != nil {
+		return nil, lookupError("configuration version not found", err)
+	}
+
+	// Approve, activate and log the change at once, keeping the proposer and the approver apart
+	change := &models.ConfigChange{
+		EnvID:      env.ID,
+		VersionTo:  pending.Version,
+		Action:     "approve",
+		CreatedBy:  pending.ProposedBy,
+		ApprovedBy: pending.ReviewedBy,
+		Message:    pending.Message,
+	}
+	if err := s.repos.PendingChanges.Approve(pending, change); err != nil {
+		return nil, recordError(err)
+	}
+	log.Printf("Pending change %s (version %d) %s", pending.ID, pending.Version, pending.Status)
+	s.endRollout(env)
+
+	// Invalidate cache for this configuration
+	if err := s.InvalidateEnvironmentCache(env.Application.Organization.Slug, env.Application.Slug, env.Slug); err != nil {
+		log.Printf("Failed to invalidate environment cache: %v", err)
+	}
+
+	configJSON, err := s.encryptor.DecryptSecrets(proposedConfig.ConfigJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt configuration: %w", err)
+	}
+
+	// Build the response
+	response := &models.ConfigResponse{
+		Organization: env.Application.Organization.Slug,
+		Application:  env.Application.Slug,
+		Environment:  env.Slug,
+		Version:      pending.Version,
+		VersionID:    &proposedConfig.ID,
+		Config:       configJSON,
+		UpdatedAt:    *pending.ReviewedAt,
+		Pending:      pending,
+	}
+
+	// Broadcast SSE event for configuration update
+	if s.sseService != nil {
+		config, defaultsVersion := s.broadcastConfig(env, proposedConfig.ConfigJSON)
+		updateEvent := models.ConfigUpdateEvent{
+			Organization:    response.Organization,
+			Application:     response.Application,
+			Environment:     response.Environment,
+			Version:         response.Version,
+			DefaultsVersion: defaultsVersion,
+			Config:          config,
+			Stored:          unmaskSecrets(config, proposedConfig.ConfigJSON),
+			Action:          "update",
+			UpdatedAt:       response.UpdatedAt,
+		}
+		s.sseService.BroadcastConfigUpdate(updateEvent)
+	}
+	s.notifyChange(env, change)
+
+	return response, nil
+}
+
+// RejectPendingChange closes a proposed change without activating it
+func (s *ConfigService) RejectPendingChange(orgSlug, appSlug, envSlug string, req *models.ReviewChangeRequest) (*models.PendingChange, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	return s.reviewPendingChange(env, req, models.PendingStatusRejected)
+}
+
+// reviewPendingChange records the review of a pending change that is not approved
+func (s *ConfigService) reviewPendingChange(env *models.Environment, req *models.ReviewChangeRequest, status string) (*models.PendingChange, error) {
+	pending, err := s.checkReview(env, req, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repos.PendingChanges.Review(pending); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Pending change %s (version %d) %s", pending.ID, pending.Version, status)
+	return pending, nil
+}
+
+// checkReview checks the review of a pending change and returns the change with the
+// review filled in. A change cannot be approved by the person who proposed it.
+func (s *ConfigService) checkReview(env *models.Environment, req *models.ReviewChangeRequest, status string) (*models.PendingChange, error) {
+	pending, err := s.repos.PendingChanges.GetByID(env.ID, req.ChangeID)
+	if err != nil {
+		return nil, recordError(err)
+	}
+
+	if pending.Status != models.PendingStatusPending {
+		return nil, conflictError("pending change already reviewed: %s was %s", pending.ID, pending.Status)
+	}
+
+	// With admin auth, the reviewer is the admin token, whatever the request names
+	reviewer := req.ReviewedBy
+	if req.AdminToken != nil {
+		reviewer = req.AdminToken
+	}
+
+	if status == models.PendingStatusApproved {
+		if err := checkApprover(pending, reviewer, req.AdminToken != nil); err != nil {
+			return nil, err
+		}
+	}
+
+	pending.Status = status
+	pending.ReviewedBy = reviewer
+	pending.ReviewComment = req.Comment
+	return pending, nil
+}
+
+// checkApprover rejects the approval of a change by its proposer. With admin auth, both
+// are admin tokens, and a change whose proposer is unknown cannot be approved at all;
+// without it, only the names the clients gave can be compared.
+func checkApprover(pending *models.PendingChange, approver *string, authenticated bool) error {
+	if authenticated && pending.ProposedBy == nil {
+		return forbiddenError("approver must differ from proposer: the proposer of change %s is unknown", pending.ID)
+	}
+	if pending.ProposedBy != nil && approver != nil && *pending.ProposedBy == *approver {
+		return forbiddenError("approver must differ from proposer: %s proposed this change", *pending.ProposedBy)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckApprover(t *testing.T) {
+	name := func(s string) *string { return &s }
+
+	tests := []struct {
+		name          string
+		proposedBy    *string
+		approver      *string
+		authenticated bool
+		allowed       bool
+	}{
+		{"another token approves", name("ci-bot"), name("release-manager"), true, true},
+		{"the proposing token approves", name("ci-bot"), name("ci-bot"), true, false},
+		{"unknown proposer with admin auth", nil, name("release-manager"), true, false},
+		{"unknown proposer without admin auth", nil, name("alice"), false, true},
+		{"same name without admin auth", name("alice"), name("alice"), false, false},
+		{"unnamed approver without admin auth", name("alice"), nil, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending := &models.PendingChange{ID: uuid.New(), ProposedBy: tt.proposedBy}
+
+			err := checkApprover(pending, tt.approver, tt.authenticated)
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, ErrForbidden), "error: %v", err)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MaxBatchEnvironments limits how many environments one batch request can read or update
+const MaxBatchEnvironments = 50
+
+// UpdateConfigurations creates a new version of the configuration of several environments
+// of an application in a single transaction, so that either every environment is updated
+// or none is. Each configuration is checked the way UpdateConfiguration checks one; if any
+// check fails, the response lists why under Errors and nothing is saved. Caches are only
+// invalidated and subscribers only notified once every version is stored.
+func (s *ConfigService) UpdateConfigurations(orgSlug, appSlug string, req *models.BatchUpdateConfigRequest, dryRun bool) (*models.BatchConfigResponse, error) {
+	if _, err := s.repos.Applications.GetBySlug(orgSlug, appSlug); err != nil {
+		return nil, lookupError("application not found", err)
+	}
+
+	slugs := make([]string, 0, len(req.Configs))
+	for slug := range req.Configs {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	targets := make([]batchTarget, len(slugs))
+	for i, slug := range slugs {
+		targets[i] = batchTarget{key: slug, config: req.Configs[slug]}
+		env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, slug)
+		if err != nil {
+			targets[i].err = lookupError("environment not found", err)
+			continue
+		}
+		targets[i].env = env
+	}
+
+	return s.updateEnvironments(targets, req, dryRun)
+}
+
+// UpdateConfigurationsBySelector sets the configuration of every environment of an
+// organization that a label selector picks, or of every such environment of one of its
+// applications when appSlug is set, the way UpdateConfigurations updates several
+// environments: in one transaction, or not at all. The response is keyed by environment
+// slug, prefixed with the application slug, e.g. "web/canary", for a whole organization.
+func (s *ConfigService) UpdateConfigurationsBySelector(orgSlug, appSlug, selector string, req *models.BatchUpdateConfigRequest, dryRun bool) (*models.BatchConfigResponse, error) {
+	requirements, err := parseLabelSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := s.repos.Organizations.GetBySlug(orgSlug)
+	if err != nil {
+		return nil, lookupError("organization not found", err)
+	}
+	var appID *uuid.UUID
+	if appSlug != "" {
+		app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
+		if err != nil {
+			return nil, lookupError("application not found", err)
+		}
+		appID = &app.ID
+	}
+
+	envs, err := s.repos.Environments.ListBySelector(org.ID, appID, requirements)
+	if err != nil {
+		return nil, err
+	}
+	if len(envs) == 0 {
+		return nil, notFoundError("no environments match the label selector %q", selector)
+	}
+	if len(envs) > MaxBatchEnvironments {
+		return nil, invalidError("invalid label selector: %q matches %d environments, more than the %d of a batch", selector, len(envs), MaxBatchEnvironments)
+	}
+
+	targets := make([]batchTarget, len(envs))
+	for i := range envs {
+		env := &envs[i]
+		targets[i] = batchTarget{key: env.Slug, env: env, config: req.Config}
+		if appSlug == "" {
+			targets[i].key = env.Application.Slug + "/" + env.Slug
+		}
+	}
+
+	return s.updateEnvironments(targets, req, dryRun)
+}
+
+// batchTarget is an environment of a batch update, keyed by how the response names it
+type batchTarget struct {
+	key    string
+	env    *models.Environment
+	err    error // Why the environment could not be found
+	config json.RawMessage
+}
+
+// updateEnvironments checks and stores the new configurations of the environments of a
+// batch update, all of them or none
+func (s *ConfigService) updateEnvironments(targets []batchTarget, req *models.BatchUpdateConfigRequest, dryRun bool) (*models.BatchConfigResponse, error) {
+	response := &models.BatchConfigResponse{
+		Configs: make(map[string]*models.ConfigResponse),
+		Errors:  make(map[string]models.BatchConfigError),
+	}
+	keys := make([]string, len(targets))
+	updated := make([]batchTarget, 0, len(targets))
+	versions := make([]*models.ConfigVersion, 0, len(targets))
+
+	for i := range targets {
+		target := &targets[i]
+		keys[i] = target.key
+		storedConfig, err := s.prepareBatchUpdate(target, dryRun)
+		if err == nil && dryRun {
+			currentConfig := json.RawMessage(`{}`)
+			if activeConfig, err := s.repos.ConfigVersions.GetActiveByEnvironment(target.env.ID); err == nil {
+				currentConfig = activeConfig.ConfigJSON
+			}
+			response.Configs[target.key], err = s.previewUpdate(target.env, currentConfig, target.config)
+		}
+		if err != nil {
+			response.Errors[target.key] = models.BatchConfigError{Status: batchUpdateErrorStatus(err), Message: err.Error()}
+			continue
+		}
+
+		updated = append(updated, *target)
+		versions = append(versions, &models.ConfigVersion{
+			EnvID:      target.env.ID,
+			ConfigJSON: storedConfig,
+			CreatedBy:  req.CreatedBy,
+		})
+	}
+
+	if len(response.Errors) > 0 {
+		return abortBatchUpdate(response, keys), nil
+	}
+	if dryRun {
+		return response, nil
+	}
+
+	changes := make([]*models.ConfigChange, len(versions))
+	for i := range versions {
+		changes[i] = &models.ConfigChange{Action: "update", CreatedBy: req.CreatedBy, Message: req.Message}
+	}
+	if err := s.repos.ConfigVersions.CreateMany(versions, changes); err != nil {
+		return nil, fmt.Errorf("failed to update configurations: %w", recordError(err))
+	}
+
+	for i, target := range updated {
+		s.endRollout(target.env)
+		response.Configs[target.key] = s.publishUpdate(target.env, versions[i], target.config, changes[i])
+	}
+
+	return response, nil
+}
+
+// prepareBatchUpdate checks the update of one environment of a batch, normalizes its
+// configuration and returns the configuration as it is stored
+func (s *ConfigService) prepareBatchUpdate(target *batchTarget, dryRun bool) (json.RawMessage, error) {
+	if err := s.checkConfigSize(target.config); err != nil {
+		return nil, err
+	}
+	if target.err != nil {
+		return nil, target.err
+	}
+	env := target.env
+
+	config, err := NormalizeConfig(target.config)
+	if err != nil {
+		return nil, err
+	}
+	target.config = config
+
+	storedConfig, err := s.prepareConfig(env, target.config)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return storedConfig, nil
+	}
+
+	if err := checkUnlocked(env); err != nil {
+		return nil, err
+	}
+
+	// A proposed version would leave the environment behind the rest of the batch
+	if env.RequiresApproval {
+		return nil, validationError("invalid configuration: environment %s requires approval, so it cannot be updated in a batch", env.Slug)
+	}
+
+	return storedConfig, nil
+}
+
+// batchUpdateErrorStatus returns the HTTP status describing why an environment of a batch
+// could not be updated
+func batchUpdateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrLocked):
+		return http.StatusLocked
+	case errors.Is(err, ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrValidation):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// abortBatchUpdate drops the configurations of a batch that failed and marks every
+// environment that did not fail itself as not updated
+func abortBatchUpdate(response *models.BatchConfigResponse, keys []string) *models.BatchConfigResponse {
+	response.Configs = make(map[string]*models.ConfigResponse)
+	for _, key := range keys {
+		if _, failed := response.Errors[key]; !failed {
+			response.Errors[key] = models.BatchConfigError{
+				Status:  http.StatusFailedDependency,
+				Message: "not updated: another environment failed, so no environment was updated",
+			}
+		}
+	}
+	return response
+}
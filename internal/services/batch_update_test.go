@@ -0,0 +1,44 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchUpdateErrorStatus(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{notFoundError("environment not found: %w", fmt.Errorf("no rows")), http.StatusNotFound},
+		{lockedError("environment prod is locked"), http.StatusLocked},
+		{invalidError("invalid JSON configuration: unexpected end of JSON input"), http.StatusBadRequest},
+		{tooLargeError("configuration too large: 2048 bytes (limit 1024)"), http.StatusRequestEntityTooLarge},
+		{validationError("configuration violates rules: limits.rps must be at most 100"), http.StatusUnprocessableEntity},
+		{validationError("invalid configuration: environment prod requires approval, so it cannot be updated in a batch"), http.StatusUnprocessableEntity},
+		{fmt.Errorf("failed to encrypt secret values: no key"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, batchUpdateErrorStatus(tt.err), tt.err.Error())
+	}
+}
+
+func TestAbortBatchUpdate(t *testing.T) {
+	response := &models.BatchConfigResponse{
+		Configs: map[string]*models.ConfigResponse{"dev": {Environment: "dev", Version: 4}},
+		Errors:  map[string]models.BatchConfigError{"prod": {Status: http.StatusLocked, Message: "environment prod is locked"}},
+	}
+
+	response = abortBatchUpdate(response, []string{"dev", "prod", "staging"})
+
+	assert.Empty(t, response.Configs)
+	assert.Equal(t, http.StatusLocked, response.Errors["prod"].Status)
+	assert.Equal(t, http.StatusFailedDependency, response.Errors["dev"].Status)
+	assert.Equal(t, http.StatusFailedDependency, response.Errors["staging"].Status)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"remote-config-system/internal/models"
+)
+
+// CheckBlobs is the name of the validation check of blob values
+const CheckBlobs = "blobs"
+
+// DefaultMaxBlobSize is the largest accepted decoded size of a single blob value
+const DefaultMaxBlobSize = 64 << 10 // 64 KiB
+
+// checkConfigBlobs returns an error if a value at one of the environment's blob keys is
+// not a base64-encoded string or decodes to more than the maximum blob size. Blob keys
+// that are absent or null are accepted.
+func (s *ConfigService) checkConfigBlobs(env *models.Environment, config json.RawMessage) error {
+	if env == nil || len(env.BlobKeys) == 0 {
+		return nil
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(config, &document); err != nil {
+		return invalidError("invalid JSON configuration: %w", err)
+	}
+
+	var invalid []string
+	for _, key := range env.BlobKeys {
+		value, ok := lookupKey(document, key)
+		if !ok || value == nil {
+			continue
+		}
+
+		encoded, isString := value.(string)
+		if !isString {
+			invalid = append(invalid, fmt.Sprintf("%s is marked as a blob but is not a string", key))
+			continue
+		}
+		data, err := decodeBlob(encoded)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s is marked as a blob but is not base64-encoded", key))
+			continue
+		}
+		if len(data) > s.maxBlobSize() {
+			return tooLargeError("configuration too large: blob %s is %d bytes, which exceeds the maximum of %d bytes", key, len(data), s.maxBlobSize())
+		}
+	}
+
+	if len(invalid) > 0 {
+		return validationError("configuration has invalid blobs: %s", strings.Join(invalid, "; "))
+	}
+	return nil
+}
+
+// checkBlobs verifies that the values of the environment's blob keys are valid blobs
+func (s *ConfigService) checkBlobs(env *models.Environment, config json.RawMessage) []models.ValidationIssue {
+	if err := s.checkConfigBlobs(env, config); err != nil {
+		return []models.ValidationIssue{{Check: CheckBlobs, Message: err.Error()}}
+	}
+	return nil
+}
+
+// maxBlobSize returns the configured maximum decoded size of a blob value
+func (s *ConfigService) maxBlobSize() int {
+	if s.config == nil || s.config.MaxBlobSize <= 0 {
+		return DefaultMaxBlobSize
+	}
+	return s.config.MaxBlobSize
+}
+
+// decodeBlob decodes a base64 blob value. Whitespace is ignored, so that line-wrapped
+// values such as the body of a PEM certificate are accepted, and both the standard and
+// URL-safe alphabets are accepted with or without padding.
+func decodeBlob(encoded string) ([]byte, error) {
+	compact := CompactBlob(encoded)
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if data, err := encoding.DecodeString(compact); err == nil {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("value is not base64-encoded")
+}
+
+// CompactBlob removes the whitespace of a blob value, such as the line breaks of a
+// wrapped certificate
+func CompactBlob(encoded string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, encoded)
+}
+
+// IsBlobPath reports whether a dot-notated path is one of the blob keys or lies within one
+func IsBlobPath(path string, blobKeys []string) bool {
+	for _, key := range blobKeys {
+		if path == key || strings.HasPrefix(path, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// SummarizeBlobDiff replaces the values of diff entries at blob keys with a short
+// summary of the blob, so that a changed certificate shows up as a changed binary value
+// instead of two long strings. Values that are not strings are left as they are.
+func SummarizeBlobDiff(diff *models.ConfigDiff, blobKeys []string) {
+	if diff == nil || len(blobKeys) == 0 {
+		return
+	}
+
+	for i := range diff.Entries {
+		if !IsBlobPath(diff.Entries[i].Path, blobKeys) {
+			continue
+		}
+		diff.Entries[i].OldValue = summarizeBlob(diff.Entries[i].OldValue)
+		diff.Entries[i].NewValue = summarizeBlob(diff.Entries[i].NewValue)
+	}
+}
+
+// summarizeBlob describes a blob value by its decoded size and a prefix of its SHA-256 hash
+func summarizeBlob(value interface{}) interface{} {
+	encoded, ok := value.(string)
+	if !ok {
+		return value
+	}
+
+	data, err := decodeBlob(encoded)
+	if err != nil {
+		// Values stored before the key was marked as a blob may not be base64
+		data = []byte(encoded)
+	}
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("<binary: %d bytes, sha256:%s>", len(data), hex.EncodeToString(sum[:])[:12])
+}
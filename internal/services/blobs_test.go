@@ -0,0 +1,77 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigService_CheckConfigBlobs(t *testing.T) {
+	service := &ConfigService{config: &Config{MaxBlobSize: 8}}
+	env := &models.Environment{Slug: "dev", BlobKeys: []string{"tls.cert", "logo"}}
+
+	t.Run("base64 values, wrapped or not, are accepted", func(t *testing.T) {
+		err := service.checkConfigBlobs(env, json.RawMessage(`{"tls": {"cert": "YWJj\nZGVm"}, "logo": "YWJjZA"}`))
+		assert.NoError(t, err)
+	})
+
+	t.Run("absent and null blobs are accepted", func(t *testing.T) {
+		err := service.checkConfigBlobs(env, json.RawMessage(`{"logo": null}`))
+		assert.NoError(t, err)
+	})
+
+	t.Run("values that are not base64 strings are reported together", func(t *testing.T) {
+		err := service.checkConfigBlobs(env, json.RawMessage(`{"tls": {"cert": "not base64!"}, "logo": 42}`))
+		require.Error(t, err)
+		assert.Equal(t, "configuration has invalid blobs: tls.cert is marked as a blob but is not base64-encoded; logo is marked as a blob but is not a string", err.Error())
+	})
+
+	t.Run("blobs larger than the maximum are rejected", func(t *testing.T) {
+		err := service.checkConfigBlobs(env, json.RawMessage(`{"logo": "`+base64.StdEncoding.EncodeToString([]byte("123456789"))+`"}`))
+		require.Error(t, err)
+		assert.Equal(t, "configuration too large: blob logo is 9 bytes, which exceeds the maximum of 8 bytes", err.Error())
+	})
+
+	t.Run("validation reports blob issues", func(t *testing.T) {
+		validating := &ConfigService{config: &Config{MaxConfigSize: 1024, MaxConfigDepth: 3, MaxBlobSize: 8}}
+		result := validating.ValidateConfiguration(env, json.RawMessage(`{"logo": "%%%"}`))
+
+		assert.False(t, result.Valid)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, CheckBlobs, result.Errors[0].Check)
+	})
+}
+
+func TestSummarizeBlobDiff(t *testing.T) {
+	oldCert := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 300)))
+	newCert := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("b", 400)))
+	diff, err := DiffConfigs(
+		json.RawMessage(`{"tls": {"cert": "`+oldCert+`"}, "name": "web"}`),
+		json.RawMessage(`{"tls": {"cert": "`+newCert+`"}, "name": "api"}`),
+	)
+	require.NoError(t, err)
+
+	SummarizeBlobDiff(diff, []string{"tls"})
+
+	require.Len(t, diff.Entries, 2)
+	assert.Equal(t, "name", diff.Entries[0].Path)
+	assert.Equal(t, "web", diff.Entries[0].OldValue)
+
+	assert.Equal(t, "tls.cert", diff.Entries[1].Path)
+	assert.Regexp(t, `^<binary: 300 bytes, sha256:[0-9a-f]{12}>$`, diff.Entries[1].OldValue)
+	assert.Regexp(t, `^<binary: 400 bytes, sha256:[0-9a-f]{12}>$`, diff.Entries[1].NewValue)
+}
+
+func TestIsBlobPath(t *testing.T) {
+	keys := []string{"tls.cert"}
+	assert.True(t, IsBlobPath("tls.cert", keys))
+	assert.True(t, IsBlobPath("tls.cert.pem", keys))
+	assert.False(t, IsBlobPath("tls.certificate", keys))
+	assert.False(t, IsBlobPath("tls", keys))
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"remote-config-system/internal/models"
+)
+
+// CreateEnvironments creates several environments of an application, each with an
+// optional initial configuration, in a single transaction. Every environment is checked
+// the way CreateEnvironment checks one. Unless continueOnError is set, any failure leaves
+// the application unchanged; otherwise every environment that can be created is.
+func (s *ConfigService) CreateEnvironments(orgSlug, appSlug string, req *models.BulkCreateEnvironmentsRequest, continueOnError bool) (*models.BulkCreateEnvironmentsResponse, error) {
+	app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
+	if err != nil {
+		return nil, lookupError("application not found", err)
+	}
+
+	results := make([]models.BulkEnvironmentResult, len(req.Environments))
+	envs := []*models.Environment{}
+	configs := []*models.ConfigVersion{}
+	indexes := []int{} // Position in the request of each environment to create
+	seen := make(map[string]bool, len(req.Environments))
+	failed := false
+
+	for i := range req.Environments {
+		item := &req.Environments[i]
+		results[i].Slug = item.Slug
+
+		env, config, err := s.prepareBulkEnvironment(app, appSlug, item, req.CreatedBy, seen)
+		if err != nil {
+			results[i].Status = bulkErrorStatus(err)
+			results[i].Error = err.Error()
+			failed = true
+			continue
+		}
+
+		envs = append(envs, env)
+		configs = append(configs, config)
+		indexes = append(indexes, i)
+	}
+
+	if failed && !continueOnError {
+		return summarizeBulk(abortBulk(results)), nil
+	}
+
+	if len(envs) > 0 {
+		errs, err := s.repos.Environments.CreateMany(envs, configs, continueOnError)
+		itemFailed := false
+		for j, i := range indexes {
+			if errs[j] != nil {
+				results[i].Status = http.StatusInternalServerError
+				results[i].Error = errs[j].Error()
+				itemFailed = true
+			}
+		}
+		if err != nil {
+			if !itemFailed {
+				return nil, fmt.Errorf("failed to create environments: %w", err)
+			}
+			return summarizeBulk(abortBulk(results)), nil
+		}
+	}
+
+	for j, i := range indexes {
+		if results[i].Error != "" {
+			continue
+		}
+
+		env := envs[j]
+		env.Application = app
+		results[i].Status = http.StatusCreated
+		results[i].Created = true
+		results[i].Environment = env
+
+		if config := configs[j]; config != nil {
+			results[i].Version = config.Version
+		}
+	}
+
+	return summarizeBulk(results), nil
+}
+
+// prepareBulkEnvironment checks one environment of a bulk creation request and builds it,
+// along with its initial configuration version if the request has one
+func (s *ConfigService) prepareBulkEnvironment(app *models.Application, appSlug string, item *models.BulkEnvironmentItem, createdBy *string, seen map[string]bool) (*models.Environment, *models.ConfigVersion, error) {
+	if seen[item.Slug] {
+		return nil, nil, conflictError("environment with slug '%s' appears more than once in the request", item.Slug)
+	}
+	seen[item.Slug] = true
+
+	env, err := s.newEnvironment(app, appSlug, &item.CreateEnvironmentRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if item.CreatedBy != nil {
+		createdBy = item.CreatedBy
+	}
+	config, err := s.initialVersion(env, item.Config, createdBy)
+	if err != nil {
+		return nil, nil, err
+	}
+	return env, config, nil
+}
+
+// initialVersion checks the initial configuration of an environment being created and
+// builds its first version; nil when there is no initial configuration
+func (s *ConfigService) initialVersion(env *models.Environment, config json.RawMessage, createdBy *string) (*models.ConfigVersion, error) {
+	if len(config) == 0 || string(config) == "null" {
+		return nil, nil
+	}
+
+	storedConfig, err := s.initialConfig(env, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ConfigVersion{
+		ConfigJSON: storedConfig,
+		IsActive:   true,
+		CreatedBy:  createdBy,
+	}, nil
+}
+
+// initialConfig checks the initial configuration of an environment being created, the way
+// UpdateConfiguration checks an update, and returns it as it is stored
+func (s *ConfigService) initialConfig(env *models.Environment, config json.RawMessage) (json.RawMessage, error) {
+	// Approval is required for every configuration, including the first
+	if env.RequiresApproval {
+		return nil, validationError("invalid configuration: environment %s requires approval, so its configuration must be proposed once it exists", env.Slug)
+	}
+
+	if err := s.checkConfigSize(config); err != nil {
+		return nil, err
+	}
+
+	var configData interface{}
+	if err := json.Unmarshal(config, &configData); err != nil {
+		return nil, invalidError("invalid JSON configuration: %w", err)
+	}
+
+	if err := s.checkConfigDepth(config); err != nil {
+		return nil, err
+	}
+	if err := s.checkConfigKeys(config); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkConfigBlobs(env, config); err != nil {
+		return nil, err
+	}
+
+	storedConfig, _, err := s.encryptor.EncryptSecrets(config, env.SecretKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret values: %w", err)
+	}
+
+	return storedConfig, nil
+}
+
+// bulkErrorStatus returns the HTTP status describing why an environment could not be created
+func bulkErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrValidation):
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// abortBulk marks every environment that did not fail itself as not created because the
+// whole request was rolled back
+func abortBulk(results []models.BulkEnvironmentResult) []models.BulkEnvironmentResult {
+	for i := range results {
+		if results[i].Error == "" {
+			results[i].Status = http.StatusFailedDependency
+			results[i].Error = "not created: another environment failed and the request was rolled back"
+		}
+	}
+	return results
+}
+
+// summarizeBulk counts the created and failed environments of a bulk creation
+func summarizeBulk(results []models.BulkEnvironmentResult) *models.BulkCreateEnvironmentsResponse {
+	response := &models.BulkCreateEnvironmentsResponse{Results: results}
+	for _, result := range results {
+		if result.Created {
+			response.Created++
+		} else {
+			response.Failed++
+		}
+	}
+	return response
+}
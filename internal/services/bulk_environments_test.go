@@ -0,0 +1,108 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigService_InitialConfig(t *testing.T) {
+	service := &ConfigService{config: &Config{MaxConfigSize: 64, MaxConfigDepth: 3}}
+	env := &models.Environment{Slug: "dev"}
+
+	t.Run("valid configuration is stored as is", func(t *testing.T) {
+		stored, err := service.initialConfig(env, json.RawMessage(`{"debug": true}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"debug": true}`, string(stored))
+	})
+
+	t.Run("configuration is checked like an update", func(t *testing.T) {
+		_, err := service.initialConfig(env, json.RawMessage(`{invalid`))
+		assert.ErrorContains(t, err, "invalid JSON configuration")
+
+		_, err = service.initialConfig(env, json.RawMessage(`{"blob": "`+strings.Repeat("x", 64)+`"}`))
+		assert.ErrorContains(t, err, "configuration too large")
+
+		_, err = service.initialConfig(env, json.RawMessage(`{"a":{"b":[{"c":1}]}}`))
+		assert.ErrorContains(t, err, "configuration too deeply nested")
+	})
+
+	t.Run("environments requiring approval cannot have one", func(t *testing.T) {
+		_, err := service.initialConfig(&models.Environment{Slug: "prod", RequiresApproval: true}, json.RawMessage(`{}`))
+		assert.ErrorContains(t, err, "invalid configuration: environment prod requires approval")
+	})
+}
+
+func TestConfigService_InitialVersion(t *testing.T) {
+	service := &ConfigService{config: &Config{MaxConfigSize: 64, MaxConfigDepth: 3}}
+	env := &models.Environment{Slug: "dev"}
+
+	t.Run("no configuration", func(t *testing.T) {
+		for _, config := range []json.RawMessage{nil, json.RawMessage(`null`)} {
+			version, err := service.initialVersion(env, config, nil)
+			require.NoError(t, err)
+			assert.Nil(t, version)
+		}
+	})
+
+	t.Run("configuration becomes an active version", func(t *testing.T) {
+		alice := "alice"
+		version, err := service.initialVersion(env, json.RawMessage(`{"debug": true}`), &alice)
+		require.NoError(t, err)
+		require.NotNil(t, version)
+		assert.True(t, version.IsActive)
+		assert.Equal(t, &alice, version.CreatedBy)
+		assert.JSONEq(t, `{"debug": true}`, string(version.ConfigJSON))
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, err := service.initialVersion(env, json.RawMessage(`{invalid`), nil)
+		assert.ErrorContains(t, err, "invalid JSON configuration")
+	})
+}
+
+func TestBulkErrorStatus(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{conflictError("environment with slug 'dev' already exists in application 'web'"), http.StatusConflict},
+		{conflictError("environment with slug 'dev' appears more than once in the request"), http.StatusConflict},
+		{invalidError("invalid JSON configuration: unexpected end of JSON input"), http.StatusBadRequest},
+		{invalidError("invalid slug \"q/a\": must contain only lowercase letters, digits and hyphens"), http.StatusBadRequest},
+		{tooLargeError("configuration too large: 100 bytes exceeds the maximum of 64 bytes"), http.StatusRequestEntityTooLarge},
+		{validationError("configuration too deeply nested: depth 4 exceeds the maximum of 3"), http.StatusUnprocessableEntity},
+		{validationError("configuration has too many keys: 4 keys exceed the maximum of 3"), http.StatusUnprocessableEntity},
+		{validationError("configuration has invalid blobs: logo is marked as a blob but is not base64-encoded"), http.StatusUnprocessableEntity},
+		{validationError("invalid configuration: environment prod requires approval"), http.StatusUnprocessableEntity},
+		{fmt.Errorf("failed to check environment existence: connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, bulkErrorStatus(tt.err), tt.err.Error())
+	}
+}
+
+func TestAbortBulk(t *testing.T) {
+	results := []models.BulkEnvironmentResult{
+		{Slug: "dev"},
+		{Slug: "staging", Status: http.StatusConflict, Error: "environment with slug 'staging' already exists in application 'web'"},
+		{Slug: "prod"},
+	}
+
+	response := summarizeBulk(abortBulk(results))
+
+	assert.Equal(t, 0, response.Created)
+	assert.Equal(t, 3, response.Failed)
+	assert.Equal(t, http.StatusFailedDependency, response.Results[0].Status)
+	assert.Equal(t, http.StatusConflict, response.Results[1].Status)
+	assert.Equal(t, http.StatusFailedDependency, response.Results[2].Status)
+	assert.Contains(t, response.Results[2].Error, "rolled back")
+}
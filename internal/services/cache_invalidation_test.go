@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	"remote-config-system/internal/cache"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigService_InvalidateAPIKeyCache(t *testing.T) {
+	mr, redisClient := newTestCache(t)
+
+	service := &ConfigService{config: &Config{}, cache: redisClient}
+
+	// Two applications with an environment of the same name
+	appA, appB := uuid.New().String(), uuid.New().String()
+	for _, appID := range []string{appA, appB} {
+		require.NoError(t, redisClient.SetConfig(cache.GenerateAPIKeyConfigKey(appID, "prod"), map[string]int{"version": 1}))
+		require.NoError(t, redisClient.SetConfig(cache.GenerateAPIKeyFlagsKey(appID, "prod"), map[string]int{"version": 1}))
+	}
+	require.NoError(t, redisClient.SetConfig(cache.GenerateAPIKeyConfigKey(appA, "staging"), map[string]int{"version": 1}))
+
+	service.invalidateAPIKeyCache(uuid.MustParse(appA), "prod")
+
+	assert.False(t, mr.Exists(cache.GenerateAPIKeyConfigKey(appA, "prod")))
+	assert.False(t, mr.Exists(cache.GenerateAPIKeyFlagsKey(appA, "prod")))
+	assert.True(t, mr.Exists(cache.GenerateAPIKeyConfigKey(appB, "prod")))
+	assert.True(t, mr.Exists(cache.GenerateAPIKeyFlagsKey(appB, "prod")))
+	assert.True(t, mr.Exists(cache.GenerateAPIKeyConfigKey(appA, "staging")))
+}
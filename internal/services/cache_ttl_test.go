@@ -0,0 +1,40 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"remote-config-system/internal/cache"
+	"remote-config-system/internal/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigService_CacheConfig(t *testing.T) {
+	mr, redisClient := newTestCache(t)
+
+	service := &ConfigService{config: &Config{}, cache: redisClient}
+	ttl := 30
+
+	require.NoError(t, service.cacheConfig("dev", &models.Environment{Slug: "dev", CacheTTLSeconds: &ttl}, map[string]int{"version": 1}))
+	require.NoError(t, service.cacheConfig("prod", &models.Environment{Slug: "prod"}, map[string]int{"version": 1}))
+
+	assert.Equal(t, 30*time.Second, mr.TTL("dev"))
+	assert.Equal(t, 5*time.Minute, mr.TTL("prod"))
+}
+
+// newTestCache starts an in-memory Redis server and connects a cache client with a TTL
+// of five minutes to it
+func newTestCache(t *testing.T) (*miniredis.Miniredis, *cache.RedisClient) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	redisClient, err := cache.NewRedisClient(&cache.Config{Host: mr.Host(), Port: mr.Port(), TTL: 5 * time.Minute})
+	require.NoError(t, err)
+	t.Cleanup(func() { redisClient.Close() })
+
+	return mr, redisClient
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"remote-config-system/internal/models"
+)
+
+// cacheWarming tracks the cache warming run started at startup
+type cacheWarming struct {
+	mu     sync.Mutex
+	status models.CacheWarmStatus
+	done   chan struct{}
+}
+
+// StartCacheWarming warms the cache in the background and returns a channel that is
+// closed once warming has finished, whether it succeeded or not. Calling it again returns
+// the channel of the first run.
+func (s *ConfigService) StartCacheWarming() <-chan struct{} {
+	s.warming.mu.Lock()
+	defer s.warming.mu.Unlock()
+
+	if s.warming.done != nil {
+		return s.warming.done
+	}
+
+	done := make(chan struct{})
+	s.warming.done = done
+	if s.cache == nil {
+		s.warming.status = models.CacheWarmStatus{Status: models.CacheWarmStatusDisabled}
+		close(done)
+		return done
+	}
+
+	startedAt := time.Now()
+	s.warming.status = models.CacheWarmStatus{Status: models.CacheWarmStatusInProgress, StartedAt: &startedAt}
+
+	go func() {
+		defer close(done)
+
+		log.Println("Starting background cache warming...")
+		warmed, err := s.WarmCacheScope("", "")
+		if err != nil {
+			log.Printf("Cache warming failed: %v", err)
+		}
+
+		s.warming.mu.Lock()
+		defer s.warming.mu.Unlock()
+
+		completedAt := time.Now()
+		s.warming.status.CompletedAt = &completedAt
+		if err != nil {
+			s.warming.status.Status = models.CacheWarmStatusFailed
+			s.warming.status.Error = err.Error()
+			return
+		}
+		s.warming.status.Status = models.CacheWarmStatusCompleted
+		s.warming.status.Configurations = warmed
+	}()
+
+	return done
+}
+
+// CacheWarmStatus reports the progress of the cache warming run started at startup
+func (s *ConfigService) CacheWarmStatus() models.CacheWarmStatus {
+	s.warming.mu.Lock()
+	defer s.warming.mu.Unlock()
+
+	if s.warming.done == nil {
+		if s.cache == nil {
+			return models.CacheWarmStatus{Status: models.CacheWarmStatusDisabled}
+		}
+		return models.CacheWarmStatus{Status: models.CacheWarmStatusPending}
+	}
+	return s.warming.status
+}
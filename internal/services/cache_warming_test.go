@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigService_CacheWarmStatus(t *testing.T) {
+	t.Run("without a cache", func(t *testing.T) {
+		service := &ConfigService{config: &Config{}}
+		assert.Equal(t, models.CacheWarmStatusDisabled, service.CacheWarmStatus().Status)
+
+		// There is nothing to wait for
+		<-service.StartCacheWarming()
+		status := service.CacheWarmStatus()
+		assert.Equal(t, models.CacheWarmStatusDisabled, status.Status)
+		assert.Nil(t, status.StartedAt)
+		assert.Equal(t, "disabled", service.HealthCheck()["cache_warming"])
+	})
+
+	t.Run("before warming starts", func(t *testing.T) {
+		_, redisClient := newTestCache(t)
+		service := &ConfigService{config: &Config{}, cache: redisClient}
+
+		assert.Equal(t, models.CacheWarmStatusPending, service.CacheWarmStatus().Status)
+	})
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"remote-config-system/internal/db"
+	"remote-config-system/internal/models"
+)
+
+// compareAndSwapAttempts is how many times a compare-and-swap reads the active version
+// again when another version became active between its read and its write
+const compareAndSwapAttempts = 3
+
+// CompareAndSwapKey sets a dot-notated key of an environment's active configuration to a
+// new value, creating a new version, only if the key currently has the expected value. A
+// nil expected value requires the key to be absent. The comparison and the new version are
+// atomic: a version activated in the meantime makes the key be compared again. A mismatch
+// is a conflict.
+func (s *ConfigService) CompareAndSwapKey(orgSlug, appSlug, envSlug string, req *models.CompareAndSwapRequest) (*models.ConfigResponse, error) {
+	path, err := parseKeyPath(req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+	if err := checkUnlocked(env); err != nil {
+		return nil, err
+	}
+
+	// A proposed version would not be compared again when it is approved
+	if env.RequiresApproval {
+		return nil, invalidError("invalid compare-and-swap: environment %s requires approval", env.Slug)
+	}
+
+	for attempt := 1; ; attempt++ {
+		response, err := s.compareAndSwap(env, path, req)
+		if err == nil {
+			return response, nil
+		}
+		if !errors.Is(err, db.ErrVersionConflict) || attempt == compareAndSwapAttempts {
+			return nil, recordError(err)
+		}
+	}
+}
+
+// compareAndSwap compares the key with the active version and creates the new version
+func (s *ConfigService) compareAndSwap(env *models.Environment, path []string, req *models.CompareAndSwapRequest) (*models.ConfigResponse, error) {
+	var currentVersion *int
+	currentConfig := json.RawMessage(`{}`)
+	if active, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID); err == nil {
+		currentVersion = &active.Version
+		currentConfig, err = s.encryptor.DecryptSecrets(active.ConfigJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt active configuration: %w", err)
+		}
+	}
+
+	document, err := decodeReferenceTarget(currentConfig)
+	if err != nil {
+		return nil, err
+	}
+	current, present := lookupReference(document, strings.Join(path, "."))
+
+	if err := compareKeyValue(req.Key, current, present, req.Expected); err != nil {
+		return nil, err
+	}
+
+	value, err := decodeReferenceTarget(req.Value)
+	if err != nil {
+		return nil, invalidError("invalid compare-and-swap: value is not valid JSON: %w", err)
+	}
+	if err := setKeyValue(document, path, value); err != nil {
+		return nil, err
+	}
+
+	config, err := json.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := s.checkConfigSize(config); err != nil {
+		return nil, err
+	}
+	storedConfig, err := s.prepareConfig(env, config)
+	if err != nil {
+		return nil, err
+	}
+
+	newVersion := &models.ConfigVersion{
+		EnvID:      env.ID,
+		ConfigJSON: storedConfig,
+		CreatedBy:  req.CreatedBy,
+	}
+	change := &models.ConfigChange{
+		EnvID:       env.ID,
+		VersionFrom: currentVersion,
+		Action:      "update",
+		CreatedBy:   req.CreatedBy,
+		Message:     req.Message,
+	}
+	if currentVersion == nil {
+		change.Action = "create"
+	}
+
+	if err := s.repos.ConfigVersions.CompareAndSwap(newVersion, change); err != nil {
+		return nil, err
+	}
+	s.endRollout(env)
+	log.Printf("Swapped %s in %s/%s/%s (version %d)", req.Key, env.Application.Organization.Slug, env.Application.Slug, env.Slug, newVersion.Version)
+
+	return s.publishUpdate(env, newVersion, config, change), nil
+}
+
+// parseKeyPath splits a dot-notated key into its parts, none of which may be empty
+func parseKeyPath(key string) ([]string, error) {
+	path := strings.Split(key, ".")
+	for _, part := range path {
+		if part == "" {
+			return nil, invalidError("invalid compare-and-swap: key %q has an empty part", key)
+		}
+	}
+	return path, nil
+}
+
+// compareKeyValue returns a conflict error unless the current value of a key is the
+// expected one. Values are compared as decoded JSON, so formatting and key order do not
+// matter. Values are left out of the error, as the key may be a secret.
+func compareKeyValue(key string, current interface{}, present bool, expected json.RawMessage) error {
+	if expected == nil {
+		if present {
+			return conflictError("compare-and-swap failed: %s is already set", key)
+		}
+		return nil
+	}
+	if !present {
+		return conflictError("compare-and-swap failed: %s is not set", key)
+	}
+
+	var want interface{}
+	if err := json.Unmarshal(expected, &want); err != nil {
+		return invalidError("invalid compare-and-swap: expected value is not valid JSON: %w", err)
+	}
+
+	// Numbers of the current value are decoded again, as floats like the expected ones
+	encoded, err := json.Marshal(current)
+	if err != nil {
+		return fmt.Errorf("failed to encode current value: %w", err)
+	}
+	var got interface{}
+	if err := json.Unmarshal(encoded, &got); err != nil {
+		return fmt.Errorf("failed to decode current value: %w", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		return conflictError("compare-and-swap failed: %s does not have the expected value", key)
+	}
+	return nil
+}
+
+// setKeyValue sets the value at a path of a decoded configuration, creating the objects
+// along the path that do not exist yet
+func setKeyValue(document interface{}, path []string, value interface{}) error {
+	object, ok := document.(map[string]interface{})
+	if !ok {
+		return invalidError("invalid compare-and-swap: the configuration is not an object")
+	}
+
+	for i, part := range path[:len(path)-1] {
+		next, exists := object[part]
+		if !exists {
+			next = map[string]interface{}{}
+			object[part] = next
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return invalidError("invalid compare-and-swap: %s is not an object", strings.Join(path[:i+1], "."))
+		}
+		object = child
+	}
+
+	object[path[len(path)-1]] = value
+	return nil
+}
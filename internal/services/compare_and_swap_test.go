@@ -0,0 +1,70 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareKeyValue(t *testing.T) {
+	t.Run("equal values", func(t *testing.T) {
+		assert.NoError(t, compareKeyValue("leader", "worker-1", true, json.RawMessage(`"worker-1"`)))
+		assert.NoError(t, compareKeyValue("limits", map[string]interface{}{"rps": json.Number("100"), "burst": json.Number("5")}, true, json.RawMessage(`{"burst": 5, "rps": 100.0}`)))
+		assert.NoError(t, compareKeyValue("holder", nil, true, json.RawMessage(`null`)))
+	})
+
+	t.Run("different values", func(t *testing.T) {
+		err := compareKeyValue("leader", "worker-2", true, json.RawMessage(`"worker-1"`))
+		assert.True(t, errors.Is(err, ErrConflict))
+		assert.NotContains(t, err.Error(), "worker-2")
+
+		assert.True(t, errors.Is(compareKeyValue("enabled", true, true, json.RawMessage(`"true"`)), ErrConflict))
+	})
+
+	t.Run("absence", func(t *testing.T) {
+		assert.NoError(t, compareKeyValue("leader", nil, false, nil))
+		assert.True(t, errors.Is(compareKeyValue("leader", "worker-1", true, nil), ErrConflict))
+		assert.True(t, errors.Is(compareKeyValue("leader", nil, true, nil), ErrConflict))
+		assert.True(t, errors.Is(compareKeyValue("leader", nil, false, json.RawMessage(`null`)), ErrConflict))
+	})
+}
+
+func TestSetKeyValue(t *testing.T) {
+	t.Run("creates missing objects", func(t *testing.T) {
+		document := map[string]interface{}{"timeout": 30}
+		require.NoError(t, setKeyValue(document, []string{"leader", "holder"}, "worker-1"))
+		assert.Equal(t, map[string]interface{}{
+			"timeout": 30,
+			"leader":  map[string]interface{}{"holder": "worker-1"},
+		}, document)
+	})
+
+	t.Run("replaces the value", func(t *testing.T) {
+		document := map[string]interface{}{"leader": map[string]interface{}{"holder": "worker-1", "term": 3}}
+		require.NoError(t, setKeyValue(document, []string{"leader", "holder"}, "worker-2"))
+		assert.Equal(t, map[string]interface{}{"holder": "worker-2", "term": 3}, document["leader"])
+	})
+
+	t.Run("parents must be objects", func(t *testing.T) {
+		err := setKeyValue(map[string]interface{}{"leader": "worker-1"}, []string{"leader", "holder"}, "worker-2")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid compare-and-swap: leader is not an object")
+
+		err = setKeyValue([]interface{}{}, []string{"leader"}, "worker-2")
+		require.Error(t, err)
+	})
+}
+
+func TestParseKeyPath(t *testing.T) {
+	path, err := parseKeyPath("leader.holder")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"leader", "holder"}, path)
+
+	for _, key := range []string{".leader", "leader.", "leader..holder"} {
+		_, err := parseKeyPath(key)
+		assert.Error(t, err, key)
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config holds configuration service settings
+type Config struct {
+	MaxConfigSize  int // Largest accepted configuration document, in bytes
+	MaxConfigDepth int // Deepest accepted nesting of objects and arrays
+	MaxConfigKeys  int // Most accepted keys of all objects of a configuration (0 is unlimited)
+	MaxBlobSize    int // Largest accepted decoded blob value, in bytes
+
+	MaxReferenceDepth int // References followed at most to resolve one value
+
+	RetentionVersions int           // Latest versions kept per environment (0 keeps none by count)
+	RetentionDays     int           // Days versions are kept per environment (0 keeps none by age)
+	PruneInterval     time.Duration // Time between background runs of the retention policies
+
+	UsageFlushInterval time.Duration // Time between flushes of the API key usage and organization read counters to the database
+
+	OrgMonthlyReadQuota int // Configuration reads allowed per organization and calendar month (0 is unlimited)
+
+	StaleDays          int           // Days without changes or reads after which an environment is reported as stale
+	StaleCheckInterval time.Duration // Time between background stale configuration checks
+}
+
+// NewConfig creates a new service configuration from environment variables
+func NewConfig() *Config {
+	return &Config{
+		MaxConfigSize:  getEnvInt("CONFIG_MAX_SIZE", DefaultMaxConfigSize),
+		MaxConfigDepth: getEnvInt("CONFIG_MAX_DEPTH", DefaultMaxConfigDepth),
+		MaxConfigKeys:  getEnvInt("CONFIG_MAX_KEYS", DefaultMaxConfigKeys),
+		MaxBlobSize:    getEnvInt("CONFIG_MAX_BLOB_SIZE", DefaultMaxBlobSize),
+
+		MaxReferenceDepth: getEnvInt("CONFIG_REF_MAX_DEPTH", DefaultMaxReferenceDepth),
+
+		RetentionVersions: getEnvInt("CONFIG_RETENTION_VERSIONS", 0),
+		RetentionDays:     getEnvInt("CONFIG_RETENTION_DAYS", 0),
+		PruneInterval:     time.Duration(getEnvInt("CONFIG_PRUNE_INTERVAL", 3600)) * time.Second,
+
+		UsageFlushInterval: time.Duration(getEnvInt("CONFIG_USAGE_FLUSH_INTERVAL", int(DefaultUsageFlushInterval/time.Second))) * time.Second,
+
+		OrgMonthlyReadQuota: getEnvInt("CONFIG_ORG_MONTHLY_READ_QUOTA", 0),
+
+		StaleDays:          getEnvInt("CONFIG_STALE_DAYS", DefaultStaleDays),
+		StaleCheckInterval: time.Duration(getEnvInt("CONFIG_STALE_CHECK_INTERVAL", int(DefaultStaleCheckInterval/time.Second))) * time.Second,
+	}
+}
+
+// getEnvInt gets a positive integer environment variable with a fallback value
+func getEnvInt(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return fallback
+}
@@ -4,29 +4,102 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"time"
 
 	"remote-config-system/internal/cache"
 	"remote-config-system/internal/db"
 	"remote-config-system/internal/models"
+	"remote-config-system/internal/notify"
 	"remote-config-system/internal/sse"
+
+	"github.com/google/uuid"
 )
 
 // ConfigServiceInterface defines the interface for configuration service operations
 type ConfigServiceInterface interface {
 	// Authentication
 	ValidateAPIKey(apiKey string) (*models.Application, error)
+	GetApplication(orgSlug, appSlug string) (*models.Application, error)
+	ValidateAdminToken(token string) (*models.AdminToken, error)
 
 	// Configuration operations
 	GetConfiguration(orgSlug, appSlug, envSlug string) (*models.ConfigResponse, error)
-	GetConfigurationByAPIKey(apiKey, envSlug string) (*models.ConfigResponse, error)
-	UpdateConfiguration(orgSlug, appSlug, envSlug string, req *models.CreateConfigRequest) (*models.ConfigResponse, error)
+	GetRawConfiguration(orgSlug, appSlug, envSlug string) (*models.ConfigResponse, error)
+	GetConfigurationByAPIKey(app *models.Application, envSlug, clientID string) (*models.ConfigResponse, error)
+	GetConfigurationsByAPIKey(app *models.Application, envSlugs []string, clientID string) (*models.BatchConfigResponse, error)
+	UpdateConfiguration(orgSlug, appSlug, envSlug string, req *models.CreateConfigRequest, dryRun bool) (*models.ConfigResponse, error)
+	UpdateConfigurations(orgSlug, appSlug string, req *models.BatchUpdateConfigRequest, dryRun bool) (*models.BatchConfigResponse, error)
+	UpdateConfigurationsBySelector(orgSlug, appSlug, selector string, req *models.BatchUpdateConfigRequest, dryRun bool) (*models.BatchConfigResponse, error)
 	RollbackConfiguration(orgSlug, appSlug, envSlug string, req *models.RollbackRequest) (*models.ConfigResponse, error)
+	CompareAndSwapKey(orgSlug, appSlug, envSlug string, req *models.CompareAndSwapRequest) (*models.ConfigResponse, error)
+	PromoteConfiguration(orgSlug, appSlug, envSlug string, req *models.PromoteConfigRequest, dryRun bool) (*models.ConfigResponse, error)
 	GetConfigurationHistory(orgSlug, appSlug, envSlug string, params models.PaginationParams) (*models.PaginatedResponse, error)
 	GetConfigurationVersion(orgSlug, appSlug, envSlug string, version int) (*models.ConfigResponse, error)
+	GetConfigurationVersionByAPIKey(app *models.Application, envSlug string, version int) (*models.ConfigResponse, error)
+	GetConfigurationAtTime(orgSlug, appSlug, envSlug string, at time.Time) (*models.ConfigResponse, error)
+	GetConfigurationMeta(orgSlug, appSlug, envSlug string, version int) (*models.ConfigMeta, error)
 	GetConfigurationChanges(orgSlug, appSlug, envSlug string, params models.PaginationParams) (*models.PaginatedResponse, error)
+	ExportConfigurationChanges(orgSlug, appSlug, envSlug string, orgScope bool, fn func(*models.ConfigChange) error) error
+	DiffDraftConfiguration(orgSlug, appSlug, envSlug string, draft json.RawMessage) (*models.ConfigDiffResponse, error)
+	LintConfiguration(orgSlug, appSlug, envSlug string, config json.RawMessage) (*models.ConfigLintResponse, error)
+	EncryptExistingSecrets(orgSlug, appSlug, envSlug string) (*models.EncryptSecretsResponse, error)
+	PruneHistory(orgSlug, appSlug, envSlug string) (*models.PruneHistoryResponse, error)
+	GetKeyHistory(orgSlug, appSlug, envSlug, keyPath string) (*models.KeyHistoryResponse, error)
+	SearchConfigurations(key, value string, params models.PaginationParams) (*models.PaginatedResponse, error)
+	ListPendingChanges(orgSlug, appSlug, envSlug, status string, params models.PaginationParams) (*models.PaginatedResponse, error)
+	ApprovePendingChange(orgSlug, appSlug, envSlug string, req *models.ReviewChangeRequest) (*models.ConfigResponse, error)
+	RejectPendingChange(orgSlug, appSlug, envSlug string, req *models.ReviewChangeRequest) (*models.PendingChange, error)
+
+	// Tag operations
+	ListTags(orgSlug, appSlug, envSlug string) ([]models.ConfigTag, error)
+	SetTag(orgSlug, appSlug, envSlug, name string, req *models.SetTagRequest) (*models.ConfigTag, bool, error)
+	DeleteTag(orgSlug, appSlug, envSlug, name string, deletedBy *string) error
+	GetConfigurationByTag(orgSlug, appSlug, envSlug, name string) (*models.ConfigResponse, error)
+	GetConfigurationByAPIKeyAndTag(app *models.Application, envSlug, name string) (*models.ConfigResponse, error)
+	GetFlagsByAPIKey(app *models.Application, envSlug string, names []string, defaultValue *bool) (*models.FlagSet, error)
+
+	// Application defaults operations
+	GetApplicationDefaults(orgSlug, appSlug string) (*models.ApplicationDefaults, error)
+	SetApplicationDefaults(orgSlug, appSlug string, req *models.UpdateApplicationDefaultsRequest) (*models.ApplicationDefaults, error)
+	DeleteApplicationDefaults(orgSlug, appSlug string, deletedBy *string) error
+
+	// Rollout operations
+	GetRollout(orgSlug, appSlug, envSlug string) (*models.ConfigRollout, error)
+	UpdateRollout(orgSlug, appSlug, envSlug string, req *models.UpdateRolloutRequest) (*models.ConfigRollout, error)
+	PromoteRollout(orgSlug, appSlug, envSlug string, req *models.PromoteRolloutRequest) (*models.ConfigResponse, error)
+	AbortRollout(orgSlug, appSlug, envSlug string, abortedBy *string) error
+
+	// Schema operations
+	GetConfigSchema(orgSlug, appSlug, envSlug string) (*models.ConfigSchema, error)
+	SetConfigSchema(orgSlug, appSlug, envSlug string, req *models.UpdateConfigSchemaRequest) (*models.ConfigSchema, error)
+	GetFormSchema(orgSlug, appSlug, envSlug string) (*models.FormSchemaResponse, error)
+
+	// Validation rule operations
+	GetConfigRules(orgSlug, appSlug, envSlug string) (*models.ConfigRuleset, error)
+	SetConfigRules(orgSlug, appSlug, envSlug string, req *models.UpdateConfigRulesRequest) (*models.ConfigRuleset, error)
+	DeleteConfigRules(orgSlug, appSlug, envSlug string) error
+	ValidateManifest(items []models.ManifestItem) *models.ManifestValidationResponse
+
+	// Template operations
+	ListTemplates(orgSlug string) ([]models.ConfigTemplate, error)
+	GetTemplate(orgSlug, name string) (*models.ConfigTemplate, error)
+	SetTemplate(orgSlug, name string, req *models.SetConfigTemplateRequest) (*models.ConfigTemplate, bool, error)
+	DeleteTemplate(orgSlug, name string) error
+	InstantiateTemplate(orgSlug, appSlug, envSlug string, req *models.InstantiateTemplateRequest, dryRun bool) (*models.ConfigResponse, error)
+
+	// Notification operations
+	GetNotificationSettings(orgSlug, appSlug, envSlug string) (*models.NotificationSettings, error)
+	SetNotificationSettings(orgSlug, appSlug, envSlug string, req *models.UpdateNotificationSettingsRequest) (*models.NotificationSettings, error)
+	DeleteNotificationSettings(orgSlug, appSlug, envSlug string) error
+
+	// Read quotas and usage
+	CheckOrganizationReadQuota(orgSlug string) error
+	RecordOrganizationRead(orgSlug string) error
+	RecordAPIKeyUsage(app *models.Application, envSlug string) error
 
 	// Health check
 	HealthCheck() map[string]string
@@ -34,30 +107,57 @@ type ConfigServiceInterface interface {
 
 // ConfigService handles configuration business logic
 type ConfigService struct {
+	config     *Config
 	repos      *db.Repositories
 	cache      *cache.RedisClient
 	sseService sse.SSEServiceInterface
+	encryptor  *ConfigEncryptor
+	notifier   notify.Notifier
+	warming    cacheWarming
+	stale      staleCheck
 }
 
 // NewConfigService creates a new configuration service
-func NewConfigService(repos *db.Repositories, cacheClient *cache.RedisClient, sseService sse.SSEServiceInterface) *ConfigService {
+func NewConfigService(config *Config, repos *db.Repositories, cacheClient *cache.RedisClient, sseService sse.SSEServiceInterface) *ConfigService {
 	return &ConfigService{
+		config:     config,
 		repos:      repos,
 		cache:      cacheClient,
 		sseService: sseService,
 	}
 }
 
+// SetEncryptor configures the encryptor used for secret configuration values.
+// A nil encryptor disables encryption; storing values for secret keys then fails.
+func (s *ConfigService) SetEncryptor(encryptor *ConfigEncryptor) {
+	s.encryptor = encryptor
+}
+
+// decryptResponse returns a copy of the response with its secret values decrypted. The
+// decrypted keys are recorded, so that public reads can mask them with MaskSecretValues.
+func (s *ConfigService) decryptResponse(response *models.ConfigResponse) (*models.ConfigResponse, error) {
+	config, err := s.encryptor.DecryptSecrets(response.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt configuration: %w", err)
+	}
+
+	decrypted := *response
+	decrypted.Config = config
+	decrypted.SecretKeys = EncryptedKeys(response.Config)
+	return &decrypted, nil
+}
+
 // GetConfiguration retrieves the active configuration for an environment
 func (s *ConfigService) GetConfiguration(orgSlug, appSlug, envSlug string) (*models.ConfigResponse, error) {
 	// Try to get from cache first
-	if s.cache != nil {
+	if s.cacheAvailable() {
 		cacheKey := cache.GenerateConfigKey(orgSlug, appSlug, envSlug)
-		if cachedData, err := s.cache.GetConfig(cacheKey); err == nil && cachedData != nil {
+		if cachedData := s.readCache(cacheKey); cachedData != nil {
 			var response models.ConfigResponse
-			if err := json.Unmarshal(cachedData, &response); err == nil {
+			err := json.Unmarshal(cachedData, &response)
+			if err == nil {
 				log.Printf("Cache hit for config: %s", cacheKey)
-				return &response, nil
+				return s.decryptResponse(&response)
 			}
 			log.Printf("Failed to unmarshal cached config: %v", err)
 		}
@@ -66,13 +166,13 @@ func (s *ConfigService) GetConfiguration(orgSlug, appSlug, envSlug string) (*mod
 	// Get the environment with all relationships
 	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
 	if err != nil {
-		return nil, fmt.Errorf("environment not found: %w", err)
+		return nil, lookupError("environment not found", err)
 	}
 
 	// Get the active configuration version
 	configVersion, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID)
 	if err != nil {
-		return nil, fmt.Errorf("no active configuration found: %w", err)
+		return nil, lookupError("no active configuration found", err)
 	}
 
 	// Build the response
@@ -81,55 +181,160 @@ func (s *ConfigService) GetConfiguration(orgSlug, appSlug, envSlug string) (*mod
 		Application:  env.Application.Slug,
 		Environment:  env.Slug,
 		Version:      configVersion.Version,
+		VersionID:    &configVersion.ID,
 		Config:       configVersion.ConfigJSON,
 		UpdatedAt:    configVersion.CreatedAt,
+		BlobKeys:     env.BlobKeys,
 	}
 
-	// Cache the response with appropriate TTL
-	if s.cache != nil {
+	// Layer the configuration over the application defaults
+	defaults, err := s.loadDefaults(env.AppID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyDefaults(response, defaults); err != nil {
+		return nil, err
+	}
+
+	// Resolve references to other environments; values of secret keys stay encrypted
+	if err := s.resolveReferences(response, s.referenceLoader(env.Application)); err != nil {
+		return nil, err
+	}
+
+	// Cache the response with the environment's TTL
+	if s.cacheAvailable() {
 		cacheKey := cache.GenerateConfigKey(orgSlug, appSlug, envSlug)
-		// Use default TTL for configuration data
-		if err := s.cache.SetConfig(cacheKey, response); err != nil {
+		if err := s.cacheConfig(cacheKey, env, response); err != nil {
 			log.Printf("Failed to cache config: %v", err)
 		} else {
 			log.Printf("Cached config: %s", cacheKey)
 		}
 	}
 
-	return response, nil
+	return s.decryptResponse(response)
 }
 
-// GetConfigurationByAPIKey retrieves configuration using API key authentication
-func (s *ConfigService) GetConfigurationByAPIKey(apiKey, envSlug string) (*models.ConfigResponse, error) {
+// cacheConfig caches a configuration of an environment for the environment's cache TTL,
+// or for the global TTL if the environment does not override it
+func (s *ConfigService) cacheConfig(key string, env *models.Environment, value interface{}) error {
+	if ttl := environmentCacheTTL(env); ttl > 0 {
+		return s.cache.SetConfigWithTTL(key, value, ttl)
+	}
+	return s.cache.SetConfig(key, value)
+}
+
+// environmentCacheTTL returns the cache TTL set on an environment, or 0 if it has none
+func environmentCacheTTL(env *models.Environment) time.Duration {
+	if env.CacheTTLSeconds == nil {
+		return 0
+	}
+	return time.Duration(*env.CacheTTLSeconds) * time.Second
+}
+
+// GetConfigurationByAPIKey retrieves configuration for an application authenticated by
+// its API key or a token. While a rollout is in progress, clientID decides whether the
+// rollout version is served.
+func (s *ConfigService) GetConfigurationByAPIKey(app *models.Application, envSlug, clientID string) (*models.ConfigResponse, error) {
 	// Try to get from cache first
-	if s.cache != nil {
-		cacheKey := cache.GenerateAPIKeyConfigKey(apiKey, envSlug)
-		if cachedData, err := s.cache.GetConfig(cacheKey); err == nil && cachedData != nil {
-			var response models.ConfigResponse
-			if err := json.Unmarshal(cachedData, &response); err == nil {
-				log.Printf("Cache hit for API key config: %s", cacheKey)
-				return &response, nil
+	if entry, ok := s.getCachedAPIKeyConfig(app, envSlug); ok {
+		return s.decryptResponse(entry.forClient(clientID))
+	}
+
+	entry, err := s.loadAPIKeyConfig(app, envSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decryptResponse(entry.forClient(clientID))
+}
+
+// GetConfigurationsByAPIKey retrieves the active configurations of several environments
+// of an application at once. Cached configurations are served without touching the
+// database, and failures are reported per environment rather than failing the whole batch.
+func (s *ConfigService) GetConfigurationsByAPIKey(app *models.Application, envSlugs []string, clientID string) (*models.BatchConfigResponse, error) {
+	response := &models.BatchConfigResponse{
+		Configs: make(map[string]*models.ConfigResponse),
+		Errors:  make(map[string]models.BatchConfigError),
+	}
+
+	for _, envSlug := range envSlugs {
+		// Skip duplicates
+		if _, done := response.Configs[envSlug]; done {
+			continue
+		}
+		if _, done := response.Errors[envSlug]; done {
+			continue
+		}
+
+		entry, ok := s.getCachedAPIKeyConfig(app, envSlug)
+		if !ok {
+			var err error
+			entry, err = s.loadAPIKeyConfig(app, envSlug)
+			if err != nil {
+				response.Errors[envSlug] = batchConfigError(err)
+				continue
 			}
-			log.Printf("Failed to unmarshal cached API key config: %v", err)
 		}
+
+		config, err := s.decryptResponse(entry.forClient(clientID))
+		if err != nil {
+			response.Errors[envSlug] = batchConfigError(err)
+			continue
+		}
+		response.Configs[envSlug] = config
 	}
 
-	// Get the application by API key
-	app, err := s.repos.Applications.GetByAPIKey(apiKey)
-	if err != nil {
-		return nil, fmt.Errorf("invalid API key: %w", err)
+	return response, nil
+}
+
+// batchConfigError describes why a single environment of a batch could not be fetched
+func batchConfigError(err error) models.BatchConfigError {
+	status := http.StatusInternalServerError
+	if errors.Is(err, ErrNotFound) {
+		status = http.StatusNotFound
+	} else if errors.Is(err, ErrBrokenReference) {
+		status = http.StatusUnprocessableEntity
+	}
+	return models.BatchConfigError{Status: status, Message: err.Error()}
+}
+
+// getCachedAPIKeyConfig returns the cached, still encrypted, configurations for an
+// application's API key and environment
+func (s *ConfigService) getCachedAPIKeyConfig(app *models.Application, envSlug string) (*cachedAPIKeyConfig, bool) {
+	if !s.cacheAvailable() {
+		return nil, false
+	}
+
+	cacheKey := cache.GenerateAPIKeyConfigKey(app.ID.String(), envSlug)
+	cachedData := s.readCache(cacheKey)
+	if cachedData == nil {
+		return nil, false
+	}
+
+	var entry cachedAPIKeyConfig
+	if err := json.Unmarshal(cachedData, &entry); err != nil || entry.Stable == nil {
+		log.Printf("Failed to unmarshal cached API key config: %v", err)
+		return nil, false
 	}
 
+	log.Printf("Cache hit for API key config: %s", cacheKey)
+	return &entry, true
+}
+
+// loadAPIKeyConfig loads the active configuration of an application's environment, and
+// the rollout configuration if a rollout is in progress, from the database and caches
+// them. The returned configurations are still encrypted.
+func (s *ConfigService) loadAPIKeyConfig(app *models.Application, envSlug string) (*cachedAPIKeyConfig, error) {
 	// Get the environment
 	env, err := s.repos.Environments.GetBySlug(app.Organization.Slug, app.Slug, envSlug)
 	if err != nil {
-		return nil, fmt.Errorf("environment not found: %w", err)
+		return nil, lookupError("environment not found", err)
 	}
 
 	// Get the active configuration version
 	configVersion, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID)
 	if err != nil {
-		return nil, fmt.Errorf("no active configuration found: %w", err)
+		return nil, lookupError("no active configuration found", err)
 	}
 
 	// Build the response
@@ -138,54 +343,173 @@ func (s *ConfigService) GetConfigurationByAPIKey(apiKey, envSlug string) (*model
 		Application:  app.Slug,
 		Environment:  env.Slug,
 		Version:      configVersion.Version,
+		VersionID:    &configVersion.ID,
 		Config:       configVersion.ConfigJSON,
 		UpdatedAt:    configVersion.CreatedAt,
+		BlobKeys:     env.BlobKeys,
+	}
+	entry := &cachedAPIKeyConfig{Stable: response}
+
+	// Include the version being rolled out, if any
+	if rollout, err := s.repos.ConfigRollouts.GetByEnvironment(env.ID); err == nil {
+		if rolloutVersion, err := s.repos.ConfigVersions.GetByVersion(env.ID, rollout.Version); err == nil {
+			entry.Rollout = &models.ConfigResponse{
+				Organization: app.Organization.Slug,
+				Application:  app.Slug,
+				Environment:  env.Slug,
+				Version:      rolloutVersion.Version,
+				VersionID:    &rolloutVersion.ID,
+				Config:       rolloutVersion.ConfigJSON,
+				UpdatedAt:    rolloutVersion.CreatedAt,
+				BlobKeys:     env.BlobKeys,
+			}
+			entry.Percentage = rollout.Percentage
+		}
+	}
+
+	// Layer both configurations over the application defaults
+	defaults, err := s.loadDefaults(env.AppID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyDefaults(entry.Stable, defaults); err != nil {
+		return nil, err
+	}
+	if err := applyDefaults(entry.Rollout, defaults); err != nil {
+		return nil, err
+	}
+
+	// Resolve references to other environments in both configurations
+	if err := s.resolveReferences(entry.Stable, s.referenceLoader(app)); err != nil {
+		return nil, err
+	}
+	if err := s.resolveReferences(entry.Rollout, s.referenceLoader(app)); err != nil {
+		return nil, err
 	}
 
 	// Cache the response
-	if s.cache != nil {
-		cacheKey := cache.GenerateAPIKeyConfigKey(apiKey, envSlug)
-		if err := s.cache.SetConfig(cacheKey, response); err != nil {
+	if s.cacheAvailable() {
+		cacheKey := cache.GenerateAPIKeyConfigKey(app.ID.String(), envSlug)
+		if err := s.cacheConfig(cacheKey, env, entry); err != nil {
 			log.Printf("Failed to cache API key config: %v", err)
 		} else {
 			log.Printf("Cached API key config: %s", cacheKey)
 		}
 	}
 
-	return response, nil
+	return entry, nil
 }
 
-// UpdateConfiguration creates a new configuration version and sets it as active
-func (s *ConfigService) UpdateConfiguration(orgSlug, appSlug, envSlug string, req *models.CreateConfigRequest) (*models.ConfigResponse, error) {
+// UpdateConfiguration creates a new configuration version and sets it as active.
+// When dryRun is set, the update is validated and diffed against the active version
+// but nothing is persisted, invalidated or broadcast. An update with the same configuration
+// as the active version returns that version flagged as unchanged, unless
+// req.AllowUnchanged is set.
+func (s *ConfigService) UpdateConfiguration(orgSlug, appSlug, envSlug string, req *models.CreateConfigRequest, dryRun bool) (*models.ConfigResponse, error) {
+	// Reject oversized documents before doing any other work
+	if err := s.checkConfigSize(req.Config); err != nil {
+		return nil, err
+	}
+
+	// Store the canonical form, so that versions only differ where their values do
+	config, err := NormalizeConfig(req.Config)
+	if err != nil {
+		return nil, err
+	}
+	normalized := *req
+	normalized.Config = config
+
 	// Get the environment
 	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
 	if err != nil {
-		return nil, fmt.Errorf("environment not found: %w", err)
+		return nil, lookupError("environment not found", err)
 	}
 
-	// Validate JSON
-	var configData interface{}
-	if err := json.Unmarshal(req.Config, &configData); err != nil {
-		return nil, fmt.Errorf("invalid JSON configuration: %w", err)
+	return s.updateEnvironmentConfig(env, &normalized, dryRun, nil)
+}
+
+// updateEnvironmentConfig validates an update of an environment's configuration and creates
+// the new version. Versions copied from another environment are logged as promotions.
+func (s *ConfigService) updateEnvironmentConfig(env *models.Environment, req *models.CreateConfigRequest, dryRun bool, source *promotionSource) (*models.ConfigResponse, error) {
+	storedConfig, err := s.prepareConfig(env, req.Config)
+	if err != nil {
+		return nil, err
 	}
 
+	// Report keys that differ from the golden environment along with the update
+	golden, err := s.compareGolden(env, req.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.applyConfigUpdate(env, req, storedConfig, dryRun, source)
+	if err != nil {
+		return nil, err
+	}
+	response.Golden = golden
+	return response, nil
+}
+
+// applyConfigUpdate previews, proposes, rolls out or activates a checked update of an
+// environment's configuration
+func (s *ConfigService) applyConfigUpdate(env *models.Environment, req *models.CreateConfigRequest, storedConfig json.RawMessage, dryRun bool, source *promotionSource) (*models.ConfigResponse, error) {
 	// Get the current active version (if any) for change logging
 	var currentVersion *int
-	if activeConfig, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID); err == nil {
+	currentConfig := json.RawMessage(`{}`)
+	activeConfig, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID)
+	if err == nil {
 		currentVersion = &activeConfig.Version
+		currentConfig = activeConfig.ConfigJSON
+	}
+
+	if dryRun {
+		return s.previewUpdate(env, currentConfig, req.Config)
+	}
+
+	// Locked environments can still preview an update, but not make it
+	if err := checkUnlocked(env); err != nil {
+		return nil, err
+	}
+
+	// An update that changes nothing creates no version, unless the client asks for one.
+	// Promotions are always logged, as they record where the configuration came from.
+	if currentVersion != nil && source == nil && !req.AllowUnchanged {
+		response, err := s.unchangedUpdate(env, activeConfig, req.Config)
+		if err != nil || response != nil {
+			return response, err
+		}
+	}
+
+	// Environments that require approval only get a proposed version for now
+	if env.RequiresApproval {
+		if req.RolloutPercentage != nil && *req.RolloutPercentage < 100 {
+			return nil, invalidError("invalid rollout: environment %s requires approval", env.Slug)
+		}
+		// With admin auth, the admin token is the proposer, so it cannot approve its own change
+		proposedBy := req.CreatedBy
+		if req.AdminToken != nil {
+			proposedBy = req.AdminToken
+		}
+		return s.proposeConfiguration(env, storedConfig, req.Config, currentVersion, req.CreatedBy, proposedBy, req.Message)
+	}
+
+	// A partial rollout needs an active version for the remaining clients
+	if req.RolloutPercentage != nil && *req.RolloutPercentage < 100 && currentVersion != nil {
+		return s.startRollout(env, storedConfig, req.Config, *currentVersion, *req.RolloutPercentage, req.CreatedBy, req.Message)
 	}
 
 	// Create new configuration version
 	newVersion := &models.ConfigVersion{
 		EnvID:      env.ID,
-		ConfigJSON: req.Config,
+		ConfigJSON: storedConfig,
 		IsActive:   true,
 		CreatedBy:  req.CreatedBy,
 	}
 
 	if err := s.repos.ConfigVersions.Create(newVersion); err != nil {
-		return nil, fmt.Errorf("failed to create configuration version: %w", err)
+		return nil, fmt.Errorf("failed to create configuration version: %w", recordError(err))
 	}
+	s.endRollout(env)
 
 	// Log the change
 	change := &models.ConfigChange{
@@ -194,12 +518,108 @@ func (s *ConfigService) UpdateConfiguration(orgSlug, appSlug, envSlug string, re
 		VersionTo:   newVersion.Version,
 		Action:      "update",
 		CreatedBy:   req.CreatedBy,
+		Message:     req.Message,
+	}
+	if currentVersion == nil {
+		// The environment's first configuration
+		change.Action = "create"
+	}
+	if source != nil {
+		change.Action = "promote"
+		change.SourceEnv = &source.envSlug
+		change.SourceVersion = &source.version
 	}
 
 	if err := s.repos.ConfigChanges.Create(change); err != nil {
 		log.Printf("Failed to log configuration change: %v", err)
 	}
 
+	return s.publishUpdate(env, newVersion, req.Config, change), nil
+}
+
+// unchangedUpdate returns the active version, flagged as unchanged, when an update has
+// the same configuration. Configurations are compared as decoded JSON, so formatting and
+// key order do not matter. It returns nil when the configuration differs, or while a
+// rollout is in progress, as the update then ends the rollout.
+func (s *ConfigService) unchangedUpdate(env *models.Environment, active *models.ConfigVersion, config json.RawMessage) (*models.ConfigResponse, error) {
+	if _, err := s.repos.ConfigRollouts.GetByEnvironment(env.ID); err == nil {
+		return nil, nil
+	}
+
+	activeConfig, err := s.encryptor.DecryptSecrets(active.ConfigJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt active configuration: %w", err)
+	}
+	diff, err := DiffConfigs(activeConfig, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute configuration diff: %w", err)
+	}
+	if diff.HasChanges {
+		return nil, nil
+	}
+
+	log.Printf("Skipped unchanged update of %s/%s/%s (version %d)", env.Application.Organization.Slug, env.Application.Slug, env.Slug, active.Version)
+	return &models.ConfigResponse{
+		Organization: env.Application.Organization.Slug,
+		Application:  env.Application.Slug,
+		Environment:  env.Slug,
+		Version:      active.Version,
+		VersionID:    &active.ID,
+		Config:       config,
+		UpdatedAt:    active.CreatedAt,
+		Unchanged:    true,
+	}, nil
+}
+
+// prepareConfig checks an update of an environment's configuration and returns the
+// configuration as it is stored, with the values of secret keys encrypted
+func (s *ConfigService) prepareConfig(env *models.Environment, config json.RawMessage) (json.RawMessage, error) {
+	// Validate JSON
+	var configData interface{}
+	if err := json.Unmarshal(config, &configData); err != nil {
+		return nil, invalidError("invalid JSON configuration: %w", err)
+	}
+
+	// Reject pathologically nested or wide documents
+	if err := s.checkConfigDepth(config); err != nil {
+		return nil, err
+	}
+	if err := s.checkConfigKeys(config); err != nil {
+		return nil, err
+	}
+
+	// Values of blob keys must be base64 and within the blob size limit
+	if err := s.checkConfigBlobs(env, config); err != nil {
+		return nil, err
+	}
+
+	// Enforce the environment's validation rules, reporting every violation
+	if err := s.checkConfigRules(env, config); err != nil {
+		return nil, err
+	}
+
+	// The keys must match those of a strict golden environment
+	if err := s.checkConfigGolden(env, config); err != nil {
+		return nil, err
+	}
+
+	// Every reference to another environment must resolve
+	if err := s.checkConfigReferences(env, config); err != nil {
+		return nil, err
+	}
+
+	// Encrypt values of secret keys before they are stored
+	storedConfig, _, err := s.encryptor.EncryptSecrets(config, env.SecretKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret values: %w", err)
+	}
+
+	return storedConfig, nil
+}
+
+// publishUpdate invalidates the cache of an environment whose configuration was just
+// updated, tells its subscribers about the new version and returns the response
+func (s *ConfigService) publishUpdate(env *models.Environment, newVersion *models.ConfigVersion, config json.RawMessage, change *models.ConfigChange) *models.ConfigResponse {
 	// Invalidate cache for this configuration
 	if err := s.InvalidateEnvironmentCache(env.Application.Organization.Slug, env.Application.Slug, env.Slug); err != nil {
 		log.Printf("Failed to invalidate environment cache: %v", err)
@@ -211,25 +631,63 @@ func (s *ConfigService) UpdateConfiguration(orgSlug, appSlug, envSlug string, re
 		Application:  env.Application.Slug,
 		Environment:  env.Slug,
 		Version:      newVersion.Version,
-		Config:       newVersion.ConfigJSON,
+		VersionID:    &newVersion.ID,
+		Config:       config,
 		UpdatedAt:    newVersion.CreatedAt,
 	}
 
 	// Broadcast SSE event for configuration update
 	if s.sseService != nil {
+		config, defaultsVersion := s.broadcastConfig(env, newVersion.ConfigJSON)
 		updateEvent := models.ConfigUpdateEvent{
-			Organization: response.Organization,
-			Application:  response.Application,
-			Environment:  response.Environment,
-			Version:      response.Version,
-			Config:       response.Config,
-			Action:       "update",
-			UpdatedAt:    response.UpdatedAt,
+			Organization:    response.Organization,
+			Application:     response.Application,
+			Environment:     response.Environment,
+			Version:         response.Version,
+			DefaultsVersion: defaultsVersion,
+			Config:          config,
+			Stored:          unmaskSecrets(config, newVersion.ConfigJSON),
+			Action:          change.Action,
+			UpdatedAt:       response.UpdatedAt,
 		}
 		s.sseService.BroadcastConfigUpdate(updateEvent)
 	}
+	s.notifyChange(env, change)
 
-	return response, nil
+	return response
+}
+
+// previewUpdate builds the response for a dry-run update without persisting anything
+func (s *ConfigService) previewUpdate(env *models.Environment, currentConfig, newConfig json.RawMessage) (*models.ConfigResponse, error) {
+	secretKeys := append(EncryptedKeys(currentConfig), env.SecretKeys...)
+
+	currentConfig, err := s.encryptor.DecryptSecrets(currentConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt active configuration: %w", err)
+	}
+
+	diff, err := DiffConfigs(currentConfig, newConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute configuration diff: %w", err)
+	}
+	SummarizeBlobDiff(diff, env.BlobKeys)
+	MaskSecretDiff(diff, secretKeys)
+
+	nextVersion, err := s.repos.ConfigVersions.GetNextVersion(env.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine next version: %w", err)
+	}
+
+	return &models.ConfigResponse{
+		Organization: env.Application.Organization.Slug,
+		Application:  env.Application.Slug,
+		Environment:  env.Slug,
+		Version:      nextVersion,
+		Config:       newConfig,
+		UpdatedAt:    time.Now(),
+		DryRun:       true,
+		Diff:         diff,
+	}, nil
 }
 
 // RollbackConfiguration rolls back to a previous configuration version
@@ -237,67 +695,103 @@ func (s *ConfigService) RollbackConfiguration(orgSlug, appSlug, envSlug string,
 	// Get the environment
 	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
 	if err != nil {
-		return nil, fmt.Errorf("environment not found: %w", err)
+		return nil, lookupError("environment not found", err)
+	}
+
+	if err := checkUnlocked(env); err != nil {
+		return nil, err
+	}
+
+	// A tag names the version it points at
+	toVersion := req.ToVersion
+	if req.ToTag != "" {
+		tag, err := s.repos.ConfigTags.GetByName(env.ID, req.ToTag)
+		if err != nil {
+			return nil, recordError(err)
+		}
+		toVersion = tag.Version
 	}
 
 	// Get the current active version
 	currentConfig, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID)
 	if err != nil {
-		return nil, fmt.Errorf("no active configuration found: %w", err)
+		return nil, lookupError("no active configuration found", err)
+	}
+
+	// Rolling back to the active version would change nothing
+	if currentConfig.Version == toVersion {
+		return nil, invalidError("invalid rollback: version %d is already active", toVersion)
 	}
 
 	// Check if the target version exists
-	targetConfig, err := s.repos.ConfigVersions.GetByVersion(env.ID, req.ToVersion)
+	targetConfig, err := s.repos.ConfigVersions.GetByVersion(env.ID, toVersion)
 	if err != nil {
-		return nil, fmt.Errorf("target version not found: %w", err)
+		return nil, lookupError("target version not found", err)
 	}
 
-	// Set the target version as active
-	if err := s.repos.ConfigVersions.SetActive(env.ID, req.ToVersion); err != nil {
-		return nil, fmt.Errorf("failed to rollback configuration: %w", err)
+	// Proposed versions can only become active through approval
+	if pending, err := s.repos.PendingChanges.GetByVersion(env.ID, toVersion); err == nil && pending.Status != models.PendingStatusApproved {
+		return nil, conflictError("target version not approved: version %d is %s", toVersion, pending.Status)
 	}
 
-	// Log the rollback
+	// Set the target version as active and log the rollback
 	change := &models.ConfigChange{
 		EnvID:       env.ID,
 		VersionFrom: &currentConfig.Version,
-		VersionTo:   req.ToVersion,
+		VersionTo:   toVersion,
 		Action:      "rollback",
 		CreatedBy:   req.CreatedBy,
+		Message:     req.Message,
 	}
 
-	if err := s.repos.ConfigChanges.Create(change); err != nil {
-		log.Printf("Failed to log configuration rollback: %v", err)
+	if err := s.repos.ConfigVersions.Rollback(change); err != nil {
+		// Another rollback may have activated the version in the meantime
+		if errors.Is(err, db.ErrAlreadyActive) {
+			return nil, invalidError("invalid rollback: version %d is already active", toVersion)
+		}
+		return nil, fmt.Errorf("failed to rollback configuration: %w", recordError(err))
 	}
+	s.endRollout(env)
 
 	// Invalidate cache for this configuration
 	if err := s.InvalidateEnvironmentCache(env.Application.Organization.Slug, env.Application.Slug, env.Slug); err != nil {
 		log.Printf("Failed to invalidate environment cache: %v", err)
 	}
 
+	targetJSON, err := s.encryptor.DecryptSecrets(targetConfig.ConfigJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt configuration: %w", err)
+	}
+
 	// Build the response
 	response := &models.ConfigResponse{
 		Organization: env.Application.Organization.Slug,
 		Application:  env.Application.Slug,
 		Environment:  env.Slug,
 		Version:      targetConfig.Version,
-		Config:       targetConfig.ConfigJSON,
-		UpdatedAt:    time.Now(), // Use current time for rollback
+		VersionID:    &targetConfig.ID,
+		Config:       targetJSON,
+		UpdatedAt:    change.CreatedAt, // When the version was activated
+		Tag:          req.ToTag,
 	}
 
 	// Broadcast SSE event for configuration rollback
 	if s.sseService != nil {
+		config, defaultsVersion := s.broadcastConfig(env, targetConfig.ConfigJSON)
 		rollbackEvent := models.ConfigUpdateEvent{
-			Organization: response.Organization,
-			Application:  response.Application,
-			Environment:  response.Environment,
-			Version:      response.Version,
-			Config:       response.Config,
-			Action:       "rollback",
-			UpdatedAt:    response.UpdatedAt,
+			Organization:    response.Organization,
+			Application:     response.Application,
+			Environment:     response.Environment,
+			Version:         response.Version,
+			DefaultsVersion: defaultsVersion,
+			Config:          config,
+			Stored:          unmaskSecrets(config, targetConfig.ConfigJSON),
+			Action:          "rollback",
+			UpdatedAt:       response.UpdatedAt,
 		}
 		s.sseService.BroadcastConfigUpdate(rollbackEvent)
 	}
+	s.notifyChange(env, change)
 
 	return response, nil
 }
@@ -307,7 +801,7 @@ func (s *ConfigService) GetConfigurationHistory(orgSlug, appSlug, envSlug string
 	// Get the environment
 	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
 	if err != nil {
-		return nil, fmt.Errorf("environment not found: %w", err)
+		return nil, lookupError("environment not found", err)
 	}
 
 	// Get configuration versions
@@ -336,13 +830,13 @@ func (s *ConfigService) GetConfigurationVersion(orgSlug, appSlug, envSlug string
 	// Get the environment
 	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
 	if err != nil {
-		return nil, fmt.Errorf("environment not found: %w", err)
+		return nil, lookupError("environment not found", err)
 	}
 
 	// Get the specific configuration version
 	configVersion, err := s.repos.ConfigVersions.GetByVersion(env.ID, version)
 	if err != nil {
-		return nil, fmt.Errorf("configuration version not found: %w", err)
+		return nil, lookupError("configuration version not found", err)
 	}
 
 	// Build the response
@@ -351,10 +845,98 @@ func (s *ConfigService) GetConfigurationVersion(orgSlug, appSlug, envSlug string
 		Application:  env.Application.Slug,
 		Environment:  env.Slug,
 		Version:      configVersion.Version,
+		VersionID:    &configVersion.ID,
+		Config:       configVersion.ConfigJSON,
+		UpdatedAt:    configVersion.CreatedAt,
+		BlobKeys:     env.BlobKeys,
+	}
+
+	return s.decryptResponse(response)
+}
+
+// GetConfigurationVersionByAPIKey retrieves a specific version of configuration for an
+// application authenticated by its API key or a token. Only versions that have been
+// active are served, so proposals awaiting approval and rollout versions that were never
+// promoted are not found. Like the active configuration, the version is merged with the
+// application defaults and its references are resolved, both as they are now.
+func (s *ConfigService) GetConfigurationVersionByAPIKey(app *models.Application, envSlug string, version int) (*models.ConfigResponse, error) {
+	env, err := s.repos.Environments.GetBySlug(app.Organization.Slug, app.Slug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	activated, err := s.repos.ConfigChanges.WasActivated(env.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	if !activated {
+		return nil, notFoundError("configuration version not found: version %d of %s has never been active", version, env.Slug)
+	}
+
+	configVersion, err := s.repos.ConfigVersions.GetByVersion(env.ID, version)
+	if err != nil {
+		return nil, lookupError("configuration version not found", err)
+	}
+
+	response := &models.ConfigResponse{
+		Organization: app.Organization.Slug,
+		Application:  app.Slug,
+		Environment:  env.Slug,
+		Version:      configVersion.Version,
+		VersionID:    &configVersion.ID,
 		Config:       configVersion.ConfigJSON,
 		UpdatedAt:    configVersion.CreatedAt,
+		BlobKeys:     env.BlobKeys,
+	}
+
+	defaults, err := s.loadDefaults(env.AppID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyDefaults(response, defaults); err != nil {
+		return nil, err
+	}
+	if err := s.resolveReferences(response, s.referenceLoader(app)); err != nil {
+		return nil, err
+	}
+
+	return s.decryptResponse(response)
+}
+
+// GetConfigurationAtTime retrieves the configuration version that was active in an
+// environment at a point in time, found in the change log. Without activations in the
+// change log it falls back to the active version, if it was created by then; other
+// versions may be proposals or rollouts that never went live. The version is returned as
+// stored, like a version of the history: the application defaults and the referenced
+// environments are not versioned with it, so they are neither merged nor resolved.
+func (s *ConfigService) GetConfigurationAtTime(orgSlug, appSlug, envSlug string, at time.Time) (*models.ConfigResponse, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
 	}
 
+	version, found, err := s.repos.ConfigChanges.GetActiveVersionAt(env.ID, at)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		active, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID)
+		if err != nil {
+			return nil, lookupError("no configuration was active at "+at.Format(time.RFC3339), err)
+		}
+		if !active.CreatedAt.After(at) {
+			version = &active.Version
+		}
+	}
+	if version == nil {
+		return nil, notFoundError("no configuration was active at %s", at.Format(time.RFC3339))
+	}
+
+	response, err := s.GetConfigurationVersion(orgSlug, appSlug, envSlug, *version)
+	if err != nil {
+		return nil, err
+	}
+	response.At = &at
 	return response, nil
 }
 
@@ -363,7 +945,7 @@ func (s *ConfigService) GetConfigurationChanges(orgSlug, appSlug, envSlug string
 	// Get the environment
 	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
 	if err != nil {
-		return nil, fmt.Errorf("environment not found: %w", err)
+		return nil, lookupError("environment not found", err)
 	}
 
 	// Get configuration changes
@@ -382,6 +964,10 @@ func (s *ConfigService) GetConfigurationChanges(orgSlug, appSlug, envSlug string
 			"action":       change.Action,
 			"created_at":   change.CreatedAt,
 			"created_by":   change.CreatedBy,
+			"approved_by":  change.ApprovedBy,
+			"message":      change.Message,
+			"tag":          change.Tag,
+			"scope":        change.Scope,
 		})
 	}
 
@@ -389,6 +975,193 @@ func (s *ConfigService) GetConfigurationChanges(orgSlug, appSlug, envSlug string
 	return &response, nil
 }
 
+// ExportConfigurationChanges calls fn with each change in the change log of an environment,
+// or with orgScope of every environment of its organization, oldest first. The changes are
+// read from the database while fn consumes them, so long histories are not held in memory.
+// Nothing is passed to fn when the environment does not exist.
+func (s *ConfigService) ExportConfigurationChanges(orgSlug, appSlug, envSlug string, orgScope bool, fn func(*models.ConfigChange) error) error {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return lookupError("environment not found", err)
+	}
+
+	if orgScope {
+		err = s.repos.ConfigChanges.EachByOrganization(env.Application.OrgID, fn)
+	} else {
+		err = s.repos.ConfigChanges.EachByEnvironment(env.ID, fn)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to export configuration changes: %w", err)
+	}
+	return nil
+}
+
+// DiffDraftConfiguration compares a draft configuration against the active version without persisting it
+func (s *ConfigService) DiffDraftConfiguration(orgSlug, appSlug, envSlug string, draft json.RawMessage) (*models.ConfigDiffResponse, error) {
+	// Validate JSON before touching the database
+	var draftData interface{}
+	if err := json.Unmarshal(draft, &draftData); err != nil {
+		return nil, invalidError("invalid JSON configuration: %w", err)
+	}
+
+	// Get the environment
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	// Diff against the active version, or an empty document if none exists yet
+	fromVersion := 0
+	activeJSON := json.RawMessage(`{}`)
+	if activeConfig, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID); err == nil {
+		fromVersion = activeConfig.Version
+		activeJSON = activeConfig.ConfigJSON
+	}
+
+	// Cache the diff keyed by the content of both documents
+	cacheKey := cache.GenerateComputeKey("diff-draft", env.ID.String(), cache.HashContent(activeJSON), cache.HashContent(draft))
+	diff, err := cache.GetOrCompute(s.cache, cacheKey, func() (*models.ConfigDiff, error) {
+		secretKeys := append(EncryptedKeys(activeJSON), env.SecretKeys...)
+
+		plainActive, err := s.encryptor.DecryptSecrets(activeJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		diff, err := DiffConfigs(plainActive, draft)
+		if err != nil {
+			return nil, err
+		}
+		SummarizeBlobDiff(diff, env.BlobKeys)
+		MaskSecretDiff(diff, secretKeys)
+		return diff, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute configuration diff: %w", err)
+	}
+
+	return &models.ConfigDiffResponse{
+		Organization: env.Application.Organization.Slug,
+		Application:  env.Application.Slug,
+		Environment:  env.Slug,
+		FromVersion:  fromVersion,
+		Diff:         *diff,
+	}, nil
+}
+
+// EncryptExistingSecrets encrypts plaintext values of the environment's secret keys in
+// every stored configuration version. Already encrypted values are left untouched, so
+// the operation can safely be repeated after adding new secret keys.
+func (s *ConfigService) EncryptExistingSecrets(orgSlug, appSlug, envSlug string) (*models.EncryptSecretsResponse, error) {
+	if s.encryptor == nil {
+		return nil, fmt.Errorf("encryption is not configured: set CONFIG_ENCRYPTION_KEY")
+	}
+
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	response := &models.EncryptSecretsResponse{
+		Organization: env.Application.Organization.Slug,
+		Application:  env.Application.Slug,
+		Environment:  env.Slug,
+		SecretKeys:   env.SecretKeys,
+	}
+
+	params := models.PaginationParams{Page: 1, PageSize: 100}
+	for {
+		versions, totalCount, err := s.repos.ConfigVersions.ListByEnvironment(env.ID, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list configuration versions: %w", err)
+		}
+
+		for _, version := range versions {
+			response.VersionsScanned++
+
+			encrypted, changed, err := s.encryptor.EncryptSecrets(version.ConfigJSON, env.SecretKeys)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt version %d: %w", version.Version, err)
+			}
+			if !changed {
+				continue
+			}
+
+			if err := s.repos.ConfigVersions.UpdateConfigJSON(version.ID, encrypted); err != nil {
+				return nil, fmt.Errorf("failed to store encrypted version %d: %w", version.Version, err)
+			}
+			response.VersionsEncrypted++
+		}
+
+		if params.Page*params.PageSize >= totalCount || len(versions) == 0 {
+			break
+		}
+		params.Page++
+	}
+
+	if response.VersionsEncrypted > 0 {
+		if err := s.InvalidateEnvironmentCache(response.Organization, response.Application, response.Environment); err != nil {
+			log.Printf("Failed to invalidate environment cache: %v", err)
+		}
+	}
+
+	log.Printf("Encrypted secret values in %d of %d versions for %s/%s/%s",
+		response.VersionsEncrypted, response.VersionsScanned, response.Organization, response.Application, response.Environment)
+	return response, nil
+}
+
+// ReencryptSecrets rewrites encrypted values in every stored configuration version
+// that were not encrypted with the primary key. Once it completes, keys other than
+// the primary one are no longer needed and can be removed from the configuration.
+func (s *ConfigService) ReencryptSecrets() (*models.ReencryptSecretsResponse, error) {
+	if s.encryptor == nil {
+		return nil, fmt.Errorf("encryption is not configured: set CONFIG_ENCRYPTION_KEYS")
+	}
+
+	response := &models.ReencryptSecretsResponse{PrimaryKeyID: s.encryptor.PrimaryKeyID()}
+
+	params := models.PaginationParams{Page: 1, PageSize: 100}
+	for {
+		versions, totalCount, err := s.repos.ConfigVersions.ListEncrypted(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list configuration versions: %w", err)
+		}
+
+		for _, version := range versions {
+			response.VersionsScanned++
+
+			reencrypted, changed, err := s.encryptor.ReencryptSecrets(version.ConfigJSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to re-encrypt version %s: %w", version.ID, err)
+			}
+			if !changed {
+				continue
+			}
+
+			if err := s.repos.ConfigVersions.UpdateConfigJSON(version.ID, reencrypted); err != nil {
+				return nil, fmt.Errorf("failed to store re-encrypted version %s: %w", version.ID, err)
+			}
+			response.VersionsReencrypted++
+		}
+
+		if params.Page*params.PageSize >= totalCount || len(versions) == 0 {
+			break
+		}
+		params.Page++
+	}
+
+	// Cached configurations hold the old ciphertexts, so drop them before old keys are retired
+	if response.VersionsReencrypted > 0 && s.cache != nil {
+		if err := s.cache.InvalidatePattern("config:*"); err != nil {
+			log.Printf("Failed to invalidate config cache: %v", err)
+		}
+	}
+
+	log.Printf("Re-encrypted secret values in %d of %d versions with key '%s'",
+		response.VersionsReencrypted, response.VersionsScanned, response.PrimaryKeyID)
+	return response, nil
+}
+
 // ValidateAPIKey validates an API key and returns the associated application
 func (s *ConfigService) ValidateAPIKey(apiKey string) (*models.Application, error) {
 	if apiKey == "" {
@@ -410,9 +1183,9 @@ func (s *ConfigService) HealthCheck() map[string]string {
 	// Always assume database is connected since we got this far
 	services["database"] = "connected"
 
-	// Check cache health
+	// Check cache health; requests are served from the database while it is down
 	if s.cache != nil {
-		if err := s.cache.Health(); err != nil {
+		if err := s.cache.Health(); err != nil || !s.cache.IsAvailable() {
 			services["cache"] = "disconnected"
 		} else {
 			services["cache"] = "connected"
@@ -421,6 +1194,14 @@ func (s *ConfigService) HealthCheck() map[string]string {
 		services["cache"] = "disabled"
 	}
 
+	// Reads skip the cache while its circuit breaker is open
+	if s.cache != nil {
+		services["cache_breaker"] = s.cache.GetBreaker().State
+	}
+
+	// Readiness probes can wait for the startup cache warming to finish
+	services["cache_warming"] = s.CacheWarmStatus().Status
+
 	return services
 }
 
@@ -441,16 +1222,29 @@ func (s *ConfigService) ListOrganizations(params models.PaginationParams) (*mode
 func (s *ConfigService) GetOrganization(slug string) (*models.Organization, error) {
 	org, err := s.repos.Organizations.GetBySlug(slug)
 	if err != nil {
-		return nil, fmt.Errorf("organization not found: %w", err)
+		return nil, lookupError("organization not found", err)
+	}
+	return org, nil
+}
+
+// GetOrganizationWithApplications retrieves an organization by slug along with its applications
+func (s *ConfigService) GetOrganizationWithApplications(slug string) (*models.OrganizationWithApplications, error) {
+	org, err := s.repos.Organizations.GetWithApplications(slug)
+	if err != nil {
+		return nil, recordError(err)
 	}
 	return org, nil
 }
 
 // CreateOrganization creates a new organization
 func (s *ConfigService) CreateOrganization(req *models.CreateOrganizationRequest) (*models.Organization, error) {
+	if err := models.ValidateSlug(req.Slug); err != nil {
+		return nil, invalidError("invalid slug %q: %w", req.Slug, err)
+	}
+
 	// Check if organization with this slug already exists
 	if _, err := s.repos.Organizations.GetBySlug(req.Slug); err == nil {
-		return nil, fmt.Errorf("organization with slug '%s' already exists", req.Slug)
+		return nil, &AlreadyExistsError{Resource: "organization", Slug: req.Slug}
 	}
 
 	org := &models.Organization{
@@ -469,7 +1263,7 @@ func (s *ConfigService) CreateOrganization(req *models.CreateOrganizationRequest
 func (s *ConfigService) UpdateOrganization(slug string, req *models.UpdateOrganizationRequest) (*models.Organization, error) {
 	org, err := s.repos.Organizations.GetBySlug(slug)
 	if err != nil {
-		return nil, fmt.Errorf("organization not found: %w", err)
+		return nil, lookupError("organization not found", err)
 	}
 
 	org.Name = req.Name
@@ -485,7 +1279,7 @@ func (s *ConfigService) UpdateOrganization(slug string, req *models.UpdateOrgani
 func (s *ConfigService) DeleteOrganization(slug string) error {
 	org, err := s.repos.Organizations.GetBySlug(slug)
 	if err != nil {
-		return fmt.Errorf("organization not found: %w", err)
+		return lookupError("organization not found", err)
 	}
 
 	if err := s.repos.Organizations.Delete(org.ID); err != nil {
@@ -501,7 +1295,7 @@ func (s *ConfigService) DeleteOrganization(slug string) error {
 func (s *ConfigService) ListApplications(orgSlug string, params models.PaginationParams) (*models.PaginatedResponse, error) {
 	org, err := s.repos.Organizations.GetBySlug(orgSlug)
 	if err != nil {
-		return nil, fmt.Errorf("organization not found: %w", err)
+		return nil, lookupError("organization not found", err)
 	}
 
 	apps, totalCount, err := s.repos.Applications.ListByOrganization(org.ID, params)
@@ -517,29 +1311,36 @@ func (s *ConfigService) ListApplications(orgSlug string, params models.Paginatio
 func (s *ConfigService) GetApplication(orgSlug, appSlug string) (*models.Application, error) {
 	app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
 	if err != nil {
-		return nil, fmt.Errorf("application not found: %w", err)
+		return nil, lookupError("application not found", err)
 	}
 	return app, nil
 }
 
 // CreateApplication creates a new application
 func (s *ConfigService) CreateApplication(orgSlug string, req *models.CreateApplicationRequest) (*models.Application, error) {
+	if err := models.ValidateSlug(req.Slug); err != nil {
+		return nil, invalidError("invalid slug %q: %w", req.Slug, err)
+	}
+
 	org, err := s.repos.Organizations.GetBySlug(orgSlug)
 	if err != nil {
-		return nil, fmt.Errorf("organization not found: %w", err)
+		return nil, lookupError("organization not found", err)
 	}
 
 	// Check if application with this slug already exists in the organization
 	if exists, err := s.repos.Applications.Exists(org.ID, req.Slug); err != nil {
 		return nil, fmt.Errorf("failed to check application existence: %w", err)
 	} else if exists {
-		return nil, fmt.Errorf("application with slug '%s' already exists in organization '%s'", req.Slug, orgSlug)
+		return nil, &AlreadyExistsError{Resource: "application", Slug: req.Slug, ParentResource: "organization", ParentSlug: orgSlug}
 	}
 
-	// Generate API key if not provided
+	// Generate API key if not provided. Custom keys must not be in use, since keys are
+	// only stored hashed and cannot be checked for uniqueness by the database.
 	apiKey := req.APIKey
 	if apiKey == "" {
 		apiKey = generateAPIKey()
+	} else if _, err := s.repos.Applications.GetByAPIKey(apiKey); err == nil {
+		return nil, conflictError("API key already in use")
 	}
 
 	app := &models.Application{
@@ -563,11 +1364,21 @@ func (s *ConfigService) CreateApplication(orgSlug string, req *models.CreateAppl
 func (s *ConfigService) UpdateApplication(orgSlug, appSlug string, req *models.UpdateApplicationRequest) (*models.Application, error) {
 	app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
 	if err != nil {
-		return nil, fmt.Errorf("application not found: %w", err)
+		return nil, lookupError("application not found", err)
 	}
 
 	app.Name = req.Name
 
+	if req.DefaultEnv != nil {
+		// The default environment must belong to the application
+		if *req.DefaultEnv != "" {
+			if _, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, *req.DefaultEnv); err != nil {
+				return nil, invalidError("default environment not found: %w", err)
+			}
+		}
+		app.DefaultEnv = *req.DefaultEnv
+	}
+
 	if err := s.repos.Applications.Update(app); err != nil {
 		return nil, fmt.Errorf("failed to update application: %w", err)
 	}
@@ -575,11 +1386,28 @@ func (s *ConfigService) UpdateApplication(orgSlug, appSlug string, req *models.U
 	return app, nil
 }
 
+// RotateAPIKey replaces the API key of an application with a newly generated one, which is
+// only returned here. The previous key stops working at once.
+func (s *ConfigService) RotateAPIKey(orgSlug, appSlug string) (*models.Application, error) {
+	app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
+	if err != nil {
+		return nil, lookupError("application not found", err)
+	}
+
+	app.APIKey = generateAPIKey()
+	if err := s.repos.Applications.UpdateAPIKey(app); err != nil {
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	log.Printf("Rotated API key of application %s/%s", orgSlug, appSlug)
+	return app, nil
+}
+
 // DeleteApplication deletes an application
 func (s *ConfigService) DeleteApplication(orgSlug, appSlug string) error {
 	app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
 	if err != nil {
-		return fmt.Errorf("application not found: %w", err)
+		return lookupError("application not found", err)
 	}
 
 	if err := s.repos.Applications.Delete(app.ID); err != nil {
@@ -595,7 +1423,7 @@ func (s *ConfigService) DeleteApplication(orgSlug, appSlug string) error {
 func (s *ConfigService) ListEnvironments(orgSlug, appSlug string, params models.PaginationParams) (*models.PaginatedResponse, error) {
 	app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
 	if err != nil {
-		return nil, fmt.Errorf("application not found: %w", err)
+		return nil, lookupError("application not found", err)
 	}
 
 	envs, totalCount, err := s.repos.Environments.ListByApplication(app.ID, params)
@@ -611,33 +1439,61 @@ func (s *ConfigService) ListEnvironments(orgSlug, appSlug string, params models.
 func (s *ConfigService) GetEnvironment(orgSlug, appSlug, envSlug string) (*models.Environment, error) {
 	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
 	if err != nil {
-		return nil, fmt.Errorf("environment not found: %w", err)
+		return nil, lookupError("environment not found", err)
 	}
 	return env, nil
 }
 
-// CreateEnvironment creates a new environment
+// GetEnvironmentWithConfigSummary retrieves an environment along with a summary of its
+// configuration versions
+func (s *ConfigService) GetEnvironmentWithConfigSummary(orgSlug, appSlug, envSlug string) (*models.Environment, error) {
+	env, err := s.GetEnvironment(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.repos.ConfigVersions.GetSummary(env.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize configuration versions: %w", err)
+	}
+	env.ConfigSummary = summary
+
+	return env, nil
+}
+
+// CreateEnvironment creates a new environment. An initial configuration in the request is
+// checked like an update and created as version 1 in the same transaction, logged as a
+// "create" change.
 func (s *ConfigService) CreateEnvironment(orgSlug, appSlug string, req *models.CreateEnvironmentRequest) (*models.Environment, error) {
 	app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
 	if err != nil {
-		return nil, fmt.Errorf("application not found: %w", err)
+		return nil, lookupError("application not found", err)
 	}
 
-	// Check if environment with this slug already exists in the application
-	if exists, err := s.repos.Environments.Exists(app.ID, req.Slug); err != nil {
-		return nil, fmt.Errorf("failed to check environment existence: %w", err)
-	} else if exists {
-		return nil, fmt.Errorf("environment with slug '%s' already exists in application '%s'", req.Slug, appSlug)
+	env, err := s.newEnvironment(app, appSlug, req)
+	if err != nil {
+		return nil, err
 	}
 
-	env := &models.Environment{
-		AppID: app.ID,
-		Name:  req.Name,
-		Slug:  req.Slug,
+	config, err := s.initialVersion(env, req.Config, req.CreatedBy)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.repos.Environments.Create(env); err != nil {
-		return nil, fmt.Errorf("failed to create environment: %w", err)
+	if config == nil {
+		if err := s.repos.Environments.Create(env); err != nil {
+			return nil, fmt.Errorf("failed to create environment: %w", err)
+		}
+	} else {
+		// The environment and its first version are created together, or not at all
+		if _, err := s.repos.Environments.CreateMany([]*models.Environment{env}, []*models.ConfigVersion{config}, false); err != nil {
+			return nil, fmt.Errorf("failed to create environment: %w", err)
+		}
+		env.ConfigSummary = &models.EnvironmentConfigSummary{
+			ActiveVersion: &config.Version,
+			VersionCount:  1,
+			LastChangedAt: &config.CreatedAt,
+		}
 	}
 
 	// Load the application relationship
@@ -646,14 +1502,71 @@ func (s *ConfigService) CreateEnvironment(orgSlug, appSlug string, req *models.C
 	return env, nil
 }
 
+// newEnvironment checks that an environment can be created in the application and
+// builds it from the request
+func (s *ConfigService) newEnvironment(app *models.Application, appSlug string, req *models.CreateEnvironmentRequest) (*models.Environment, error) {
+	if err := models.ValidateSlug(req.Slug); err != nil {
+		return nil, invalidError("invalid slug %q: %w", req.Slug, err)
+	}
+
+	// Check if environment with this slug already exists in the application
+	if exists, err := s.repos.Environments.Exists(app.ID, req.Slug); err != nil {
+		return nil, fmt.Errorf("failed to check environment existence: %w", err)
+	} else if exists {
+		return nil, &AlreadyExistsError{Resource: "environment", Slug: req.Slug, ParentResource: "application", ParentSlug: appSlug}
+	}
+
+	if err := s.checkGoldenEnvironment(app.ID, req.Slug, req.GoldenEnvironment); err != nil {
+		return nil, err
+	}
+
+	return &models.Environment{
+		AppID:             app.ID,
+		Name:              req.Name,
+		Slug:              req.Slug,
+		SecretKeys:        req.SecretKeys,
+		BlobKeys:          req.BlobKeys,
+		RequiresApproval:  req.RequiresApproval,
+		CacheTTLSeconds:   req.CacheTTLSeconds,
+		RetentionVersions: req.RetentionVersions,
+		RetentionDays:     req.RetentionDays,
+		GoldenEnvironment: req.GoldenEnvironment,
+		GoldenStrict:      req.GoldenStrict,
+	}, nil
+}
+
 // UpdateEnvironment updates an existing environment
 func (s *ConfigService) UpdateEnvironment(orgSlug, appSlug, envSlug string, req *models.UpdateEnvironmentRequest) (*models.Environment, error) {
 	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
 	if err != nil {
-		return nil, fmt.Errorf("environment not found: %w", err)
+		return nil, lookupError("environment not found", err)
 	}
 
 	env.Name = req.Name
+	if req.SecretKeys != nil {
+		env.SecretKeys = req.SecretKeys
+	}
+	if req.BlobKeys != nil {
+		env.BlobKeys = req.BlobKeys
+	}
+	if req.RequiresApproval != nil {
+		env.RequiresApproval = *req.RequiresApproval
+	}
+	env.CacheTTLSeconds = updateOverride(env.CacheTTLSeconds, req.CacheTTLSeconds)
+	env.RetentionVersions = updateOverride(env.RetentionVersions, req.RetentionVersions)
+	env.RetentionDays = updateOverride(env.RetentionDays, req.RetentionDays)
+	if req.GoldenEnvironment != nil {
+		env.GoldenEnvironment = req.GoldenEnvironment
+		if *req.GoldenEnvironment == "" {
+			env.GoldenEnvironment = nil
+		}
+	}
+	if req.GoldenStrict != nil {
+		env.GoldenStrict = *req.GoldenStrict
+	}
+	if err := s.checkGoldenEnvironment(env.AppID, env.Slug, env.GoldenEnvironment); err != nil {
+		return nil, err
+	}
 
 	if err := s.repos.Environments.Update(env); err != nil {
 		return nil, fmt.Errorf("failed to update environment: %w", err)
@@ -662,17 +1575,41 @@ func (s *ConfigService) UpdateEnvironment(orgSlug, appSlug, envSlug string, req
 	return env, nil
 }
 
+// updateOverride applies an update of a per-environment override of a global setting:
+// nil leaves the current override unchanged and 0 removes it
+func updateOverride(current, requested *int) *int {
+	if requested == nil {
+		return current
+	}
+	if *requested == 0 {
+		return nil
+	}
+	return requested
+}
+
 // DeleteEnvironment deletes an environment
 func (s *ConfigService) DeleteEnvironment(orgSlug, appSlug, envSlug string) error {
 	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
 	if err != nil {
-		return fmt.Errorf("environment not found: %w", err)
+		return lookupError("environment not found", err)
 	}
 
 	if err := s.repos.Environments.Delete(env.ID); err != nil {
 		return fmt.Errorf("failed to delete environment: %w", err)
 	}
 
+	// Unset the application's default environment if it was the deleted one
+	app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
+	if err != nil {
+		return lookupError("application not found", err)
+	}
+	if app.DefaultEnv == env.Slug {
+		app.DefaultEnv = ""
+		if err := s.repos.Applications.Update(app); err != nil {
+			return fmt.Errorf("failed to clear default environment: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -680,83 +1617,117 @@ func (s *ConfigService) DeleteEnvironment(orgSlug, appSlug, envSlug string) erro
 
 // WarmCache preloads frequently accessed configurations into cache
 func (s *ConfigService) WarmCache() error {
+	_, err := s.WarmCacheScope("", "")
+	return err
+}
+
+// WarmCacheScope preloads the active configurations of an organization, or of one of its
+// applications, into cache. Empty slugs widen the scope to everything. It returns the
+// number of configurations warmed.
+func (s *ConfigService) WarmCacheScope(orgSlug, appSlug string) (int, error) {
 	if s.cache == nil {
-		return fmt.Errorf("cache is not enabled")
+		return 0, fmt.Errorf("cache is not enabled")
 	}
 
-	log.Println("Starting cache warming...")
+	// Unknown scopes are reported rather than warming nothing
+	switch {
+	case appSlug != "":
+		if _, err := s.repos.Applications.GetBySlug(orgSlug, appSlug); err != nil {
+			return 0, err
+		}
+	case orgSlug != "":
+		if _, err := s.repos.Organizations.GetBySlug(orgSlug); err != nil {
+			return 0, err
+		}
+	}
 
-	// Get all environments with their active configurations
-	// This is a simplified approach - in production you might want to be more selective
-	params := models.PaginationParams{Page: 1, PageSize: 100}
+	log.Printf("Starting cache warming (scope: %s)...", warmScope(orgSlug, appSlug))
 
-	// Get organizations
-	orgs, _, err := s.repos.Organizations.List(params)
+	// Load every active configuration in scope in one query
+	activeConfigs, err := s.repos.ConfigVersions.ListActive(orgSlug, appSlug)
 	if err != nil {
-		return fmt.Errorf("failed to get organizations for cache warming: %w", err)
+		return 0, fmt.Errorf("failed to get configurations for cache warming: %w", err)
 	}
 
-	configs := make(map[string]interface{})
-
-	for _, org := range orgs {
-		// Get applications for this organization
-		apps, _, err := s.repos.Applications.ListByOrganization(org.ID, params)
-		if err != nil {
-			log.Printf("Failed to get applications for org %s: %v", org.Slug, err)
-			continue
+	// References are resolved among the active configurations of the same application
+	appActives := make(map[string]map[string]*models.ActiveConfig)
+	for i := range activeConfigs {
+		active := &activeConfigs[i]
+		appKey := active.Organization + "/" + active.Application
+		if appActives[appKey] == nil {
+			appActives[appKey] = make(map[string]*models.ActiveConfig)
 		}
+		appActives[appKey][active.Environment] = active
+	}
 
-		for _, app := range apps {
-			// Get environments for this application
-			envs, _, err := s.repos.Environments.ListByApplication(app.ID, params)
-			if err != nil {
-				log.Printf("Failed to get environments for app %s: %v", app.Slug, err)
+	configs := make(map[string]interface{})
+	orgs := make(map[string]bool)
+	apps := make(map[string]bool)
+
+	for _, active := range activeConfigs {
+		orgs[active.Organization] = true
+		apps[active.Organization+"/"+active.Application] = true
+
+		// Build cache entry
+		versionID := active.VersionID
+		response := &models.ConfigResponse{
+			Organization: active.Organization,
+			Application:  active.Application,
+			Environment:  active.Environment,
+			Version:      active.Version,
+			VersionID:    &versionID,
+			Config:       active.ConfigJSON,
+			UpdatedAt:    active.CreatedAt,
+			BlobKeys:     active.BlobKeys,
+		}
+		if active.DefaultsJSON != nil {
+			defaults := &models.ApplicationDefaults{ConfigJSON: active.DefaultsJSON, Version: active.DefaultsVersion}
+			if err := applyDefaults(response, defaults); err != nil {
+				log.Printf("Skipping cache warming for %s/%s/%s: %v", active.Organization, active.Application, active.Environment, err)
 				continue
 			}
+		}
+		if err := s.resolveReferences(response, activeReferenceLoader(appActives[active.Organization+"/"+active.Application])); err != nil {
+			log.Printf("Skipping cache warming for %s/%s/%s: %v", active.Organization, active.Application, active.Environment, err)
+			continue
+		}
 
-			for _, env := range envs {
-				// Get active configuration for this environment
-				configVersion, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID)
-				if err != nil {
-					log.Printf("No active config for env %s/%s/%s: %v", org.Slug, app.Slug, env.Slug, err)
-					continue
-				}
-
-				// Build cache entry
-				response := &models.ConfigResponse{
-					Organization: org.Slug,
-					Application:  app.Slug,
-					Environment:  env.Slug,
-					Version:      configVersion.Version,
-					Config:       configVersion.ConfigJSON,
-					UpdatedAt:    configVersion.CreatedAt,
-				}
-
-				// Add to cache warming batch
-				cacheKey := cache.GenerateConfigKey(org.Slug, app.Slug, env.Slug)
-				configs[cacheKey] = response
-
-				// Also add API key cache entry if available
-				if app.APIKey != "" {
-					apiCacheKey := cache.GenerateAPIKeyConfigKey(app.APIKey, env.Slug)
-					configs[apiCacheKey] = response
-				}
-			}
+		// Add to cache warming batch
+		cacheKey := cache.GenerateConfigKey(active.Organization, active.Application, active.Environment)
+		configs[cacheKey] = response
+
+		// Also add the API key cache entry. Environments with a rollout in progress are
+		// left to be loaded on first request, along with the rollout.
+		if !active.HasRollout {
+			apiCacheKey := cache.GenerateAPIKeyConfigKey(active.AppID.String(), active.Environment)
+			configs[apiCacheKey] = &cachedAPIKeyConfig{Stable: response}
 		}
 	}
 
 	if len(configs) == 0 {
 		log.Println("No configurations found for cache warming")
-		return nil
+		return 0, nil
 	}
 
 	// Warm the cache
 	if err := s.cache.WarmCache(configs); err != nil {
-		return fmt.Errorf("failed to warm cache: %w", err)
+		return 0, fmt.Errorf("failed to warm cache: %w", err)
 	}
 
-	log.Printf("Cache warming completed: %d configurations loaded", len(configs))
-	return nil
+	log.Printf("Cache warming completed: %d configurations loaded from %d applications in %d organizations", len(activeConfigs), len(apps), len(orgs))
+	return len(activeConfigs), nil
+}
+
+// warmScope describes the scope of a cache warming run for logging
+func warmScope(orgSlug, appSlug string) string {
+	switch {
+	case appSlug != "":
+		return orgSlug + "/" + appSlug
+	case orgSlug != "":
+		return orgSlug
+	default:
+		return "all"
+	}
 }
 
 // GetCacheStats returns cache statistics
@@ -768,6 +1739,18 @@ func (s *ConfigService) GetCacheStats() (map[string]interface{}, error) {
 		}, nil
 	}
 
+	// Counters are still available while Redis is down
+	if !s.cache.IsAvailable() {
+		stats := s.cache.GetStats()
+		return map[string]interface{}{
+			"enabled":      true,
+			"stats":        stats,
+			"hit_ratio":    stats.GetHitRatio(),
+			"availability": s.cache.GetAvailability(),
+			"breaker":      s.cache.GetBreaker(),
+		}, nil
+	}
+
 	info, err := s.cache.GetCacheInfo()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cache stats: %w", err)
@@ -777,6 +1760,30 @@ func (s *ConfigService) GetCacheStats() (map[string]interface{}, error) {
 	return info, nil
 }
 
+// cacheAvailable reports whether the cache is enabled and Redis can currently be reached
+func (s *ConfigService) cacheAvailable() bool {
+	return s.cache != nil && s.cache.IsAvailable()
+}
+
+// readCache returns a cached value, or nil if it has to be read from the database. A miss
+// is not logged, a failed read is; reads skipped by the open circuit breaker are counted
+// in the cache stats only.
+func (s *ConfigService) readCache(key string) []byte {
+	data, err := s.cache.GetConfig(key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrBreakerOpen) {
+			log.Printf("Cache read of %s failed, reading from the database: %v", key, err)
+		}
+		return nil
+	}
+	return data
+}
+
+// GetDatabaseStats returns the settings and usage of the database connection pool
+func (s *ConfigService) GetDatabaseStats() *db.PoolStats {
+	return s.repos.PoolStats()
+}
+
 // ClearCache clears all cached configurations
 func (s *ConfigService) ClearCache() error {
 	if s.cache == nil {
@@ -787,15 +1794,36 @@ func (s *ConfigService) ClearCache() error {
 		return fmt.Errorf("failed to clear cache: %w", err)
 	}
 
+	if err := s.cache.InvalidatePattern("flags:*"); err != nil {
+		return fmt.Errorf("failed to clear flags cache: %w", err)
+	}
+
+	if err := s.cache.InvalidateComputed(); err != nil {
+		return fmt.Errorf("failed to clear computed results: %w", err)
+	}
+
 	// Reset statistics
 	s.cache.ResetStats()
 	log.Println("Cache cleared successfully")
 	return nil
 }
 
+// ResetCacheStats resets the cache counters, e.g. before measuring cache effectiveness,
+// without clearing cached configurations
+func (s *ConfigService) ResetCacheStats() error {
+	if s.cache == nil {
+		return fmt.Errorf("cache is not enabled")
+	}
+
+	s.cache.ResetCounters()
+	log.Println("Cache statistics reset")
+	return nil
+}
+
 // InvalidateEnvironmentCache invalidates cache for a specific environment
 func (s *ConfigService) InvalidateEnvironmentCache(orgSlug, appSlug, envSlug string) error {
-	if s.cache == nil {
+	// Entries are dropped when an unavailable cache recovers
+	if !s.cacheAvailable() {
 		return nil // No cache to invalidate
 	}
 
@@ -805,16 +1833,32 @@ func (s *ConfigService) InvalidateEnvironmentCache(orgSlug, appSlug, envSlug str
 		log.Printf("Failed to invalidate config cache: %v", err)
 	}
 
-	// Invalidate API key cache pattern for this environment
-	pattern := fmt.Sprintf("config:api:*:%s", envSlug)
-	if err := s.cache.InvalidatePattern(pattern); err != nil {
-		log.Printf("Failed to invalidate API key cache pattern: %v", err)
+	// Invalidate the API key caches of this environment. Environment slugs are only unique
+	// within an application, so only the entries cached for its application's key go.
+	if app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug); err != nil {
+		log.Printf("Failed to invalidate API key cache: %v", err)
+	} else {
+		s.invalidateAPIKeyCache(app.ID, envSlug)
+
+		// Environments that reference this one serve its values
+		s.invalidateReferencingEnvironments(app, envSlug)
 	}
 
 	log.Printf("Invalidated cache for environment: %s/%s/%s", orgSlug, appSlug, envSlug)
 	return nil
 }
 
+// invalidateAPIKeyCache deletes the configuration and flags cached for an application's API
+// key and environment
+func (s *ConfigService) invalidateAPIKeyCache(appID uuid.UUID, envSlug string) {
+	if err := s.cache.DeleteConfig(cache.GenerateAPIKeyConfigKey(appID.String(), envSlug)); err != nil {
+		log.Printf("Failed to invalidate API key config cache: %v", err)
+	}
+	if err := s.cache.DeleteConfig(cache.GenerateAPIKeyFlagsKey(appID.String(), envSlug)); err != nil {
+		log.Printf("Failed to invalidate API key flags cache: %v", err)
+	}
+}
+
 // generateAPIKey generates a random API key
 func generateAPIKey() string {
 	bytes := make([]byte, 32)
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"remote-config-system/internal/db"
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// GetApplicationDefaults retrieves the default configuration of an application
+func (s *ConfigService) GetApplicationDefaults(orgSlug, appSlug string) (*models.ApplicationDefaults, error) {
+	app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
+	if err != nil {
+		return nil, lookupError("application not found", err)
+	}
+
+	defaults, err := s.repos.AppDefaults.GetByApplication(app.ID)
+	if err != nil {
+		return nil, lookupError("application defaults not found", err)
+	}
+
+	return defaults, nil
+}
+
+// SetApplicationDefaults stores the default configuration of an application, replacing
+// any existing one. Every environment of the application inherits the change, so it is
+// rejected while an environment is locked or requires approval.
+func (s *ConfigService) SetApplicationDefaults(orgSlug, appSlug string, req *models.UpdateApplicationDefaultsRequest) (*models.ApplicationDefaults, error) {
+	if err := s.checkConfigSize(req.Config); err != nil {
+		return nil, err
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(req.Config, &object); err != nil {
+		return nil, invalidError("invalid defaults: must be a JSON object: %w", err)
+	}
+
+	if err := s.checkConfigDepth(req.Config); err != nil {
+		return nil, err
+	}
+	if err := s.checkConfigKeys(req.Config); err != nil {
+		return nil, err
+	}
+
+	app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
+	if err != nil {
+		return nil, lookupError("application not found", err)
+	}
+
+	if err := s.checkApplicationUnlocked(app); err != nil {
+		return nil, err
+	}
+	if err := s.checkApplicationUngated(app); err != nil {
+		return nil, err
+	}
+
+	action := "create"
+	var previousVersion *int
+	if previous, err := s.repos.AppDefaults.GetByApplication(app.ID); err == nil {
+		action = "update"
+		previousVersion = &previous.Version
+	}
+
+	defaults := &models.ApplicationDefaults{
+		AppID:      app.ID,
+		ConfigJSON: req.Config,
+		UpdatedBy:  req.UpdatedBy,
+	}
+
+	if err := s.repos.AppDefaults.Upsert(defaults); err != nil {
+		return nil, fmt.Errorf("failed to save application defaults: %w", err)
+	}
+
+	s.propagateDefaults(app, action, previousVersion, defaults.Version, req.UpdatedBy)
+	return defaults, nil
+}
+
+// DeleteApplicationDefaults removes the default configuration of an application, leaving
+// every environment with only its own configuration. Like edits, it is rejected while an
+// environment is locked or requires approval.
+func (s *ConfigService) DeleteApplicationDefaults(orgSlug, appSlug string, deletedBy *string) error {
+	app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
+	if err != nil {
+		return lookupError("application not found", err)
+	}
+
+	if err := s.checkApplicationUnlocked(app); err != nil {
+		return err
+	}
+	if err := s.checkApplicationUngated(app); err != nil {
+		return err
+	}
+
+	previous, err := s.repos.AppDefaults.GetByApplication(app.ID)
+	if err != nil {
+		return lookupError("application defaults not found", err)
+	}
+
+	deleted, err := s.repos.AppDefaults.Delete(app.ID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return notFoundError("application defaults not found for application: %s", appSlug)
+	}
+
+	// Version 0 stands for no defaults
+	s.propagateDefaults(app, "delete", &previous.Version, 0, deletedBy)
+	return nil
+}
+
+// checkApplicationUngated rejects a change of the application defaults while any
+// environment of the application requires approval. The defaults are merged in when
+// configurations are read, so the change would reach that environment unreviewed.
+func (s *ConfigService) checkApplicationUngated(app *models.Application) error {
+	envs, err := s.repos.Environments.ListAllByApplication(app.ID)
+	if err != nil {
+		return err
+	}
+	for _, env := range envs {
+		if env.RequiresApproval {
+			return conflictError("application defaults cannot be changed while environment %s requires approval, as it would inherit them without review", env.Slug)
+		}
+	}
+	return nil
+}
+
+// propagateDefaults logs an edit of the application defaults for every environment of
+// the application, invalidates their cache and notifies their subscribers
+func (s *ConfigService) propagateDefaults(app *models.Application, action string, versionFrom *int, versionTo int, createdBy *string) {
+	envs, err := s.repos.Environments.ListAllByApplication(app.ID)
+	if err != nil {
+		log.Printf("Failed to list environments of application %s/%s: %v", app.Organization.Slug, app.Slug, err)
+		return
+	}
+
+	defaults, err := s.loadDefaults(app.ID)
+	if err != nil {
+		log.Printf("Failed to load application defaults for broadcast: %v", err)
+	}
+
+	for _, env := range envs {
+		change := &models.ConfigChange{
+			EnvID:       env.ID,
+			VersionFrom: versionFrom,
+			VersionTo:   versionTo,
+			Action:      action,
+			CreatedBy:   createdBy,
+			Scope:       models.ChangeScopeDefaults,
+		}
+		if err := s.repos.ConfigChanges.Create(change); err != nil {
+			log.Printf("Failed to log application defaults change: %v", err)
+		}
+
+		if err := s.InvalidateEnvironmentCache(app.Organization.Slug, app.Slug, env.Slug); err != nil {
+			log.Printf("Failed to invalidate environment cache: %v", err)
+		}
+
+		if s.sseService != nil {
+			s.broadcastDefaultsChange(app, &env, action, defaults)
+		}
+	}
+}
+
+// broadcastDefaultsChange sends subscribers of an environment its configuration merged
+// with the new application defaults. Environments without an active configuration have
+// nothing to send.
+func (s *ConfigService) broadcastDefaultsChange(app *models.Application, env *models.Environment, action string, defaults *models.ApplicationDefaults) {
+	activeConfig, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID)
+	if err != nil {
+		return
+	}
+
+	config, err := MaskSecrets(activeConfig.ConfigJSON)
+	if err != nil {
+		log.Printf("Failed to mask secret values for broadcast: %v", err)
+		return
+	}
+
+	response := &models.ConfigResponse{Environment: env.Slug, Config: config}
+	if err := applyDefaults(response, defaults); err != nil {
+		log.Printf("Failed to apply application defaults for broadcast: %v", err)
+		return
+	}
+	if err := s.resolveBroadcastReferences(app, response); err != nil {
+		log.Printf("Failed to resolve references for broadcast: %v", err)
+	}
+
+	s.sseService.BroadcastConfigUpdate(models.ConfigUpdateEvent{
+		Organization:    app.Organization.Slug,
+		Application:     app.Slug,
+		Environment:     env.Slug,
+		Version:         activeConfig.Version,
+		DefaultsVersion: response.DefaultsVersion,
+		Config:          response.Config,
+		Stored:          unmaskSecrets(response.Config, activeConfig.ConfigJSON),
+		Action:          "defaults_" + action,
+		UpdatedAt:       time.Now(),
+	})
+}
+
+// GetRawConfiguration retrieves the active configuration of an environment as stored,
+// without the application defaults merged in
+func (s *ConfigService) GetRawConfiguration(orgSlug, appSlug, envSlug string) (*models.ConfigResponse, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	configVersion, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID)
+	if err != nil {
+		return nil, lookupError("no active configuration found", err)
+	}
+
+	response := &models.ConfigResponse{
+		Organization: env.Application.Organization.Slug,
+		Application:  env.Application.Slug,
+		Environment:  env.Slug,
+		Version:      configVersion.Version,
+		VersionID:    &configVersion.ID,
+		Config:       configVersion.ConfigJSON,
+		UpdatedAt:    configVersion.CreatedAt,
+	}
+
+	return s.decryptResponse(response)
+}
+
+// loadDefaults retrieves the default configuration of an application, or nil if it has none
+func (s *ConfigService) loadDefaults(appID uuid.UUID) (*models.ApplicationDefaults, error) {
+	defaults, err := s.repos.AppDefaults.GetByApplication(appID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load application defaults: %w", err)
+	}
+	return defaults, nil
+}
+
+// applyDefaults merges application defaults under the configuration of a response.
+// Nil defaults leave the response unchanged.
+func applyDefaults(response *models.ConfigResponse, defaults *models.ApplicationDefaults) error {
+	if response == nil || defaults == nil {
+		return nil
+	}
+
+	merged, err := MergeConfig(defaults.ConfigJSON, response.Config)
+	if err != nil {
+		return fmt.Errorf("failed to apply application defaults: %w", err)
+	}
+
+	response.Config = merged
+	response.DefaultsVersion = defaults.Version
+	return nil
+}
+
+// effectiveConfig returns an environment configuration with its application defaults merged in
+func (s *ConfigService) effectiveConfig(appID uuid.UUID, config json.RawMessage) (json.RawMessage, int, error) {
+	defaults, err := s.loadDefaults(appID)
+	if err != nil || defaults == nil {
+		return config, 0, err
+	}
+
+	merged, err := MergeConfig(defaults.ConfigJSON, config)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to apply application defaults: %w", err)
+	}
+	return merged, defaults.Version, nil
+}
+
+// broadcastConfig returns the configuration to send to subscribers of an environment,
+// which is the one clients fetch: merged with the application defaults and with its
+// references resolved. It takes the configuration as stored. Streams and their replay
+// buffer are public, so secret values are masked rather than decrypted. Authenticated
+// gRPC watchers must not apply it: they read the decrypted configuration again instead.
+// On failure the masked environment configuration is sent as is. Callers set the event's
+// Stored configuration from it with unmaskSecrets, so that changed secrets are tracked.
+func (s *ConfigService) broadcastConfig(env *models.Environment, stored json.RawMessage) (json.RawMessage, int) {
+	config, err := MaskSecrets(stored)
+	if err != nil {
+		log.Printf("Failed to mask secret values for broadcast: %v", err)
+		return json.RawMessage(`{}`), 0
+	}
+
+	merged, defaultsVersion, err := s.effectiveConfig(env.AppID, config)
+	if err != nil {
+		log.Printf("Failed to apply application defaults for broadcast: %v", err)
+		return config, 0
+	}
+
+	response := &models.ConfigResponse{Environment: env.Slug, Config: merged}
+	if err := s.resolveBroadcastReferences(env.Application, response); err != nil {
+		log.Printf("Failed to resolve references for broadcast: %v", err)
+		return merged, defaultsVersion
+	}
+	return response.Config, defaultsVersion
+}
+
+// resolveBroadcastReferences resolves the references of a masked configuration about
+// to be broadcast. Referenced secret values are masked too.
+func (s *ConfigService) resolveBroadcastReferences(app *models.Application, response *models.ConfigResponse) error {
+	if app == nil || app.Organization == nil {
+		return nil
+	}
+	if err := s.resolveReferences(response, s.referenceLoader(app)); err != nil {
+		return err
+	}
+	if response.References == nil {
+		return nil
+	}
+
+	config, err := MaskSecrets(response.Config)
+	if err != nil {
+		return fmt.Errorf("failed to mask referenced values: %w", err)
+	}
+	response.Config = config
+	return nil
+}
+
+// MergeConfig layers an environment configuration over application defaults. Objects are
+// merged key by key, recursively; any other value in the override replaces the default,
+// including arrays and null.
+func MergeConfig(defaults, override json.RawMessage) (json.RawMessage, error) {
+	base, err := decodeConfigValue(defaults)
+	if err != nil {
+		return nil, invalidError("invalid defaults: %w", err)
+	}
+	layer, err := decodeConfigValue(override)
+	if err != nil {
+		return nil, invalidError("invalid JSON configuration: %w", err)
+	}
+
+	merged, err := json.Marshal(mergeValues(base, layer))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return merged, nil
+}
+
+// mergeValues merges override into base, without modifying either
+func mergeValues(base, override interface{}) interface{} {
+	baseObject, baseIsObject := base.(map[string]interface{})
+	overrideObject, overrideIsObject := override.(map[string]interface{})
+	if !baseIsObject || !overrideIsObject {
+		return override
+	}
+
+	merged := make(map[string]interface{}, len(baseObject)+len(overrideObject))
+	for key, value := range baseObject {
+		merged[key] = value
+	}
+	for key, value := range overrideObject {
+		if baseValue, ok := merged[key]; ok {
+			merged[key] = mergeValues(baseValue, value)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
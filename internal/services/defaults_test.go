@@ -0,0 +1,97 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		defaults string
+		override string
+		expected string
+	}{
+		{
+			name:     "environment keys override defaults",
+			defaults: `{"timeout": 30, "retries": 3}`,
+			override: `{"timeout": 60}`,
+			expected: `{"timeout": 60, "retries": 3}`,
+		},
+		{
+			name:     "nested objects are merged",
+			defaults: `{"features": {"search": true, "reviews": false}, "theme": "light"}`,
+			override: `{"features": {"reviews": true}}`,
+			expected: `{"features": {"search": true, "reviews": true}, "theme": "light"}`,
+		},
+		{
+			name:     "arrays are replaced",
+			defaults: `{"regions": ["eu", "us"]}`,
+			override: `{"regions": ["ap"]}`,
+			expected: `{"regions": ["ap"]}`,
+		},
+		{
+			name:     "null overrides a default",
+			defaults: `{"proxy": {"host": "proxy.local"}}`,
+			override: `{"proxy": null}`,
+			expected: `{"proxy": null}`,
+		},
+		{
+			name:     "object replaces a scalar default",
+			defaults: `{"cache": false}`,
+			override: `{"cache": {"ttl": 60}}`,
+			expected: `{"cache": {"ttl": 60}}`,
+		},
+		{
+			name:     "number formatting is preserved",
+			defaults: `{"ratio": 0.10, "big": 12345678901234567890}`,
+			override: `{}`,
+			expected: `{"ratio": 0.10, "big": 12345678901234567890}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := MergeConfig(json.RawMessage(tt.defaults), json.RawMessage(tt.override))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(merged))
+		})
+	}
+
+	t.Run("inputs are not modified", func(t *testing.T) {
+		defaults := json.RawMessage(`{"features": {"search": true}}`)
+		_, err := MergeConfig(defaults, json.RawMessage(`{"features": {"search": false}}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"features": {"search": true}}`, string(defaults))
+	})
+
+	t.Run("invalid defaults", func(t *testing.T) {
+		_, err := MergeConfig(json.RawMessage(`{invalid`), json.RawMessage(`{}`))
+		assert.ErrorContains(t, err, "invalid defaults")
+	})
+}
+
+func TestApplyDefaults(t *testing.T) {
+	t.Run("without defaults", func(t *testing.T) {
+		response := &models.ConfigResponse{Version: 3, Config: json.RawMessage(`{"timeout": 60}`)}
+		require.NoError(t, applyDefaults(response, nil))
+
+		assert.JSONEq(t, `{"timeout": 60}`, string(response.Config))
+		assert.Zero(t, response.DefaultsVersion)
+	})
+
+	t.Run("with defaults", func(t *testing.T) {
+		response := &models.ConfigResponse{Version: 3, Config: json.RawMessage(`{"timeout": 60}`)}
+		defaults := &models.ApplicationDefaults{Version: 2, ConfigJSON: json.RawMessage(`{"timeout": 30, "retries": 3}`)}
+		require.NoError(t, applyDefaults(response, defaults))
+
+		assert.JSONEq(t, `{"timeout": 60, "retries": 3}`, string(response.Config))
+		assert.Equal(t, 3, response.Version)
+		assert.Equal(t, 2, response.DefaultsVersion)
+	})
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"remote-config-system/internal/models"
+)
+
+// Diff entry types
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// DiffConfigs computes the differences between two JSON configuration documents.
+// Nested objects are walked recursively and reported using dot-notated paths;
+// arrays and scalar values are compared as a whole.
+func DiffConfigs(from, to json.RawMessage) (*models.ConfigDiff, error) {
+	fromValue, err := decodeConfigValue(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode source configuration: %w", err)
+	}
+
+	toValue, err := decodeConfigValue(to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode target configuration: %w", err)
+	}
+
+	diff := &models.ConfigDiff{Entries: []models.ConfigDiffEntry{}}
+	diffValues("", fromValue, toValue, diff)
+
+	sort.Slice(diff.Entries, func(i, j int) bool {
+		return diff.Entries[i].Path < diff.Entries[j].Path
+	})
+
+	for _, entry := range diff.Entries {
+		switch entry.Type {
+		case DiffAdded:
+			diff.Added++
+		case DiffRemoved:
+			diff.Removed++
+		case DiffChanged:
+			diff.Changed++
+		}
+	}
+	diff.HasChanges = len(diff.Entries) > 0
+
+	return diff, nil
+}
+
+// diffValues recursively compares two decoded JSON values and appends entries to the diff
+func diffValues(path string, from, to interface{}, diff *models.ConfigDiff) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+
+	if fromIsMap && toIsMap {
+		for key, fromChild := range fromMap {
+			childPath := joinPath(path, key)
+			toChild, exists := toMap[key]
+			if !exists {
+				diff.Entries = append(diff.Entries, models.ConfigDiffEntry{
+					Path:     childPath,
+					Type:     DiffRemoved,
+					OldValue: fromChild,
+				})
+				continue
+			}
+			diffValues(childPath, fromChild, toChild, diff)
+		}
+
+		for key, toChild := range toMap {
+			if _, exists := fromMap[key]; !exists {
+				diff.Entries = append(diff.Entries, models.ConfigDiffEntry{
+					Path:     joinPath(path, key),
+					Type:     DiffAdded,
+					NewValue: toChild,
+				})
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		diff.Entries = append(diff.Entries, models.ConfigDiffEntry{
+			Path:     path,
+			Type:     DiffChanged,
+			OldValue: from,
+			NewValue: to,
+		})
+	}
+}
+
+// decodeConfigValue decodes a JSON document preserving number formatting
+func decodeConfigValue(data json.RawMessage) (interface{}, error) {
+	if len(data) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// joinPath appends a key to a dot-notated path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
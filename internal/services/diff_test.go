@@ -0,0 +1,63 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	t.Run("identical configurations", func(t *testing.T) {
+		config := json.RawMessage(`{"a": 1, "b": {"c": true}}`)
+
+		diff, err := DiffConfigs(config, config)
+		require.NoError(t, err)
+
+		assert.False(t, diff.HasChanges)
+		assert.Empty(t, diff.Entries)
+	})
+
+	t.Run("added, removed and changed keys", func(t *testing.T) {
+		from := json.RawMessage(`{"timeout": 30, "debug": true, "db": {"host": "old-host", "port": 5432}}`)
+		to := json.RawMessage(`{"timeout": 60, "feature": "on", "db": {"host": "new-host", "port": 5432}}`)
+
+		diff, err := DiffConfigs(from, to)
+		require.NoError(t, err)
+
+		assert.True(t, diff.HasChanges)
+		assert.Equal(t, 1, diff.Added)
+		assert.Equal(t, 1, diff.Removed)
+		assert.Equal(t, 2, diff.Changed)
+
+		require.Len(t, diff.Entries, 4)
+		assert.Equal(t, "db.host", diff.Entries[0].Path)
+		assert.Equal(t, DiffChanged, diff.Entries[0].Type)
+		assert.Equal(t, "old-host", diff.Entries[0].OldValue)
+		assert.Equal(t, "new-host", diff.Entries[0].NewValue)
+		assert.Equal(t, "debug", diff.Entries[1].Path)
+		assert.Equal(t, DiffRemoved, diff.Entries[1].Type)
+		assert.Equal(t, "feature", diff.Entries[2].Path)
+		assert.Equal(t, DiffAdded, diff.Entries[2].Type)
+		assert.Equal(t, "timeout", diff.Entries[3].Path)
+		assert.Equal(t, DiffChanged, diff.Entries[3].Type)
+	})
+
+	t.Run("arrays are compared as a whole", func(t *testing.T) {
+		from := json.RawMessage(`{"hosts": ["a", "b"]}`)
+		to := json.RawMessage(`{"hosts": ["a", "c"]}`)
+
+		diff, err := DiffConfigs(from, to)
+		require.NoError(t, err)
+
+		require.Len(t, diff.Entries, 1)
+		assert.Equal(t, "hosts", diff.Entries[0].Path)
+		assert.Equal(t, DiffChanged, diff.Entries[0].Type)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := DiffConfigs(json.RawMessage(`{}`), json.RawMessage(`{invalid`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,463 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"remote-config-system/internal/models"
+)
+
+// Field names used to wrap encrypted values in stored configurations
+const (
+	encryptedValueField = "$encrypted"
+	encryptedKeyIDField = "kid"
+)
+
+// legacyKeyID identifies the key configured through CONFIG_ENCRYPTION_KEY. Values
+// encrypted with it carry no key ID.
+const legacyKeyID = ""
+
+// MaskedValue replaces secret values in diffs and change logs
+const MaskedValue = "<encrypted>"
+
+// encryptedValue is the stored representation of an encrypted configuration value
+type encryptedValue struct {
+	Ciphertext string `json:"$encrypted"`
+	KeyID      string `json:"kid,omitempty"`
+}
+
+// ConfigEncryptor encrypts and decrypts secret configuration values with AES-GCM.
+// New values are encrypted with the primary key; any configured key can decrypt.
+type ConfigEncryptor struct {
+	primaryID string
+	keys      map[string]cipher.AEAD
+}
+
+// NewConfigEncryptor creates an encryptor from a single raw AES key (16, 24 or 32 bytes)
+func NewConfigEncryptor(key []byte) (*ConfigEncryptor, error) {
+	return NewConfigEncryptorWithKeys(legacyKeyID, map[string][]byte{legacyKeyID: key})
+}
+
+// NewConfigEncryptorWithKeys creates an encryptor from raw AES keys indexed by key ID.
+// The primary key is used for new writes; the others are only used for decryption.
+func NewConfigEncryptorWithKeys(primaryID string, keys map[string][]byte) (*ConfigEncryptor, error) {
+	if _, ok := keys[primaryID]; !ok {
+		return nil, fmt.Errorf("primary encryption key '%s' is not configured", primaryID)
+	}
+
+	encryptor := &ConfigEncryptor{
+		primaryID: primaryID,
+		keys:      make(map[string]cipher.AEAD, len(keys)),
+	}
+
+	for id, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key '%s': %w", id, err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCM cipher: %w", err)
+		}
+		encryptor.keys[id] = aead
+	}
+
+	return encryptor, nil
+}
+
+// NewConfigEncryptorFromEnv creates an encryptor from environment variables. CONFIG_ENCRYPTION_KEYS
+// holds a comma-separated list of id:base64-key pairs, the first being the primary key. The
+// base64-encoded CONFIG_ENCRYPTION_KEY is also accepted, and is the primary key when no key list
+// is set. It returns nil when no key is configured.
+func NewConfigEncryptorFromEnv() (*ConfigEncryptor, error) {
+	keys := make(map[string][]byte)
+	primaryID := legacyKeyID
+
+	if encoded := os.Getenv("CONFIG_ENCRYPTION_KEY"); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("CONFIG_ENCRYPTION_KEY must be base64-encoded: %w", err)
+		}
+		keys[legacyKeyID] = key
+	}
+
+	if list := os.Getenv("CONFIG_ENCRYPTION_KEYS"); list != "" {
+		for i, entry := range strings.Split(list, ",") {
+			id, encoded, found := strings.Cut(strings.TrimSpace(entry), ":")
+			if !found || id == "" {
+				return nil, fmt.Errorf("CONFIG_ENCRYPTION_KEYS entries must be in the form id:base64-key")
+			}
+			if _, exists := keys[id]; exists {
+				return nil, fmt.Errorf("CONFIG_ENCRYPTION_KEYS contains duplicate key ID '%s'", id)
+			}
+
+			key, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("CONFIG_ENCRYPTION_KEYS key '%s' must be base64-encoded: %w", id, err)
+			}
+			keys[id] = key
+
+			if i == 0 {
+				primaryID = id
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	return NewConfigEncryptorWithKeys(primaryID, keys)
+}
+
+// PrimaryKeyID returns the ID of the key used for new writes
+func (e *ConfigEncryptor) PrimaryKeyID() string {
+	return e.primaryID
+}
+
+// encrypt seals a raw JSON value with the primary key and returns its stored representation
+func (e *ConfigEncryptor) encrypt(value json.RawMessage) (json.RawMessage, error) {
+	aead := e.keys[e.primaryID]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, value, nil)
+	return json.Marshal(encryptedValue{
+		Ciphertext: base64.StdEncoding.EncodeToString(sealed),
+		KeyID:      e.primaryID,
+	})
+}
+
+// decrypt opens a stored value with the key it was encrypted with and returns the
+// original raw JSON value. Values without a key ID predate key rotation and are
+// tried against every configured key, starting with the legacy key.
+func (e *ConfigEncryptor) decrypt(value encryptedValue) (json.RawMessage, error) {
+	sealed, err := base64.StdEncoding.DecodeString(value.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext encoding: %w", err)
+	}
+
+	if value.KeyID != legacyKeyID {
+		aead, ok := e.keys[value.KeyID]
+		if !ok {
+			return nil, fmt.Errorf("encryption key '%s' is not configured", value.KeyID)
+		}
+		return open(aead, sealed)
+	}
+
+	if aead, ok := e.keys[legacyKeyID]; ok {
+		if plaintext, err := open(aead, sealed); err == nil {
+			return plaintext, nil
+		}
+	}
+	for id, aead := range e.keys {
+		if id == legacyKeyID {
+			continue
+		}
+		if plaintext, err := open(aead, sealed); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to decrypt value: no configured key matches")
+}
+
+// open splits the nonce from a sealed value and authenticates and decrypts it
+func open(aead cipher.AEAD, sealed []byte) (json.RawMessage, error) {
+	nonceSize := aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// EncryptSecrets encrypts the values of the given top-level keys. Values that are
+// already encrypted are left untouched, so the operation is idempotent.
+func (e *ConfigEncryptor) EncryptSecrets(config json.RawMessage, secretKeys []string) (json.RawMessage, bool, error) {
+	if len(secretKeys) == 0 {
+		return config, false, nil
+	}
+
+	fields, ok := decodeTopLevel(config)
+	if !ok {
+		return config, false, nil
+	}
+
+	changed := false
+	for _, key := range secretKeys {
+		value, exists := fields[key]
+		if !exists {
+			continue
+		}
+		if _, encrypted := parseEncryptedValue(value); encrypted {
+			continue
+		}
+
+		if e == nil {
+			return nil, false, fmt.Errorf("secret key '%s' cannot be stored: CONFIG_ENCRYPTION_KEY is not configured", key)
+		}
+
+		sealed, err := e.encrypt(value)
+		if err != nil {
+			return nil, false, err
+		}
+		fields[key] = sealed
+		changed = true
+	}
+
+	if !changed {
+		return config, false, nil
+	}
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return encoded, true, nil
+}
+
+// DecryptSecrets replaces every encrypted top-level value with its plaintext
+func (e *ConfigEncryptor) DecryptSecrets(config json.RawMessage) (json.RawMessage, error) {
+	fields, ok := decodeTopLevel(config)
+	if !ok {
+		return config, nil
+	}
+
+	changed := false
+	for key, value := range fields {
+		sealed, encrypted := parseEncryptedValue(value)
+		if !encrypted {
+			continue
+		}
+
+		if e == nil {
+			return nil, fmt.Errorf("configuration contains encrypted values but CONFIG_ENCRYPTION_KEY is not configured")
+		}
+
+		plaintext, err := e.decrypt(sealed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt '%s': %w", key, err)
+		}
+		fields[key] = plaintext
+		changed = true
+	}
+
+	if !changed {
+		return config, nil
+	}
+
+	return json.Marshal(fields)
+}
+
+// ReencryptSecrets re-encrypts every encrypted top-level value that was not encrypted
+// with the primary key, so that older keys can be retired
+func (e *ConfigEncryptor) ReencryptSecrets(config json.RawMessage) (json.RawMessage, bool, error) {
+	fields, ok := decodeTopLevel(config)
+	if !ok {
+		return config, false, nil
+	}
+
+	changed := false
+	for key, value := range fields {
+		sealed, encrypted := parseEncryptedValue(value)
+		if !encrypted || sealed.KeyID == e.primaryID {
+			continue
+		}
+
+		plaintext, err := e.decrypt(sealed)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to decrypt '%s': %w", key, err)
+		}
+
+		resealed, err := e.encrypt(plaintext)
+		if err != nil {
+			return nil, false, err
+		}
+		fields[key] = resealed
+		changed = true
+	}
+
+	if !changed {
+		return config, false, nil
+	}
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return encoded, true, nil
+}
+
+// EncryptedKeys returns the top-level keys whose values are stored encrypted
+func EncryptedKeys(config json.RawMessage) []string {
+	fields, ok := decodeTopLevel(config)
+	if !ok {
+		return nil
+	}
+
+	var keys []string
+	for key, value := range fields {
+		if _, encrypted := parseEncryptedValue(value); encrypted {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// MaskSecrets replaces every encrypted top-level value with MaskedValue, for configurations
+// sent to clients that may not read secrets
+func MaskSecrets(config json.RawMessage) (json.RawMessage, error) {
+	return maskKeys(config, EncryptedKeys(config))
+}
+
+// unmaskSecrets returns a copy of a masked configuration with the encrypted values of
+// the stored configuration it was masked from put back, each as a single string so that
+// a changed secret is reported under its own key. Unlike the masked values, they differ
+// whenever a secret changes, so changes to secrets can be detected.
+func unmaskSecrets(masked, stored json.RawMessage) json.RawMessage {
+	keys := EncryptedKeys(stored)
+	if len(keys) == 0 {
+		return masked
+	}
+	fields, ok := decodeTopLevel(masked)
+	if !ok {
+		return masked
+	}
+	storedFields, _ := decodeTopLevel(stored)
+
+	for _, key := range keys {
+		if _, present := fields[key]; present {
+			ciphertext, _ := json.Marshal(string(storedFields[key]))
+			fields[key] = ciphertext
+		}
+	}
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return masked
+	}
+	return encoded
+}
+
+// MaskSecretValues returns a copy of a decrypted response with the values of its secret
+// keys replaced with MaskedValue
+func MaskSecretValues(response *models.ConfigResponse) (*models.ConfigResponse, error) {
+	config, err := maskKeys(response.Config, response.SecretKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	masked := *response
+	masked.Config = config
+	masked.SecretKeys = nil
+	return &masked, nil
+}
+
+// maskKeys replaces the values of the given top-level keys of a configuration with MaskedValue
+func maskKeys(config json.RawMessage, keys []string) (json.RawMessage, error) {
+	if len(keys) == 0 {
+		return config, nil
+	}
+	fields, ok := decodeTopLevel(config)
+	if !ok {
+		return config, nil
+	}
+
+	placeholder, _ := json.Marshal(MaskedValue)
+	for _, key := range keys {
+		if _, present := fields[key]; present {
+			fields[key] = placeholder
+		}
+	}
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return encoded, nil
+}
+
+// MaskSecretDiff replaces the values of diff entries under secret top-level keys
+// with a placeholder so that plaintext secrets never appear in a diff
+func MaskSecretDiff(diff *models.ConfigDiff, secretKeys []string) {
+	if diff == nil || len(secretKeys) == 0 {
+		return
+	}
+
+	secrets := make(map[string]bool, len(secretKeys))
+	for _, key := range secretKeys {
+		secrets[key] = true
+	}
+
+	for i := range diff.Entries {
+		topLevel := strings.SplitN(diff.Entries[i].Path, ".", 2)[0]
+		if !secrets[topLevel] {
+			continue
+		}
+		if diff.Entries[i].OldValue != nil {
+			diff.Entries[i].OldValue = MaskedValue
+		}
+		if diff.Entries[i].NewValue != nil {
+			diff.Entries[i].NewValue = MaskedValue
+		}
+	}
+}
+
+// decodeTopLevel decodes a configuration object into its raw top-level fields
+func decodeTopLevel(config json.RawMessage) (map[string]json.RawMessage, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(config, &fields); err != nil || fields == nil {
+		return nil, false
+	}
+	return fields, true
+}
+
+// parseEncryptedValue reports whether a raw value is an encrypted wrapper and returns its contents
+func parseEncryptedValue(value json.RawMessage) (encryptedValue, bool) {
+	trimmed := strings.TrimSpace(string(value))
+	if !strings.HasPrefix(trimmed, "{") || !strings.Contains(trimmed, encryptedValueField) {
+		return encryptedValue{}, false
+	}
+
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(value, &wrapper); err != nil {
+		return encryptedValue{}, false
+	}
+
+	var sealed encryptedValue
+	for field, raw := range wrapper {
+		switch field {
+		case encryptedValueField:
+			if err := json.Unmarshal(raw, &sealed.Ciphertext); err != nil {
+				return encryptedValue{}, false
+			}
+		case encryptedKeyIDField:
+			if err := json.Unmarshal(raw, &sealed.KeyID); err != nil {
+				return encryptedValue{}, false
+			}
+		default:
+			return encryptedValue{}, false
+		}
+	}
+
+	if _, ok := wrapper[encryptedValueField]; !ok {
+		return encryptedValue{}, false
+	}
+	return sealed, true
+}
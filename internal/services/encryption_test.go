@@ -0,0 +1,228 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEncryptor(t *testing.T) *ConfigEncryptor {
+	encryptor, err := NewConfigEncryptor([]byte(strings.Repeat("k", 32)))
+	require.NoError(t, err)
+	return encryptor
+}
+
+func TestConfigEncryptor_RoundTrip(t *testing.T) {
+	encryptor := newTestEncryptor(t)
+	config := json.RawMessage(`{"db_password": "hunter2", "api": {"token": "abc"}, "timeout": 30}`)
+
+	stored, changed, err := encryptor.EncryptSecrets(config, []string{"db_password", "api", "missing"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.NotContains(t, string(stored), "hunter2")
+	assert.NotContains(t, string(stored), "abc")
+	assert.ElementsMatch(t, []string{"db_password", "api"}, EncryptedKeys(stored))
+
+	// Encrypting again is a no-op
+	again, changed, err := encryptor.EncryptSecrets(stored, []string{"db_password", "api"})
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, string(stored), string(again))
+
+	decrypted, err := encryptor.DecryptSecrets(stored)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(config), string(decrypted))
+}
+
+func TestConfigEncryptor_WithoutKey(t *testing.T) {
+	var encryptor *ConfigEncryptor
+	config := json.RawMessage(`{"db_password": "hunter2"}`)
+
+	// Configurations without secret keys pass through untouched
+	stored, changed, err := encryptor.EncryptSecrets(config, nil)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, string(config), string(stored))
+
+	_, _, err = encryptor.EncryptSecrets(config, []string{"db_password"})
+	assert.Error(t, err)
+
+	encrypted, _, err := newTestEncryptor(t).EncryptSecrets(config, []string{"db_password"})
+	require.NoError(t, err)
+	_, err = encryptor.DecryptSecrets(encrypted)
+	assert.Error(t, err)
+}
+
+func TestConfigEncryptor_WrongKey(t *testing.T) {
+	stored, _, err := newTestEncryptor(t).EncryptSecrets(json.RawMessage(`{"secret": 1}`), []string{"secret"})
+	require.NoError(t, err)
+
+	other, err := NewConfigEncryptor([]byte(strings.Repeat("x", 32)))
+	require.NoError(t, err)
+
+	_, err = other.DecryptSecrets(stored)
+	assert.Error(t, err)
+}
+
+func TestNewConfigEncryptorFromEnv(t *testing.T) {
+	t.Setenv("CONFIG_ENCRYPTION_KEYS", "")
+	t.Setenv("CONFIG_ENCRYPTION_KEY", "")
+	encryptor, err := NewConfigEncryptorFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, encryptor)
+
+	t.Setenv("CONFIG_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	encryptor, err = NewConfigEncryptorFromEnv()
+	require.NoError(t, err)
+	assert.NotNil(t, encryptor)
+
+	t.Setenv("CONFIG_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString([]byte("short")))
+	_, err = NewConfigEncryptorFromEnv()
+	assert.Error(t, err)
+}
+
+func TestNewConfigEncryptorFromEnv_KeyList(t *testing.T) {
+	newKey := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("n", 32)))
+	oldKey := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("o", 32)))
+
+	t.Setenv("CONFIG_ENCRYPTION_KEY", "")
+	t.Setenv("CONFIG_ENCRYPTION_KEYS", "k2:"+newKey+", k1:"+oldKey)
+	encryptor, err := NewConfigEncryptorFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "k2", encryptor.PrimaryKeyID())
+
+	for _, invalid := range []string{"k2", ":" + newKey, "k2:not-base64!", "k2:" + newKey + ",k2:" + oldKey} {
+		t.Setenv("CONFIG_ENCRYPTION_KEYS", invalid)
+		_, err = NewConfigEncryptorFromEnv()
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestConfigEncryptor_KeyRotation(t *testing.T) {
+	oldKey := []byte(strings.Repeat("o", 32))
+	newKey := []byte(strings.Repeat("n", 32))
+	config := json.RawMessage(`{"db_password": "hunter2", "timeout": 30}`)
+
+	// Values written before key IDs existed carry no key ID
+	legacy := newTestEncryptor(t)
+	legacyStored, _, err := legacy.EncryptSecrets(config, []string{"db_password"})
+	require.NoError(t, err)
+
+	old, err := NewConfigEncryptorWithKeys("k1", map[string][]byte{"k1": oldKey})
+	require.NoError(t, err)
+	oldStored, _, err := old.EncryptSecrets(config, []string{"db_password"})
+	require.NoError(t, err)
+	assert.Contains(t, string(oldStored), `"kid":"k1"`)
+
+	rotated, err := NewConfigEncryptorWithKeys("k2", map[string][]byte{
+		"k2": newKey,
+		"k1": oldKey,
+		"":   []byte(strings.Repeat("k", 32)),
+	})
+	require.NoError(t, err)
+
+	for _, stored := range []json.RawMessage{legacyStored, oldStored} {
+		decrypted, err := rotated.DecryptSecrets(stored)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(config), string(decrypted))
+
+		reencrypted, changed, err := rotated.ReencryptSecrets(stored)
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.Contains(t, string(reencrypted), `"kid":"k2"`)
+
+		// Values already under the primary key are left untouched
+		_, changed, err = rotated.ReencryptSecrets(reencrypted)
+		require.NoError(t, err)
+		assert.False(t, changed)
+
+		// Only the new key is needed once values are re-encrypted
+		current, err := NewConfigEncryptorWithKeys("k2", map[string][]byte{"k2": newKey})
+		require.NoError(t, err)
+		decrypted, err = current.DecryptSecrets(reencrypted)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(config), string(decrypted))
+	}
+
+	// Values for an unknown key ID cannot be decrypted
+	current, err := NewConfigEncryptorWithKeys("k2", map[string][]byte{"k2": newKey})
+	require.NoError(t, err)
+	_, err = current.DecryptSecrets(oldStored)
+	assert.Error(t, err)
+}
+
+func TestMaskSecretDiff(t *testing.T) {
+	diff, err := DiffConfigs(
+		json.RawMessage(`{"password": "old", "nested": {"token": "a"}, "timeout": 30}`),
+		json.RawMessage(`{"password": "new", "nested": {"token": "b"}, "timeout": 60}`),
+	)
+	require.NoError(t, err)
+
+	MaskSecretDiff(diff, []string{"password", "nested"})
+
+	byPath := map[string]models.ConfigDiffEntry{}
+	for _, entry := range diff.Entries {
+		byPath[entry.Path] = entry
+	}
+	assert.Equal(t, MaskedValue, byPath["password"].OldValue)
+	assert.Equal(t, MaskedValue, byPath["password"].NewValue)
+	assert.Equal(t, MaskedValue, byPath["nested.token"].NewValue)
+	assert.Equal(t, json.Number("60"), byPath["timeout"].NewValue)
+}
+
+func TestMaskSecrets(t *testing.T) {
+	encryptor := newTestEncryptor(t)
+	stored, _, err := encryptor.EncryptSecrets(json.RawMessage(`{"db_password": "hunter2", "timeout": 30}`), []string{"db_password"})
+	require.NoError(t, err)
+
+	masked, err := MaskSecrets(stored)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"db_password": "<encrypted>", "timeout": 30}`, string(masked))
+
+	plain := json.RawMessage(`{"timeout": 30}`)
+	masked, err = MaskSecrets(plain)
+	require.NoError(t, err)
+	assert.Equal(t, string(plain), string(masked))
+}
+
+func TestUnmaskSecrets(t *testing.T) {
+	encryptor := newTestEncryptor(t)
+	stored, _, err := encryptor.EncryptSecrets(json.RawMessage(`{"db_password": "hunter2", "timeout": 30}`), []string{"db_password"})
+	require.NoError(t, err)
+	masked, err := MaskSecrets(stored)
+	require.NoError(t, err)
+
+	merged := json.RawMessage(`{"db_password": "<encrypted>", "timeout": 30, "retries": 3}`)
+	unmasked := unmaskSecrets(merged, stored)
+	var storedFields, fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(stored, &storedFields))
+	require.NoError(t, json.Unmarshal(unmasked, &fields))
+	var ciphertext string
+	require.NoError(t, json.Unmarshal(fields["db_password"], &ciphertext))
+	assert.Equal(t, string(storedFields["db_password"]), ciphertext)
+	assert.JSONEq(t, `3`, string(fields["retries"]))
+
+	plain := json.RawMessage(`{"timeout": 30}`)
+	assert.Equal(t, string(plain), string(unmaskSecrets(plain, plain)))
+	assert.Equal(t, string(masked), string(unmaskSecrets(masked, json.RawMessage(`not json`))))
+}
+
+func TestMaskSecretValues(t *testing.T) {
+	response := &models.ConfigResponse{
+		Environment: "prod",
+		Config:      json.RawMessage(`{"db_password": "hunter2", "api": {"token": "abc"}, "timeout": 30}`),
+		SecretKeys:  []string{"db_password", "api"},
+	}
+
+	masked, err := MaskSecretValues(response)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"db_password": "<encrypted>", "api": "<encrypted>", "timeout": 30}`, string(masked.Config))
+	assert.Equal(t, "prod", masked.Environment)
+	assert.Contains(t, string(response.Config), "hunter2", "the response itself is left alone")
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"remote-config-system/internal/db"
+)
+
+// Sentinel errors that callers match with errors.Is to tell what kind of failure an
+// error describes. The errors returned by the service keep their descriptive messages.
+var (
+	// ErrNotFound means an organization, application, environment or other resource does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict means a request conflicts with the current state, e.g. a slug already in use
+	ErrConflict = errors.New("conflict")
+	// ErrAlreadyExists means a resource cannot be created because its slug is already in
+	// use. Errors that match it also match ErrConflict.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrForbidden means a request is not allowed for its caller, e.g. the approval of a
+	// change by the admin token that proposed it
+	ErrForbidden = errors.New("forbidden")
+	// ErrLocked means a configuration change was rejected because the environment is locked
+	ErrLocked = errors.New("locked")
+	// ErrQuotaExceeded means a configuration read was rejected because the organization
+	// has used up its monthly read quota
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrInvalidInput means a request is malformed, e.g. a configuration that is not valid
+	// JSON or a slug with characters that are not allowed
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrTooLarge means a configuration or blob exceeds the maximum size
+	ErrTooLarge = errors.New("too large")
+	// ErrValidation means a well-formed configuration was rejected, e.g. because it is
+	// nested too deeply, violates the environment's rules or has a broken reference
+	ErrValidation = errors.New("validation failed")
+	// ErrBrokenReference means a configuration has a reference that cannot be resolved.
+	// Errors that match it also match ErrValidation.
+	ErrBrokenReference = errors.New("broken reference")
+	// ErrCorruptConfig means a stored configuration version cannot be served because its
+	// document is not a JSON object
+	ErrCorruptConfig = db.ErrCorruptConfig
+)
+
+// AlreadyExistsError reports the resource whose slug is already in use
+type AlreadyExistsError struct {
+	Resource       string // organization, application or environment
+	Slug           string
+	ParentResource string // Set for resources whose slugs are unique within a parent
+	ParentSlug     string
+}
+
+func (e *AlreadyExistsError) Error() string {
+	if e.ParentResource == "" {
+		return fmt.Sprintf("%s with slug '%s' already exists", e.Resource, e.Slug)
+	}
+	return fmt.Sprintf("%s with slug '%s' already exists in %s '%s'", e.Resource, e.Slug, e.ParentResource, e.ParentSlug)
+}
+
+func (e *AlreadyExistsError) Is(target error) bool {
+	return target == ErrAlreadyExists || target == ErrConflict
+}
+
+// QuotaExceededError reports an organization that has used up its monthly read quota
+type QuotaExceededError struct {
+	Organization string
+	Quota        int64
+	ResetsAt     time.Time // When the next month's reads start
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("monthly read quota of %d reads exceeded for organization '%s'; reads resume at %s",
+		e.Quota, e.Organization, e.ResetsAt.Format(time.RFC3339))
+}
+
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// kindError tags an error with one of the sentinel errors without changing its message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// notFoundError formats an error that matches ErrNotFound. A corrupt configuration is
+// returned as it is: the version exists, and its error already names it.
+func notFoundError(format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	var corrupt *db.CorruptConfigError
+	if errors.As(err, &corrupt) {
+		return corrupt
+	}
+	return &kindError{kind: ErrNotFound, err: err}
+}
+
+// lookupError describes a failed repository lookup with a message such as "environment
+// not found". Only a missing record matches ErrNotFound; any other error, such as a lost
+// database connection, is passed on with its own kind, so that it is not reported as a
+// missing resource.
+func lookupError(message string, err error) error {
+	if errors.Is(err, db.ErrNotFound) || errors.Is(err, ErrNotFound) {
+		return notFoundError("%s: %w", message, err)
+	}
+	return recordError(err)
+}
+
+// conflictError formats an error that matches ErrConflict
+func conflictError(format string, args ...interface{}) error {
+	return &kindError{kind: ErrConflict, err: fmt.Errorf(format, args...)}
+}
+
+// forbiddenError formats an error that matches ErrForbidden
+func forbiddenError(format string, args ...interface{}) error {
+	return &kindError{kind: ErrForbidden, err: fmt.Errorf(format, args...)}
+}
+
+// lockedError formats an error that matches ErrLocked
+func lockedError(format string, args ...interface{}) error {
+	return &kindError{kind: ErrLocked, err: fmt.Errorf(format, args...)}
+}
+
+// invalidError formats an error that matches ErrInvalidInput
+func invalidError(format string, args ...interface{}) error {
+	return &kindError{kind: ErrInvalidInput, err: fmt.Errorf(format, args...)}
+}
+
+// tooLargeError formats an error that matches ErrTooLarge
+func tooLargeError(format string, args ...interface{}) error {
+	return &kindError{kind: ErrTooLarge, err: fmt.Errorf(format, args...)}
+}
+
+// validationError formats an error that matches ErrValidation
+func validationError(format string, args ...interface{}) error {
+	return &kindError{kind: ErrValidation, err: fmt.Errorf(format, args...)}
+}
+
+// brokenReferenceError formats an error that matches ErrBrokenReference and ErrValidation
+func brokenReferenceError(format string, args ...interface{}) error {
+	return &kindError{kind: ErrBrokenReference, err: validationError(format, args...)}
+}
+
+// recordError marks a repository error about a missing record as ErrNotFound and one about
+// a version numbered by a concurrent update as ErrConflict, and returns any other error
+// unchanged
+func recordError(err error) error {
+	if errors.Is(err, db.ErrNotFound) {
+		return &kindError{kind: ErrNotFound, err: err}
+	}
+	if errors.Is(err, db.ErrVersionConflict) {
+		return &kindError{kind: ErrConflict, err: err}
+	}
+	return err
+}
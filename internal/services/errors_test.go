@@ -0,0 +1,86 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"remote-config-system/internal/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	t.Run("kind is matched without changing the message", func(t *testing.T) {
+		cause := fmt.Errorf("environment not found: acme/web/qa")
+		err := notFoundError("environment not found: %w", cause)
+
+		assert.True(t, errors.Is(err, ErrNotFound))
+		assert.False(t, errors.Is(err, ErrConflict))
+		assert.True(t, errors.Is(err, cause))
+		assert.Equal(t, "environment not found: environment not found: acme/web/qa", err.Error())
+
+		err = conflictError("organization with slug '%s' already exists", "acme")
+		assert.True(t, errors.Is(err, ErrConflict))
+		assert.Equal(t, "organization with slug 'acme' already exists", err.Error())
+	})
+
+	t.Run("slug already in use", func(t *testing.T) {
+		err := fmt.Errorf("failed to create: %w", &AlreadyExistsError{Resource: "environment", Slug: "prod", ParentResource: "application", ParentSlug: "web"})
+
+		assert.True(t, errors.Is(err, ErrAlreadyExists))
+		assert.True(t, errors.Is(err, ErrConflict))
+		assert.False(t, errors.Is(err, ErrNotFound))
+		assert.Equal(t, "failed to create: environment with slug 'prod' already exists in application 'web'", err.Error())
+
+		var exists *AlreadyExistsError
+		assert.True(t, errors.As(err, &exists))
+		assert.Equal(t, "prod", exists.Slug)
+
+		err = &AlreadyExistsError{Resource: "organization", Slug: "acme"}
+		assert.Equal(t, "organization with slug 'acme' already exists", err.Error())
+	})
+
+	t.Run("kind survives further wrapping", func(t *testing.T) {
+		err := fmt.Errorf("failed to load: %w", notFoundError("tag not found: beta"))
+		assert.True(t, errors.Is(err, ErrNotFound))
+	})
+
+	t.Run("repository errors", func(t *testing.T) {
+		assert.True(t, errors.Is(recordError(fmt.Errorf("rollout not found for environment: qa: %w", db.ErrNotFound)), ErrNotFound))
+		assert.False(t, errors.Is(recordError(fmt.Errorf("failed to get rollout: connection refused")), ErrNotFound))
+		// Only the kind is matched, not the message
+		assert.False(t, errors.Is(recordError(fmt.Errorf("failed to notify: webhook not found")), ErrNotFound))
+		assert.True(t, errors.Is(recordError(fmt.Errorf("another version was created at the same time: %w", db.ErrVersionConflict)), ErrConflict))
+		assert.Nil(t, recordError(nil))
+	})
+
+	t.Run("lookups", func(t *testing.T) {
+		missing := lookupError("environment not found", fmt.Errorf("environment not found: acme/web/qa: %w", db.ErrNotFound))
+		assert.True(t, errors.Is(missing, ErrNotFound))
+		assert.Equal(t, "environment not found: environment not found: acme/web/qa: record not found", missing.Error())
+
+		// A database outage is not a missing environment
+		outage := lookupError("environment not found", fmt.Errorf("failed to get environment: %w", driver.ErrBadConn))
+		assert.False(t, errors.Is(outage, ErrNotFound))
+		assert.True(t, errors.Is(outage, driver.ErrBadConn))
+		assert.Equal(t, "failed to get environment: driver: bad connection", outage.Error())
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		err := fmt.Errorf("failed to instantiate: %w", tooLargeError("configuration too large: %d bytes", 2048))
+		assert.True(t, errors.Is(err, ErrTooLarge))
+		assert.False(t, errors.Is(err, ErrValidation))
+		assert.Equal(t, "failed to instantiate: configuration too large: 2048 bytes", err.Error())
+
+		err = brokenReferenceError("broken reference %s: cycle", "env://qa/db")
+		assert.True(t, errors.Is(err, ErrBrokenReference))
+		assert.True(t, errors.Is(err, ErrValidation))
+		assert.False(t, errors.Is(err, ErrInvalidInput))
+		assert.Equal(t, "broken reference env://qa/db: cycle", err.Error())
+
+		assert.True(t, errors.Is(invalidError("invalid slug %q", "A B"), ErrInvalidInput))
+		assert.False(t, errors.Is(validationError("configuration has too many keys"), ErrBrokenReference))
+	})
+}
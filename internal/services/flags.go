@@ -0,0 +1,101 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+
+	"remote-config-system/internal/cache"
+	"remote-config-system/internal/models"
+)
+
+// GetFlagsByAPIKey returns the boolean top-level keys of the active configuration as
+// feature flags. When names is non-empty only those flags are returned; flags missing
+// from the configuration are then set to defaultValue, or omitted if it is nil.
+func (s *ConfigService) GetFlagsByAPIKey(app *models.Application, envSlug string, names []string, defaultValue *bool) (*models.FlagSet, error) {
+	flagSet, err := s.getFlagSetByAPIKey(app, envSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	return FilterFlags(flagSet, names, defaultValue), nil
+}
+
+// getFlagSetByAPIKey retrieves all flags of the active configuration, caching them
+// separately from the full configuration
+func (s *ConfigService) getFlagSetByAPIKey(app *models.Application, envSlug string) (*models.FlagSet, error) {
+	// Try to get from cache first
+	if s.cacheAvailable() {
+		cacheKey := cache.GenerateAPIKeyFlagsKey(app.ID.String(), envSlug)
+		if cachedData := s.readCache(cacheKey); cachedData != nil {
+			var flagSet models.FlagSet
+			err := json.Unmarshal(cachedData, &flagSet)
+			if err == nil {
+				log.Printf("Cache hit for API key flags: %s", cacheKey)
+				return &flagSet, nil
+			}
+			log.Printf("Failed to unmarshal cached API key flags: %v", err)
+		}
+	}
+
+	// Flags follow the active version; rollouts only apply to configuration reads
+	config, err := s.GetConfigurationByAPIKey(app, envSlug, "")
+	if err != nil {
+		return nil, err
+	}
+
+	flagSet := &models.FlagSet{
+		Version:         config.Version,
+		DefaultsVersion: config.DefaultsVersion,
+		Flags:           ExtractFlags(config.Config),
+	}
+
+	// Cache the flags
+	if s.cacheAvailable() {
+		cacheKey := cache.GenerateAPIKeyFlagsKey(app.ID.String(), envSlug)
+		if err := s.cache.SetConfig(cacheKey, flagSet); err != nil {
+			log.Printf("Failed to cache API key flags: %v", err)
+		}
+	}
+
+	return flagSet, nil
+}
+
+// ExtractFlags returns the top-level keys of a configuration whose values are booleans
+func ExtractFlags(config json.RawMessage) map[string]bool {
+	flags := make(map[string]bool)
+
+	fields, ok := decodeTopLevel(config)
+	if !ok {
+		return flags
+	}
+
+	for key, value := range fields {
+		var flag bool
+		if err := json.Unmarshal(value, &flag); err == nil {
+			flags[key] = flag
+		}
+	}
+	return flags
+}
+
+// FilterFlags restricts a flag set to the requested names, filling in defaultValue
+// for requested flags that are not present. An empty names list returns all flags.
+func FilterFlags(flagSet *models.FlagSet, names []string, defaultValue *bool) *models.FlagSet {
+	if len(names) == 0 {
+		return flagSet
+	}
+
+	filtered := &models.FlagSet{
+		Version:         flagSet.Version,
+		DefaultsVersion: flagSet.DefaultsVersion,
+		Flags:           make(map[string]bool, len(names)),
+	}
+	for _, name := range names {
+		if flag, ok := flagSet.Flags[name]; ok {
+			filtered.Flags[name] = flag
+		} else if defaultValue != nil {
+			filtered.Flags[name] = *defaultValue
+		}
+	}
+	return filtered
+}
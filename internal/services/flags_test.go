@@ -0,0 +1,42 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractFlags(t *testing.T) {
+	config := json.RawMessage(`{"dark_mode": true, "beta": false, "max_items": 10, "name": "app", "nested": {"flag": true}}`)
+
+	flags := ExtractFlags(config)
+	assert.Equal(t, map[string]bool{"dark_mode": true, "beta": false}, flags)
+
+	assert.Empty(t, ExtractFlags(json.RawMessage(`[true, false]`)))
+}
+
+func TestFilterFlags(t *testing.T) {
+	flagSet := &models.FlagSet{
+		Version: 3,
+		Flags:   map[string]bool{"dark_mode": true, "beta": false},
+	}
+
+	t.Run("no names returns all flags", func(t *testing.T) {
+		assert.Equal(t, flagSet, FilterFlags(flagSet, nil, nil))
+	})
+
+	t.Run("missing flags are omitted without a default", func(t *testing.T) {
+		filtered := FilterFlags(flagSet, []string{"dark_mode", "unknown"}, nil)
+		assert.Equal(t, 3, filtered.Version)
+		assert.Equal(t, map[string]bool{"dark_mode": true}, filtered.Flags)
+	})
+
+	t.Run("missing flags use the default", func(t *testing.T) {
+		defaultValue := false
+		filtered := FilterFlags(flagSet, []string{"beta", "unknown"}, &defaultValue)
+		assert.Equal(t, map[string]bool{"beta": false, "unknown": false}, filtered.Flags)
+	})
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// CheckGolden is the name of the validation check against the golden environment
+const CheckGolden = "golden"
+
+// compareGolden compares the keys of an update of an environment's configuration with the
+// keys of the active configuration of its golden environment. It returns nil when the
+// environment has no golden environment, or when that has no active configuration.
+func (s *ConfigService) compareGolden(env *models.Environment, config json.RawMessage) (*models.GoldenCheck, error) {
+	if env == nil || env.GoldenEnvironment == nil || env.Application == nil || env.Application.Organization == nil || s.repos == nil {
+		return nil, nil
+	}
+
+	goldenSlug := *env.GoldenEnvironment
+	golden, err := s.repos.Environments.GetBySlug(env.Application.Organization.Slug, env.Application.Slug, goldenSlug)
+	if err != nil {
+		log.Printf("Golden environment %s of %s not found, skipping key comparison: %v", goldenSlug, env.Slug, err)
+		return nil, nil
+	}
+	active, err := s.repos.ConfigVersions.GetActiveByEnvironment(golden.ID)
+	if err != nil {
+		return nil, nil
+	}
+
+	goldenConfig, err := s.encryptor.DecryptSecrets(active.ConfigJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt golden configuration: %w", err)
+	}
+
+	// Keys inherited from the application defaults are present in both
+	goldenConfig, _, err = s.effectiveConfig(env.AppID, goldenConfig)
+	if err != nil {
+		return nil, err
+	}
+	config, _, err = s.effectiveConfig(env.AppID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	missing, extra, err := CompareKeys(goldenConfig, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.GoldenCheck{
+		Environment: goldenSlug,
+		Version:     active.Version,
+		Strict:      env.GoldenStrict,
+		MissingKeys: missing,
+		ExtraKeys:   extra,
+	}, nil
+}
+
+// checkGoldenEnvironment returns an error if an environment's golden environment is not
+// another existing environment of the same application
+func (s *ConfigService) checkGoldenEnvironment(appID uuid.UUID, envSlug string, golden *string) error {
+	if golden == nil {
+		return nil
+	}
+	if *golden == envSlug {
+		return invalidError("invalid golden environment: environment %s cannot be its own golden environment", envSlug)
+	}
+
+	exists, err := s.repos.Environments.Exists(appID, *golden)
+	if err != nil {
+		return fmt.Errorf("failed to check golden environment existence: %w", err)
+	}
+	if !exists {
+		return invalidError("invalid golden environment: environment %s does not exist in the application", *golden)
+	}
+	return nil
+}
+
+// checkConfigGolden returns an error if the environment's golden environment is strict and
+// the keys of the configuration differ from its keys
+func (s *ConfigService) checkConfigGolden(env *models.Environment, config json.RawMessage) error {
+	if env == nil || !env.GoldenStrict {
+		return nil
+	}
+
+	golden, err := s.compareGolden(env, config)
+	if err != nil || golden == nil {
+		return err
+	}
+	if issues := goldenIssues(golden); len(issues) > 0 {
+		messages := make([]string, len(issues))
+		for i, issue := range issues {
+			messages[i] = issue.Message
+		}
+		return validationError("configuration does not match its golden environment: %s", strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// checkGolden verifies that the keys of the configuration match those of a strict golden
+// environment
+func (s *ConfigService) checkGolden(env *models.Environment, config json.RawMessage) []models.ValidationIssue {
+	if env == nil || !env.GoldenStrict {
+		return nil
+	}
+
+	golden, err := s.compareGolden(env, config)
+	if err != nil {
+		return []models.ValidationIssue{{Check: CheckGolden, Message: err.Error()}}
+	}
+	if golden == nil {
+		return nil
+	}
+	return goldenIssues(golden)
+}
+
+// goldenIssues describes every missing and extra key of a comparison with the golden environment
+func goldenIssues(golden *models.GoldenCheck) []models.ValidationIssue {
+	var issues []models.ValidationIssue
+	for _, key := range golden.MissingKeys {
+		issues = append(issues, models.ValidationIssue{
+			Check:   CheckGolden,
+			Path:    key,
+			Message: fmt.Sprintf("%s is missing, but golden environment %s has it", key, golden.Environment),
+		})
+	}
+	for _, key := range golden.ExtraKeys {
+		issues = append(issues, models.ValidationIssue{
+			Check:   CheckGolden,
+			Path:    key,
+			Message: fmt.Sprintf("%s is not in golden environment %s", key, golden.Environment),
+		})
+	}
+	return issues
+}
+
+// CompareKeys returns the keys of the golden configuration that the configuration does not
+// have and the keys of the configuration that the golden one does not have, both sorted.
+// Keys are the dot-notated paths of values; arrays and references are values as a whole.
+func CompareKeys(golden, config json.RawMessage) (missing, extra []string, err error) {
+	goldenKeys, err := configKeys(golden)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid golden configuration: %w", err)
+	}
+	keys, err := configKeys(config)
+	if err != nil {
+		return nil, nil, invalidError("invalid JSON configuration: %w", err)
+	}
+
+	missing, extra = []string{}, []string{}
+	for key := range goldenKeys {
+		if !keys[key] {
+			missing = append(missing, key)
+		}
+	}
+	for key := range keys {
+		if !goldenKeys[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra, nil
+}
+
+// configKeys returns the dot-notated paths of the values of a configuration. Objects are
+// descended into, except empty ones and references, which are values themselves.
+func configKeys(config json.RawMessage) (map[string]bool, error) {
+	var document interface{}
+	if err := json.Unmarshal(config, &document); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	var collect func(value interface{}, path string)
+	collect = func(value interface{}, path string) {
+		object, isObject := value.(map[string]interface{})
+		if isObject && len(object) > 0 {
+			if _, isReference, _ := parseReference(object); !isReference {
+				for key, child := range object {
+					if path != "" {
+						key = path + "." + key
+					}
+					collect(child, key)
+				}
+				return
+			}
+		}
+		if path != "" {
+			keys[path] = true
+		}
+	}
+	collect(document, "")
+	return keys, nil
+}
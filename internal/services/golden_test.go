@@ -0,0 +1,67 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareKeys(t *testing.T) {
+	golden := json.RawMessage(`{
+		"api_timeout": 30,
+		"database": {"host": "db.internal", "pool": {"size": 10}},
+		"features": ["search"],
+		"limits": {},
+		"base": {"$ref": "env://shared/api.base"}
+	}`)
+
+	t.Run("identical keys", func(t *testing.T) {
+		missing, extra, err := CompareKeys(golden, json.RawMessage(`{
+			"api_timeout": 60,
+			"database": {"host": "db.staging", "pool": {"size": 2}},
+			"features": [],
+			"limits": {},
+			"base": {"$ref": "env://shared/api.staging"}
+		}`))
+		require.NoError(t, err)
+		assert.Empty(t, missing)
+		assert.Empty(t, extra)
+	})
+
+	t.Run("missing and extra keys are nested paths", func(t *testing.T) {
+		missing, extra, err := CompareKeys(golden, json.RawMessage(`{
+			"api_timeout": 60,
+			"database": {"host": "db.staging", "port": 5432},
+			"features": ["search"],
+			"limits": {"rps": 100},
+			"base": "https://api.example.com",
+			"debug": true
+		}`))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"database.pool.size", "limits"}, missing)
+		assert.Equal(t, []string{"database.port", "debug", "limits.rps"}, extra)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		_, _, err := CompareKeys(golden, json.RawMessage(`{`))
+		assert.ErrorContains(t, err, "invalid JSON configuration")
+	})
+}
+
+func TestGoldenIssues(t *testing.T) {
+	issues := goldenIssues(&models.GoldenCheck{
+		Environment: "prod",
+		Strict:      true,
+		MissingKeys: []string{"database.host"},
+		ExtraKeys:   []string{"debug"},
+	})
+
+	assert.Equal(t, []models.ValidationIssue{
+		{Check: CheckGolden, Path: "database.host", Message: "database.host is missing, but golden environment prod has it"},
+		{Check: CheckGolden, Path: "debug", Message: "debug is not in golden environment prod"},
+	}, issues)
+}
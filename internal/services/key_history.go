@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"remote-config-system/internal/models"
+)
+
+// GetKeyHistory returns the values a dot-notated configuration key took across the stored
+// versions of an environment, oldest first. Consecutive versions in which the key kept its
+// value are collapsed into the first of them, and versions before the key first appeared
+// are left out. Values of secret keys are compared decrypted and reported masked; blob
+// values are summarized.
+func (s *ConfigService) GetKeyHistory(orgSlug, appSlug, envSlug, keyPath string) (*models.KeyHistoryResponse, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	// Secrets are encrypted whole at the top level, so their value is extracted there
+	// and the rest of the path is resolved once decrypted
+	path := strings.Split(keyPath, ".")
+	secret := slices.Contains(env.SecretKeys, path[0])
+	queryPath := path
+	if secret {
+		queryPath = path[:1]
+	}
+
+	values, err := s.repos.ConfigVersions.ListKeyValues(env.ID, queryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key history: %w", err)
+	}
+
+	response := &models.KeyHistoryResponse{
+		Organization: env.Application.Organization.Slug,
+		Application:  env.Application.Slug,
+		Environment:  env.Slug,
+		Key:          keyPath,
+		Changes:      []models.KeyHistoryEntry{},
+	}
+
+	var previous interface{}
+	previousPresent := false
+	for _, version := range values {
+		value, present, err := s.keyValue(version, secret, path[1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s in version %d: %w", keyPath, version.Version, err)
+		}
+
+		if present == previousPresent && reflect.DeepEqual(value, previous) {
+			continue
+		}
+		previous, previousPresent = value, present
+
+		entry := models.KeyHistoryEntry{
+			Version:   version.Version,
+			Present:   present,
+			ChangedAt: version.CreatedAt,
+			ChangedBy: version.CreatedBy,
+		}
+		if present {
+			switch {
+			case secret:
+				entry.Value = MaskedValue
+			case IsBlobPath(keyPath, env.BlobKeys):
+				entry.Value = summarizeBlob(value)
+			default:
+				entry.Value = value
+			}
+		}
+		response.Changes = append(response.Changes, entry)
+	}
+
+	return response, nil
+}
+
+// keyValue decodes the value of a key in one version. For secret keys, the extracted
+// top-level value is decrypted and the rest of the path resolved in it.
+func (s *ConfigService) keyValue(version models.KeyVersionValue, secret bool, rest []string) (interface{}, bool, error) {
+	if version.Value == nil {
+		return nil, false, nil
+	}
+
+	raw := version.Value
+	if sealed, encrypted := parseEncryptedValue(raw); secret && encrypted {
+		if s.encryptor == nil {
+			return nil, false, fmt.Errorf("configuration contains encrypted values but CONFIG_ENCRYPTION_KEY is not configured")
+		}
+		plaintext, err := s.encryptor.decrypt(sealed)
+		if err != nil {
+			return nil, false, err
+		}
+		raw = plaintext
+	}
+
+	value, err := decodeConfigValue(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	if !secret || len(rest) == 0 {
+		return value, true, nil
+	}
+
+	value, present := lookupKey(value, strings.Join(rest, "."))
+	return value, present, nil
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+
+	"remote-config-system/internal/models"
+)
+
+// labelPattern restricts label keys and values to words such as "tier" or "eu-west-1"
+var labelPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?$`)
+
+// ListLabels retrieves the labels of an environment
+func (s *ConfigService) ListLabels(orgSlug, appSlug, envSlug string) (*models.EnvironmentLabels, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	labels, err := s.repos.Labels.ListByEnvironment(env.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.EnvironmentLabels{Labels: labels}, nil
+}
+
+// SetLabel sets a label of an environment, creating it if it does not exist yet, and
+// returns the environment's labels. It reports whether the label was created.
+func (s *ConfigService) SetLabel(orgSlug, appSlug, envSlug, key string, req *models.SetLabelRequest) (*models.EnvironmentLabels, bool, error) {
+	if err := checkLabel("key", key); err != nil {
+		return nil, false, err
+	}
+	if err := checkLabel("value", req.Value); err != nil {
+		return nil, false, err
+	}
+
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, false, lookupError("environment not found", err)
+	}
+
+	created, err := s.repos.Labels.Set(env.ID, key, req.Value)
+	if err != nil {
+		return nil, false, err
+	}
+
+	labels, err := s.repos.Labels.ListByEnvironment(env.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	return &models.EnvironmentLabels{Labels: labels}, created, nil
+}
+
+// DeleteLabel removes a label from an environment
+func (s *ConfigService) DeleteLabel(orgSlug, appSlug, envSlug, key string) error {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return lookupError("environment not found", err)
+	}
+
+	return recordError(s.repos.Labels.Delete(env.ID, key))
+}
+
+// checkLabel checks a label key or value
+func checkLabel(part, text string) error {
+	if !labelPattern.MatchString(text) {
+		return invalidError("invalid label %s: %q (use up to 63 letters, digits, '.', '_' and '-', starting and ending with a letter or digit)", part, text)
+	}
+	return nil
+}
+
+// parseLabelSelector parses a label selector: comma-separated requirements that must all
+// be met, each key=value, key!=value or a bare key that the label must exist, e.g.
+// "tier=canary,region!=eu"
+func parseLabelSelector(selector string) ([]models.LabelRequirement, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, invalidError("invalid label selector: it is empty")
+	}
+
+	requirements := []models.LabelRequirement{}
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+
+		requirement := models.LabelRequirement{Key: part, Operator: models.LabelExists}
+		if key, value, ok := strings.Cut(part, "!="); ok {
+			requirement = models.LabelRequirement{Key: key, Operator: models.LabelNotEquals, Value: value}
+		} else if key, value, ok := strings.Cut(part, "="); ok {
+			requirement = models.LabelRequirement{Key: key, Operator: models.LabelEquals, Value: value}
+		}
+		requirement.Key = strings.TrimSpace(requirement.Key)
+		requirement.Value = strings.TrimSpace(requirement.Value)
+
+		if !labelPattern.MatchString(requirement.Key) {
+			return nil, invalidError("invalid label selector: %q has an invalid key", part)
+		}
+		if requirement.Operator != models.LabelExists && !labelPattern.MatchString(requirement.Value) {
+			return nil, invalidError("invalid label selector: %q has an invalid value", part)
+		}
+		requirements = append(requirements, requirement)
+	}
+	return requirements, nil
+}
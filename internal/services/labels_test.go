@@ -0,0 +1,40 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	t.Run("requirements", func(t *testing.T) {
+		requirements, err := parseLabelSelector("tier=canary, region!=eu-west-1,team")
+		require.NoError(t, err)
+		assert.Equal(t, []models.LabelRequirement{
+			{Key: "tier", Operator: models.LabelEquals, Value: "canary"},
+			{Key: "region", Operator: models.LabelNotEquals, Value: "eu-west-1"},
+			{Key: "team", Operator: models.LabelExists},
+		}, requirements)
+	})
+
+	t.Run("invalid selectors", func(t *testing.T) {
+		for _, selector := range []string{"", " ", "tier=", "=canary", "tier==canary", "tier=canary,", "tier=a b", "-tier"} {
+			_, err := parseLabelSelector(selector)
+			require.Error(t, err, selector)
+			assert.True(t, strings.HasPrefix(err.Error(), "invalid label selector"), err.Error())
+		}
+	})
+}
+
+func TestCheckLabel(t *testing.T) {
+	for _, text := range []string{"tier", "eu-west-1", "v2.3", "a", strings.Repeat("x", 63)} {
+		assert.NoError(t, checkLabel("key", text), text)
+	}
+	for _, text := range []string{"", "-tier", "tier-", "a/b", "a b", strings.Repeat("x", 64)} {
+		assert.Error(t, checkLabel("key", text), text)
+	}
+}
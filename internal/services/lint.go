@@ -0,0 +1,226 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"remote-config-system/internal/models"
+)
+
+// Lint check names
+const (
+	LintPlaceholder   = "placeholder"
+	LintLocalhost     = "localhost"
+	LintEmptyValue    = "empty_value"
+	LintCaseDuplicate = "case_duplicate"
+)
+
+// Lint warning severities
+const (
+	LintSeverityError   = "error"
+	LintSeverityWarning = "warning"
+)
+
+var (
+	// placeholderWordPattern matches placeholder words anywhere in a value, such as the
+	// password of "postgres://app:CHANGEME@db"
+	placeholderWordPattern = regexp.MustCompile(`(?i)(change[_-]?me|replace[_-]?me|fixme|your[_-][a-z0-9_-]+[_-]here)`)
+
+	// placeholderValuePattern matches values that are placeholders as a whole, such as
+	// "TODO", "xxx", "<api-key>" or an unexpanded "${API_KEY}"
+	placeholderValuePattern = regexp.MustCompile(`(?i)^(todo|tbd|placeholder|x{3,}|<[^<>]+>|\$\{[^{}]+\})$`)
+
+	// localhostPattern matches loopback and unspecified addresses
+	localhostPattern = regexp.MustCompile(`(?i)(^|[^a-z0-9.-])(localhost|127\.0\.0\.1|0\.0\.0\.0)($|[^a-z0-9.-])`)
+)
+
+// likelyRequiredWords are words of key names whose values are rarely meant to be empty
+var likelyRequiredWords = map[string]bool{
+	"url": true, "uri": true, "host": true, "hostname": true, "endpoint": true, "dsn": true,
+	"address": true, "addr": true, "password": true, "secret": true, "token": true,
+	"bucket": true, "region": true, "database": true,
+}
+
+// productionWords are words of environment slugs and names that mark production environments
+var productionWords = map[string]bool{"prod": true, "production": true, "prd": true, "live": true}
+
+// LintConfiguration checks a configuration for an environment for common mistakes without
+// saving it. Unlike validation, nothing is rejected: the warnings point at values that are
+// most likely wrong, such as placeholders or, in production, addresses of the local host.
+func (s *ConfigService) LintConfiguration(orgSlug, appSlug, envSlug string, config json.RawMessage) (*models.ConfigLintResponse, error) {
+	if !json.Valid(config) {
+		return nil, invalidError("invalid JSON configuration")
+	}
+	document, err := decodeConfigValue(config)
+	if err != nil {
+		return nil, invalidError("invalid JSON configuration: %w", err)
+	}
+
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	production := IsProductionEnvironment(env)
+	warnings := LintConfig(document, production)
+
+	response := &models.ConfigLintResponse{
+		Organization: orgSlug,
+		Application:  appSlug,
+		Environment:  env.Slug,
+		Production:   production,
+		Warnings:     warnings,
+	}
+	for _, warning := range warnings {
+		if warning.Severity == LintSeverityError {
+			response.Errors++
+		}
+	}
+	return response, nil
+}
+
+// IsProductionEnvironment reports whether an environment's slug or name has a word such as
+// "prod" or "production"
+func IsProductionEnvironment(env *models.Environment) bool {
+	for _, word := range splitWords(env.Slug + " " + env.Name) {
+		if productionWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+// LintConfig returns the warnings for a decoded configuration, with keys visited in sorted
+// order. Addresses of the local host are only reported in production environments.
+func LintConfig(document interface{}, production bool) []models.LintWarning {
+	warnings := []models.LintWarning{}
+
+	var walk func(path, key string, value interface{})
+	walk = func(path, key string, value interface{}) {
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(typed))
+			for childKey := range typed {
+				keys = append(keys, childKey)
+			}
+			sort.Strings(keys)
+
+			warnings = append(warnings, caseDuplicates(path, keys)...)
+			for _, childKey := range keys {
+				walk(joinPath(path, childKey), childKey, typed[childKey])
+			}
+		case []interface{}:
+			for i, item := range typed {
+				walk(joinPath(path, strconv.Itoa(i)), key, item)
+			}
+		case string:
+			warnings = append(warnings, lintString(path, key, typed, production)...)
+		}
+	}
+	walk("", "", document)
+
+	return warnings
+}
+
+// lintString returns the warnings for a string value at a path
+func lintString(path, key, value string, production bool) []models.LintWarning {
+	trimmed := strings.TrimSpace(value)
+
+	if trimmed == "" {
+		for _, word := range splitWords(key) {
+			if likelyRequiredWords[word] {
+				return []models.LintWarning{{
+					Check:    LintEmptyValue,
+					Severity: LintSeverityWarning,
+					Path:     path,
+					Message:  fmt.Sprintf("%s is empty, but its name suggests it needs a value", key),
+				}}
+			}
+		}
+		return nil
+	}
+
+	var warnings []models.LintWarning
+	if match := placeholderWordPattern.FindString(trimmed); match != "" || placeholderValuePattern.MatchString(trimmed) {
+		if match == "" {
+			match = trimmed
+		}
+		warnings = append(warnings, models.LintWarning{
+			Check:    LintPlaceholder,
+			Severity: LintSeverityError,
+			Path:     path,
+			Message:  fmt.Sprintf("value contains the placeholder %q", match),
+		})
+	}
+	if production {
+		if match := localhostPattern.FindStringSubmatch(trimmed); match != nil {
+			warnings = append(warnings, models.LintWarning{
+				Check:    LintLocalhost,
+				Severity: LintSeverityError,
+				Path:     path,
+				Message:  fmt.Sprintf("value points at %s in a production environment", match[2]),
+			})
+		}
+	}
+	return warnings
+}
+
+// caseDuplicates returns a warning for each group of sorted keys of an object that differ
+// only by case, such as "apiKey" and "ApiKey"
+func caseDuplicates(path string, keys []string) []models.LintWarning {
+	groups := make(map[string][]string)
+	var order []string
+	for _, key := range keys {
+		folded := strings.ToLower(key)
+		if _, seen := groups[folded]; !seen {
+			order = append(order, folded)
+		}
+		groups[folded] = append(groups[folded], key)
+	}
+
+	var warnings []models.LintWarning
+	for _, folded := range order {
+		if group := groups[folded]; len(group) > 1 {
+			warnings = append(warnings, models.LintWarning{
+				Check:    LintCaseDuplicate,
+				Severity: LintSeverityWarning,
+				Path:     path,
+				Message:  fmt.Sprintf("keys %q differ only by case", group),
+			})
+		}
+	}
+	return warnings
+}
+
+// splitWords splits a key or slug into lowercase words at separators and at changes from
+// lower to upper case, so that "dbHost", "db_host" and "DB-HOST" all give "db" and "host"
+func splitWords(s string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]) {
+			flush()
+		}
+		word = append(word, r)
+	}
+	flush()
+
+	return words
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lintDocument(t *testing.T, config string, production bool) []models.LintWarning {
+	t.Helper()
+	document, err := decodeConfigValue(json.RawMessage(config))
+	require.NoError(t, err)
+	return LintConfig(document, production)
+}
+
+func TestLintConfig(t *testing.T) {
+	t.Run("clean configuration", func(t *testing.T) {
+		warnings := lintDocument(t, `{"database_url": "postgres://db.internal/shop", "timeout": 30, "debug": false}`, true)
+		assert.NotNil(t, warnings)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("placeholders", func(t *testing.T) {
+		warnings := lintDocument(t, `{
+			"db": {"password": "CHANGEME", "url": "postgres://app:change_me@db/shop"},
+			"hosts": ["a.example.com", "TODO"],
+			"api_key": "<api-key>",
+			"token": "${TOKEN}",
+			"note": "todo list is empty"
+		}`, false)
+
+		paths := make(map[string]string)
+		for _, warning := range warnings {
+			assert.Equal(t, LintPlaceholder, warning.Check)
+			assert.Equal(t, LintSeverityError, warning.Severity)
+			paths[warning.Path] = warning.Message
+		}
+		assert.Len(t, paths, 5)
+		assert.Contains(t, paths["db.url"], `"change_me"`)
+		assert.Contains(t, paths, "hosts.1")
+		assert.Contains(t, paths, "api_key")
+		assert.Contains(t, paths, "token")
+	})
+
+	t.Run("localhost only in production", func(t *testing.T) {
+		config := `{"redis_url": "redis://localhost:6379", "bind": "0.0.0.0", "cdn": "https://localhost.example.com"}`
+		assert.Empty(t, lintDocument(t, config, false))
+
+		warnings := lintDocument(t, config, true)
+		require.Len(t, warnings, 2)
+		assert.Equal(t, "bind", warnings[0].Path)
+		assert.Equal(t, "redis_url", warnings[1].Path)
+		assert.Equal(t, LintLocalhost, warnings[1].Check)
+		assert.Contains(t, warnings[1].Message, "localhost")
+	})
+
+	t.Run("empty values of likely required keys", func(t *testing.T) {
+		warnings := lintDocument(t, `{"redisHost": " ", "api_token": "", "description": "", "DB-PASSWORD": ""}`, false)
+		require.Len(t, warnings, 3)
+		assert.Equal(t, "DB-PASSWORD", warnings[0].Path)
+		assert.Equal(t, "api_token", warnings[1].Path)
+		assert.Equal(t, "redisHost", warnings[2].Path)
+		for _, warning := range warnings {
+			assert.Equal(t, LintEmptyValue, warning.Check)
+			assert.Equal(t, LintSeverityWarning, warning.Severity)
+		}
+	})
+
+	t.Run("keys differing only by case", func(t *testing.T) {
+		warnings := lintDocument(t, `{"apiKey": "a", "ApiKey": "b", "nested": {"Timeout": 1, "timeout": 2, "TIMEOUT": 3}}`, false)
+		require.Len(t, warnings, 2)
+		assert.Equal(t, "", warnings[0].Path)
+		assert.Contains(t, warnings[0].Message, `"ApiKey" "apiKey"`)
+		assert.Equal(t, "nested", warnings[1].Path)
+		assert.Contains(t, warnings[1].Message, `"TIMEOUT" "Timeout" "timeout"`)
+	})
+}
+
+func TestIsProductionEnvironment(t *testing.T) {
+	for _, env := range []models.Environment{
+		{Slug: "prod"},
+		{Slug: "eu-production"},
+		{Slug: "blue", Name: "Live"},
+		{Slug: "prd-2"},
+	} {
+		assert.True(t, IsProductionEnvironment(&env), env.Slug)
+	}
+
+	for _, env := range []models.Environment{
+		{Slug: "staging", Name: "Staging"},
+		{Slug: "product-demo", Name: "Product demo"},
+		{Slug: "dev", Name: "Delivery"},
+	} {
+		assert.False(t, IsProductionEnvironment(&env), env.Slug)
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	assert.Equal(t, []string{"db", "host"}, splitWords("dbHost"))
+	assert.Equal(t, []string{"db", "host"}, splitWords("DB_HOST"))
+	assert.Equal(t, []string{"api", "url", "2"}, splitWords("api-url.2"))
+	assert.Nil(t, splitWords(""))
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"remote-config-system/internal/models"
+)
+
+// LockEnvironment locks an environment so that its configuration cannot be changed, e.g.
+// during incident response or a release freeze. Reads and streams are not affected.
+func (s *ConfigService) LockEnvironment(orgSlug, appSlug, envSlug string, req *models.LockEnvironmentRequest) (*models.Environment, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	if env.Locked {
+		return nil, conflictError("%s", lockMessage(env))
+	}
+
+	reason := req.Reason
+	if reason != nil {
+		trimmed := strings.TrimSpace(*reason)
+		reason = &trimmed
+		if trimmed == "" {
+			reason = nil
+		}
+	}
+
+	if err := s.repos.Environments.SetLock(env, true, reason, req.LockedBy); err != nil {
+		if strings.Contains(err.Error(), "already locked") {
+			return nil, conflictError("%w", err)
+		}
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// UnlockEnvironment lifts the lock of an environment
+func (s *ConfigService) UnlockEnvironment(orgSlug, appSlug, envSlug string) (*models.Environment, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	if !env.Locked {
+		return nil, conflictError("environment %s is not locked", env.Slug)
+	}
+
+	if err := s.repos.Environments.SetLock(env, false, nil, nil); err != nil {
+		if strings.Contains(err.Error(), "already unlocked") {
+			return nil, conflictError("environment %s is not locked", env.Slug)
+		}
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// checkUnlocked rejects a configuration change to a locked environment
+func checkUnlocked(env *models.Environment) error {
+	if env.Locked {
+		return lockedError("%s", lockMessage(env))
+	}
+	return nil
+}
+
+// checkApplicationUnlocked rejects a change of the application defaults while any
+// environment of the application is locked, since every environment inherits them
+func (s *ConfigService) checkApplicationUnlocked(app *models.Application) error {
+	envs, err := s.repos.Environments.ListAllByApplication(app.ID)
+	if err != nil {
+		return err
+	}
+	for i := range envs {
+		if err := checkUnlocked(&envs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockMessage describes the lock of an environment, with who locked it and why when known
+func lockMessage(env *models.Environment) string {
+	message := fmt.Sprintf("environment %s is locked", env.Slug)
+	if env.LockedBy != nil {
+		message += " by " + *env.LockedBy
+	}
+	if env.LockReason != nil {
+		message += ": " + *env.LockReason
+	}
+	return message
+}
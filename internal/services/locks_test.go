@@ -0,0 +1,25 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckUnlocked(t *testing.T) {
+	alice := "alice"
+	reason := "release freeze"
+
+	assert.NoError(t, checkUnlocked(&models.Environment{Slug: "prod"}))
+
+	err := checkUnlocked(&models.Environment{Slug: "prod", Locked: true, LockedBy: &alice, LockReason: &reason})
+	assert.True(t, errors.Is(err, ErrLocked))
+	assert.False(t, errors.Is(err, ErrConflict))
+	assert.EqualError(t, err, "environment prod is locked by alice: release freeze")
+
+	err = checkUnlocked(&models.Environment{Slug: "prod", Locked: true})
+	assert.EqualError(t, err, "environment prod is locked")
+}
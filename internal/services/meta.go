@@ -0,0 +1,52 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"remote-config-system/internal/models"
+)
+
+// GetConfigurationMeta describes who created a version of an environment's configuration
+// and how long ago
+func (s *ConfigService) GetConfigurationMeta(orgSlug, appSlug, envSlug string, version int) (*models.ConfigMeta, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	configVersion, err := s.repos.ConfigVersions.GetByVersion(env.ID, version)
+	if err != nil {
+		return nil, lookupError("configuration version not found", err)
+	}
+
+	return &models.ConfigMeta{
+		CreatedBy: configVersion.CreatedBy,
+		CreatedAt: configVersion.CreatedAt,
+		Age:       FormatAge(time.Since(configVersion.CreatedAt)),
+	}, nil
+}
+
+// FormatAge describes a duration in its largest whole unit, e.g. "3 days" or "1 hour".
+// Durations under a minute are "less than a minute".
+func FormatAge(age time.Duration) string {
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+
+	for _, unit := range units {
+		count := int(age / unit.size)
+		if count == 1 {
+			return "1 " + unit.name
+		}
+		if count > 1 {
+			return fmt.Sprintf("%d %ss", count, unit.name)
+		}
+	}
+	return "less than a minute"
+}
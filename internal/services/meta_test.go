@@ -0,0 +1,26 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAge(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                               "less than a minute",
+		59 * time.Second:                "less than a minute",
+		time.Minute:                     "1 minute",
+		45*time.Minute + 30*time.Second: "45 minutes",
+		time.Hour:                       "1 hour",
+		23*time.Hour + 59*time.Minute:   "23 hours",
+		24 * time.Hour:                  "1 day",
+		90*24*time.Hour + 5*time.Hour:   "90 days",
+		-5 * time.Minute:                "less than a minute",
+	}
+
+	for age, expected := range tests {
+		assert.Equal(t, expected, FormatAge(age), age.String())
+	}
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// NormalizeConfig returns the canonical form of a configuration document: object keys
+// sorted, no whitespace between tokens and characters such as < and & left unescaped.
+// Numbers keep the digits they were written with, so values like 1.0 and integers beyond
+// the precision of a float64 are stored exactly as sent. Documents that only differ in
+// formatting normalize to the same bytes.
+func NormalizeConfig(config json.RawMessage) (json.RawMessage, error) {
+	if err := json.Unmarshal(config, new(json.RawMessage)); err != nil {
+		return nil, invalidError("invalid JSON configuration: %w", err)
+	}
+	document, err := decodeConfigValue(config)
+	if err != nil {
+		return nil, invalidError("invalid JSON configuration: %w", err)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(document); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeConfig(t *testing.T) {
+	t.Run("sorts keys and drops whitespace", func(t *testing.T) {
+		config, err := NormalizeConfig(json.RawMessage("{\n  \"b\": [1, 2, {\"y\": 1, \"x\": 2}],\n  \"a\": {\"d\": null, \"c\": true}\n}\n"))
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":{"c":true,"d":null},"b":[1,2,{"x":2,"y":1}]}`, string(config))
+	})
+
+	t.Run("documents differing in formatting normalize alike", func(t *testing.T) {
+		first, err := NormalizeConfig(json.RawMessage(`{"timeout": 30, "feature": {"enabled": true}}`))
+		require.NoError(t, err)
+		second, err := NormalizeConfig(json.RawMessage("{\"feature\":{\n\t\"enabled\":true},\"timeout\":30}"))
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("numbers keep their digits", func(t *testing.T) {
+		config, err := NormalizeConfig(json.RawMessage(`{"ratio": 1.0, "big": 12345678901234567890, "exp": 1e3, "small": 0.1, "neg": -0}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"big":12345678901234567890,"exp":1e3,"neg":-0,"ratio":1.0,"small":0.1}`, string(config))
+	})
+
+	t.Run("strings are not escaped further", func(t *testing.T) {
+		config, err := NormalizeConfig(json.RawMessage(`{"html": "<b>a & b</b>", "text": "héllo é"}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"html":"<b>a & b</b>","text":"héllo é"}`, string(config))
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		for _, document := range []string{`{"a": 1`, `{"a": 1} {"b": 2}`, ``} {
+			_, err := NormalizeConfig(json.RawMessage(document))
+			assert.ErrorContains(t, err, "invalid JSON configuration", document)
+		}
+	})
+}
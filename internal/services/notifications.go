@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/notify"
+)
+
+// maxEmailRecipients limits how many addresses an environment's notifications go to
+const maxEmailRecipients = 20
+
+// NotificationEvents are the change log actions notifications can announce
+var NotificationEvents = []string{"create", "update", "promote", "rollback", "approve"}
+
+// SetNotifier configures how configuration changes are announced. A nil notifier
+// disables notifications.
+func (s *ConfigService) SetNotifier(notifier notify.Notifier) {
+	s.notifier = notifier
+}
+
+// GetNotificationSettings retrieves the notification settings of an environment
+func (s *ConfigService) GetNotificationSettings(orgSlug, appSlug, envSlug string) (*models.NotificationSettings, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	settings, err := s.repos.Notifications.GetByEnvironment(env.ID)
+	if err != nil {
+		return nil, lookupError("notification settings not found", err)
+	}
+
+	return settings, nil
+}
+
+// SetNotificationSettings stores the notification settings of an environment, replacing
+// any existing ones
+func (s *ConfigService) SetNotificationSettings(orgSlug, appSlug, envSlug string, req *models.UpdateNotificationSettingsRequest) (*models.NotificationSettings, error) {
+	settings := &models.NotificationSettings{
+		SlackWebhookURL: req.SlackWebhookURL,
+		EmailRecipients: req.EmailRecipients,
+		Events:          req.Events,
+	}
+	if err := s.checkNotificationSettings(settings); err != nil {
+		return nil, err
+	}
+
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	settings.EnvID = env.ID
+	if err := s.repos.Notifications.Upsert(settings); err != nil {
+		return nil, fmt.Errorf("failed to save notification settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// DeleteNotificationSettings removes the notification settings of an environment
+func (s *ConfigService) DeleteNotificationSettings(orgSlug, appSlug, envSlug string) error {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return lookupError("environment not found", err)
+	}
+
+	deleted, err := s.repos.Notifications.Delete(env.ID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return notFoundError("notification settings not found for environment: %s", envSlug)
+	}
+
+	return nil
+}
+
+// checkNotificationSettings validates notification settings and fills in the default
+// events. Destinations are checked by the notifier, if there is one.
+func (s *ConfigService) checkNotificationSettings(settings *models.NotificationSettings) error {
+	if settings.SlackWebhookURL == "" && len(settings.EmailRecipients) == 0 {
+		return invalidError("invalid notification settings: set slack_webhook_url or email_recipients")
+	}
+	if len(settings.EmailRecipients) > maxEmailRecipients {
+		return invalidError("invalid notification settings: at most %d email recipients are allowed", maxEmailRecipients)
+	}
+	if settings.EmailRecipients == nil {
+		settings.EmailRecipients = []string{}
+	}
+
+	if len(settings.Events) == 0 {
+		settings.Events = append([]string(nil), NotificationEvents...)
+	}
+	for _, event := range settings.Events {
+		if !slices.Contains(NotificationEvents, event) {
+			return invalidError("invalid notification settings: unknown event %q, use update, promote, rollback or approve", event)
+		}
+	}
+
+	if s.notifier != nil {
+		if err := s.notifier.Check(settings); err != nil {
+			return invalidError("invalid notification settings: %w", err)
+		}
+	}
+	return nil
+}
+
+// notifyChange announces a logged configuration change in the background, if the
+// environment has notification settings for its action. The settings are read before
+// returning, so that they are the ones in effect when the change was made. Failures are
+// only logged.
+func (s *ConfigService) notifyChange(env *models.Environment, change *models.ConfigChange) {
+	if s.notifier == nil {
+		return
+	}
+
+	settings, err := s.repos.Notifications.GetByEnvironment(env.ID)
+	if err != nil || !slices.Contains(settings.Events, change.Action) {
+		return
+	}
+
+	logged := *change
+	go func() {
+		message := s.buildNotification(env, &logged)
+		if err := s.notifier.Notify(context.Background(), settings, message); err != nil {
+			log.Printf("Failed to send notification for %s/%s/%s version %d: %v",
+				message.Organization, message.Application, message.Environment, message.VersionTo, err)
+		}
+	}()
+}
+
+// buildNotification describes a change, with the differences between the versions it
+// moved between. Secret values are masked, although messages only name the changed paths.
+func (s *ConfigService) buildNotification(env *models.Environment, change *models.ConfigChange) *notify.Message {
+	message := &notify.Message{
+		Organization: env.Application.Organization.Slug,
+		Application:  env.Application.Slug,
+		Environment:  env.Slug,
+		Action:       change.Action,
+		VersionFrom:  change.VersionFrom,
+		VersionTo:    change.VersionTo,
+		ChangedAt:    change.CreatedAt,
+	}
+	if change.CreatedBy != nil {
+		message.ChangedBy = *change.CreatedBy
+	}
+	if change.ApprovedBy != nil {
+		message.ApprovedBy = *change.ApprovedBy
+	}
+	if change.SourceEnv != nil {
+		message.SourceEnv = *change.SourceEnv
+	}
+	if change.Message != nil {
+		message.Note = *change.Message
+	}
+	if message.ChangedAt.IsZero() {
+		message.ChangedAt = time.Now()
+	}
+
+	diff, err := s.versionDiff(env, change.VersionFrom, change.VersionTo)
+	if err != nil {
+		log.Printf("Failed to compute notification diff: %v", err)
+		return message
+	}
+	message.Diff = diff
+	return message
+}
+
+// versionDiff computes the differences between two configuration versions of an
+// environment. A nil from version is compared as an empty configuration.
+func (s *ConfigService) versionDiff(env *models.Environment, from *int, to int) (*models.ConfigDiff, error) {
+	fromConfig := []byte("{}")
+	var secretKeys []string
+	if from != nil {
+		fromVersion, err := s.repos.ConfigVersions.GetByVersion(env.ID, *from)
+		if err != nil {
+			return nil, err
+		}
+		secretKeys = EncryptedKeys(fromVersion.ConfigJSON)
+		if fromConfig, err = s.encryptor.DecryptSecrets(fromVersion.ConfigJSON); err != nil {
+			return nil, err
+		}
+	}
+
+	toVersion, err := s.repos.ConfigVersions.GetByVersion(env.ID, to)
+	if err != nil {
+		return nil, err
+	}
+	secretKeys = append(secretKeys, EncryptedKeys(toVersion.ConfigJSON)...)
+	toConfig, err := s.encryptor.DecryptSecrets(toVersion.ConfigJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := DiffConfigs(fromConfig, toConfig)
+	if err != nil {
+		return nil, err
+	}
+	SummarizeBlobDiff(diff, env.BlobKeys)
+	MaskSecretDiff(diff, append(secretKeys, env.SecretKeys...))
+	return diff, nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"remote-config-system/internal/models"
+	"remote-config-system/internal/notify"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rejectingNotifier rejects every destination
+type rejectingNotifier struct{}
+
+func (rejectingNotifier) Check(settings *models.NotificationSettings) error {
+	return errors.New("slack_webhook_url host example.com is not allowed")
+}
+
+func (rejectingNotifier) Notify(ctx context.Context, settings *models.NotificationSettings, message *notify.Message) error {
+	return nil
+}
+
+func TestCheckNotificationSettings(t *testing.T) {
+	service := &ConfigService{}
+	slack := "https://hooks.slack.com/services/T/B/X"
+
+	t.Run("events default to every supported action", func(t *testing.T) {
+		settings := &models.NotificationSettings{SlackWebhookURL: slack}
+		assert.NoError(t, service.checkNotificationSettings(settings))
+		assert.Equal(t, NotificationEvents, settings.Events)
+		assert.Equal(t, []string{}, settings.EmailRecipients)
+	})
+
+	t.Run("selected events", func(t *testing.T) {
+		settings := &models.NotificationSettings{EmailRecipients: []string{"ops@example.com"}, Events: []string{"rollback"}}
+		assert.NoError(t, service.checkNotificationSettings(settings))
+		assert.Equal(t, []string{"rollback"}, settings.Events)
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		tooMany := make([]string, maxEmailRecipients+1)
+		for i := range tooMany {
+			tooMany[i] = "ops@example.com"
+		}
+
+		for name, settings := range map[string]*models.NotificationSettings{
+			"no destination":      {},
+			"unknown event":       {SlackWebhookURL: slack, Events: []string{"tag_create"}},
+			"too many recipients": {EmailRecipients: tooMany},
+		} {
+			err := service.checkNotificationSettings(settings)
+			assert.ErrorContains(t, err, "invalid notification settings", name)
+		}
+	})
+
+	t.Run("destinations are checked by the notifier", func(t *testing.T) {
+		service := &ConfigService{notifier: rejectingNotifier{}}
+		err := service.checkNotificationSettings(&models.NotificationSettings{SlackWebhookURL: "https://example.com/hook"})
+		assert.EqualError(t, err, "invalid notification settings: slack_webhook_url host example.com is not allowed")
+	})
+}
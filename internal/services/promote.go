@@ -0,0 +1,105 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"remote-config-system/internal/models"
+)
+
+// promotionSource identifies the environment version a promoted configuration was copied from
+type promotionSource struct {
+	envSlug string
+	version int
+}
+
+// PromoteConfiguration copies the active configuration of another environment of the same
+// application into an environment as a new version. The copy goes through the checks of an
+// update, so environments that require approval get a proposed version. When keys are
+// given, only those top-level keys are copied and the target keeps the rest of its
+// configuration.
+func (s *ConfigService) PromoteConfiguration(orgSlug, appSlug, envSlug string, req *models.PromoteConfigRequest, dryRun bool) (*models.ConfigResponse, error) {
+	if req.Source == envSlug {
+		return nil, invalidError("invalid promotion: source and target are both environment %s", envSlug)
+	}
+
+	// Get the target environment
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	sourceEnv, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, req.Source)
+	if err != nil {
+		return nil, lookupError("source environment not found", err)
+	}
+
+	sourceVersion, err := s.repos.ConfigVersions.GetActiveByEnvironment(sourceEnv.ID)
+	if err != nil {
+		return nil, lookupError("no active configuration found in source environment", err)
+	}
+
+	// Secrets are re-encrypted with the target's secret keys when the version is stored
+	config, err := s.encryptor.DecryptSecrets(sourceVersion.ConfigJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt source configuration: %w", err)
+	}
+
+	if len(req.Keys) > 0 {
+		targetConfig := json.RawMessage(`{}`)
+		if activeConfig, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID); err == nil {
+			targetConfig, err = s.encryptor.DecryptSecrets(activeConfig.ConfigJSON)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt active configuration: %w", err)
+			}
+		}
+
+		config, err = promoteKeys(config, targetConfig, req.Keys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkConfigSize(config); err != nil {
+		return nil, err
+	}
+
+	update := &models.CreateConfigRequest{
+		Config:     config,
+		CreatedBy:  req.CreatedBy,
+		Message:    req.Message,
+		AdminToken: req.AdminToken,
+	}
+	source := &promotionSource{envSlug: sourceEnv.Slug, version: sourceVersion.Version}
+
+	return s.updateEnvironmentConfig(env, update, dryRun, source)
+}
+
+// promoteKeys returns the target configuration with the given top-level keys copied from
+// the source configuration
+func promoteKeys(source, target json.RawMessage, keys []string) (json.RawMessage, error) {
+	var sourceValues, targetValues map[string]json.RawMessage
+	if err := json.Unmarshal(source, &sourceValues); err != nil {
+		return nil, invalidError("invalid promotion: keys can only be promoted from an object configuration")
+	}
+	if err := json.Unmarshal(target, &targetValues); err != nil {
+		return nil, invalidError("invalid promotion: keys can only be promoted into an object configuration")
+	}
+	if targetValues == nil {
+		targetValues = make(map[string]json.RawMessage, len(keys))
+	}
+
+	for _, key := range keys {
+		value, ok := sourceValues[key]
+		if !ok {
+			return nil, invalidError("invalid promotion: key '%s' is not in the source configuration", key)
+		}
+		targetValues[key] = value
+	}
+
+	promoted, err := json.Marshal(targetValues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode promoted configuration: %w", err)
+	}
+	return promoted, nil
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromoteKeys(t *testing.T) {
+	source := json.RawMessage(`{"flags": {"checkout": true}, "limits": [1, 2], "db_host": "staging-db"}`)
+
+	t.Run("copies the keys and keeps the rest of the target", func(t *testing.T) {
+		promoted, err := promoteKeys(source, json.RawMessage(`{"flags": {"checkout": false, "search": true}, "db_host": "prod-db"}`), []string{"flags", "limits"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"flags": {"checkout": true}, "limits": [1, 2], "db_host": "prod-db"}`, string(promoted))
+	})
+
+	t.Run("target without a configuration", func(t *testing.T) {
+		promoted, err := promoteKeys(source, json.RawMessage(`null`), []string{"db_host"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"db_host": "staging-db"}`, string(promoted))
+	})
+
+	t.Run("key missing from the source", func(t *testing.T) {
+		_, err := promoteKeys(source, json.RawMessage(`{}`), []string{"flags", "timeouts"})
+		assert.EqualError(t, err, "invalid promotion: key 'timeouts' is not in the source configuration")
+	})
+
+	t.Run("configurations that are not objects", func(t *testing.T) {
+		_, err := promoteKeys(json.RawMessage(`[1, 2]`), json.RawMessage(`{}`), []string{"flags"})
+		assert.ErrorContains(t, err, "invalid promotion")
+
+		_, err = promoteKeys(source, json.RawMessage(`"text"`), []string{"flags"})
+		assert.ErrorContains(t, err, "invalid promotion")
+	})
+}
+
+func TestConfigService_PromoteConfiguration_SameEnvironment(t *testing.T) {
+	service := &ConfigService{config: &Config{}}
+
+	_, err := service.PromoteConfiguration("org", "app", "prod", &models.PromoteConfigRequest{Source: "prod"}, false)
+	assert.EqualError(t, err, "invalid promotion: source and target are both environment prod")
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"remote-config-system/internal/cache"
+	"remote-config-system/internal/models"
+)
+
+// CheckOrganizationReadQuota returns a *QuotaExceededError, which matches
+// ErrQuotaExceeded, if the organization has used up its monthly read quota. Reads are
+// not limited without a quota or without an available cache, which counts them.
+func (s *ConfigService) CheckOrganizationReadQuota(orgSlug string) error {
+	quota := int64(s.config.OrgMonthlyReadQuota)
+	if quota <= 0 || !s.cacheAvailable() {
+		return nil
+	}
+
+	now := time.Now()
+	reads, _, err := s.cache.GetOrgReads(orgSlug, now)
+	if err != nil {
+		return err
+	}
+	if reads >= quota {
+		return &QuotaExceededError{
+			Organization: orgSlug,
+			Quota:        quota,
+			ResetsAt:     cache.OrgReadsMonth(now).AddDate(0, 1, 0),
+		}
+	}
+	return nil
+}
+
+// RecordOrganizationRead counts a configuration read of an organization towards its
+// monthly read quota. Reads are counted in Redis and stored by the usage flusher. The
+// first read of a month in Redis, e.g. after Redis lost its data, resumes from the
+// stored count of the month.
+func (s *ConfigService) RecordOrganizationRead(orgSlug string) error {
+	if !s.cacheAvailable() {
+		return nil
+	}
+
+	now := time.Now()
+	reads, err := s.cache.RecordOrgRead(orgSlug, now)
+	if err != nil || reads != 1 || s.repos == nil {
+		return err
+	}
+
+	org, err := s.repos.Organizations.GetBySlug(orgSlug)
+	if err != nil {
+		// Reads of organizations that do not exist are not stored by the flusher either
+		return nil
+	}
+	stored, err := s.repos.OrgReads.Get(org.ID, cache.OrgReadsMonth(now))
+	if err != nil || stored == 0 {
+		return err
+	}
+	_, err = s.cache.AddOrgReads(orgSlug, now, stored)
+	return err
+}
+
+// FlushOrganizationReads stores the organization read counts changed in Redis since the
+// last flush and returns the number of counts stored. Counts that cannot be stored stay
+// in Redis and are stored by the next flush.
+func (s *ConfigService) FlushOrganizationReads() (int, error) {
+	if s.cache == nil {
+		return 0, nil
+	}
+
+	counts, err := s.cache.DrainOrgReads()
+	if err != nil {
+		return 0, err
+	}
+
+	reads := make([]models.OrganizationReads, len(counts))
+	for i, count := range counts {
+		reads[i] = models.OrganizationReads{
+			Organization: count.Organization,
+			Month:        count.Month,
+			Reads:        count.Reads,
+		}
+	}
+
+	if len(reads) > 0 {
+		if err := s.repos.OrgReads.Store(reads); err != nil {
+			return 0, err
+		}
+	}
+	if err := s.cache.ClearDrainedOrgReads(); err != nil {
+		// The counts would be stored again by the next flush
+		return 0, err
+	}
+
+	return len(reads), nil
+}
+
+// GetOrganizationQuota reports an organization's configuration reads of the current month
+// against its monthly read quota. Reads counted since the last flush are included while
+// Redis is available.
+func (s *ConfigService) GetOrganizationQuota(orgSlug string) (*models.OrganizationQuota, error) {
+	org, err := s.repos.Organizations.GetBySlug(orgSlug)
+	if err != nil {
+		return nil, lookupError("organization not found", err)
+	}
+
+	now := time.Now()
+	month := cache.OrgReadsMonth(now)
+	reads, err := s.repos.OrgReads.Get(org.ID, month)
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := s.cacheAvailable()
+	if tracked {
+		counted, _, err := s.cache.GetOrgReads(orgSlug, now)
+		if err != nil {
+			log.Printf("Failed to get the reads of organization %s counted in Redis: %v", orgSlug, err)
+		}
+		reads = max(reads, counted)
+	}
+
+	quota := newOrganizationQuota(orgSlug, month, reads, int64(s.config.OrgMonthlyReadQuota))
+	quota.Tracked = tracked
+	return quota, nil
+}
+
+// newOrganizationQuota builds the quota report of an organization's reads in a month;
+// a quota of 0 or less is unlimited
+func newOrganizationQuota(orgSlug string, month time.Time, reads, quota int64) *models.OrganizationQuota {
+	report := &models.OrganizationQuota{
+		Organization: orgSlug,
+		Month:        month.Format("2006-01"),
+		Reads:        reads,
+		ResetsAt:     month.AddDate(0, 1, 0),
+	}
+	if quota > 0 {
+		remaining := max(quota-reads, 0)
+		report.Quota = &quota
+		report.Remaining = &remaining
+		report.Exceeded = reads >= quota
+	}
+	return report
+}
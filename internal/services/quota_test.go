@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOrganizationQuota(t *testing.T) {
+	month := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("within the quota", func(t *testing.T) {
+		quota := newOrganizationQuota("acme", month, 400, 1000)
+
+		assert.Equal(t, "2026-12", quota.Month)
+		assert.Equal(t, int64(400), quota.Reads)
+		require.NotNil(t, quota.Quota)
+		assert.Equal(t, int64(1000), *quota.Quota)
+		require.NotNil(t, quota.Remaining)
+		assert.Equal(t, int64(600), *quota.Remaining)
+		assert.False(t, quota.Exceeded)
+		assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), quota.ResetsAt)
+	})
+
+	t.Run("exceeded", func(t *testing.T) {
+		quota := newOrganizationQuota("acme", month, 1200, 1000)
+
+		assert.Zero(t, *quota.Remaining)
+		assert.True(t, quota.Exceeded)
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		quota := newOrganizationQuota("acme", month, 1200, 0)
+
+		assert.Nil(t, quota.Quota)
+		assert.Nil(t, quota.Remaining)
+		assert.False(t, quota.Exceeded)
+	})
+}
+
+func TestQuotaExceededError(t *testing.T) {
+	err := error(&QuotaExceededError{Organization: "acme", Quota: 1000, ResetsAt: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+	assert.ErrorIs(t, err, ErrQuotaExceeded)
+	assert.NotErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, "monthly read quota of 1000 reads exceeded for organization 'acme'; reads resume at 2027-01-01T00:00:00Z", err.Error())
+}
@@ -0,0 +1,452 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"remote-config-system/internal/cache"
+	"remote-config-system/internal/models"
+)
+
+// A configuration value of the form {"$ref": "env://<env>/<key path>"} references a key of
+// another environment of the same application. The key path is dot-separated; array
+// elements are addressed by index. References are resolved when configurations are read,
+// so a value shared by several environments is defined once.
+const (
+	referenceField  = "$ref"
+	referenceScheme = "env://"
+
+	// DefaultMaxReferenceDepth is the default number of references that may be followed
+	// to resolve a single value
+	DefaultMaxReferenceDepth = 5
+)
+
+// CheckReferences is the name of the validation check for configuration references
+const CheckReferences = "references"
+
+// reference is a parsed configuration reference
+type reference struct {
+	env  string
+	path string
+}
+
+func (r reference) String() string {
+	return referenceScheme + r.env + "/" + r.path
+}
+
+// parseReference returns the reference an object stands for. Objects with other keys, or
+// whose $ref is not an env:// URI (e.g. JSON Schema references), are plain values.
+func parseReference(object map[string]interface{}) (reference, bool, error) {
+	if len(object) != 1 {
+		return reference{}, false, nil
+	}
+	uri, ok := object[referenceField].(string)
+	if !ok || !strings.HasPrefix(uri, referenceScheme) {
+		return reference{}, false, nil
+	}
+
+	env, path, _ := strings.Cut(strings.TrimPrefix(uri, referenceScheme), "/")
+	if env == "" || path == "" {
+		return reference{}, true, brokenReferenceError("broken reference %s: must name an environment and a key, as %s<env>/<key>", uri, referenceScheme)
+	}
+	return reference{env: env, path: path}, true, nil
+}
+
+// referenceTarget is the configuration of an environment that references resolve against.
+// Secret values are still encrypted.
+type referenceTarget struct {
+	version int
+	config  interface{}
+}
+
+// referenceLoader loads the configuration, merged with the application defaults, of an
+// environment of the application whose references are resolved
+type referenceLoader func(envSlug string) (*referenceTarget, error)
+
+// referenceResolver resolves the references of one configuration
+type referenceResolver struct {
+	load     referenceLoader
+	maxDepth int
+	env      string // Environment whose configuration is resolved
+	followed int
+	targets  map[string]*referenceTarget
+	sources  map[string]int // Version of each other environment values were taken from
+}
+
+func newReferenceResolver(load referenceLoader, maxDepth int) *referenceResolver {
+	return &referenceResolver{
+		load:     load,
+		maxDepth: maxDepth,
+		targets:  make(map[string]*referenceTarget),
+		sources:  make(map[string]int),
+	}
+}
+
+// resolve replaces every reference in the configuration of an environment with the value
+// it references. References to the environment itself resolve against the configuration
+// being resolved. It returns the configuration unchanged if it has no references.
+func (r *referenceResolver) resolve(envSlug string, version int, config json.RawMessage) (json.RawMessage, error) {
+	if !bytes.Contains(config, []byte(`"`+referenceField+`"`)) {
+		return config, nil
+	}
+
+	document, err := decodeReferenceTarget(config)
+	if err != nil {
+		return nil, err
+	}
+	r.env = envSlug
+	r.targets[envSlug] = &referenceTarget{version: version, config: document}
+
+	resolved, err := r.walk(document, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if r.followed == 0 {
+		return config, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(resolved); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// walk returns a copy of a value with its references resolved. chain holds the
+// references followed to reach the value, and depth how deeply the value is nested in
+// the configuration; values of top-level keys have depth 1.
+func (r *referenceResolver) walk(value interface{}, chain []string, depth int) (interface{}, error) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		ref, ok, err := parseReference(typed)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return r.follow(ref, chain, depth)
+		}
+
+		resolved := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			if resolved[key], err = r.walk(item, chain, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(typed))
+		for i, item := range typed {
+			var err error
+			if resolved[i], err = r.walk(item, chain, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
+
+// follow resolves a single reference at the given depth, including any references in the
+// value it points at
+func (r *referenceResolver) follow(ref reference, chain []string, depth int) (interface{}, error) {
+	uri := ref.String()
+	if depth == 0 {
+		return nil, brokenReferenceError("broken reference %s: a configuration cannot itself be a reference", uri)
+	}
+	if slices.Contains(chain, uri) {
+		return nil, brokenReferenceError("broken reference %s: cycle %s", uri, strings.Join(append(chain, uri), " -> "))
+	}
+	if len(chain) >= r.maxDepth {
+		return nil, brokenReferenceError("broken reference %s: more than %d references deep", uri, r.maxDepth)
+	}
+
+	target, ok := r.targets[ref.env]
+	if !ok {
+		var err error
+		if target, err = r.load(ref.env); err != nil {
+			return nil, brokenReferenceError("broken reference %s: %w", uri, err)
+		}
+		r.targets[ref.env] = target
+	}
+
+	value, ok := lookupReference(target.config, ref.path)
+	if !ok {
+		return nil, brokenReferenceError("broken reference %s: key %s not found in environment %s", uri, ref.path, ref.env)
+	}
+
+	// Only top-level values are decrypted, so secrets cannot be moved deeper
+	if depth != 1 && isEncryptedValue(value) {
+		return nil, brokenReferenceError("broken reference %s: secret values can only be referenced by top-level keys", uri)
+	}
+
+	r.followed++
+	if ref.env != r.env {
+		r.sources[ref.env] = target.version
+	}
+
+	return r.walk(value, append(slices.Clip(chain), uri), depth)
+}
+
+// isEncryptedValue reports whether a decoded value is a stored encrypted value
+func isEncryptedValue(value interface{}) bool {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, encrypted := object[encryptedValueField]
+	return encrypted
+}
+
+// referenceSources lists the environment versions the resolved values were taken from
+func (r *referenceResolver) referenceSources() []models.ConfigReferenceSource {
+	if len(r.sources) == 0 {
+		return nil
+	}
+
+	sources := make([]models.ConfigReferenceSource, 0, len(r.sources))
+	for env, version := range r.sources {
+		sources = append(sources, models.ConfigReferenceSource{Environment: env, Version: version})
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Environment < sources[j].Environment })
+	return sources
+}
+
+// lookupReference returns the value at a dot-separated path of a decoded configuration
+func lookupReference(document interface{}, path string) (interface{}, bool) {
+	value := document
+	for _, part := range strings.Split(path, ".") {
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			next, ok := typed[part]
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(typed) {
+				return nil, false
+			}
+			value = typed[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// decodeReferenceTarget decodes a configuration, keeping numbers as they were written
+func decodeReferenceTarget(config json.RawMessage) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(config))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+	return document, nil
+}
+
+// maxReferenceDepth returns the number of references that may be followed for one value
+func (s *ConfigService) maxReferenceDepth() int {
+	if s.config == nil || s.config.MaxReferenceDepth <= 0 {
+		return DefaultMaxReferenceDepth
+	}
+	return s.config.MaxReferenceDepth
+}
+
+// referenceLoader returns a loader of the active configurations of an application's
+// environments
+func (s *ConfigService) referenceLoader(app *models.Application) referenceLoader {
+	return func(envSlug string) (*referenceTarget, error) {
+		env, err := s.repos.Environments.GetBySlug(app.Organization.Slug, app.Slug, envSlug)
+		if err != nil {
+			return nil, fmt.Errorf("environment %s not found", envSlug)
+		}
+
+		active, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID)
+		if err != nil {
+			return nil, fmt.Errorf("environment %s has no active configuration", envSlug)
+		}
+
+		config, _, err := s.effectiveConfig(app.ID, active.ConfigJSON)
+		if err != nil {
+			return nil, err
+		}
+
+		document, err := decodeReferenceTarget(config)
+		if err != nil {
+			return nil, err
+		}
+		return &referenceTarget{version: active.Version, config: document}, nil
+	}
+}
+
+// activeReferenceLoader returns a loader of the environments of one application among
+// already loaded active configurations
+func activeReferenceLoader(actives map[string]*models.ActiveConfig) referenceLoader {
+	return func(envSlug string) (*referenceTarget, error) {
+		active, ok := actives[envSlug]
+		if !ok {
+			return nil, fmt.Errorf("environment %s has no active configuration", envSlug)
+		}
+
+		config := active.ConfigJSON
+		if active.DefaultsJSON != nil {
+			merged, err := MergeConfig(active.DefaultsJSON, config)
+			if err != nil {
+				return nil, fmt.Errorf("failed to apply application defaults: %w", err)
+			}
+			config = merged
+		}
+
+		document, err := decodeReferenceTarget(config)
+		if err != nil {
+			return nil, err
+		}
+		return &referenceTarget{version: active.Version, config: document}, nil
+	}
+}
+
+// resolveReferences replaces the references in the configuration of a response with the
+// values they reference, and records the environment versions the values came from. Nil
+// responses are left alone.
+func (s *ConfigService) resolveReferences(response *models.ConfigResponse, load referenceLoader) error {
+	if response == nil {
+		return nil
+	}
+
+	resolver := newReferenceResolver(load, s.maxReferenceDepth())
+	config, err := resolver.resolve(response.Environment, response.Version, response.Config)
+	if err != nil {
+		return err
+	}
+
+	response.Config = config
+	response.References = resolver.referenceSources()
+	return nil
+}
+
+// checkConfigReferences returns an error if a proposed configuration of an environment has
+// a reference that cannot be resolved
+func (s *ConfigService) checkConfigReferences(env *models.Environment, config json.RawMessage) error {
+	if env == nil || env.Application == nil || s.repos == nil {
+		return nil
+	}
+
+	effective, _, err := s.effectiveConfig(env.AppID, config)
+	if err != nil {
+		return err
+	}
+
+	resolver := newReferenceResolver(s.referenceLoader(env.Application), s.maxReferenceDepth())
+	_, err = resolver.resolve(env.Slug, 0, effective)
+	return err
+}
+
+// checkReferences verifies that every reference of the configuration can be resolved
+func (s *ConfigService) checkReferences(env *models.Environment, config json.RawMessage) []models.ValidationIssue {
+	if err := s.checkConfigReferences(env, config); err != nil {
+		return []models.ValidationIssue{{Check: CheckReferences, Message: err.Error()}}
+	}
+	return nil
+}
+
+// referencedEnvironments returns the environments a configuration references
+func referencedEnvironments(config json.RawMessage) []string {
+	if !bytes.Contains(config, []byte(`"`+referenceField+`"`)) {
+		return nil
+	}
+	document, err := decodeReferenceTarget(config)
+	if err != nil {
+		return nil
+	}
+
+	var envs []string
+	var collect func(value interface{})
+	collect = func(value interface{}) {
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			if ref, ok, err := parseReference(typed); ok {
+				if err == nil && !slices.Contains(envs, ref.env) {
+					envs = append(envs, ref.env)
+				}
+				return
+			}
+			for _, item := range typed {
+				collect(item)
+			}
+		case []interface{}:
+			for _, item := range typed {
+				collect(item)
+			}
+		}
+	}
+	collect(document)
+	return envs
+}
+
+// referencingEnvironments returns the environments among the active configurations of an
+// application whose served configuration depends on an environment: those that reference
+// it directly or through other environments. Environments with a rollout in progress are
+// included too, since the version being rolled out is not among the active ones.
+func referencingEnvironments(actives []models.ActiveConfig, envSlug string) []string {
+	// Environments referenced by each environment, including through the defaults
+	referencedBy := make(map[string][]string)
+	var defaultsRefs []string
+	if len(actives) > 0 {
+		defaultsRefs = referencedEnvironments(actives[0].DefaultsJSON)
+	}
+	for _, active := range actives {
+		refs := append(referencedEnvironments(active.ConfigJSON), defaultsRefs...)
+		for _, ref := range refs {
+			referencedBy[ref] = append(referencedBy[ref], active.Environment)
+		}
+	}
+
+	dependents := []string{}
+	queue := []string{envSlug}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range referencedBy[current] {
+			if dependent != envSlug && !slices.Contains(dependents, dependent) {
+				dependents = append(dependents, dependent)
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	for _, active := range actives {
+		if active.HasRollout && active.Environment != envSlug && !slices.Contains(dependents, active.Environment) {
+			dependents = append(dependents, active.Environment)
+		}
+	}
+	return dependents
+}
+
+// invalidateReferencingEnvironments drops the cached configurations of the environments
+// that depend on an environment through references
+func (s *ConfigService) invalidateReferencingEnvironments(app *models.Application, envSlug string) {
+	actives, err := s.repos.ConfigVersions.ListActive(app.Organization.Slug, app.Slug)
+	if err != nil {
+		log.Printf("Failed to invalidate referencing environments: %v", err)
+		return
+	}
+
+	for _, dependent := range referencingEnvironments(actives, envSlug) {
+		if err := s.cache.DeleteConfig(cache.GenerateConfigKey(app.Organization.Slug, app.Slug, dependent)); err != nil {
+			log.Printf("Failed to invalidate config cache: %v", err)
+		}
+		s.invalidateAPIKeyCache(app.ID, dependent)
+	}
+}
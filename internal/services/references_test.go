@@ -0,0 +1,148 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testReferenceLoader serves the given configurations, as version 7 of each environment
+func testReferenceLoader(configs map[string]string) referenceLoader {
+	actives := make(map[string]*models.ActiveConfig)
+	for env, config := range configs {
+		actives[env] = &models.ActiveConfig{Environment: env, Version: 7, ConfigJSON: json.RawMessage(config)}
+	}
+	return activeReferenceLoader(actives)
+}
+
+func TestResolveReferences(t *testing.T) {
+	service := &ConfigService{}
+	load := testReferenceLoader(map[string]string{
+		"prod":   `{"api": {"base": "https://api.example.com", "ports": [80, 443]}, "timeout": 1.50, "password": {"$encrypted": "c2VhbGVk"}}`,
+		"shared": `{"base": {"$ref": "env://prod/api.base"}, "loop": {"$ref": "env://staging/loop"}}`,
+	})
+
+	resolve := func(config string) (*models.ConfigResponse, error) {
+		response := &models.ConfigResponse{Environment: "staging", Version: 3, Config: json.RawMessage(config)}
+		return response, service.resolveReferences(response, load)
+	}
+
+	t.Run("values are taken from the referenced environment", func(t *testing.T) {
+		response, err := resolve(`{"api_base": {"$ref": "env://prod/api.base"}, "nested": {"port": {"$ref": "env://prod/api.ports.1"}}, "timeout": {"$ref": "env://prod/timeout"}, "own": "x"}`)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"api_base": "https://api.example.com", "nested": {"port": 443}, "timeout": 1.50, "own": "x"}`, string(response.Config))
+		assert.Contains(t, string(response.Config), "1.50")
+		assert.Equal(t, []models.ConfigReferenceSource{{Environment: "prod", Version: 7}}, response.References)
+	})
+
+	t.Run("referenced values can be references themselves", func(t *testing.T) {
+		response, err := resolve(`{"base": {"$ref": "env://shared/base"}, "alias": {"$ref": "env://staging/own"}, "own": "x"}`)
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"base": "https://api.example.com", "alias": "x", "own": "x"}`, string(response.Config))
+		assert.Equal(t, []models.ConfigReferenceSource{{Environment: "prod", Version: 7}, {Environment: "shared", Version: 7}}, response.References)
+	})
+
+	t.Run("configurations without references are unchanged", func(t *testing.T) {
+		config := `{"schema": {"$ref": "#/definitions/user"}, "link": {"$ref": "env://prod/api.base", "note": "not a reference"}}`
+		response, err := resolve(config)
+		require.NoError(t, err)
+
+		assert.Equal(t, config, string(response.Config))
+		assert.Nil(t, response.References)
+	})
+
+	t.Run("secrets are copied encrypted to top-level keys only", func(t *testing.T) {
+		response, err := resolve(`{"password": {"$ref": "env://prod/password"}}`)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"password": {"$encrypted": "c2VhbGVk"}}`, string(response.Config))
+
+		_, err = resolve(`{"db": {"password": {"$ref": "env://prod/password"}}}`)
+		assert.EqualError(t, err, "broken reference env://prod/password: secret values can only be referenced by top-level keys")
+	})
+
+	t.Run("broken references", func(t *testing.T) {
+		tests := map[string]struct {
+			config  string
+			wantErr string
+		}{
+			"missing key": {
+				`{"a": {"$ref": "env://prod/api.missing"}}`,
+				"broken reference env://prod/api.missing: key api.missing not found in environment prod",
+			},
+			"missing environment": {
+				`{"a": {"$ref": "env://qa/api"}}`,
+				"broken reference env://qa/api: environment qa has no active configuration",
+			},
+			"no key": {
+				`{"a": {"$ref": "env://prod"}}`,
+				"broken reference env://prod: must name an environment and a key, as env://<env>/<key>",
+			},
+			"cycle": {
+				`{"loop": {"$ref": "env://shared/loop"}}`,
+				"broken reference env://shared/loop: cycle env://shared/loop -> env://staging/loop -> env://shared/loop",
+			},
+			"self reference": {
+				`{"a": {"$ref": "env://staging/a"}}`,
+				"broken reference env://staging/a: cycle env://staging/a -> env://staging/a",
+			},
+			"whole configuration": {
+				`{"$ref": "env://prod/api"}`,
+				"broken reference env://prod/api: a configuration cannot itself be a reference",
+			},
+		}
+
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := resolve(tt.config)
+				assert.EqualError(t, err, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("references are followed up to the maximum depth", func(t *testing.T) {
+		shallow := &ConfigService{config: &Config{MaxReferenceDepth: 1}}
+		response := &models.ConfigResponse{Environment: "staging", Config: json.RawMessage(`{"base": {"$ref": "env://shared/base"}}`)}
+
+		err := shallow.resolveReferences(response, load)
+		assert.EqualError(t, err, "broken reference env://prod/api.base: more than 1 references deep")
+
+		response.Config = json.RawMessage(`{"base": {"$ref": "env://prod/api.base"}}`)
+		assert.NoError(t, shallow.resolveReferences(response, load))
+	})
+}
+
+func TestReferencingEnvironments(t *testing.T) {
+	actives := []models.ActiveConfig{
+		{Environment: "prod", ConfigJSON: json.RawMessage(`{"base": "https://api.example.com"}`)},
+		{Environment: "staging", ConfigJSON: json.RawMessage(`{"base": {"$ref": "env://prod/base"}}`)},
+		{Environment: "dev", ConfigJSON: json.RawMessage(`{"items": [{"$ref": "env://staging/base"}]}`)},
+		{Environment: "qa", ConfigJSON: json.RawMessage(`{"base": "local"}`)},
+	}
+
+	t.Run("direct and indirect references", func(t *testing.T) {
+		assert.Equal(t, []string{"staging", "dev"}, referencingEnvironments(actives, "prod"))
+		assert.Equal(t, []string{"dev"}, referencingEnvironments(actives, "staging"))
+		assert.Empty(t, referencingEnvironments(actives, "qa"))
+	})
+
+	t.Run("references in the defaults", func(t *testing.T) {
+		withDefaults := make([]models.ActiveConfig, len(actives))
+		for i, active := range actives {
+			active.DefaultsJSON = json.RawMessage(`{"region": {"$ref": "env://qa/region"}}`)
+			withDefaults[i] = active
+		}
+		assert.ElementsMatch(t, []string{"prod", "staging", "dev"}, referencingEnvironments(withDefaults, "qa"))
+	})
+
+	t.Run("rollouts are always included", func(t *testing.T) {
+		withRollout := append([]models.ActiveConfig{}, actives...)
+		withRollout[3].HasRollout = true
+		assert.Equal(t, []string{"qa"}, referencingEnvironments(withRollout, "dev"))
+	})
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"remote-config-system/internal/models"
+)
+
+// retentionPolicy returns the number of latest versions and the number of days of history
+// an environment keeps, taking its overrides over the global policy. A version is pruned
+// once neither rule keeps it; when both are zero, history is kept forever.
+func (s *ConfigService) retentionPolicy(env *models.Environment) (int, int) {
+	versions, days := s.config.RetentionVersions, s.config.RetentionDays
+	if env.RetentionVersions != nil {
+		versions = *env.RetentionVersions
+	}
+	if env.RetentionDays != nil {
+		days = *env.RetentionDays
+	}
+	return versions, days
+}
+
+// PruneHistory deletes the configuration versions of an environment that its retention
+// policy no longer keeps. The active version, tagged versions, the version of a rollout
+// in progress and versions awaiting approval are never deleted.
+func (s *ConfigService) PruneHistory(orgSlug, appSlug, envSlug string) (*models.PruneHistoryResponse, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	versions, days := s.retentionPolicy(env)
+	pruned, err := s.pruneEnvironment(env, versions, days)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PruneHistoryResponse{
+		Organization:      env.Application.Organization.Slug,
+		Application:       env.Application.Slug,
+		Environment:       env.Slug,
+		RetentionVersions: versions,
+		RetentionDays:     days,
+		Pruned:            pruned,
+	}, nil
+}
+
+// PruneAllHistory applies the retention policy of every environment and returns the
+// number of versions deleted. An environment that fails is logged and skipped.
+func (s *ConfigService) PruneAllHistory() (int, error) {
+	envs, err := s.repos.Environments.ListAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list environments: %w", err)
+	}
+
+	total := 0
+	for i := range envs {
+		env := &envs[i]
+		versions, days := s.retentionPolicy(env)
+		pruned, err := s.pruneEnvironment(env, versions, days)
+		if err != nil {
+			log.Printf("Failed to prune history of %s/%s/%s: %v", env.Application.Organization.Slug, env.Application.Slug, env.Slug, err)
+			continue
+		}
+		total += pruned
+	}
+
+	return total, nil
+}
+
+// pruneEnvironment deletes the versions of an environment outside the given policy
+func (s *ConfigService) pruneEnvironment(env *models.Environment, versions, days int) (int, error) {
+	// Without a policy, history is kept forever
+	if versions == 0 && days == 0 {
+		return 0, nil
+	}
+
+	pruned, err := s.repos.ConfigVersions.Prune(env.ID, versions, days)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune configuration history: %w", err)
+	}
+	return pruned, nil
+}
+
+// RunHistoryPruner applies the retention policies at the configured interval until ctx
+// is done
+func (s *ConfigService) RunHistoryPruner(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pruned, err := s.PruneAllHistory()
+			if err != nil {
+				log.Printf("History pruning failed: %v", err)
+				continue
+			}
+			if pruned > 0 {
+				log.Printf("Pruned %d configuration versions", pruned)
+			}
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigService_RetentionPolicy(t *testing.T) {
+	service := &ConfigService{config: &Config{RetentionVersions: 50, RetentionDays: 90}}
+	versions, days := 10, 7
+
+	tests := []struct {
+		name             string
+		env              *models.Environment
+		expectedVersions int
+		expectedDays     int
+	}{
+		{"global policy", &models.Environment{}, 50, 90},
+		{"versions override", &models.Environment{RetentionVersions: &versions}, 10, 90},
+		{"days override", &models.Environment{RetentionDays: &days}, 50, 7},
+		{"both overrides", &models.Environment{RetentionVersions: &versions, RetentionDays: &days}, 10, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions, days := service.retentionPolicy(tt.env)
+			assert.Equal(t, tt.expectedVersions, versions)
+			assert.Equal(t, tt.expectedDays, days)
+		})
+	}
+}
+
+func TestConfigService_PruneEnvironment_WithoutPolicy(t *testing.T) {
+	// Without a policy the repository is never reached
+	service := &ConfigService{config: &Config{}}
+
+	pruned, err := service.pruneEnvironment(&models.Environment{}, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, pruned)
+}
+
+func TestUpdateOverride(t *testing.T) {
+	current, requested, reset := 30, 60, 0
+
+	assert.Equal(t, &current, updateOverride(&current, nil))
+	assert.Equal(t, &requested, updateOverride(&current, &requested))
+	assert.Nil(t, updateOverride(&current, &reset))
+	assert.Nil(t, updateOverride(nil, nil))
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"remote-config-system/internal/models"
+)
+
+// cachedAPIKeyConfig is what gets cached for an API key and environment: the active
+// configuration and, while a rollout is in progress, the rollout configuration with the
+// share of clients that get it. Caching both under one key means a cache hit never
+// serves the version picked for one client to another.
+type cachedAPIKeyConfig struct {
+	Stable     *models.ConfigResponse `json:"stable"`
+	Rollout    *models.ConfigResponse `json:"rollout,omitempty"`
+	Percentage int                    `json:"percentage,omitempty"`
+}
+
+// forClient picks the configuration served to a client. Clients without an ID always
+// get the active configuration.
+func (e *cachedAPIKeyConfig) forClient(clientID string) *models.ConfigResponse {
+	if e.Rollout == nil || clientID == "" {
+		return e.Stable
+	}
+
+	if rolloutBucket(e.Stable.Organization, e.Stable.Application, e.Stable.Environment, clientID) < e.Percentage {
+		return e.Rollout
+	}
+	return e.Stable
+}
+
+// rolloutBucket deterministically maps a client of an environment to a bucket between 0
+// and 99. A client stays in its bucket as the percentage grows, so it never flips back.
+func rolloutBucket(orgSlug, appSlug, envSlug, clientID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(orgSlug + "/" + appSlug + "/" + envSlug + "\x00" + clientID))
+	return int(h.Sum32() % 100)
+}
+
+// startRollout stores an update as an inactive version that is served to percentage of
+// API-key clients until it is promoted. A rollout already in progress is replaced.
+func (s *ConfigService) startRollout(env *models.Environment, storedConfig, config json.RawMessage, stableVersion, percentage int, createdBy, message *string) (*models.ConfigResponse, error) {
+	rolloutVersion := &models.ConfigVersion{
+		EnvID:      env.ID,
+		ConfigJSON: storedConfig,
+		IsActive:   false,
+		CreatedBy:  createdBy,
+	}
+
+	if err := s.repos.ConfigVersions.Create(rolloutVersion); err != nil {
+		return nil, fmt.Errorf("failed to create configuration version: %w", recordError(err))
+	}
+
+	rollout := &models.ConfigRollout{
+		EnvID:         env.ID,
+		Version:       rolloutVersion.Version,
+		StableVersion: stableVersion,
+		Percentage:    percentage,
+		UpdatedBy:     createdBy,
+	}
+
+	if err := s.repos.ConfigRollouts.Upsert(rollout); err != nil {
+		return nil, err
+	}
+
+	// Log the change
+	change := &models.ConfigChange{
+		EnvID:       env.ID,
+		VersionFrom: &stableVersion,
+		VersionTo:   rolloutVersion.Version,
+		Action:      "rollout",
+		CreatedBy:   createdBy,
+		Message:     message,
+	}
+
+	if err := s.repos.ConfigChanges.Create(change); err != nil {
+		log.Printf("Failed to log configuration rollout: %v", err)
+	}
+
+	// Invalidate cache for this configuration
+	if err := s.InvalidateEnvironmentCache(env.Application.Organization.Slug, env.Application.Slug, env.Slug); err != nil {
+		log.Printf("Failed to invalidate environment cache: %v", err)
+	}
+
+	return &models.ConfigResponse{
+		Organization: env.Application.Organization.Slug,
+		Application:  env.Application.Slug,
+		Environment:  env.Slug,
+		Version:      rolloutVersion.Version,
+		VersionID:    &rolloutVersion.ID,
+		Config:       config,
+		UpdatedAt:    rolloutVersion.CreatedAt,
+		Rollout:      rollout,
+	}, nil
+}
+
+// GetRollout retrieves the rollout in progress for an environment
+func (s *ConfigService) GetRollout(orgSlug, appSlug, envSlug string) (*models.ConfigRollout, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	rollout, err := s.repos.ConfigRollouts.GetByEnvironment(env.ID)
+	if err != nil {
+		return nil, recordError(err)
+	}
+
+	return rollout, nil
+}
+
+// UpdateRollout changes the share of clients that get the rollout version
+func (s *ConfigService) UpdateRollout(orgSlug, appSlug, envSlug string, req *models.UpdateRolloutRequest) (*models.ConfigRollout, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	if err := checkUnlocked(env); err != nil {
+		return nil, err
+	}
+
+	rollout, err := s.repos.ConfigRollouts.GetByEnvironment(env.ID)
+	if err != nil {
+		return nil, recordError(err)
+	}
+
+	rollout.Percentage = *req.Percentage
+	rollout.UpdatedBy = req.UpdatedBy
+	if err := s.repos.ConfigRollouts.UpdatePercentage(rollout); err != nil {
+		return nil, err
+	}
+
+	if err := s.InvalidateEnvironmentCache(orgSlug, appSlug, envSlug); err != nil {
+		log.Printf("Failed to invalidate environment cache: %v", err)
+	}
+
+	log.Printf("Rollout of version %d in %s/%s/%s set to %d%%", rollout.Version, orgSlug, appSlug, envSlug, rollout.Percentage)
+	return rollout, nil
+}
+
+// PromoteRollout activates the rollout version for every client and ends the rollout
+func (s *ConfigService) PromoteRollout(orgSlug, appSlug, envSlug string, req *models.PromoteRolloutRequest) (*models.ConfigResponse, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	if err := checkUnlocked(env); err != nil {
+		return nil, err
+	}
+
+	rollout, err := s.repos.ConfigRollouts.GetByEnvironment(env.ID)
+	if err != nil {
+		return nil, recordError(err)
+	}
+
+	rolloutConfig, err := s.repos.ConfigVersions.GetByVersion(env.ID, rollout.Version)
+	if err != nil {
+		return nil, lookupError("configuration version not found", err)
+	}
+
+	if err := s.repos.ConfigVersions.SetActive(env.ID, rollout.Version); err != nil {
+		return nil, fmt.Errorf("failed to activate configuration: %w", err)
+	}
+
+	if _, err := s.repos.ConfigRollouts.Delete(env.ID); err != nil {
+		log.Printf("Failed to end rollout: %v", err)
+	}
+
+	// Log the change
+	change := &models.ConfigChange{
+		EnvID:       env.ID,
+		VersionFrom: &rollout.StableVersion,
+		VersionTo:   rollout.Version,
+		Action:      "promote",
+		CreatedBy:   req.UpdatedBy,
+	}
+
+	if err := s.repos.ConfigChanges.Create(change); err != nil {
+		log.Printf("Failed to log rollout promotion: %v", err)
+	}
+
+	// Invalidate cache for this configuration
+	if err := s.InvalidateEnvironmentCache(orgSlug, appSlug, envSlug); err != nil {
+		log.Printf("Failed to invalidate environment cache: %v", err)
+	}
+
+	configJSON, err := s.encryptor.DecryptSecrets(rolloutConfig.ConfigJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt configuration: %w", err)
+	}
+
+	// Build the response
+	response := &models.ConfigResponse{
+		Organization: env.Application.Organization.Slug,
+		Application:  env.Application.Slug,
+		Environment:  env.Slug,
+		Version:      rollout.Version,
+		VersionID:    &rolloutConfig.ID,
+		Config:       configJSON,
+		UpdatedAt:    time.Now(),
+	}
+
+	// Broadcast SSE event for configuration update
+	if s.sseService != nil {
+		config, defaultsVersion := s.broadcastConfig(env, rolloutConfig.ConfigJSON)
+		updateEvent := models.ConfigUpdateEvent{
+			Organization:    response.Organization,
+			Application:     response.Application,
+			Environment:     response.Environment,
+			Version:         response.Version,
+			DefaultsVersion: defaultsVersion,
+			Config:          config,
+			Stored:          unmaskSecrets(config, rolloutConfig.ConfigJSON),
+			Action:          "update",
+			UpdatedAt:       response.UpdatedAt,
+		}
+		s.sseService.BroadcastConfigUpdate(updateEvent)
+	}
+	s.notifyChange(env, change)
+
+	return response, nil
+}
+
+// AbortRollout ends a rollout without activating its version, so that every client is
+// back on the active version. Unlike other changes, it is allowed while the environment is
+// locked, since it only takes clients back to the version they had before the rollout.
+func (s *ConfigService) AbortRollout(orgSlug, appSlug, envSlug string, abortedBy *string) error {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return lookupError("environment not found", err)
+	}
+
+	rollout, err := s.repos.ConfigRollouts.GetByEnvironment(env.ID)
+	if err != nil {
+		return recordError(err)
+	}
+
+	if _, err := s.repos.ConfigRollouts.Delete(env.ID); err != nil {
+		return err
+	}
+
+	// Log the change
+	change := &models.ConfigChange{
+		EnvID:       env.ID,
+		VersionFrom: &rollout.Version,
+		VersionTo:   rollout.StableVersion,
+		Action:      "rollout_abort",
+		CreatedBy:   abortedBy,
+	}
+
+	if err := s.repos.ConfigChanges.Create(change); err != nil {
+		log.Printf("Failed to log rollout abort: %v", err)
+	}
+
+	if err := s.InvalidateEnvironmentCache(orgSlug, appSlug, envSlug); err != nil {
+		log.Printf("Failed to invalidate environment cache: %v", err)
+	}
+
+	return nil
+}
+
+// endRollout drops the rollout of an environment whose active version is being replaced
+// by other means; the rollout version would otherwise be served on top of the new one
+func (s *ConfigService) endRollout(env *models.Environment) {
+	ended, err := s.repos.ConfigRollouts.Delete(env.ID)
+	if err != nil {
+		log.Printf("Failed to end rollout: %v", err)
+		return
+	}
+	if ended {
+		log.Printf("Ended rollout in %s/%s/%s", env.Application.Organization.Slug, env.Application.Slug, env.Slug)
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolloutBucket(t *testing.T) {
+	t.Run("deterministic and in range", func(t *testing.T) {
+		for i := 0; i < 1000; i++ {
+			clientID := fmt.Sprintf("client-%d", i)
+			bucket := rolloutBucket("org", "app", "prod", clientID)
+			assert.GreaterOrEqual(t, bucket, 0)
+			assert.Less(t, bucket, 100)
+			assert.Equal(t, bucket, rolloutBucket("org", "app", "prod", clientID))
+		}
+	})
+
+	t.Run("roughly uniform", func(t *testing.T) {
+		inFirstHalf := 0
+		for i := 0; i < 10000; i++ {
+			if rolloutBucket("org", "app", "prod", fmt.Sprintf("client-%d", i)) < 50 {
+				inFirstHalf++
+			}
+		}
+		assert.InDelta(t, 5000, inFirstHalf, 300)
+	})
+}
+
+func TestCachedAPIKeyConfigForClient(t *testing.T) {
+	stable := &models.ConfigResponse{Organization: "org", Application: "app", Environment: "prod", Version: 3}
+	rollout := &models.ConfigResponse{Organization: "org", Application: "app", Environment: "prod", Version: 4}
+
+	t.Run("no rollout", func(t *testing.T) {
+		entry := &cachedAPIKeyConfig{Stable: stable}
+		assert.Same(t, stable, entry.forClient("client-1"))
+	})
+
+	t.Run("clients without an ID get the active version", func(t *testing.T) {
+		entry := &cachedAPIKeyConfig{Stable: stable, Rollout: rollout, Percentage: 100}
+		assert.Same(t, stable, entry.forClient(""))
+	})
+
+	t.Run("percentage bounds", func(t *testing.T) {
+		none := &cachedAPIKeyConfig{Stable: stable, Rollout: rollout, Percentage: 0}
+		all := &cachedAPIKeyConfig{Stable: stable, Rollout: rollout, Percentage: 100}
+		for i := 0; i < 100; i++ {
+			clientID := fmt.Sprintf("client-%d", i)
+			assert.Same(t, stable, none.forClient(clientID))
+			assert.Same(t, rollout, all.forClient(clientID))
+		}
+	})
+
+	t.Run("clients keep the new version as the percentage grows", func(t *testing.T) {
+		entry := &cachedAPIKeyConfig{Stable: stable, Rollout: rollout, Percentage: 10}
+		var early []string
+		for i := 0; i < 1000; i++ {
+			clientID := fmt.Sprintf("client-%d", i)
+			if entry.forClient(clientID) == rollout {
+				early = append(early, clientID)
+			}
+		}
+		assert.NotEmpty(t, early)
+
+		entry.Percentage = 60
+		for _, clientID := range early {
+			assert.Same(t, rollout, entry.forClient(clientID))
+		}
+	})
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"remote-config-system/internal/db"
+	"remote-config-system/internal/models"
+)
+
+// Value types a rule can require
+const (
+	RuleTypeString = "string"
+	RuleTypeInt    = "int"
+	RuleTypeNumber = "number"
+	RuleTypeBool   = "bool"
+	RuleTypeObject = "object"
+	RuleTypeArray  = "array"
+)
+
+// GetConfigRules retrieves the validation rules of an environment
+func (s *ConfigService) GetConfigRules(orgSlug, appSlug, envSlug string) (*models.ConfigRuleset, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	ruleset, err := s.repos.ConfigRules.GetByEnvironment(env.ID)
+	if err != nil {
+		return nil, lookupError("config rules not found", err)
+	}
+
+	return ruleset, nil
+}
+
+// SetConfigRules stores the validation rules of an environment, replacing any existing
+// ones. Configurations already active are not re-checked.
+func (s *ConfigService) SetConfigRules(orgSlug, appSlug, envSlug string, req *models.UpdateConfigRulesRequest) (*models.ConfigRuleset, error) {
+	if err := CheckRuleset(req.Rules); err != nil {
+		return nil, err
+	}
+
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	ruleset := &models.ConfigRuleset{
+		EnvID: env.ID,
+		Rules: req.Rules,
+	}
+
+	if err := s.repos.ConfigRules.Upsert(ruleset); err != nil {
+		return nil, fmt.Errorf("failed to save config rules: %w", err)
+	}
+
+	return ruleset, nil
+}
+
+// DeleteConfigRules removes the validation rules of an environment
+func (s *ConfigService) DeleteConfigRules(orgSlug, appSlug, envSlug string) error {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return lookupError("environment not found", err)
+	}
+
+	deleted, err := s.repos.ConfigRules.Delete(env.ID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return notFoundError("config rules not found for environment: %s", envSlug)
+	}
+
+	return nil
+}
+
+// checkRules evaluates the environment's rules against the configuration
+func (s *ConfigService) checkRules(env *models.Environment, config json.RawMessage) []models.ValidationIssue {
+	issues, err := s.ruleViolations(env, config)
+	if err != nil {
+		return []models.ValidationIssue{{Check: CheckRules, Message: err.Error()}}
+	}
+	return issues
+}
+
+// checkConfigRules returns an error listing every rule of the environment that the
+// configuration violates
+func (s *ConfigService) checkConfigRules(env *models.Environment, config json.RawMessage) error {
+	issues, err := s.ruleViolations(env, config)
+	if err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(issues))
+	for i, issue := range issues {
+		messages[i] = issue.Message
+	}
+	return validationError("configuration violates rules: %s", strings.Join(messages, "; "))
+}
+
+// ruleViolations loads the environment's rules, if it has any, and evaluates them against
+// the configuration merged with the application defaults
+func (s *ConfigService) ruleViolations(env *models.Environment, config json.RawMessage) ([]models.ValidationIssue, error) {
+	if env == nil || s.repos == nil {
+		return nil, nil
+	}
+
+	ruleset, err := s.repos.ConfigRules.GetByEnvironment(env.ID)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load config rules: %w", err)
+	}
+
+	// Keys inherited from the application defaults count towards the rules
+	effective, _, err := s.effectiveConfig(env.AppID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return EvaluateRules(ruleset.Rules, effective)
+}
+
+// CheckRuleset verifies that rules are well-formed before they are stored
+func CheckRuleset(rules []models.ConfigRule) error {
+	for i, rule := range rules {
+		if err := checkRule(rule); err != nil {
+			return invalidError("invalid rules: rule %d (%s): %w", i+1, rule.Key, err)
+		}
+	}
+	return nil
+}
+
+// checkRule verifies that a single rule is well-formed
+func checkRule(rule models.ConfigRule) error {
+	if rule.Key == "" {
+		return fmt.Errorf("key is required")
+	}
+	for _, part := range strings.Split(rule.Key, ".") {
+		if part == "" {
+			return fmt.Errorf("key must be a dot-notated path")
+		}
+	}
+
+	switch rule.Type {
+	case "", RuleTypeString, RuleTypeInt, RuleTypeNumber, RuleTypeBool, RuleTypeObject, RuleTypeArray:
+	default:
+		return fmt.Errorf("unknown type %q", rule.Type)
+	}
+
+	if rule.Min != nil || rule.Max != nil {
+		if rule.Type != RuleTypeInt && rule.Type != RuleTypeNumber {
+			return fmt.Errorf("min and max require type int or number")
+		}
+		if rule.Min != nil && rule.Max != nil && *rule.Min > *rule.Max {
+			return fmt.Errorf("min is greater than max")
+		}
+	}
+
+	if rule.Pattern != "" {
+		if rule.Type != RuleTypeString {
+			return fmt.Errorf("pattern requires type string")
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// EvaluateRules checks a configuration against a list of rules, returning every
+// violation. Violation messages never include configuration values, which may be secret.
+func EvaluateRules(rules []models.ConfigRule, config json.RawMessage) ([]models.ValidationIssue, error) {
+	root, err := decodeConfigValue(config)
+	if err != nil {
+		return nil, invalidError("invalid JSON configuration: %w", err)
+	}
+
+	issues := []models.ValidationIssue{}
+	for _, rule := range rules {
+		for _, message := range evaluateRule(rule, root) {
+			if rule.Message != "" {
+				message = rule.Message
+			}
+			issues = append(issues, models.ValidationIssue{
+				Check:   CheckRules,
+				Path:    rule.Key,
+				Message: message,
+			})
+		}
+	}
+
+	return issues, nil
+}
+
+// evaluateRule returns the violation messages of a single rule
+func evaluateRule(rule models.ConfigRule, root interface{}) []string {
+	value, present := lookupKey(root, rule.Key)
+	if !present || value == nil {
+		if rule.Required {
+			return []string{fmt.Sprintf("%s is required", rule.Key)}
+		}
+		return nil
+	}
+
+	if rule.Type != "" && !matchesRuleType(rule.Type, value) {
+		return []string{fmt.Sprintf("%s must be %s", rule.Key, ruleTypeName(rule.Type))}
+	}
+
+	var messages []string
+	if number, ok := value.(json.Number); ok {
+		if f, err := number.Float64(); err == nil {
+			if rule.Min != nil && f < *rule.Min {
+				messages = append(messages, fmt.Sprintf("%s must be at least %v", rule.Key, *rule.Min))
+			}
+			if rule.Max != nil && f > *rule.Max {
+				messages = append(messages, fmt.Sprintf("%s must be at most %v", rule.Key, *rule.Max))
+			}
+		}
+	}
+
+	if s, ok := value.(string); ok && rule.Pattern != "" {
+		if re, err := regexp.Compile(rule.Pattern); err == nil && !re.MatchString(s) {
+			messages = append(messages, fmt.Sprintf("%s must match %s", rule.Key, rule.Pattern))
+		}
+	}
+
+	if len(rule.Enum) > 0 && !enumContains(rule.Enum, value) {
+		messages = append(messages, fmt.Sprintf("%s must be one of the allowed values", rule.Key))
+	}
+
+	return messages
+}
+
+// lookupKey resolves a dot-notated path through nested objects
+func lookupKey(root interface{}, key string) (interface{}, bool) {
+	value := root
+	for _, part := range strings.Split(key, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = object[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// matchesRuleType reports whether a decoded configuration value has the rule type
+func matchesRuleType(ruleType string, value interface{}) bool {
+	switch ruleType {
+	case RuleTypeString:
+		_, ok := value.(string)
+		return ok
+	case RuleTypeInt:
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := number.Float64()
+		return err == nil && f == math.Trunc(f)
+	case RuleTypeNumber:
+		_, ok := value.(json.Number)
+		return ok
+	case RuleTypeBool:
+		_, ok := value.(bool)
+		return ok
+	case RuleTypeObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case RuleTypeArray:
+		_, ok := value.([]interface{})
+		return ok
+	}
+	return true
+}
+
+// ruleTypeName returns the rule type with an article, for violation messages
+func ruleTypeName(ruleType string) string {
+	switch ruleType {
+	case RuleTypeInt:
+		return "an int"
+	case RuleTypeObject, RuleTypeArray:
+		return "an " + ruleType
+	}
+	return "a " + ruleType
+}
+
+// enumContains reports whether value equals one of the allowed values. Numbers are
+// compared by value, so that 1 and 1.0 are equal.
+func enumContains(allowed []interface{}, value interface{}) bool {
+	for _, candidate := range allowed {
+		if number, ok := value.(json.Number); ok {
+			f, err := number.Float64()
+			if c, isFloat := candidate.(float64); isFloat && err == nil && c == f {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func TestEvaluateRules(t *testing.T) {
+	rules := []models.ConfigRule{
+		{Key: "api_timeout", Type: RuleTypeInt, Min: float64Ptr(1), Max: float64Ptr(300)},
+		{Key: "database_url", Required: true, Type: RuleTypeString, Pattern: "^postgres://"},
+		{Key: "log.level", Enum: []interface{}{"debug", "info"}},
+		{Key: "ratio", Type: RuleTypeNumber, Max: float64Ptr(1), Message: "ratio is a share between 0 and 1"},
+	}
+
+	t.Run("valid configuration", func(t *testing.T) {
+		config := json.RawMessage(`{"api_timeout": 30, "database_url": "postgres://db/app", "log": {"level": "info"}, "ratio": 0.5}`)
+
+		issues, err := EvaluateRules(rules, config)
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("optional keys may be missing", func(t *testing.T) {
+		issues, err := EvaluateRules(rules, json.RawMessage(`{"database_url": "postgres://db/app"}`))
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+
+	t.Run("every violation is reported", func(t *testing.T) {
+		config := json.RawMessage(`{"api_timeout": 500, "database_url": null, "log": {"level": "trace"}, "ratio": 2}`)
+
+		issues, err := EvaluateRules(rules, config)
+		require.NoError(t, err)
+		assert.Equal(t, []models.ValidationIssue{
+			{Check: CheckRules, Path: "api_timeout", Message: "api_timeout must be at most 300"},
+			{Check: CheckRules, Path: "database_url", Message: "database_url is required"},
+			{Check: CheckRules, Path: "log.level", Message: "log.level must be one of the allowed values"},
+			{Check: CheckRules, Path: "ratio", Message: "ratio is a share between 0 and 1"},
+		}, issues)
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		config := json.RawMessage(`{"api_timeout": 2.5, "database_url": 42}`)
+
+		issues, err := EvaluateRules(rules, config)
+		require.NoError(t, err)
+		require.Len(t, issues, 2)
+		assert.Equal(t, "api_timeout must be an int", issues[0].Message)
+		assert.Equal(t, "database_url must be a string", issues[1].Message)
+	})
+
+	t.Run("pattern mismatch does not reveal the value", func(t *testing.T) {
+		issues, err := EvaluateRules(rules, json.RawMessage(`{"database_url": "mysql://secret@db"}`))
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "database_url must match ^postgres://", issues[0].Message)
+		assert.NotContains(t, issues[0].Message, "secret")
+	})
+
+	t.Run("numbers in enums are compared by value", func(t *testing.T) {
+		enumRules := []models.ConfigRule{{Key: "retries", Enum: []interface{}{float64(1), float64(3)}}}
+
+		issues, err := EvaluateRules(enumRules, json.RawMessage(`{"retries": 3.0}`))
+		require.NoError(t, err)
+		assert.Empty(t, issues)
+	})
+}
+
+func TestCheckRuleset(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     models.ConfigRule
+		expected string
+	}{
+		{"valid", models.ConfigRule{Key: "api_timeout", Type: RuleTypeInt, Min: float64Ptr(1)}, ""},
+		{"missing key", models.ConfigRule{Required: true}, "key is required"},
+		{"empty path segment", models.ConfigRule{Key: "log..level"}, "key must be a dot-notated path"},
+		{"unknown type", models.ConfigRule{Key: "a", Type: "integer"}, `unknown type "integer"`},
+		{"range without numeric type", models.ConfigRule{Key: "a", Max: float64Ptr(1)}, "min and max require type int or number"},
+		{"inverted range", models.ConfigRule{Key: "a", Type: RuleTypeNumber, Min: float64Ptr(2), Max: float64Ptr(1)}, "min is greater than max"},
+		{"pattern without string type", models.ConfigRule{Key: "a", Pattern: "^x"}, "pattern requires type string"},
+		{"invalid pattern", models.ConfigRule{Key: "a", Type: RuleTypeString, Pattern: "("}, "invalid pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckRuleset([]models.ConfigRule{tt.rule})
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid rules: rule 1")
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"remote-config-system/internal/models"
+)
+
+// GetConfigSchema retrieves the JSON Schema stored for an environment
+func (s *ConfigService) GetConfigSchema(orgSlug, appSlug, envSlug string) (*models.ConfigSchema, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	schema, err := s.repos.ConfigSchemas.GetByEnvironment(env.ID)
+	if err != nil {
+		return nil, lookupError("config schema not found", err)
+	}
+
+	return schema, nil
+}
+
+// SetConfigSchema stores the JSON Schema for an environment, replacing any existing one
+func (s *ConfigService) SetConfigSchema(orgSlug, appSlug, envSlug string, req *models.UpdateConfigSchemaRequest) (*models.ConfigSchema, error) {
+	if err := checkSchemaDocument(req.Schema); err != nil {
+		return nil, err
+	}
+
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	schema := &models.ConfigSchema{
+		EnvID:      env.ID,
+		SchemaJSON: req.Schema,
+	}
+
+	if err := s.repos.ConfigSchemas.Upsert(schema); err != nil {
+		return nil, fmt.Errorf("failed to save config schema: %w", err)
+	}
+
+	return schema, nil
+}
+
+// GetFormSchema combines an environment's JSON Schema with the values of its active
+// configuration, producing a pre-filled structure that form renderers can consume
+func (s *ConfigService) GetFormSchema(orgSlug, appSlug, envSlug string) (*models.FormSchemaResponse, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	schema, err := s.repos.ConfigSchemas.GetByEnvironment(env.ID)
+	if err != nil {
+		return nil, lookupError("config schema not found", err)
+	}
+
+	// Pre-fill from the active version, or leave values empty if there is none yet
+	version := 0
+	config := json.RawMessage(`{}`)
+	secretKeys := env.SecretKeys
+	if activeConfig, err := s.repos.ConfigVersions.GetActiveByEnvironment(env.ID); err == nil {
+		version = activeConfig.Version
+		secretKeys = append(EncryptedKeys(activeConfig.ConfigJSON), secretKeys...)
+		config, err = s.encryptor.DecryptSecrets(activeConfig.ConfigJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt configuration: %w", err)
+		}
+	}
+
+	annotated, fields, err := BuildFormSchema(schema.SchemaJSON, config, secretKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.FormSchemaResponse{
+		Organization: env.Application.Organization.Slug,
+		Application:  env.Application.Slug,
+		Environment:  env.Slug,
+		Version:      version,
+		Schema:       annotated,
+		Fields:       fields,
+	}, nil
+}
+
+// BuildFormSchema annotates a JSON Schema with the current configuration values as
+// defaults and flattens its leaf properties into form fields with dot-notated paths.
+// Values under secret keys are replaced with MaskedValue in both.
+func BuildFormSchema(schema, config json.RawMessage, secretKeys []string) (json.RawMessage, []models.FormField, error) {
+	schemaValue, err := decodeConfigValue(schema)
+	if err != nil {
+		return nil, nil, validationError("invalid schema: %w", err)
+	}
+	root, ok := schemaValue.(map[string]interface{})
+	if !ok {
+		return nil, nil, validationError("invalid schema: root must be an object")
+	}
+
+	configValue, err := decodeConfigValue(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+
+	secrets := make(map[string]bool, len(secretKeys))
+	for _, key := range secretKeys {
+		secrets[key] = true
+	}
+
+	fields := []models.FormField{}
+	annotateSchemaNode("", root, configValue, true, false, secrets, &fields)
+
+	annotated, err := json.Marshal(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+
+	return annotated, fields, nil
+}
+
+// annotateSchemaNode walks an object schema node, recursing into nested object
+// properties and recording leaf properties as form fields
+func annotateSchemaNode(path string, node map[string]interface{}, value interface{}, present, required bool, secrets map[string]bool, fields *[]models.FormField) {
+	properties, hasProperties := node["properties"].(map[string]interface{})
+	if !hasProperties {
+		if present && secrets[strings.SplitN(path, ".", 2)[0]] {
+			value = MaskedValue
+		}
+		if present {
+			node["default"] = value
+		}
+		field := formFieldFor(path, node, value, present, secrets)
+		field.Required = required
+		*fields = append(*fields, field)
+		return
+	}
+
+	requiredNames := make(map[string]bool)
+	if list, ok := node["required"].([]interface{}); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				requiredNames[s] = true
+			}
+		}
+	}
+
+	valueMap, _ := value.(map[string]interface{})
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		child, ok := properties[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		childValue, childPresent := valueMap[name]
+		annotateSchemaNode(joinPath(path, name), child, childValue, childPresent, requiredNames[name], secrets, fields)
+	}
+}
+
+// formFieldFor builds a form field from a leaf schema node
+func formFieldFor(path string, node map[string]interface{}, value interface{}, present bool, secrets map[string]bool) models.FormField {
+	field := models.FormField{
+		Path:   path,
+		Type:   schemaType(node["type"]),
+		Secret: secrets[strings.SplitN(path, ".", 2)[0]],
+	}
+
+	if title, ok := node["title"].(string); ok {
+		field.Title = title
+	}
+	if description, ok := node["description"].(string); ok {
+		field.Description = description
+	}
+	if enum, ok := node["enum"].([]interface{}); ok {
+		field.Enum = enum
+	}
+	if present {
+		field.Value = value
+	}
+
+	return field
+}
+
+// schemaType returns the primary type of a schema node, ignoring "null" in type unions
+func schemaType(raw interface{}) string {
+	switch t := raw.(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, candidate := range t {
+			if s, ok := candidate.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// checkSchemaDocument performs basic sanity checks on a JSON Schema document
+func checkSchemaDocument(schema json.RawMessage) error {
+	var root map[string]interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return invalidError("invalid schema: must be a JSON object: %w", err)
+	}
+
+	if schemaType, ok := root["type"]; ok && schemaType != "object" {
+		return invalidError("invalid schema: root type must be \"object\"")
+	}
+
+	return nil
+}
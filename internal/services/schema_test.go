@@ -0,0 +1,50 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildFormSchema(t *testing.T) {
+	schema := json.RawMessage(`{
+		"type": "object",
+		"required": ["timeout"],
+		"properties": {
+			"timeout": {"type": "integer", "title": "Timeout"},
+			"api_token": {"type": "string"},
+			"database": {"type": "object", "properties": {"host": {"type": "string"}, "password": {"type": "string"}}}
+		}
+	}`)
+	config := json.RawMessage(`{"timeout": 30, "api_token": "tok-plaintext", "database": {"host": "db", "password": "pw-plaintext"}}`)
+
+	annotated, fields, err := BuildFormSchema(schema, config, []string{"api_token", "database"})
+	require.NoError(t, err)
+
+	t.Run("values are filled in", func(t *testing.T) {
+		require.Len(t, fields, 4)
+		assert.Equal(t, "api_token", fields[0].Path)
+		assert.Equal(t, "timeout", fields[3].Path)
+		assert.True(t, fields[3].Required)
+		assert.Equal(t, json.Number("30"), fields[3].Value)
+		assert.Contains(t, string(annotated), `"default":30`)
+	})
+
+	t.Run("secret values never appear", func(t *testing.T) {
+		output, err := json.Marshal(fields)
+		require.NoError(t, err)
+		for _, document := range []string{string(annotated), string(output)} {
+			assert.NotContains(t, document, "tok-plaintext")
+			assert.NotContains(t, document, "pw-plaintext")
+			assert.NotContains(t, document, `"db"`)
+		}
+
+		for _, field := range fields[:3] {
+			assert.True(t, field.Secret, field.Path)
+			assert.Equal(t, MaskedValue, field.Value, field.Path)
+		}
+		assert.False(t, fields[3].Secret)
+	})
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"remote-config-system/internal/models"
+)
+
+// SearchConfigurations finds environments whose active configuration references a key,
+// a value, or a value under a specific key. Keys may be dot-notated paths into nested
+// objects; values are matched exactly, and also as JSON scalars when they parse as one.
+// Encrypted secret values never match.
+func (s *ConfigService) SearchConfigurations(key, value string, params models.PaginationParams) (*models.PaginatedResponse, error) {
+	jsonPath, err := buildSearchPath(key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	results, totalCount, err := s.repos.ConfigVersions.SearchActive(jsonPath, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search configurations: %w", err)
+	}
+
+	response := models.NewPaginatedResponse(results, params.Page, params.PageSize, totalCount)
+	return &response, nil
+}
+
+// buildSearchPath builds a SQL/JSON path expression matching the given key and value.
+// Every literal is JSON-encoded so that user input cannot alter the expression.
+func buildSearchPath(key, value string) (string, error) {
+	if key == "" && value == "" {
+		return "", invalidError("invalid search: key or value is required")
+	}
+
+	path := "$.**"
+	if key != "" {
+		var segments []string
+		for _, segment := range strings.Split(key, ".") {
+			if segment == "" {
+				return "", invalidError("invalid search: malformed key path '%s'", key)
+			}
+			encoded, _ := json.Marshal(segment)
+			segments = append(segments, string(encoded))
+		}
+		path = "$." + strings.Join(segments, ".")
+	}
+
+	if value == "" {
+		return path, nil
+	}
+
+	encoded, _ := json.Marshal(value)
+	condition := "@ == " + string(encoded)
+
+	// Also match non-string scalars such as numbers and booleans
+	var scalar interface{}
+	if err := json.Unmarshal([]byte(value), &scalar); err == nil {
+		switch scalar.(type) {
+		case float64, bool:
+			condition += " || @ == " + value
+		}
+	}
+
+	return fmt.Sprintf("%s ? (%s)", path, condition), nil
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSearchPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		value    string
+		expected string
+	}{
+		{"key only", "database_url", "", `$."database_url"`},
+		{"nested key", "db.host", "", `$."db"."host"`},
+		{"value only", "", "old-host", `$.** ? (@ == "old-host")`},
+		{"key and value", "db.host", "old-host", `$."db"."host" ? (@ == "old-host")`},
+		{"numeric value", "timeout", "30", `$."timeout" ? (@ == "30" || @ == 30)`},
+		{"boolean value", "", "true", `$.** ? (@ == "true" || @ == true)`},
+		{"quotes are escaped", `we"ird`, `x") || (@ == "y`, `$."we\"ird" ? (@ == "x\") || (@ == \"y")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := buildSearchPath(tt.key, tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, path)
+		})
+	}
+
+	t.Run("empty search", func(t *testing.T) {
+		_, err := buildSearchPath("", "")
+		assert.Error(t, err)
+	})
+
+	t.Run("malformed key path", func(t *testing.T) {
+		_, err := buildSearchPath("db..host", "")
+		assert.Error(t, err)
+	})
+}
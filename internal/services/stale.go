@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"remote-config-system/internal/models"
+)
+
+// DefaultStaleDays is the default number of days after which an environment whose
+// configuration has not changed or has not been read is reported as stale
+const DefaultStaleDays = 90
+
+// DefaultStaleCheckInterval is the default time between background stale configuration checks
+const DefaultStaleCheckInterval = time.Hour
+
+// staleCheck keeps the report of the latest stale configuration check
+type staleCheck struct {
+	mu     sync.Mutex
+	report *models.StaleConfigReport
+}
+
+// CheckStaleConfigs flags the environments whose active configuration has not changed for
+// longer than the stale threshold, and those that have not been read with their
+// application's API key for as long. The report is kept for StaleConfigReport.
+func (s *ConfigService) CheckStaleConfigs() (*models.StaleConfigReport, error) {
+	activity, err := s.repos.Environments.ListActivity()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check stale configurations: %w", err)
+	}
+
+	// Reads are only counted when usage is tracked in Redis
+	report := findStaleEnvironments(activity, s.staleDays(), s.cache != nil, time.Now())
+
+	s.stale.mu.Lock()
+	s.stale.report = report
+	s.stale.mu.Unlock()
+
+	return report, nil
+}
+
+// StaleConfigReport returns the report of the latest stale configuration check, running
+// the check if none has run yet
+func (s *ConfigService) StaleConfigReport() (*models.StaleConfigReport, error) {
+	s.stale.mu.Lock()
+	report := s.stale.report
+	s.stale.mu.Unlock()
+
+	if report != nil {
+		return report, nil
+	}
+	return s.CheckStaleConfigs()
+}
+
+// findStaleEnvironments flags environments unchanged or unread since the threshold.
+// Environments created within the threshold are not reported as unread.
+func findStaleEnvironments(activity []models.EnvironmentActivity, days int, usageTracked bool, now time.Time) *models.StaleConfigReport {
+	report := &models.StaleConfigReport{
+		CheckedAt:     now,
+		ThresholdDays: days,
+		UsageTracked:  usageTracked,
+		Environments:  []models.StaleEnvironment{},
+	}
+
+	cutoff := now.AddDate(0, 0, -days)
+	for _, env := range activity {
+		var reasons []string
+		if env.LastChangedAt.Before(cutoff) {
+			reasons = append(reasons, models.StaleReasonUnchanged)
+			report.Unchanged++
+		}
+		if usageTracked && env.CreatedAt.Before(cutoff) && (env.LastReadAt == nil || env.LastReadAt.Before(cutoff)) {
+			reasons = append(reasons, models.StaleReasonUnread)
+			report.Unread++
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		report.Environments = append(report.Environments, models.StaleEnvironment{
+			Organization:  env.Organization,
+			Application:   env.Application,
+			Environment:   env.Environment,
+			ActiveVersion: env.ActiveVersion,
+			Reasons:       reasons,
+			LastChangedAt: env.LastChangedAt,
+			LastReadAt:    env.LastReadAt,
+		})
+	}
+
+	return report
+}
+
+// staleDays returns the configured stale threshold in days
+func (s *ConfigService) staleDays() int {
+	if s.config == nil || s.config.StaleDays <= 0 {
+		return DefaultStaleDays
+	}
+	return s.config.StaleDays
+}
+
+// RunStaleCheck checks for stale configurations at the configured interval until ctx is
+// done, logging a warning when environments are flagged
+func (s *ConfigService) RunStaleCheck(ctx context.Context) {
+	interval := s.config.StaleCheckInterval
+	if interval <= 0 {
+		interval = DefaultStaleCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := s.CheckStaleConfigs()
+			if err != nil {
+				log.Printf("Stale configuration check failed: %v", err)
+				continue
+			}
+			if len(report.Environments) > 0 {
+				log.Printf("WARNING: %d environments unchanged and %d unread for more than %d days, see /admin/stats/stale",
+					report.Unchanged, report.Unread, report.ThresholdDays)
+			}
+		}
+	}
+}
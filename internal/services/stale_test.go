@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindStaleEnvironments(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -120)
+	recent := now.AddDate(0, 0, -3)
+
+	activity := []models.EnvironmentActivity{
+		{Organization: "acme", Application: "web", Environment: "active", ActiveVersion: 4, CreatedAt: old, LastChangedAt: recent, LastReadAt: &recent},
+		{Organization: "acme", Application: "web", Environment: "abandoned", ActiveVersion: 1, CreatedAt: old, LastChangedAt: old},
+		{Organization: "acme", Application: "web", Environment: "stable", ActiveVersion: 2, CreatedAt: old, LastChangedAt: old, LastReadAt: &recent},
+		{Organization: "acme", Application: "web", Environment: "forgotten", ActiveVersion: 9, CreatedAt: old, LastChangedAt: recent, LastReadAt: &old},
+		{Organization: "acme", Application: "web", Environment: "new", ActiveVersion: 1, CreatedAt: recent, LastChangedAt: recent},
+	}
+
+	t.Run("unchanged and unread environments are flagged", func(t *testing.T) {
+		report := findStaleEnvironments(activity, 90, true, now)
+
+		assert.Equal(t, 90, report.ThresholdDays)
+		assert.Equal(t, 2, report.Unchanged)
+		assert.Equal(t, 2, report.Unread)
+		require.Len(t, report.Environments, 3)
+
+		assert.Equal(t, "abandoned", report.Environments[0].Environment)
+		assert.Equal(t, []string{models.StaleReasonUnchanged, models.StaleReasonUnread}, report.Environments[0].Reasons)
+		assert.Nil(t, report.Environments[0].LastReadAt)
+
+		// A configuration that is still read is only flagged as unchanged
+		assert.Equal(t, "stable", report.Environments[1].Environment)
+		assert.Equal(t, []string{models.StaleReasonUnchanged}, report.Environments[1].Reasons)
+
+		assert.Equal(t, "forgotten", report.Environments[2].Environment)
+		assert.Equal(t, []string{models.StaleReasonUnread}, report.Environments[2].Reasons)
+	})
+
+	t.Run("without usage tracking only unchanged environments are flagged", func(t *testing.T) {
+		report := findStaleEnvironments(activity, 90, false, now)
+
+		assert.False(t, report.UsageTracked)
+		assert.Zero(t, report.Unread)
+		assert.Len(t, report.Environments, 2)
+	})
+
+	t.Run("a longer threshold flags nothing", func(t *testing.T) {
+		report := findStaleEnvironments(activity, 365, true, now)
+		assert.NotNil(t, report.Environments)
+		assert.Empty(t, report.Environments)
+	})
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"log"
+	"regexp"
+	"time"
+
+	"remote-config-system/internal/models"
+)
+
+// tagNamePattern restricts tag names to labels such as "canary" or "v2.3.0"
+var tagNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,99}$`)
+
+// ListTags retrieves the tags of an environment
+func (s *ConfigService) ListTags(orgSlug, appSlug, envSlug string) ([]models.ConfigTag, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	return s.repos.ConfigTags.ListByEnvironment(env.ID)
+}
+
+// SetTag points a tag at a configuration version, creating the tag if it does not exist
+// yet. It reports whether the tag was created. Creating and moving tags is logged.
+func (s *ConfigService) SetTag(orgSlug, appSlug, envSlug, name string, req *models.SetTagRequest) (*models.ConfigTag, bool, error) {
+	if !tagNamePattern.MatchString(name) {
+		return nil, false, invalidError("invalid tag name: %q (use letters, digits, '.', '_' and '-')", name)
+	}
+
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, false, lookupError("environment not found", err)
+	}
+
+	if _, err := s.repos.ConfigVersions.GetByVersion(env.ID, req.Version); err != nil {
+		return nil, false, lookupError("configuration version not found", err)
+	}
+
+	// Proposed versions can only be served once they have been approved
+	if pending, err := s.repos.PendingChanges.GetByVersion(env.ID, req.Version); err == nil && pending.Status != models.PendingStatusApproved {
+		return nil, false, conflictError("target version not approved: version %d is %s", req.Version, pending.Status)
+	}
+
+	var previousVersion *int
+	tag, err := s.repos.ConfigTags.GetByName(env.ID, name)
+	if err != nil {
+		tag = &models.ConfigTag{
+			EnvID:     env.ID,
+			Name:      name,
+			Version:   req.Version,
+			UpdatedBy: req.UpdatedBy,
+		}
+		if err := s.repos.ConfigTags.Create(tag); err != nil {
+			return nil, false, err
+		}
+	} else {
+		if tag.Version == req.Version {
+			return tag, false, nil
+		}
+		previous := tag.Version
+		previousVersion = &previous
+
+		tag.Version = req.Version
+		tag.UpdatedBy = req.UpdatedBy
+		if err := s.repos.ConfigTags.Move(tag); err != nil {
+			return nil, false, err
+		}
+	}
+
+	action := "tag_create"
+	if previousVersion != nil {
+		action = "tag_move"
+	}
+	s.logTagChange(env, tag, action, previousVersion, req.UpdatedBy)
+
+	return tag, previousVersion == nil, nil
+}
+
+// DeleteTag removes a tag from an environment
+func (s *ConfigService) DeleteTag(orgSlug, appSlug, envSlug, name string, deletedBy *string) error {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return lookupError("environment not found", err)
+	}
+
+	tag, err := s.repos.ConfigTags.GetByName(env.ID, name)
+	if err != nil {
+		return recordError(err)
+	}
+
+	if err := s.repos.ConfigTags.Delete(tag.ID); err != nil {
+		return err
+	}
+
+	s.logTagChange(env, tag, "tag_delete", &tag.Version, deletedBy)
+	return nil
+}
+
+// GetConfigurationByTag retrieves the configuration version a tag points at
+func (s *ConfigService) GetConfigurationByTag(orgSlug, appSlug, envSlug, name string) (*models.ConfigResponse, error) {
+	env, err := s.repos.Environments.GetBySlug(orgSlug, appSlug, envSlug)
+	if err != nil {
+		return nil, lookupError("environment not found", err)
+	}
+
+	tag, err := s.repos.ConfigTags.GetByName(env.ID, name)
+	if err != nil {
+		return nil, recordError(err)
+	}
+
+	response, err := s.GetConfigurationVersion(orgSlug, appSlug, envSlug, tag.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tagged versions are layered over the current application defaults, like the active one
+	defaults, err := s.loadDefaults(env.AppID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyDefaults(response, defaults); err != nil {
+		return nil, err
+	}
+
+	// References resolve against the current versions of the referenced environments
+	if err := s.resolveReferences(response, s.referenceLoader(env.Application)); err != nil {
+		return nil, err
+	}
+	if response.References != nil {
+		if response, err = s.decryptResponse(response); err != nil {
+			return nil, err
+		}
+	}
+
+	response.Tag = tag.Name
+	return response, nil
+}
+
+// GetConfigurationByAPIKeyAndTag retrieves the configuration version a tag points at for an
+// application authenticated by its API key or a token
+func (s *ConfigService) GetConfigurationByAPIKeyAndTag(app *models.Application, envSlug, name string) (*models.ConfigResponse, error) {
+	return s.GetConfigurationByTag(app.Organization.Slug, app.Slug, envSlug, name)
+}
+
+// logTagChange records a tag change in the change log and notifies subscribers of the environment
+func (s *ConfigService) logTagChange(env *models.Environment, tag *models.ConfigTag, action string, versionFrom *int, createdBy *string) {
+	change := &models.ConfigChange{
+		EnvID:       env.ID,
+		VersionFrom: versionFrom,
+		VersionTo:   tag.Version,
+		Action:      action,
+		CreatedBy:   createdBy,
+		Tag:         &tag.Name,
+	}
+
+	if err := s.repos.ConfigChanges.Create(change); err != nil {
+		log.Printf("Failed to log tag change: %v", err)
+	}
+
+	if s.sseService != nil {
+		s.sseService.BroadcastCustomEvent(env.Application.Organization.Slug, env.Application.Slug, env.Slug, "tag_update", models.TagUpdateEvent{
+			Organization: env.Application.Organization.Slug,
+			Application:  env.Application.Slug,
+			Environment:  env.Slug,
+			Tag:          tag.Name,
+			Version:      tag.Version,
+			Action:       action,
+			UpdatedAt:    time.Now(),
+		})
+	}
+}
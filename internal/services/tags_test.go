@@ -0,0 +1,16 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagNamePattern(t *testing.T) {
+	for _, name := range []string{"canary", "v2.3.0", "release_2024-01", "7"} {
+		assert.True(t, tagNamePattern.MatchString(name), name)
+	}
+	for _, name := range []string{"", "-canary", ".hidden", "with space", "a/b"} {
+		assert.False(t, tagNamePattern.MatchString(name), name)
+	}
+}
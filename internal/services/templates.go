@@ -0,0 +1,299 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"remote-config-system/internal/models"
+)
+
+// templateNamePattern restricts template names to labels such as "web-service" or "worker.v2"
+var templateNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,99}$`)
+
+// templateVariablePattern matches a {{variable}} placeholder in a template string value
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// ListTemplates retrieves the configuration templates of an organization
+func (s *ConfigService) ListTemplates(orgSlug string) ([]models.ConfigTemplate, error) {
+	org, err := s.repos.Organizations.GetBySlug(orgSlug)
+	if err != nil {
+		return nil, lookupError("organization not found", err)
+	}
+
+	templates, err := s.repos.Templates.ListByOrganization(org.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		if err := describeTemplate(&templates[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return templates, nil
+}
+
+// GetTemplate retrieves a configuration template of an organization
+func (s *ConfigService) GetTemplate(orgSlug, name string) (*models.ConfigTemplate, error) {
+	org, err := s.repos.Organizations.GetBySlug(orgSlug)
+	if err != nil {
+		return nil, lookupError("organization not found", err)
+	}
+
+	template, err := s.repos.Templates.GetByName(org.ID, name)
+	if err != nil {
+		return nil, recordError(err)
+	}
+	if err := describeTemplate(template); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// SetTemplate stores a configuration template, creating it if the organization has no
+// template of that name yet. It reports whether the template was created.
+func (s *ConfigService) SetTemplate(orgSlug, name string, req *models.SetConfigTemplateRequest) (*models.ConfigTemplate, bool, error) {
+	if !templateNamePattern.MatchString(name) {
+		return nil, false, invalidError("invalid template name: %q (use letters, digits, '.', '_' and '-')", name)
+	}
+
+	// Templates are held to the limits of the configurations they become
+	if err := s.checkConfigSize(req.Template); err != nil {
+		return nil, false, err
+	}
+	if err := s.checkConfigDepth(req.Template); err != nil {
+		return nil, false, err
+	}
+	if err := s.checkConfigKeys(req.Template); err != nil {
+		return nil, false, err
+	}
+	document, err := decodeConfigValue(req.Template)
+	if err != nil {
+		return nil, false, invalidError("invalid template: %w", err)
+	}
+	if _, ok := document.(map[string]interface{}); !ok {
+		return nil, false, invalidError("invalid template: must be a JSON object")
+	}
+
+	org, err := s.repos.Organizations.GetBySlug(orgSlug)
+	if err != nil {
+		return nil, false, lookupError("organization not found", err)
+	}
+
+	created := false
+	template, err := s.repos.Templates.GetByName(org.ID, name)
+	if err != nil {
+		created = true
+		template = &models.ConfigTemplate{OrgID: org.ID, Name: name}
+	}
+	template.Description = req.Description
+	template.Template = req.Template
+	template.UpdatedBy = req.UpdatedBy
+
+	if created {
+		err = s.repos.Templates.Create(template)
+	} else {
+		err = s.repos.Templates.Update(template)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := describeTemplate(template); err != nil {
+		return nil, false, err
+	}
+	return template, created, nil
+}
+
+// DeleteTemplate removes a configuration template. Configurations created from it are
+// not affected.
+func (s *ConfigService) DeleteTemplate(orgSlug, name string) error {
+	org, err := s.repos.Organizations.GetBySlug(orgSlug)
+	if err != nil {
+		return lookupError("organization not found", err)
+	}
+
+	template, err := s.repos.Templates.GetByName(org.ID, name)
+	if err != nil {
+		return recordError(err)
+	}
+
+	return s.repos.Templates.Delete(template.ID)
+}
+
+// InstantiateTemplate substitutes the variables of a template of the environment's
+// organization and stores the result as a new configuration version of the environment.
+// The update goes through the same checks, approvals and rollouts as any other.
+func (s *ConfigService) InstantiateTemplate(orgSlug, appSlug, envSlug string, req *models.InstantiateTemplateRequest, dryRun bool) (*models.ConfigResponse, error) {
+	template, err := s.GetTemplate(orgSlug, req.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := substituteTemplate(template.Template, req.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UpdateConfiguration(orgSlug, appSlug, envSlug, &models.CreateConfigRequest{
+		Config:            config,
+		CreatedBy:         req.CreatedBy,
+		Message:           req.Message,
+		RolloutPercentage: req.RolloutPercentage,
+		AdminToken:        req.AdminToken,
+	}, dryRun)
+}
+
+// describeTemplate lists the variables of a template
+func describeTemplate(template *models.ConfigTemplate) error {
+	document, err := decodeConfigValue(template.Template)
+	if err != nil {
+		return fmt.Errorf("failed to decode template: %w", err)
+	}
+
+	names := make(map[string]bool)
+	collectTemplateVariables(document, names)
+
+	template.Variables = make([]string, 0, len(names))
+	for name := range names {
+		template.Variables = append(template.Variables, name)
+	}
+	sort.Strings(template.Variables)
+	return nil
+}
+
+// collectTemplateVariables adds the names of the placeholders in a value to names
+func collectTemplateVariables(value interface{}, names map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			collectTemplateVariables(child, names)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectTemplateVariables(child, names)
+		}
+	case string:
+		for _, match := range templateVariablePattern.FindAllStringSubmatch(v, -1) {
+			names[match[1]] = true
+		}
+	}
+}
+
+// substituteTemplate replaces the placeholders of a template with the values of its
+// variables. A string that is a single placeholder takes the variable's value, of any
+// type; placeholders within text take the text of a string, number or boolean. Every
+// variable the template uses must be given, and no other.
+func substituteTemplate(template json.RawMessage, variables map[string]json.RawMessage) (json.RawMessage, error) {
+	document, err := decodeConfigValue(template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode template: %w", err)
+	}
+
+	used := make(map[string]bool)
+	collectTemplateVariables(document, used)
+
+	var missing, unknown []string
+	for name := range used {
+		if _, ok := variables[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	for name := range variables {
+		if !used[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	var problems []string
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		problems = append(problems, "missing "+strings.Join(missing, ", "))
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		problems = append(problems, "unknown "+strings.Join(unknown, ", "))
+	}
+	if len(problems) > 0 {
+		return nil, validationError("invalid template variables: %s", strings.Join(problems, "; "))
+	}
+
+	values := make(map[string]interface{}, len(variables))
+	for name, raw := range variables {
+		if values[name], err = decodeConfigValue(raw); err != nil {
+			return nil, validationError("invalid template variables: %s: %w", name, err)
+		}
+	}
+
+	substituted, err := substituteValue(document, values)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(substituted); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// substituteValue returns a copy of a template value with its placeholders replaced
+func substituteValue(value interface{}, values map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			substituted, err := substituteValue(child, values)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = substituted
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, child := range v {
+			substituted, err := substituteValue(child, values)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = substituted
+		}
+		return result, nil
+	case string:
+		if match := templateVariablePattern.FindStringSubmatchIndex(v); match != nil && match[0] == 0 && match[1] == len(v) {
+			return values[v[match[2]:match[3]]], nil
+		}
+
+		var embedErr error
+		text := templateVariablePattern.ReplaceAllStringFunc(v, func(placeholder string) string {
+			name := templateVariablePattern.FindStringSubmatch(placeholder)[1]
+			switch value := values[name].(type) {
+			case string:
+				return value
+			case json.Number:
+				return value.String()
+			case bool:
+				return fmt.Sprint(value)
+			default:
+				if embedErr == nil {
+					embedErr = validationError("invalid template variables: %s must be a string, number or boolean to be used within text", name)
+				}
+				return placeholder
+			}
+		})
+		if embedErr != nil {
+			return nil, embedErr
+		}
+		return text, nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateNamePattern(t *testing.T) {
+	for _, name := range []string{"web-service", "worker.v2", "base_config", "7"} {
+		assert.True(t, templateNamePattern.MatchString(name), name)
+	}
+	for _, name := range []string{"", "-web", ".hidden", "with space", "a/b"} {
+		assert.False(t, templateNamePattern.MatchString(name), name)
+	}
+}
+
+func TestDescribeTemplate(t *testing.T) {
+	template := &models.ConfigTemplate{
+		Template: json.RawMessage(`{"db": {"host": "{{db_host}}", "port": "{{ db_port }}"}, "hosts": ["{{db_host}}-replica"], "name": "plain"}`),
+	}
+
+	require.NoError(t, describeTemplate(template))
+	assert.Equal(t, []string{"db_host", "db_port"}, template.Variables)
+}
+
+func TestSubstituteTemplate(t *testing.T) {
+	template := json.RawMessage(`{"db": {"host": "{{host}}", "port": "{{port}}"}, "url": "https://{{host}}:{{port}}/<app>", "features": "{{features}}"}`)
+
+	t.Run("whole strings take the value and text takes its text", func(t *testing.T) {
+		config, err := substituteTemplate(template, map[string]json.RawMessage{
+			"host":     json.RawMessage(`"db.internal"`),
+			"port":     json.RawMessage(`5432`),
+			"features": json.RawMessage(`{"checkout": true}`),
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"db": {"host": "db.internal", "port": 5432}, "url": "https://db.internal:5432/<app>", "features": {"checkout": true}}`, string(config))
+		assert.Contains(t, string(config), "<app>", "HTML characters are not escaped")
+	})
+
+	t.Run("missing and unknown variables", func(t *testing.T) {
+		_, err := substituteTemplate(template, map[string]json.RawMessage{
+			"host":  json.RawMessage(`"db.internal"`),
+			"extra": json.RawMessage(`1`),
+		})
+		require.Error(t, err)
+		assert.Equal(t, "invalid template variables: missing features, port; unknown extra", err.Error())
+	})
+
+	t.Run("objects cannot be used within text", func(t *testing.T) {
+		_, err := substituteTemplate(template, map[string]json.RawMessage{
+			"host":     json.RawMessage(`"db.internal"`),
+			"port":     json.RawMessage(`{"primary": 5432}`),
+			"features": json.RawMessage(`{}`),
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "port must be a string, number or boolean")
+	})
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MaxTreeDepth is the depth of a complete organization tree: applications, then environments
+const MaxTreeDepth = 2
+
+// GetOrganizationTree retrieves the hierarchy of an organization down to depth levels:
+// 0 for the organization alone, 1 to add its applications and 2 to add their environments
+// and active versions. The tree is assembled from at most two queries.
+func (s *ConfigService) GetOrganizationTree(orgSlug string, depth int) (*models.OrganizationTree, error) {
+	if depth < 0 || depth > MaxTreeDepth {
+		return nil, fmt.Errorf("invalid depth: %d (must be between 0 and %d)", depth, MaxTreeDepth)
+	}
+
+	if depth == 0 {
+		org, err := s.GetOrganization(orgSlug)
+		if err != nil {
+			return nil, err
+		}
+		return &models.OrganizationTree{ID: org.ID, Name: org.Name, Slug: org.Slug}, nil
+	}
+
+	org, err := s.GetOrganizationWithApplications(orgSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	var environments map[uuid.UUID][]models.EnvironmentTreeNode
+	if depth > 1 {
+		if environments, err = s.repos.Environments.ListTreeByOrganization(org.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	tree := &models.OrganizationTree{
+		ID:           org.ID,
+		Name:         org.Name,
+		Slug:         org.Slug,
+		Applications: make([]models.ApplicationTreeNode, len(org.Applications)),
+	}
+	for i, app := range org.Applications {
+		node := models.ApplicationTreeNode{ID: app.ID, Name: app.Name, Slug: app.Slug}
+		if depth > 1 {
+			node.Environments = environments[app.ID]
+			if node.Environments == nil {
+				node.Environments = []models.EnvironmentTreeNode{}
+			}
+		}
+		tree.Applications[i] = node
+	}
+
+	return tree, nil
+}
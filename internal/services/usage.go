@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"remote-config-system/internal/cache"
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Periods of usage reports, in days
+const (
+	DefaultUsageDays = 30
+	MaxUsageDays     = 365
+)
+
+// DefaultUsageFlushInterval is the default time between flushes of the usage counters
+const DefaultUsageFlushInterval = 60 * time.Second
+
+// RecordAPIKeyUsage counts a request of an API-key authenticated application for an
+// environment towards its usage reports, as the HTTP API's usage tracker does. Without an
+// available cache, requests are not counted.
+func (s *ConfigService) RecordAPIKeyUsage(app *models.Application, envSlug string) error {
+	if !s.cacheAvailable() {
+		return nil
+	}
+	return s.cache.RecordUsage(app.ID.String(), envSlug, time.Now())
+}
+
+// FlushUsage adds the usage counted in Redis since the last flush to the stored usage and
+// returns the number of requests stored. Counts that cannot be stored stay in Redis and
+// are stored by the next flush.
+func (s *ConfigService) FlushUsage() (int64, error) {
+	if s.cache == nil {
+		return 0, nil
+	}
+
+	counts, err := s.cache.DrainUsage()
+	if err != nil {
+		return 0, err
+	}
+
+	usage := make([]models.APIKeyUsage, 0, len(counts))
+	var total int64
+	for _, count := range counts {
+		appID, err := uuid.Parse(count.AppID)
+		if err != nil {
+			continue
+		}
+		usage = append(usage, usageFromCache(appID, count))
+		total += count.Requests
+	}
+
+	if len(usage) > 0 {
+		if err := s.repos.APIKeyUsage.Add(usage); err != nil {
+			return 0, err
+		}
+	}
+	if err := s.cache.ClearDrainedUsage(); err != nil {
+		// The counts would be stored again by the next flush
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// usageFromCache converts a usage counter of the cache to its stored form
+func usageFromCache(appID uuid.UUID, count cache.UsageCount) models.APIKeyUsage {
+	return models.APIKeyUsage{
+		AppID:       appID,
+		Environment: count.Environment,
+		Day:         count.Day,
+		Requests:    count.Requests,
+		LastSeenAt:  count.LastSeenAt,
+	}
+}
+
+// RunUsageFlusher flushes the API key usage and organization read counters at the
+// configured interval until ctx is done
+func (s *ConfigService) RunUsageFlusher(ctx context.Context) {
+	interval := s.config.UsageFlushInterval
+	if interval <= 0 {
+		interval = DefaultUsageFlushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.FlushUsage(); err != nil {
+				log.Printf("API key usage flush failed: %v", err)
+			}
+			if _, err := s.FlushOrganizationReads(); err != nil {
+				log.Printf("Organization reads flush failed: %v", err)
+			}
+		}
+	}
+}
+
+// GetApplicationUsage reports the requests an application made with its API key over the
+// last days, including today. Requests of the last flush interval are not included yet.
+func (s *ConfigService) GetApplicationUsage(orgSlug, appSlug string, days int) (*models.ApplicationUsage, error) {
+	app, err := s.repos.Applications.GetBySlug(orgSlug, appSlug)
+	if err != nil {
+		return nil, lookupError("application not found", err)
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+	usage, err := s.repos.APIKeyUsage.ListByApplication(app.ID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	report := summarizeUsage(usage)
+	report.Organization = orgSlug
+	report.Application = appSlug
+	report.Days = days
+	return report, nil
+}
+
+// summarizeUsage totals usage counts ordered by day, per environment and per day
+func summarizeUsage(usage []models.APIKeyUsage) *models.ApplicationUsage {
+	report := &models.ApplicationUsage{
+		Environments: []models.EnvironmentUsage{},
+		Daily:        []models.DailyUsage{},
+	}
+
+	environments := make(map[string]*models.EnvironmentUsage)
+	for _, count := range usage {
+		report.Requests += count.Requests
+		if report.LastSeenAt == nil || count.LastSeenAt.After(*report.LastSeenAt) {
+			lastSeen := count.LastSeenAt
+			report.LastSeenAt = &lastSeen
+		}
+
+		env, ok := environments[count.Environment]
+		if !ok {
+			env = &models.EnvironmentUsage{Environment: count.Environment}
+			environments[count.Environment] = env
+		}
+		env.Requests += count.Requests
+		if count.LastSeenAt.After(env.LastSeenAt) {
+			env.LastSeenAt = count.LastSeenAt
+		}
+
+		date := count.Day.UTC().Format("2006-01-02")
+		if n := len(report.Daily); n == 0 || report.Daily[n-1].Date != date {
+			report.Daily = append(report.Daily, models.DailyUsage{Date: date, Environments: map[string]int64{}})
+		}
+		day := &report.Daily[len(report.Daily)-1]
+		day.Requests += count.Requests
+		day.Environments[count.Environment] += count.Requests
+	}
+
+	for _, env := range environments {
+		report.Environments = append(report.Environments, *env)
+	}
+	sort.Slice(report.Environments, func(i, j int) bool {
+		return report.Environments[i].Environment < report.Environments[j].Environment
+	})
+
+	return report
+}
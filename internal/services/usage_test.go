@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeUsage(t *testing.T) {
+	appID := uuid.New()
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	at := func(days, hours int) time.Time { return day.AddDate(0, 0, days).Add(time.Duration(hours) * time.Hour) }
+
+	report := summarizeUsage([]models.APIKeyUsage{
+		{AppID: appID, Environment: "dev", Day: day, Requests: 2, LastSeenAt: at(0, 9)},
+		{AppID: appID, Environment: "prod", Day: day, Requests: 10, LastSeenAt: at(0, 23)},
+		{AppID: appID, Environment: "prod", Day: day.AddDate(0, 0, 2), Requests: 5, LastSeenAt: at(2, 8)},
+	})
+
+	assert.Equal(t, int64(17), report.Requests)
+	require.NotNil(t, report.LastSeenAt)
+	assert.Equal(t, at(2, 8), *report.LastSeenAt)
+
+	assert.Equal(t, []models.EnvironmentUsage{
+		{Environment: "dev", Requests: 2, LastSeenAt: at(0, 9)},
+		{Environment: "prod", Requests: 15, LastSeenAt: at(2, 8)},
+	}, report.Environments)
+
+	assert.Equal(t, []models.DailyUsage{
+		{Date: "2026-10-15", Requests: 12, Environments: map[string]int64{"dev": 2, "prod": 10}},
+		{Date: "2026-10-17", Requests: 5, Environments: map[string]int64{"prod": 5}},
+	}, report.Daily)
+
+	t.Run("unused keys", func(t *testing.T) {
+		report := summarizeUsage(nil)
+		assert.Zero(t, report.Requests)
+		assert.Nil(t, report.LastSeenAt)
+		assert.NotNil(t, report.Environments)
+		assert.NotNil(t, report.Daily)
+	})
+}
+
+func TestConfigService_RecordAPIKeyUsage(t *testing.T) {
+	_, redisClient := newTestCache(t)
+	service := &ConfigService{config: &Config{}, cache: redisClient}
+	app := &models.Application{ID: uuid.New()}
+
+	require.NoError(t, service.RecordAPIKeyUsage(app, "prod"))
+	require.NoError(t, service.RecordAPIKeyUsage(app, "prod"))
+
+	counts, err := redisClient.DrainUsage()
+	require.NoError(t, err)
+	require.Len(t, counts, 1)
+	assert.Equal(t, app.ID.String(), counts[0].AppID)
+	assert.Equal(t, "prod", counts[0].Environment)
+	assert.Equal(t, int64(2), counts[0].Requests)
+
+	// Without a cache, requests are not counted
+	assert.NoError(t, (&ConfigService{config: &Config{}}).RecordAPIKeyUsage(app, "prod"))
+}
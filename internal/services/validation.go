@@ -0,0 +1,258 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"remote-config-system/internal/models"
+)
+
+// Validation check names
+const (
+	CheckSyntax = "syntax"
+	CheckSize   = "size"
+	CheckDepth  = "depth"
+	CheckKeys   = "keys"
+	CheckRules  = "rules"
+)
+
+// Default configuration document limits
+const (
+	DefaultMaxConfigSize  = 1 << 20 // 1 MiB
+	DefaultMaxConfigDepth = 32
+	DefaultMaxConfigKeys  = 10000
+)
+
+// configCheck is a single step of the validation pipeline. It returns the issues found
+// in the proposed configuration for the given environment.
+type configCheck struct {
+	name string
+	run  func(env *models.Environment, config json.RawMessage) []models.ValidationIssue
+	// fatal checks stop the pipeline when they fail, as later checks depend on them
+	fatal bool
+}
+
+// validationPipeline returns the ordered checks run against proposed configurations
+func (s *ConfigService) validationPipeline() []configCheck {
+	return []configCheck{
+		{name: CheckSyntax, run: checkSyntax, fatal: true},
+		{name: CheckSize, run: s.checkSize},
+		{name: CheckDepth, run: s.checkDepth},
+		{name: CheckKeys, run: s.checkKeys},
+		{name: CheckBlobs, run: s.checkBlobs},
+		{name: CheckRules, run: s.checkRules},
+		{name: CheckGolden, run: s.checkGolden},
+		{name: CheckReferences, run: s.checkReferences},
+	}
+}
+
+// ValidateConfiguration runs the validation pipeline against a proposed configuration
+// for an environment without persisting anything
+func (s *ConfigService) ValidateConfiguration(env *models.Environment, config json.RawMessage) *models.ValidationResult {
+	result := &models.ValidationResult{Valid: true, Errors: []models.ValidationIssue{}}
+
+	for _, check := range s.validationPipeline() {
+		issues := check.run(env, config)
+		if len(issues) == 0 {
+			continue
+		}
+
+		result.Valid = false
+		result.Errors = append(result.Errors, issues...)
+		if check.fatal {
+			break
+		}
+	}
+
+	return result
+}
+
+// ValidateManifest validates a set of proposed configurations, one per environment,
+// returning a result for every item. Nothing is persisted.
+func (s *ConfigService) ValidateManifest(items []models.ManifestItem) *models.ManifestValidationResponse {
+	response := &models.ManifestValidationResponse{
+		Valid:   true,
+		Results: make([]models.ManifestItemResult, 0, len(items)),
+	}
+
+	for _, item := range items {
+		itemResult := models.ManifestItemResult{
+			Organization: item.Organization,
+			Application:  item.Application,
+			Environment:  item.Environment,
+			Status:       http.StatusOK,
+			Valid:        true,
+			Errors:       []models.ValidationIssue{},
+		}
+
+		env, err := s.repos.Environments.GetBySlug(item.Organization, item.Application, item.Environment)
+		if err != nil {
+			itemResult.Status = http.StatusNotFound
+			itemResult.Valid = false
+			itemResult.Errors = append(itemResult.Errors, models.ValidationIssue{
+				Check:   "environment",
+				Message: fmt.Sprintf("environment not found: %s/%s/%s", item.Organization, item.Application, item.Environment),
+			})
+		} else {
+			validation := s.ValidateConfiguration(env, item.Config)
+			itemResult.Valid = validation.Valid
+			itemResult.Errors = validation.Errors
+			if !validation.Valid {
+				itemResult.Status = http.StatusUnprocessableEntity
+			}
+		}
+
+		if !itemResult.Valid {
+			response.Valid = false
+		}
+		response.Results = append(response.Results, itemResult)
+	}
+
+	return response
+}
+
+// checkSyntax verifies that the configuration is well-formed JSON
+func checkSyntax(env *models.Environment, config json.RawMessage) []models.ValidationIssue {
+	if !json.Valid(config) {
+		return []models.ValidationIssue{{
+			Check:   CheckSyntax,
+			Message: "configuration is not valid JSON",
+		}}
+	}
+	return nil
+}
+
+// checkSize verifies that the configuration does not exceed the maximum document size
+func (s *ConfigService) checkSize(env *models.Environment, config json.RawMessage) []models.ValidationIssue {
+	if err := s.checkConfigSize(config); err != nil {
+		return []models.ValidationIssue{{Check: CheckSize, Message: err.Error()}}
+	}
+	return nil
+}
+
+// checkDepth verifies that the configuration does not exceed the maximum nesting depth
+func (s *ConfigService) checkDepth(env *models.Environment, config json.RawMessage) []models.ValidationIssue {
+	if err := s.checkConfigDepth(config); err != nil {
+		return []models.ValidationIssue{{Check: CheckDepth, Message: err.Error()}}
+	}
+	return nil
+}
+
+// checkKeys verifies that the configuration does not exceed the maximum number of keys
+func (s *ConfigService) checkKeys(env *models.Environment, config json.RawMessage) []models.ValidationIssue {
+	if err := s.checkConfigKeys(config); err != nil {
+		return []models.ValidationIssue{{Check: CheckKeys, Message: err.Error()}}
+	}
+	return nil
+}
+
+// checkConfigSize returns an error if the configuration exceeds the configured maximum size
+func (s *ConfigService) checkConfigSize(config json.RawMessage) error {
+	if len(config) > s.config.MaxConfigSize {
+		return tooLargeError("configuration too large: %d bytes exceeds the maximum of %d bytes", len(config), s.config.MaxConfigSize)
+	}
+	return nil
+}
+
+// checkConfigDepth returns an error if the configuration nests objects and arrays deeper
+// than the configured maximum
+func (s *ConfigService) checkConfigDepth(config json.RawMessage) error {
+	depth, err := jsonDepth(config)
+	if err != nil {
+		return invalidError("invalid JSON configuration: %w", err)
+	}
+	if depth > s.config.MaxConfigDepth {
+		return validationError("configuration too deeply nested: depth %d exceeds the maximum of %d", depth, s.config.MaxConfigDepth)
+	}
+	return nil
+}
+
+// checkConfigKeys returns an error if the objects of the configuration have more keys in
+// total than the configured maximum. Every key is marshaled again for each cache write and
+// each SSE client, so wide documents are as costly as deep ones.
+func (s *ConfigService) checkConfigKeys(config json.RawMessage) error {
+	if s.config.MaxConfigKeys <= 0 {
+		return nil
+	}
+
+	keys, err := jsonKeyCount(config)
+	if err != nil {
+		return invalidError("invalid JSON configuration: %w", err)
+	}
+	if keys > s.config.MaxConfigKeys {
+		return validationError("configuration has too many keys: %d keys exceed the maximum of %d", keys, s.config.MaxConfigKeys)
+	}
+	return nil
+}
+
+// jsonDepth returns the maximum nesting depth of objects and arrays in a JSON document.
+// Scalars have depth 0. The document is scanned as a token stream, without building it in memory.
+func jsonDepth(data json.RawMessage) (int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	depth, maxDepth := 0, 0
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return maxDepth, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if depth > maxDepth {
+				maxDepth = depth
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
+
+// jsonKeyCount returns the number of keys of all objects in a JSON document, nested ones
+// included. Like jsonDepth, it scans the document as a token stream.
+func jsonKeyCount(data json.RawMessage) (int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// The open objects and arrays; an object's next token is a key when expectKey is set
+	type level struct{ object, expectKey bool }
+	levels := []level{}
+	keys := 0
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return keys, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if token == json.Delim('}') || token == json.Delim(']') {
+			levels = levels[:len(levels)-1]
+			continue
+		}
+
+		if n := len(levels); n > 0 && levels[n-1].object {
+			if levels[n-1].expectKey {
+				keys++
+				levels[n-1].expectKey = false
+				continue
+			}
+			// The token starts the value of the key; the key after it comes next
+			levels[n-1].expectKey = true
+		}
+
+		switch token {
+		case json.Delim('{'):
+			levels = append(levels, level{object: true, expectKey: true})
+		case json.Delim('['):
+			levels = append(levels, level{})
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigService_ValidateConfiguration(t *testing.T) {
+	service := &ConfigService{config: &Config{MaxConfigSize: 64, MaxConfigDepth: 3, MaxConfigKeys: 3}}
+
+	t.Run("valid configuration", func(t *testing.T) {
+		result := service.ValidateConfiguration(nil, json.RawMessage(`{"timeout": 30}`))
+
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Errors)
+	})
+
+	t.Run("invalid JSON stops the pipeline", func(t *testing.T) {
+		result := service.ValidateConfiguration(nil, json.RawMessage(`{invalid`))
+
+		assert.False(t, result.Valid)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, CheckSyntax, result.Errors[0].Check)
+	})
+
+	t.Run("oversized configuration", func(t *testing.T) {
+		config := json.RawMessage(`{"blob": "` + strings.Repeat("x", 64) + `"}`)
+		result := service.ValidateConfiguration(nil, config)
+
+		assert.False(t, result.Valid)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, CheckSize, result.Errors[0].Check)
+		assert.Contains(t, result.Errors[0].Message, "maximum of 64 bytes")
+	})
+
+	t.Run("deeply nested configuration", func(t *testing.T) {
+		result := service.ValidateConfiguration(nil, json.RawMessage(`{"a":{"b":[{"c":1}]}}`))
+
+		assert.False(t, result.Valid)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, CheckDepth, result.Errors[0].Check)
+		assert.Contains(t, result.Errors[0].Message, "maximum of 3")
+	})
+
+	t.Run("configuration at the limits", func(t *testing.T) {
+		result := service.ValidateConfiguration(nil, json.RawMessage(`{"a":{"b":{"c":1}}}`))
+
+		assert.True(t, result.Valid)
+		assert.Empty(t, result.Errors)
+	})
+
+	t.Run("configuration with too many keys", func(t *testing.T) {
+		result := service.ValidateConfiguration(nil, json.RawMessage(`{"a":1,"b":[{"c":2,"d":3}]}`))
+
+		assert.False(t, result.Valid)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, CheckKeys, result.Errors[0].Check)
+		assert.Contains(t, result.Errors[0].Message, "4 keys exceed the maximum of 3")
+	})
+}
+
+func TestJSONDepth(t *testing.T) {
+	tests := []struct {
+		config   string
+		expected int
+	}{
+		{`42`, 0},
+		{`{}`, 1},
+		{`{"a": [1, 2], "b": {"c": true}}`, 2},
+		{`[[[[]]]]`, 4},
+		{`{"a": {"b": {}}, "c": 1}`, 3},
+	}
+
+	for _, tt := range tests {
+		depth, err := jsonDepth(json.RawMessage(tt.config))
+		require.NoError(t, err, tt.config)
+		assert.Equal(t, tt.expected, depth, tt.config)
+	}
+}
+
+func TestJSONKeyCount(t *testing.T) {
+	tests := []struct {
+		config   string
+		expected int
+	}{
+		{`42`, 0},
+		{`{}`, 0},
+		{`{"a": 1, "b": {"c": 2}}`, 3},
+		{`[{"a": 1}, {"b": 2}]`, 2},
+		{`{"a": [{"b": 1}], "c": []}`, 3},
+		{`{"a": "b", "c": ["d", "e"]}`, 2},
+	}
+
+	for _, tt := range tests {
+		count, err := jsonKeyCount(json.RawMessage(tt.config))
+		require.NoError(t, err, tt.config)
+		assert.Equal(t, tt.expected, count, tt.config)
+	}
+}
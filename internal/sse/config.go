@@ -0,0 +1,104 @@
+package sse
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Policies for clients whose channel is full when a message is broadcast
+const (
+	// SlowClientDropOldest discards the oldest queued message to make room for the new one
+	SlowClientDropOldest = "drop_oldest"
+	// SlowClientDisconnect unregisters the client
+	SlowClientDisconnect = "disconnect"
+)
+
+// DefaultClientBufferSize is the default number of messages queued per client. It holds
+// a full replay of the default SSE_REPLAY_BUFFER_SIZE updates with the welcome and initial
+// messages, and leaves room for bursts of updates, e.g. from a deploy script updating an
+// environment several times in a row, while costing a few kilobytes per connection.
+const DefaultClientBufferSize = 100
+
+// DefaultBroadcastBufferSize is the default number of broadcasts queued for the service
+// loop, which delivers them to the matching clients one at a time
+const DefaultBroadcastBufferSize = 1000
+
+// DefaultBroadcastWarnPercent is the default fill of the broadcast queue, as a percentage
+// of its size, at which a warning is logged
+const DefaultBroadcastWarnPercent = 80
+
+// Config holds SSE service configuration
+type Config struct {
+	StaleThreshold    time.Duration // Inactivity after which a client is considered stale
+	MaxStaleThreshold time.Duration // Upper bound for per-client stale thresholds
+	QuietClientGrace  time.Duration // Extra time granted to clients that have not yet received a message
+
+	ReplayBufferSize      int // Configuration updates kept per environment for replay (0 disables)
+	ReplayMaxEnvironments int // Environments whose updates are kept for replay at once
+
+	SlowClientPolicy string // SlowClientDropOldest or SlowClientDisconnect
+	ClientBufferSize int    // Messages queued per client before the slow client policy applies
+
+	BroadcastBufferSize  int // Broadcasts queued for delivery before new ones are dropped
+	BroadcastWarnPercent int // Fill of the broadcast queue at which a warning is logged (0 disables)
+
+	PollTimeout time.Duration // Longest a long-polling request waits for a change
+}
+
+// NewConfig creates a new SSE configuration from environment variables
+func NewConfig() *Config {
+	return &Config{
+		StaleThreshold:    getEnvSeconds("SSE_STALE_THRESHOLD", 5*time.Minute),
+		MaxStaleThreshold: getEnvSeconds("SSE_MAX_STALE_THRESHOLD", 30*time.Minute),
+		QuietClientGrace:  getEnvSeconds("SSE_QUIET_CLIENT_GRACE", 5*time.Minute),
+
+		ReplayBufferSize:      getEnvInt("SSE_REPLAY_BUFFER_SIZE", 20),
+		ReplayMaxEnvironments: getEnvInt("SSE_REPLAY_MAX_ENVIRONMENTS", 1000),
+
+		SlowClientPolicy: getEnvPolicy("SSE_SLOW_CLIENT_POLICY"),
+		ClientBufferSize: getEnvPositiveInt("SSE_CLIENT_BUFFER_SIZE", DefaultClientBufferSize),
+
+		BroadcastBufferSize:  getEnvPositiveInt("SSE_BROADCAST_BUFFER_SIZE", DefaultBroadcastBufferSize),
+		BroadcastWarnPercent: getEnvInt("SSE_BROADCAST_WARN_PERCENT", DefaultBroadcastWarnPercent),
+
+		PollTimeout: getEnvSeconds("SSE_POLL_TIMEOUT", 30*time.Second),
+	}
+}
+
+// getEnvPolicy reads the slow client policy from an environment variable, defaulting to
+// SlowClientDropOldest so that a briefly slow client keeps its connection
+func getEnvPolicy(key string) string {
+	if os.Getenv(key) == SlowClientDisconnect {
+		return SlowClientDisconnect
+	}
+	return SlowClientDropOldest
+}
+
+// getEnvSeconds reads a duration in whole seconds from an environment variable
+func getEnvSeconds(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return fallback
+}
+
+// getEnvInt reads a non-negative integer from an environment variable
+func getEnvInt(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			return n
+		}
+	}
+	return fallback
+}
+
+// getEnvPositiveInt reads a positive integer from an environment variable
+func getEnvPositiveInt(key string, fallback int) int {
+	if n := getEnvInt(key, fallback); n > 0 {
+		return n
+	}
+	return fallback
+}
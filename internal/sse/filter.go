@@ -0,0 +1,145 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"remote-config-system/internal/models"
+)
+
+// changeTracker remembers the configuration last broadcast for each environment, so that
+// the keys an update changes can be computed. It holds at most maxEnvironments
+// configurations; once full, an arbitrary environment is forgotten to make room.
+type changeTracker struct {
+	maxEnvironments int
+
+	mux     sync.Mutex
+	configs map[environmentKey]json.RawMessage
+}
+
+// newChangeTracker creates a change tracker. A non-positive limit disables it.
+func newChangeTracker(maxEnvironments int) *changeTracker {
+	return &changeTracker{
+		maxEnvironments: maxEnvironments,
+		configs:         make(map[environmentKey]json.RawMessage),
+	}
+}
+
+// track records the configuration of an update, as stored when known, and returns the dot-notated paths it
+// changed since the previous update of the environment, sorted. It returns nil when the
+// changes are unknown, because the environment's previous configuration is not known or
+// either configuration is not a JSON object.
+func (t *changeTracker) track(event models.ConfigUpdateEvent) []string {
+	if t.maxEnvironments <= 0 {
+		return nil
+	}
+
+	key := environmentKey{event.Organization, event.Application, event.Environment}
+	config := event.Config
+	if event.Stored != nil {
+		config = event.Stored
+	}
+
+	t.mux.Lock()
+	previous, known := t.configs[key]
+	if !known && len(t.configs) >= t.maxEnvironments {
+		for evicted := range t.configs {
+			delete(t.configs, evicted)
+			break
+		}
+	}
+	t.configs[key] = config
+	t.mux.Unlock()
+
+	if !known {
+		return nil
+	}
+	return changedPaths(previous, config)
+}
+
+// changedPaths returns the sorted paths whose values differ between two configuration
+// documents, descending through objects present in both. It returns nil if either
+// document is not a JSON object.
+func changedPaths(previous, current json.RawMessage) []string {
+	oldRoot, ok := decodeObject(previous)
+	if !ok {
+		return nil
+	}
+	newRoot, ok := decodeObject(current)
+	if !ok {
+		return nil
+	}
+
+	changed := []string{}
+	collectChanges(oldRoot, newRoot, "", &changed)
+	sort.Strings(changed)
+	return changed
+}
+
+// collectChanges appends the paths below prefix whose values differ between two objects
+func collectChanges(previous, current map[string]interface{}, prefix string, changed *[]string) {
+	for key, oldValue := range previous {
+		path := prefix + key
+		newValue, exists := current[key]
+		if !exists {
+			*changed = append(*changed, path)
+			continue
+		}
+
+		oldObject, oldIsObject := oldValue.(map[string]interface{})
+		newObject, newIsObject := newValue.(map[string]interface{})
+		if oldIsObject && newIsObject {
+			collectChanges(oldObject, newObject, path+".", changed)
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			*changed = append(*changed, path)
+		}
+	}
+
+	for key := range current {
+		if _, exists := previous[key]; !exists {
+			*changed = append(*changed, prefix+key)
+		}
+	}
+}
+
+// decodeObject decodes a JSON object, keeping numbers as they are written
+func decodeObject(document json.RawMessage) (map[string]interface{}, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil || object == nil {
+		return nil, false
+	}
+	return object, true
+}
+
+// WantsChanges reports whether the client receives an update that changed the given
+// paths. Clients without keys receive every update, as do all clients when the changes
+// are unknown (nil).
+func (c *Client) WantsChanges(changed []string) bool {
+	if len(c.Keys) == 0 || changed == nil {
+		return true
+	}
+	return keysChanged(c.Keys, changed)
+}
+
+// keysChanged reports whether any of a client's keys is affected by the changed paths. A
+// key is affected when a path at or below it changed, or when a whole object containing
+// it changed.
+func keysChanged(keys, changed []string) bool {
+	for _, key := range keys {
+		for _, path := range changed {
+			if path == key || strings.HasPrefix(path, key+".") || strings.HasPrefix(key, path+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
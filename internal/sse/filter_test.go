@@ -0,0 +1,119 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedPaths(t *testing.T) {
+	changed := changedPaths(
+		json.RawMessage(`{"db": {"host": "a", "port": 5432}, "features": {"beta": true}, "timeout": 30, "old": 1}`),
+		json.RawMessage(`{"db": {"host": "b", "port": 5432}, "features": "off", "timeout": 30, "new": [1]}`),
+	)
+	assert.Equal(t, []string{"db.host", "features", "new", "old"}, changed)
+
+	assert.Equal(t, []string{}, changedPaths(json.RawMessage(`{"a": 1}`), json.RawMessage(`{"a": 1}`)))
+	assert.Nil(t, changedPaths(json.RawMessage(`[1]`), json.RawMessage(`{"a": 1}`)))
+}
+
+func TestClient_WantsChanges(t *testing.T) {
+	client := &Client{Keys: []string{"db", "features.beta"}}
+
+	assert.True(t, client.WantsChanges([]string{"db.host"}))
+	assert.True(t, client.WantsChanges([]string{"features"}))
+	assert.False(t, client.WantsChanges([]string{"dbx", "features.gamma"}))
+	assert.False(t, client.WantsChanges([]string{}))
+
+	// Unknown changes reach every client
+	assert.True(t, client.WantsChanges(nil))
+	assert.True(t, (&Client{}).WantsChanges([]string{"timeout"}))
+}
+
+func TestChangeTracker(t *testing.T) {
+	tracker := newChangeTracker(1)
+	event := models.ConfigUpdateEvent{Organization: "org", Application: "app", Environment: "prod", Config: json.RawMessage(`{"a": 1}`)}
+
+	assert.Nil(t, tracker.track(event), "the first update's changes are unknown")
+
+	event.Config = json.RawMessage(`{"a": 2}`)
+	assert.Equal(t, []string{"a"}, tracker.track(event))
+
+	// Tracking another environment forgets the first one
+	other := event
+	other.Environment = "dev"
+	tracker.track(other)
+	assert.Nil(t, tracker.track(event))
+
+	assert.Nil(t, newChangeTracker(0).track(event))
+}
+
+func TestChangeTracker_Stored(t *testing.T) {
+	tracker := newChangeTracker(1)
+	event := models.ConfigUpdateEvent{
+		Organization: "org", Application: "app", Environment: "prod",
+		Config: json.RawMessage(`{"db_password": "<encrypted>", "a": 1}`),
+		Stored: json.RawMessage(`{"db_password": "v1", "a": 1}`),
+	}
+	tracker.track(event)
+
+	// The masked values are equal, but a changed secret is still a change
+	event.Stored = json.RawMessage(`{"db_password": "v2", "a": 1}`)
+	assert.Equal(t, []string{"db_password"}, tracker.track(event))
+}
+
+func TestSSEService_KeysFilterUpdates(t *testing.T) {
+	service := NewSSEServiceWithConfig(&Config{StaleThreshold: time.Minute, ReplayMaxEnvironments: 10})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &Client{
+		ID:           uuid.New().String(),
+		Organization: "org",
+		Application:  "app",
+		Environment:  "prod",
+		Channel:      make(chan models.SSEMessage, 10),
+		Context:      ctx,
+		Cancel:       cancel,
+		ConnectedAt:  time.Now(),
+		LastPing:     time.Now(),
+		Keys:         []string{"db"},
+	}
+	service.RegisterClient(client)
+	require.Equal(t, "connected", (<-client.Channel).Event)
+
+	update := func(version int, config string) {
+		service.BroadcastConfigUpdate(models.ConfigUpdateEvent{
+			Organization: "org", Application: "app", Environment: "prod",
+			Version: version, Config: json.RawMessage(config), Action: "update",
+		})
+	}
+	update(1, `{"db": {"host": "a"}, "theme": "light"}`)
+	update(2, `{"db": {"host": "a"}, "theme": "dark"}`)
+	update(3, `{"db": {"host": "b"}, "theme": "dark"}`)
+
+	// The first update is delivered as its changes are unknown, the second changed no key
+	// of the client
+	var versions []int
+	for len(versions) < 2 {
+		select {
+		case message := <-client.Channel:
+			event := message.Data.(models.ConfigUpdateEvent)
+			versions = append(versions, event.Version)
+			if event.Version == 3 {
+				assert.Equal(t, []string{"db.host"}, event.ChangedKeys)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received only versions %v", versions)
+		}
+	}
+	assert.Equal(t, []int{1, 3}, versions)
+}
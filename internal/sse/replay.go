@@ -0,0 +1,105 @@
+package sse
+
+import (
+	"sync"
+
+	"remote-config-system/internal/models"
+)
+
+// environmentKey identifies the environment an event belongs to
+type environmentKey struct {
+	Organization string
+	Application  string
+	Environment  string
+}
+
+// replayBuffer keeps the most recent configuration update events of each environment, so
+// that clients can catch up on changes they missed while disconnected. It holds at most
+// size events for each of at most maxEnvironments environments; the environment updated
+// least recently is dropped to make room for a new one.
+type replayBuffer struct {
+	size            int
+	maxEnvironments int
+
+	mux          sync.Mutex
+	environments map[environmentKey]*eventRing
+	sequence     uint64 // Orders the environments by their last update
+}
+
+// eventRing holds the latest events of one environment in a fixed-size ring
+type eventRing struct {
+	events     []models.ConfigUpdateEvent
+	next       int // Index the next event is written to
+	count      int
+	lastUpdate uint64
+}
+
+// newReplayBuffer creates a replay buffer. A non-positive size disables it.
+func newReplayBuffer(size, maxEnvironments int) *replayBuffer {
+	return &replayBuffer{
+		size:            size,
+		maxEnvironments: maxEnvironments,
+		environments:    make(map[environmentKey]*eventRing),
+	}
+}
+
+// record adds an event to the buffer of its environment, replacing the oldest event once
+// the buffer is full
+func (b *replayBuffer) record(event models.ConfigUpdateEvent) {
+	if b.size <= 0 || b.maxEnvironments <= 0 {
+		return
+	}
+
+	key := environmentKey{event.Organization, event.Application, event.Environment}
+
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	ring, exists := b.environments[key]
+	if !exists {
+		if len(b.environments) >= b.maxEnvironments {
+			b.evictOldest()
+		}
+		ring = &eventRing{events: make([]models.ConfigUpdateEvent, b.size)}
+		b.environments[key] = ring
+	}
+
+	ring.events[ring.next] = event
+	ring.next = (ring.next + 1) % b.size
+	if ring.count < b.size {
+		ring.count++
+	}
+
+	b.sequence++
+	ring.lastUpdate = b.sequence
+}
+
+// evictOldest drops the buffer of the environment updated least recently
+func (b *replayBuffer) evictOldest() {
+	var oldestKey environmentKey
+	var oldest *eventRing
+	for key, ring := range b.environments {
+		if oldest == nil || ring.lastUpdate < oldest.lastUpdate {
+			oldestKey, oldest = key, ring
+		}
+	}
+	delete(b.environments, oldestKey)
+}
+
+// recent returns the buffered events of an environment, oldest first
+func (b *replayBuffer) recent(org, app, env string) []models.ConfigUpdateEvent {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	ring, exists := b.environments[environmentKey{org, app, env}]
+	if !exists {
+		return []models.ConfigUpdateEvent{}
+	}
+
+	events := make([]models.ConfigUpdateEvent, 0, ring.count)
+	start := (ring.next - ring.count + b.size) % b.size
+	for i := 0; i < ring.count; i++ {
+		events = append(events, ring.events[(start+i)%b.size])
+	}
+	return events
+}
@@ -0,0 +1,88 @@
+package sse
+
+import (
+	"testing"
+	"time"
+
+	"remote-config-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func replayEvent(env string, version int) models.ConfigUpdateEvent {
+	return models.ConfigUpdateEvent{
+		Organization: "test-org",
+		Application:  "test-app",
+		Environment:  env,
+		Version:      version,
+		Action:       "update",
+	}
+}
+
+func versions(events []models.ConfigUpdateEvent) []int {
+	result := []int{}
+	for _, event := range events {
+		result = append(result, event.Version)
+	}
+	return result
+}
+
+func TestReplayBuffer_KeepsLatestEvents(t *testing.T) {
+	buffer := newReplayBuffer(3, 10)
+
+	assert.Empty(t, buffer.recent("test-org", "test-app", "prod"))
+
+	buffer.record(replayEvent("prod", 1))
+	buffer.record(replayEvent("prod", 2))
+	assert.Equal(t, []int{1, 2}, versions(buffer.recent("test-org", "test-app", "prod")))
+
+	for version := 3; version <= 7; version++ {
+		buffer.record(replayEvent("prod", version))
+	}
+	assert.Equal(t, []int{5, 6, 7}, versions(buffer.recent("test-org", "test-app", "prod")))
+
+	// Environments are kept apart
+	buffer.record(replayEvent("dev", 1))
+	assert.Equal(t, []int{1}, versions(buffer.recent("test-org", "test-app", "dev")))
+	assert.Empty(t, buffer.recent("other-org", "test-app", "prod"))
+}
+
+func TestReplayBuffer_EvictsLeastRecentlyUpdatedEnvironment(t *testing.T) {
+	buffer := newReplayBuffer(2, 2)
+
+	buffer.record(replayEvent("dev", 1))
+	buffer.record(replayEvent("staging", 1))
+	buffer.record(replayEvent("dev", 2))
+	buffer.record(replayEvent("prod", 1))
+
+	assert.Len(t, buffer.environments, 2)
+	assert.Empty(t, buffer.recent("test-org", "test-app", "staging"))
+	assert.Equal(t, []int{1, 2}, versions(buffer.recent("test-org", "test-app", "dev")))
+	assert.Equal(t, []int{1}, versions(buffer.recent("test-org", "test-app", "prod")))
+}
+
+func TestReplayBuffer_Disabled(t *testing.T) {
+	buffer := newReplayBuffer(0, 10)
+
+	buffer.record(replayEvent("prod", 1))
+
+	assert.Empty(t, buffer.recent("test-org", "test-app", "prod"))
+	assert.Empty(t, buffer.environments)
+}
+
+func TestSSEService_RecentEvents(t *testing.T) {
+	service := NewSSEServiceWithConfig(&Config{
+		StaleThreshold:        5 * time.Minute,
+		MaxStaleThreshold:     30 * time.Minute,
+		ReplayBufferSize:      2,
+		ReplayMaxEnvironments: 10,
+	})
+
+	// Updates are kept without any client connected
+	service.BroadcastConfigUpdate(replayEvent("prod", 1))
+	service.BroadcastConfigUpdate(replayEvent("prod", 2))
+	service.BroadcastConfigUpdate(replayEvent("prod", 3))
+	service.BroadcastCustomEvent("test-org", "test-app", "prod", "tag_update", map[string]string{"tag": "stable"})
+
+	assert.Equal(t, []int{2, 3}, versions(service.RecentEvents("test-org", "test-app", "prod")))
+}
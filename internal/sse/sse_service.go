@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"remote-config-system/internal/models"
@@ -17,6 +18,7 @@ type SSEServiceInterface interface {
 	BroadcastCustomEvent(org, app, env, eventType string, data interface{})
 	GetStats() SSEStats
 	Ping(clientID string)
+	NewClientChannel() chan models.SSEMessage
 }
 
 // Client represents a connected SSE client
@@ -30,15 +32,37 @@ type Client struct {
 	Cancel       context.CancelFunc
 	ConnectedAt  time.Time
 	LastPing     time.Time
+
+	// StaleThreshold overrides the service stale threshold for this client when non-zero
+	StaleThreshold time.Duration
+	// LastMessageAt is when the client last received a broadcast; zero if it never has
+	LastMessageAt time.Time
+	// DroppedMessages counts the messages discarded because the client's channel was full
+	DroppedMessages int64
+	// QueueHighWaterMark is the most messages queued on the client's channel at once
+	// after a broadcast, to tell how close the client came to filling it
+	QueueHighWaterMark int
+
+	// Keys limits the client to configuration updates changing one of these dot-notated
+	// paths; empty receives every update
+	Keys []string
+
+	// registered is closed once the client is registered and its welcome message queued
+	registered chan struct{}
 }
 
 // SSEService manages Server-Sent Events connections and broadcasting
 type SSEService struct {
+	config *Config
+
 	clients    map[string]*Client
 	clientsMux sync.RWMutex
 
 	// Channel for broadcasting events to all clients
 	broadcast chan BroadcastMessage
+	// Whether the broadcast queue was last found above the warning threshold, so that a
+	// backlog is reported once rather than for every broadcast
+	broadcastBacklogged atomic.Bool
 
 	// Channel for registering new clients
 	register chan *Client
@@ -49,6 +73,12 @@ type SSEService struct {
 	// Statistics
 	stats    SSEStats
 	statsMux sync.RWMutex
+
+	// Recent configuration updates of each environment
+	replay *replayBuffer
+
+	// Configuration last broadcast for each environment, to find the keys updates change
+	changes *changeTracker
 }
 
 // BroadcastMessage represents a message to be broadcasted
@@ -57,6 +87,10 @@ type BroadcastMessage struct {
 	Application  string
 	Environment  string
 	Message      models.SSEMessage
+
+	// ChangedKeys are the paths a configuration update changed; nil if unknown or the
+	// message is not a configuration update
+	ChangedKeys []string
 }
 
 // SSEStats holds SSE service statistics
@@ -65,19 +99,31 @@ type SSEStats struct {
 	ActiveConnections   int       `json:"active_connections"`
 	MessagesSent        int64     `json:"messages_sent"`
 	ConnectionsDropped  int64     `json:"connections_dropped"`
+	MessagesDropped     int64     `json:"messages_dropped"`   // Queued or new messages discarded for slow clients
+	BroadcastsDropped   int64     `json:"broadcasts_dropped"` // Broadcasts discarded because the broadcast queue was full
+	BroadcastsQueued    int       `json:"broadcasts_queued"`  // Broadcasts waiting to be delivered
+	BroadcastBufferSize int       `json:"broadcast_buffer_size"`
 	LastActivity        time.Time `json:"last_activity"`
 }
 
-// NewSSEService creates a new SSE service
+// NewSSEService creates a new SSE service configured from environment variables
 func NewSSEService() *SSEService {
+	return NewSSEServiceWithConfig(NewConfig())
+}
+
+// NewSSEServiceWithConfig creates a new SSE service with the given configuration
+func NewSSEServiceWithConfig(config *Config) *SSEService {
 	service := &SSEService{
+		config:     config,
 		clients:    make(map[string]*Client),
-		broadcast:  make(chan BroadcastMessage, 1000),
+		broadcast:  make(chan BroadcastMessage, broadcastBufferSize(config)),
 		register:   make(chan *Client, 100),
 		unregister: make(chan *Client, 100),
 		stats: SSEStats{
 			LastActivity: time.Now(),
 		},
+		replay: newReplayBuffer(config.ReplayBufferSize, config.ReplayMaxEnvironments),
+		changes: newChangeTracker(config.ReplayMaxEnvironments),
 	}
 
 	// Start the service in a goroutine
@@ -142,23 +188,30 @@ func (s *SSEService) registerClient(client *Client) {
 	default:
 		log.Printf("Failed to send welcome message to client %s", client.ID)
 	}
+
+	if client.registered != nil {
+		close(client.registered)
+	}
 }
 
-// unregisterClient removes a client from the service
+// unregisterClient removes a client from the service. Clients that were already removed,
+// e.g. as stale or disconnected by an operator, are not counted again.
 func (s *SSEService) unregisterClient(client *Client) {
 	s.clientsMux.Lock()
-	var activeConnections int
-	if _, exists := s.clients[client.ID]; exists {
+	_, exists := s.clients[client.ID]
+	if exists {
 		delete(s.clients, client.ID)
 		close(client.Channel)
 		client.Cancel()
-		activeConnections = len(s.clients)
 		log.Printf("SSE client unregistered: %s", client.ID)
-	} else {
-		activeConnections = len(s.clients)
 	}
+	activeConnections := len(s.clients)
 	s.clientsMux.Unlock()
 
+	if !exists {
+		return
+	}
+
 	// Update stats with proper locking
 	s.statsMux.Lock()
 	s.stats.ConnectionsDropped++
@@ -169,26 +222,30 @@ func (s *SSEService) unregisterClient(client *Client) {
 
 // broadcastMessage sends a message to all matching clients
 func (s *SSEService) broadcastMessage(message BroadcastMessage) {
-	s.clientsMux.RLock()
-	defer s.clientsMux.RUnlock()
+	s.clientsMux.Lock()
+	defer s.clientsMux.Unlock()
 
+	now := time.Now()
 	sentCount := 0
+	droppedCount := 0
 	for _, client := range s.clients {
 		// Check if client should receive this message
 		if s.shouldReceiveMessage(client, message) {
-			select {
-			case client.Channel <- message.Message:
+			sent, dropped := s.deliver(client, message.Message)
+			if sent {
+				client.LastMessageAt = now
 				sentCount++
-			default:
-				// Client channel is full, remove the client
-				log.Printf("Client %s channel full, removing", client.ID)
-				go func(c *Client) {
-					s.unregister <- c
-				}(client)
 			}
+			droppedCount += dropped
 		}
 	}
 
+	if droppedCount > 0 {
+		s.statsMux.Lock()
+		s.stats.MessagesDropped += int64(droppedCount)
+		s.statsMux.Unlock()
+	}
+
 	if sentCount > 0 {
 		// Update stats with proper locking
 		s.statsMux.Lock()
@@ -201,17 +258,75 @@ func (s *SSEService) broadcastMessage(message BroadcastMessage) {
 	}
 }
 
+// deliver queues a message on a client's channel. If the channel is full, the slow client
+// policy either discards the oldest queued message to make room, or unregisters the client.
+// It reports whether the message was queued and how many messages were discarded.
+func (s *SSEService) deliver(client *Client, message models.SSEMessage) (bool, int) {
+	select {
+	case client.Channel <- message:
+		client.recordQueued()
+		return true, 0
+	default:
+	}
+
+	if s.config.SlowClientPolicy == SlowClientDisconnect {
+		// Client channel is full, remove the client
+		log.Printf("Client %s channel full, removing", client.ID)
+		go func(c *Client) {
+			s.unregister <- c
+		}(client)
+		return false, 0
+	}
+
+	dropped := 0
+	select {
+	case <-client.Channel:
+		dropped++
+	default:
+		// The client caught up in the meantime
+	}
+
+	sent := false
+	select {
+	case client.Channel <- message:
+		sent = true
+	default:
+		// Refilled by another sender; the new message is the one discarded
+		dropped++
+	}
+
+	client.DroppedMessages += int64(dropped)
+	client.recordQueued()
+	log.Printf("Client %s channel full, dropped %d message(s)", client.ID, dropped)
+	return sent, dropped
+}
+
+// recordQueued raises the client's high-water mark to the messages now queued
+func (c *Client) recordQueued() {
+	if queued := len(c.Channel); queued > c.QueueHighWaterMark {
+		c.QueueHighWaterMark = queued
+	}
+}
+
 // shouldReceiveMessage determines if a client should receive a specific message
 func (s *SSEService) shouldReceiveMessage(client *Client, message BroadcastMessage) bool {
 	// Match organization, application, and environment
-	return client.Organization == message.Organization &&
-		   client.Application == message.Application &&
-		   client.Environment == message.Environment
+	if client.Organization != message.Organization ||
+		client.Application != message.Application ||
+		client.Environment != message.Environment {
+		return false
+	}
+
+	return client.WantsChanges(message.ChangedKeys)
 }
 
-// RegisterClient registers a new SSE client
+// RegisterClient registers a new SSE client. It returns once the client's "connected"
+// welcome message is queued, so that messages the caller queues afterwards, such as the
+// initial configuration, always follow it.
 func (s *SSEService) RegisterClient(client *Client) {
+	client.registered = make(chan struct{})
 	s.register <- client
+	<-client.registered
 }
 
 // UnregisterClient unregisters an SSE client
@@ -221,6 +336,13 @@ func (s *SSEService) UnregisterClient(client *Client) {
 
 // BroadcastConfigUpdate broadcasts a configuration update to relevant clients
 func (s *SSEService) BroadcastConfigUpdate(event models.ConfigUpdateEvent) {
+	changed := s.changes.track(event)
+	event.ChangedKeys = changed
+	event.Stored = nil
+
+	// Kept for replay even if no client is connected
+	s.replay.record(event)
+
 	message := BroadcastMessage{
 		Organization: event.Organization,
 		Application:  event.Application,
@@ -229,15 +351,10 @@ func (s *SSEService) BroadcastConfigUpdate(event models.ConfigUpdateEvent) {
 			Event: "config_update",
 			Data:  event,
 		},
+		ChangedKeys: changed,
 	}
 
-	select {
-	case s.broadcast <- message:
-		// Message queued successfully
-	default:
-		log.Printf("Broadcast channel full, dropping message for %s/%s/%s", 
-			event.Organization, event.Application, event.Environment)
-	}
+	s.queueBroadcast(message)
 }
 
 // BroadcastCustomEvent broadcasts a custom event to clients
@@ -252,12 +369,59 @@ func (s *SSEService) BroadcastCustomEvent(org, app, env, eventType string, data
 		},
 	}
 
+	s.queueBroadcast(message)
+}
+
+// queueBroadcast queues a message for the service loop without blocking the caller. The
+// message is dropped and counted if the queue is full, and a warning is logged when the
+// queue fills past the configured threshold.
+func (s *SSEService) queueBroadcast(message BroadcastMessage) {
 	select {
 	case s.broadcast <- message:
 		// Message queued successfully
 	default:
-		log.Printf("Broadcast channel full, dropping custom event for %s/%s/%s", org, app, env)
+		s.statsMux.Lock()
+		s.stats.BroadcastsDropped++
+		s.statsMux.Unlock()
+
+		log.Printf("Broadcast channel full, dropping %s event for %s/%s/%s",
+			message.Message.Event, message.Organization, message.Application, message.Environment)
+		return
 	}
+
+	queued := len(s.broadcast)
+	if s.config.BroadcastWarnPercent <= 0 || queued*100 < cap(s.broadcast)*s.config.BroadcastWarnPercent {
+		s.broadcastBacklogged.Store(false)
+		return
+	}
+	if s.broadcastBacklogged.CompareAndSwap(false, true) {
+		log.Printf("Warning: broadcast channel is %d%% full (%d of %d), consider raising SSE_BROADCAST_BUFFER_SIZE",
+			queued*100/cap(s.broadcast), queued, cap(s.broadcast))
+	}
+}
+
+// broadcastBufferSize returns the configured size of the broadcast queue, or the default
+// if it is unset
+func broadcastBufferSize(config *Config) int {
+	if config.BroadcastBufferSize > 0 {
+		return config.BroadcastBufferSize
+	}
+	return DefaultBroadcastBufferSize
+}
+
+// RecentEvents returns the most recent configuration updates of an environment, oldest first
+func (s *SSEService) RecentEvents(org, app, env string) []models.ConfigUpdateEvent {
+	return s.replay.recent(org, app, env)
+}
+
+// NewClientChannel creates the message channel of a new client, buffering as many
+// messages as configured
+func (s *SSEService) NewClientChannel() chan models.SSEMessage {
+	size := s.config.ClientBufferSize
+	if size <= 0 {
+		size = DefaultClientBufferSize
+	}
+	return make(chan models.SSEMessage, size)
 }
 
 // GetStats returns current SSE service statistics
@@ -271,6 +435,8 @@ func (s *SSEService) GetStats() SSEStats {
 	s.statsMux.RUnlock()
 
 	stats.ActiveConnections = activeConnections
+	stats.BroadcastsQueued = len(s.broadcast)
+	stats.BroadcastBufferSize = cap(s.broadcast)
 	return stats
 }
 
@@ -282,12 +448,17 @@ func (s *SSEService) GetClients() []map[string]interface{} {
 	clients := make([]map[string]interface{}, 0, len(s.clients))
 	for _, client := range s.clients {
 		clients = append(clients, map[string]interface{}{
-			"id":           client.ID,
-			"organization": client.Organization,
-			"application":  client.Application,
-			"environment":  client.Environment,
-			"connected_at": client.ConnectedAt,
-			"last_ping":    client.LastPing,
+			"id":               client.ID,
+			"organization":     client.Organization,
+			"application":      client.Application,
+			"environment":      client.Environment,
+			"connected_at":     client.ConnectedAt,
+			"last_ping":        client.LastPing,
+			"last_message_at":  client.LastMessageAt,
+			"dropped_messages": client.DroppedMessages,
+			"buffer_size":      cap(client.Channel),
+			"high_water_mark":  client.QueueHighWaterMark,
+			"stale_threshold":  s.staleThreshold(client).String(),
 		})
 	}
 
@@ -304,15 +475,60 @@ func (s *SSEService) periodicCleanup() {
 	}
 }
 
+// ClientStaleThreshold bounds a client-requested stale threshold by the server maximum.
+// A non-positive request selects the server default.
+func (s *SSEService) ClientStaleThreshold(requested time.Duration) time.Duration {
+	if requested <= 0 {
+		return 0
+	}
+	if requested > s.config.MaxStaleThreshold {
+		return s.config.MaxStaleThreshold
+	}
+	return requested
+}
+
+// ClientPollTimeout bounds a client-requested long-polling timeout by the server maximum.
+// A non-positive request selects the maximum.
+func (s *SSEService) ClientPollTimeout(requested time.Duration) time.Duration {
+	if requested <= 0 || requested > s.config.PollTimeout {
+		return s.config.PollTimeout
+	}
+	return requested
+}
+
+// staleThreshold returns how long a client may be inactive before it is reaped
+func (s *SSEService) staleThreshold(client *Client) time.Duration {
+	threshold := s.config.StaleThreshold
+	if client.StaleThreshold > 0 {
+		threshold = client.StaleThreshold
+	}
+
+	// Quiet subscriptions that have never been sent anything get extra grace
+	if client.LastMessageAt.IsZero() {
+		threshold += s.config.QuietClientGrace
+	}
+
+	return threshold
+}
+
+// isStale reports whether a client has been inactive for longer than its threshold.
+// Activity is the most recent of the last ping and the last delivered message.
+func (s *SSEService) isStale(client *Client, now time.Time) bool {
+	lastActivity := client.LastPing
+	if client.LastMessageAt.After(lastActivity) {
+		lastActivity = client.LastMessageAt
+	}
+	return now.Sub(lastActivity) > s.staleThreshold(client)
+}
+
 // cleanupStaleConnections removes connections that haven't been active
 func (s *SSEService) cleanupStaleConnections() {
 	s.clientsMux.Lock()
 	now := time.Now()
-	staleThreshold := 5 * time.Minute
 	droppedCount := 0
 
 	for id, client := range s.clients {
-		if now.Sub(client.LastPing) > staleThreshold {
+		if s.isStale(client, now) {
 			log.Printf("Removing stale SSE client: %s", id)
 			delete(s.clients, id)
 			close(client.Channel)
@@ -333,6 +549,33 @@ func (s *SSEService) cleanupStaleConnections() {
 	}
 }
 
+// DisconnectClient closes the connection of a client, returning false if no client with
+// the ID is connected. The client's channel is closed and its context cancelled, which
+// ends its stream.
+func (s *SSEService) DisconnectClient(clientID string) bool {
+	s.clientsMux.Lock()
+	client, exists := s.clients[clientID]
+	if exists {
+		delete(s.clients, clientID)
+		close(client.Channel)
+		client.Cancel()
+	}
+	activeConnections := len(s.clients)
+	s.clientsMux.Unlock()
+
+	if !exists {
+		return false
+	}
+	log.Printf("SSE client disconnected by an operator: %s", clientID)
+
+	s.statsMux.Lock()
+	s.stats.ConnectionsDropped++
+	s.stats.ActiveConnections = activeConnections
+	s.stats.LastActivity = time.Now()
+	s.statsMux.Unlock()
+	return true
+}
+
 // Ping updates the last ping time for a client
 func (s *SSEService) Ping(clientID string) {
 	s.clientsMux.Lock()
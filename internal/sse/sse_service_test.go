@@ -52,6 +52,32 @@ func TestSSEService_RegisterClient(t *testing.T) {
 	assert.Equal(t, 1, stats.ActiveConnections)
 }
 
+func TestSSEService_RegisterClientQueuesWelcomeFirst(t *testing.T) {
+	service := NewSSEService()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &Client{
+		ID:           uuid.New().String(),
+		Organization: "test-org",
+		Application:  "test-app",
+		Environment:  "prod",
+		Channel:      make(chan models.SSEMessage, 10),
+		Context:      ctx,
+		Cancel:       cancel,
+		ConnectedAt:  time.Now(),
+		LastPing:     time.Now(),
+	}
+
+	// Registration returns once the welcome message is queued
+	service.RegisterClient(client)
+	client.Channel <- models.SSEMessage{Event: "initial_config"}
+
+	assert.Equal(t, "connected", (<-client.Channel).Event)
+	assert.Equal(t, "initial_config", (<-client.Channel).Event)
+}
+
 func TestSSEService_UnregisterClient(t *testing.T) {
 	service := NewSSEService()
 	
@@ -335,3 +361,264 @@ func TestSSEService_GetStats(t *testing.T) {
 	assert.True(t, stats.MessagesSent > 0)
 	assert.True(t, !stats.LastActivity.IsZero())
 }
+
+func TestSSEService_IsStale(t *testing.T) {
+	service := NewSSEServiceWithConfig(&Config{
+		StaleThreshold:    5 * time.Minute,
+		MaxStaleThreshold: 30 * time.Minute,
+		QuietClientGrace:  5 * time.Minute,
+	})
+	now := time.Now()
+
+	// A client that never received a message gets the grace period on top of the threshold
+	quiet := &Client{LastPing: now.Add(-7 * time.Minute)}
+	assert.False(t, service.isStale(quiet, now))
+
+	quiet.LastPing = now.Add(-11 * time.Minute)
+	assert.True(t, service.isStale(quiet, now))
+
+	// Once it has received a message only the threshold applies, measured from the latest activity
+	active := &Client{
+		LastPing:      now.Add(-20 * time.Minute),
+		LastMessageAt: now.Add(-4 * time.Minute),
+	}
+	assert.False(t, service.isStale(active, now))
+
+	active.LastMessageAt = now.Add(-6 * time.Minute)
+	assert.True(t, service.isStale(active, now))
+
+	// A per-client threshold overrides the default
+	custom := &Client{
+		LastPing:       now.Add(-20 * time.Minute),
+		LastMessageAt:  now.Add(-20 * time.Minute),
+		StaleThreshold: 25 * time.Minute,
+	}
+	assert.False(t, service.isStale(custom, now))
+}
+
+func TestSSEService_ClientStaleThreshold(t *testing.T) {
+	service := NewSSEServiceWithConfig(&Config{
+		StaleThreshold:    5 * time.Minute,
+		MaxStaleThreshold: 30 * time.Minute,
+	})
+
+	assert.Equal(t, time.Duration(0), service.ClientStaleThreshold(0))
+	assert.Equal(t, 10*time.Minute, service.ClientStaleThreshold(10*time.Minute))
+	assert.Equal(t, 30*time.Minute, service.ClientStaleThreshold(2*time.Hour))
+}
+
+func TestSSEService_ClientPollTimeout(t *testing.T) {
+	service := NewSSEServiceWithConfig(&Config{
+		StaleThreshold: 5 * time.Minute,
+		PollTimeout:    30 * time.Second,
+	})
+
+	assert.Equal(t, 30*time.Second, service.ClientPollTimeout(0))
+	assert.Equal(t, 10*time.Second, service.ClientPollTimeout(10*time.Second))
+	assert.Equal(t, 30*time.Second, service.ClientPollTimeout(time.Hour))
+}
+
+func TestSSEService_BroadcastRecordsLastMessage(t *testing.T) {
+	service := NewSSEService()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := &Client{
+		ID:           uuid.New().String(),
+		Organization: "test-org",
+		Application:  "test-app",
+		Environment:  "prod",
+		Channel:      make(chan models.SSEMessage, 10),
+		Context:      ctx,
+		Cancel:       cancel,
+		ConnectedAt:  time.Now(),
+		LastPing:     time.Now(),
+	}
+
+	service.RegisterClient(client)
+	time.Sleep(100 * time.Millisecond)
+
+	// The welcome message is not a broadcast and must not count as activity
+	welcome := <-client.Channel
+	require.Equal(t, "connected", welcome.Event)
+	service.clientsMux.RLock()
+	assert.True(t, client.LastMessageAt.IsZero())
+	service.clientsMux.RUnlock()
+
+	service.BroadcastCustomEvent("test-org", "test-app", "prod", "custom", map[string]string{"k": "v"})
+
+	select {
+	case msg := <-client.Channel:
+		assert.Equal(t, "custom", msg.Event)
+	case <-time.After(time.Second):
+		t.Fatal("expected a message")
+	}
+
+	service.clientsMux.RLock()
+	lastMessageAt := client.LastMessageAt
+	service.clientsMux.RUnlock()
+	assert.False(t, lastMessageAt.IsZero())
+}
+
+func newSlowClient(t *testing.T) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	return &Client{
+		ID:           uuid.New().String(),
+		Organization: "test-org",
+		Application:  "test-app",
+		Environment:  "prod",
+		Channel:      make(chan models.SSEMessage, 2),
+		Context:      ctx,
+		Cancel:       cancel,
+		ConnectedAt:  time.Now(),
+		LastPing:     time.Now(),
+	}
+}
+
+func configUpdate(version int) BroadcastMessage {
+	return BroadcastMessage{
+		Organization: "test-org",
+		Application:  "test-app",
+		Environment:  "prod",
+		Message: models.SSEMessage{
+			Event: "config_update",
+			Data:  models.ConfigUpdateEvent{Version: version},
+		},
+	}
+}
+
+func TestSSEService_FullChannelDropsOldest(t *testing.T) {
+	service := NewSSEServiceWithConfig(&Config{StaleThreshold: 5 * time.Minute, SlowClientPolicy: SlowClientDropOldest})
+	client := newSlowClient(t)
+
+	// The welcome message and the first update fill the channel
+	service.registerClient(client)
+	service.broadcastMessage(configUpdate(1))
+	service.broadcastMessage(configUpdate(2))
+	service.broadcastMessage(configUpdate(3))
+
+	// The client stays connected and gets the latest updates
+	service.clientsMux.RLock()
+	_, connected := service.clients[client.ID]
+	service.clientsMux.RUnlock()
+	assert.True(t, connected)
+
+	first := <-client.Channel
+	second := <-client.Channel
+	assert.Equal(t, 2, first.Data.(models.ConfigUpdateEvent).Version)
+	assert.Equal(t, 3, second.Data.(models.ConfigUpdateEvent).Version)
+
+	assert.Equal(t, int64(2), client.DroppedMessages)
+	assert.Equal(t, int64(2), service.GetStats().MessagesDropped)
+}
+
+func TestSSEService_ClientBufferSize(t *testing.T) {
+	t.Run("channels buffer the configured number of messages", func(t *testing.T) {
+		service := NewSSEServiceWithConfig(&Config{StaleThreshold: 5 * time.Minute, ClientBufferSize: 4})
+		assert.Equal(t, 4, cap(service.NewClientChannel()))
+	})
+
+	t.Run("unset size uses the default", func(t *testing.T) {
+		service := NewSSEServiceWithConfig(&Config{StaleThreshold: 5 * time.Minute})
+		assert.Equal(t, DefaultClientBufferSize, cap(service.NewClientChannel()))
+	})
+}
+
+func TestSSEService_QueueHighWaterMark(t *testing.T) {
+	service := NewSSEServiceWithConfig(&Config{StaleThreshold: 5 * time.Minute, ClientBufferSize: 4})
+	client := newSlowClient(t)
+	client.Channel = service.NewClientChannel()
+
+	// The welcome message and two updates are queued at once
+	service.registerClient(client)
+	service.broadcastMessage(configUpdate(1))
+	service.broadcastMessage(configUpdate(2))
+	for i := 0; i < 3; i++ {
+		<-client.Channel
+	}
+
+	// The mark is kept once the client catches up
+	service.broadcastMessage(configUpdate(3))
+	assert.Equal(t, 3, client.QueueHighWaterMark)
+
+	clients := service.GetClients()
+	require.Len(t, clients, 1)
+	assert.Equal(t, 4, clients[0]["buffer_size"])
+	assert.Equal(t, 3, clients[0]["high_water_mark"])
+}
+
+func TestSSEService_BroadcastBufferSize(t *testing.T) {
+	t.Run("broadcasts beyond the queue are dropped and counted", func(t *testing.T) {
+		// Without the service loop nothing drains the queue
+		service := &SSEService{
+			config:    &Config{BroadcastBufferSize: 2, BroadcastWarnPercent: 100},
+			broadcast: make(chan BroadcastMessage, 2),
+		}
+		for i := 0; i < 3; i++ {
+			service.BroadcastCustomEvent("test-org", "test-app", "prod", "tag_update", i)
+		}
+
+		stats := service.GetStats()
+		assert.Equal(t, int64(1), stats.BroadcastsDropped)
+		assert.Equal(t, 2, stats.BroadcastsQueued)
+		assert.Equal(t, 2, stats.BroadcastBufferSize)
+		assert.True(t, service.broadcastBacklogged.Load())
+
+		// The backlog is over once the queue drains below the threshold
+		<-service.broadcast
+		<-service.broadcast
+		service.BroadcastCustomEvent("test-org", "test-app", "prod", "tag_update", 3)
+		assert.False(t, service.broadcastBacklogged.Load())
+		assert.Equal(t, int64(1), service.GetStats().BroadcastsDropped)
+	})
+
+	t.Run("unset size uses the default", func(t *testing.T) {
+		service := NewSSEServiceWithConfig(&Config{StaleThreshold: 5 * time.Minute})
+		assert.Equal(t, DefaultBroadcastBufferSize, service.GetStats().BroadcastBufferSize)
+	})
+}
+
+func TestSSEService_DisconnectClient(t *testing.T) {
+	service := NewSSEServiceWithConfig(&Config{StaleThreshold: 5 * time.Minute})
+	client := newSlowClient(t)
+	service.registerClient(client)
+	<-client.Channel
+
+	assert.True(t, service.DisconnectClient(client.ID))
+
+	// The stream ends with the closed channel and the cancelled context
+	_, open := <-client.Channel
+	assert.False(t, open)
+	assert.Error(t, client.Context.Err())
+	assert.Empty(t, service.GetClients())
+	assert.Equal(t, int64(1), service.GetStats().ConnectionsDropped)
+
+	// Unregistering the client as its stream ends does not count it again
+	service.unregisterClient(client)
+	assert.Equal(t, int64(1), service.GetStats().ConnectionsDropped)
+
+	assert.False(t, service.DisconnectClient(client.ID))
+	assert.False(t, service.DisconnectClient("unknown"))
+}
+
+func TestSSEService_FullChannelDisconnects(t *testing.T) {
+	service := NewSSEServiceWithConfig(&Config{StaleThreshold: 5 * time.Minute, SlowClientPolicy: SlowClientDisconnect})
+	client := newSlowClient(t)
+
+	service.registerClient(client)
+	service.broadcastMessage(configUpdate(1))
+	service.broadcastMessage(configUpdate(2))
+
+	// The client is unregistered by the service loop
+	require.Eventually(t, func() bool {
+		service.clientsMux.RLock()
+		defer service.clientsMux.RUnlock()
+		_, connected := service.clients[client.ID]
+		return !connected
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Zero(t, service.GetStats().MessagesDropped)
+}
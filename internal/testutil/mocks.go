@@ -61,6 +61,10 @@ func SetupTestSuite(t *testing.T) *TestSuite {
 			Password: getEnv("DB_PASSWORD", "postgres"),
 			DBName:   getEnv("DB_NAME", "test_remote_config"),
 			SSLMode:  "disable",
+
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 5 * time.Minute,
 		}
 
 		var err error
@@ -117,6 +121,10 @@ func SetupTestSuite(t *testing.T) *TestSuite {
 			Password: "test",
 			DBName:   "test_remote_config",
 			SSLMode:  "disable",
+
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 5 * time.Minute,
 		}
 
 		database, err = db.Connect(dbConfig)
@@ -241,16 +249,32 @@ func (m *MockConfigService) GetConfiguration(orgSlug, appSlug, envSlug string) (
 	return args.Get(0).(*models.ConfigResponse), args.Error(1)
 }
 
-func (m *MockConfigService) GetConfigurationByAPIKey(apiKey, envSlug string) (*models.ConfigResponse, error) {
-	args := m.Called(apiKey, envSlug)
+func (m *MockConfigService) GetRawConfiguration(orgSlug, appSlug, envSlug string) (*models.ConfigResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.ConfigResponse), args.Error(1)
 }
 
-func (m *MockConfigService) UpdateConfiguration(orgSlug, appSlug, envSlug string, req *models.CreateConfigRequest) (*models.ConfigResponse, error) {
-	args := m.Called(orgSlug, appSlug, envSlug, req)
+func (m *MockConfigService) GetConfigurationByAPIKey(app *models.Application, envSlug, clientID string) (*models.ConfigResponse, error) {
+	args := m.Called(app, envSlug, clientID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigResponse), args.Error(1)
+}
+
+func (m *MockConfigService) GetConfigurationsByAPIKey(app *models.Application, envSlugs []string, clientID string) (*models.BatchConfigResponse, error) {
+	args := m.Called(app, envSlugs, clientID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BatchConfigResponse), args.Error(1)
+}
+
+func (m *MockConfigService) UpdateConfiguration(orgSlug, appSlug, envSlug string, req *models.CreateConfigRequest, dryRun bool) (*models.ConfigResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, req, dryRun)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -265,6 +289,37 @@ func (m *MockConfigService) ValidateAPIKey(apiKey string) (*models.Application,
 	return args.Get(0).(*models.Application), args.Error(1)
 }
 
+func (m *MockConfigService) GetApplication(orgSlug, appSlug string) (*models.Application, error) {
+	args := m.Called(orgSlug, appSlug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Application), args.Error(1)
+}
+
+func (m *MockConfigService) ValidateAdminToken(token string) (*models.AdminToken, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AdminToken), args.Error(1)
+}
+
+func (m *MockConfigService) CheckOrganizationReadQuota(orgSlug string) error {
+	args := m.Called(orgSlug)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) RecordOrganizationRead(orgSlug string) error {
+	args := m.Called(orgSlug)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) RecordAPIKeyUsage(app *models.Application, envSlug string) error {
+	args := m.Called(app, envSlug)
+	return args.Error(0)
+}
+
 func (m *MockConfigService) HealthCheck() map[string]string {
 	args := m.Called()
 	return args.Get(0).(map[string]string)
@@ -303,6 +358,38 @@ func (m *MockConfigService) RollbackConfiguration(orgSlug, appSlug, envSlug stri
 	return args.Get(0).(*models.ConfigResponse), args.Error(1)
 }
 
+func (m *MockConfigService) CompareAndSwapKey(orgSlug, appSlug, envSlug string, req *models.CompareAndSwapRequest) (*models.ConfigResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigResponse), args.Error(1)
+}
+
+func (m *MockConfigService) UpdateConfigurations(orgSlug, appSlug string, req *models.BatchUpdateConfigRequest, dryRun bool) (*models.BatchConfigResponse, error) {
+	args := m.Called(orgSlug, appSlug, req, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BatchConfigResponse), args.Error(1)
+}
+
+func (m *MockConfigService) UpdateConfigurationsBySelector(orgSlug, appSlug, selector string, req *models.BatchUpdateConfigRequest, dryRun bool) (*models.BatchConfigResponse, error) {
+	args := m.Called(orgSlug, appSlug, selector, req, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BatchConfigResponse), args.Error(1)
+}
+
+func (m *MockConfigService) PromoteConfiguration(orgSlug, appSlug, envSlug string, req *models.PromoteConfigRequest, dryRun bool) (*models.ConfigResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, req, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigResponse), args.Error(1)
+}
+
 func (m *MockConfigService) GetConfigurationHistory(orgSlug, appSlug, envSlug string, params models.PaginationParams) (*models.PaginatedResponse, error) {
 	args := m.Called(orgSlug, appSlug, envSlug, params)
 	if args.Get(0) == nil {
@@ -319,6 +406,35 @@ func (m *MockConfigService) GetConfigurationVersion(orgSlug, appSlug, envSlug st
 	return args.Get(0).(*models.ConfigResponse), args.Error(1)
 }
 
+func (m *MockConfigService) GetConfigurationVersionByAPIKey(app *models.Application, envSlug string, version int) (*models.ConfigResponse, error) {
+	args := m.Called(app, envSlug, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigResponse), args.Error(1)
+}
+
+func (m *MockConfigService) GetConfigurationMeta(orgSlug, appSlug, envSlug string, version int) (*models.ConfigMeta, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigMeta), args.Error(1)
+}
+
+// ExportConfigurationChanges passes the changes given to Return to fn
+func (m *MockConfigService) ExportConfigurationChanges(orgSlug, appSlug, envSlug string, orgScope bool, fn func(*models.ConfigChange) error) error {
+	args := m.Called(orgSlug, appSlug, envSlug, orgScope)
+	if changes, ok := args.Get(0).([]models.ConfigChange); ok {
+		for i := range changes {
+			if err := fn(&changes[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
 func (m *MockConfigService) GetConfigurationChanges(orgSlug, appSlug, envSlug string, params models.PaginationParams) (*models.PaginatedResponse, error) {
 	args := m.Called(orgSlug, appSlug, envSlug, params)
 	if args.Get(0) == nil {
@@ -327,6 +443,289 @@ func (m *MockConfigService) GetConfigurationChanges(orgSlug, appSlug, envSlug st
 	return args.Get(0).(*models.PaginatedResponse), args.Error(1)
 }
 
+func (m *MockConfigService) DiffDraftConfiguration(orgSlug, appSlug, envSlug string, draft json.RawMessage) (*models.ConfigDiffResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, draft)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigDiffResponse), args.Error(1)
+}
+
+func (m *MockConfigService) LintConfiguration(orgSlug, appSlug, envSlug string, config json.RawMessage) (*models.ConfigLintResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, config)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigLintResponse), args.Error(1)
+}
+
+func (m *MockConfigService) EncryptExistingSecrets(orgSlug, appSlug, envSlug string) (*models.EncryptSecretsResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EncryptSecretsResponse), args.Error(1)
+}
+
+func (m *MockConfigService) PruneHistory(orgSlug, appSlug, envSlug string) (*models.PruneHistoryResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PruneHistoryResponse), args.Error(1)
+}
+
+func (m *MockConfigService) GetKeyHistory(orgSlug, appSlug, envSlug, keyPath string) (*models.KeyHistoryResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, keyPath)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.KeyHistoryResponse), args.Error(1)
+}
+
+func (m *MockConfigService) SearchConfigurations(key, value string, params models.PaginationParams) (*models.PaginatedResponse, error) {
+	args := m.Called(key, value, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaginatedResponse), args.Error(1)
+}
+
+func (m *MockConfigService) ListPendingChanges(orgSlug, appSlug, envSlug, status string, params models.PaginationParams) (*models.PaginatedResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, status, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PaginatedResponse), args.Error(1)
+}
+
+func (m *MockConfigService) ApprovePendingChange(orgSlug, appSlug, envSlug string, req *models.ReviewChangeRequest) (*models.ConfigResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigResponse), args.Error(1)
+}
+
+func (m *MockConfigService) RejectPendingChange(orgSlug, appSlug, envSlug string, req *models.ReviewChangeRequest) (*models.PendingChange, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PendingChange), args.Error(1)
+}
+
+func (m *MockConfigService) ListTags(orgSlug, appSlug, envSlug string) ([]models.ConfigTag, error) {
+	args := m.Called(orgSlug, appSlug, envSlug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ConfigTag), args.Error(1)
+}
+
+func (m *MockConfigService) SetTag(orgSlug, appSlug, envSlug, name string, req *models.SetTagRequest) (*models.ConfigTag, bool, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, name, req)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*models.ConfigTag), args.Bool(1), args.Error(2)
+}
+
+func (m *MockConfigService) DeleteTag(orgSlug, appSlug, envSlug, name string, deletedBy *string) error {
+	args := m.Called(orgSlug, appSlug, envSlug, name, deletedBy)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) GetConfigurationAtTime(orgSlug, appSlug, envSlug string, at time.Time) (*models.ConfigResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigResponse), args.Error(1)
+}
+
+func (m *MockConfigService) GetConfigurationByTag(orgSlug, appSlug, envSlug, name string) (*models.ConfigResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigResponse), args.Error(1)
+}
+
+func (m *MockConfigService) GetConfigurationByAPIKeyAndTag(app *models.Application, envSlug, name string) (*models.ConfigResponse, error) {
+	args := m.Called(app, envSlug, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigResponse), args.Error(1)
+}
+
+func (m *MockConfigService) GetFlagsByAPIKey(app *models.Application, envSlug string, names []string, defaultValue *bool) (*models.FlagSet, error) {
+	args := m.Called(app, envSlug, names, defaultValue)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FlagSet), args.Error(1)
+}
+
+func (m *MockConfigService) GetRollout(orgSlug, appSlug, envSlug string) (*models.ConfigRollout, error) {
+	args := m.Called(orgSlug, appSlug, envSlug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigRollout), args.Error(1)
+}
+
+func (m *MockConfigService) UpdateRollout(orgSlug, appSlug, envSlug string, req *models.UpdateRolloutRequest) (*models.ConfigRollout, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigRollout), args.Error(1)
+}
+
+func (m *MockConfigService) PromoteRollout(orgSlug, appSlug, envSlug string, req *models.PromoteRolloutRequest) (*models.ConfigResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigResponse), args.Error(1)
+}
+
+func (m *MockConfigService) AbortRollout(orgSlug, appSlug, envSlug string, abortedBy *string) error {
+	args := m.Called(orgSlug, appSlug, envSlug, abortedBy)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) GetApplicationDefaults(orgSlug, appSlug string) (*models.ApplicationDefaults, error) {
+	args := m.Called(orgSlug, appSlug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ApplicationDefaults), args.Error(1)
+}
+
+func (m *MockConfigService) SetApplicationDefaults(orgSlug, appSlug string, req *models.UpdateApplicationDefaultsRequest) (*models.ApplicationDefaults, error) {
+	args := m.Called(orgSlug, appSlug, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ApplicationDefaults), args.Error(1)
+}
+
+func (m *MockConfigService) DeleteApplicationDefaults(orgSlug, appSlug string, deletedBy *string) error {
+	args := m.Called(orgSlug, appSlug, deletedBy)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) GetConfigSchema(orgSlug, appSlug, envSlug string) (*models.ConfigSchema, error) {
+	args := m.Called(orgSlug, appSlug, envSlug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigSchema), args.Error(1)
+}
+
+func (m *MockConfigService) SetConfigSchema(orgSlug, appSlug, envSlug string, req *models.UpdateConfigSchemaRequest) (*models.ConfigSchema, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigSchema), args.Error(1)
+}
+
+func (m *MockConfigService) GetFormSchema(orgSlug, appSlug, envSlug string) (*models.FormSchemaResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FormSchemaResponse), args.Error(1)
+}
+
+func (m *MockConfigService) GetConfigRules(orgSlug, appSlug, envSlug string) (*models.ConfigRuleset, error) {
+	args := m.Called(orgSlug, appSlug, envSlug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigRuleset), args.Error(1)
+}
+
+func (m *MockConfigService) SetConfigRules(orgSlug, appSlug, envSlug string, req *models.UpdateConfigRulesRequest) (*models.ConfigRuleset, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigRuleset), args.Error(1)
+}
+
+func (m *MockConfigService) DeleteConfigRules(orgSlug, appSlug, envSlug string) error {
+	args := m.Called(orgSlug, appSlug, envSlug)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) ValidateManifest(items []models.ManifestItem) *models.ManifestValidationResponse {
+	args := m.Called(items)
+	return args.Get(0).(*models.ManifestValidationResponse)
+}
+
+func (m *MockConfigService) ListTemplates(orgSlug string) ([]models.ConfigTemplate, error) {
+	args := m.Called(orgSlug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ConfigTemplate), args.Error(1)
+}
+
+func (m *MockConfigService) GetTemplate(orgSlug, name string) (*models.ConfigTemplate, error) {
+	args := m.Called(orgSlug, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigTemplate), args.Error(1)
+}
+
+func (m *MockConfigService) SetTemplate(orgSlug, name string, req *models.SetConfigTemplateRequest) (*models.ConfigTemplate, bool, error) {
+	args := m.Called(orgSlug, name, req)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*models.ConfigTemplate), args.Bool(1), args.Error(2)
+}
+
+func (m *MockConfigService) DeleteTemplate(orgSlug, name string) error {
+	args := m.Called(orgSlug, name)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) InstantiateTemplate(orgSlug, appSlug, envSlug string, req *models.InstantiateTemplateRequest, dryRun bool) (*models.ConfigResponse, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, req, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ConfigResponse), args.Error(1)
+}
+
+func (m *MockConfigService) GetNotificationSettings(orgSlug, appSlug, envSlug string) (*models.NotificationSettings, error) {
+	args := m.Called(orgSlug, appSlug, envSlug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationSettings), args.Error(1)
+}
+
+func (m *MockConfigService) SetNotificationSettings(orgSlug, appSlug, envSlug string, req *models.UpdateNotificationSettingsRequest) (*models.NotificationSettings, error) {
+	args := m.Called(orgSlug, appSlug, envSlug, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.NotificationSettings), args.Error(1)
+}
+
+func (m *MockConfigService) DeleteNotificationSettings(orgSlug, appSlug, envSlug string) error {
+	args := m.Called(orgSlug, appSlug, envSlug)
+	return args.Error(0)
+}
+
 // MockSSEService is a mock implementation of the SSE service
 type MockSSEService struct {
 	mock.Mock
@@ -374,6 +773,11 @@ func (m *MockSSEService) Ping(clientID string) {
 	m.Called(clientID)
 }
 
+func (m *MockSSEService) NewClientChannel() chan models.SSEMessage {
+	m.Called()
+	return make(chan models.SSEMessage, 10)
+}
+
 // MockCacheClient is a mock implementation of the Redis cache client
 type MockCacheClient struct {
 	mock.Mock
@@ -0,0 +1,4 @@
+-- Secret configuration keys
+-- Top-level configuration keys listed here have their values encrypted at rest
+
+ALTER TABLE environments ADD COLUMN secret_keys TEXT[] NOT NULL DEFAULT '{}';
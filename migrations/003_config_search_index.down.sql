@@ -0,0 +1 @@
+DROP INDEX idx_config_versions_active_json;
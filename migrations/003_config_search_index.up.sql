@@ -0,0 +1,4 @@
+-- Configuration search
+-- GIN index over active configuration documents to support JSONB path queries
+
+CREATE INDEX idx_config_versions_active_json ON config_versions USING GIN (config_json jsonb_path_ops) WHERE is_active = TRUE;
@@ -0,0 +1,12 @@
+-- Configuration schemas
+-- Stores a JSON Schema per environment describing its configuration document
+
+CREATE TABLE config_schemas (
+    env_id UUID PRIMARY KEY REFERENCES environments(id) ON DELETE CASCADE,
+    schema_json JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER update_config_schemas_updated_at BEFORE UPDATE ON config_schemas
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
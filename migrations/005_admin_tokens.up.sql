@@ -0,0 +1,10 @@
+-- Admin tokens
+-- Credentials for the admin API, each granting a role. Only a SHA-256 hash of the token is stored.
+
+CREATE TABLE admin_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('viewer', 'editor', 'admin')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
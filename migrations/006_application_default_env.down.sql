@@ -0,0 +1 @@
+ALTER TABLE applications DROP COLUMN default_env;
@@ -0,0 +1,4 @@
+-- Default environment per application
+-- Slug of the environment served by GET /api/config when a client does not name one
+
+ALTER TABLE applications ADD COLUMN default_env VARCHAR(50);
@@ -0,0 +1,7 @@
+-- Proposed versions that were never approved stay behind as inactive versions
+
+ALTER TABLE config_changes DROP COLUMN approved_by;
+
+DROP TABLE pending_changes;
+
+ALTER TABLE environments DROP COLUMN requires_approval;
@@ -0,0 +1,24 @@
+-- Configuration approval workflow
+-- Updates to environments that require approval create an inactive proposed version
+-- and a pending change, which is activated only once it has been approved.
+
+ALTER TABLE environments ADD COLUMN requires_approval BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE pending_changes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    env_id UUID NOT NULL REFERENCES environments(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    base_version INTEGER,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    proposed_by VARCHAR(255),
+    reviewed_by VARCHAR(255),
+    review_comment TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE(env_id, version)
+);
+
+CREATE INDEX idx_pending_changes_env_status ON pending_changes(env_id, status);
+
+-- The proposer stays in created_by; approved changes also record who approved them
+ALTER TABLE config_changes ADD COLUMN approved_by VARCHAR(255);
@@ -0,0 +1,3 @@
+ALTER TABLE config_changes DROP COLUMN tag;
+
+DROP TABLE config_tags;
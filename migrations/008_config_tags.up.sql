@@ -0,0 +1,18 @@
+-- Configuration version tags
+-- Named labels such as "v2.3.0" or "canary" pointing at a configuration version.
+-- Clients can follow a tag instead of the active version.
+
+CREATE TABLE config_tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    env_id UUID NOT NULL REFERENCES environments(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    version INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_by VARCHAR(255),
+    UNIQUE(env_id, name),
+    FOREIGN KEY (env_id, version) REFERENCES config_versions(env_id, version) ON DELETE CASCADE
+);
+
+-- Tag changes are recorded in the change log with the tag they affected
+ALTER TABLE config_changes ADD COLUMN tag VARCHAR(100);
@@ -0,0 +1,3 @@
+-- Rollout versions that were never promoted stay behind as inactive versions
+
+DROP TABLE config_rollouts;
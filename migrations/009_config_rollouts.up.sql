@@ -0,0 +1,15 @@
+-- Gradual rollouts of configuration versions
+-- While a rollout is in progress, a share of API-key clients (picked by hashing their
+-- X-Client-ID) get the rollout version instead of the active one. An environment has at
+-- most one rollout; promoting it activates the version and removes the rollout.
+
+CREATE TABLE config_rollouts (
+    env_id UUID PRIMARY KEY REFERENCES environments(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    stable_version INTEGER NOT NULL,
+    percentage INTEGER NOT NULL CHECK (percentage BETWEEN 0 AND 100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_by VARCHAR(255),
+    FOREIGN KEY (env_id, version) REFERENCES config_versions(env_id, version) ON DELETE CASCADE
+);
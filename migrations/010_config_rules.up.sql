@@ -0,0 +1,13 @@
+-- Configuration rules
+-- Stores a list of declarative rules per environment (required keys, types, ranges,
+-- patterns) that configuration updates must satisfy
+
+CREATE TABLE config_rules (
+    env_id UUID PRIMARY KEY REFERENCES environments(id) ON DELETE CASCADE,
+    rules JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER update_config_rules_updated_at BEFORE UPDATE ON config_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,6 @@
+-- Change log entries of default edits would read as environment versions without their scope
+
+DELETE FROM config_changes WHERE scope = 'defaults';
+ALTER TABLE config_changes DROP COLUMN scope;
+
+DROP TABLE application_defaults;
@@ -0,0 +1,19 @@
+-- Application default configurations
+-- Keys shared by an application's environments. Each environment inherits them and
+-- overrides the ones it sets itself. The version is bumped on every edit.
+
+CREATE TABLE application_defaults (
+    app_id UUID PRIMARY KEY REFERENCES applications(id) ON DELETE CASCADE,
+    config_json JSONB NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_by VARCHAR(255)
+);
+
+CREATE TRIGGER update_application_defaults_updated_at BEFORE UPDATE ON application_defaults
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Edits of the defaults are logged for every child environment with scope 'defaults';
+-- their versions refer to the application defaults rather than the environment
+ALTER TABLE config_changes ADD COLUMN scope VARCHAR(20) NOT NULL DEFAULT 'environment';
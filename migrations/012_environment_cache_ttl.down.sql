@@ -0,0 +1 @@
+ALTER TABLE environments DROP COLUMN cache_ttl_seconds;
@@ -0,0 +1,4 @@
+-- Per-environment cache TTL
+-- Cached configurations of environments without an override expire after the global TTL
+
+ALTER TABLE environments ADD COLUMN cache_ttl_seconds INTEGER CHECK (cache_ttl_seconds > 0);
@@ -0,0 +1,2 @@
+ALTER TABLE config_changes DROP COLUMN source_version;
+ALTER TABLE config_changes DROP COLUMN source_env;
@@ -0,0 +1,6 @@
+-- Configuration promotions
+-- A promotion copies the active configuration of one environment into another as a new
+-- version. Its change log entry records the environment and version it was copied from.
+
+ALTER TABLE config_changes ADD COLUMN source_env VARCHAR(100);
+ALTER TABLE config_changes ADD COLUMN source_version INTEGER;
@@ -0,0 +1,2 @@
+ALTER TABLE environments DROP COLUMN retention_days;
+ALTER TABLE environments DROP COLUMN retention_versions;
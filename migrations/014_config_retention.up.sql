@@ -0,0 +1,7 @@
+-- Configuration version retention
+-- Environments can override the global retention policy: inactive versions beyond the
+-- latest retention_versions and older than retention_days are pruned. Active, tagged,
+-- rolling out and pending versions are always kept.
+
+ALTER TABLE environments ADD COLUMN retention_versions INTEGER CHECK (retention_versions > 0);
+ALTER TABLE environments ADD COLUMN retention_days INTEGER CHECK (retention_days > 0);
@@ -0,0 +1 @@
+DROP TABLE environment_notifications;
@@ -0,0 +1,15 @@
+-- Environment notifications
+-- Announces configuration changes of an environment to people: a Slack incoming webhook
+-- and/or email recipients, for the change log actions listed in events
+
+CREATE TABLE environment_notifications (
+    env_id UUID PRIMARY KEY REFERENCES environments(id) ON DELETE CASCADE,
+    slack_webhook_url TEXT,
+    email_recipients TEXT[] NOT NULL DEFAULT '{}',
+    events TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TRIGGER update_environment_notifications_updated_at BEFORE UPDATE ON environment_notifications
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+ALTER TABLE environments DROP COLUMN locked_at;
+ALTER TABLE environments DROP COLUMN locked_by;
+ALTER TABLE environments DROP COLUMN lock_reason;
+ALTER TABLE environments DROP COLUMN locked;
@@ -0,0 +1,9 @@
+-- Environment locks
+-- A locked environment rejects configuration changes, e.g. during incident response or a
+-- release freeze. Reads and streams keep working. The reason and who locked it are kept
+-- so that rejected changes can say why.
+
+ALTER TABLE environments ADD COLUMN locked BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE environments ADD COLUMN lock_reason TEXT;
+ALTER TABLE environments ADD COLUMN locked_by VARCHAR(255);
+ALTER TABLE environments ADD COLUMN locked_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,19 @@
+-- Configuration templates
+-- Named base configurations shared by the applications of an organization. Instantiating
+-- a template substitutes its {{variables}} and stores the result as a new version of an
+-- environment.
+
+CREATE TABLE config_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    template JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_by VARCHAR(255),
+    UNIQUE(org_id, name)
+);
+
+CREATE TRIGGER update_config_templates_updated_at BEFORE UPDATE ON config_templates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
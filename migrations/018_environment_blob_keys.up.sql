@@ -0,0 +1,5 @@
+-- Blob configuration keys
+-- Configuration keys listed here, as dot-notated paths, hold base64-encoded blobs such as
+-- certificates. Diffs summarize their changes and each blob has its own size limit.
+
+ALTER TABLE environments ADD COLUMN blob_keys TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,15 @@
+-- API key usage
+-- Requests made with each application's API key, per environment and day. Requests are
+-- counted in Redis and added to this table periodically, so that serving a configuration
+-- never writes to the database.
+
+CREATE TABLE api_key_usage (
+    app_id UUID NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    environment VARCHAR(100) NOT NULL,
+    day DATE NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (app_id, environment, day)
+);
+
+CREATE INDEX idx_api_key_usage_day ON api_key_usage(day);
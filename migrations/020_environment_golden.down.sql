@@ -0,0 +1,2 @@
+ALTER TABLE environments DROP COLUMN golden_strict;
+ALTER TABLE environments DROP COLUMN golden_environment;
@@ -0,0 +1,7 @@
+-- Golden environments
+-- Updates of an environment are compared with the keys of the active configuration of its
+-- golden environment, another environment of the same application. Missing and extra keys
+-- are reported, or rejected when golden_strict is set.
+
+ALTER TABLE environments ADD COLUMN golden_environment VARCHAR(50);
+ALTER TABLE environments ADD COLUMN golden_strict BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,12 @@
+-- Organization read usage
+-- Configuration reads of each organization per calendar month (UTC), for the monthly read
+-- quota. Reads are counted in Redis and their running totals are stored in this table
+-- periodically, so that counts survive a restart of Redis.
+
+CREATE TABLE organization_read_usage (
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    month DATE NOT NULL,
+    read_count BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, month)
+);
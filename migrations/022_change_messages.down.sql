@@ -0,0 +1,2 @@
+ALTER TABLE pending_changes DROP COLUMN message;
+ALTER TABLE config_changes DROP COLUMN message;
@@ -0,0 +1,6 @@
+-- Change messages
+-- An optional note explaining a configuration change, like a commit message. Proposed
+-- changes keep the message of their proposer until they are approved.
+
+ALTER TABLE config_changes ADD COLUMN message TEXT;
+ALTER TABLE pending_changes ADD COLUMN message TEXT;
@@ -0,0 +1,17 @@
+-- Environment labels
+-- Arbitrary key=value labels such as tier=canary on environments. Label selectors pick
+-- environments across applications, e.g. for batch updates of an organization's fleet.
+
+CREATE TABLE environment_labels (
+    env_id UUID NOT NULL REFERENCES environments(id) ON DELETE CASCADE,
+    key VARCHAR(63) NOT NULL,
+    value VARCHAR(63) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (env_id, key)
+);
+
+CREATE INDEX idx_environment_labels_key_value ON environment_labels(key, value);
+
+CREATE TRIGGER update_environment_labels_updated_at BEFORE UPDATE ON environment_labels
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,26 @@
+-- API key hashes
+-- Applications keep a salted SHA-256 hash of their API key instead of the key itself, so a
+-- leaked database does not leak the keys. A short prefix of each key is kept in the clear
+-- to look keys up by and to tell them apart. Existing keys are hashed in place and keep
+-- working. They cannot be recovered afterwards, so this migration cannot be rolled back.
+
+ALTER TABLE applications
+    ADD COLUMN api_key_prefix VARCHAR(8),
+    ADD COLUMN api_key_salt VARCHAR(32),
+    ADD COLUMN api_key_hash VARCHAR(64);
+
+-- The prefix is at most half of the key, so short custom keys are not stored in the clear
+UPDATE applications SET
+    api_key_prefix = left(api_key, LEAST(8, char_length(api_key) / 2)),
+    api_key_salt = replace(gen_random_uuid()::text, '-', '');
+
+UPDATE applications SET
+    api_key_hash = encode(sha256(convert_to(api_key_salt || api_key, 'UTF8')), 'hex');
+
+ALTER TABLE applications
+    ALTER COLUMN api_key_prefix SET NOT NULL,
+    ALTER COLUMN api_key_salt SET NOT NULL,
+    ALTER COLUMN api_key_hash SET NOT NULL,
+    DROP COLUMN api_key;
+
+CREATE INDEX idx_applications_api_key_prefix ON applications(api_key_prefix);
@@ -5,10 +5,10 @@ class API {
         'Content-Type': 'application/json',
     };
 
-    static async request(method, url, data = null, headers = {}) {
+    static async request(method, url, data = null, headers = {}, retried = false) {
         const config = {
             method: method.toUpperCase(),
-            headers: { ...this.defaultHeaders, ...headers },
+            headers: { ...this.defaultHeaders, ...this.authHeaders(), ...headers },
         };
 
         if (data && (method.toUpperCase() === 'POST' || method.toUpperCase() === 'PUT')) {
@@ -18,6 +18,11 @@ class API {
         try {
             const response = await fetch(this.baseURL + url, config);
             
+            // A missing or revoked admin token: ask for one and try again once
+            if (response.status === 401 && !retried && this.promptAdminToken()) {
+                return this.request(method, url, data, headers, true);
+            }
+
             if (!response.ok) {
                 const errorData = await response.json().catch(() => ({}));
                 throw new APIError(response.status, errorData.message || 'Request failed', errorData);
@@ -37,6 +42,35 @@ class API {
         }
     }
 
+    static authHeaders() {
+        const adminToken = localStorage.getItem('adminToken');
+        return adminToken ? { 'X-Admin-Token': adminToken } : {};
+    }
+
+    static setAdminToken(token) {
+        token = (token || '').trim();
+        if (token) {
+            localStorage.setItem('adminToken', token);
+        } else {
+            localStorage.removeItem('adminToken');
+        }
+
+        const input = document.getElementById('admin-token');
+        if (input) {
+            input.value = token;
+        }
+    }
+
+    // Asks for an admin token after the current one was rejected. Returns whether one was entered.
+    static promptAdminToken() {
+        const token = window.prompt('The admin API requires a token. Enter your admin token (X-Admin-Token):');
+        if (!token || !token.trim()) {
+            return false;
+        }
+        this.setAdminToken(token);
+        return true;
+    }
+
     static async get(url, headers = {}) {
         return this.request('GET', url, null, headers);
     }
@@ -64,6 +98,10 @@ class API {
         return this.get(`/admin/orgs/${slug}`);
     }
 
+    static async getOrganizationTree(slug, depth = 2) {
+        return this.get(`/admin/orgs/${slug}/tree?depth=${depth}`);
+    }
+
     static async createOrganization(data) {
         return this.post('/admin/orgs', data);
     }
@@ -191,7 +229,7 @@ window.APIError = APIError;
 
 // Add request interceptor for loading states
 const originalRequest = API.request;
-API.request = async function(method, url, data, headers) {
+API.request = async function(method, url, data, headers, retried) {
     // Show loading indicator if available
     const loadingIndicator = document.querySelector('.loading-indicator');
     if (loadingIndicator) {
@@ -199,7 +237,7 @@ API.request = async function(method, url, data, headers) {
     }
 
     try {
-        const result = await originalRequest.call(this, method, url, data, headers);
+        const result = await originalRequest.call(this, method, url, data, headers, retried);
         return result;
     } catch (error) {
         // Log error for debugging
@@ -41,6 +41,16 @@ class Dashboard {
         // Cache management
         window.warmCache = () => this.warmCache();
         window.clearCache = () => this.clearCache();
+
+        // Admin token, kept in localStorage and sent with every admin request
+        const tokenInput = document.getElementById('admin-token');
+        if (tokenInput) {
+            tokenInput.value = localStorage.getItem('adminToken') || '';
+        }
+        window.setAdminToken = (token) => {
+            API.setAdminToken(token);
+            this.refreshCurrentSection();
+        };
     }
 
     switchSection(section) {
@@ -180,12 +190,12 @@ class Dashboard {
                 return;
             }
 
-            // Load applications for all organizations
+            // Load applications for all organizations, each organization with its applications in one call
             const apps = [];
             const loadPromises = this.data.organizations.map(async (org) => {
                 try {
-                    const response = await API.get(`/admin/orgs/${org.slug}/apps`);
-                    const orgApps = (response.data || []).map(app => ({
+                    const response = await API.get(`/admin/orgs/${org.slug}?include=apps`);
+                    const orgApps = (response.applications || []).map(app => ({
                         ...app,
                         organization: org
                     }));
@@ -453,7 +463,7 @@ class Dashboard {
                 <td>${app.name}</td>
                 <td><code>${app.slug}</code></td>
                 <td>${app.organization.name}</td>
-                <td><code class="api-key">${app.api_key ? app.api_key.substring(0, 20) + '...' : 'N/A'}</code></td>
+                <td><code class="api-key">${app.api_key_prefix ? app.api_key_prefix + '...' : 'N/A'}</code></td>
                 <td>${new Date(app.created_at).toLocaleDateString()}</td>
                 <td>
                     <div class="action-buttons">
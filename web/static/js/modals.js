@@ -297,8 +297,10 @@ window.handleCreateApp = async (event) => {
 
     try {
         APIUtils.validateRequired(data, ['name', 'slug']);
-        await API.createApplication(orgSlug, data);
+        const app = await API.createApplication(orgSlug, data);
         modalManager.closeModal();
+        // Only a hash of the key is stored, so this is the only time it can be shown
+        prompt('Application created. Copy its API key now, it will not be shown again:', app.api_key);
         dashboard.showSuccess('Application created successfully');
         await dashboard.loadApplications();
         if (dashboard.currentSection === 'applications') {